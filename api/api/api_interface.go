@@ -88,8 +88,13 @@ type TenantInterface interface {
 	GetManyDeployVersion(w http.ResponseWriter, r *http.Request)
 	LimitTenantMemory(w http.ResponseWriter, r *http.Request)
 	TenantResourcesStatus(w http.ResponseWriter, r *http.Request)
+	GetTenantQuota(w http.ResponseWriter, r *http.Request)
+	UpdateTenantQuota(w http.ResponseWriter, r *http.Request)
+	GetNetworkIsolationReport(w http.ResponseWriter, r *http.Request)
+	UpdateNetworkIsolation(w http.ResponseWriter, r *http.Request)
 	CheckResourceName(w http.ResponseWriter, r *http.Request)
 	Log(w http.ResponseWriter, r *http.Request)
+	ListResourceRecommendations(w http.ResponseWriter, r *http.Request)
 }
 
 // HelmInterface HelmInterface
@@ -135,11 +140,36 @@ type ServiceInterface interface {
 	GetDeployVersion(w http.ResponseWriter, r *http.Request)
 	AutoscalerRules(w http.ResponseWriter, r *http.Request)
 	ScalingRecords(w http.ResponseWriter, r *http.Request)
+	ListScalingSchedules(w http.ResponseWriter, r *http.Request)
+	AddScalingSchedule(w http.ResponseWriter, r *http.Request)
+	UpdScalingSchedule(w http.ResponseWriter, r *http.Request)
+	DeleteScalingSchedule(w http.ResponseWriter, r *http.Request)
+	GetScaleToZero(w http.ResponseWriter, r *http.Request)
+	UpdScaleToZero(w http.ResponseWriter, r *http.Request)
+	GetResourceRecommendation(w http.ResponseWriter, r *http.Request)
+	UpdRecommendationPolicy(w http.ResponseWriter, r *http.Request)
+	ApplyResourceRecommendation(w http.ResponseWriter, r *http.Request)
+	ListVolumeSnapshots(w http.ResponseWriter, r *http.Request)
+	CreateVolumeSnapshot(w http.ResponseWriter, r *http.Request)
+	RestoreVolumeSnapshot(w http.ResponseWriter, r *http.Request)
+	DeleteVolumeSnapshot(w http.ResponseWriter, r *http.Request)
+	ListSnapshotPolicies(w http.ResponseWriter, r *http.Request)
+	AddSnapshotPolicy(w http.ResponseWriter, r *http.Request)
+	UpdSnapshotPolicy(w http.ResponseWriter, r *http.Request)
+	DeleteSnapshotPolicy(w http.ResponseWriter, r *http.Request)
 	AddServiceMonitors(w http.ResponseWriter, r *http.Request)
 	DeleteServiceMonitors(w http.ResponseWriter, r *http.Request)
 	UpdateServiceMonitors(w http.ResponseWriter, r *http.Request)
 	UploadPackage(w http.ResponseWriter, r *http.Request)
 	K8sAttributes(w http.ResponseWriter, r *http.Request)
+	GetBuildTrigger(w http.ResponseWriter, r *http.Request)
+	UpdBuildTrigger(w http.ResponseWriter, r *http.Request)
+	DeleteBuildTrigger(w http.ResponseWriter, r *http.Request)
+	GetBuildPipeline(w http.ResponseWriter, r *http.Request)
+	UpdBuildPipeline(w http.ResponseWriter, r *http.Request)
+	DeleteBuildPipeline(w http.ResponseWriter, r *http.Request)
+	ListPipelineRuns(w http.ResponseWriter, r *http.Request)
+	PromotePipelineRun(w http.ResponseWriter, r *http.Request)
 }
 
 // TenantInterfaceWithV1 funcs for both v2 and v1
@@ -294,3 +324,29 @@ type RegistryInterface interface {
 	GetTagsByRepoName(w http.ResponseWriter, r *http.Request)
 	CheckRegistry(w http.ResponseWriter, r *http.Request)
 }
+
+// AlertInterface user defined alert rules, channels and silences
+type AlertInterface interface {
+	ListAlertRules(w http.ResponseWriter, r *http.Request)
+	CreateAlertRule(w http.ResponseWriter, r *http.Request)
+	GetAlertRule(w http.ResponseWriter, r *http.Request)
+	UpdateAlertRule(w http.ResponseWriter, r *http.Request)
+	DeleteAlertRule(w http.ResponseWriter, r *http.Request)
+	ListAlertChannels(w http.ResponseWriter, r *http.Request)
+	CreateAlertChannel(w http.ResponseWriter, r *http.Request)
+	UpdateAlertChannel(w http.ResponseWriter, r *http.Request)
+	DeleteAlertChannel(w http.ResponseWriter, r *http.Request)
+	TestAlertChannel(w http.ResponseWriter, r *http.Request)
+	ListAlertSilences(w http.ResponseWriter, r *http.Request)
+	CreateAlertSilence(w http.ResponseWriter, r *http.Request)
+	DeleteAlertSilence(w http.ResponseWriter, r *http.Request)
+	ListAlerts(w http.ResponseWriter, r *http.Request)
+	AcknowledgeAlert(w http.ResponseWriter, r *http.Request)
+}
+
+// AuditInterface the audit logs of the mutating api calls
+type AuditInterface interface {
+	ListAuditLogs(w http.ResponseWriter, r *http.Request)
+	ExportAuditLogs(w http.ResponseWriter, r *http.Request)
+	VerifyAuditLogs(w http.ResponseWriter, r *http.Request)
+}
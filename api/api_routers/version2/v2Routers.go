@@ -64,10 +64,28 @@ func (v2 *V2) Routes() chi.Router {
 	r.Mount("/monitor", v2.monitorRouter())
 	r.Mount("/helm", v2.helmRouter())
 	r.Mount("/proxy-pass", v2.proxyRoute())
+	r.Mount("/api-tokens", v2.apiTokenRouter())
+	r.Mount("/audit-logs", v2.auditLogRouter())
 
 	return r
 }
 
+func (v2 *V2) apiTokenRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", controller.GetCloudRouterManager().ListAPITokens)
+	r.Post("/", controller.GetCloudRouterManager().CreateAPIToken)
+	r.Delete("/{token_id}", controller.GetCloudRouterManager().RevokeAPIToken)
+	return r
+}
+
+func (v2 *V2) auditLogRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", controller.GetManager().ListAuditLogs)
+	r.Get("/export", controller.GetManager().ExportAuditLogs)
+	r.Get("/verify", controller.GetManager().VerifyAuditLogs)
+	return r
+}
+
 func (v2 *V2) proxyRoute() chi.Router {
 	r := chi.NewRouter()
 	r.Post("/registry/repos", controller.GetManager().GetAllRepo)
@@ -192,6 +210,7 @@ func (v2 *V2) tenantNameRouter() chi.Router {
 	r.Get("/image-tags", controller.RegistryImageTags)
 	r.Get("/servicecheck/{uuid}", controller.GetServiceCheckInfo)
 	r.Get("/resources", controller.GetManager().SingleTenantResources)
+	r.Get("/resource-recommendations", controller.GetManager().ListResourceRecommendations)
 	r.Get("/services", controller.GetManager().ServicesInfo)
 	//创建应用
 	r.Post("/services", middleware.WrapEL(controller.GetManager().CreateService, dbmodel.TargetTypeService, "create-service", dbmodel.SYNEVENTTYPE))
@@ -227,10 +246,22 @@ func (v2 *V2) tenantNameRouter() chi.Router {
 	r.Delete("/groupapp/backups/{backup_id}", controller.DeleteBackup)
 	r.Post("/groupapp/backups/{backup_id}/restore", controller.Restore)
 	r.Get("/groupapp/backups/{backup_id}/restore/{restore_id}", controller.RestoreResult)
+	//app migration
+	r.Get("/groupapp/migrations", controller.Migrations)
+	r.Post("/groupapp/migrations", controller.NewMigration)
+	r.Get("/groupapp/migrations/{migration_id}", controller.GetMigration)
+	r.Post("/groupapp/migrations/{migration_id}/presync", controller.PresyncMigration)
+	r.Post("/groupapp/migrations/{migration_id}/cutover", controller.CutoverMigration)
+	r.Post("/groupapp/migrations/{migration_id}/complete", controller.CompleteMigration)
+	r.Post("/groupapp/migrations/{migration_id}/rollback", controller.RollbackMigration)
 	r.Post("/deployversions", controller.GetManager().GetManyDeployVersion)
 	//团队资源限制
 	r.Post("/limit_memory", controller.GetManager().LimitTenantMemory)
 	r.Get("/limit_memory", controller.GetManager().TenantResourcesStatus)
+	r.Get("/quota", controller.GetManager().GetTenantQuota)
+	r.Put("/quota", controller.GetManager().UpdateTenantQuota)
+	r.Get("/network-isolation", controller.GetManager().GetNetworkIsolationReport)
+	r.Put("/network-isolation", controller.GetManager().UpdateNetworkIsolation)
 
 	// Gateway
 	r.Post("/http-rule", controller.GetManager().HTTPRule)
@@ -262,6 +293,29 @@ func (v2 *V2) tenantNameRouter() chi.Router {
 	r.Put("/registry/auth", controller.GetManager().RegistryAuthSecret)
 	r.Delete("/registry/auth", controller.GetManager().RegistryAuthSecret)
 
+	// alerting
+	r.Mount("/alerting", v2.alertingRouter())
+
+	return r
+}
+
+func (v2 *V2) alertingRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/rules", controller.GetManager().ListAlertRules)
+	r.Post("/rules", controller.GetManager().CreateAlertRule)
+	r.Get("/rules/{rule_id}", controller.GetManager().GetAlertRule)
+	r.Put("/rules/{rule_id}", controller.GetManager().UpdateAlertRule)
+	r.Delete("/rules/{rule_id}", controller.GetManager().DeleteAlertRule)
+	r.Get("/channels", controller.GetManager().ListAlertChannels)
+	r.Post("/channels", controller.GetManager().CreateAlertChannel)
+	r.Put("/channels/{channel_id}", controller.GetManager().UpdateAlertChannel)
+	r.Delete("/channels/{channel_id}", controller.GetManager().DeleteAlertChannel)
+	r.Post("/channels/{channel_id}/test", controller.GetManager().TestAlertChannel)
+	r.Get("/silences", controller.GetManager().ListAlertSilences)
+	r.Post("/silences", controller.GetManager().CreateAlertSilence)
+	r.Delete("/silences/{silence_id}", controller.GetManager().DeleteAlertSilence)
+	r.Get("/alerts", controller.GetManager().ListAlerts)
+	r.Put("/alerts/{hash}/ack", controller.GetManager().AcknowledgeAlert)
 	return r
 }
 
@@ -396,6 +450,37 @@ func (v2 *V2) serviceRouter() chi.Router {
 	r.Post("/xparules", middleware.WrapEL(controller.GetManager().AutoscalerRules, dbmodel.TargetTypeService, "add-app-autoscaler-rule", dbmodel.SYNEVENTTYPE))
 	r.Put("/xparules", middleware.WrapEL(controller.GetManager().AutoscalerRules, dbmodel.TargetTypeService, "update-app-autoscaler-rule", dbmodel.SYNEVENTTYPE))
 	r.Get("/xparecords", controller.GetManager().ScalingRecords)
+	r.Get("/scaling-schedules", controller.GetManager().ListScalingSchedules)
+	r.Post("/scaling-schedules", middleware.WrapEL(controller.GetManager().AddScalingSchedule, dbmodel.TargetTypeService, "add-app-scaling-schedule", dbmodel.SYNEVENTTYPE))
+	r.Put("/scaling-schedules/{schedule_id}", middleware.WrapEL(controller.GetManager().UpdScalingSchedule, dbmodel.TargetTypeService, "update-app-scaling-schedule", dbmodel.SYNEVENTTYPE))
+	r.Delete("/scaling-schedules/{schedule_id}", middleware.WrapEL(controller.GetManager().DeleteScalingSchedule, dbmodel.TargetTypeService, "delete-app-scaling-schedule", dbmodel.SYNEVENTTYPE))
+	r.Get("/scale-to-zero", controller.GetManager().GetScaleToZero)
+	r.Put("/scale-to-zero", middleware.WrapEL(controller.GetManager().UpdScaleToZero, dbmodel.TargetTypeService, "update-app-scale-to-zero", dbmodel.SYNEVENTTYPE))
+	r.Get("/resource-recommendation", controller.GetManager().GetResourceRecommendation)
+	r.Put("/resource-recommendation/policy", middleware.WrapEL(controller.GetManager().UpdRecommendationPolicy, dbmodel.TargetTypeService, "update-app-recommendation-policy", dbmodel.SYNEVENTTYPE))
+	r.Post("/resource-recommendation/apply", middleware.WrapEL(controller.GetManager().ApplyResourceRecommendation, dbmodel.TargetTypeService, "apply-app-resource-recommendation", dbmodel.ASYNEVENTTYPE))
+
+	// volume snapshots
+	r.Get("/volume-snapshots", controller.GetManager().ListVolumeSnapshots)
+	r.Post("/volume-snapshots", middleware.WrapEL(controller.GetManager().CreateVolumeSnapshot, dbmodel.TargetTypeService, "create-volume-snapshot", dbmodel.ASYNEVENTTYPE))
+	r.Post("/volume-snapshots/{snapshot_id}/restore", middleware.WrapEL(controller.GetManager().RestoreVolumeSnapshot, dbmodel.TargetTypeService, "restore-volume-snapshot", dbmodel.ASYNEVENTTYPE))
+	r.Delete("/volume-snapshots/{snapshot_id}", middleware.WrapEL(controller.GetManager().DeleteVolumeSnapshot, dbmodel.TargetTypeService, "delete-volume-snapshot", dbmodel.ASYNEVENTTYPE))
+	r.Get("/snapshot-policies", controller.GetManager().ListSnapshotPolicies)
+	r.Post("/snapshot-policies", middleware.WrapEL(controller.GetManager().AddSnapshotPolicy, dbmodel.TargetTypeService, "add-volume-snapshot-policy", dbmodel.SYNEVENTTYPE))
+	r.Put("/snapshot-policies/{policy_id}", middleware.WrapEL(controller.GetManager().UpdSnapshotPolicy, dbmodel.TargetTypeService, "update-volume-snapshot-policy", dbmodel.SYNEVENTTYPE))
+	r.Delete("/snapshot-policies/{policy_id}", middleware.WrapEL(controller.GetManager().DeleteSnapshotPolicy, dbmodel.TargetTypeService, "delete-volume-snapshot-policy", dbmodel.SYNEVENTTYPE))
+
+	// build trigger
+	r.Get("/build-trigger", controller.GetManager().GetBuildTrigger)
+	r.Put("/build-trigger", middleware.WrapEL(controller.GetManager().UpdBuildTrigger, dbmodel.TargetTypeService, "update-build-trigger", dbmodel.SYNEVENTTYPE))
+	r.Delete("/build-trigger", middleware.WrapEL(controller.GetManager().DeleteBuildTrigger, dbmodel.TargetTypeService, "delete-build-trigger", dbmodel.SYNEVENTTYPE))
+
+	// build pipeline
+	r.Get("/build-pipeline", controller.GetManager().GetBuildPipeline)
+	r.Put("/build-pipeline", middleware.WrapEL(controller.GetManager().UpdBuildPipeline, dbmodel.TargetTypeService, "update-build-pipeline", dbmodel.SYNEVENTTYPE))
+	r.Delete("/build-pipeline", middleware.WrapEL(controller.GetManager().DeleteBuildPipeline, dbmodel.TargetTypeService, "delete-build-pipeline", dbmodel.SYNEVENTTYPE))
+	r.Get("/pipeline-runs", controller.GetManager().ListPipelineRuns)
+	r.Post("/pipeline-runs/{event_id}/promote", middleware.WrapEL(controller.GetManager().PromotePipelineRun, dbmodel.TargetTypeService, "promote-version", dbmodel.ASYNEVENTTYPE))
 
 	//service monitor
 	r.Post("/service-monitors", middleware.WrapEL(controller.GetManager().AddServiceMonitors, dbmodel.TargetTypeService, "add-app-service-monitor", dbmodel.SYNEVENTTYPE))
@@ -60,3 +60,10 @@ func PackageBuildRoutes() chi.Router {
 	r.Options("/component/events/{eventID}", controller.GetManager().UploadPackage)
 	return r
 }
+
+// WebhookRoutes the webhooks of the git servers, they are verified by the secrets of the build triggers
+func WebhookRoutes() chi.Router {
+	r := chi.NewRouter()
+	r.Post("/git/{provider}", controller.GetManager().GitWebhook)
+	return r
+}
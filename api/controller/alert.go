@@ -0,0 +1,217 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/goodrain/rainbond/api/handler"
+	api_model "github.com/goodrain/rainbond/api/model"
+	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	httputil "github.com/goodrain/rainbond/util/http"
+)
+
+// AlertController user defined alert rules, channels and silences
+type AlertController struct{}
+
+// ListAlertRules lists the alert rules of the tenant
+func (a *AlertController) ListAlertRules(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	rules, err := handler.GetAlertHandler().ListAlertRules(tenantID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, rules)
+}
+
+// CreateAlertRule creates an alert rule
+func (a *AlertController) CreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	var req api_model.AlertRuleStruct
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	rule, err := handler.GetAlertHandler().CreateAlertRule(tenant, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, rule)
+}
+
+// GetAlertRule returns an alert rule
+func (a *AlertController) GetAlertRule(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	rule, err := handler.GetAlertHandler().GetAlertRule(tenantID, chi.URLParam(r, "rule_id"))
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, rule)
+}
+
+// UpdateAlertRule updates an alert rule
+func (a *AlertController) UpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	var req api_model.AlertRuleStruct
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	rule, err := handler.GetAlertHandler().UpdateAlertRule(tenant, chi.URLParam(r, "rule_id"), &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, rule)
+}
+
+// DeleteAlertRule deletes an alert rule
+func (a *AlertController) DeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	if err := handler.GetAlertHandler().DeleteAlertRule(tenant, chi.URLParam(r, "rule_id")); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// ListAlertChannels lists the notification channels of the tenant
+func (a *AlertController) ListAlertChannels(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	channels, err := handler.GetAlertHandler().ListAlertChannels(tenantID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, channels)
+}
+
+// CreateAlertChannel creates a notification channel
+func (a *AlertController) CreateAlertChannel(w http.ResponseWriter, r *http.Request) {
+	var req api_model.AlertChannelStruct
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	channel, err := handler.GetAlertHandler().CreateAlertChannel(tenantID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, channel)
+}
+
+// UpdateAlertChannel updates a notification channel
+func (a *AlertController) UpdateAlertChannel(w http.ResponseWriter, r *http.Request) {
+	var req api_model.AlertChannelStruct
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	channel, err := handler.GetAlertHandler().UpdateAlertChannel(tenantID, chi.URLParam(r, "channel_id"), &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, channel)
+}
+
+// DeleteAlertChannel deletes a notification channel
+func (a *AlertController) DeleteAlertChannel(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	if err := handler.GetAlertHandler().DeleteAlertChannel(tenantID, chi.URLParam(r, "channel_id")); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// TestAlertChannel sends a test message to a notification channel
+func (a *AlertController) TestAlertChannel(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	if err := handler.GetAlertHandler().TestAlertChannel(tenantID, chi.URLParam(r, "channel_id")); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// ListAlertSilences lists the silences of the tenant
+func (a *AlertController) ListAlertSilences(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	silences, err := handler.GetAlertHandler().ListAlertSilences(tenantID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, silences)
+}
+
+// CreateAlertSilence creates a silence
+func (a *AlertController) CreateAlertSilence(w http.ResponseWriter, r *http.Request) {
+	var req api_model.AlertSilenceStruct
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	silence, err := handler.GetAlertHandler().CreateAlertSilence(tenantID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, silence)
+}
+
+// DeleteAlertSilence deletes a silence
+func (a *AlertController) DeleteAlertSilence(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	if err := handler.GetAlertHandler().DeleteAlertSilence(tenantID, chi.URLParam(r, "silence_id")); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// ListAlerts lists the alerts fired by the rules of the tenant
+func (a *AlertController) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	alerts, err := handler.GetAlertHandler().ListAlerts(tenantID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, alerts)
+}
+
+// AcknowledgeAlert acknowledges an alert
+func (a *AlertController) AcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	var req api_model.AcknowledgeAlertStruct
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	if err := handler.GetAlertHandler().AcknowledgeAlert(tenantID, chi.URLParam(r, "hash"), req.Message); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
@@ -128,7 +128,8 @@ func (a *AppStruct) ImportID(w http.ResponseWriter, r *http.Request) {
 				continue
 			}
 			ex := filepath.Ext(dir.Name())
-			if ex != ".zip" && ex != ".tar.gz" && ex != ".gz" && ex != ".tgz" {
+			// the encrypted packages are decrypted by the builder while importing
+			if ex != ".zip" && ex != ".tar.gz" && ex != ".gz" && ex != ".tgz" && ex != ".enc" {
 				continue
 			}
 			appArr = append(appArr, dir.Name())
@@ -0,0 +1,109 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/goodrain/rainbond/api/handler"
+	"github.com/goodrain/rainbond/db/dao"
+	httputil "github.com/goodrain/rainbond/util/http"
+	"github.com/sirupsen/logrus"
+)
+
+// AuditController the audit logs of the mutating api calls
+type AuditController struct{}
+
+// ListAuditLogs lists the audit logs filtered by start, end, actor and tenant_name
+func (a *AuditController) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAuditLogQuery(r.URL.Query())
+	if err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	list, err := handler.GetAuditHandler().ListAuditLogs(query, page, pageSize)
+	if err != nil {
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, list)
+}
+
+// ExportAuditLogs exports the audit logs as json lines, one record per line
+func (a *AuditController) ExportAuditLogs(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAuditLogQuery(r.URL.Query())
+	if err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", "attachment; filename=audit-logs.jsonl")
+	if err := handler.GetAuditHandler().ExportAuditLogs(query, w); err != nil {
+		// the response has been partially written, the client tells it by the broken last line
+		logrus.Errorf("export audit logs: %v", err)
+	}
+}
+
+// VerifyAuditLogs verifies the hash chain of the audit logs
+func (a *AuditController) VerifyAuditLogs(w http.ResponseWriter, r *http.Request) {
+	result, err := handler.GetAuditHandler().VerifyAuditLogs()
+	if err != nil {
+		httputil.ReturnError(r, w, 500, err.Error())
+		return
+	}
+	httputil.ReturnSuccess(r, w, result)
+}
+
+func parseAuditLogQuery(values url.Values) (*dao.AuditLogQuery, error) {
+	query := &dao.AuditLogQuery{
+		Actor:      values.Get("actor"),
+		TenantName: values.Get("tenant_name"),
+	}
+	var err error
+	if query.Start, err = parseAuditLogTime(values.Get("start")); err != nil {
+		return nil, fmt.Errorf("invalid start: %v", err)
+	}
+	if query.End, err = parseAuditLogTime(values.Get("end")); err != nil {
+		return nil, fmt.Errorf("invalid end: %v", err)
+	}
+	return query, nil
+}
+
+// parseAuditLogTime parses the time in RFC3339 or unix seconds
+func parseAuditLogTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/go-chi/chi"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/goodrain/rainbond/api/model"
 	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
 	"github.com/goodrain/rainbond/db/errors"
+	dbmodel "github.com/goodrain/rainbond/db/model"
 	httputil "github.com/goodrain/rainbond/util/http"
 )
 
@@ -48,6 +50,12 @@ func (t *TenantStruct) addAutoscalerRule(w http.ResponseWriter, r *http.Request)
 	if !ok {
 		return
 	}
+	for _, metric := range req.Metrics {
+		if err := metric.Validate(); err != nil {
+			httputil.ReturnError(r, w, 400, err.Error())
+			return
+		}
+	}
 
 	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
 	req.ServiceID = serviceID
@@ -70,6 +78,12 @@ func (t *TenantStruct) updAutoscalerRule(w http.ResponseWriter, r *http.Request)
 	if !ok {
 		return
 	}
+	for _, metric := range req.Metrics {
+		if err := metric.Validate(); err != nil {
+			httputil.ReturnError(r, w, 400, err.Error())
+			return
+		}
+	}
 
 	if err := handler.GetServiceManager().UpdAutoscalerRule(&req); err != nil {
 		if err == errors.ErrRecordAlreadyExist {
@@ -128,3 +142,159 @@ func (t *TenantStruct) listScalingRecords(w http.ResponseWriter, r *http.Request
 		"data":  records,
 	})
 }
+
+// ListScalingSchedules lists the scaling schedules of the component
+func (t *TenantStruct) ListScalingSchedules(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	schedules, err := handler.GetServiceManager().ListScalingSchedules(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, schedules)
+}
+
+// AddScalingSchedule adds a scaling schedule for the component
+func (t *TenantStruct) AddScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.ScalingScheduleReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	schedule, err := handler.GetServiceManager().AddScalingSchedule(serviceID, &req)
+	if err != nil {
+		if err == errors.ErrRecordAlreadyExist {
+			httputil.ReturnError(r, w, 400, err.Error())
+			return
+		}
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, schedule)
+}
+
+// UpdScalingSchedule updates a scaling schedule of the component
+func (t *TenantStruct) UpdScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.ScalingScheduleReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	schedule, err := handler.GetServiceManager().UpdScalingSchedule(serviceID, chi.URLParam(r, "schedule_id"), &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, schedule)
+}
+
+// DeleteScalingSchedule deletes a scaling schedule of the component
+func (t *TenantStruct) DeleteScalingSchedule(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	if err := handler.GetServiceManager().DeleteScalingSchedule(serviceID, chi.URLParam(r, "schedule_id")); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// GetScaleToZero returns the scale-to-zero setting of the component
+func (t *TenantStruct) GetScaleToZero(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	stz, err := handler.GetServiceManager().GetScaleToZero(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, stz)
+}
+
+// UpdScaleToZero enables or disables scale-to-zero of the component
+func (t *TenantStruct) UpdScaleToZero(w http.ResponseWriter, r *http.Request) {
+	var req model.ScaleToZeroReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	stz, err := handler.GetServiceManager().UpdScaleToZero(serviceID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, stz)
+}
+
+// GetResourceRecommendation returns the resources recommended for the component
+func (t *TenantStruct) GetResourceRecommendation(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	rec, err := handler.GetServiceManager().GetResourceRecommendation(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, rec)
+}
+
+// ListResourceRecommendations returns the resources recommended for the components of the tenant
+func (t *TenantStruct) ListResourceRecommendations(w http.ResponseWriter, r *http.Request) {
+	tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	recs, err := handler.GetServiceManager().ListResourceRecommendations(tenantID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, recs)
+}
+
+// UpdRecommendationPolicy updates whether the recommendation of the component is applied automatically
+func (t *TenantStruct) UpdRecommendationPolicy(w http.ResponseWriter, r *http.Request) {
+	var req model.RecommendationPolicyReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	policy, err := handler.GetServiceManager().UpdRecommendationPolicy(serviceID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, policy)
+}
+
+// ApplyResourceRecommendation applies the recommendation to the component
+func (t *TenantStruct) ApplyResourceRecommendation(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
+	sEvent := r.Context().Value(ctxutil.ContextKey("event")).(*dbmodel.ServiceEvent)
+	rec, err := handler.GetServiceManager().GetResourceRecommendation(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	if err := handler.CheckServiceResource(r.Context(), tenant, service, service.Replicas, rec.CPULimit, rec.MemoryLimit, service.ContainerGPU); err != nil {
+		httputil.ReturnResNotEnoughError(r, w, sEvent.EventID, err)
+		return
+	}
+	if _, err := handler.GetServiceManager().ApplyResourceRecommendation(r.Context(), serviceID, sEvent.EventID); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, sEvent)
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/goodrain/rainbond/api/handler"
 	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util"
 	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
 	dbmodel "github.com/goodrain/rainbond/db/model"
 	httputil "github.com/goodrain/rainbond/util/http"
@@ -83,7 +84,7 @@ func BatchOperation(w http.ResponseWriter, r *http.Request) {
 	if len(batchOpReqs) > 1024 {
 		batchOpReqs = batchOpReqs[0:1024]
 	}
-	res, err := f(r.Context(), tenant, build.Operator, batchOpReqs)
+	res, err := f(r.Context(), tenant, util.GetOperator(r.Context(), build.Operator), batchOpReqs)
 	if err != nil {
 		httputil.ReturnBcodeError(r, w, err)
 		return
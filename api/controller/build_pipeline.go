@@ -0,0 +1,92 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/goodrain/rainbond/api/handler"
+	"github.com/goodrain/rainbond/api/model"
+	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	httputil "github.com/goodrain/rainbond/util/http"
+)
+
+// GetBuildPipeline returns the build pipeline of the component
+func (t *TenantStruct) GetBuildPipeline(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	pipeline, err := handler.GetBuildPipelineHandler().GetBuildPipeline(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, pipeline)
+}
+
+// UpdBuildPipeline creates or updates the build pipeline of the component
+func (t *TenantStruct) UpdBuildPipeline(w http.ResponseWriter, r *http.Request) {
+	var req dbmodel.BuildPipeline
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
+	if err := handler.GetBuildPipelineHandler().UpdBuildPipeline(service, &req); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, &req)
+}
+
+// DeleteBuildPipeline deletes the build pipeline of the component
+func (t *TenantStruct) DeleteBuildPipeline(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	if err := handler.GetBuildPipelineHandler().DeleteBuildPipeline(serviceID); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// ListPipelineRuns lists the latest pipeline runs of the component
+func (t *TenantStruct) ListPipelineRuns(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	runs, err := handler.GetBuildPipelineHandler().ListPipelineRuns(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, runs)
+}
+
+// PromotePipelineRun approves or rejects the version waiting for the manual approval
+func (t *TenantStruct) PromotePipelineRun(w http.ResponseWriter, r *http.Request) {
+	var req model.PromotePipelineRunReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
+	eventID := r.Context().Value(ctxutil.ContextKey("event_id")).(string)
+	run, err := handler.GetBuildPipelineHandler().PromotePipelineRun(service, chi.URLParam(r, "event_id"), eventID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, run)
+}
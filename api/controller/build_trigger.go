@@ -0,0 +1,89 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/goodrain/rainbond/api/handler"
+	"github.com/goodrain/rainbond/api/model"
+	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
+	httputil "github.com/goodrain/rainbond/util/http"
+)
+
+// maxWebhookPayload the max size of the payloads of the git webhooks
+const maxWebhookPayload = 10 << 20
+
+// GetBuildTrigger returns the build trigger of the component
+func (t *TenantStruct) GetBuildTrigger(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	trigger, err := handler.GetBuildTriggerHandler().GetBuildTrigger(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, trigger)
+}
+
+// UpdBuildTrigger creates or updates the build trigger of the component
+func (t *TenantStruct) UpdBuildTrigger(w http.ResponseWriter, r *http.Request) {
+	var req model.BuildTriggerReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	trigger, err := handler.GetBuildTriggerHandler().UpdBuildTrigger(serviceID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, trigger)
+}
+
+// DeleteBuildTrigger deletes the build trigger of the component
+func (t *TenantStruct) DeleteBuildTrigger(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	if err := handler.GetBuildTriggerHandler().DeleteBuildTrigger(serviceID); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// GitWebhook receives the push and tag webhooks of the git servers and builds the triggered components,
+// the payload is authenticated by the secrets of the triggers instead of the api token.
+func (v2 *V2Routes) GitWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookPayload))
+	if err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	result, err := handler.GetBuildTriggerHandler().HandleGitWebhook(chi.URLParam(r, "provider"), r.Header, body)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, result)
+}
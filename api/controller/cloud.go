@@ -239,3 +239,36 @@ func (c *CloudManager) DeleteAPIManager(w http.ResponseWriter, r *http.Request)
 	}
 	httputil.ReturnSuccess(r, w, nil)
 }
+
+// ListAPITokens lists the issued api tokens, filtered by the tenant_name query
+func (c *CloudManager) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := handler.GetTokenIdenHandler().ListAPITokens(r.URL.Query().Get("tenant_name"))
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, tokens)
+}
+
+// CreateAPIToken issues an api token, the token is only returned once
+func (c *CloudManager) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req api_model.CreateAPITokenStruct
+	if ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil); !ok {
+		return
+	}
+	token, err := handler.GetTokenIdenHandler().CreateAPIToken(&req)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, token)
+}
+
+// RevokeAPIToken revokes an api token
+func (c *CloudManager) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	if err := handler.GetTokenIdenHandler().RevokeAPIToken(chi.URLParam(r, "token_id")); err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
@@ -130,3 +130,94 @@ func DeleteBackup(w http.ResponseWriter, r *http.Request) {
 	}
 	httputil.ReturnSuccess(r, w, nil)
 }
+
+// Migrations list the migrations of the group app
+func Migrations(w http.ResponseWriter, r *http.Request) {
+	groupID := r.FormValue("group_id")
+	if groupID == "" {
+		httputil.ReturnError(r, w, 400, "group id can not be empty")
+		return
+	}
+	list, err := handler.GetAPPBackupHandler().ListMigrations(groupID)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, list)
+}
+
+// NewMigration migrates the group app to another region, the data is presynced at first
+func NewMigration(w http.ResponseWriter, r *http.Request) {
+	var req group.AppMigrationReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req.Body, nil)
+	if !ok {
+		return
+	}
+	req.TenantID = r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
+	bean, err := handler.GetAPPBackupHandler().NewMigration(req)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
+
+// GetMigration get the migration and its progress
+func GetMigration(w http.ResponseWriter, r *http.Request) {
+	bean, err := handler.GetAPPBackupHandler().GetMigration(chi.URLParam(r, "migration_id"))
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
+
+// PresyncMigration syncs the data changed since the last round
+func PresyncMigration(w http.ResponseWriter, r *http.Request) {
+	var req group.AppMigrationSyncReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req.Body, nil)
+	if !ok {
+		return
+	}
+	bean, err := handler.GetAPPBackupHandler().PresyncMigration(chi.URLParam(r, "migration_id"), req)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
+
+// CutoverMigration stops the components and syncs the final data
+func CutoverMigration(w http.ResponseWriter, r *http.Request) {
+	var req group.AppMigrationSyncReq
+	ok := httputil.ValidatorRequestStructAndErrorResponse(r, w, &req.Body, nil)
+	if !ok {
+		return
+	}
+	bean, err := handler.GetAPPBackupHandler().CutoverMigration(chi.URLParam(r, "migration_id"), req)
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
+
+// CompleteMigration removes the gateway rules of the source region
+func CompleteMigration(w http.ResponseWriter, r *http.Request) {
+	bean, err := handler.GetAPPBackupHandler().CompleteMigration(chi.URLParam(r, "migration_id"))
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
+
+// RollbackMigration makes the group app serve in the source region again
+func RollbackMigration(w http.ResponseWriter, r *http.Request) {
+	bean, err := handler.GetAPPBackupHandler().RollbackMigration(chi.URLParam(r, "migration_id"))
+	if err != nil {
+		err.Handle(r, w)
+		return
+	}
+	httputil.ReturnSuccess(r, w, bean)
+}
@@ -33,6 +33,7 @@ type V2Manager interface {
 	Show(w http.ResponseWriter, r *http.Request)
 	Health(w http.ResponseWriter, r *http.Request)
 	AlertManagerWebHook(w http.ResponseWriter, r *http.Request)
+	GitWebhook(w http.ResponseWriter, r *http.Request)
 	Version(w http.ResponseWriter, r *http.Request)
 	api.ClusterInterface
 	api.NodesInterface
@@ -51,6 +52,8 @@ type V2Manager interface {
 	api.RegistryAuthSecretInterface
 	api.HelmInterface
 	api.RegistryInterface
+	api.AlertInterface
+	api.AuditInterface
 }
 
 var defaultV2Manager V2Manager
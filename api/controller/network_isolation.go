@@ -0,0 +1,54 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/goodrain/rainbond/api/handler"
+	api_model "github.com/goodrain/rainbond/api/model"
+	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	httputil "github.com/goodrain/rainbond/util/http"
+)
+
+// GetNetworkIsolationReport reports the connections the network isolation of the tenant blocks or would block
+func (t *TenantStruct) GetNetworkIsolationReport(w http.ResponseWriter, r *http.Request) {
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	report, err := handler.GetNetworkIsolationHandler().GetNetworkIsolationReport(tenant, r.URL.Query().Get("app_id"))
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, report)
+}
+
+// UpdateNetworkIsolation updates the network isolation mode of the tenant
+func (t *TenantStruct) UpdateNetworkIsolation(w http.ResponseWriter, r *http.Request) {
+	var req api_model.NetworkIsolation
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
+	if err := handler.GetNetworkIsolationHandler().UpdateTenantNetworkIsolation(tenant, req.Mode); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
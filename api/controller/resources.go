@@ -21,7 +21,6 @@ package controller
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
@@ -65,6 +64,8 @@ type V2Routes struct {
 	K8sAttributeController
 	HelmStruct
 	Registry
+	AlertController
+	AuditController
 }
 
 // Show test
@@ -92,19 +93,19 @@ func (v2 *V2Routes) Health(w http.ResponseWriter, r *http.Request) {
 	httputil.ReturnSuccess(r, w, map[string]string{"status": "health", "info": "api service health"})
 }
 
-// AlertManagerWebHook -
+// AlertManagerWebHook receives the alerts of alertmanager and routes the
+// alerts of user defined rules to their notification channels.
 func (v2 *V2Routes) AlertManagerWebHook(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("=======>webhook")
-	in, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		fmt.Println(err)
-		httputil.ReturnError(r, w, 400, "")
+	var req api_model.AlertmanagerWebhookStruct
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
 		return
 	}
-	fmt.Println("=====>body")
-	fmt.Println(string(in))
-	httputil.ReturnSuccess(r, w, "")
-
+	if err := handler.GetAlertHandler().HandleAlertmanagerWebhook(&req); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
 }
 
 // Version -
@@ -701,6 +702,10 @@ func (t *TenantStruct) CreateService(w http.ResponseWriter, r *http.Request) {
 			httputil.ReturnSuccess(r, w, nil)
 			return
 		}
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
 		httputil.ReturnError(r, w, 500, fmt.Sprintf("create service error, %v", err))
 		return
 	}
@@ -1250,6 +1255,11 @@ func (t *TenantStruct) AddEnv(w http.ResponseWriter, r *http.Request) {
 	envD.IsChange = envM.IsChange
 	envD.Name = envM.Name
 	envD.Scope = envM.Scope
+	envD.ExternalSecretRef = envM.ExternalSecretRef
+	if err := handler.ValidateExternalSecretEnv(&envD); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
 	if err := handler.GetServiceManager().EnvAttr("add", &envD); err != nil {
 		if err == errors.ErrRecordAlreadyExist {
 			httputil.ReturnError(r, w, 400, fmt.Sprintf("%v", err))
@@ -1300,6 +1310,11 @@ func (t *TenantStruct) UpdateEnv(w http.ResponseWriter, r *http.Request) {
 	envD.IsChange = envM.IsChange
 	envD.Name = envM.Name
 	envD.Scope = envM.Scope
+	envD.ExternalSecretRef = envM.ExternalSecretRef
+	if err := handler.ValidateExternalSecretEnv(&envD); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
 	if err := handler.GetServiceManager().EnvAttr("update", &envD); err != nil {
 		logrus.Errorf("update env error, %v", err)
 		httputil.ReturnError(r, w, 500, fmt.Sprintf("update env error, %v", err))
@@ -1784,8 +1799,15 @@ func (t *TenantStruct) AddProbe(w http.ResponseWriter, r *http.Request) {
 	tspD.SuccessThreshold = tsp.SuccessThreshold
 	tspD.TimeoutSecond = tsp.TimeoutSecond
 	tspD.FailureAction = tsp.FailureAction
+	tspD.GRPCService = tsp.GRPCService
+	tspD.GRPCTLS = tsp.GRPCTLS
+	tspD.TLSExpireDays = tsp.TLSExpireDays
 	//注意端口问题
 	if err := handler.GetServiceManager().ServiceProbe(&tspD, "add"); err != nil {
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
 		httputil.ReturnError(r, w, 500, fmt.Sprintf("add service probe error, %v", err))
 		return
 	}
@@ -1835,8 +1857,15 @@ func (t *TenantStruct) UpdateProbe(w http.ResponseWriter, r *http.Request) {
 	tspD.Scheme = tsp.Scheme
 	tspD.SuccessThreshold = tsp.SuccessThreshold
 	tspD.TimeoutSecond = tsp.TimeoutSecond
+	tspD.GRPCService = tsp.GRPCService
+	tspD.GRPCTLS = tsp.GRPCTLS
+	tspD.TLSExpireDays = tsp.TLSExpireDays
 	//注意端口问题
 	if err := handler.GetServiceManager().ServiceProbe(&tspD, "update"); err != nil {
+		if _, ok := err.(bcode.Coder); ok {
+			httputil.ReturnBcodeError(r, w, err)
+			return
+		}
 		if err.Error() == gorm.ErrRecordNotFound.Error() {
 			httputil.ReturnError(r, w, 404, fmt.Sprintf("update prob error, %v", err))
 			return
@@ -70,8 +70,8 @@ func (t *TenantStruct) StartService(w http.ResponseWriter, r *http.Request) {
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
 	sEvent := r.Context().Value(ctxutil.ContextKey("event")).(*dbmodel.ServiceEvent)
 	if service.Kind != "third_party" {
-		if err := handler.CheckTenantResource(r.Context(), tenant, service.Replicas*service.ContainerMemory); err != nil {
-			httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
+		if err := handler.CheckServiceResource(r.Context(), tenant, service, service.Replicas, service.ContainerCPU, service.ContainerMemory, service.ContainerGPU); err != nil {
+			httputil.ReturnResNotEnoughError(r, w, sEvent.EventID, err)
 			return
 		}
 	}
@@ -171,8 +171,8 @@ func (t *TenantStruct) RestartService(w http.ResponseWriter, r *http.Request) {
 
 	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
-	if err := handler.CheckTenantResource(r.Context(), tenant, service.Replicas*service.ContainerMemory); err != nil {
-		httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
+	if err := handler.CheckServiceResource(r.Context(), tenant, service, service.Replicas, service.ContainerCPU, service.ContainerMemory, service.ContainerGPU); err != nil {
+		httputil.ReturnResNotEnoughError(r, w, sEvent.EventID, err)
 		return
 	}
 
@@ -232,11 +232,19 @@ func (t *TenantStruct) VerticalService(w http.ResponseWriter, r *http.Request) {
 	}
 	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
+	cpu, memory, gpu := service.ContainerCPU, service.ContainerMemory, service.ContainerGPU
+	if cpuSet != nil {
+		cpu = *cpuSet
+	}
 	if memorySet != nil {
-		if err := handler.CheckTenantResource(r.Context(), tenant, service.Replicas*(*memorySet)); err != nil {
-			httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
-			return
-		}
+		memory = *memorySet
+	}
+	if gpuSet != nil {
+		gpu = *gpuSet
+	}
+	if err := handler.CheckServiceResource(r.Context(), tenant, service, service.Replicas, cpu, memory, gpu); err != nil {
+		httputil.ReturnResNotEnoughError(r, w, sEvent.EventID, err)
+		return
 	}
 	verticalTask := &model.VerticalScalingTaskBody{
 		TenantID:        tenantID,
@@ -290,8 +298,8 @@ func (t *TenantStruct) HorizontalService(w http.ResponseWriter, r *http.Request)
 
 	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
-	if err := handler.CheckTenantResource(r.Context(), tenant, service.ContainerMemory*int(replicas)); err != nil {
-		httputil.ReturnResNotEnough(r, w, sEvent.EventID, err.Error())
+	if err := handler.CheckServiceResource(r.Context(), tenant, service, int(replicas), service.ContainerCPU, service.ContainerMemory, service.ContainerGPU); err != nil {
+		httputil.ReturnResNotEnoughError(r, w, sEvent.EventID, err)
 		return
 	}
 
@@ -349,8 +357,8 @@ func (t *TenantStruct) BuildService(w http.ResponseWriter, r *http.Request) {
 
 	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
-	if err := handler.CheckTenantResource(r.Context(), tenant, service.Replicas*service.ContainerMemory); err != nil {
-		httputil.ReturnResNotEnough(r, w, build.EventID, err.Error())
+	if err := handler.CheckServiceResource(r.Context(), tenant, service, service.Replicas, service.ContainerCPU, service.ContainerMemory, service.ContainerGPU); err != nil {
+		httputil.ReturnResNotEnoughError(r, w, build.EventID, err)
 		return
 	}
 
@@ -599,8 +607,8 @@ func (t *TenantStruct) UpgradeService(w http.ResponseWriter, r *http.Request) {
 	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
 	if service.Kind != "third_party" {
-		if err := handler.CheckTenantResource(r.Context(), tenant, service.Replicas*service.ContainerMemory); err != nil {
-			httputil.ReturnResNotEnough(r, w, upgradeRequest.EventID, err.Error())
+		if err := handler.CheckServiceResource(r.Context(), tenant, service, service.Replicas, service.ContainerCPU, service.ContainerMemory, service.ContainerGPU); err != nil {
+			httputil.ReturnResNotEnoughError(r, w, upgradeRequest.EventID, err)
 			return
 		}
 	}
@@ -692,8 +700,8 @@ func (t *TenantStruct) RollBack(w http.ResponseWriter, r *http.Request) {
 
 	tenant := r.Context().Value(ctxutil.ContextKey("tenant")).(*dbmodel.Tenants)
 	service := r.Context().Value(ctxutil.ContextKey("service")).(*dbmodel.TenantServices)
-	if err := handler.CheckTenantResource(r.Context(), tenant, service.Replicas*service.ContainerMemory); err != nil {
-		httputil.ReturnResNotEnough(r, w, rollbackRequest.EventID, err.Error())
+	if err := handler.CheckServiceResource(r.Context(), tenant, service, service.Replicas, service.ContainerCPU, service.ContainerMemory, service.ContainerGPU); err != nil {
+		httputil.ReturnResNotEnoughError(r, w, rollbackRequest.EventID, err)
 		return
 	}
 
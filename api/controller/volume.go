@@ -173,7 +173,7 @@ func (t *TenantStruct) AddVolume(w http.ResponseWriter, r *http.Request) {
 		httputil.ReturnError(r, w, 400, "volume path is invalid,must begin with /")
 		return
 	}
-	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, "", "add"); err != nil {
+	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, nil, "add"); err != nil {
 		err.Handle(r, w)
 		return
 	}
@@ -233,7 +233,7 @@ func (t *TenantStruct) DeleteVolume(w http.ResponseWriter, r *http.Request) {
 		VolumePath: avs.Body.VolumePath,
 		Category:   avs.Body.Category,
 	}
-	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, "", "delete"); err != nil {
+	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, nil, "delete"); err != nil {
 		err.Handle(r, w)
 		return
 	}
@@ -388,7 +388,15 @@ func AddVolume(w http.ResponseWriter, r *http.Request) {
 		httputil.ReturnError(r, w, 400, "volume path is invalid,must begin with /")
 		return
 	}
-	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, avs.Body.FileContent, "add"); err != nil {
+	configFile := &dbmodel.TenantServiceConfigFile{
+		FileContent:       avs.Body.FileContent,
+		ExternalSecretRef: avs.Body.ExternalSecretRef,
+	}
+	if err := handler.ValidateExternalSecretConfigFile(configFile); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, configFile, "add"); err != nil {
 		err.Handle(r, w)
 		return
 	}
@@ -423,7 +431,7 @@ func DeleteVolume(w http.ResponseWriter, r *http.Request) {
 	tsv := &dbmodel.TenantServiceVolume{}
 	tsv.ServiceID = serviceID
 	tsv.VolumeName = chi.URLParam(r, "volume_name")
-	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, "", "delete"); err != nil {
+	if err := handler.GetServiceManager().VolumnVar(tsv, tenantID, nil, "delete"); err != nil {
 		err.Handle(r, w)
 		return
 	}
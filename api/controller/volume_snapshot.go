@@ -0,0 +1,141 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package controller
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/goodrain/rainbond/api/handler"
+	"github.com/goodrain/rainbond/api/model"
+	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
+	httputil "github.com/goodrain/rainbond/util/http"
+)
+
+// ListVolumeSnapshots lists the volume snapshots of the component
+func (t *TenantStruct) ListVolumeSnapshots(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	snapshots, err := handler.GetServiceManager().ListVolumeSnapshots(serviceID, r.URL.Query().Get("volume_name"))
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, snapshots)
+}
+
+// CreateVolumeSnapshot takes a snapshot of a volume of the component
+func (t *TenantStruct) CreateVolumeSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateVolumeSnapshotReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	eventID := r.Context().Value(ctxutil.ContextKey("event_id")).(string)
+	snapshot, err := handler.GetServiceManager().CreateVolumeSnapshot(serviceID, eventID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, snapshot)
+}
+
+// RestoreVolumeSnapshot restores a volume snapshot of the component
+func (t *TenantStruct) RestoreVolumeSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req model.RestoreVolumeSnapshotReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	eventID := r.Context().Value(ctxutil.ContextKey("event_id")).(string)
+	if err := handler.GetServiceManager().RestoreVolumeSnapshot(serviceID, chi.URLParam(r, "snapshot_id"), eventID, &req); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// DeleteVolumeSnapshot deletes a volume snapshot of the component
+func (t *TenantStruct) DeleteVolumeSnapshot(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	eventID := r.Context().Value(ctxutil.ContextKey("event_id")).(string)
+	if err := handler.GetServiceManager().DeleteVolumeSnapshot(serviceID, chi.URLParam(r, "snapshot_id"), eventID); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
+
+// ListSnapshotPolicies lists the snapshot policies of the component
+func (t *TenantStruct) ListSnapshotPolicies(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	policies, err := handler.GetServiceManager().ListSnapshotPolicies(serviceID)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, policies)
+}
+
+// AddSnapshotPolicy adds a snapshot policy for the component
+func (t *TenantStruct) AddSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	var req model.SnapshotPolicyReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	policy, err := handler.GetServiceManager().AddSnapshotPolicy(serviceID, &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, policy)
+}
+
+// UpdSnapshotPolicy updates a snapshot policy of the component
+func (t *TenantStruct) UpdSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	var req model.SnapshotPolicyReq
+	if !httputil.ValidatorRequestStructAndErrorResponse(r, w, &req, nil) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		httputil.ReturnError(r, w, 400, err.Error())
+		return
+	}
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	policy, err := handler.GetServiceManager().UpdSnapshotPolicy(serviceID, chi.URLParam(r, "policy_id"), &req)
+	if err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, policy)
+}
+
+// DeleteSnapshotPolicy deletes a snapshot policy of the component
+func (t *TenantStruct) DeleteSnapshotPolicy(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.Context().Value(ctxutil.ContextKey("service_id")).(string)
+	if err := handler.GetServiceManager().DeleteSnapshotPolicy(serviceID, chi.URLParam(r, "policy_id")); err != nil {
+		httputil.ReturnBcodeError(r, w, err)
+		return
+	}
+	httputil.ReturnSuccess(r, w, nil)
+}
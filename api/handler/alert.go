@@ -0,0 +1,870 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	apimodel "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/notify"
+	"github.com/jinzhu/gorm"
+	mv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
+	"github.com/sirupsen/logrus"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/rest"
+)
+
+// AlertHandler user defined alert rules, notification channels and silences
+type AlertHandler interface {
+	CreateAlertRule(tenant *dbmodel.Tenants, req *apimodel.AlertRuleStruct) (*dbmodel.TenantAlertRule, error)
+	UpdateAlertRule(tenant *dbmodel.Tenants, ruleID string, req *apimodel.AlertRuleStruct) (*dbmodel.TenantAlertRule, error)
+	DeleteAlertRule(tenant *dbmodel.Tenants, ruleID string) error
+	GetAlertRule(tenantID, ruleID string) (*dbmodel.TenantAlertRule, error)
+	ListAlertRules(tenantID string) ([]*dbmodel.TenantAlertRule, error)
+
+	CreateAlertChannel(tenantID string, req *apimodel.AlertChannelStruct) (*dbmodel.TenantAlertChannel, error)
+	UpdateAlertChannel(tenantID, channelID string, req *apimodel.AlertChannelStruct) (*dbmodel.TenantAlertChannel, error)
+	DeleteAlertChannel(tenantID, channelID string) error
+	ListAlertChannels(tenantID string) ([]*dbmodel.TenantAlertChannel, error)
+	TestAlertChannel(tenantID, channelID string) error
+
+	CreateAlertSilence(tenantID string, req *apimodel.AlertSilenceStruct) (*dbmodel.TenantAlertSilence, error)
+	DeleteAlertSilence(tenantID, silenceID string) error
+	ListAlertSilences(tenantID string) ([]*dbmodel.TenantAlertSilence, error)
+
+	ListAlerts(tenantID string) ([]*dbmodel.NotificationEvent, error)
+	AcknowledgeAlert(tenantID, hash, message string) error
+	HandleAlertmanagerWebhook(req *apimodel.AlertmanagerWebhookStruct) error
+}
+
+// AlertAction implements AlertHandler
+type AlertAction struct {
+	monitorClient versioned.Interface
+}
+
+// NewAlertHandler creates a new AlertHandler
+func NewAlertHandler(config *rest.Config) AlertHandler {
+	a := &AlertAction{}
+	if config != nil {
+		monitorClient, err := versioned.NewForConfig(config)
+		if err != nil {
+			logrus.Errorf("create prometheus operator client failure: %v", err)
+		} else {
+			a.monitorClient = monitorClient
+		}
+	}
+	return a
+}
+
+// CreateAlertRule creates an alert rule and renders it into a PrometheusRule
+func (a *AlertAction) CreateAlertRule(tenant *dbmodel.Tenants, req *apimodel.AlertRuleStruct) (*dbmodel.TenantAlertRule, error) {
+	rule := &dbmodel.TenantAlertRule{
+		RuleID:   util.NewUUID(),
+		TenantID: tenant.UUID,
+	}
+	if err := a.fillAlertRule(tenant.UUID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := db.GetManager().TenantAlertRuleDao().AddModel(rule); err != nil {
+		return nil, err
+	}
+	if err := a.syncPrometheusRule(tenant, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateAlertRule updates an alert rule
+func (a *AlertAction) UpdateAlertRule(tenant *dbmodel.Tenants, ruleID string, req *apimodel.AlertRuleStruct) (*dbmodel.TenantAlertRule, error) {
+	rule, err := a.GetAlertRule(tenant.UUID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.fillAlertRule(tenant.UUID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := db.GetManager().TenantAlertRuleDao().UpdateModel(rule); err != nil {
+		return nil, err
+	}
+	if err := a.syncPrometheusRule(tenant, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteAlertRule deletes an alert rule and its PrometheusRule
+func (a *AlertAction) DeleteAlertRule(tenant *dbmodel.Tenants, ruleID string) error {
+	rule, err := a.GetAlertRule(tenant.UUID, ruleID)
+	if err != nil {
+		return err
+	}
+	if err := a.deletePrometheusRule(tenant.Namespace, rule.RuleID); err != nil {
+		return err
+	}
+	return db.GetManager().TenantAlertRuleDao().DeleteByRuleID(rule.RuleID)
+}
+
+// GetAlertRule returns the alert rule of the tenant
+func (a *AlertAction) GetAlertRule(tenantID, ruleID string) (*dbmodel.TenantAlertRule, error) {
+	rule, err := db.GetManager().TenantAlertRuleDao().GetByRuleID(ruleID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrAlertRuleNotFound
+		}
+		return nil, err
+	}
+	if rule.TenantID != tenantID {
+		return nil, bcode.ErrAlertRuleNotFound
+	}
+	return rule, nil
+}
+
+// ListAlertRules lists the alert rules of the tenant
+func (a *AlertAction) ListAlertRules(tenantID string) ([]*dbmodel.TenantAlertRule, error) {
+	return db.GetManager().TenantAlertRuleDao().ListByTenantID(tenantID)
+}
+
+func (a *AlertAction) fillAlertRule(tenantID string, rule *dbmodel.TenantAlertRule, req *apimodel.AlertRuleStruct) error {
+	switch dbmodel.AlertRuleScope(req.Scope) {
+	case dbmodel.AlertRuleScopeComponent:
+		if req.ServiceID == "" {
+			return bcode.NewBadRequest("service_id is required by component scope")
+		}
+		service, err := db.GetManager().TenantServiceDao().GetServiceByID(req.ServiceID)
+		if err != nil || service.TenantID != tenantID {
+			return bcode.ErrServiceNotFound
+		}
+		req.AppID = service.AppID
+	case dbmodel.AlertRuleScopeApp:
+		if req.AppID == "" {
+			return bcode.NewBadRequest("app_id is required by app scope")
+		}
+		app, err := db.GetManager().ApplicationDao().GetAppByID(req.AppID)
+		if err != nil || app.TenantID != tenantID {
+			return bcode.ErrApplicationNotFound
+		}
+		req.ServiceID = ""
+	}
+	if dbmodel.AlertMetricType(req.MetricType) == dbmodel.AlertMetricCustom && strings.TrimSpace(req.Expr) == "" {
+		return bcode.NewBadRequest("expr is required by custom metric")
+	}
+	if req.Duration == "" {
+		req.Duration = "1m"
+	}
+	if _, err := time.ParseDuration(req.Duration); err != nil {
+		return bcode.NewBadRequest("invalid duration " + req.Duration)
+	}
+	if req.Severity == "" {
+		req.Severity = "warning"
+	}
+	if len(req.Channels) > 0 {
+		channels, err := db.GetManager().TenantAlertChannelDao().ListByChannelIDs(req.Channels)
+		if err != nil {
+			return err
+		}
+		if len(channels) != len(req.Channels) {
+			return bcode.ErrAlertChannelNotFound
+		}
+		for _, channel := range channels {
+			if channel.TenantID != tenantID {
+				return bcode.ErrAlertChannelNotFound
+			}
+		}
+	}
+	rule.Name = req.Name
+	rule.Scope = req.Scope
+	rule.AppID = req.AppID
+	rule.ServiceID = req.ServiceID
+	rule.MetricType = req.MetricType
+	rule.Expr = req.Expr
+	rule.Operator = req.Operator
+	rule.Threshold = req.Threshold
+	rule.Duration = req.Duration
+	rule.Severity = req.Severity
+	rule.Summary = req.Summary
+	rule.ChannelIDs = strings.Join(req.Channels, ",")
+	rule.Enable = req.Enable
+	return nil
+}
+
+func prometheusRuleName(ruleID string) string {
+	return "rbd-alert-" + ruleID
+}
+
+func alertName(ruleID string) string {
+	return "RainbondAlert_" + ruleID
+}
+
+// alertRuleExpr renders the promql expression of the rule, the components are
+// the components the rule applies to.
+func alertRuleExpr(rule *dbmodel.TenantAlertRule, namespace string, components []*dbmodel.TenantServices) (string, error) {
+	var ids, workloads []string
+	for _, component := range components {
+		ids = append(ids, component.ServiceID)
+		name := component.K8sComponentName
+		if name == "" {
+			name = component.ServiceAlias
+		}
+		workloads = append(workloads, name)
+	}
+	if len(ids) == 0 && dbmodel.AlertMetricType(rule.MetricType) != dbmodel.AlertMetricCustom {
+		return "", fmt.Errorf("no component matches the rule")
+	}
+	selector := fmt.Sprintf(`service_id=~"%s"`, strings.Join(ids, "|"))
+	var expr string
+	switch dbmodel.AlertMetricType(rule.MetricType) {
+	case dbmodel.AlertMetricCPU:
+		expr = fmt.Sprintf(`sum by (service_id) (rate(container_cpu_usage_seconds_total{%s}[2m])) * 1000`, selector)
+	case dbmodel.AlertMetricMemory:
+		expr = fmt.Sprintf(`sum by (service_id) (container_memory_working_set_bytes{%s}) / 1024 / 1024`, selector)
+	case dbmodel.AlertMetricRestart:
+		expr = fmt.Sprintf(`sum by (namespace) (increase(kube_pod_container_status_restarts_total{namespace="%s",pod=~"(%s)-.*"}[10m]))`,
+			namespace, strings.Join(workloads, "|"))
+	case dbmodel.AlertMetricHTTP5xx:
+		expr = fmt.Sprintf(`sum by (service_id) (rate(gateway_requests{%s,status=~"5.."}[5m]))`, selector)
+	case dbmodel.AlertMetricCustom:
+		scoped, err := scopePromQL(strings.TrimSpace(rule.Expr), namespace)
+		if err != nil {
+			return "", err
+		}
+		expr = "(" + scoped + ")"
+	default:
+		return "", fmt.Errorf("metric type %s is not supported", rule.MetricType)
+	}
+	return fmt.Sprintf("%s %s %s", expr, rule.Operator, strconv.FormatFloat(rule.Threshold, 'f', -1, 64)), nil
+}
+
+// promqlKeywords the identifiers of promql which are not metric names
+var promqlKeywords = map[string]bool{
+	"and": true, "or": true, "unless": true, "bool": true, "offset": true, "atan2": true,
+	"inf": true, "nan": true,
+}
+
+// promqlGroupings the keywords followed by a list of label names
+var promqlGroupings = map[string]bool{
+	"by": true, "without": true, "on": true, "ignoring": true, "group_left": true, "group_right": true,
+}
+
+// scopePromQL adds the namespace matcher to every vector selector of the expression,
+// so that the custom rule only sees the metrics of the tenant.
+func scopePromQL(expr, namespace string) (string, error) {
+	matcher := fmt.Sprintf(`namespace="%s"`, namespace)
+	var out strings.Builder
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			end, err := skipPromQLString(expr, i)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(expr[i:end])
+			i = end
+		case c == '#':
+			end := strings.IndexByte(expr[i:], '\n')
+			if end < 0 {
+				end = len(expr) - i
+			}
+			i += end
+		case c == '[':
+			// range or subquery durations
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unclosed [ in expression")
+			}
+			out.WriteString(expr[i : i+end+1])
+			i += end + 1
+		case c == '{':
+			end, err := writeScopedMatchers(&out, expr, i, matcher)
+			if err != nil {
+				return "", err
+			}
+			i = end
+		case c >= '0' && c <= '9' || c == '.':
+			// numbers and durations
+			j := i
+			for j < len(expr) && (isPromQLIdentChar(expr[j]) || expr[j] == '.') {
+				j++
+			}
+			out.WriteString(expr[i:j])
+			i = j
+		case isPromQLIdentChar(c):
+			j := i
+			for j < len(expr) && isPromQLIdentChar(expr[j]) {
+				j++
+			}
+			ident := expr[i:j]
+			out.WriteString(ident)
+			next := j
+			for next < len(expr) && (expr[next] == ' ' || expr[next] == '\t' || expr[next] == '\n' || expr[next] == '\r') {
+				next++
+			}
+			i = j
+			lower := strings.ToLower(ident)
+			switch {
+			case promqlGroupings[lower]:
+				// the label names are not metric names
+				if next < len(expr) && expr[next] == '(' {
+					end := strings.IndexByte(expr[next:], ')')
+					if end < 0 {
+						return "", fmt.Errorf("unclosed ( in expression")
+					}
+					out.WriteString(expr[j : next+end+1])
+					i = next + end + 1
+				}
+			case promqlKeywords[lower]:
+			case next < len(expr) && expr[next] == '(':
+				// functions and aggregations
+			case next < len(expr) && expr[next] == '{':
+				out.WriteString(expr[j:next])
+				end, err := writeScopedMatchers(&out, expr, next, matcher)
+				if err != nil {
+					return "", err
+				}
+				i = end
+			case next < len(expr) && isAggregationGrouping(leadingIdent(expr[next:])):
+				// aggregations with the grouping clause first, such as sum by (label) (...)
+			default:
+				out.WriteString("{" + matcher + "}")
+			}
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String(), nil
+}
+
+// writeScopedMatchers writes the matchers starting at the { with the namespace matcher added,
+// it returns the position after the }.
+func writeScopedMatchers(out *strings.Builder, expr string, start int, matcher string) (int, error) {
+	i := start + 1
+	for i < len(expr) && expr[i] != '}' {
+		if c := expr[i]; c == '"' || c == '\'' || c == '`' {
+			end, err := skipPromQLString(expr, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+	if i >= len(expr) {
+		return 0, fmt.Errorf("unclosed { in expression")
+	}
+	inner := strings.TrimSpace(expr[start+1 : i])
+	if inner == "" {
+		out.WriteString("{" + matcher + "}")
+	} else {
+		out.WriteString("{" + matcher + "," + inner + "}")
+	}
+	return i + 1, nil
+}
+
+func skipPromQLString(expr string, start int) (int, error) {
+	quote := expr[start]
+	for i := start + 1; i < len(expr); i++ {
+		if expr[i] == '\\' && quote != '`' {
+			i++
+			continue
+		}
+		if expr[i] == quote {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unclosed string in expression")
+}
+
+func isPromQLIdentChar(c byte) bool {
+	return c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isAggregationGrouping(ident string) bool {
+	ident = strings.ToLower(ident)
+	return ident == "by" || ident == "without"
+}
+
+func leadingIdent(s string) string {
+	i := 0
+	for i < len(s) && isPromQLIdentChar(s[i]) {
+		i++
+	}
+	return s[:i]
+}
+
+func (a *AlertAction) ruleComponents(rule *dbmodel.TenantAlertRule) ([]*dbmodel.TenantServices, error) {
+	if dbmodel.AlertRuleScope(rule.Scope) == dbmodel.AlertRuleScopeApp {
+		return db.GetManager().TenantServiceDao().ListByAppID(rule.AppID)
+	}
+	if rule.ServiceID == "" {
+		return nil, nil
+	}
+	component, err := db.GetManager().TenantServiceDao().GetServiceByID(rule.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	return []*dbmodel.TenantServices{component}, nil
+}
+
+func (a *AlertAction) buildPrometheusRule(tenant *dbmodel.Tenants, rule *dbmodel.TenantAlertRule) (*mv1.PrometheusRule, error) {
+	components, err := a.ruleComponents(rule)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := alertRuleExpr(rule, tenant.Namespace, components)
+	if err != nil {
+		return nil, bcode.NewBadRequest(err.Error())
+	}
+	summary := rule.Summary
+	if summary == "" {
+		summary = fmt.Sprintf("%s %s %s %s", rule.Name, rule.MetricType, rule.Operator, strconv.FormatFloat(rule.Threshold, 'f', -1, 64))
+	}
+	return &mv1.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prometheusRuleName(rule.RuleID),
+			Namespace: tenant.Namespace,
+			Labels: map[string]string{
+				"creator":   "Rainbond",
+				"tenant_id": tenant.UUID,
+				"rule_id":   rule.RuleID,
+			},
+		},
+		Spec: mv1.PrometheusRuleSpec{
+			Groups: []mv1.RuleGroup{
+				{
+					Name: prometheusRuleName(rule.RuleID),
+					Rules: []mv1.Rule{
+						{
+							Alert: alertName(rule.RuleID),
+							Expr:  intstr.FromString(expr),
+							For:   rule.Duration,
+							Labels: map[string]string{
+								"rule_id":   rule.RuleID,
+								"tenant_id": tenant.UUID,
+								"severity":  rule.Severity,
+							},
+							Annotations: map[string]string{
+								"rule_name":   rule.Name,
+								"summary":     summary,
+								"description": rule.Name + ": current value is {{ $value }}",
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (a *AlertAction) syncPrometheusRule(tenant *dbmodel.Tenants, rule *dbmodel.TenantAlertRule) error {
+	if !rule.Enable {
+		return a.deletePrometheusRule(tenant.Namespace, rule.RuleID)
+	}
+	if a.monitorClient == nil {
+		return fmt.Errorf("prometheus operator client is not ready")
+	}
+	promRule, err := a.buildPrometheusRule(tenant, rule)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	rules := a.monitorClient.MonitoringV1().PrometheusRules(tenant.Namespace)
+	old, err := rules.Get(ctx, promRule.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			return err
+		}
+		_, err = rules.Create(ctx, promRule, metav1.CreateOptions{})
+		return err
+	}
+	promRule.ResourceVersion = old.ResourceVersion
+	_, err = rules.Update(ctx, promRule, metav1.UpdateOptions{})
+	return err
+}
+
+func (a *AlertAction) deletePrometheusRule(namespace, ruleID string) error {
+	if a.monitorClient == nil {
+		return nil
+	}
+	err := a.monitorClient.MonitoringV1().PrometheusRules(namespace).Delete(context.Background(), prometheusRuleName(ruleID), metav1.DeleteOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// CreateAlertChannel creates a notification channel
+func (a *AlertAction) CreateAlertChannel(tenantID string, req *apimodel.AlertChannelStruct) (*dbmodel.TenantAlertChannel, error) {
+	if err := req.Config.Validate(req.Type); err != nil {
+		return nil, bcode.NewBadRequest(err.Error())
+	}
+	config, _ := json.Marshal(req.Config)
+	channel := &dbmodel.TenantAlertChannel{
+		ChannelID: util.NewUUID(),
+		TenantID:  tenantID,
+		Name:      req.Name,
+		Type:      req.Type,
+		Config:    string(config),
+		Enable:    req.Enable,
+	}
+	if err := db.GetManager().TenantAlertChannelDao().AddModel(channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// UpdateAlertChannel updates a notification channel
+func (a *AlertAction) UpdateAlertChannel(tenantID, channelID string, req *apimodel.AlertChannelStruct) (*dbmodel.TenantAlertChannel, error) {
+	channel, err := a.getAlertChannel(tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.Type == req.Type {
+		keepChannelSecrets(channel, &req.Config)
+	}
+	if err := req.Config.Validate(req.Type); err != nil {
+		return nil, bcode.NewBadRequest(err.Error())
+	}
+	config, _ := json.Marshal(req.Config)
+	channel.Name = req.Name
+	channel.Type = req.Type
+	channel.Config = string(config)
+	channel.Enable = req.Enable
+	if err := db.GetManager().TenantAlertChannelDao().UpdateModel(channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// keepChannelSecrets fills the secrets omitted by the request from the stored config. The config
+// is never returned to the clients, so they can not send the secrets back.
+func keepChannelSecrets(channel *dbmodel.TenantAlertChannel, cfg *notify.Config) {
+	var old notify.Config
+	if err := json.Unmarshal([]byte(channel.Config), &old); err != nil {
+		logrus.Warningf("parse config of channel %s: %v", channel.ChannelID, err)
+		return
+	}
+	if cfg.URL == "" {
+		cfg.URL = old.URL
+	}
+	if cfg.Secret == "" {
+		cfg.Secret = old.Secret
+	}
+	if cfg.Password == "" {
+		cfg.Password = old.Password
+	}
+	if cfg.Headers == nil {
+		cfg.Headers = old.Headers
+	}
+}
+
+// DeleteAlertChannel deletes a notification channel which is not used by any rule
+func (a *AlertAction) DeleteAlertChannel(tenantID, channelID string) error {
+	channel, err := a.getAlertChannel(tenantID, channelID)
+	if err != nil {
+		return err
+	}
+	rules, err := db.GetManager().TenantAlertRuleDao().ListByTenantID(tenantID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		for _, id := range strings.Split(rule.ChannelIDs, ",") {
+			if id == channel.ChannelID {
+				return bcode.ErrAlertChannelInUse
+			}
+		}
+	}
+	return db.GetManager().TenantAlertChannelDao().DeleteByChannelID(channel.ChannelID)
+}
+
+// ListAlertChannels lists the notification channels of the tenant
+func (a *AlertAction) ListAlertChannels(tenantID string) ([]*dbmodel.TenantAlertChannel, error) {
+	return db.GetManager().TenantAlertChannelDao().ListByTenantID(tenantID)
+}
+
+// TestAlertChannel sends a test message to the channel
+func (a *AlertAction) TestAlertChannel(tenantID, channelID string) error {
+	channel, err := a.getAlertChannel(tenantID, channelID)
+	if err != nil {
+		return err
+	}
+	n, err := channelNotifier(channel)
+	if err != nil {
+		return bcode.NewBadRequest(err.Error())
+	}
+	msg := &notify.Message{
+		RuleName: "test",
+		Status:   "firing",
+		Severity: "info",
+		Summary:  "This is a test message from rainbond, channel " + channel.Name,
+		StartsAt: time.Now(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := n.Notify(ctx, msg); err != nil {
+		return bcode.NewBadRequest(err.Error())
+	}
+	return nil
+}
+
+func (a *AlertAction) getAlertChannel(tenantID, channelID string) (*dbmodel.TenantAlertChannel, error) {
+	channel, err := db.GetManager().TenantAlertChannelDao().GetByChannelID(channelID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrAlertChannelNotFound
+		}
+		return nil, err
+	}
+	if channel.TenantID != tenantID {
+		return nil, bcode.ErrAlertChannelNotFound
+	}
+	return channel, nil
+}
+
+func channelNotifier(channel *dbmodel.TenantAlertChannel) (notify.Notifier, error) {
+	var cfg notify.Config
+	if err := json.Unmarshal([]byte(channel.Config), &cfg); err != nil {
+		return nil, fmt.Errorf("parse config of channel %s: %v", channel.Name, err)
+	}
+	return notify.New(channel.Type, &cfg)
+}
+
+// CreateAlertSilence creates a silence for a rule
+func (a *AlertAction) CreateAlertSilence(tenantID string, req *apimodel.AlertSilenceStruct) (*dbmodel.TenantAlertSilence, error) {
+	if _, err := a.GetAlertRule(tenantID, req.RuleID); err != nil {
+		return nil, err
+	}
+	if req.StartsAt.IsZero() {
+		req.StartsAt = time.Now()
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, bcode.NewBadRequest("ends_at must be after starts_at")
+	}
+	silence := &dbmodel.TenantAlertSilence{
+		SilenceID: util.NewUUID(),
+		TenantID:  tenantID,
+		RuleID:    req.RuleID,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		Comment:   req.Comment,
+		CreatedBy: req.CreatedBy,
+	}
+	if err := db.GetManager().TenantAlertSilenceDao().AddModel(silence); err != nil {
+		return nil, err
+	}
+	return silence, nil
+}
+
+// DeleteAlertSilence deletes a silence, the notifications are resumed
+func (a *AlertAction) DeleteAlertSilence(tenantID, silenceID string) error {
+	silences, err := db.GetManager().TenantAlertSilenceDao().ListByTenantID(tenantID)
+	if err != nil {
+		return err
+	}
+	for _, silence := range silences {
+		if silence.SilenceID == silenceID {
+			return db.GetManager().TenantAlertSilenceDao().DeleteBySilenceID(silenceID)
+		}
+	}
+	return bcode.NotFound
+}
+
+// ListAlertSilences lists the silences of the tenant
+func (a *AlertAction) ListAlertSilences(tenantID string) ([]*dbmodel.TenantAlertSilence, error) {
+	return db.GetManager().TenantAlertSilenceDao().ListByTenantID(tenantID)
+}
+
+// ListAlerts lists the alerts fired by the rules of the tenant
+func (a *AlertAction) ListAlerts(tenantID string) ([]*dbmodel.NotificationEvent, error) {
+	rules, err := db.GetManager().TenantAlertRuleDao().ListByTenantID(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	var alerts []*dbmodel.NotificationEvent
+	for _, rule := range rules {
+		events, err := db.GetManager().NotificationEventDao().GetNotificationEventByKind(dbmodel.NotificationEventKindAlert, rule.RuleID)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, events...)
+	}
+	return alerts, nil
+}
+
+// AcknowledgeAlert marks the alert as handled, no more notifications are sent until it resolves
+func (a *AlertAction) AcknowledgeAlert(tenantID, hash, message string) error {
+	event, err := db.GetManager().NotificationEventDao().GetNotificationEventByHash(hash)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return bcode.ErrAlertNotFound
+		}
+		return err
+	}
+	if event.Kind != dbmodel.NotificationEventKindAlert {
+		return bcode.ErrAlertNotFound
+	}
+	if _, err := a.GetAlertRule(tenantID, event.KindID); err != nil {
+		return bcode.ErrAlertNotFound
+	}
+	event.IsHandle = true
+	event.HandleMessage = message
+	return db.GetManager().NotificationEventDao().UpdateModel(event)
+}
+
+// HandleAlertmanagerWebhook records the alerts sent by alertmanager and routes
+// them to the channels of their rules.
+func (a *AlertAction) HandleAlertmanagerWebhook(req *apimodel.AlertmanagerWebhookStruct) error {
+	for _, alert := range req.Alerts {
+		ruleID := alert.Labels["rule_id"]
+		if ruleID == "" {
+			// platform alerts are not routed to tenant channels
+			continue
+		}
+		rule, err := db.GetManager().TenantAlertRuleDao().GetByRuleID(ruleID)
+		if err != nil {
+			logrus.Warningf("alert rule %s of alert %s not found: %v", ruleID, alert.Fingerprint, err)
+			continue
+		}
+		notifyChannels, err := a.recordAlert(rule, alert)
+		if err != nil {
+			logrus.Errorf("record alert %s: %v", alert.Fingerprint, err)
+			continue
+		}
+		if !notifyChannels {
+			continue
+		}
+		a.dispatch(rule, alert)
+	}
+	return nil
+}
+
+// recordAlert saves the alert as notification event, it returns whether the
+// channels of the rule should be notified.
+func (a *AlertAction) recordAlert(rule *dbmodel.TenantAlertRule, alert apimodel.AlertmanagerAlert) (bool, error) {
+	hash := alert.Fingerprint
+	if hash == "" {
+		hash, _ = util.CreateHashString(rule.RuleID + alert.StartsAt.String())
+	}
+	dao := db.GetManager().NotificationEventDao()
+	event, err := dao.GetNotificationEventByHash(hash)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+	now := time.Now()
+	eventType := "UnNormal"
+	if alert.Status == "resolved" {
+		eventType = "Normal"
+	}
+	message := alert.Annotations["summary"]
+	if len(message) > 200 {
+		message = message[:200]
+	}
+	if event == nil || event.ID == 0 {
+		event = &dbmodel.NotificationEvent{
+			Kind:      dbmodel.NotificationEventKindAlert,
+			KindID:    rule.RuleID,
+			Hash:      hash,
+			Type:      eventType,
+			Message:   message,
+			Reason:    rule.Name,
+			Count:     1,
+			FirstTime: alert.StartsAt,
+			LastTime:  now,
+		}
+		if err := dao.AddModel(event); err != nil {
+			return false, err
+		}
+	} else {
+		acknowledged := event.IsHandle
+		if event.Type != eventType && eventType == "UnNormal" {
+			// fired again after resolved
+			acknowledged = false
+			event.HandleMessage = ""
+		}
+		event.Type = eventType
+		event.Message = message
+		event.Count++
+		event.LastTime = now
+		event.IsHandle = acknowledged
+		if err := dao.UpdateModel(event); err != nil {
+			return false, err
+		}
+		if acknowledged && eventType == "UnNormal" {
+			return false, nil
+		}
+	}
+	silences, err := db.GetManager().TenantAlertSilenceDao().ListActiveByRuleID(rule.RuleID, now)
+	if err != nil {
+		return false, err
+	}
+	return len(silences) == 0, nil
+}
+
+func (a *AlertAction) dispatch(rule *dbmodel.TenantAlertRule, alert apimodel.AlertmanagerAlert) {
+	if rule.ChannelIDs == "" {
+		return
+	}
+	channels, err := db.GetManager().TenantAlertChannelDao().ListByChannelIDs(strings.Split(rule.ChannelIDs, ","))
+	if err != nil {
+		logrus.Errorf("list channels of alert rule %s: %v", rule.RuleID, err)
+		return
+	}
+	msg := &notify.Message{
+		RuleID:      rule.RuleID,
+		RuleName:    rule.Name,
+		Status:      alert.Status,
+		Severity:    rule.Severity,
+		Summary:     alert.Annotations["summary"],
+		Description: alert.Annotations["description"],
+		Labels:      alert.Labels,
+		StartsAt:    alert.StartsAt,
+		EndsAt:      alert.EndsAt,
+	}
+	for _, channel := range channels {
+		if !channel.Enable {
+			continue
+		}
+		n, err := channelNotifier(channel)
+		if err != nil {
+			logrus.Errorf("create notifier of channel %s: %v", channel.ChannelID, err)
+			continue
+		}
+		go func(channel *dbmodel.TenantAlertChannel, n notify.Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := n.Notify(ctx, msg); err != nil {
+				logrus.Errorf("notify alert %s to channel %s: %v", rule.RuleID, channel.Name, err)
+			}
+		}(channel, n)
+	}
+}
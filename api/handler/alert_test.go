@@ -0,0 +1,141 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package handler
+
+import (
+	"testing"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util/notify"
+)
+
+func TestAlertRuleExpr(t *testing.T) {
+	components := []*dbmodel.TenantServices{
+		{ServiceID: "s1", ServiceAlias: "gr000001"},
+		{ServiceID: "s2", ServiceAlias: "gr000002", K8sComponentName: "nginx"},
+	}
+	tests := []struct {
+		name       string
+		rule       dbmodel.TenantAlertRule
+		components []*dbmodel.TenantServices
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "cpu",
+			rule:       dbmodel.TenantAlertRule{MetricType: "cpu", Operator: ">", Threshold: 500},
+			components: components,
+			want:       `sum by (service_id) (rate(container_cpu_usage_seconds_total{service_id=~"s1|s2"}[2m])) * 1000 > 500`,
+		},
+		{
+			name:       "memory",
+			rule:       dbmodel.TenantAlertRule{MetricType: "memory", Operator: ">=", Threshold: 1024.5},
+			components: components[:1],
+			want:       `sum by (service_id) (container_memory_working_set_bytes{service_id=~"s1"}) / 1024 / 1024 >= 1024.5`,
+		},
+		{
+			name:       "restart",
+			rule:       dbmodel.TenantAlertRule{MetricType: "restart", Operator: ">", Threshold: 3},
+			components: components,
+			want:       `sum by (namespace) (increase(kube_pod_container_status_restarts_total{namespace="ns",pod=~"(gr000001|nginx)-.*"}[10m])) > 3`,
+		},
+		{
+			name:       "http 5xx",
+			rule:       dbmodel.TenantAlertRule{MetricType: "http_5xx", Operator: ">", Threshold: 0.1},
+			components: components[1:],
+			want:       `sum by (service_id) (rate(gateway_requests{service_id=~"s2",status=~"5.."}[5m])) > 0.1`,
+		},
+		{
+			name: "custom",
+			rule: dbmodel.TenantAlertRule{MetricType: "custom", Expr: " up{job=\"foo\"} ", Operator: "==", Threshold: 0},
+			want: `(up{namespace="ns",job="foo"}) == 0`,
+		},
+		{
+			name:    "no component",
+			rule:    dbmodel.TenantAlertRule{MetricType: "cpu", Operator: ">", Threshold: 1},
+			wantErr: true,
+		},
+		{
+			name:       "unknown metric",
+			rule:       dbmodel.TenantAlertRule{MetricType: "disk", Operator: ">", Threshold: 1},
+			components: components,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := alertRuleExpr(&tc.rule, "ns", tc.components)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestScopePromQL(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{expr: `up`, want: `up{namespace="ns"}`},
+		{expr: `{__name__=~"up|down"}`, want: `{namespace="ns",__name__=~"up|down"}`},
+		{expr: `up{}`, want: `up{namespace="ns"}`},
+		// the namespace matchers of the expression can not widen the scope
+		{expr: `up{namespace!="ns"}`, want: `up{namespace="ns",namespace!="ns"}`},
+		{
+			expr: `sum by (pod) (rate(http_requests_total{code=~"5.."}[5m] offset 1h)) / on(pod) group_left sum(rate(http_requests_total[5m])) by (pod)`,
+			want: `sum by (pod) (rate(http_requests_total{namespace="ns",code=~"5.."}[5m] offset 1h)) / on(pod) group_left sum(rate(http_requests_total{namespace="ns"}[5m])) by (pod)`,
+		},
+		{expr: `a and on(job) b`, want: `a{namespace="ns"} and on(job) b{namespace="ns"}`},
+		{expr: `max_over_time(up[1h:5m]) > bool 1e3`, want: `max_over_time(up{namespace="ns"}[1h:5m]) > bool 1e3`},
+		{
+			expr: `label_replace(up, "dst", "$1", "src", "(.*)")`,
+			want: `label_replace(up{namespace="ns"}, "dst", "$1", "src", "(.*)")`,
+		},
+		{expr: `up{job="}"}`, want: `up{namespace="ns",job="}"}`},
+		{expr: `up{job="foo"`, wantErr: true},
+		{expr: `up{job="foo}`, wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := scopePromQL(tc.expr, "ns")
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: want error %v, got %v", tc.expr, tc.wantErr, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: want %s, got %s", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestKeepChannelSecrets(t *testing.T) {
+	channel := &dbmodel.TenantAlertChannel{
+		Config: `{"url":"https://oapi.dingtalk.com/robot/send?access_token=t","secret":"s","password":"p","headers":{"Authorization":"Bearer x"}}`,
+	}
+	cfg := notify.Config{Password: "new"}
+	keepChannelSecrets(channel, &cfg)
+	if cfg.URL != "https://oapi.dingtalk.com/robot/send?access_token=t" || cfg.Secret != "s" || cfg.Headers["Authorization"] != "Bearer x" {
+		t.Errorf("the omitted secrets should be kept, got %+v", cfg)
+	}
+	if cfg.Password != "new" {
+		t.Errorf("the secret of the request should take effect, got %s", cfg.Password)
+	}
+}
@@ -0,0 +1,249 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	utilx "github.com/goodrain/rainbond/util"
+	"github.com/jinzhu/gorm"
+)
+
+// APITokenPrefix the prefix of the issued api tokens, which tells them apart from the legacy tokens
+const APITokenPrefix = "rbd_"
+
+// apiTokenCacheTTL how long an authenticated token is cached, a token revoked
+// on another rbd-api instance is rejected by this instance at most after the ttl.
+const apiTokenCacheTTL = time.Minute
+
+const apiTokenRoutePrefix = "/v2/api-tokens"
+
+const auditLogRoutePrefix = "/v2/audit-logs"
+
+type cachedAPIToken struct {
+	token    *dbmodel.APIToken
+	loadTime time.Time
+}
+
+var apiTokenCache = struct {
+	sync.RWMutex
+	tokens map[string]*cachedAPIToken
+}{tokens: make(map[string]*cachedAPIToken)}
+
+// APITokenRequest the request checked against an api token, resolved from the matched route
+type APITokenRequest struct {
+	Method       string
+	RoutePattern string
+	TenantName   string
+	// AppID the app accessed by the request, resolved from the app id or the component of the route
+	AppID string
+}
+
+// HashAPIToken returns the hash of the token stored in db
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIToken() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return APITokenPrefix + hex.EncodeToString(b), nil
+}
+
+// CreateAPIToken issues a new api token
+func (t *TokenIdenAction) CreateAPIToken(req *api_model.CreateAPITokenStruct) (*api_model.APITokenInfo, *util.APIHandleError) {
+	if req.AppID != "" && req.TenantName == "" {
+		return nil, util.CreateAPIHandleError(400, fmt.Errorf("tenant_name is required when app_id is specified"))
+	}
+	if req.ExpireTime != nil && !req.ExpireTime.After(time.Now()) {
+		return nil, util.CreateAPIHandleError(400, fmt.Errorf("expire_time must be in the future"))
+	}
+	if req.TenantName != "" {
+		tenant, err := db.GetManager().TenantDao().GetTenantIDByName(req.TenantName)
+		if err != nil {
+			return nil, util.CreateAPIHandleErrorFromDBError("get tenant "+req.TenantName, err)
+		}
+		if req.AppID != "" {
+			app, err := db.GetManager().ApplicationDao().GetAppByID(req.AppID)
+			if err != nil {
+				return nil, util.CreateAPIHandleErrorFromDBError("get app "+req.AppID, err)
+			}
+			if app.TenantID != tenant.UUID {
+				return nil, util.CreateAPIHandleError(400, fmt.Errorf("app %s does not belong to tenant %s", req.AppID, req.TenantName))
+			}
+		}
+	}
+	token, err := generateAPIToken()
+	if err != nil {
+		return nil, util.CreateAPIHandleError(500, err)
+	}
+	apiToken := &dbmodel.APIToken{
+		TokenID:    utilx.NewUUID(),
+		Name:       req.Name,
+		TokenHash:  HashAPIToken(token),
+		Scope:      req.Scope,
+		TenantName: req.TenantName,
+		AppID:      req.AppID,
+		ExpireTime: req.ExpireTime,
+		CreatedBy:  req.CreatedBy,
+	}
+	if err := db.GetManager().APITokenDao().AddModel(apiToken); err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("create api token", err)
+	}
+	return &api_model.APITokenInfo{APIToken: apiToken, Token: token}, nil
+}
+
+// ListAPITokens lists the api tokens, the tokens of all tenants are returned if tenantName is empty
+func (t *TokenIdenAction) ListAPITokens(tenantName string) ([]*dbmodel.APIToken, *util.APIHandleError) {
+	tokens, err := db.GetManager().APITokenDao().ListByTenantName(tenantName)
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("list api tokens", err)
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken revokes the api token, the record is kept for auditing
+func (t *TokenIdenAction) RevokeAPIToken(tokenID string) *util.APIHandleError {
+	apiToken, err := db.GetManager().APITokenDao().GetByTokenID(tokenID)
+	if err != nil {
+		return util.CreateAPIHandleErrorFromDBError("get api token "+tokenID, err)
+	}
+	if apiToken.Revoked {
+		return nil
+	}
+	apiToken.Revoked = true
+	if err := db.GetManager().APITokenDao().UpdateModel(apiToken); err != nil {
+		return util.CreateAPIHandleErrorFromDBError("revoke api token", err)
+	}
+	apiTokenCache.Lock()
+	delete(apiTokenCache.tokens, apiToken.TokenHash)
+	apiTokenCache.Unlock()
+	return nil
+}
+
+// CheckAPIToken authenticates an issued api token, it returns nil if the token
+// does not exist, is revoked or is expired.
+func (t *TokenIdenAction) CheckAPIToken(token string) *dbmodel.APIToken {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return nil
+	}
+	hash := HashAPIToken(token)
+	apiTokenCache.RLock()
+	cached, ok := apiTokenCache.tokens[hash]
+	apiTokenCache.RUnlock()
+	if !ok || time.Since(cached.loadTime) > apiTokenCacheTTL {
+		apiToken, err := db.GetManager().APITokenDao().GetByTokenHash(hash)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				// the misses are not cached, so that unknown tokens can not fill the cache
+				apiTokenCache.Lock()
+				delete(apiTokenCache.tokens, hash)
+				apiTokenCache.Unlock()
+			}
+			return nil
+		}
+		cached = &cachedAPIToken{token: apiToken, loadTime: time.Now()}
+		apiTokenCache.Lock()
+		if apiToken.Revoked || apiToken.Expired(time.Now()) {
+			delete(apiTokenCache.tokens, hash)
+		} else {
+			apiTokenCache.tokens[hash] = cached
+		}
+		apiTokenCache.Unlock()
+	}
+	if cached.token.Revoked || cached.token.Expired(time.Now()) {
+		return nil
+	}
+	return cached.token
+}
+
+// CheckAPITokenPermission checks whether the scope and the restrictions of the token allow the request.
+func CheckAPITokenPermission(token *dbmodel.APIToken, req *APITokenRequest) error {
+	if strings.HasPrefix(req.RoutePattern, apiTokenRoutePrefix) && (token.Scope != dbmodel.APITokenScopeAdmin || token.TenantName != "") {
+		return fmt.Errorf("managing api tokens requires an unrestricted admin token")
+	}
+	if strings.HasPrefix(req.RoutePattern, auditLogRoutePrefix) && (token.Scope != dbmodel.APITokenScopeAdmin || token.TenantName != "") {
+		return fmt.Errorf("reading the audit logs requires an unrestricted admin token")
+	}
+	if token.TenantName != "" {
+		if !strings.Contains(req.RoutePattern, "{tenant_name}") {
+			return fmt.Errorf("the token is restricted to tenant %s", token.TenantName)
+		}
+		if req.TenantName != token.TenantName {
+			return fmt.Errorf("the token is restricted to tenant %s", token.TenantName)
+		}
+	}
+	if token.AppID != "" && req.AppID != token.AppID {
+		return fmt.Errorf("the token is restricted to app %s", token.AppID)
+	}
+	switch token.Scope {
+	case dbmodel.APITokenScopeAdmin:
+		return nil
+	case dbmodel.APITokenScopeDeploy:
+		if isReadOnlyMethod(req.Method) && req.RoutePattern != "" {
+			return nil
+		}
+		// deploy tokens can only change the resources of the tenants, but not the tenants themselves
+		if strings.HasPrefix(req.RoutePattern, "/v2/tenants/{tenant_name}/") && !isTenantLimitRoute(req.RoutePattern) {
+			return nil
+		}
+		return fmt.Errorf("the deploy scope does not allow %s %s", req.Method, req.RoutePattern)
+	case dbmodel.APITokenScopeReadOnly:
+		if isReadOnlyMethod(req.Method) && req.RoutePattern != "" {
+			return nil
+		}
+		return fmt.Errorf("the read-only scope does not allow %s %s", req.Method, req.RoutePattern)
+	}
+	return fmt.Errorf("unknown scope %s", token.Scope)
+}
+
+// StrongestAPITokenScope returns the strongest of the scopes, or empty if none of them is known
+func StrongestAPITokenScope(scopes []string) string {
+	var strongest string
+	rank := map[string]int{dbmodel.APITokenScopeReadOnly: 1, dbmodel.APITokenScopeDeploy: 2, dbmodel.APITokenScopeAdmin: 3}
+	for _, scope := range scopes {
+		if rank[scope] > rank[strongest] {
+			strongest = scope
+		}
+	}
+	return strongest
+}
+
+// isTenantLimitRoute reports whether the route changes the limits of the tenant, which are only changed by the admins
+func isTenantLimitRoute(routePattern string) bool {
+	return routePattern == "/v2/tenants/{tenant_name}/quota" || routePattern == "/v2/tenants/{tenant_name}/limit_memory"
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
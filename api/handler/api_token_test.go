@@ -0,0 +1,145 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"testing"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+func TestCheckAPITokenPermission(t *testing.T) {
+	const servicePattern = "/v2/tenants/{tenant_name}/services/{service_alias}/start"
+	tests := []struct {
+		name  string
+		token *dbmodel.APIToken
+		req   *APITokenRequest
+		allow bool
+	}{
+		{
+			name:  "read-only get",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeReadOnly},
+			req:   &APITokenRequest{Method: "GET", RoutePattern: "/v2/cluster/"},
+			allow: true,
+		},
+		{
+			name:  "read-only post",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeReadOnly},
+			req:   &APITokenRequest{Method: "POST", RoutePattern: servicePattern, TenantName: "team"},
+		},
+		{
+			name:  "deploy in tenant",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeDeploy},
+			req:   &APITokenRequest{Method: "POST", RoutePattern: servicePattern, TenantName: "team"},
+			allow: true,
+		},
+		{
+			name:  "deploy deletes tenant",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeDeploy},
+			req:   &APITokenRequest{Method: "DELETE", RoutePattern: "/v2/tenants/{tenant_name}", TenantName: "team"},
+		},
+		{
+			name:  "deploy changes tenant quota",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeDeploy},
+			req:   &APITokenRequest{Method: "PUT", RoutePattern: "/v2/tenants/{tenant_name}/quota", TenantName: "team"},
+		},
+		{
+			name:  "deploy changes cluster",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeDeploy},
+			req:   &APITokenRequest{Method: "POST", RoutePattern: "/v2/volume-options"},
+		},
+		{
+			name:  "unmatched route",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeDeploy},
+			req:   &APITokenRequest{Method: "GET"},
+		},
+		{
+			name:  "other tenant",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeAdmin, TenantName: "team"},
+			req:   &APITokenRequest{Method: "GET", RoutePattern: servicePattern, TenantName: "other"},
+		},
+		{
+			name:  "tenant token outside tenants",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeAdmin, TenantName: "team"},
+			req:   &APITokenRequest{Method: "GET", RoutePattern: "/v2/cluster/"},
+		},
+		{
+			name:  "app token",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeDeploy, TenantName: "team", AppID: "app"},
+			req:   &APITokenRequest{Method: "POST", RoutePattern: servicePattern, TenantName: "team", AppID: "app"},
+			allow: true,
+		},
+		{
+			name:  "app token in other app",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeDeploy, TenantName: "team", AppID: "app"},
+			req:   &APITokenRequest{Method: "POST", RoutePattern: servicePattern, TenantName: "team", AppID: "other"},
+		},
+		{
+			name:  "admin manages tokens",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeAdmin},
+			req:   &APITokenRequest{Method: "POST", RoutePattern: "/v2/api-tokens/"},
+			allow: true,
+		},
+		{
+			name:  "deploy manages tokens",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeDeploy},
+			req:   &APITokenRequest{Method: "GET", RoutePattern: "/v2/api-tokens/"},
+		},
+		{
+			name:  "read-only reads audit logs",
+			token: &dbmodel.APIToken{Scope: dbmodel.APITokenScopeReadOnly},
+			req:   &APITokenRequest{Method: "GET", RoutePattern: "/v2/audit-logs/"},
+		},
+	}
+	for _, tc := range tests {
+		err := CheckAPITokenPermission(tc.token, tc.req)
+		if tc.allow && err != nil {
+			t.Errorf("%s: expected allowed, got %v", tc.name, err)
+		}
+		if !tc.allow && err == nil {
+			t.Errorf("%s: expected denied", tc.name)
+		}
+	}
+}
+
+func TestAPITokenExpired(t *testing.T) {
+	now := time.Now()
+	token := &dbmodel.APIToken{}
+	if token.Expired(now) {
+		t.Error("token without expire time should never expire")
+	}
+	expireTime := now.Add(-time.Second)
+	token.ExpireTime = &expireTime
+	if !token.Expired(now) {
+		t.Error("token should be expired")
+	}
+	if HashAPIToken("rbd_a") == HashAPIToken("rbd_b") || len(HashAPIToken("rbd_a")) != 64 {
+		t.Error("unexpected token hash")
+	}
+}
+
+func TestStrongestAPITokenScope(t *testing.T) {
+	if scope := StrongestAPITokenScope([]string{"read-only", "admin", "deploy"}); scope != dbmodel.APITokenScopeAdmin {
+		t.Errorf("expected admin, got %s", scope)
+	}
+	if scope := StrongestAPITokenScope([]string{"unknown"}); scope != "" {
+		t.Errorf("expected no scope, got %s", scope)
+	}
+}
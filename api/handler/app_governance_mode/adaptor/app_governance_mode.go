@@ -6,6 +6,7 @@ import (
 	"github.com/goodrain/rainbond/db/model"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
@@ -15,6 +16,37 @@ import (
 type AppGoveranceModeHandler interface {
 	IsInstalledControlPlane() bool
 	GetInjectLabels() map[string]string
+	GetInjectAnnotations(component *MeshComponent) map[string]string
+}
+
+// AuthorizationPolicyHandler is implemented by the governance modes which authorize
+// traffic between components by workload identity.
+type AuthorizationPolicyHandler interface {
+	// GetAuthorizationPolicies returns the mesh objects that allow the clients of the component to access it.
+	GetAuthorizationPolicies(component *MeshComponent) []*unstructured.Unstructured
+	// GetAuthorizationPolicyKinds returns the kinds of the mesh objects, so that the ones no longer wanted can be listed and deleted.
+	GetAuthorizationPolicyKinds() []schema.GroupVersionKind
+}
+
+// MeshPolicyLabel marks the mesh objects created by GetAuthorizationPolicies
+const MeshPolicyLabel = "rainbond.io/mesh-policy"
+
+// MeshComponent describes a component as seen by the service mesh
+type MeshComponent struct {
+	Name           string
+	Namespace      string
+	ServiceAccount string
+	// Selector selects the pods of the component
+	Selector map[string]string
+	Ports    []MeshPort
+	// Clients are the components which depend on this component
+	Clients []*MeshComponent
+}
+
+// MeshPort -
+type MeshPort struct {
+	Port           int
+	IsOuterService bool
 }
 
 // NewAppGoveranceModeHandler -
@@ -26,6 +58,10 @@ func NewAppGoveranceModeHandler(governanceMode string, kubeClient clientset.Inte
 		return NewBuildInServiceMeshMode(), nil
 	case model.GovernanceModeKubernetesNativeService:
 		return NewKubernetesNativeMode(), nil
+	case model.GovernanceModeLinkerdServiceMesh:
+		return NewLinkerdGoveranceMode(kubeClient), nil
+	case model.GovernanceModeConsulServiceMesh:
+		return NewConsulGoveranceMode(kubeClient), nil
 	default:
 		return nil, bcode.ErrInvalidGovernanceMode
 	}
@@ -40,6 +76,10 @@ func IsGovernanceModeValid(governanceMode string, dynamicClient dynamic.Interfac
 		return true
 	case model.GovernanceModeIstioServiceMesh:
 		return true
+	case model.GovernanceModeLinkerdServiceMesh:
+		return true
+	case model.GovernanceModeConsulServiceMesh:
+		return true
 	default:
 		found := findGovernanceMode(governanceMode, dynamicClient)
 		logrus.Debugf("find governance mode %s, found: %v", governanceMode, found)
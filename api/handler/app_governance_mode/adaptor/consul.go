@@ -0,0 +1,84 @@
+package adaptor
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+type consulServiceMeshMode struct {
+	kubeClient clientset.Interface
+}
+
+// NewConsulGoveranceMode -
+func NewConsulGoveranceMode(kubeClient clientset.Interface) AppGoveranceModeHandler {
+	return &consulServiceMeshMode{
+		kubeClient: kubeClient,
+	}
+}
+
+// IsInstalledControlPlane -
+func (c *consulServiceMeshMode) IsInstalledControlPlane() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	webhookName := os.Getenv("CONSUL_INJECTOR")
+	if webhookName == "" {
+		webhookName = "consul-connect-injector"
+	}
+	_, err := c.kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, webhookName, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return true
+}
+
+// GetInjectLabels -
+func (c *consulServiceMeshMode) GetInjectLabels() map[string]string {
+	return nil
+}
+
+// GetInjectAnnotations registers the component in consul with its workload name,
+// so that it matches the name of the service account and the service intentions.
+func (c *consulServiceMeshMode) GetInjectAnnotations(component *MeshComponent) map[string]string {
+	annotations := map[string]string{"consul.hashicorp.com/connect-inject": "true"}
+	if component == nil {
+		return annotations
+	}
+	annotations["consul.hashicorp.com/connect-service"] = component.Name
+	if len(component.Ports) > 0 {
+		annotations["consul.hashicorp.com/connect-service-port"] = strconv.Itoa(component.Ports[0].Port)
+	}
+	return annotations
+}
+
+// GetAuthorizationPolicies creates the service intentions which allow the clients of the component to access it.
+func (c *consulServiceMeshMode) GetAuthorizationPolicies(component *MeshComponent) []*unstructured.Unstructured {
+	if len(component.Clients) == 0 {
+		return nil
+	}
+	var sources []interface{}
+	for _, client := range component.Clients {
+		sources = append(sources, map[string]interface{}{
+			"name":   client.Name,
+			"action": "allow",
+		})
+	}
+	intentions := newMeshObject("consul.hashicorp.com/v1alpha1", "ServiceIntentions", component.Name, component, map[string]interface{}{
+		"destination": map[string]interface{}{
+			"name": component.Name,
+		},
+		"sources": sources,
+	})
+	return []*unstructured.Unstructured{intentions}
+}
+
+// GetAuthorizationPolicyKinds -
+func (c *consulServiceMeshMode) GetAuthorizationPolicyKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{{Group: "consul.hashicorp.com", Version: "v1alpha1", Kind: "ServiceIntentions"}}
+}
@@ -17,3 +17,8 @@ func (k *kubernetesNativeMode) IsInstalledControlPlane() bool {
 func (k *kubernetesNativeMode) GetInjectLabels() map[string]string {
 	return nil
 }
+
+// GetInjectAnnotations -
+func (k *kubernetesNativeMode) GetInjectAnnotations(component *MeshComponent) map[string]string {
+	return nil
+}
@@ -0,0 +1,135 @@
+package adaptor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const linkerdPolicyGroup = "policy.linkerd.io"
+
+type linkerdServiceMeshMode struct {
+	kubeClient clientset.Interface
+}
+
+// NewLinkerdGoveranceMode -
+func NewLinkerdGoveranceMode(kubeClient clientset.Interface) AppGoveranceModeHandler {
+	return &linkerdServiceMeshMode{
+		kubeClient: kubeClient,
+	}
+}
+
+// IsInstalledControlPlane -
+func (l *linkerdServiceMeshMode) IsInstalledControlPlane() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	webhookName := os.Getenv("LINKERD_INJECTOR")
+	if webhookName == "" {
+		webhookName = "linkerd-proxy-injector-webhook-config"
+	}
+	_, err := l.kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, webhookName, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return true
+}
+
+// GetInjectLabels -
+func (l *linkerdServiceMeshMode) GetInjectLabels() map[string]string {
+	return nil
+}
+
+// GetInjectAnnotations -
+func (l *linkerdServiceMeshMode) GetInjectAnnotations(component *MeshComponent) map[string]string {
+	return map[string]string{"linkerd.io/inject": "enabled"}
+}
+
+// GetAuthorizationPolicies creates a Server for every inner port of the component, and
+// only allows the identities of its clients to access it. Ports open to the outer network
+// are left to the default policy, so that the gateway can still reach them.
+func (l *linkerdServiceMeshMode) GetAuthorizationPolicies(component *MeshComponent) []*unstructured.Unstructured {
+	if len(component.Clients) == 0 {
+		return nil
+	}
+	var identityRefs []interface{}
+	for _, client := range component.Clients {
+		identityRefs = append(identityRefs, map[string]interface{}{
+			"kind":      "ServiceAccount",
+			"name":      client.ServiceAccount,
+			"namespace": client.Namespace,
+		})
+	}
+	var policies []*unstructured.Unstructured
+	for _, port := range component.Ports {
+		if port.IsOuterService {
+			continue
+		}
+		name := fmt.Sprintf("%s-%d", component.Name, port.Port)
+		policies = append(policies, newMeshObject(linkerdPolicyGroup+"/v1beta1", "Server", name, component, map[string]interface{}{
+			"podSelector": map[string]interface{}{
+				"matchLabels": stringMap(component.Selector),
+			},
+			"port": int64(port.Port),
+		}))
+		policies = append(policies, newMeshObject(linkerdPolicyGroup+"/v1alpha1", "AuthorizationPolicy", name, component, map[string]interface{}{
+			"targetRef": map[string]interface{}{
+				"group": linkerdPolicyGroup,
+				"kind":  "Server",
+				"name":  name,
+			},
+			"requiredAuthenticationRefs": []interface{}{
+				map[string]interface{}{
+					"group": linkerdPolicyGroup,
+					"kind":  "MeshTLSAuthentication",
+					"name":  component.Name,
+				},
+			},
+		}))
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	authn := newMeshObject(linkerdPolicyGroup+"/v1alpha1", "MeshTLSAuthentication", component.Name, component, map[string]interface{}{
+		"identityRefs": identityRefs,
+	})
+	return append([]*unstructured.Unstructured{authn}, policies...)
+}
+
+// GetAuthorizationPolicyKinds -
+func (l *linkerdServiceMeshMode) GetAuthorizationPolicyKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{
+		{Group: linkerdPolicyGroup, Version: "v1alpha1", Kind: "MeshTLSAuthentication"},
+		{Group: linkerdPolicyGroup, Version: "v1beta1", Kind: "Server"},
+		{Group: linkerdPolicyGroup, Version: "v1alpha1", Kind: "AuthorizationPolicy"},
+	}
+}
+
+func newMeshObject(apiVersion, kind, name string, component *MeshComponent, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"spec":       spec,
+	}}
+	obj.SetName(name)
+	obj.SetNamespace(component.Namespace)
+	labels := map[string]string{MeshPolicyLabel: "true"}
+	for k, v := range component.Selector {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+	return obj
+}
+
+func stringMap(in map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package adaptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestMeshComponent() *MeshComponent {
+	return &MeshComponent{
+		Name:           "app-api",
+		Namespace:      "tenant",
+		ServiceAccount: "app-api",
+		Selector:       map[string]string{"service_id": "api"},
+		Ports: []MeshPort{
+			{Port: 80, IsOuterService: true},
+			{Port: 8080},
+		},
+		Clients: []*MeshComponent{
+			{Name: "app-web", Namespace: "tenant", ServiceAccount: "app-web"},
+		},
+	}
+}
+
+func TestLinkerdAuthorizationPolicies(t *testing.T) {
+	mode := NewLinkerdGoveranceMode(nil)
+	component := newTestMeshComponent()
+	assert.Equal(t, map[string]string{"linkerd.io/inject": "enabled"}, mode.GetInjectAnnotations(component))
+
+	policies := mode.(AuthorizationPolicyHandler).GetAuthorizationPolicies(component)
+	var kinds []string
+	for _, policy := range policies {
+		kinds = append(kinds, policy.GetKind()+"/"+policy.GetName())
+		assert.Equal(t, "tenant", policy.GetNamespace())
+		// the stale policies are listed by the label and the kinds
+		assert.Equal(t, "true", policy.GetLabels()[MeshPolicyLabel])
+		assert.Contains(t, mode.(AuthorizationPolicyHandler).GetAuthorizationPolicyKinds(), policy.GroupVersionKind())
+	}
+	// the outer port is left to the default policy
+	assert.Equal(t, []string{"MeshTLSAuthentication/app-api", "Server/app-api-8080", "AuthorizationPolicy/app-api-8080"}, kinds)
+
+	refs, _, _ := unstructured.NestedSlice(policies[0].Object, "spec", "identityRefs")
+	assert.Equal(t, []interface{}{map[string]interface{}{"kind": "ServiceAccount", "name": "app-web", "namespace": "tenant"}}, refs)
+	port, _, _ := unstructured.NestedInt64(policies[1].Object, "spec", "port")
+	assert.Equal(t, int64(8080), port)
+
+	component.Clients = nil
+	assert.Nil(t, mode.(AuthorizationPolicyHandler).GetAuthorizationPolicies(component))
+}
+
+func TestConsulServiceIntentions(t *testing.T) {
+	mode := NewConsulGoveranceMode(nil)
+	component := newTestMeshComponent()
+	assert.Equal(t, map[string]string{
+		"consul.hashicorp.com/connect-inject":       "true",
+		"consul.hashicorp.com/connect-service":      "app-api",
+		"consul.hashicorp.com/connect-service-port": "80",
+	}, mode.GetInjectAnnotations(component))
+
+	policies := mode.(AuthorizationPolicyHandler).GetAuthorizationPolicies(component)
+	assert.Len(t, policies, 1)
+	assert.Equal(t, "ServiceIntentions", policies[0].GetKind())
+	assert.Equal(t, "true", policies[0].GetLabels()[MeshPolicyLabel])
+	assert.Contains(t, mode.(AuthorizationPolicyHandler).GetAuthorizationPolicyKinds(), policies[0].GroupVersionKind())
+	destination, _, _ := unstructured.NestedString(policies[0].Object, "spec", "destination", "name")
+	assert.Equal(t, "app-api", destination)
+	sources, _, _ := unstructured.NestedSlice(policies[0].Object, "spec", "sources")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "app-web", "action": "allow"}}, sources)
+}
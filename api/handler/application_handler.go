@@ -106,6 +106,10 @@ func (a *ApplicationAction) CreateApp(ctx context.Context, req *model.Applicatio
 		AppType:         req.AppType,
 		AppStoreName:    req.AppStoreName,
 		AppStoreURL:     req.AppStoreURL,
+		AppStoreType:    req.AppStoreType,
+		AppStoreBranch:  req.AppStoreBranch,
+		ChartPath:       req.ChartPath,
+		ChartDigest:     req.ChartDigest,
 		AppTemplateName: req.AppTemplateName,
 		Version:         req.Version,
 		K8sApp:          req.K8sApp,
@@ -154,8 +158,12 @@ func (a *ApplicationAction) createHelmApp(ctx context.Context, app *dbmodel.Appl
 			TemplateName: app.AppTemplateName,
 			Version:      app.Version,
 			AppStore: &v1alpha1.HelmAppStore{
-				Name: app.AppStoreName,
-				URL:  app.AppStoreURL,
+				Name:   app.AppStoreName,
+				URL:    app.AppStoreURL,
+				Type:   v1alpha1.HelmAppStoreType(app.AppStoreType),
+				Branch: app.AppStoreBranch,
+				Path:   app.ChartPath,
+				Digest: app.ChartDigest,
 			},
 		}}
 	ctx1, cancel := context.WithTimeout(ctx, 3*time.Second)
@@ -213,6 +221,18 @@ func (a *ApplicationAction) UpdateApp(ctx context.Context, app *dbmodel.Applicat
 		}
 		app.GovernanceMode = req.GovernanceMode
 	}
+	if req.MeshMTLSMode != "" {
+		if !dbmodel.IsMeshMTLSModeValid(req.MeshMTLSMode) {
+			return nil, bcode.ErrInvalidMeshMTLSMode
+		}
+		app.MeshMTLSMode = req.MeshMTLSMode
+	}
+	if req.NetworkIsolation != "" {
+		if !dbmodel.IsNetworkIsolationValid(req.NetworkIsolation) {
+			return nil, bcode.ErrInvalidNetworkIsolation
+		}
+		app.NetworkIsolation = req.NetworkIsolation
+	}
 	app.K8sApp = req.K8sApp
 
 	err := db.GetManager().DB().Transaction(func(tx *gorm.DB) error {
@@ -386,6 +406,9 @@ func (a *ApplicationAction) updateHelmApp(ctx context.Context, app *dbmodel.Appl
 	if req.Revision != 0 {
 		helmApp.Spec.Revision = req.Revision
 	}
+	if req.ChartDigest != "" && helmApp.Spec.AppStore != nil {
+		helmApp.Spec.AppStore.Digest = req.ChartDigest
+	}
 	_, err = a.rainbondClient.RainbondV1alpha1().HelmApps(tenant.Namespace).Update(ctx, helmApp, metav1.UpdateOptions{})
 	return err
 }
@@ -927,6 +950,25 @@ func (a *ApplicationAction) ListGovernanceMode() ([]model.GovernanceMode, error)
 			Description: dbmodel.GovernanceModeKubernetesNativeServiceDesc,
 		},
 	}
+	meshModes := []model.GovernanceMode{
+		{
+			Name:        dbmodel.GovernanceModeLinkerdServiceMesh,
+			Description: dbmodel.GovernanceModeLinkerdServiceMeshDesc,
+		},
+		{
+			Name:        dbmodel.GovernanceModeConsulServiceMesh,
+			Description: dbmodel.GovernanceModeConsulServiceMeshDesc,
+		},
+	}
+	for _, meshMode := range meshModes {
+		mode, err := adaptor.NewAppGoveranceModeHandler(meshMode.Name, a.kubeClient)
+		if err != nil {
+			continue
+		}
+		if mode.IsInstalledControlPlane() {
+			governanceModes = append(governanceModes, meshMode)
+		}
+	}
 
 	var serviceMeshClassesResource = schema.GroupVersionResource{Group: "rainbond.io", Version: "v1alpha1", Resource: "servicemeshclasses"}
 	list, err := a.dynamicClient.Resource(serviceMeshClassesResource).List(context.Background(), metav1.ListOptions{})
@@ -949,6 +991,15 @@ func (a *ApplicationAction) CheckGovernanceMode(ctx context.Context, governanceM
 	if !adaptor.IsGovernanceModeValid(governanceMode, a.dynamicClient) {
 		return bcode.ErrInvalidGovernanceMode
 	}
+	if governanceMode == dbmodel.GovernanceModeLinkerdServiceMesh || governanceMode == dbmodel.GovernanceModeConsulServiceMesh {
+		mode, err := adaptor.NewAppGoveranceModeHandler(governanceMode, a.kubeClient)
+		if err != nil {
+			return err
+		}
+		if !mode.IsInstalledControlPlane() {
+			return bcode.ErrControlPlaneNotInstall
+		}
+	}
 	return nil
 }
 
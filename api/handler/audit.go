@@ -0,0 +1,179 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/url"
+	"time"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/dao"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// auditLogBatchSize how many audit logs are loaded at once when exporting or verifying
+const auditLogBatchSize = 500
+
+// AuditHandler records the mutating api calls in the hash chained audit log
+type AuditHandler interface {
+	Record(auditLog *dbmodel.AuditLog) error
+	ListAuditLogs(query *dao.AuditLogQuery, page, pageSize int) (*api_model.AuditLogList, error)
+	ExportAuditLogs(query *dao.AuditLogQuery, w io.Writer) error
+	VerifyAuditLogs() (*api_model.AuditLogVerifyResult, error)
+}
+
+// AuditAction is an implementation of AuditHandler
+type AuditAction struct {
+	syslog *syslog.Writer
+}
+
+// NewAuditHandler creates an audit handler, the records are also forwarded to syslog if syslogAddr is set, such as udp://127.0.0.1:514
+func NewAuditHandler(syslogAddr string) (AuditHandler, error) {
+	a := &AuditAction{}
+	if syslogAddr != "" {
+		u, err := url.Parse(syslogAddr)
+		if err != nil {
+			return nil, fmt.Errorf("parse audit syslog address %s: %v", syslogAddr, err)
+		}
+		writer, err := syslog.Dial(u.Scheme, u.Host, syslog.LOG_INFO|syslog.LOG_AUTH, "rbd-api")
+		if err != nil {
+			return nil, fmt.Errorf("dial audit syslog %s: %v", syslogAddr, err)
+		}
+		a.syslog = writer
+	}
+	return a, nil
+}
+
+// Record chains the audit log to the last one and saves it, the last record is locked
+// in the transaction so that the concurrent rbd-api instances keep one chain.
+func (a *AuditAction) Record(auditLog *dbmodel.AuditLog) error {
+	auditLog.Time = auditLog.Time.Truncate(time.Second)
+	auditLog.Truncate()
+	tx := db.GetManager().Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("Unexpected panic occurred, rollback transaction: %v", r)
+			tx.Rollback()
+		}
+	}()
+	last, err := db.GetManager().AuditLogDaoTransactions(tx).GetLastAuditLog()
+	if err != nil && err != gorm.ErrRecordNotFound {
+		tx.Rollback()
+		return err
+	}
+	auditLog.PrevHash = ""
+	if last != nil {
+		auditLog.PrevHash = last.Hash
+	}
+	auditLog.Hash = auditLog.ComputeHash()
+	if err := db.GetManager().AuditLogDaoTransactions(tx).AddModel(auditLog); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	if a.syslog != nil {
+		line, _ := json.Marshal(auditLog)
+		if err := a.syslog.Info(string(line)); err != nil {
+			logrus.Warningf("forward audit log %d to syslog: %v", auditLog.ID, err)
+		}
+	}
+	return nil
+}
+
+// ListAuditLogs lists the audit logs by page, the latest first
+func (a *AuditAction) ListAuditLogs(query *dao.AuditLogQuery, page, pageSize int) (*api_model.AuditLogList, error) {
+	auditLogs, total, err := db.GetManager().AuditLogDao().ListAuditLogs(query, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &api_model.AuditLogList{
+		AuditLogs: auditLogs,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+	}, nil
+}
+
+// ExportAuditLogs writes the audit logs as json lines, the earliest first
+func (a *AuditAction) ExportAuditLogs(query *dao.AuditLogQuery, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	var lastID uint
+	for {
+		auditLogs, err := db.GetManager().AuditLogDao().ListAuditLogsAfterID(query, lastID, auditLogBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, auditLog := range auditLogs {
+			if err := encoder.Encode(auditLog); err != nil {
+				return err
+			}
+			lastID = auditLog.ID
+		}
+		if len(auditLogs) < auditLogBatchSize {
+			return nil
+		}
+	}
+}
+
+// VerifyAuditLogs walks through the whole chain and checks the hash of every record
+func (a *AuditAction) VerifyAuditLogs() (*api_model.AuditLogVerifyResult, error) {
+	result := &api_model.AuditLogVerifyResult{Valid: true}
+	var lastID uint
+	var prevHash string
+	for {
+		auditLogs, err := db.GetManager().AuditLogDao().ListAuditLogsAfterID(nil, lastID, auditLogBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, auditLog := range auditLogs {
+			if reason := verifyAuditLog(auditLog, prevHash); reason != "" {
+				result.Valid = false
+				result.BrokenID = auditLog.ID
+				result.Reason = reason
+				return result, nil
+			}
+			result.Checked++
+			prevHash = auditLog.Hash
+			lastID = auditLog.ID
+		}
+		if len(auditLogs) < auditLogBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// verifyAuditLog returns why the record breaks the chain, or empty if it does not
+func verifyAuditLog(auditLog *dbmodel.AuditLog, prevHash string) string {
+	if auditLog.PrevHash != prevHash {
+		return "the previous hash does not match the hash of the previous record"
+	}
+	if auditLog.Hash != auditLog.ComputeHash() {
+		return "the hash does not match the content of the record"
+	}
+	return ""
+}
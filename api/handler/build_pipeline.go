@@ -0,0 +1,215 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"encoding/json"
+	"time"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/worker/discover/model"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// pipelineRunsLimit the number of the latest pipeline runs listed
+const pipelineRunsLimit = 20
+
+// BuildPipelineHandler the build pipelines of the components built from the source code.
+// The builder runs the stages of the pipeline, and holds the version until it passes the promotion gate.
+type BuildPipelineHandler interface {
+	GetBuildPipeline(serviceID string) (*dbmodel.BuildPipeline, error)
+	UpdBuildPipeline(service *dbmodel.TenantServices, pipeline *dbmodel.BuildPipeline) error
+	DeleteBuildPipeline(serviceID string) error
+	ListPipelineRuns(serviceID string) ([]*api_model.PipelineRun, error)
+	PromotePipelineRun(service *dbmodel.TenantServices, runEventID, eventID string, req *api_model.PromotePipelineRunReq) (*api_model.PipelineRun, error)
+}
+
+// NewBuildPipelineHandler creates a build pipeline handler
+func NewBuildPipelineHandler(serviceHandler ServiceHandler) BuildPipelineHandler {
+	return &buildPipelineAction{serviceHandler: serviceHandler}
+}
+
+type buildPipelineAction struct {
+	serviceHandler ServiceHandler
+}
+
+// GetBuildPipeline returns the build pipeline of the component defined by the api
+func (b *buildPipelineAction) GetBuildPipeline(serviceID string) (*dbmodel.BuildPipeline, error) {
+	record, err := db.GetManager().TenantServiceBuildPipelineDao().GetByServiceID(serviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrBuildPipelineNotFound
+		}
+		return nil, errors.Wrap(err, "get build pipeline")
+	}
+	return record.BuildPipeline()
+}
+
+// UpdBuildPipeline creates or updates the build pipeline of the component,
+// which takes precedence over the pipeline of the rainbondfile.
+func (b *buildPipelineAction) UpdBuildPipeline(service *dbmodel.TenantServices, pipeline *dbmodel.BuildPipeline) error {
+	if err := pipeline.Validate(); err != nil {
+		return bcode.NewBadRequest(err.Error())
+	}
+	body, err := json.Marshal(pipeline)
+	if err != nil {
+		return err
+	}
+	record, err := db.GetManager().TenantServiceBuildPipelineDao().GetByServiceID(service.ServiceID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return errors.Wrap(err, "get build pipeline")
+		}
+		return db.GetManager().TenantServiceBuildPipelineDao().AddModel(&dbmodel.TenantServiceBuildPipeline{
+			ServiceID: service.ServiceID,
+			TenantID:  service.TenantID,
+			Pipeline:  string(body),
+		})
+	}
+	record.Pipeline = string(body)
+	return db.GetManager().TenantServiceBuildPipelineDao().UpdateModel(record)
+}
+
+// DeleteBuildPipeline deletes the build pipeline of the component, the pipeline of the rainbondfile applies if any
+func (b *buildPipelineAction) DeleteBuildPipeline(serviceID string) error {
+	return db.GetManager().TenantServiceBuildPipelineDao().DeleteByServiceID(serviceID)
+}
+
+// ListPipelineRuns lists the latest pipeline runs of the component
+func (b *buildPipelineAction) ListPipelineRuns(serviceID string) ([]*api_model.PipelineRun, error) {
+	runs, err := db.GetManager().TenantServicePipelineRunDao().ListByServiceID(serviceID, pipelineRunsLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "list pipeline runs")
+	}
+	result := make([]*api_model.PipelineRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, api_model.NewPipelineRun(run))
+	}
+	return result, nil
+}
+
+// PromotePipelineRun approves or rejects the version waiting for the manual approval.
+// The approved version becomes the deploy version of the component, and is rolling upgraded
+// if the build requested so, with the event eventID.
+func (b *buildPipelineAction) PromotePipelineRun(service *dbmodel.TenantServices, runEventID, eventID string, req *api_model.PromotePipelineRunReq) (*api_model.PipelineRun, error) {
+	run, err := db.GetManager().TenantServicePipelineRunDao().GetByEventID(runEventID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrPipelineRunNotFound
+		}
+		return nil, errors.Wrap(err, "get pipeline run")
+	}
+	if run.ServiceID != service.ServiceID {
+		return nil, bcode.ErrPipelineRunNotFound
+	}
+	if run.Status != dbmodel.PipelineRunStatusWaitingApproval {
+		return nil, bcode.ErrPipelineRunNotWaiting
+	}
+	// the deploy versions are timestamps
+	if req.Approve && service.DeployVersion > run.DeployVersion {
+		return nil, bcode.ErrPipelineRunOutdated
+	}
+
+	// claim the run, so that it is approved or rejected only once
+	status := dbmodel.PipelineRunStatusRejected
+	if req.Approve {
+		status = dbmodel.PipelineRunStatusPromoted
+	}
+	claimed, err := db.GetManager().TenantServicePipelineRunDao().UpdateStatusIfMatch(run.EventID, dbmodel.PipelineRunStatusWaitingApproval, status)
+	if err != nil {
+		return nil, errors.Wrap(err, "update pipeline run")
+	}
+	if !claimed {
+		return nil, bcode.ErrPipelineRunNotWaiting
+	}
+	now := time.Now()
+	run.Status, run.Operator, run.Message, run.FinishTime = status, req.Operator, req.Message, &now
+	if !req.Approve {
+		if err := db.GetManager().TenantServicePipelineRunDao().UpdateModel(run); err != nil {
+			return nil, errors.Wrap(err, "update pipeline run")
+		}
+		util.UpdateEvent(eventID, 200)
+		return api_model.NewPipelineRun(run), nil
+	}
+
+	if err := b.deploy(service, run, eventID); err != nil {
+		// release the run, so that it can be approved again
+		if _, rerr := db.GetManager().TenantServicePipelineRunDao().UpdateStatusIfMatch(run.EventID, status, dbmodel.PipelineRunStatusWaitingApproval); rerr != nil {
+			logrus.Warningf("release pipeline run %s: %v", run.EventID, rerr)
+		}
+		return nil, err
+	}
+	if err := db.GetManager().TenantServicePipelineRunDao().UpdateModel(run); err != nil {
+		return nil, errors.Wrap(err, "update pipeline run")
+	}
+	return api_model.NewPipelineRun(run), nil
+}
+
+// deploy makes the approved version the deploy version of the component,
+// and rolling upgrades the component if the build requested so.
+func (b *buildPipelineAction) deploy(service *dbmodel.TenantServices, run *dbmodel.TenantServicePipelineRun, eventID string) error {
+	if run.Action == "upgrade" {
+		var configs map[string]string
+		if run.Configs != "" {
+			if err := json.Unmarshal([]byte(run.Configs), &configs); err != nil {
+				return errors.Wrap(err, "decode configs of the pipeline run")
+			}
+		}
+		if err := b.serviceHandler.ServiceUpgrade(&model.RollingUpgradeTaskBody{
+			TenantID:         service.TenantID,
+			ServiceID:        service.ServiceID,
+			NewDeployVersion: run.DeployVersion,
+			EventID:          eventID,
+			Configs:          configs,
+		}); err != nil {
+			return errors.Wrap(err, "upgrade component")
+		}
+		return nil
+	}
+	if err := db.GetManager().TenantServiceDao().UpdateDeployVersion(service.ServiceID, run.DeployVersion); err != nil {
+		return errors.Wrap(err, "update deploy version")
+	}
+	util.UpdateEvent(eventID, 200)
+	return nil
+}
+
+// CheckVersionDeployable returns an error if the version of the component is held or rejected by the promotion gate,
+// the upgrade and the rollback apis must not deploy such a version without the approval.
+func CheckVersionDeployable(serviceID, deployVersion string) error {
+	if deployVersion == "" {
+		return nil
+	}
+	run, err := db.GetManager().TenantServicePipelineRunDao().GetLatestByDeployVersion(serviceID, deployVersion)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return errors.Wrap(err, "get pipeline run")
+	}
+	if !dbmodel.IsPipelineRunDeployable(run.Status) {
+		return bcode.ErrVersionNotPromoted
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/builder/sources"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// buildTriggerPollPeriod how often the poll mode triggers are checked whether their interval expires
+const buildTriggerPollPeriod = 30 * time.Second
+
+// BuildTriggerHandler builds the components from the source code once the git repositories are pushed,
+// the pushes are reported by the webhooks of the git servers or found by polling the last commits.
+type BuildTriggerHandler interface {
+	GetBuildTrigger(serviceID string) (*api_model.BuildTrigger, error)
+	UpdBuildTrigger(serviceID string, req *api_model.BuildTriggerReq) (*api_model.BuildTrigger, error)
+	DeleteBuildTrigger(serviceID string) error
+	HandleGitWebhook(provider string, header http.Header, body []byte) (*api_model.GitWebhookResult, error)
+	Run(ctx context.Context)
+}
+
+// NewBuildTriggerHandler creates a build trigger handler
+func NewBuildTriggerHandler(operation *OperationHandler) BuildTriggerHandler {
+	b := &buildTriggerAction{operation: operation}
+	b.debouncer = newBuildDebouncer(b.build)
+	return b
+}
+
+type buildTriggerAction struct {
+	operation *OperationHandler
+	debouncer *buildDebouncer
+}
+
+// pendingBuild a build waiting for the debounce to expire
+type pendingBuild struct {
+	serviceID string
+	trigger   *dbmodel.BuildTrigger
+	branch    string
+	commit    string
+	operator  string
+}
+
+func (b *buildTriggerAction) getTrigger(serviceID string) (*dbmodel.ServiceSourceConfig, *dbmodel.BuildTrigger, error) {
+	source, err := db.GetManager().ServiceSourceDao().GetServiceSourceByType(serviceID, dbmodel.ServiceSourceTypeBuildTrigger)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "get build trigger")
+	}
+	if source == nil {
+		return nil, nil, nil
+	}
+	trigger, err := dbmodel.ParseBuildTrigger(source)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parse build trigger")
+	}
+	return source, trigger, nil
+}
+
+// GetBuildTrigger returns the build trigger of the component with the secret and the password masked
+func (b *buildTriggerAction) GetBuildTrigger(serviceID string) (*api_model.BuildTrigger, error) {
+	_, trigger, err := b.getTrigger(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if trigger == nil {
+		return nil, bcode.ErrBuildTriggerNotFound
+	}
+	return maskBuildTrigger(trigger), nil
+}
+
+func maskBuildTrigger(trigger *dbmodel.BuildTrigger) *api_model.BuildTrigger {
+	masked := *trigger
+	if masked.Secret != "" {
+		masked.Secret = dbmodel.MaskedSecretValue
+	}
+	if masked.Password != "" {
+		masked.Password = dbmodel.MaskedSecretValue
+	}
+	res := &api_model.BuildTrigger{BuildTrigger: masked}
+	if trigger.Mode == dbmodel.BuildTriggerModeWebhook {
+		provider := trigger.Provider
+		if provider == "" {
+			provider = "{provider}"
+		}
+		res.WebhookPath = "/webhooks/git/" + provider
+	}
+	return res
+}
+
+// UpdBuildTrigger creates or updates the build trigger of the component
+func (b *buildTriggerAction) UpdBuildTrigger(serviceID string, req *api_model.BuildTriggerReq) (*api_model.BuildTrigger, error) {
+	_, old, err := b.getTrigger(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	trigger := req.DbModel(old)
+	if trigger.Mode == dbmodel.BuildTriggerModeWebhook && trigger.Secret == "" {
+		return nil, bcode.NewBadRequest("the secret is required by the webhook mode")
+	}
+	source, err := trigger.ServiceSource(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	// AddModel updates the body of the existing source of the same type
+	if err := db.GetManager().ServiceSourceDao().AddModel(source); err != nil {
+		return nil, errors.Wrap(err, "save build trigger")
+	}
+	return maskBuildTrigger(trigger), nil
+}
+
+// DeleteBuildTrigger deletes the build trigger of the component
+func (b *buildTriggerAction) DeleteBuildTrigger(serviceID string) error {
+	b.debouncer.cancel(serviceID)
+	return db.GetManager().ServiceSourceDao().DeleteByServiceIDAndType(serviceID, dbmodel.ServiceSourceTypeBuildTrigger)
+}
+
+// HandleGitWebhook schedules the builds of the components whose triggers match the pushed repository
+// and branch or tag. The payload must be verified by the secret of the trigger.
+func (b *buildTriggerAction) HandleGitWebhook(provider string, header http.Header, body []byte) (*api_model.GitWebhookResult, error) {
+	events, err := parseGitWebhook(provider, header, body)
+	if err != nil {
+		return nil, bcode.NewBadRequest(err.Error())
+	}
+	result := &api_model.GitWebhookResult{Scheduled: []string{}}
+	if len(events) == 0 {
+		result.Ignored = "no branch or tag pushed"
+		return result, nil
+	}
+	triggerSources, err := db.GetManager().ServiceSourceDao().ListBySourceType(dbmodel.ServiceSourceTypeBuildTrigger)
+	if err != nil {
+		return nil, errors.Wrap(err, "list build triggers")
+	}
+	var matched, verified int
+	for _, source := range triggerSources {
+		trigger, err := dbmodel.ParseBuildTrigger(source)
+		if err != nil {
+			logrus.Warningf("parse build trigger of component %s: %v", source.ServiceID, err)
+			continue
+		}
+		if trigger.Mode != dbmodel.BuildTriggerModeWebhook || (trigger.Provider != "" && trigger.Provider != provider) {
+			continue
+		}
+		for _, event := range events {
+			branch, ok := matchPushEvent(trigger, event)
+			if !ok {
+				continue
+			}
+			matched++
+			if !verifyGitWebhook(provider, header, body, trigger.Secret) {
+				logrus.Warningf("the signature of the %s webhook does not match the secret of component %s", provider, source.ServiceID)
+				break
+			}
+			verified++
+			b.debouncer.push(&pendingBuild{
+				serviceID: source.ServiceID,
+				trigger:   trigger,
+				branch:    branch,
+				commit:    event.Commit,
+				operator:  provider + "-webhook",
+			}, time.Duration(trigger.Debounce)*time.Second)
+			result.Scheduled = append(result.Scheduled, source.ServiceID)
+			break
+		}
+	}
+	if matched > 0 && verified == 0 {
+		return nil, bcode.ErrInvalidWebhookSignature
+	}
+	if matched == 0 {
+		result.Ignored = "no component is triggered by the pushed branch or tag"
+	}
+	return result, nil
+}
+
+// matchPushEvent returns the branch to build if the event matches the trigger, the tags are built as tag:<name>
+func matchPushEvent(trigger *dbmodel.BuildTrigger, event *gitPushEvent) (string, bool) {
+	repoURL := normalizeRepoURL(trigger.RepoURL)
+	found := false
+	for _, u := range event.RepoURLs {
+		if u != "" && normalizeRepoURL(u) == repoURL {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", false
+	}
+	if event.Tag != "" {
+		if trigger.TagPattern == "" {
+			return "", false
+		}
+		if ok, _ := path.Match(trigger.TagPattern, event.Tag); !ok {
+			return "", false
+		}
+		return "tag:" + event.Tag, true
+	}
+	return event.Branch, event.Branch == trigger.Branch
+}
+
+// Run polls the last commits of the poll mode triggers until the context is done
+func (b *buildTriggerAction) Run(ctx context.Context) {
+	ticker := time.NewTicker(buildTriggerPollPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.poll()
+		}
+	}
+}
+
+func (b *buildTriggerAction) poll() {
+	triggerSources, err := db.GetManager().ServiceSourceDao().ListBySourceType(dbmodel.ServiceSourceTypeBuildTrigger)
+	if err != nil {
+		logrus.Errorf("list build triggers: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, source := range triggerSources {
+		trigger, err := dbmodel.ParseBuildTrigger(source)
+		if err != nil || trigger.Mode != dbmodel.BuildTriggerModePoll {
+			continue
+		}
+		if now.Sub(time.Unix(trigger.LastPollAt, 0)) < time.Duration(trigger.PollInterval)*time.Second {
+			continue
+		}
+		service, err := db.GetManager().TenantServiceDao().GetServiceByID(source.ServiceID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				// the trigger is left behind by the deleted component
+				_ = b.DeleteBuildTrigger(source.ServiceID)
+				continue
+			}
+			logrus.Warningf("get component %s of build trigger: %v", source.ServiceID, err)
+			continue
+		}
+		// every rbd-api instance polls, only the one which moves last_poll_at forward polls the trigger this time
+		trigger.LastPollAt = now.Unix()
+		claimed, ok := b.updateTrigger(source, trigger)
+		if !ok {
+			continue
+		}
+		commit, err := sources.GetRemoteLastCommit(sources.CodeSourceInfo{
+			ServerType:    trigger.ServerType,
+			RepositoryURL: trigger.RepoURL,
+			Branch:        trigger.Branch,
+			User:          trigger.User,
+			Password:      trigger.Password,
+			TenantID:      service.TenantID,
+			ServiceID:     service.ServiceID,
+		}, time.Minute)
+		if err != nil {
+			logrus.Warningf("get the last commit of %s %s: %v", trigger.RepoURL, trigger.Branch, err)
+			continue
+		}
+		if commit.String() == trigger.LastCommit {
+			continue
+		}
+		lastCommit := trigger.LastCommit
+		trigger.LastCommit = commit.String()
+		// the trigger updated by the user while polling is polled again next time
+		if _, ok := b.updateTrigger(claimed, trigger); !ok || lastCommit == "" {
+			// the first poll only records the commit, the component is built once the branch changes
+			continue
+		}
+		b.debouncer.push(&pendingBuild{
+			serviceID: source.ServiceID,
+			trigger:   trigger,
+			branch:    trigger.Branch,
+			commit:    commit.String(),
+			operator:  "git-poll",
+		}, time.Duration(trigger.Debounce)*time.Second)
+	}
+}
+
+// updateTrigger saves the trigger only if the stored body is still the one of the source,
+// it returns the source with the saved body and whether the trigger is saved.
+func (b *buildTriggerAction) updateTrigger(source *dbmodel.ServiceSourceConfig, trigger *dbmodel.BuildTrigger) (*dbmodel.ServiceSourceConfig, bool) {
+	body, err := json.Marshal(trigger)
+	if err != nil {
+		return nil, false
+	}
+	ok, err := db.GetManager().ServiceSourceDao().UpdateSourceBodyIfUnchanged(source.ID, source.SourceBody, string(body))
+	if err != nil {
+		logrus.Warningf("save build trigger of component %s: %v", source.ServiceID, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+	updated := *source
+	updated.SourceBody = string(body)
+	return &updated, true
+}
+
+// build sends the build task of the pending build, the build is delayed again if the component is being operated
+func (b *buildTriggerAction) build(p *pendingBuild) {
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(p.serviceID)
+	if err != nil {
+		logrus.Errorf("get component %s to build: %v", p.serviceID, err)
+		return
+	}
+	tenant, err := db.GetManager().TenantDao().GetTenantByUUID(service.TenantID)
+	if err != nil {
+		logrus.Errorf("get tenant of component %s to build: %v", p.serviceID, err)
+		return
+	}
+	if !util.CanDoEvent("build-service", dbmodel.ASYNEVENTTYPE, dbmodel.TargetTypeService, service.ServiceID, service.Kind) {
+		logrus.Infof("component %s is being operated, delay the build of commit %s", service.ServiceAlias, p.commit)
+		b.debouncer.push(p, time.Duration(p.trigger.Debounce)*time.Second+buildTriggerPollPeriod)
+		return
+	}
+	req := &api_model.ComponentBuildReq{
+		ComponentOpGeneralReq: api_model.ComponentOpGeneralReq{ServiceID: service.ServiceID},
+		Kind:                  api_model.FromCodeBuildKing,
+		Action:                p.trigger.Action,
+		Operator:              p.operator,
+		CodeInfo: api_model.BuildCodeInfo{
+			RepoURL:    p.trigger.RepoURL,
+			Branch:     p.branch,
+			Lang:       p.trigger.Lang,
+			ServerType: p.trigger.ServerType,
+			Runtime:    p.trigger.Runtime,
+			User:       p.trigger.User,
+			Password:   p.trigger.Password,
+		},
+		TenantName: tenant.Name,
+	}
+	reqBody, _ := json.Marshal(map[string]string{"repo_url": p.trigger.RepoURL, "branch": p.branch, "commit": p.commit})
+	event, err := util.CreateEvent(dbmodel.TargetTypeService, "build-service", service.ServiceID, tenant.UUID, string(reqBody), p.operator, dbmodel.ASYNEVENTTYPE)
+	if err != nil {
+		logrus.Errorf("create build event of component %s: %v", service.ServiceAlias, err)
+		return
+	}
+	req.EventID = event.EventID
+	if err := b.operation.build(req); err != nil {
+		logrus.Errorf("build component %s from commit %s: %v", service.ServiceAlias, p.commit, err)
+		util.UpdateEvent(event.EventID, http.StatusInternalServerError)
+		return
+	}
+	logrus.Infof("build component %s from %s commit %s", service.ServiceAlias, p.branch, p.commit)
+}
+
+// buildDebouncer delays the builds so that the rapid pushes of a component are built once, the last one wins
+type buildDebouncer struct {
+	lock    sync.Mutex
+	pending map[string]*time.Timer
+	build   func(*pendingBuild)
+}
+
+func newBuildDebouncer(build func(*pendingBuild)) *buildDebouncer {
+	return &buildDebouncer{pending: make(map[string]*time.Timer), build: build}
+}
+
+// push replaces the pending build of the component and restarts the delay
+func (d *buildDebouncer) push(p *pendingBuild, delay time.Duration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if timer, ok := d.pending[p.serviceID]; ok {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		d.lock.Lock()
+		if d.pending[p.serviceID] != timer {
+			d.lock.Unlock()
+			return
+		}
+		delete(d.pending, p.serviceID)
+		d.lock.Unlock()
+		d.build(p)
+	})
+	d.pending[p.serviceID] = timer
+}
+
+// cancel drops the pending build of the component
+func (d *buildDebouncer) cancel(serviceID string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if timer, ok := d.pending[serviceID]; ok {
+		timer.Stop()
+		delete(d.pending, serviceID)
+	}
+}
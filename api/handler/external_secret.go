@@ -0,0 +1,76 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goodrain/rainbond/api/util/bcode"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidateExternalSecretEnv checks the reference of the env var to the external secret store.
+// The value of a referencing env var is masked, so that neither the database nor the responses contain it.
+func ValidateExternalSecretEnv(env *dbmodel.TenantServiceEnvVar) error {
+	if err := validateExternalSecretRef(env.ExternalSecretRef); err != nil {
+		return err
+	}
+	if !env.IsExternalSecret() {
+		return nil
+	}
+	if errs := validation.IsConfigMapKey(strings.TrimSpace(env.AttrName)); len(errs) > 0 {
+		return bcode.NewBadRequest(fmt.Sprintf("invalid name of the env referencing the external secret: %s", strings.Join(errs, ",")))
+	}
+	env.AttrValue = dbmodel.MaskedSecretValue
+	return nil
+}
+
+// ValidateExternalSecretConfigFile checks the reference of the config file to the external secret store.
+// The content of a referencing config file is masked.
+func ValidateExternalSecretConfigFile(cf *dbmodel.TenantServiceConfigFile) error {
+	if err := validateExternalSecretRef(cf.ExternalSecretRef); err != nil {
+		return err
+	}
+	if cf.IsExternalSecret() {
+		cf.FileContent = dbmodel.MaskedSecretValue
+	}
+	return nil
+}
+
+func validateExternalSecretRef(ref dbmodel.ExternalSecretRef) error {
+	if !ref.IsExternalSecret() {
+		if ref.SecretKey != "" || ref.SecretVersion != 0 || ref.RestartOnRotation {
+			return bcode.NewBadRequest("secret_path is required by the reference to the external secret")
+		}
+		return nil
+	}
+	if ref.SecretKey == "" {
+		return bcode.NewBadRequest("secret_key is required by the reference to the external secret")
+	}
+	if ref.SecretVersion < 0 {
+		return bcode.NewBadRequest("secret_version can not be negative")
+	}
+	for _, segment := range strings.Split(strings.Trim(ref.SecretPath, "/"), "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return bcode.NewBadRequest(fmt.Sprintf("invalid secret_path %s", ref.SecretPath))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/goodrain/rainbond/db/model"
+)
+
+func TestValidateExternalSecretEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		ref   model.ExternalSecretRef
+		valid bool
+	}{
+		{"DB_PASSWORD", model.ExternalSecretRef{}, true},
+		{"DB_PASSWORD", model.ExternalSecretRef{SecretPath: "mysql/prod", SecretKey: "password"}, true},
+		{"DB_PASSWORD", model.ExternalSecretRef{SecretPath: "mysql/prod", SecretKey: "password", SecretVersion: 3, RestartOnRotation: true}, true},
+		{"DB_PASSWORD", model.ExternalSecretRef{SecretKey: "password"}, false},
+		{"DB_PASSWORD", model.ExternalSecretRef{SecretPath: "mysql/prod"}, false},
+		{"DB_PASSWORD", model.ExternalSecretRef{SecretPath: "mysql/prod", SecretKey: "password", SecretVersion: -1}, false},
+		{"DB_PASSWORD", model.ExternalSecretRef{SecretPath: "mysql/../admin", SecretKey: "password"}, false},
+		{"DB PASSWORD", model.ExternalSecretRef{SecretPath: "mysql/prod", SecretKey: "password"}, false},
+	}
+	for _, tc := range tests {
+		env := &model.TenantServiceEnvVar{AttrName: tc.name, AttrValue: "plain", ExternalSecretRef: tc.ref}
+		err := ValidateExternalSecretEnv(env)
+		if (err == nil) != tc.valid {
+			t.Errorf("validate %s %+v: expected valid %v, got %v", tc.name, tc.ref, tc.valid, err)
+			continue
+		}
+		if err == nil && tc.ref.IsExternalSecret() != (env.AttrValue == model.MaskedSecretValue) {
+			t.Errorf("validate %s %+v: unexpected value %q", tc.name, tc.ref, env.AttrValue)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+// gitPushEvent a branch or a tag pushed to the repository
+type gitPushEvent struct {
+	RepoURLs []string
+	Branch   string
+	Tag      string
+	Commit   string
+	Message  string
+	Author   string
+}
+
+// parseGitWebhook parses the pushed branches and tags of the webhook payload,
+// the events other than the push, such as ping, are parsed as nothing.
+func parseGitWebhook(provider string, header http.Header, body []byte) ([]*gitPushEvent, error) {
+	switch provider {
+	case "github", "gitea":
+		event := header.Get("X-GitHub-Event")
+		if provider == "gitea" {
+			event = header.Get("X-Gitea-Event")
+		}
+		if event != "push" {
+			return nil, nil
+		}
+		return parseGithubPush(body)
+	case "gitlab":
+		event := header.Get("X-Gitlab-Event")
+		if event != "Push Hook" && event != "Tag Push Hook" {
+			return nil, nil
+		}
+		return parseGitlabPush(body)
+	case "bitbucket":
+		switch header.Get("X-Event-Key") {
+		case "repo:push":
+			return parseBitbucketCloudPush(body)
+		case "repo:refs_changed":
+			return parseBitbucketServerPush(body)
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported git provider %s", provider)
+}
+
+// verifyGitWebhook verifies the payload with the secret of the webhook. GitLab sends the secret token
+// as is, the others sign the payload with the HMAC of the secret.
+func verifyGitWebhook(provider string, header http.Header, body []byte, secret string) bool {
+	if secret == "" {
+		return false
+	}
+	switch provider {
+	case "gitlab":
+		token := header.Get("X-Gitlab-Token")
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	case "gitea":
+		if signature := header.Get("X-Gitea-Signature"); signature != "" {
+			return verifyHMAC(sha256.New, secret, body, signature)
+		}
+	}
+	if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+		return verifyHMAC(sha256.New, secret, body, strings.TrimPrefix(signature, "sha256="))
+	}
+	signature := header.Get("X-Hub-Signature")
+	switch {
+	case strings.HasPrefix(signature, "sha256="):
+		return verifyHMAC(sha256.New, secret, body, strings.TrimPrefix(signature, "sha256="))
+	case strings.HasPrefix(signature, "sha1="):
+		return verifyHMAC(sha1.New, secret, body, strings.TrimPrefix(signature, "sha1="))
+	}
+	return false
+}
+
+func verifyHMAC(h func() hash.Hash, secret string, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// refEvent sets the branch or the tag of the event by the full reference name
+func refEvent(ref string, event *gitPushEvent) bool {
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		event.Branch = strings.TrimPrefix(ref, "refs/heads/")
+	case strings.HasPrefix(ref, "refs/tags/"):
+		event.Tag = strings.TrimPrefix(ref, "refs/tags/")
+	default:
+		return false
+	}
+	return true
+}
+
+func parseGithubPush(body []byte) ([]*gitPushEvent, error) {
+	var payload struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Deleted    bool   `json:"deleted"`
+		Repository struct {
+			CloneURL string `json:"clone_url"`
+			SSHURL   string `json:"ssh_url"`
+			HTMLURL  string `json:"html_url"`
+		} `json:"repository"`
+		HeadCommit *struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"head_commit"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Deleted || payload.After == zeroCommit {
+		return nil, nil
+	}
+	event := &gitPushEvent{
+		RepoURLs: []string{payload.Repository.CloneURL, payload.Repository.SSHURL, payload.Repository.HTMLURL},
+		Commit:   payload.After,
+	}
+	if !refEvent(payload.Ref, event) {
+		return nil, nil
+	}
+	if payload.HeadCommit != nil {
+		event.Message = payload.HeadCommit.Message
+		event.Author = payload.HeadCommit.Author.Name
+	}
+	return []*gitPushEvent{event}, nil
+}
+
+func parseGitlabPush(body []byte) ([]*gitPushEvent, error) {
+	var payload struct {
+		Ref      string `json:"ref"`
+		After    string `json:"after"`
+		UserName string `json:"user_name"`
+		Project  struct {
+			GitHTTPURL string `json:"git_http_url"`
+			GitSSHURL  string `json:"git_ssh_url"`
+			WebURL     string `json:"web_url"`
+		} `json:"project"`
+		Commits []struct {
+			ID      string `json:"id"`
+			Message string `json:"message"`
+			Author  struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.After == zeroCommit {
+		return nil, nil
+	}
+	event := &gitPushEvent{
+		RepoURLs: []string{payload.Project.GitHTTPURL, payload.Project.GitSSHURL, payload.Project.WebURL},
+		Commit:   payload.After,
+		Author:   payload.UserName,
+	}
+	if !refEvent(payload.Ref, event) {
+		return nil, nil
+	}
+	for _, commit := range payload.Commits {
+		if commit.ID == payload.After {
+			event.Message = commit.Message
+			event.Author = commit.Author.Name
+		}
+	}
+	return []*gitPushEvent{event}, nil
+}
+
+func parseBitbucketCloudPush(body []byte) ([]*gitPushEvent, error) {
+	var payload struct {
+		Repository struct {
+			FullName string `json:"full_name"`
+			Links    struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+		} `json:"repository"`
+		Push struct {
+			Changes []struct {
+				New *struct {
+					Type   string `json:"type"`
+					Name   string `json:"name"`
+					Target struct {
+						Hash    string `json:"hash"`
+						Message string `json:"message"`
+						Author  struct {
+							Raw string `json:"raw"`
+						} `json:"author"`
+					} `json:"target"`
+				} `json:"new"`
+			} `json:"changes"`
+		} `json:"push"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	repoURLs := []string{payload.Repository.Links.HTML.Href}
+	if payload.Repository.FullName != "" {
+		repoURLs = append(repoURLs, "https://bitbucket.org/"+payload.Repository.FullName)
+	}
+	var events []*gitPushEvent
+	for _, change := range payload.Push.Changes {
+		// the new state is null once the branch or the tag is deleted
+		if change.New == nil {
+			continue
+		}
+		event := &gitPushEvent{
+			RepoURLs: repoURLs,
+			Commit:   change.New.Target.Hash,
+			Message:  change.New.Target.Message,
+			Author:   change.New.Target.Author.Raw,
+		}
+		switch change.New.Type {
+		case "branch":
+			event.Branch = change.New.Name
+		case "tag":
+			event.Tag = change.New.Name
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func parseBitbucketServerPush(body []byte) ([]*gitPushEvent, error) {
+	var payload struct {
+		Actor struct {
+			DisplayName string `json:"displayName"`
+		} `json:"actor"`
+		Repository struct {
+			Links struct {
+				Clone []struct {
+					Href string `json:"href"`
+				} `json:"clone"`
+				Self []struct {
+					Href string `json:"href"`
+				} `json:"self"`
+			} `json:"links"`
+		} `json:"repository"`
+		Changes []struct {
+			RefID  string `json:"refId"`
+			ToHash string `json:"toHash"`
+			Type   string `json:"type"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	var repoURLs []string
+	for _, link := range payload.Repository.Links.Clone {
+		repoURLs = append(repoURLs, link.Href)
+	}
+	for _, link := range payload.Repository.Links.Self {
+		repoURLs = append(repoURLs, link.Href)
+	}
+	var events []*gitPushEvent
+	for _, change := range payload.Changes {
+		if change.Type == "DELETE" || change.ToHash == zeroCommit {
+			continue
+		}
+		event := &gitPushEvent{RepoURLs: repoURLs, Commit: change.ToHash, Author: payload.Actor.DisplayName}
+		if !refEvent(change.RefID, event) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// normalizeRepoURL returns the host and the path of the repository in lower case, so that
+// the https, ssh and web urls of the same repository are equal.
+func normalizeRepoURL(repoURL string) string {
+	repoURL = strings.TrimSpace(repoURL)
+	if repoURL == "" {
+		return ""
+	}
+	var host, repoPath string
+	if u, err := url.Parse(repoURL); err == nil && u.Host != "" {
+		host, repoPath = u.Hostname(), u.Path
+	} else if i := strings.Index(repoURL, ":"); i > 0 {
+		// scp like ssh url, such as git@github.com:goodrain/rainbond.git
+		host, repoPath = repoURL[:i], repoURL[i+1:]
+		if j := strings.LastIndex(host, "@"); j >= 0 {
+			host = host[j+1:]
+		}
+	} else {
+		return ""
+	}
+	repoPath = strings.Trim(repoPath, "/")
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+	// the clone urls of bitbucket server are under /scm/, the web urls are /projects/<key>/repos/<name>/browse
+	repoPath = strings.TrimPrefix(repoPath, "scm/")
+	repoPath = strings.TrimSuffix(repoPath, "/browse")
+	if parts := strings.Split(repoPath, "/"); len(parts) == 4 && parts[0] == "projects" && parts[2] == "repos" {
+		repoPath = parts[1] + "/" + parts[3]
+	}
+	return strings.ToLower(host + "/" + repoPath)
+}
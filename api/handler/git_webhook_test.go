@@ -0,0 +1,190 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseGitWebhook(t *testing.T) {
+	tests := []struct {
+		provider string
+		header   map[string]string
+		body     string
+		branch   string
+		tag      string
+		commit   string
+	}{
+		{
+			provider: "github",
+			header:   map[string]string{"X-GitHub-Event": "push"},
+			body:     `{"ref":"refs/heads/main","after":"abc","repository":{"clone_url":"https://github.com/goodrain/demo.git"},"head_commit":{"message":"fix","author":{"name":"dev"}}}`,
+			branch:   "main",
+			commit:   "abc",
+		},
+		{
+			provider: "gitlab",
+			header:   map[string]string{"X-Gitlab-Event": "Tag Push Hook"},
+			body:     `{"ref":"refs/tags/v1.0.0","after":"def","project":{"git_http_url":"https://gitlab.com/goodrain/demo.git"}}`,
+			tag:      "v1.0.0",
+			commit:   "def",
+		},
+		{
+			provider: "gitea",
+			header:   map[string]string{"X-Gitea-Event": "push"},
+			body:     `{"ref":"refs/heads/dev","after":"123","repository":{"ssh_url":"git@gitea.local:goodrain/demo.git"}}`,
+			branch:   "dev",
+			commit:   "123",
+		},
+		{
+			provider: "bitbucket",
+			header:   map[string]string{"X-Event-Key": "repo:push"},
+			body:     `{"repository":{"full_name":"goodrain/demo"},"push":{"changes":[{"new":null},{"new":{"type":"branch","name":"main","target":{"hash":"456"}}}]}}`,
+			branch:   "main",
+			commit:   "456",
+		},
+		{
+			provider: "bitbucket",
+			header:   map[string]string{"X-Event-Key": "repo:refs_changed"},
+			body:     `{"repository":{"links":{"clone":[{"href":"https://bitbucket.local/scm/gr/demo.git"}]}},"changes":[{"refId":"refs/tags/v2","toHash":"789","type":"ADD"}]}`,
+			tag:      "v2",
+			commit:   "789",
+		},
+	}
+	for _, tc := range tests {
+		header := http.Header{}
+		for k, v := range tc.header {
+			header.Set(k, v)
+		}
+		events, err := parseGitWebhook(tc.provider, header, []byte(tc.body))
+		if err != nil {
+			t.Fatalf("parse %s webhook: %v", tc.provider, err)
+		}
+		if len(events) != 1 {
+			t.Fatalf("parse %s webhook: expected 1 event, got %d", tc.provider, len(events))
+		}
+		if e := events[0]; e.Branch != tc.branch || e.Tag != tc.tag || e.Commit != tc.commit {
+			t.Errorf("parse %s webhook: unexpected event %+v", tc.provider, e)
+		}
+	}
+
+	header := http.Header{}
+	header.Set("X-GitHub-Event", "ping")
+	if events, err := parseGitWebhook("github", header, []byte(`{}`)); err != nil || len(events) != 0 {
+		t.Errorf("expected the ping event to be ignored, got %v %v", events, err)
+	}
+	header.Set("X-GitHub-Event", "push")
+	deleted := `{"ref":"refs/heads/main","after":"0000000000000000000000000000000000000000","deleted":true}`
+	if events, err := parseGitWebhook("github", header, []byte(deleted)); err != nil || len(events) != 0 {
+		t.Errorf("expected the deleted branch to be ignored, got %v %v", events, err)
+	}
+}
+
+func TestVerifyGitWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	tests := []struct {
+		provider, header, value string
+		valid                   bool
+	}{
+		{"github", "X-Hub-Signature-256", "sha256=" + sign("s3cret", body), true},
+		{"github", "X-Hub-Signature-256", "sha256=" + sign("other", body), false},
+		{"gitea", "X-Gitea-Signature", sign("s3cret", body), true},
+		{"bitbucket", "X-Hub-Signature", "sha256=" + sign("s3cret", body), true},
+		{"gitlab", "X-Gitlab-Token", "s3cret", true},
+		{"gitlab", "X-Gitlab-Token", "wrong", false},
+		{"github", "X-Other", "whatever", false},
+	}
+	for _, tc := range tests {
+		header := http.Header{}
+		header.Set(tc.header, tc.value)
+		if got := verifyGitWebhook(tc.provider, header, body, "s3cret"); got != tc.valid {
+			t.Errorf("verify %s %s: expected %v, got %v", tc.provider, tc.header, tc.valid, got)
+		}
+	}
+	header := http.Header{}
+	header.Set("X-Gitlab-Token", "")
+	if verifyGitWebhook("gitlab", header, body, "") {
+		t.Errorf("the webhook should not be verified by an empty secret")
+	}
+}
+
+func TestMatchPushEvent(t *testing.T) {
+	trigger := &dbmodel.BuildTrigger{RepoURL: "https://GitHub.com/goodrain/demo", Branch: "main", TagPattern: "v*"}
+	tests := []struct {
+		event  gitPushEvent
+		branch string
+		ok     bool
+	}{
+		{gitPushEvent{RepoURLs: []string{"git@github.com:goodrain/demo.git"}, Branch: "main"}, "main", true},
+		{gitPushEvent{RepoURLs: []string{"https://github.com/goodrain/demo.git"}, Branch: "dev"}, "", false},
+		{gitPushEvent{RepoURLs: []string{"https://github.com/goodrain/demo.git"}, Tag: "v1.2"}, "tag:v1.2", true},
+		{gitPushEvent{RepoURLs: []string{"https://github.com/goodrain/demo.git"}, Tag: "release-1"}, "", false},
+		{gitPushEvent{RepoURLs: []string{"https://github.com/goodrain/other.git"}, Branch: "main"}, "", false},
+	}
+	for _, tc := range tests {
+		branch, ok := matchPushEvent(trigger, &tc.event)
+		if ok != tc.ok || (ok && branch != tc.branch) {
+			t.Errorf("match %+v: expected %q %v, got %q %v", tc.event, tc.branch, tc.ok, branch, ok)
+		}
+	}
+	if normalizeRepoURL("https://bitbucket.local/projects/GR/repos/demo/browse") != normalizeRepoURL("ssh://git@bitbucket.local:7999/scm/gr/demo.git") {
+		t.Errorf("the web and clone urls of bitbucket server should be equal")
+	}
+}
+
+func TestBuildDebouncer(t *testing.T) {
+	var lock sync.Mutex
+	var built []string
+	done := make(chan struct{}, 2)
+	d := newBuildDebouncer(func(p *pendingBuild) {
+		lock.Lock()
+		built = append(built, p.commit)
+		lock.Unlock()
+		done <- struct{}{}
+	})
+	d.push(&pendingBuild{serviceID: "s1", commit: "c1"}, 50*time.Millisecond)
+	d.push(&pendingBuild{serviceID: "s1", commit: "c2"}, 50*time.Millisecond)
+	d.push(&pendingBuild{serviceID: "s2", commit: "c3"}, 50*time.Millisecond)
+	d.cancel("s2")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the pending build is not built")
+	}
+	time.Sleep(100 * time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	if len(built) != 1 || built[0] != "c2" {
+		t.Errorf("expected only the last push to be built, got %v", built)
+	}
+}
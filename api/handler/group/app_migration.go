@@ -0,0 +1,515 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package group
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+
+	"github.com/goodrain/rainbond/api/util"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/event"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	mqclient "github.com/goodrain/rainbond/mq/client"
+	core_util "github.com/goodrain/rainbond/util"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	discovermodel "github.com/goodrain/rainbond/worker/discover/model"
+)
+
+// migrationStopTimeout the max time waiting for the components to stop while cutting over
+var migrationStopTimeout = 10 * time.Minute
+
+// MigrationS3Config the object storage the data is synced through
+type MigrationS3Config struct {
+	Provider   string `json:"provider"`
+	Endpoint   string `json:"endpoint"`
+	AccessKey  string `json:"access_key"`
+	SecretKey  string `json:"secret_key"`
+	BucketName string `json:"bucket_name"`
+}
+
+// AppMigrationReq migrates the app to another region
+type AppMigrationReq struct {
+	TenantID string `json:"-"`
+	Body     struct {
+		EventID      string            `json:"event_id" validate:"event_id|required"`
+		GroupID      string            `json:"group_id" validate:"group_id|required"`
+		ServiceIDs   []string          `json:"service_ids" validate:"service_ids|required"`
+		Metadata     string            `json:"metadata" validate:"metadata|required"`
+		TargetRegion string            `json:"target_region" validate:"target_region|required"`
+		Encrypt      bool              `json:"encrypt"`
+		S3Config     MigrationS3Config `json:"s3_config"`
+	}
+}
+
+// AppMigrationSyncReq starts a sync round of the migration
+type AppMigrationSyncReq struct {
+	Body struct {
+		// Metadata the latest console level metadata of the app, optional
+		Metadata string            `json:"metadata"`
+		S3Config MigrationS3Config `json:"s3_config"`
+	}
+}
+
+// MigrationGatewayRules the gateway rules of the migrated components
+type MigrationGatewayRules struct {
+	HTTPRules        []*dbmodel.HTTPRule        `json:"http_rules"`
+	HTTPRuleRewrites []*dbmodel.HTTPRuleRewrite `json:"http_rule_rewrites"`
+	RuleExtensions   []*dbmodel.RuleExtension   `json:"rule_extensions"`
+	RuleConfigs      []*dbmodel.GwRuleConfig    `json:"rule_configs"`
+	TCPRules         []*dbmodel.TCPRule         `json:"tcp_rules"`
+}
+
+// migrationRepository the prefix of the repository the migration syncs the data into,
+// every migration has its own repository, so the backups of the app are not affected.
+func migrationRepository(migrationID string) string {
+	return path.Join("rainbond-migrations", migrationID)
+}
+
+// NewMigration creates the migration and starts the first presync round. The components
+// keep serving while presyncing, so that only the final delta is synced in the cutover.
+func (h *BackupHandle) NewMigration(req AppMigrationReq) (*dbmodel.AppMigration, *util.APIHandleError) {
+	if alias, err := db.GetManager().TenantServiceDao().GetServiceAliasByIDs(req.Body.ServiceIDs); len(alias) != len(req.Body.ServiceIDs) || err != nil {
+		return nil, util.CreateAPIHandleError(400, fmt.Errorf("some services do not exist in need migrate services"))
+	}
+	migration := &dbmodel.AppMigration{
+		MigrationID:  core_util.NewUUID(),
+		EventID:      req.Body.EventID,
+		TenantID:     req.TenantID,
+		GroupID:      req.Body.GroupID,
+		ServiceIDs:   strings.Join(req.Body.ServiceIDs, ","),
+		TargetRegion: req.Body.TargetRegion,
+		Encrypt:      req.Body.Encrypt,
+		Metadata:     req.Body.Metadata,
+	}
+	if err := db.GetManager().AppMigrationDao().AddModel(migration); err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("create migration", err)
+	}
+	if err := h.syncMigration(migration, dbmodel.AppMigrationStatusPresyncing, req.Body.S3Config, true); err != nil {
+		h.failMigration(migration, err.Error())
+		return nil, err
+	}
+	return migration, nil
+}
+
+// GetMigration returns the migration with the progress of its last sync round
+func (h *BackupHandle) GetMigration(migrationID string) (*dbmodel.AppMigration, *util.APIHandleError) {
+	migration, err := db.GetManager().AppMigrationDao().GetByMigrationID(migrationID)
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("get migration", err)
+	}
+	h.refreshMigration(migration)
+	return migration, nil
+}
+
+// ListMigrations lists the migrations of the app
+func (h *BackupHandle) ListMigrations(groupID string) ([]*dbmodel.AppMigration, *util.APIHandleError) {
+	migrations, err := db.GetManager().AppMigrationDao().ListByGroupID(groupID)
+	if err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("list migrations", err)
+	}
+	for _, migration := range migrations {
+		h.refreshMigration(migration)
+	}
+	return migrations, nil
+}
+
+// PresyncMigration starts another presync round, only the data changed since the last round is synced
+func (h *BackupHandle) PresyncMigration(migrationID string, req AppMigrationSyncReq) (*dbmodel.AppMigration, *util.APIHandleError) {
+	migration, err := h.GetMigration(migrationID)
+	if err != nil {
+		return nil, err
+	}
+	if migration.Status != dbmodel.AppMigrationStatusPresynced {
+		return nil, util.CreateAPIHandleErrorf(400, "can not presync the migration in status %s", migration.Status)
+	}
+	if req.Body.Metadata != "" {
+		migration.Metadata = req.Body.Metadata
+	}
+	if err := h.syncMigration(migration, dbmodel.AppMigrationStatusPresyncing, req.Body.S3Config, true); err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
+
+// CutoverMigration stops the components, and syncs the final delta once all of them are closed.
+// The migration is ready to be restored in the target region after the final round.
+func (h *BackupHandle) CutoverMigration(migrationID string, req AppMigrationSyncReq) (*dbmodel.AppMigration, *util.APIHandleError) {
+	migration, err := h.GetMigration(migrationID)
+	if err != nil {
+		return nil, err
+	}
+	if migration.Status != dbmodel.AppMigrationStatusPresynced {
+		return nil, util.CreateAPIHandleErrorf(400, "can not cut over the migration in status %s", migration.Status)
+	}
+	if req.Body.Metadata != "" {
+		migration.Metadata = req.Body.Metadata
+	}
+	var running []string
+	for serviceID, status := range h.statusCli.GetStatuss(migration.ServiceIDs) {
+		if status != v1.CLOSED && status != v1.UNDEPLOY {
+			running = append(running, serviceID)
+		}
+	}
+	migration.RunningServiceIDs = strings.Join(running, ",")
+	migration.Status = dbmodel.AppMigrationStatusStopping
+	migration.Message = ""
+	if err := db.GetManager().AppMigrationDao().UpdateModel(migration); err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("update migration", err)
+	}
+	if err := h.sendServiceTasks(migration, running, "stop"); err != nil {
+		h.failMigration(migration, err.Error())
+		return nil, util.CreateAPIHandleError(500, err)
+	}
+	go h.finishCutover(migration, req.Body.S3Config)
+	return migration, nil
+}
+
+// finishCutover waits for the components to stop, then syncs the final delta
+func (h *BackupHandle) finishCutover(migration *dbmodel.AppMigration, s3Config MigrationS3Config) {
+	logger := event.GetManager().GetLogger(migration.EventID)
+	defer event.GetManager().ReleaseLogger(logger)
+	deadline := time.Now().Add(migrationStopTimeout)
+	for {
+		// the migration may be rolled back while waiting
+		if !h.isStopping(migration.MigrationID) {
+			return
+		}
+		if h.allClosed(migration.ServiceIDs) {
+			break
+		}
+		if time.Now().After(deadline) {
+			logger.Error("Timeout waiting for the components to stop", map[string]string{"step": "migration", "status": "failure"})
+			h.failMigration(migration, "timeout waiting for the components to stop")
+			return
+		}
+		time.Sleep(3 * time.Second)
+	}
+	logger.Info("All the components are stopped, start syncing the final data", map[string]string{"step": "migration", "status": "starting"})
+	if err := h.syncMigration(migration, dbmodel.AppMigrationStatusFinalSyncing, s3Config, false); err != nil {
+		logger.Error(fmt.Sprintf("Failed to sync the final data: %v", err), map[string]string{"step": "migration", "status": "failure"})
+		h.failMigration(migration, err.Error())
+	}
+}
+
+func (h *BackupHandle) isStopping(migrationID string) bool {
+	migration, err := db.GetManager().AppMigrationDao().GetByMigrationID(migrationID)
+	if err != nil {
+		logrus.Warningf("migration %s: get status: %v", migrationID, err)
+		return false
+	}
+	return migration.Status == dbmodel.AppMigrationStatusStopping
+}
+
+func (h *BackupHandle) allClosed(serviceIDs string) bool {
+	for _, status := range h.statusCli.GetStatuss(serviceIDs) {
+		if status != v1.CLOSED && status != v1.UNDEPLOY {
+			return false
+		}
+	}
+	return true
+}
+
+// CompleteMigration switches the gateway rules after the app is started in the target region.
+// The rules of the source region are removed, so that the domains and the ports are released,
+// they are kept in the migration and recreated if the migration is rolled back.
+func (h *BackupHandle) CompleteMigration(migrationID string) (*dbmodel.AppMigration, *util.APIHandleError) {
+	migration, err := h.GetMigration(migrationID)
+	if err != nil {
+		return nil, err
+	}
+	if migration.Status != dbmodel.AppMigrationStatusReady {
+		return nil, util.CreateAPIHandleErrorf(400, "can not complete the migration in status %s", migration.Status)
+	}
+	serviceIDs := strings.Split(migration.ServiceIDs, ",")
+	rules, lerr := listGatewayRules(serviceIDs)
+	if lerr != nil {
+		return nil, util.CreateAPIHandleError(500, lerr)
+	}
+	body, _ := json.Marshal(rules)
+	migration.GatewayRules = string(body)
+	migration.Status = dbmodel.AppMigrationStatusCompleted
+
+	tx := db.GetManager().Begin()
+	defer db.GetManager().EnsureEndTransactionFunc()(tx)
+	if err := deleteGatewayRules(tx, serviceIDs, rules); err != nil {
+		tx.Rollback()
+		return nil, util.CreateAPIHandleError(500, err)
+	}
+	if err := db.GetManager().AppMigrationDaoTransactions(tx).UpdateModel(migration); err != nil {
+		tx.Rollback()
+		return nil, util.CreateAPIHandleErrorFromDBError("update migration", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("complete migration", err)
+	}
+	return migration, nil
+}
+
+// RollbackMigration makes the app serve in the source region again. The gateway rules are
+// recreated and the components stopped by the cutover are started. A cutover which is stopping
+// or final syncing can be rolled back too, so that it never gets stuck if rbd-api restarts
+// while waiting for the components to stop.
+func (h *BackupHandle) RollbackMigration(migrationID string) (*dbmodel.AppMigration, *util.APIHandleError) {
+	migration, err := h.GetMigration(migrationID)
+	if err != nil {
+		return nil, err
+	}
+	switch migration.Status {
+	case dbmodel.AppMigrationStatusPresyncing:
+		return nil, util.CreateAPIHandleErrorf(400, "can not roll back the migration while it is %s", migration.Status)
+	case dbmodel.AppMigrationStatusRolledBack:
+		return migration, nil
+	}
+	tx := db.GetManager().Begin()
+	defer db.GetManager().EnsureEndTransactionFunc()(tx)
+	if migration.Status == dbmodel.AppMigrationStatusCompleted && migration.GatewayRules != "" {
+		var rules MigrationGatewayRules
+		if err := json.Unmarshal([]byte(migration.GatewayRules), &rules); err != nil {
+			tx.Rollback()
+			return nil, util.CreateAPIHandleError(500, fmt.Errorf("decode gateway rules: %v", err))
+		}
+		if err := createGatewayRules(tx, &rules); err != nil {
+			tx.Rollback()
+			return nil, util.CreateAPIHandleError(500, err)
+		}
+	}
+	migration.GatewayRules = ""
+	migration.Status = dbmodel.AppMigrationStatusRolledBack
+	if err := db.GetManager().AppMigrationDaoTransactions(tx).UpdateModel(migration); err != nil {
+		tx.Rollback()
+		return nil, util.CreateAPIHandleErrorFromDBError("update migration", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, util.CreateAPIHandleErrorFromDBError("roll back migration", err)
+	}
+	if migration.RunningServiceIDs != "" {
+		if err := h.sendServiceTasks(migration, strings.Split(migration.RunningServiceIDs, ","), "start"); err != nil {
+			return nil, util.CreateAPIHandleError(500, err)
+		}
+	}
+	return migration, nil
+}
+
+// syncMigration starts a sync round, which is an incremental backup into the repository of the migration.
+// Only the latest round is kept in the repository, the chunks not changed are reused by the next round.
+func (h *BackupHandle) syncMigration(migration *dbmodel.AppMigration, status string, s3Config MigrationS3Config, force bool) *util.APIHandleError {
+	var b Backup
+	b.Body.EventID = migration.EventID
+	b.Body.GroupID = migration.GroupID
+	b.Body.Metadata = migration.Metadata
+	b.Body.ServiceIDs = strings.Split(migration.ServiceIDs, ",")
+	b.Body.Version = fmt.Sprintf("mig-%s-%d", migration.MigrationID[:16], migration.Rounds+1)
+	b.Body.Mode = "incremental-online"
+	b.Body.Force = force
+	b.Body.Retention = 1
+	b.Body.Repository = migrationRepository(migration.MigrationID)
+	b.Body.Encrypt = migration.Encrypt
+	b.Body.S3Config = s3Config
+	appBackup, err := h.NewBackup(b)
+	if err != nil {
+		return err
+	}
+	migration.Rounds++
+	migration.BackupID = appBackup.BackupID
+	migration.Status = status
+	migration.Message = ""
+	if err := db.GetManager().AppMigrationDao().UpdateModel(migration); err != nil {
+		return util.CreateAPIHandleErrorFromDBError("update migration", err)
+	}
+	return nil
+}
+
+// refreshMigration updates the status of the migration by the backup of its last sync round
+func (h *BackupHandle) refreshMigration(migration *dbmodel.AppMigration) {
+	if migration.Status != dbmodel.AppMigrationStatusPresyncing && migration.Status != dbmodel.AppMigrationStatusFinalSyncing {
+		return
+	}
+	appBackup, err := db.GetManager().AppBackupDao().GetAppBackup(migration.BackupID)
+	if err != nil {
+		logrus.Warningf("migration %s: get backup %s: %v", migration.MigrationID, migration.BackupID, err)
+		return
+	}
+	switch appBackup.Status {
+	case "success":
+		migration.SyncedSize = appBackup.BuckupSize
+		if migration.Status == dbmodel.AppMigrationStatusPresyncing {
+			migration.Status = dbmodel.AppMigrationStatusPresynced
+		} else {
+			migration.Status = dbmodel.AppMigrationStatusReady
+		}
+	case "failed":
+		migration.Status = dbmodel.AppMigrationStatusFailed
+		migration.Message = fmt.Sprintf("sync round %d failed, please check the event log", migration.Rounds)
+	default:
+		return
+	}
+	if err := db.GetManager().AppMigrationDao().UpdateModel(migration); err != nil {
+		logrus.Warningf("migration %s: update status: %v", migration.MigrationID, err)
+	}
+}
+
+func (h *BackupHandle) failMigration(migration *dbmodel.AppMigration, message string) {
+	migration.Status = dbmodel.AppMigrationStatusFailed
+	migration.Message = message
+	if err := db.GetManager().AppMigrationDao().UpdateModel(migration); err != nil {
+		logrus.Warningf("migration %s: update status: %v", migration.MigrationID, err)
+	}
+}
+
+// sendServiceTasks sends the start or stop tasks of the components to the worker
+func (h *BackupHandle) sendServiceTasks(migration *dbmodel.AppMigration, serviceIDs []string, taskType string) error {
+	for _, serviceID := range serviceIDs {
+		service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+		if err != nil {
+			return fmt.Errorf("get service %s: %v", serviceID, err)
+		}
+		var taskBody interface{} = discovermodel.StopTaskBody{
+			TenantID:      service.TenantID,
+			ServiceID:     serviceID,
+			DeployVersion: service.DeployVersion,
+			EventID:       migration.EventID,
+		}
+		if taskType == "start" {
+			taskBody = discovermodel.StartTaskBody{
+				TenantID:      service.TenantID,
+				ServiceID:     serviceID,
+				DeployVersion: service.DeployVersion,
+				EventID:       migration.EventID,
+			}
+		}
+		err = h.mqcli.SendBuilderTopic(mqclient.TaskStruct{
+			TaskType: taskType,
+			TaskBody: taskBody,
+			Topic:    mqclient.WorkerTopic,
+		})
+		if err != nil {
+			return fmt.Errorf("%s service %s: %v", taskType, serviceID, err)
+		}
+	}
+	return nil
+}
+
+// listGatewayRules lists the gateway rules of the components, with their rewrites, extensions and configs
+func listGatewayRules(serviceIDs []string) (*MigrationGatewayRules, error) {
+	var rules MigrationGatewayRules
+	httpRules, err := db.GetManager().HTTPRuleDao().ListByComponentIDs(serviceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list http rules: %v", err)
+	}
+	rules.HTTPRules = httpRules
+	for _, serviceID := range serviceIDs {
+		tcpRules, err := db.GetManager().TCPRuleDao().ListByServiceID(serviceID)
+		if err != nil {
+			return nil, fmt.Errorf("list tcp rules: %v", err)
+		}
+		rules.TCPRules = append(rules.TCPRules, tcpRules...)
+	}
+	for _, ruleID := range rules.ruleIDs() {
+		extensions, err := db.GetManager().RuleExtensionDao().GetRuleExtensionByRuleID(ruleID)
+		if err != nil {
+			return nil, fmt.Errorf("list rule extensions: %v", err)
+		}
+		rules.RuleExtensions = append(rules.RuleExtensions, extensions...)
+		configs, err := db.GetManager().GwRuleConfigDao().ListByRuleID(ruleID)
+		if err != nil {
+			return nil, fmt.Errorf("list rule configs: %v", err)
+		}
+		rules.RuleConfigs = append(rules.RuleConfigs, configs...)
+	}
+	for _, rule := range httpRules {
+		rewrites, err := db.GetManager().HTTPRuleRewriteDao().ListByHTTPRuleID(rule.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("list http rule rewrites: %v", err)
+		}
+		rules.HTTPRuleRewrites = append(rules.HTTPRuleRewrites, rewrites...)
+	}
+	return &rules, nil
+}
+
+func (m *MigrationGatewayRules) ruleIDs() []string {
+	var ids []string
+	for _, rule := range m.HTTPRules {
+		ids = append(ids, rule.UUID)
+	}
+	for _, rule := range m.TCPRules {
+		ids = append(ids, rule.UUID)
+	}
+	return ids
+}
+
+func deleteGatewayRules(tx *gorm.DB, serviceIDs []string, rules *MigrationGatewayRules) error {
+	ruleIDs := rules.ruleIDs()
+	var httpRuleIDs []string
+	for _, rule := range rules.HTTPRules {
+		httpRuleIDs = append(httpRuleIDs, rule.UUID)
+	}
+	if err := db.GetManager().HTTPRuleRewriteDaoTransactions(tx).DeleteByHTTPRuleIDs(httpRuleIDs); err != nil {
+		return fmt.Errorf("delete http rule rewrites: %v", err)
+	}
+	if err := db.GetManager().RuleExtensionDaoTransactions(tx).DeleteByRuleIDs(ruleIDs); err != nil {
+		return fmt.Errorf("delete rule extensions: %v", err)
+	}
+	if err := db.GetManager().GwRuleConfigDaoTransactions(tx).DeleteByRuleIDs(ruleIDs); err != nil {
+		return fmt.Errorf("delete rule configs: %v", err)
+	}
+	if err := db.GetManager().HTTPRuleDaoTransactions(tx).DeleteByComponentIDs(serviceIDs); err != nil {
+		return fmt.Errorf("delete http rules: %v", err)
+	}
+	if err := db.GetManager().TCPRuleDaoTransactions(tx).DeleteByComponentIDs(serviceIDs); err != nil {
+		return fmt.Errorf("delete tcp rules: %v", err)
+	}
+	return nil
+}
+
+func createGatewayRules(tx *gorm.DB, rules *MigrationGatewayRules) error {
+	if len(rules.HTTPRules) > 0 {
+		if err := db.GetManager().HTTPRuleDaoTransactions(tx).CreateOrUpdateHTTPRuleInBatch(rules.HTTPRules); err != nil {
+			return fmt.Errorf("create http rules: %v", err)
+		}
+	}
+	if len(rules.HTTPRuleRewrites) > 0 {
+		if err := db.GetManager().HTTPRuleRewriteDaoTransactions(tx).CreateOrUpdateHTTPRuleRewriteInBatch(rules.HTTPRuleRewrites); err != nil {
+			return fmt.Errorf("create http rule rewrites: %v", err)
+		}
+	}
+	if len(rules.RuleExtensions) > 0 {
+		if err := db.GetManager().RuleExtensionDaoTransactions(tx).CreateOrUpdateRuleExtensionsInBatch(rules.RuleExtensions); err != nil {
+			return fmt.Errorf("create rule extensions: %v", err)
+		}
+	}
+	if len(rules.RuleConfigs) > 0 {
+		if err := db.GetManager().GwRuleConfigDaoTransactions(tx).CreateOrUpdateGwRuleConfigsInBatch(rules.RuleConfigs); err != nil {
+			return fmt.Errorf("create rule configs: %v", err)
+		}
+	}
+	if len(rules.TCPRules) > 0 {
+		if err := db.GetManager().TCPRuleDaoTransactions(tx).CreateOrUpdateTCPRuleInBatch(rules.TCPRules); err != nil {
+			return fmt.Errorf("create tcp rules: %v", err)
+		}
+	}
+	return nil
+}
@@ -57,8 +57,14 @@ type Backup struct {
 		SourceDir  string   `json:"source_dir"`
 		BackupID   string   `json:"backup_id,omitempty"`
 
-		Mode     string `json:"mode" validate:"mode|required|in:full-online,full-offline"`
-		Force    bool   `json:"force"`
+		Mode  string `json:"mode" validate:"mode|required|in:full-online,full-offline,incremental-online"`
+		Force bool   `json:"force"`
+		// Retention the number of the incremental backups kept for the app, 0 means keeping all of them
+		Retention int `json:"retention"`
+		// Repository the prefix of the repository keeping the incremental backup, defaults to the one of the app
+		Repository string `json:"repository,omitempty"`
+		// Encrypt encrypts the backup with the keys configured for the builder
+		Encrypt  bool `json:"encrypt"`
 		S3Config struct {
 			Provider   string `json:"provider"`
 			Endpoint   string `json:"endpoint"`
@@ -486,13 +492,19 @@ type BackupCopy struct {
 		SourceType string ` json:"source_type" validate:"source_type|required"`
 		BackupMode string `json:"backup_mode" validate:"backup_mode|required"`
 		BuckupSize int64  `json:"backup_size" validate:"backup_size|required"`
+		// BackupID keeps the id of the copied backup, the incremental backups are restored
+		// from the snapshots named after their ids, such as the final round of a migration.
+		BackupID string `json:"backup_id"`
 	}
 }
 
 // BackupCopy BackupCopy
 func (h *BackupHandle) BackupCopy(b BackupCopy) (*dbmodel.AppBackup, *util.APIHandleError) {
 	var ab dbmodel.AppBackup
-	ab.BackupID = core_util.NewUUID()
+	ab.BackupID = b.Body.BackupID
+	if ab.BackupID == "" {
+		ab.BackupID = core_util.NewUUID()
+	}
 	ab.EventID = b.Body.EventID
 	ab.GroupID = b.Body.GroupID
 	ab.Status = b.Body.Status
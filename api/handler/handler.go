@@ -19,6 +19,8 @@
 package handler
 
 import (
+	"context"
+
 	"github.com/goodrain/rainbond/api/handler/group"
 	"github.com/goodrain/rainbond/api/handler/share"
 	"github.com/goodrain/rainbond/cmd/api/option"
@@ -83,6 +85,18 @@ func InitHandle(conf option.Config) error {
 	defApplicationHandler = NewApplicationHandler(statusCli, prometheusCli, rainbondClient, clientset, dynamicClient)
 	defRegistryAuthSecretHandler = CreateRegistryAuthSecretManager(dbmanager, mqClient)
 	defNodesHandler = NewNodesHandler(clientset, conf.RbdNamespace, restconfig, mapper, prometheusCli)
+	defAlertHandler = NewAlertHandler(restconfig)
+	defTenantQuotaHandler = NewTenantQuotaHandler(clientset, statusCli)
+	defNetworkIsolationHandler = NewNetworkIsolationHandler(conf.RbdNamespace)
+	defBuildTriggerHandler = NewBuildTriggerHandler(operationHandler)
+	go defBuildTriggerHandler.Run(context.Background())
+	defBuildPipelineHandler = NewBuildPipelineHandler(defaultServieHandler)
+	auditHandler, err := NewAuditHandler(conf.AuditSyslogAddr)
+	if err != nil {
+		logrus.Errorf("create audit handler error, %v", err)
+		return err
+	}
+	defAuditHandler = auditHandler
 	return nil
 }
 
@@ -250,3 +264,45 @@ var defRegistryAuthSecretHandler RegistryAuthSecretHandler
 func GetRegistryAuthSecretHandler() RegistryAuthSecretHandler {
 	return defRegistryAuthSecretHandler
 }
+
+var defAlertHandler AlertHandler
+
+// GetAlertHandler returns the default alert handler
+func GetAlertHandler() AlertHandler {
+	return defAlertHandler
+}
+
+var defAuditHandler AuditHandler
+
+// GetAuditHandler returns the default audit handler
+func GetAuditHandler() AuditHandler {
+	return defAuditHandler
+}
+
+var defTenantQuotaHandler TenantQuotaHandler
+
+// GetTenantQuotaHandler returns the default tenant quota handler
+func GetTenantQuotaHandler() TenantQuotaHandler {
+	return defTenantQuotaHandler
+}
+
+var defNetworkIsolationHandler NetworkIsolationHandler
+
+// GetNetworkIsolationHandler returns the default network isolation handler
+func GetNetworkIsolationHandler() NetworkIsolationHandler {
+	return defNetworkIsolationHandler
+}
+
+var defBuildTriggerHandler BuildTriggerHandler
+
+// GetBuildTriggerHandler returns the default build trigger handler
+func GetBuildTriggerHandler() BuildTriggerHandler {
+	return defBuildTriggerHandler
+}
+
+var defBuildPipelineHandler BuildPipelineHandler
+
+// GetBuildPipelineHandler returns the default build pipeline handler
+func GetBuildPipelineHandler() BuildPipelineHandler {
+	return defBuildPipelineHandler
+}
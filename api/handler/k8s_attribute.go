@@ -20,10 +20,18 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
+
 	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/db/model"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/yaml"
 )
 
@@ -111,11 +119,17 @@ func (s *ServiceAction) GetK8sAttribute(componentID, name string) (*model.Compon
 
 // CreateK8sAttribute -
 func (s *ServiceAction) CreateK8sAttribute(tenantID, componentID string, k8sAttr *api_model.ComponentK8sAttribute) error {
+	if err := validateK8sAttribute(k8sAttr); err != nil {
+		return err
+	}
 	return db.GetManager().ComponentK8sAttributeDao().AddModel(k8sAttr.DbModel(tenantID, componentID))
 }
 
 // UpdateK8sAttribute -
 func (s *ServiceAction) UpdateK8sAttribute(componentID string, k8sAttributes *api_model.ComponentK8sAttribute) error {
+	if err := validateK8sAttribute(k8sAttributes); err != nil {
+		return err
+	}
 	attr, err := db.GetManager().ComponentK8sAttributeDao().GetByComponentIDAndName(componentID, k8sAttributes.Name)
 	if err != nil {
 		return err
@@ -128,3 +142,84 @@ func (s *ServiceAction) UpdateK8sAttribute(componentID string, k8sAttributes *ap
 func (s *ServiceAction) DeleteK8sAttribute(componentID, name string) error {
 	return db.GetManager().ComponentK8sAttributeDao().DeleteByComponentIDAndName(componentID, name)
 }
+
+// validateK8sAttribute checks the attributes converted into the workload by the worker,
+// so that an invalid value is rejected here rather than failing the next start.
+func validateK8sAttribute(k8sAttr *api_model.ComponentK8sAttribute) error {
+	if k8sAttr.AttributeValue == "" {
+		return nil
+	}
+	switch k8sAttr.Name {
+	case model.K8sAttributeNameInitContainers, model.K8sAttributeNameSidecarContainers:
+		var containers []corev1.Container
+		if err := unmarshalAttributeValue(k8sAttr.AttributeValue, &containers); err != nil {
+			return bcode.NewBadRequest(fmt.Sprintf("invalid %s: %v", k8sAttr.Name, err))
+		}
+		names := make(map[string]bool)
+		for _, c := range containers {
+			if errs := validation.IsDNS1123Label(c.Name); len(errs) > 0 {
+				return bcode.NewBadRequest(fmt.Sprintf("invalid container name %q: %s", c.Name, strings.Join(errs, ",")))
+			}
+			if names[c.Name] {
+				return bcode.NewBadRequest(fmt.Sprintf("container name %s is duplicated", c.Name))
+			}
+			names[c.Name] = true
+			if c.Image == "" {
+				return bcode.NewBadRequest(fmt.Sprintf("the image of container %s is required", c.Name))
+			}
+		}
+	case model.K8sAttributeNameUpdateStrategy:
+		var strategy appsv1.DaemonSetUpdateStrategy
+		if err := unmarshalAttributeValue(k8sAttr.AttributeValue, &strategy); err != nil {
+			return bcode.NewBadRequest(fmt.Sprintf("invalid %s: %v", k8sAttr.Name, err))
+		}
+		if strategy.Type != "" && strategy.Type != appsv1.RollingUpdateDaemonSetStrategyType && strategy.Type != appsv1.OnDeleteDaemonSetStrategyType {
+			return bcode.NewBadRequest(fmt.Sprintf("unsupported update strategy type %s", strategy.Type))
+		}
+	case model.K8sAttributeNamePodDisruptionBudget:
+		var spec policyv1.PodDisruptionBudgetSpec
+		if err := unmarshalAttributeValue(k8sAttr.AttributeValue, &spec); err != nil {
+			return bcode.NewBadRequest(fmt.Sprintf("invalid %s: %v", k8sAttr.Name, err))
+		}
+		if spec.MinAvailable != nil && spec.MaxUnavailable != nil {
+			return bcode.NewBadRequest("minAvailable and maxUnavailable can not be set at the same time")
+		}
+		for field, value := range map[string]*intstr.IntOrString{"minAvailable": spec.MinAvailable, "maxUnavailable": spec.MaxUnavailable} {
+			if value == nil {
+				continue
+			}
+			if _, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true); err != nil || value.IntValue() < 0 {
+				return bcode.NewBadRequest(fmt.Sprintf("invalid %s %s", field, value.String()))
+			}
+		}
+	case model.K8sAttributeNameTopologySpreadConstraints:
+		var constraints []corev1.TopologySpreadConstraint
+		if err := unmarshalAttributeValue(k8sAttr.AttributeValue, &constraints); err != nil {
+			return bcode.NewBadRequest(fmt.Sprintf("invalid %s: %v", k8sAttr.Name, err))
+		}
+		for _, c := range constraints {
+			if c.MaxSkew <= 0 {
+				return bcode.NewBadRequest(fmt.Sprintf("the maxSkew of topology key %s must be greater than zero", c.TopologyKey))
+			}
+			if errs := validation.IsQualifiedName(c.TopologyKey); len(errs) > 0 {
+				return bcode.NewBadRequest(fmt.Sprintf("invalid topology key %q: %s", c.TopologyKey, strings.Join(errs, ",")))
+			}
+			if c.WhenUnsatisfiable != corev1.DoNotSchedule && c.WhenUnsatisfiable != corev1.ScheduleAnyway {
+				return bcode.NewBadRequest(fmt.Sprintf("unsupported whenUnsatisfiable %s", c.WhenUnsatisfiable))
+			}
+		}
+	case model.K8sAttributeNamePriorityClassName:
+		if errs := validation.IsDNS1123Subdomain(strings.TrimSpace(k8sAttr.AttributeValue)); len(errs) > 0 {
+			return bcode.NewBadRequest(fmt.Sprintf("invalid priority class name: %s", strings.Join(errs, ",")))
+		}
+	}
+	return nil
+}
+
+func unmarshalAttributeValue(value string, v interface{}) error {
+	valueJSON, err := yaml.YAMLToJSON([]byte(value))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(valueJSON, v)
+}
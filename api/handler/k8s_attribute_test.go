@@ -0,0 +1,58 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package handler
+
+import (
+	"testing"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/db/model"
+)
+
+func TestValidateK8sAttribute(t *testing.T) {
+	tests := []struct {
+		name, value string
+		valid       bool
+	}{
+		{model.K8sAttributeNameSidecarContainers, "- name: exporter\n  image: prom/node-exporter\n", true},
+		{model.K8sAttributeNameInitContainers, `[{"name":"init-db","image":"busybox"}]`, true},
+		{model.K8sAttributeNameInitContainers, "- name: Init_DB\n  image: busybox\n", false},
+		{model.K8sAttributeNameSidecarContainers, "- name: exporter\n", false},
+		{model.K8sAttributeNameSidecarContainers, "- name: a\n  image: busybox\n- name: a\n  image: busybox\n", false},
+		{model.K8sAttributeNameSidecarContainers, "name: a", false},
+		{model.K8sAttributeNameUpdateStrategy, "type: OnDelete", true},
+		{model.K8sAttributeNameUpdateStrategy, "type: Recreate", false},
+		{model.K8sAttributeNamePodDisruptionBudget, "maxUnavailable: 25%", true},
+		{model.K8sAttributeNamePodDisruptionBudget, "{}", true},
+		{model.K8sAttributeNamePodDisruptionBudget, "minAvailable: 1\nmaxUnavailable: 1", false},
+		{model.K8sAttributeNamePodDisruptionBudget, "minAvailable: -1", false},
+		{model.K8sAttributeNameTopologySpreadConstraints, "- maxSkew: 1\n  topologyKey: topology.kubernetes.io/zone\n  whenUnsatisfiable: DoNotSchedule\n", true},
+		{model.K8sAttributeNameTopologySpreadConstraints, "[]", true},
+		{model.K8sAttributeNameTopologySpreadConstraints, "- maxSkew: 0\n  topologyKey: kubernetes.io/hostname\n  whenUnsatisfiable: ScheduleAnyway\n", false},
+		{model.K8sAttributeNameTopologySpreadConstraints, "- maxSkew: 1\n  topologyKey: kubernetes.io/hostname\n", false},
+		{model.K8sAttributeNamePriorityClassName, "high-priority", true},
+		{model.K8sAttributeNamePriorityClassName, "High_Priority", false},
+		{model.K8sAttributeNameNodeSelector, "whatever", true},
+	}
+	for _, tc := range tests {
+		err := validateK8sAttribute(&api_model.ComponentK8sAttribute{Name: tc.name, AttributeValue: tc.value})
+		if (err == nil) != tc.valid {
+			t.Errorf("validate %s %q: expected valid %v, got %v", tc.name, tc.value, tc.valid, err)
+		}
+	}
+}
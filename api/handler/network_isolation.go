@@ -0,0 +1,107 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"sort"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/worker/appm/networkpolicy"
+	"github.com/pkg/errors"
+)
+
+// NetworkIsolationHandler the network isolation of the components, the worker generates
+// the network policies allowing the ingress only from the dependent components.
+type NetworkIsolationHandler interface {
+	UpdateTenantNetworkIsolation(tenant *dbmodel.Tenants, mode string) error
+	GetNetworkIsolationReport(tenant *dbmodel.Tenants, appID string) (*api_model.NetworkIsolationReport, error)
+}
+
+// NewNetworkIsolationHandler creates a network isolation handler
+func NewNetworkIsolationHandler(systemNamespace string) NetworkIsolationHandler {
+	return &networkIsolationAction{systemNamespace: systemNamespace}
+}
+
+type networkIsolationAction struct {
+	systemNamespace string
+}
+
+// UpdateTenantNetworkIsolation updates the network isolation mode of the tenant
+func (n *networkIsolationAction) UpdateTenantNetworkIsolation(tenant *dbmodel.Tenants, mode string) error {
+	tenant.NetworkIsolation = mode
+	return db.GetManager().TenantDao().UpdateModel(tenant)
+}
+
+// GetNetworkIsolationReport reports the components each component would stop accepting the connections from
+// once the isolation is enforced, whatever the current mode is.
+func (n *networkIsolationAction) GetNetworkIsolationReport(tenant *dbmodel.Tenants, appID string) (*api_model.NetworkIsolationReport, error) {
+	services, err := db.GetManager().TenantServiceDao().GetServicesByTenantID(tenant.UUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list components")
+	}
+	rules, err := networkpolicy.ListRules(db.GetManager(), tenant, appID, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "list network isolation rules")
+	}
+	report := &api_model.NetworkIsolationReport{
+		TenantMode:        tenant.NetworkIsolation,
+		AllowedNamespaces: []string{n.systemNamespace},
+	}
+	for _, rule := range rules {
+		report.Components = append(report.Components, componentIsolationReport(rule, services))
+	}
+	return report, nil
+}
+
+func componentIsolationReport(rule *networkpolicy.Rule, services []*dbmodel.TenantServices) *api_model.ComponentIsolationReport {
+	component := &api_model.ComponentIsolationReport{
+		ServiceID:    rule.Service.ServiceID,
+		ServiceAlias: rule.Service.ServiceAlias,
+		ServiceName:  rule.Service.ServiceName,
+		Mode:         rule.Mode,
+		Allowed:      []string{},
+		Blocked:      []string{},
+	}
+	for _, port := range rule.Ports {
+		component.Ports = append(component.Ports, port.ContainerPort)
+	}
+	sort.Ints(component.Ports)
+	allowed := make(map[string]bool)
+	for _, peer := range rule.Peers {
+		// the dependents only access the inner ports, see networkpolicy.Build
+		if len(rule.Ports) == 0 {
+			break
+		}
+		allowed[peer.ServiceID] = true
+		component.Allowed = append(component.Allowed, peer.ServiceAlias)
+	}
+	for _, service := range services {
+		if service.ServiceID == rule.Service.ServiceID || allowed[service.ServiceID] {
+			continue
+		}
+		if service.Kind == dbmodel.ServiceKindThirdParty.String() {
+			continue
+		}
+		component.Blocked = append(component.Blocked, service.ServiceAlias)
+	}
+	sort.Strings(component.Blocked)
+	return component
+}
@@ -397,11 +397,14 @@ func (c *clusterAction) createTelescopic(telescopic model.TelescopicManagement,
 	}
 	for _, metric := range telescopic.CPUOrMemory {
 		m := &dbmodel.TenantServiceAutoscalerRuleMetrics{
-			RuleID:            r.RuleID,
-			MetricsType:       metric.MetricsType,
-			MetricsName:       metric.MetricsName,
-			MetricTargetType:  metric.MetricTargetType,
-			MetricTargetValue: metric.MetricTargetValue,
+			RuleID:              r.RuleID,
+			MetricsType:         metric.MetricsType,
+			MetricsName:         metric.MetricsName,
+			MetricTargetType:    metric.MetricTargetType,
+			MetricTargetValue:   metric.MetricTargetValue,
+			MetricSelector:      metric.MetricSelector,
+			DescribedObjectKind: metric.DescribedObjectKind,
+			DescribedObjectName: metric.DescribedObjectName,
 		}
 		if err := db.GetManager().TenantServceAutoscalerRuleMetricsDao().AddModel(m); err != nil {
 			logrus.Errorf("%v TenantServceAutoscalerRuleMetricsDao creation failed:%v", service.ServiceAlias, err)
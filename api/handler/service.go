@@ -35,6 +35,7 @@ import (
 
 	"github.com/coreos/etcd/clientv3"
 	"github.com/goodrain/rainbond/api/client/prometheus"
+	"github.com/goodrain/rainbond/api/handler/app_governance_mode/adaptor"
 	api_model "github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/api/util"
 	"github.com/goodrain/rainbond/api/util/bcode"
@@ -48,6 +49,7 @@ import (
 	gclient "github.com/goodrain/rainbond/mq/client"
 	"github.com/goodrain/rainbond/pkg/generated/clientset/versioned"
 	core_util "github.com/goodrain/rainbond/util"
+	k8sutil "github.com/goodrain/rainbond/util/k8s"
 	"github.com/goodrain/rainbond/worker/client"
 	"github.com/goodrain/rainbond/worker/discover/model"
 	"github.com/goodrain/rainbond/worker/server"
@@ -60,6 +62,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/apiserver/pkg/util/flushwriter"
 	"k8s.io/client-go/kubernetes"
 )
@@ -476,6 +479,9 @@ func (s *ServiceAction) ServiceHorizontal(hs *model.HorizontalScalingTaskBody) e
 		logrus.Errorf("get service by id %s error, %s", hs.ServiceID, err)
 		return err
 	}
+	if service.IsDaemonSet() {
+		return bcode.ErrHorizontalDaemonSet
+	}
 
 	// for rollback database
 	oldReplicas := service.Replicas
@@ -524,6 +530,9 @@ func (s *ServiceAction) ServiceUpgrade(ru *model.RollingUpgradeTaskBody) error {
 		logrus.Errorf("get service by id %s error %s", ru.ServiceID, err.Error())
 		return err
 	}
+	if err := CheckVersionDeployable(ru.ServiceID, ru.NewDeployVersion); err != nil {
+		return err
+	}
 	version, err := db.GetManager().VersionInfoDao().GetVersionByDeployVersion(ru.NewDeployVersion, ru.ServiceID)
 	if err != nil {
 		logrus.Errorf("get service version by id %s version %s error, %s", ru.ServiceID, ru.NewDeployVersion, err.Error())
@@ -621,6 +630,10 @@ func (s *ServiceAction) ServiceCreate(sc *api_model.ServiceStruct) error {
 			env := env
 			env.ServiceID = ts.ServiceID
 			env.TenantID = ts.TenantID
+			if err := ValidateExternalSecretEnv(&env); err != nil {
+				tx.Rollback()
+				return err
+			}
 			batchEnvs = append(batchEnvs, &env)
 		}
 		if err := db.GetManager().TenantServiceEnvVarDaoTransactions(tx).CreateOrUpdateEnvsInBatch(batchEnvs); err != nil {
@@ -655,6 +668,7 @@ func (s *ServiceAction) ServiceCreate(sc *api_model.ServiceStruct) error {
 			sharePath = "/grdata"
 		}
 
+		var quotaVolumes []*dbmodel.TenantServiceVolume
 		for _, volumn := range volumns {
 			v := dbmodel.TenantServiceVolume{
 				ServiceID:      ts.ServiceID,
@@ -712,6 +726,7 @@ func (s *ServiceAction) ServiceCreate(sc *api_model.ServiceStruct) error {
 				tx.Rollback()
 				return err
 			}
+			quotaVolumes = append(quotaVolumes, &v)
 			if volumn.FileContent != "" {
 				cf := &dbmodel.TenantServiceConfigFile{
 					ServiceID:   sc.ServiceID,
@@ -724,6 +739,10 @@ func (s *ServiceAction) ServiceCreate(sc *api_model.ServiceStruct) error {
 				}
 			}
 		}
+		if err := checkVolumeQuota(ts.TenantID, &ts, quotaVolumes...); err != nil {
+			tx.Rollback()
+			return errors.Cause(err)
+		}
 	}
 	//set app dependVolumes
 	if len(dependVolumes) > 0 {
@@ -823,6 +842,10 @@ func (s *ServiceAction) ServiceCreate(sc *api_model.ServiceStruct) error {
 	}
 	if len(probes) > 0 {
 		for _, pb := range probes {
+			if err := checkProbeScheme(pb.Scheme); err != nil {
+				tx.Rollback()
+				return err
+			}
 			probe := s.convertProbeModel(&pb, ts.ServiceID)
 			if err := db.GetManager().ServiceProbeDaoTransactions(tx).AddModel(probe); err != nil {
 				logrus.Errorf("add probe %v error, %v", probe.ProbeID, err)
@@ -911,6 +934,9 @@ func (s *ServiceAction) convertProbeModel(req *api_model.ServiceProbe, serviceID
 		SuccessThreshold:   req.SuccessThreshold,
 		TimeoutSecond:      req.TimeoutSecond,
 		FailureAction:      req.FailureAction,
+		GRPCService:        req.GRPCService,
+		GRPCTLS:            req.GRPCTLS,
+		TLSExpireDays:      req.TLSExpireDays,
 	}
 }
 
@@ -1219,11 +1245,43 @@ func (s *ServiceAction) ServiceDepend(action string, ds *api_model.DependService
 			return err
 		}
 	}
+	s.applyMeshPolicies(ds.DepServiceID)
 	return nil
 }
 
+// applyMeshPolicies refreshes the authorization policies of the component after its clients changed.
+func (s *ServiceAction) applyMeshPolicies(serviceID string) {
+	app, err := db.GetManager().ApplicationDao().GetByServiceID(serviceID)
+	if err != nil {
+		return
+	}
+	mode, err := adaptor.NewAppGoveranceModeHandler(app.GovernanceMode, nil)
+	if err != nil {
+		return
+	}
+	if _, ok := mode.(adaptor.AuthorizationPolicyHandler); !ok {
+		return
+	}
+	err = s.MQClient.SendBuilderTopic(gclient.TaskStruct{
+		Topic:    gclient.WorkerTopic,
+		TaskType: "apply_rule",
+		TaskBody: map[string]interface{}{
+			"service_id": serviceID,
+			"action":     "mesh-policy",
+		},
+	})
+	if err != nil {
+		logrus.Warningf("send apply mesh policies task for component %s: %v", serviceID, err)
+	}
+}
+
 // EnvAttr env attr
 func (s *ServiceAction) EnvAttr(action string, at *dbmodel.TenantServiceEnvVar) error {
+	if action == "add" || action == "update" {
+		if err := ValidateExternalSecretEnv(at); err != nil {
+			return err
+		}
+	}
 	switch action {
 	case "add":
 		if err := db.GetManager().TenantServiceEnvVarDao().AddModel(at); err != nil {
@@ -1664,7 +1722,7 @@ func (s *ServiceAction) PortInner(tenantName, serviceID, operation string, port
 }
 
 // VolumnVar var volumn
-func (s *ServiceAction) VolumnVar(tsv *dbmodel.TenantServiceVolume, tenantID, fileContent, action string) *util.APIHandleError {
+func (s *ServiceAction) VolumnVar(tsv *dbmodel.TenantServiceVolume, tenantID string, configFile *dbmodel.TenantServiceConfigFile, action string) *util.APIHandleError {
 	localPath := os.Getenv("LOCAL_DATA_PATH")
 	sharePath := os.Getenv("SHARE_DATA_PATH")
 	if localPath == "" {
@@ -1698,6 +1756,13 @@ func (s *ServiceAction) VolumnVar(tsv *dbmodel.TenantServiceVolume, tenantID, fi
 			}
 		}
 		util.SetVolumeDefaultValue(tsv)
+		service, err := db.GetManager().TenantServiceDao().GetServiceByID(tsv.ServiceID)
+		if err != nil {
+			return util.CreateAPIHandleErrorFromDBError("get service", err)
+		}
+		if err := checkVolumeQuota(tenantID, service, tsv); err != nil {
+			return util.CreateAPIHandleError(bcode.Err2Coder(err).GetStatus(), err)
+		}
 		// begin transaction
 		tx := db.GetManager().Begin()
 		defer func() {
@@ -1710,11 +1775,12 @@ func (s *ServiceAction) VolumnVar(tsv *dbmodel.TenantServiceVolume, tenantID, fi
 			tx.Rollback()
 			return util.CreateAPIHandleErrorFromDBError("add volume", err)
 		}
-		if fileContent != "" {
+		if configFile != nil && (configFile.FileContent != "" || configFile.IsExternalSecret()) {
 			cf := &dbmodel.TenantServiceConfigFile{
-				ServiceID:   tsv.ServiceID,
-				VolumeName:  tsv.VolumeName,
-				FileContent: fileContent,
+				ServiceID:         tsv.ServiceID,
+				VolumeName:        tsv.VolumeName,
+				FileContent:       configFile.FileContent,
+				ExternalSecretRef: configFile.ExternalSecretRef,
 			}
 			if err := db.GetManager().TenantServiceConfigFileDaoTransactions(tx).AddModel(cf); err != nil {
 				tx.Rollback()
@@ -1808,6 +1874,11 @@ func (s *ServiceAction) UpdVolume(sid string, req *api_model.UpdVolumeReq) error
 			return err
 		}
 		configfile.FileContent = req.FileContent
+		configfile.ExternalSecretRef = req.ExternalSecretRef
+		if err := ValidateExternalSecretConfigFile(configfile); err != nil {
+			tx.Rollback()
+			return err
+		}
 		if err := db.GetManager().TenantServiceConfigFileDaoTransactions(tx).UpdateModel(configfile); err != nil {
 			tx.Rollback()
 			return err
@@ -1918,6 +1989,11 @@ func (s *ServiceAction) GetDepVolumes(serviceID string) ([]*dbmodel.TenantServic
 
 // ServiceProbe ServiceProbe
 func (s *ServiceAction) ServiceProbe(tsp *dbmodel.TenantServiceProbe, action string) error {
+	if action != "delete" {
+		if err := checkProbeScheme(tsp.Scheme); err != nil {
+			return err
+		}
+	}
 	switch action {
 	case "add":
 		if err := db.GetManager().ServiceProbeDao().AddModel(tsp); err != nil {
@@ -1935,6 +2011,23 @@ func (s *ServiceAction) ServiceProbe(tsp *dbmodel.TenantServiceProbe, action str
 	return nil
 }
 
+// checkVolumeQuota checks the storage quota of the tenant before adding the volumes to the component
+func checkVolumeQuota(tenantID string, service *dbmodel.TenantServices, volumes ...*dbmodel.TenantServiceVolume) error {
+	tenant, err := db.GetManager().TenantDao().GetTenantByUUID(tenantID)
+	if err != nil {
+		return err
+	}
+	return GetTenantQuotaHandler().CheckVolumeQuota(context.Background(), tenant, service, volumes...)
+}
+
+// checkProbeScheme rejects probe schemes the cluster kubelet can not run.
+func checkProbeScheme(scheme string) error {
+	if scheme == "grpc" && !k8sutil.GetKubeVersion().AtLeast(utilversion.MustParseSemantic("v1.24.0")) {
+		return bcode.ErrGRPCProbeNotSupported
+	}
+	return nil
+}
+
 // RollBack RollBack
 func (s *ServiceAction) RollBack(rs *api_model.RollbackStruct) error {
 	service, err := db.GetManager().TenantServiceDao().GetServiceByID(rs.ServiceID)
@@ -1945,6 +2038,9 @@ func (s *ServiceAction) RollBack(rs *api_model.RollbackStruct) error {
 	if service.DeployVersion == rs.DeployVersion {
 		return fmt.Errorf("current version is %v, don't need rollback", rs.DeployVersion)
 	}
+	if err := CheckVersionDeployable(rs.ServiceID, rs.DeployVersion); err != nil {
+		return err
+	}
 	service.DeployVersion = rs.DeployVersion
 	if err := db.GetManager().TenantServiceDao().UpdateModel(service); err != nil {
 		return err
@@ -2303,6 +2399,14 @@ func (s *ServiceAction) deleteComponent(tx *gorm.DB, service *dbmodel.TenantServ
 		db.GetManager().ServiceProbeDaoTransactions(tx).DELServiceProbesByServiceID,
 		db.GetManager().ServiceEventDaoTransactions(tx).DelEventByServiceID,
 		db.GetManager().TenantServiceMonitorDaoTransactions(tx).DeleteServiceMonitorByServiceID,
+		db.GetManager().TenantServiceScalingScheduleDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServiceScaleToZeroDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServiceResourceRecommendationDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServiceRecommendationPolicyDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServiceSnapshotPolicyDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServiceVolumeSnapshotDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServiceBuildPipelineDaoTransactions(tx).DeleteByServiceID,
+		db.GetManager().TenantServicePipelineRunDaoTransactions(tx).DeleteByServiceID,
 		db.GetManager().AppConfigGroupServiceDaoTransactions(tx).DeleteEffectiveServiceByServiceID,
 	}
 	if err := GetGatewayHandler().DeleteTCPRuleByServiceIDWithTransaction(service.ServiceID, tx); err != nil {
@@ -2475,13 +2579,7 @@ func (s *ServiceAction) AddAutoscalerRule(req *api_model.AutoscalerRuleReq) erro
 	}
 
 	for _, metric := range req.Metrics {
-		m := &dbmodel.TenantServiceAutoscalerRuleMetrics{
-			RuleID:            req.RuleID,
-			MetricsType:       metric.MetricsType,
-			MetricsName:       metric.MetricsName,
-			MetricTargetType:  metric.MetricTargetType,
-			MetricTargetValue: metric.MetricTargetValue,
-		}
+		m := metric.DbModel(req.RuleID)
 		if err := db.GetManager().TenantServceAutoscalerRuleMetricsDaoTransactions(tx).AddModel(m); err != nil {
 			tx.Rollback()
 			return err
@@ -2532,13 +2630,7 @@ func (s *ServiceAction) UpdAutoscalerRule(req *api_model.AutoscalerRuleReq) erro
 	}
 
 	for _, metric := range req.Metrics {
-		m := &dbmodel.TenantServiceAutoscalerRuleMetrics{
-			RuleID:            req.RuleID,
-			MetricsType:       metric.MetricsType,
-			MetricsName:       metric.MetricsName,
-			MetricTargetType:  metric.MetricTargetType,
-			MetricTargetValue: metric.MetricTargetValue,
-		}
+		m := metric.DbModel(req.RuleID)
 		if err := db.GetManager().TenantServceAutoscalerRuleMetricsDaoTransactions(tx).AddModel(m); err != nil {
 			tx.Rollback()
 			return err
@@ -2577,6 +2669,247 @@ func (s *ServiceAction) ListScalingRecords(serviceID string, page, pageSize int)
 	return records, count, nil
 }
 
+// ListScalingSchedules -
+func (s *ServiceAction) ListScalingSchedules(serviceID string) ([]*dbmodel.TenantServiceScalingSchedule, error) {
+	return db.GetManager().TenantServiceScalingScheduleDao().ListByServiceID(serviceID)
+}
+
+// AddScalingSchedule -
+func (s *ServiceAction) AddScalingSchedule(serviceID string, req *api_model.ScalingScheduleReq) (*dbmodel.TenantServiceScalingSchedule, error) {
+	if req.ScheduleID == "" {
+		req.ScheduleID = core_util.NewUUID()
+	}
+	schedule := req.DbModel(serviceID)
+	// the schedule runs from now on
+	now := time.Now()
+	schedule.LastScheduleTime = &now
+	if err := db.GetManager().TenantServiceScalingScheduleDao().AddModel(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// UpdScalingSchedule -
+func (s *ServiceAction) UpdScalingSchedule(serviceID, scheduleID string, req *api_model.ScalingScheduleReq) (*dbmodel.TenantServiceScalingSchedule, error) {
+	schedule, err := s.getScalingSchedule(serviceID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Schedule != req.Schedule || schedule.Timezone != req.Timezone {
+		// evaluate the new schedule from now on
+		now := time.Now()
+		schedule.LastScheduleTime = &now
+	}
+	schedule.Name = req.Name
+	schedule.Schedule = req.Schedule
+	schedule.Timezone = req.Timezone
+	schedule.Replicas = req.Replicas
+	schedule.Enable = req.Enable
+	if err := db.GetManager().TenantServiceScalingScheduleDao().UpdateModel(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// DeleteScalingSchedule -
+func (s *ServiceAction) DeleteScalingSchedule(serviceID, scheduleID string) error {
+	if _, err := s.getScalingSchedule(serviceID, scheduleID); err != nil {
+		return err
+	}
+	return db.GetManager().TenantServiceScalingScheduleDao().DeleteByScheduleID(scheduleID)
+}
+
+func (s *ServiceAction) getScalingSchedule(serviceID, scheduleID string) (*dbmodel.TenantServiceScalingSchedule, error) {
+	schedule, err := db.GetManager().TenantServiceScalingScheduleDao().GetByScheduleID(scheduleID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrScalingScheduleNotFound
+		}
+		return nil, err
+	}
+	if schedule.ServiceID != serviceID {
+		return nil, bcode.ErrScalingScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// GetScaleToZero returns the scale-to-zero setting of the component, disabled by default
+func (s *ServiceAction) GetScaleToZero(serviceID string) (*dbmodel.TenantServiceScaleToZero, error) {
+	stz, err := db.GetManager().TenantServiceScaleToZeroDao().GetByServiceID(serviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &dbmodel.TenantServiceScaleToZero{ServiceID: serviceID}, nil
+		}
+		return nil, err
+	}
+	return stz, nil
+}
+
+// UpdScaleToZero enables or disables scale-to-zero of the component.
+// The gateway rules are reapplied so that the gateway holds the requests of the idle component.
+func (s *ServiceAction) UpdScaleToZero(serviceID string, req *api_model.ScaleToZeroReq) (*dbmodel.TenantServiceScaleToZero, error) {
+	stz, err := s.GetScaleToZero(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	wakeReplicas := stz.WakeReplicas
+	stz.Enable = req.Enable
+	if req.IdleSeconds > 0 {
+		stz.IdleSeconds = req.IdleSeconds
+	}
+	if !req.Enable {
+		stz.WakeReplicas = 0
+	}
+	if stz.ID == 0 {
+		err = db.GetManager().TenantServiceScaleToZeroDao().AddModel(stz)
+	} else {
+		err = db.GetManager().TenantServiceScaleToZeroDao().UpdateModel(stz)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := GetGatewayHandler().SendTaskDeprecated(map[string]interface{}{
+		"service_id": serviceID,
+		"action":     "update-http-rule",
+	}); err != nil {
+		logrus.Errorf("send runtime message about gateway failure %s", err.Error())
+	}
+
+	// restore the component that has been scaled to zero
+	if !req.Enable && wakeReplicas > 0 {
+		service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+		if err != nil {
+			return nil, err
+		}
+		if service.Replicas == 0 {
+			err := s.ServiceHorizontal(&model.HorizontalScalingTaskBody{
+				TenantID:   service.TenantID,
+				ServiceID:  serviceID,
+				Replicas:   int32(wakeReplicas),
+				RecordType: dbmodel.ScaleToZeroScalingRecord,
+			})
+			if err != nil && err != bcode.ErrHorizontalDueToNoChange {
+				return nil, err
+			}
+		}
+	}
+	return stz, nil
+}
+
+// GetResourceRecommendation returns the resources recommended for the component
+func (s *ServiceAction) GetResourceRecommendation(serviceID string) (*api_model.ResourceRecommendation, error) {
+	rec, err := db.GetManager().TenantServiceResourceRecommendationDao().GetByServiceID(serviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrResourceRecommendationNotFound
+		}
+		return nil, err
+	}
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resourceRecommendation(rec, service)
+}
+
+// ListResourceRecommendations returns the resources recommended for the components of the tenant
+func (s *ServiceAction) ListResourceRecommendations(tenantID string) ([]*api_model.ResourceRecommendation, error) {
+	recs, err := db.GetManager().TenantServiceResourceRecommendationDao().ListByTenantID(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	services, err := db.GetManager().TenantServiceDao().GetServicesByTenantID(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	serviceMap := make(map[string]*dbmodel.TenantServices, len(services))
+	for _, service := range services {
+		serviceMap[service.ServiceID] = service
+	}
+	var res []*api_model.ResourceRecommendation
+	for _, rec := range recs {
+		service, ok := serviceMap[rec.ServiceID]
+		if !ok {
+			continue
+		}
+		item, err := s.resourceRecommendation(rec, service)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
+
+func (s *ServiceAction) resourceRecommendation(rec *dbmodel.TenantServiceResourceRecommendation, service *dbmodel.TenantServices) (*api_model.ResourceRecommendation, error) {
+	res := &api_model.ResourceRecommendation{
+		TenantServiceResourceRecommendation: rec,
+		ServiceAlias:                        service.ServiceAlias,
+		ContainerCPU:                        service.ContainerCPU,
+		ContainerMemory:                     service.ContainerMemory,
+	}
+	policy, err := db.GetManager().TenantServiceRecommendationPolicyDao().GetByServiceID(service.ServiceID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if err == nil {
+		res.Policy = policy
+	}
+	return res, nil
+}
+
+// UpdRecommendationPolicy updates whether the recommendation of the component is applied automatically
+func (s *ServiceAction) UpdRecommendationPolicy(serviceID string, req *api_model.RecommendationPolicyReq) (*dbmodel.TenantServiceRecommendationPolicy, error) {
+	policy, err := db.GetManager().TenantServiceRecommendationPolicyDao().GetByServiceID(serviceID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+		policy = &dbmodel.TenantServiceRecommendationPolicy{ServiceID: serviceID}
+	}
+	policy.AutoApply = req.AutoApply
+	policy.ApplyWindowStart = req.ApplyWindowStart
+	policy.ApplyWindowEnd = req.ApplyWindowEnd
+	policy.Timezone = req.Timezone
+	if policy.ID == 0 {
+		err = db.GetManager().TenantServiceRecommendationPolicyDao().AddModel(policy)
+	} else {
+		err = db.GetManager().TenantServiceRecommendationPolicyDao().UpdateModel(policy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ApplyResourceRecommendation applies the recommendation to the component by vertical scaling.
+// The cpu and memory of the component are set to the recommended limits.
+func (s *ServiceAction) ApplyResourceRecommendation(ctx context.Context, serviceID, eventID string) (*model.VerticalScalingTaskBody, error) {
+	rec, err := db.GetManager().TenantServiceResourceRecommendationDao().GetByServiceID(serviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrResourceRecommendationNotFound
+		}
+		return nil, err
+	}
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	task := &model.VerticalScalingTaskBody{
+		TenantID:        service.TenantID,
+		ServiceID:       serviceID,
+		EventID:         eventID,
+		ContainerCPU:    &rec.CPULimit,
+		ContainerMemory: &rec.MemoryLimit,
+	}
+	if err := s.ServiceVertical(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
 // SyncComponentBase -
 func (s *ServiceAction) SyncComponentBase(tx *gorm.DB, app *dbmodel.Application, components []*api_model.Component) error {
 	var (
@@ -2640,7 +2973,11 @@ func (s *ServiceAction) SyncComponentEnvs(tx *gorm.DB, app *dbmodel.Application,
 		}
 		componentIDs = append(componentIDs, component.ComponentBase.ComponentID)
 		for _, env := range component.Envs {
-			envs = append(envs, env.DbModel(app.TenantID, component.ComponentBase.ComponentID))
+			envD := env.DbModel(app.TenantID, component.ComponentBase.ComponentID)
+			if err := ValidateExternalSecretEnv(envD); err != nil {
+				return err
+			}
+			envs = append(envs, envD)
 		}
 	}
 	if err := db.GetManager().TenantServiceEnvVarDaoTransactions(tx).DeleteByComponentIDs(componentIDs); err != nil {
@@ -2774,7 +3111,11 @@ func (s *ServiceAction) SyncComponentConfigFiles(tx *gorm.DB, components []*api_
 		}
 		componentIDs = append(componentIDs, component.ComponentBase.ComponentID)
 		for _, configFile := range component.ConfigFiles {
-			configFiles = append(configFiles, configFile.DbModel(component.ComponentBase.ComponentID))
+			cf := configFile.DbModel(component.ComponentBase.ComponentID)
+			if err := ValidateExternalSecretConfigFile(cf); err != nil {
+				return err
+			}
+			configFiles = append(configFiles, cf)
 		}
 	}
 	if err := db.GetManager().TenantServiceConfigFileDaoTransactions(tx).DeleteByComponentIDs(componentIDs); err != nil {
@@ -2797,6 +3138,9 @@ func (s *ServiceAction) SyncComponentProbes(tx *gorm.DB, components []*api_model
 			if ok {
 				continue
 			}
+			if err := checkProbeScheme(probe.Scheme); err != nil {
+				return err
+			}
 			probes = append(probes, probe.DbModel(component.ComponentBase.ComponentID))
 			modes[probe.Mode] = struct{}{}
 		}
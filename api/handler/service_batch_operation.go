@@ -27,6 +27,7 @@ import (
 
 	"github.com/goodrain/rainbond/api/model"
 	apiutil "github.com/goodrain/rainbond/api/util"
+	"github.com/goodrain/rainbond/api/util/bcode"
 	"github.com/goodrain/rainbond/db"
 	dbmodel "github.com/goodrain/rainbond/db/model"
 	gclient "github.com/goodrain/rainbond/mq/client"
@@ -469,6 +470,7 @@ type AllocMemory struct {
 	tenant          *dbmodel.Tenants
 	allcm           *int64
 	memoryType      string
+	quota           *TenantQuotaAllowance
 	components      map[string]*dbmodel.TenantServices
 	batchOpResult   model.BatchOpResult
 	batchOpRequests model.BatchOpRequesters
@@ -502,6 +504,12 @@ func NewAllocMemory(ctx context.Context, statusCli *client.AppRuntimeSyncClient,
 		am.memoryType = "cluster_lack_of_memory"
 	}
 
+	quota, err := GetTenantQuotaHandler().NewQuotaAllowance(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	am.quota = quota
+
 	components, err := am.listComponents(batchOpReqs.ComponentIDs())
 	if err != nil {
 		return nil, err
@@ -573,6 +581,14 @@ func (a *AllocMemory) check(componentID string) error {
 		return errors.New("tenant_lack_of_memory")
 	}
 
+	if a.quota != nil {
+		need := GetTenantQuotaHandler().ServiceQuotaNeed(component, component.Replicas, component.ContainerCPU, component.ContainerMemory, component.ContainerGPU)
+		if err := a.quota.Admit(need); err != nil {
+			logrus.Errorf("component %s: %v", component.ServiceAlias, err)
+			return bcode.Err2Coder(err)
+		}
+	}
+
 	*a.allcm -= int64(requestMemory)
 
 	return nil
@@ -56,7 +56,7 @@ type ServiceHandler interface {
 	CreatePorts(tenantID, serviceID string, vps *api_model.ServicePorts) error
 	PortOuter(tenantName, serviceID string, containerPort int, servicePort *api_model.ServicePortInnerOrOuter) (*dbmodel.TenantServiceLBMappingPort, string, error)
 	PortInner(tenantName, serviceID, operation string, port int) error
-	VolumnVar(avs *dbmodel.TenantServiceVolume, tenantID, fileContent, action string) *util.APIHandleError
+	VolumnVar(avs *dbmodel.TenantServiceVolume, tenantID string, configFile *dbmodel.TenantServiceConfigFile, action string) *util.APIHandleError
 	UpdVolume(sid string, req *api_model.UpdVolumeReq) error
 	VolumeDependency(tsr *dbmodel.TenantServiceMountRelation, action string) *util.APIHandleError
 	GetDepVolumes(serviceID string) ([]*dbmodel.TenantServiceMountRelation, *util.APIHandleError)
@@ -89,6 +89,24 @@ type ServiceHandler interface {
 	AddAutoscalerRule(req *api_model.AutoscalerRuleReq) error
 	UpdAutoscalerRule(req *api_model.AutoscalerRuleReq) error
 	ListScalingRecords(serviceID string, page, pageSize int) ([]*dbmodel.TenantServiceScalingRecords, int, error)
+	ListScalingSchedules(serviceID string) ([]*dbmodel.TenantServiceScalingSchedule, error)
+	AddScalingSchedule(serviceID string, req *api_model.ScalingScheduleReq) (*dbmodel.TenantServiceScalingSchedule, error)
+	UpdScalingSchedule(serviceID, scheduleID string, req *api_model.ScalingScheduleReq) (*dbmodel.TenantServiceScalingSchedule, error)
+	DeleteScalingSchedule(serviceID, scheduleID string) error
+	GetScaleToZero(serviceID string) (*dbmodel.TenantServiceScaleToZero, error)
+	UpdScaleToZero(serviceID string, req *api_model.ScaleToZeroReq) (*dbmodel.TenantServiceScaleToZero, error)
+	GetResourceRecommendation(serviceID string) (*api_model.ResourceRecommendation, error)
+	ListResourceRecommendations(tenantID string) ([]*api_model.ResourceRecommendation, error)
+	UpdRecommendationPolicy(serviceID string, req *api_model.RecommendationPolicyReq) (*dbmodel.TenantServiceRecommendationPolicy, error)
+	ApplyResourceRecommendation(ctx context.Context, serviceID, eventID string) (*model.VerticalScalingTaskBody, error)
+	ListVolumeSnapshots(serviceID, volumeName string) ([]*dbmodel.TenantServiceVolumeSnapshot, error)
+	CreateVolumeSnapshot(serviceID, eventID string, req *api_model.CreateVolumeSnapshotReq) (*dbmodel.TenantServiceVolumeSnapshot, error)
+	RestoreVolumeSnapshot(serviceID, snapshotID, eventID string, req *api_model.RestoreVolumeSnapshotReq) error
+	DeleteVolumeSnapshot(serviceID, snapshotID, eventID string) error
+	ListSnapshotPolicies(serviceID string) ([]*dbmodel.TenantServiceSnapshotPolicy, error)
+	AddSnapshotPolicy(serviceID string, req *api_model.SnapshotPolicyReq) (*dbmodel.TenantServiceSnapshotPolicy, error)
+	UpdSnapshotPolicy(serviceID, policyID string, req *api_model.SnapshotPolicyReq) (*dbmodel.TenantServiceSnapshotPolicy, error)
+	DeleteSnapshotPolicy(serviceID, policyID string) error
 
 	UpdateServiceMonitor(tenantID, serviceID, name string, update api_model.UpdateServiceMonitorRequestStruct) (*dbmodel.TenantServiceMonitor, error)
 	DeleteServiceMonitor(tenantID, serviceID, name string) (*dbmodel.TenantServiceMonitor, error)
@@ -214,6 +214,9 @@ func (o *OperationHandler) upgrade(batchOpReq model.ComponentOpReq) error {
 	}
 
 	batchOpReq.SetVersion(component.DeployVersion)
+	if err := CheckVersionDeployable(component.ServiceID, batchOpReq.GetVersion()); err != nil {
+		return err
+	}
 
 	version, err := db.GetManager().VersionInfoDao().GetVersionByDeployVersion(batchOpReq.GetVersion(), batchOpReq.GetComponentID())
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -272,6 +275,10 @@ func (o *OperationHandler) RollBack(rollback model.RollbackInfoRequestStruct) (r
 		_ = db.GetManager().TenantServiceDao().UpdateModel(service)
 	}
 
+	if err := CheckVersionDeployable(service.ServiceID, rollback.RollBackVersion); err != nil {
+		re.ErrMsg = err.Error()
+		return
+	}
 	if service.DeployVersion == rollback.RollBackVersion {
 		logrus.Warningf("rollback version is same of current version")
 	}
@@ -0,0 +1,546 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/worker/client"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// tenantQuotaName the name of the ResourceQuota and the LimitRange generated in the tenant namespace
+const tenantQuotaName = "rainbond-tenant-quota"
+
+// the limits of the containers which do not set them, the ResourceQuota rejects
+// the pods without the limits of the constrained resources.
+const (
+	quotaDefaultContainerCPU              = 500
+	quotaDefaultContainerMemory           = 512
+	quotaDefaultContainerEphemeralStorage = 1
+)
+
+const (
+	megabyte = 1024 * 1024
+	gigabyte = 1024 * 1024 * 1024
+)
+
+// TenantQuotaHandler tenant quotas, enforced at api admission and by the ResourceQuota and LimitRange in the tenant namespace
+type TenantQuotaHandler interface {
+	GetTenantQuota(ctx context.Context, tenant *dbmodel.Tenants) (*api_model.TenantQuotaStatus, error)
+	UpdateTenantQuota(ctx context.Context, tenant *dbmodel.Tenants, req *api_model.TenantQuota) (*api_model.TenantQuotaStatus, error)
+	CheckServiceQuota(ctx context.Context, tenant *dbmodel.Tenants, service *dbmodel.TenantServices, replicas, cpu, memory, gpu int) error
+	CheckVolumeQuota(ctx context.Context, tenant *dbmodel.Tenants, service *dbmodel.TenantServices, volumes ...*dbmodel.TenantServiceVolume) error
+	NewQuotaAllowance(ctx context.Context, tenant *dbmodel.Tenants) (*TenantQuotaAllowance, error)
+	ServiceQuotaNeed(service *dbmodel.TenantServices, replicas, cpu, memory, gpu int) *api_model.TenantQuota
+}
+
+// TenantQuotaAction is an implementation of TenantQuotaHandler
+type TenantQuotaAction struct {
+	clientset kubernetes.Interface
+	statusCli *client.AppRuntimeSyncClient
+}
+
+// NewTenantQuotaHandler creates a tenant quota handler
+func NewTenantQuotaHandler(clientset kubernetes.Interface, statusCli *client.AppRuntimeSyncClient) TenantQuotaHandler {
+	return &TenantQuotaAction{
+		clientset: clientset,
+		statusCli: statusCli,
+	}
+}
+
+// TenantQuotaAllowance the quota of a tenant and its usage, the operations are admitted one by one against it
+type TenantQuotaAllowance struct {
+	hard *api_model.TenantQuota
+	used *api_model.TenantQuota
+}
+
+// Admit checks whether the quota allows the need, and takes the need from the quota if it does
+func (a *TenantQuotaAllowance) Admit(need *api_model.TenantQuota) error {
+	checks := []struct {
+		hard, used, need int
+		err              error
+	}{
+		{a.hard.CPU, a.used.CPU, need.CPU, bcode.ErrTenantLackOfCPU},
+		{a.hard.Memory, a.used.Memory, need.Memory, bcode.ErrTenantLackOfMemory},
+		{a.hard.GPU, a.used.GPU, need.GPU, bcode.ErrTenantLackOfGPU},
+		{a.hard.Pods, a.used.Pods, need.Pods, bcode.ErrTenantLackOfPods},
+		{a.hard.Storage, a.used.Storage, need.Storage, bcode.ErrTenantLackOfStorage},
+	}
+	for _, c := range checks {
+		if c.hard > 0 && c.need > 0 && c.used+c.need > c.hard {
+			return errors.Wrap(c.err, fmt.Sprintf("quota %d, used %d, need %d", c.hard, c.used, c.need))
+		}
+	}
+	for _, sc := range need.StorageClasses {
+		hard, used := storageClassQuota(a.hard, sc.StorageClass), storageClassQuota(a.used, sc.StorageClass)
+		if hard > 0 && sc.Storage > 0 && used+sc.Storage > hard {
+			return errors.Wrap(bcode.ErrTenantLackOfStorage, fmt.Sprintf("storage class %s quota %d, used %d, need %d", sc.StorageClass, hard, used, sc.Storage))
+		}
+	}
+	a.used.CPU += need.CPU
+	a.used.Memory += need.Memory
+	a.used.GPU += need.GPU
+	a.used.Pods += need.Pods
+	a.used.Storage += need.Storage
+	for _, sc := range need.StorageClasses {
+		addStorageClassQuota(a.used, sc.StorageClass, sc.Storage)
+	}
+	return nil
+}
+
+func storageClassQuota(quota *api_model.TenantQuota, storageClass string) int {
+	for _, sc := range quota.StorageClasses {
+		if sc.StorageClass == storageClass {
+			return sc.Storage
+		}
+	}
+	return 0
+}
+
+func addStorageClassQuota(quota *api_model.TenantQuota, storageClass string, storage int) {
+	for i := range quota.StorageClasses {
+		if quota.StorageClasses[i].StorageClass == storageClass {
+			quota.StorageClasses[i].Storage += storage
+			return
+		}
+	}
+	quota.StorageClasses = append(quota.StorageClasses, api_model.StorageClassQuota{StorageClass: storageClass, Storage: storage})
+}
+
+// GetTenantQuota returns the quota of the tenant and its usage
+func (q *TenantQuotaAction) GetTenantQuota(ctx context.Context, tenant *dbmodel.Tenants) (*api_model.TenantQuotaStatus, error) {
+	hard, err := getTenantQuota(tenant.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if hard == nil {
+		hard = &api_model.TenantQuota{}
+	}
+	used, err := q.getTenantQuotaUsage(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	return &api_model.TenantQuotaStatus{Hard: hard, Used: used}, nil
+}
+
+// UpdateTenantQuota replaces the quota of the tenant, and applies it to the tenant namespace. The quota is
+// applied before it is saved, and the previous quota is applied again if saving fails, so that the quota
+// in the namespace never differs from the saved one.
+func (q *TenantQuotaAction) UpdateTenantQuota(ctx context.Context, tenant *dbmodel.Tenants, req *api_model.TenantQuota) (*api_model.TenantQuotaStatus, error) {
+	if err := validateTenantQuota(req); err != nil {
+		return nil, err
+	}
+	previous, err := getTenantQuota(tenant.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		previous = &api_model.TenantQuota{}
+	}
+	if err := q.syncQuotaObjects(ctx, tenant.Namespace, req); err != nil {
+		return nil, err
+	}
+	if err := saveTenantQuota(tenant.UUID, req); err != nil {
+		if rerr := q.syncQuotaObjects(ctx, tenant.Namespace, previous); rerr != nil {
+			logrus.Errorf("tenant %s: restore the previous quota: %v", tenant.Name, rerr)
+		}
+		return nil, errors.Wrap(err, "save tenant quota")
+	}
+	return q.GetTenantQuota(ctx, tenant)
+}
+
+func saveTenantQuota(tenantID string, req *api_model.TenantQuota) error {
+	return db.GetManager().DB().Transaction(func(tx *gorm.DB) error {
+		quota, err := db.GetManager().TenantQuotaDaoTransactions(tx).GetByTenantID(tenantID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		if quota == nil {
+			quota = &dbmodel.TenantQuota{TenantID: tenantID}
+		}
+		quota.CPU = req.CPU
+		quota.Memory = req.Memory
+		quota.EphemeralStorage = req.EphemeralStorage
+		quota.Storage = req.Storage
+		quota.Pods = req.Pods
+		quota.GPU = req.GPU
+		if quota.ID == 0 {
+			err = db.GetManager().TenantQuotaDaoTransactions(tx).AddModel(quota)
+		} else {
+			err = db.GetManager().TenantQuotaDaoTransactions(tx).UpdateModel(quota)
+		}
+		if err != nil {
+			return err
+		}
+		if err := db.GetManager().TenantStorageClassQuotaDaoTransactions(tx).DeleteByTenantID(tenantID); err != nil {
+			return err
+		}
+		for _, sc := range req.StorageClasses {
+			if err := db.GetManager().TenantStorageClassQuotaDaoTransactions(tx).AddModel(&dbmodel.TenantStorageClassQuota{
+				TenantID:     tenantID,
+				StorageClass: sc.StorageClass,
+				Storage:      sc.Storage,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CheckServiceQuota checks whether the quota of the tenant allows running the component with the given replicas and resources
+func (q *TenantQuotaAction) CheckServiceQuota(ctx context.Context, tenant *dbmodel.Tenants, service *dbmodel.TenantServices, replicas, cpu, memory, gpu int) error {
+	allowance, err := q.NewQuotaAllowance(ctx, tenant)
+	if err != nil || allowance == nil {
+		return err
+	}
+	need := q.ServiceQuotaNeed(service, replicas, cpu, memory, gpu)
+	if err := allowance.Admit(need); err != nil {
+		logrus.Warningf("tenant %s, component %s: %v", tenant.Name, service.ServiceAlias, err)
+		return err
+	}
+	return nil
+}
+
+// CheckVolumeQuota checks whether the storage quota of the tenant allows adding the volumes to the component
+func (q *TenantQuotaAction) CheckVolumeQuota(ctx context.Context, tenant *dbmodel.Tenants, service *dbmodel.TenantServices, volumes ...*dbmodel.TenantServiceVolume) error {
+	need := volumeQuotaNeed(service, volumes)
+	if need.Storage == 0 {
+		return nil
+	}
+	allowance, err := q.NewQuotaAllowance(ctx, tenant)
+	if err != nil || allowance == nil {
+		return err
+	}
+	if err := allowance.Admit(need); err != nil {
+		logrus.Warningf("tenant %s, component %s: %v", tenant.Name, service.ServiceAlias, err)
+		return err
+	}
+	return nil
+}
+
+// volumeQuotaNeed returns the storage requested by the claims of the volumes, every pod of
+// the stateful component has its own claims.
+func volumeQuotaNeed(service *dbmodel.TenantServices, volumes []*dbmodel.TenantServiceVolume) *api_model.TenantQuota {
+	need := &api_model.TenantQuota{}
+	claims := 1
+	if service.IsState() && service.Replicas > 1 {
+		claims = service.Replicas
+	}
+	for _, v := range volumes {
+		if v.VolumeCapacity <= 0 || v.VolumeType == dbmodel.ConfigFileVolumeType.String() || v.VolumeType == dbmodel.MemoryFSVolumeType.String() {
+			continue
+		}
+		storage := int(v.VolumeCapacity) * claims
+		need.Storage += storage
+		// the custom volume types are the storage classes of the claims
+		if v.VolumeType != dbmodel.ShareFileVolumeType.String() && v.VolumeType != dbmodel.LocalVolumeType.String() {
+			addStorageClassQuota(need, v.VolumeType, storage)
+		}
+	}
+	return need
+}
+
+// NewQuotaAllowance returns the allowance of the tenant, or nil if the tenant has no quota
+func (q *TenantQuotaAction) NewQuotaAllowance(ctx context.Context, tenant *dbmodel.Tenants) (*TenantQuotaAllowance, error) {
+	hard, err := getTenantQuota(tenant.UUID)
+	if err != nil || hard == nil {
+		return nil, err
+	}
+	used, err := q.getTenantQuotaUsage(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	return &TenantQuotaAllowance{hard: hard, used: used}, nil
+}
+
+// ServiceQuotaNeed returns the resources added by running the component with the given replicas and resources,
+// the resources taken by the component are excluded if it is running.
+func (q *TenantQuotaAction) ServiceQuotaNeed(service *dbmodel.TenantServices, replicas, cpu, memory, gpu int) *api_model.TenantQuota {
+	need := &api_model.TenantQuota{}
+	if service.Kind == dbmodel.ServiceKindThirdParty.String() {
+		return need
+	}
+	need.CPU = replicas * quotaContainerCPU(cpu)
+	need.Memory = replicas * quotaContainerMemory(memory)
+	need.GPU = replicas * gpu
+	need.Pods = replicas
+	if q.statusCli != nil && !q.statusCli.IsClosedStatus(q.statusCli.GetStatus(service.ServiceID)) {
+		need.CPU -= service.Replicas * quotaContainerCPU(service.ContainerCPU)
+		need.Memory -= service.Replicas * quotaContainerMemory(service.ContainerMemory)
+		need.GPU -= service.Replicas * service.ContainerGPU
+		need.Pods -= service.Replicas
+	}
+	return need
+}
+
+func quotaContainerCPU(cpu int) int {
+	if cpu > 0 {
+		return cpu
+	}
+	return quotaDefaultContainerCPU
+}
+
+func quotaContainerMemory(memory int) int {
+	if memory > 0 {
+		return memory
+	}
+	return quotaDefaultContainerMemory
+}
+
+func validateTenantQuota(req *api_model.TenantQuota) error {
+	if req.CPU < 0 || req.Memory < 0 || req.EphemeralStorage < 0 || req.Storage < 0 || req.Pods < 0 || req.GPU < 0 {
+		return errors.Wrap(bcode.ErrInvalidTenantQuota, "the quota can not be negative")
+	}
+	classes := make(map[string]struct{})
+	for _, sc := range req.StorageClasses {
+		if sc.Storage < 0 {
+			return errors.Wrap(bcode.ErrInvalidTenantQuota, "the quota can not be negative")
+		}
+		if _, ok := classes[sc.StorageClass]; ok {
+			return errors.Wrap(bcode.ErrInvalidTenantQuota, "duplicate storage class "+sc.StorageClass)
+		}
+		classes[sc.StorageClass] = struct{}{}
+	}
+	return nil
+}
+
+// getTenantQuota returns the quota of the tenant, or nil if the tenant has no quota
+func getTenantQuota(tenantID string) (*api_model.TenantQuota, error) {
+	quota, err := db.GetManager().TenantQuotaDao().GetByTenantID(tenantID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	classes, err := db.GetManager().TenantStorageClassQuotaDao().ListByTenantID(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	hard := &api_model.TenantQuota{
+		CPU:              quota.CPU,
+		Memory:           quota.Memory,
+		EphemeralStorage: quota.EphemeralStorage,
+		Storage:          quota.Storage,
+		Pods:             quota.Pods,
+		GPU:              quota.GPU,
+	}
+	for _, sc := range classes {
+		hard.StorageClasses = append(hard.StorageClasses, api_model.StorageClassQuota{StorageClass: sc.StorageClass, Storage: sc.Storage})
+	}
+	return hard, nil
+}
+
+// getTenantQuotaUsage returns the usage tracked by the ResourceQuota in the tenant namespace,
+// or the cpu and memory reported by the worker if the tenant namespace has no ResourceQuota.
+func (q *TenantQuotaAction) getTenantQuotaUsage(ctx context.Context, tenant *dbmodel.Tenants) (*api_model.TenantQuota, error) {
+	rq, err := q.clientset.CoreV1().ResourceQuotas(tenant.Namespace).Get(ctx, tenantQuotaName, metav1.GetOptions{})
+	if err == nil {
+		return quotaFromResourceList(rq.Status.Used), nil
+	}
+	if !k8sErrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "get resource quota")
+	}
+	used := &api_model.TenantQuota{}
+	if q.statusCli != nil {
+		res, err := q.statusCli.GetTenantResource(tenant.UUID)
+		if err != nil {
+			return nil, errors.Wrap(err, "get tenant resource")
+		}
+		used.CPU = int(res.CpuLimit)
+		used.Memory = int(res.MemoryLimit)
+	}
+	return used, nil
+}
+
+// syncQuotaObjects applies the quota to the ResourceQuota and the LimitRange in the tenant namespace,
+// they are deleted if the quota is unlimited.
+func (q *TenantQuotaAction) syncQuotaObjects(ctx context.Context, namespace string, quota *api_model.TenantQuota) error {
+	hard := quotaToResourceList(quota)
+	if len(hard) == 0 {
+		err := q.clientset.CoreV1().ResourceQuotas(namespace).Delete(ctx, tenantQuotaName, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(err, "delete resource quota")
+		}
+		err = q.clientset.CoreV1().LimitRanges(namespace).Delete(ctx, tenantQuotaName, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(err, "delete limit range")
+		}
+		return nil
+	}
+	labels := map[string]string{"creator": "Rainbond"}
+	rq, err := q.clientset.CoreV1().ResourceQuotas(namespace).Get(ctx, tenantQuotaName, metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(err, "get resource quota")
+		}
+		rq = &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: tenantQuotaName, Namespace: namespace, Labels: labels},
+			Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+		}
+		if _, err := q.clientset.CoreV1().ResourceQuotas(namespace).Create(ctx, rq, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(err, "create resource quota")
+		}
+	} else {
+		rq.Spec.Hard = hard
+		if _, err := q.clientset.CoreV1().ResourceQuotas(namespace).Update(ctx, rq, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrap(err, "update resource quota")
+		}
+	}
+
+	limits := quotaDefaultLimits(quota)
+	lr, err := q.clientset.CoreV1().LimitRanges(namespace).Get(ctx, tenantQuotaName, metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(err, "get limit range")
+		}
+		if len(limits) == 0 {
+			return nil
+		}
+		lr = &corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: tenantQuotaName, Namespace: namespace, Labels: labels},
+			Spec:       corev1.LimitRangeSpec{Limits: limits},
+		}
+		if _, err := q.clientset.CoreV1().LimitRanges(namespace).Create(ctx, lr, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(err, "create limit range")
+		}
+		return nil
+	}
+	if len(limits) == 0 {
+		if err := q.clientset.CoreV1().LimitRanges(namespace).Delete(ctx, tenantQuotaName, metav1.DeleteOptions{}); err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(err, "delete limit range")
+		}
+		return nil
+	}
+	lr.Spec.Limits = limits
+	if _, err := q.clientset.CoreV1().LimitRanges(namespace).Update(ctx, lr, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "update limit range")
+	}
+	return nil
+}
+
+func quotaToResourceList(quota *api_model.TenantQuota) corev1.ResourceList {
+	hard := corev1.ResourceList{}
+	if quota.CPU > 0 {
+		hard[corev1.ResourceLimitsCPU] = *resource.NewMilliQuantity(int64(quota.CPU), resource.DecimalSI)
+	}
+	if quota.Memory > 0 {
+		hard[corev1.ResourceLimitsMemory] = *resource.NewQuantity(int64(quota.Memory)*megabyte, resource.BinarySI)
+	}
+	if quota.EphemeralStorage > 0 {
+		hard[corev1.ResourceLimitsEphemeralStorage] = *resource.NewQuantity(int64(quota.EphemeralStorage)*gigabyte, resource.BinarySI)
+	}
+	if quota.Storage > 0 {
+		hard[corev1.ResourceRequestsStorage] = *resource.NewQuantity(int64(quota.Storage)*gigabyte, resource.BinarySI)
+	}
+	if quota.Pods > 0 {
+		hard[corev1.ResourcePods] = *resource.NewQuantity(int64(quota.Pods), resource.DecimalSI)
+	}
+	if quota.GPU > 0 {
+		// the extended resources can only be constrained by the requests, which default to the limits
+		hard[corev1.ResourceName(corev1.DefaultResourceRequestsPrefix+string(quotaGPUResourceName()))] = *resource.NewQuantity(int64(quota.GPU), resource.DecimalSI)
+	}
+	for _, sc := range quota.StorageClasses {
+		if sc.Storage > 0 {
+			hard[storageClassResourceName(sc.StorageClass)] = *resource.NewQuantity(int64(sc.Storage)*gigabyte, resource.BinarySI)
+		}
+	}
+	return hard
+}
+
+func quotaFromResourceList(list corev1.ResourceList) *api_model.TenantQuota {
+	quota := &api_model.TenantQuota{}
+	for name, quantity := range list {
+		switch {
+		case name == corev1.ResourceLimitsCPU:
+			quota.CPU = int(quantity.MilliValue())
+		case name == corev1.ResourceLimitsMemory:
+			quota.Memory = ceilDiv(quantity.Value(), megabyte)
+		case name == corev1.ResourceLimitsEphemeralStorage:
+			quota.EphemeralStorage = ceilDiv(quantity.Value(), gigabyte)
+		case name == corev1.ResourceRequestsStorage:
+			quota.Storage = ceilDiv(quantity.Value(), gigabyte)
+		case name == corev1.ResourcePods:
+			quota.Pods = int(quantity.Value())
+		case name == corev1.ResourceName(corev1.DefaultResourceRequestsPrefix+string(quotaGPUResourceName())):
+			quota.GPU = int(quantity.Value())
+		case strings.HasSuffix(string(name), storageClassResourceSuffix):
+			quota.StorageClasses = append(quota.StorageClasses, api_model.StorageClassQuota{
+				StorageClass: strings.TrimSuffix(string(name), storageClassResourceSuffix),
+				Storage:      ceilDiv(quantity.Value(), gigabyte),
+			})
+		}
+	}
+	return quota
+}
+
+// quotaDefaultLimits returns the default limits of the containers for the resources constrained by the quota
+func quotaDefaultLimits(quota *api_model.TenantQuota) []corev1.LimitRangeItem {
+	defaults := corev1.ResourceList{}
+	if quota.CPU > 0 {
+		defaults[corev1.ResourceCPU] = *resource.NewMilliQuantity(quotaDefaultContainerCPU, resource.DecimalSI)
+	}
+	if quota.Memory > 0 {
+		defaults[corev1.ResourceMemory] = *resource.NewQuantity(quotaDefaultContainerMemory*megabyte, resource.BinarySI)
+	}
+	if quota.EphemeralStorage > 0 {
+		defaults[corev1.ResourceEphemeralStorage] = *resource.NewQuantity(quotaDefaultContainerEphemeralStorage*gigabyte, resource.BinarySI)
+	}
+	if len(defaults) == 0 {
+		return nil
+	}
+	return []corev1.LimitRangeItem{{
+		Type:    corev1.LimitTypeContainer,
+		Default: defaults,
+	}}
+}
+
+const storageClassResourceSuffix = ".storageclass.storage.k8s.io/requests.storage"
+
+func storageClassResourceName(storageClass string) corev1.ResourceName {
+	return corev1.ResourceName(storageClass + storageClassResourceSuffix)
+}
+
+// quotaGPUResourceName returns the gpu resource set by the worker
+func quotaGPUResourceName() corev1.ResourceName {
+	if os.Getenv("GPU_LABLE_KEY") != "" {
+		return corev1.ResourceName(os.Getenv("GPU_LABLE_KEY"))
+	}
+	return "rainbond.com/gpu-mem"
+}
+
+func ceilDiv(value, unit int64) int {
+	return int((value + unit - 1) / unit)
+}
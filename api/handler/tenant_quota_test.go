@@ -0,0 +1,99 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+func TestTenantQuotaAllowanceAdmit(t *testing.T) {
+	allowance := &TenantQuotaAllowance{
+		hard: &api_model.TenantQuota{CPU: 2000, Memory: 2048, Pods: 4},
+		used: &api_model.TenantQuota{CPU: 1000, Memory: 1024, Pods: 2},
+	}
+	if err := allowance.Admit(&api_model.TenantQuota{CPU: 500, Memory: 512, Pods: 1, GPU: 1}); err != nil {
+		t.Fatalf("expected admitted, got %v", err)
+	}
+	if err := allowance.Admit(&api_model.TenantQuota{CPU: 1000, Memory: 256, Pods: 1}); !bcode.ErrTenantLackOfCPU.Equal(err) {
+		t.Fatalf("expected lack of cpu, got %v", err)
+	}
+	if err := allowance.Admit(&api_model.TenantQuota{Memory: 1024}); !bcode.ErrTenantLackOfMemory.Equal(err) {
+		t.Fatalf("expected lack of memory, got %v", err)
+	}
+	if err := allowance.Admit(&api_model.TenantQuota{Pods: 2}); !bcode.ErrTenantLackOfPods.Equal(err) {
+		t.Fatalf("expected lack of pods, got %v", err)
+	}
+	// scaling down is always allowed
+	if err := allowance.Admit(&api_model.TenantQuota{CPU: -1000, Pods: -1}); err != nil {
+		t.Fatalf("expected admitted, got %v", err)
+	}
+}
+
+func TestTenantQuotaVolumeAdmit(t *testing.T) {
+	allowance := &TenantQuotaAllowance{
+		hard: &api_model.TenantQuota{Storage: 100, StorageClasses: []api_model.StorageClassQuota{{StorageClass: "ceph-rbd", Storage: 30}}},
+		used: &api_model.TenantQuota{Storage: 60, StorageClasses: []api_model.StorageClassQuota{{StorageClass: "ceph-rbd", Storage: 10}}},
+	}
+	service := &dbmodel.TenantServices{ExtendMethod: "state_multiple", Replicas: 2}
+	volumes := []*dbmodel.TenantServiceVolume{
+		{VolumeType: "ceph-rbd", VolumeCapacity: 10},
+		{VolumeType: dbmodel.ConfigFileVolumeType.String(), VolumeCapacity: 10},
+	}
+	// every pod of the stateful component has its own claim
+	need := volumeQuotaNeed(service, volumes)
+	if need.Storage != 20 || storageClassQuota(need, "ceph-rbd") != 20 {
+		t.Fatalf("unexpected need %+v", need)
+	}
+	if err := allowance.Admit(need); err != nil {
+		t.Fatalf("expected admitted, got %v", err)
+	}
+	if err := allowance.Admit(volumeQuotaNeed(service, volumes[:1])); !bcode.ErrTenantLackOfStorage.Equal(err) {
+		t.Fatalf("expected lack of storage, got %v", err)
+	}
+	if err := allowance.Admit(volumeQuotaNeed(service, []*dbmodel.TenantServiceVolume{{VolumeType: "share-file", VolumeCapacity: 30}})); !bcode.ErrTenantLackOfStorage.Equal(err) {
+		t.Fatalf("expected lack of storage, got %v", err)
+	}
+}
+
+func TestTenantQuotaResourceList(t *testing.T) {
+	quota := &api_model.TenantQuota{
+		CPU:              4000,
+		Memory:           8192,
+		EphemeralStorage: 20,
+		Storage:          100,
+		Pods:             50,
+		GPU:              2,
+		StorageClasses:   []api_model.StorageClassQuota{{StorageClass: "rainbondvolumerwx", Storage: 50}},
+	}
+	list := quotaToResourceList(quota)
+	if len(list) != 7 {
+		t.Fatalf("expected 7 resources, got %v", list)
+	}
+	if got := quotaFromResourceList(list); !reflect.DeepEqual(got, quota) {
+		t.Errorf("expected %+v, got %+v", quota, got)
+	}
+	if len(quotaToResourceList(&api_model.TenantQuota{})) != 0 || quotaDefaultLimits(&api_model.TenantQuota{Pods: 1}) != nil {
+		t.Error("unlimited quota should not generate any resource")
+	}
+}
@@ -37,6 +37,15 @@ func CheckTenantResource(ctx context.Context, tenant *dbmodel.Tenants, needMemor
 	return nil
 }
 
+// CheckServiceResource checks the quota of the tenant and the memory of the cluster before
+// running the component with the given replicas and resources.
+func CheckServiceResource(ctx context.Context, tenant *dbmodel.Tenants, service *dbmodel.TenantServices, replicas, cpu, memory, gpu int) error {
+	if err := GetTenantQuotaHandler().CheckServiceQuota(ctx, tenant, service, replicas, cpu, memory, gpu); err != nil {
+		return err
+	}
+	return CheckTenantResource(ctx, tenant, replicas*memory)
+}
+
 // ClusterAllocMemory returns the allocatable memory of the cluster.
 func ClusterAllocMemory(ctx context.Context) (int64, error) {
 	if logrus.IsLevelEnabled(logrus.DebugLevel) {
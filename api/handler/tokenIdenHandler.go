@@ -37,6 +37,10 @@ type TokenMapHandler interface {
 	AddAPIManager(am *api_model.APIManager) *util.APIHandleError
 	DeleteAPIManager(am *api_model.APIManager) *util.APIHandleError
 	InitTokenMap() error
+	CreateAPIToken(req *api_model.CreateAPITokenStruct) (*api_model.APITokenInfo, *util.APIHandleError)
+	ListAPITokens(tenantName string) ([]*dbmodel.APIToken, *util.APIHandleError)
+	RevokeAPIToken(tokenID string) *util.APIHandleError
+	CheckAPIToken(token string) *dbmodel.APIToken
 }
 
 var defaultTokenIdenHandler TokenMapHandler
@@ -96,17 +100,15 @@ func CreateDefaultTokenMap(conf option.Config) {
 	if defaultTokenMap != nil {
 		return
 	}
-	consoleToken := "defaulttokentoken"
-	if os.Getenv("TOKEN") != "" {
-		consoleToken = os.Getenv("TOKEN")
-	}
-	rui := &dbmodel.RegionUserInfo{
-		Token:          consoleToken,
-		APIRange:       dbmodel.ALLPOWER,
-		ValidityPeriod: 3257894000,
-	}
 	tokenMap := make(map[string]*dbmodel.RegionUserInfo)
-	tokenMap[consoleToken] = rui
+	// without TOKEN, only the issued api tokens are accepted
+	if consoleToken := os.Getenv("TOKEN"); consoleToken != "" {
+		tokenMap[consoleToken] = &dbmodel.RegionUserInfo{
+			Token:          consoleToken,
+			APIRange:       dbmodel.ALLPOWER,
+			ValidityPeriod: 3257894000,
+		}
+	}
 	defaultTokenMap = tokenMap
 	return
 }
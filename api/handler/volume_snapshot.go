@@ -0,0 +1,238 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"time"
+
+	api_model "github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	gclient "github.com/goodrain/rainbond/mq/client"
+	core_util "github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/worker/discover/model"
+	"github.com/jinzhu/gorm"
+)
+
+// ListVolumeSnapshots lists the snapshots of the component, optionally of one volume
+func (s *ServiceAction) ListVolumeSnapshots(serviceID, volumeName string) ([]*dbmodel.TenantServiceVolumeSnapshot, error) {
+	return db.GetManager().TenantServiceVolumeSnapshotDao().ListByServiceID(serviceID, volumeName)
+}
+
+// CreateVolumeSnapshot records the snapshot and sends the task taking it to the worker
+func (s *ServiceAction) CreateVolumeSnapshot(serviceID, eventID string, req *api_model.CreateVolumeSnapshotReq) (*dbmodel.TenantServiceVolumeSnapshot, error) {
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSnapshotVolume(serviceID, req.VolumeName); err != nil {
+		return nil, err
+	}
+	snapshot := &dbmodel.TenantServiceVolumeSnapshot{
+		SnapshotID: core_util.NewUUID(),
+		TenantID:   service.TenantID,
+		ServiceID:  serviceID,
+		VolumeName: req.VolumeName,
+		Status:     dbmodel.VolumeSnapshotStatusCreating,
+	}
+	if err := db.GetManager().TenantServiceVolumeSnapshotDao().AddModel(snapshot); err != nil {
+		return nil, err
+	}
+	if err := s.sendVolumeSnapshotTask("create_volume_snapshot", &model.VolumeSnapshotTaskBody{
+		TenantID:   service.TenantID,
+		ServiceID:  serviceID,
+		SnapshotID: snapshot.SnapshotID,
+		EventID:    eventID,
+	}); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// RestoreVolumeSnapshot restores the snapshot into the volume of a closed component of the same team
+func (s *ServiceAction) RestoreVolumeSnapshot(serviceID, snapshotID, eventID string, req *api_model.RestoreVolumeSnapshotReq) error {
+	snapshot, err := s.getVolumeSnapshot(serviceID, snapshotID)
+	if err != nil {
+		return err
+	}
+	if snapshot.Status != dbmodel.VolumeSnapshotStatusReady {
+		return bcode.ErrVolumeSnapshotNotReady
+	}
+	targetServiceID, targetVolumeName := req.TargetServiceID, req.TargetVolumeName
+	if targetServiceID == "" {
+		targetServiceID = serviceID
+	}
+	if targetVolumeName == "" {
+		targetVolumeName = snapshot.VolumeName
+	}
+	target, err := db.GetManager().TenantServiceDao().GetServiceByID(targetServiceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return bcode.ErrRestoreTargetNotFound
+		}
+		return err
+	}
+	if target.TenantID != snapshot.TenantID {
+		return bcode.ErrRestoreTargetNotFound
+	}
+	if err := checkSnapshotVolume(targetServiceID, targetVolumeName); err != nil {
+		return err
+	}
+	if err := s.isServiceClosed(targetServiceID); err != nil {
+		if err == ErrServiceNotClosed {
+			return bcode.ErrRestoreTargetNotClosed
+		}
+		return err
+	}
+	return s.sendVolumeSnapshotTask("restore_volume_snapshot", &model.VolumeSnapshotTaskBody{
+		TenantID:         snapshot.TenantID,
+		ServiceID:        serviceID,
+		SnapshotID:       snapshotID,
+		TargetServiceID:  targetServiceID,
+		TargetVolumeName: targetVolumeName,
+		EventID:          eventID,
+	})
+}
+
+// DeleteVolumeSnapshot sends the task deleting the snapshot to the worker
+func (s *ServiceAction) DeleteVolumeSnapshot(serviceID, snapshotID, eventID string) error {
+	snapshot, err := s.getVolumeSnapshot(serviceID, snapshotID)
+	if err != nil {
+		return err
+	}
+	snapshot.Status = dbmodel.VolumeSnapshotStatusDeleting
+	if err := db.GetManager().TenantServiceVolumeSnapshotDao().UpdateModel(snapshot); err != nil {
+		return err
+	}
+	return s.sendVolumeSnapshotTask("delete_volume_snapshot", &model.VolumeSnapshotTaskBody{
+		TenantID:   snapshot.TenantID,
+		ServiceID:  serviceID,
+		SnapshotID: snapshotID,
+		EventID:    eventID,
+	})
+}
+
+func (s *ServiceAction) getVolumeSnapshot(serviceID, snapshotID string) (*dbmodel.TenantServiceVolumeSnapshot, error) {
+	snapshot, err := db.GetManager().TenantServiceVolumeSnapshotDao().GetBySnapshotID(snapshotID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrVolumeSnapshotNotFound
+		}
+		return nil, err
+	}
+	if snapshot.ServiceID != serviceID {
+		return nil, bcode.ErrVolumeSnapshotNotFound
+	}
+	return snapshot, nil
+}
+
+func (s *ServiceAction) sendVolumeSnapshotTask(taskType string, body *model.VolumeSnapshotTaskBody) error {
+	return s.MQClient.SendBuilderTopic(gclient.TaskStruct{
+		TaskType: taskType,
+		TaskBody: body,
+		Topic:    gclient.WorkerTopic,
+	})
+}
+
+func checkSnapshotVolume(serviceID, volumeName string) error {
+	if _, err := db.GetManager().TenantServiceVolumeDao().GetVolumeByServiceIDAndName(serviceID, volumeName); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return bcode.ErrSnapshotVolumeNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListSnapshotPolicies -
+func (s *ServiceAction) ListSnapshotPolicies(serviceID string) ([]*dbmodel.TenantServiceSnapshotPolicy, error) {
+	return db.GetManager().TenantServiceSnapshotPolicyDao().ListByServiceID(serviceID)
+}
+
+// AddSnapshotPolicy -
+func (s *ServiceAction) AddSnapshotPolicy(serviceID string, req *api_model.SnapshotPolicyReq) (*dbmodel.TenantServiceSnapshotPolicy, error) {
+	service, err := db.GetManager().TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if req.VolumeName != "" {
+		if err := checkSnapshotVolume(serviceID, req.VolumeName); err != nil {
+			return nil, err
+		}
+	}
+	policy := req.DbModel(service.TenantID, serviceID)
+	policy.PolicyID = core_util.NewUUID()
+	// the policy runs from now on
+	now := time.Now()
+	policy.LastScheduleTime = &now
+	if err := db.GetManager().TenantServiceSnapshotPolicyDao().AddModel(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpdSnapshotPolicy -
+func (s *ServiceAction) UpdSnapshotPolicy(serviceID, policyID string, req *api_model.SnapshotPolicyReq) (*dbmodel.TenantServiceSnapshotPolicy, error) {
+	policy, err := s.getSnapshotPolicy(serviceID, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if req.VolumeName != "" && req.VolumeName != policy.VolumeName {
+		if err := checkSnapshotVolume(serviceID, req.VolumeName); err != nil {
+			return nil, err
+		}
+	}
+	if policy.Schedule != req.Schedule || policy.Timezone != req.Timezone {
+		// evaluate the new schedule from now on
+		now := time.Now()
+		policy.LastScheduleTime = &now
+	}
+	policy.VolumeName = req.VolumeName
+	policy.Schedule = req.Schedule
+	policy.Timezone = req.Timezone
+	policy.Retention = req.Retention
+	policy.Enable = req.Enable
+	if err := db.GetManager().TenantServiceSnapshotPolicyDao().UpdateModel(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DeleteSnapshotPolicy deletes the policy, the snapshots taken by it are kept
+func (s *ServiceAction) DeleteSnapshotPolicy(serviceID, policyID string) error {
+	if _, err := s.getSnapshotPolicy(serviceID, policyID); err != nil {
+		return err
+	}
+	return db.GetManager().TenantServiceSnapshotPolicyDao().DeleteByPolicyID(policyID)
+}
+
+func (s *ServiceAction) getSnapshotPolicy(serviceID, policyID string) (*dbmodel.TenantServiceSnapshotPolicy, error) {
+	policy, err := db.GetManager().TenantServiceSnapshotPolicyDao().GetByPolicyID(policyID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, bcode.ErrSnapshotPolicyNotFound
+		}
+		return nil, err
+	}
+	if policy.ServiceID != serviceID {
+		return nil, bcode.ErrSnapshotPolicyNotFound
+	}
+	return policy, nil
+}
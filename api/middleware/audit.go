@@ -0,0 +1,205 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/goodrain/rainbond/api/handler"
+	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/sirupsen/logrus"
+)
+
+// auditOperatorBodyLimit only the request bodies smaller than it are parsed for the operator
+const auditOperatorBodyLimit = 64 * 1024
+
+// auditActor is filled by the authentication after the audit middleware
+type auditActor struct {
+	name      string
+	actorType string
+}
+
+// setAuditActor sets the authenticated actor of the request
+func setAuditActor(r *http.Request, name, actorType string) {
+	if actor, ok := r.Context().Value(ctxutil.ContextKey("audit_actor")).(*auditActor); ok {
+		actor.name, actor.actorType = name, actorType
+	}
+}
+
+// auditBody hashes the request body while the handler reads it,
+// and keeps the beginning of it to find the operator given by the console.
+type auditBody struct {
+	io.ReadCloser
+	hash hash.Hash
+	head bytes.Buffer
+	size int
+}
+
+func (b *auditBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.hash.Write(p[:n])
+		if b.head.Len() < auditOperatorBodyLimit {
+			b.head.Write(p[:n])
+		}
+		b.size += n
+	}
+	return n, err
+}
+
+// digest drains the rest of the body which is not read by the handler and returns the digest of the whole body
+func (b *auditBody) digest() string {
+	io.Copy(ioutil.Discard, b)
+	if b.size == 0 {
+		return ""
+	}
+	return hex.EncodeToString(b.hash.Sum(nil))
+}
+
+// operator returns the operator field of the json body
+func (b *auditBody) operator() string {
+	if b.size > auditOperatorBodyLimit {
+		return ""
+	}
+	var body struct {
+		Operator string `json:"operator"`
+	}
+	if err := json.Unmarshal(b.head.Bytes(), &body); err != nil {
+		return ""
+	}
+	return body.Operator
+}
+
+// Audit records every mutating request in the audit log, including the requests rejected by the authentication
+func Audit(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if isReadOnlyRequest(r.Method) || strings.HasPrefix(r.RequestURI, "/docs") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		auditLog := &dbmodel.AuditLog{
+			Time:     time.Now(),
+			SourceIP: sourceIP(r.RemoteAddr),
+			Method:   r.Method,
+			Path:     r.URL.Path,
+		}
+		var body *auditBody
+		if r.Body != nil {
+			body = &auditBody{ReadCloser: r.Body, hash: sha256.New()}
+			r.Body = body
+		}
+		actor := &auditActor{actorType: dbmodel.AuditActorAnonymous}
+		ctx := context.WithValue(r.Context(), ctxutil.ContextKey("audit_actor"), actor)
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		auditLog.Actor, auditLog.ActorType = actor.name, actor.actorType
+		if body != nil {
+			auditLog.RequestDigest = body.digest()
+			auditLog.ClaimedOperator = body.operator()
+		}
+		switch auditLog.ActorType {
+		case dbmodel.AuditActorToken:
+			// the console passes the user as the operator with the legacy token
+			if auditLog.Actor == "" {
+				auditLog.Actor = auditLog.ClaimedOperator
+			}
+		case dbmodel.AuditActorAnonymous:
+			// the operator of an unauthenticated request is only a claim
+			auditLog.Actor = dbmodel.AuditActorAnonymous
+		}
+		if tctx := matchRoute(r); tctx != nil {
+			auditLog.Route = tctx.RoutePattern()
+			auditLog.TenantName = tctx.URLParam("tenant_name")
+			auditLog.Target = auditTarget(tctx)
+		}
+		auditLog.StatusCode = ww.Status()
+		if auditLog.StatusCode == 0 {
+			auditLog.StatusCode = http.StatusOK
+		}
+		switch {
+		case auditLog.StatusCode == http.StatusUnauthorized || auditLog.StatusCode == http.StatusForbidden:
+			auditLog.Outcome = dbmodel.AuditOutcomeDenied
+		case auditLog.StatusCode < 400:
+			auditLog.Outcome = dbmodel.AuditOutcomeSuccess
+		default:
+			auditLog.Outcome = dbmodel.AuditOutcomeFailure
+		}
+		if err := handler.GetAuditHandler().Record(auditLog); err != nil {
+			logrus.Errorf("record audit log of %s %s: %v", r.Method, r.URL.Path, err)
+		}
+	}
+	return http.HandlerFunc(fn)
+}
+
+// matchRoute matches the request against the routes, it works even if the request is not routed yet
+func matchRoute(r *http.Request) *chi.Context {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return nil
+	}
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	tctx := chi.NewRouteContext()
+	if !rctx.Routes.Match(tctx, r.Method, path) {
+		return nil
+	}
+	return tctx
+}
+
+// auditTarget returns the parameters of the route except the tenant, such as service_alias=gr123456
+func auditTarget(tctx *chi.Context) string {
+	var params []string
+	for i, key := range tctx.URLParams.Keys {
+		if key == "tenant_name" || key == "*" || tctx.URLParams.Values[i] == "" {
+			continue
+		}
+		params = append(params, key+"="+tctx.URLParams.Values[i])
+	}
+	sort.Strings(params)
+	return strings.Join(params, ",")
+}
+
+func sourceIP(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+func isReadOnlyRequest(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
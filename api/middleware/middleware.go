@@ -290,6 +290,8 @@ func WrapEL(f http.HandlerFunc, target, optType string, synType int) http.Handle
 				}
 			}
 
+			operator = util.GetOperator(r.Context(), operator)
+
 			// tenantID can not null
 			tenantID := r.Context().Value(ctxutil.ContextKey("tenant_id")).(string)
 			var ctx context.Context
@@ -19,62 +19,53 @@
 package middleware
 
 import (
+	"context"
 	"encoding/base64"
-	"io"
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
 
 	"github.com/goodrain/rainbond/api/handler"
 	"github.com/goodrain/rainbond/api/util"
+	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	httputil "github.com/goodrain/rainbond/util/http"
+	"github.com/goodrain/rainbond/util/oidc"
+	"github.com/sirupsen/logrus"
 )
 
+var docsUser, docsPassword string
+
+var oidcVerifier *oidc.Verifier
+
+// SetOIDCVerifier enables authenticating the users with the oidc id tokens
+func SetOIDCVerifier(verifier *oidc.Verifier) {
+	oidcVerifier = verifier
+}
+
+// SetDocsBasicAuth set the basic auth pair of the api docs, the docs accept any valid token as password if not set
+func SetDocsBasicAuth(auth string) {
+	if userPwd := strings.SplitN(auth, ":", 2); len(userPwd) == 2 {
+		docsUser, docsPassword = userPwd[0], userPwd[1]
+	}
+}
+
 //Token 简单token验证
 func Token(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
+		token := os.Getenv("TOKEN")
 		if strings.HasPrefix(r.RequestURI, "/docs") {
-			auth := r.Header.Get("Authorization")
-			if auth == "" {
-				w.Header().Set("WWW-Authenticate", `Basic realm="Dotcoo User Login"`)
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
-			auths := strings.SplitN(auth, " ", 2)
-			if len(auths) != 2 {
-				return
-			}
-			authMethod := auths[0]
-			authB64 := auths[1]
-			switch authMethod {
-			case "Basic":
-				authstr, err := base64.StdEncoding.DecodeString(authB64)
-				if err != nil {
-					io.WriteString(w, "Unauthorized!\n")
-					return
-				}
-				userPwd := strings.SplitN(string(authstr), ":", 2)
-				if len(userPwd) != 2 {
-					io.WriteString(w, "Unauthorized!\n")
-					return
-				}
-				username := userPwd[0]
-				password := userPwd[1]
-				if username == "goodrain" && password == "goodrain-api-test" {
-					next.ServeHTTP(w, r)
-					return
-				}
-			default:
-				io.WriteString(w, "Unauthorized!\n")
-				return
-			}
-			w.Header().Set("WWW-Authenticate", `Basic realm="Dotcoo User Login"`)
-			w.WriteHeader(http.StatusUnauthorized)
+			docsAuth(w, r, next, func(password string) bool {
+				return token != "" && password == token
+			})
 			return
 		}
-		token := os.Getenv("TOKEN")
 		t := r.Header.Get("Authorization")
 		if tt := strings.Split(t, " "); len(tt) == 2 {
 			if tt[1] == token {
+				setAuditActor(r, "", dbmodel.AuditActorToken)
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -89,48 +80,57 @@ func Token(next http.Handler) http.Handler {
 func FullToken(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasPrefix(r.RequestURI, "/docs") {
-			auth := r.Header.Get("Authorization")
-			if auth == "" {
-				w.Header().Set("WWW-Authenticate", `Basic realm="Dotcoo User Login"`)
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
-			auths := strings.SplitN(auth, " ", 2)
-			if len(auths) != 2 {
+			docsAuth(w, r, next, func(password string) bool {
+				if handler.GetTokenIdenHandler().CheckAPIToken(password) != nil {
+					return true
+				}
+				if oidcVerifier != nil && oidc.LooksLikeJWT(password) {
+					_, err := oidcVerifier.Verify(password)
+					return err == nil
+				}
+				return handler.GetTokenIdenHandler().CheckToken(password, r.RequestURI)
+			})
+			return
+		}
+		t := r.Header.Get("Authorization")
+		if tt := strings.Split(t, " "); len(tt) == 2 {
+			if strings.HasPrefix(tt[1], handler.APITokenPrefix) {
+				apiToken := handler.GetTokenIdenHandler().CheckAPIToken(tt[1])
+				if apiToken == nil {
+					util.CloseRequest(r)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				setAuditActor(r, apiToken.TokenID, dbmodel.AuditActorAPIToken)
+				if err := checkAPITokenPermission(apiToken, r); err != nil {
+					logrus.Debugf("api token %s denied: %v", apiToken.TokenID, err)
+					httputil.ReturnError(r, w, 403, err.Error())
+					return
+				}
+				ctx := context.WithValue(r.Context(), ctxutil.ContextKey("api_token"), apiToken)
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
-			authMethod := auths[0]
-			authB64 := auths[1]
-			switch authMethod {
-			case "Basic":
-				authstr, err := base64.StdEncoding.DecodeString(authB64)
+			if oidcVerifier != nil && oidc.LooksLikeJWT(tt[1]) {
+				identity, err := oidcVerifier.Verify(tt[1])
 				if err != nil {
-					io.WriteString(w, "Unauthorized!\n")
+					logrus.Debugf("verify oidc token: %v", err)
+					util.CloseRequest(r)
+					w.WriteHeader(http.StatusUnauthorized)
 					return
 				}
-				userPwd := strings.SplitN(string(authstr), ":", 2)
-				if len(userPwd) != 2 {
-					io.WriteString(w, "Unauthorized!\n")
-					return
-				}
-				username := userPwd[0]
-				password := userPwd[1]
-				if username == "goodrain" && password == "goodrain-api-test" {
-					next.ServeHTTP(w, r)
+				setAuditActor(r, identity.Username, dbmodel.AuditActorOIDC)
+				if err := checkIdentityPermission(identity, r); err != nil {
+					logrus.Debugf("user %s denied: %v", identity.Username, err)
+					httputil.ReturnError(r, w, 403, err.Error())
 					return
 				}
-			default:
-				io.WriteString(w, "Unauthorized!\n")
+				ctx := context.WithValue(r.Context(), ctxutil.ContextKey("user"), identity)
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
-			w.Header().Set("WWW-Authenticate", `Basic realm="Dotcoo User Login"`)
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-		//logrus.Debugf("request uri is %s", r.RequestURI)
-		t := r.Header.Get("Authorization")
-		if tt := strings.Split(t, " "); len(tt) == 2 {
 			if handler.GetTokenIdenHandler().CheckToken(tt[1], r.RequestURI) {
+				setAuditActor(r, "", dbmodel.AuditActorToken)
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -140,3 +140,77 @@ func FullToken(next http.Handler) http.Handler {
 	}
 	return http.HandlerFunc(fn)
 }
+
+// checkAPITokenPermission checks the api token against the route pattern and the parameters of the request.
+func checkAPITokenPermission(apiToken *dbmodel.APIToken, r *http.Request) error {
+	return handler.CheckAPITokenPermission(apiToken, resolveAPITokenRequest(r, apiToken.AppID != ""))
+}
+
+// checkIdentityPermission checks the oidc user as an api token with the strongest scope of the roles,
+// which is restricted to the tenant of the request if the user can access it.
+func checkIdentityPermission(identity *oidc.Identity, r *http.Request) error {
+	scope := handler.StrongestAPITokenScope(identity.Scopes)
+	if scope == "" {
+		return fmt.Errorf("none of the roles of the user is mapped to a scope")
+	}
+	req := resolveAPITokenRequest(r, false)
+	principal := &dbmodel.APIToken{Scope: scope}
+	if !identity.CanAccessTenant("*") {
+		if len(identity.Tenants) == 0 {
+			return fmt.Errorf("the user can not access any tenant")
+		}
+		principal.TenantName = identity.Tenants[0]
+		if identity.CanAccessTenant(req.TenantName) {
+			principal.TenantName = req.TenantName
+		}
+	}
+	return handler.CheckAPITokenPermission(principal, req)
+}
+
+// resolveAPITokenRequest matches the request against the routes in advance,
+// so that the permission can be checked against the route pattern and its parameters.
+func resolveAPITokenRequest(r *http.Request, resolveApp bool) *handler.APITokenRequest {
+	req := &handler.APITokenRequest{Method: r.Method}
+	if tctx := matchRoute(r); tctx != nil {
+		req.RoutePattern = tctx.RoutePattern()
+		req.TenantName = tctx.URLParam("tenant_name")
+		req.AppID = tctx.URLParam("app_id")
+		if serviceAlias := tctx.URLParam("service_alias"); req.AppID == "" && serviceAlias != "" && resolveApp {
+			req.AppID = getServiceAppID(req.TenantName, serviceAlias)
+		}
+	}
+	return req
+}
+
+func getServiceAppID(tenantName, serviceAlias string) string {
+	tenant, err := db.GetManager().TenantDao().GetTenantIDByName(tenantName)
+	if err != nil {
+		return ""
+	}
+	service, err := db.GetManager().TenantServiceDao().GetServiceByTenantIDAndServiceAlias(tenant.UUID, serviceAlias)
+	if err != nil {
+		return ""
+	}
+	return service.AppID
+}
+
+func docsAuth(w http.ResponseWriter, r *http.Request, next http.Handler, checkPassword func(password string) bool) {
+	auths := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(auths) == 2 && auths[0] == "Basic" {
+		if authstr, err := base64.StdEncoding.DecodeString(auths[1]); err == nil {
+			if userPwd := strings.SplitN(string(authstr), ":", 2); len(userPwd) == 2 {
+				if docsUser != "" {
+					if userPwd[0] == docsUser && userPwd[1] == docsPassword {
+						next.ServeHTTP(w, r)
+						return
+					}
+				} else if checkPassword(userPwd[1]) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+	}
+	w.Header().Set("WWW-Authenticate", `Basic realm="Rainbond API Docs"`)
+	w.WriteHeader(http.StatusUnauthorized)
+}
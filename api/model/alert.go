@@ -0,0 +1,113 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"time"
+
+	"github.com/goodrain/rainbond/util/notify"
+)
+
+// AlertRuleStruct create or update alert rule request
+type AlertRuleStruct struct {
+	// name of the rule, unique in the tenant
+	// in: body
+	// required: true
+	Name string `json:"name" validate:"name|required|max:64"`
+	// component or app
+	// in: body
+	// required: true
+	Scope     string `json:"scope" validate:"scope|required|in:component,app"`
+	AppID     string `json:"app_id"`
+	ServiceID string `json:"service_id"`
+	// cpu, memory, restart, http_5xx or custom
+	// in: body
+	// required: true
+	MetricType string `json:"metric_type" validate:"metric_type|required|in:cpu,memory,restart,http_5xx,custom"`
+	// promql expression, required by the custom metric type, its selectors are limited to the tenant namespace
+	Expr string `json:"expr"`
+	// in: body
+	// required: true
+	Operator  string  `json:"operator" validate:"operator|required|in:>,>=,<,<=,==,!="`
+	Threshold float64 `json:"threshold"`
+	// how long the condition must hold, such as 1m, 5m
+	Duration string   `json:"duration"`
+	Severity string   `json:"severity" validate:"severity|in:critical,warning,info"`
+	Summary  string   `json:"summary" validate:"summary|max:255"`
+	Channels []string `json:"channels"`
+	Enable   bool     `json:"enable"`
+}
+
+// AlertChannelStruct create or update alert channel request
+type AlertChannelStruct struct {
+	// in: body
+	// required: true
+	Name string `json:"name" validate:"name|required|max:64"`
+	// webhook, email, dingtalk or slack
+	// in: body
+	// required: true
+	Type string `json:"type" validate:"type|required|in:webhook,email,dingtalk,slack"`
+	// the omitted url, secret, password and headers keep the stored ones on update
+	Config notify.Config `json:"config"`
+	Enable bool          `json:"enable"`
+}
+
+// AlertSilenceStruct create alert silence request
+type AlertSilenceStruct struct {
+	// in: body
+	// required: true
+	RuleID string `json:"rule_id" validate:"rule_id|required"`
+	// in: body
+	// required: true
+	StartsAt time.Time `json:"starts_at"`
+	// in: body
+	// required: true
+	EndsAt    time.Time `json:"ends_at"`
+	Comment   string    `json:"comment" validate:"comment|max:255"`
+	CreatedBy string    `json:"created_by"`
+}
+
+// AcknowledgeAlertStruct acknowledge alert request
+type AcknowledgeAlertStruct struct {
+	Message string `json:"message"`
+}
+
+// AlertmanagerWebhookStruct the payload alertmanager posts to webhook receivers
+type AlertmanagerWebhookStruct struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts"`
+}
+
+// AlertmanagerAlert a single alert of the alertmanager webhook payload
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
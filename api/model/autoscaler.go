@@ -18,38 +18,35 @@
 
 package model
 
-import dbmodel "github.com/goodrain/rainbond/db/model"
+import (
+	"fmt"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/labels"
+)
 
 // AutoscalerRuleReq -
 type AutoscalerRuleReq struct {
 	RuleID      string `json:"rule_id" validate:"rule_id|required"`
 	ServiceID   string
-	Enable      bool   `json:"enable" validate:"enable|required"`
-	XPAType     string `json:"xpa_type" validate:"xpa_type|required"`
-	MinReplicas int    `json:"min_replicas" validate:"min_replicas|required"`
-	MaxReplicas int    `json:"max_replicas" validate:"min_replicas|required"`
-	Metrics     []struct {
-		MetricsType       string `json:"metric_type"`
-		MetricsName       string `json:"metric_name"`
-		MetricTargetType  string `json:"metric_target_type"`
-		MetricTargetValue int    `json:"metric_target_value"`
-	} `json:"metrics"`
+	Enable      bool         `json:"enable" validate:"enable|required"`
+	XPAType     string       `json:"xpa_type" validate:"xpa_type|required"`
+	MinReplicas int          `json:"min_replicas" validate:"min_replicas|required"`
+	MaxReplicas int          `json:"max_replicas" validate:"min_replicas|required"`
+	Metrics     []RuleMetric `json:"metrics"`
 }
 
 // AutoscalerRuleResp -
 type AutoscalerRuleResp struct {
-	RuleID      string `json:"rule_id"`
-	ServiceID   string `json:"service_id"`
-	Enable      bool   `json:"enable"`
-	XPAType     string `json:"xpa_type"`
-	MinReplicas int    `json:"min_replicas"`
-	MaxReplicas int    `json:"max_replicas"`
-	Metrics     []struct {
-		MetricsType       string `json:"metric_type"`
-		MetricsName       string `json:"metric_name"`
-		MetricTargetType  string `json:"metric_target_type"`
-		MetricTargetValue int    `json:"metric_target_value"`
-	} `json:"metrics"`
+	RuleID      string       `json:"rule_id"`
+	ServiceID   string       `json:"service_id"`
+	Enable      bool         `json:"enable"`
+	XPAType     string       `json:"xpa_type"`
+	MinReplicas int          `json:"min_replicas"`
+	MaxReplicas int          `json:"max_replicas"`
+	Metrics     []RuleMetric `json:"metrics"`
 }
 
 // AutoScalerRule -
@@ -80,15 +77,154 @@ type RuleMetric struct {
 	MetricsName       string `json:"metric_name"`
 	MetricTargetType  string `json:"metric_target_type"`
 	MetricTargetValue int    `json:"metric_target_value"`
+	// label selector of pods, object and external metrics
+	MetricSelector string `json:"metric_selector,omitempty"`
+	// the object described by object metrics, such as Service/gr3a4b5c
+	DescribedObjectKind string `json:"described_object_kind,omitempty"`
+	DescribedObjectName string `json:"described_object_name,omitempty"`
 }
 
 // DbModel return database model
 func (r RuleMetric) DbModel(ruleID string) *dbmodel.TenantServiceAutoscalerRuleMetrics {
 	return &dbmodel.TenantServiceAutoscalerRuleMetrics{
-		RuleID:            ruleID,
-		MetricsType:       r.MetricsType,
-		MetricsName:       r.MetricsName,
-		MetricTargetType:  r.MetricTargetType,
-		MetricTargetValue: r.MetricTargetValue,
+		RuleID:              ruleID,
+		MetricsType:         r.MetricsType,
+		MetricsName:         r.MetricsName,
+		MetricTargetType:    r.MetricTargetType,
+		MetricTargetValue:   r.MetricTargetValue,
+		MetricSelector:      r.MetricSelector,
+		DescribedObjectKind: r.DescribedObjectKind,
+		DescribedObjectName: r.DescribedObjectName,
+	}
+}
+
+// Validate checks the metric type and the target type of the metric
+func (r RuleMetric) Validate() error {
+	if r.MetricsName == "" {
+		return fmt.Errorf("metric name can not be empty")
+	}
+	if r.MetricSelector != "" {
+		if _, err := labels.Parse(r.MetricSelector); err != nil {
+			return fmt.Errorf("invalid metric selector %s: %v", r.MetricSelector, err)
+		}
+	}
+	switch r.MetricsType {
+	case dbmodel.ResourceMetricsType:
+		if r.MetricsName != "cpu" && r.MetricsName != "memory" {
+			return fmt.Errorf("resource metric %s is not supported", r.MetricsName)
+		}
+		if r.MetricTargetType != dbmodel.UtilizationMetricTarget && r.MetricTargetType != dbmodel.AverageValueMetricTarget {
+			return fmt.Errorf("resource metrics only support utilization and average_value targets")
+		}
+	case dbmodel.PodsMetricsType:
+		if r.MetricTargetType != dbmodel.AverageValueMetricTarget {
+			return fmt.Errorf("pods metrics only support average_value target")
+		}
+	case dbmodel.ObjectMetricsType:
+		if r.DescribedObjectKind == "" || r.DescribedObjectName == "" {
+			return fmt.Errorf("object metrics require described_object_kind and described_object_name")
+		}
+		fallthrough
+	case dbmodel.ExternalMetricsType:
+		if r.MetricTargetType != dbmodel.ValueMetricTarget && r.MetricTargetType != dbmodel.AverageValueMetricTarget {
+			return fmt.Errorf("%s only support value and average_value targets", r.MetricsType)
+		}
+	default:
+		return fmt.Errorf("metric type %s is not supported", r.MetricsType)
+	}
+	return nil
+}
+
+// ScalingScheduleReq -
+type ScalingScheduleReq struct {
+	ScheduleID string `json:"schedule_id"`
+	Name       string `json:"name" validate:"name|required"`
+	// Schedule standard 5 fields cron expression, such as '0 20 * * 1-5'
+	Schedule string `json:"schedule" validate:"schedule|required"`
+	// Timezone IANA time zone name, such as Asia/Shanghai, defaults to UTC
+	Timezone string `json:"timezone"`
+	Replicas int    `json:"replicas"`
+	Enable   bool   `json:"enable"`
+}
+
+// Validate checks the cron expression, the timezone and the replicas
+func (s ScalingScheduleReq) Validate() error {
+	if _, err := cron.ParseStandard(s.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule %s: %v", s.Schedule, err)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %s: %v", s.Timezone, err)
+	}
+	if s.Replicas < 0 {
+		return fmt.Errorf("replicas can not be negative")
+	}
+	return nil
+}
+
+// DbModel return database model
+func (s ScalingScheduleReq) DbModel(componentID string) *dbmodel.TenantServiceScalingSchedule {
+	return &dbmodel.TenantServiceScalingSchedule{
+		ScheduleID: s.ScheduleID,
+		ServiceID:  componentID,
+		Name:       s.Name,
+		Schedule:   s.Schedule,
+		Timezone:   s.Timezone,
+		Replicas:   s.Replicas,
+		Enable:     s.Enable,
+	}
+}
+
+// MinScaleToZeroIdleSeconds is the minimum idle time before a component is scaled to zero
+const MinScaleToZeroIdleSeconds = 60
+
+// ScaleToZeroReq -
+type ScaleToZeroReq struct {
+	Enable bool `json:"enable"`
+	// IdleSeconds the component is scaled to zero after receiving no requests for IdleSeconds
+	IdleSeconds int `json:"idle_seconds"`
+}
+
+// Validate checks the idle seconds
+func (s ScaleToZeroReq) Validate() error {
+	if s.Enable && s.IdleSeconds < MinScaleToZeroIdleSeconds {
+		return fmt.Errorf("idle_seconds can not be less than %d", MinScaleToZeroIdleSeconds)
 	}
+	return nil
+}
+
+// RecommendationPolicyReq -
+type RecommendationPolicyReq struct {
+	AutoApply bool `json:"auto_apply"`
+	// ApplyWindowStart and ApplyWindowEnd the off-peak window in HH:MM
+	ApplyWindowStart string `json:"apply_window_start"`
+	ApplyWindowEnd   string `json:"apply_window_end"`
+	Timezone         string `json:"timezone"`
+}
+
+// Validate checks the apply window and the timezone
+func (r RecommendationPolicyReq) Validate() error {
+	if !r.AutoApply {
+		return nil
+	}
+	for _, t := range []string{r.ApplyWindowStart, r.ApplyWindowEnd} {
+		if _, err := time.Parse("15:04", t); err != nil {
+			return fmt.Errorf("invalid apply window %q, expect HH:MM", t)
+		}
+	}
+	if r.ApplyWindowStart == r.ApplyWindowEnd {
+		return fmt.Errorf("the apply window can not be empty")
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %v", err)
+	}
+	return nil
+}
+
+// ResourceRecommendation the resources recommended for the component and the resources it uses now
+type ResourceRecommendation struct {
+	*dbmodel.TenantServiceResourceRecommendation
+	ServiceAlias    string                                     `json:"service_alias"`
+	ContainerCPU    int                                        `json:"container_cpu"`
+	ContainerMemory int                                        `json:"container_memory"`
+	Policy          *dbmodel.TenantServiceRecommendationPolicy `json:"policy,omitempty"`
 }
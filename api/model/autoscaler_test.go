@@ -0,0 +1,92 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package model
+
+import "testing"
+
+func TestRuleMetricValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		metric  RuleMetric
+		wantErr bool
+	}{
+		{name: "resource", metric: RuleMetric{MetricsType: "resource_metrics", MetricsName: "cpu", MetricTargetType: "utilization"}},
+		{name: "resource not supported", metric: RuleMetric{MetricsType: "resource_metrics", MetricsName: "disk", MetricTargetType: "utilization"}, wantErr: true},
+		{name: "pods", metric: RuleMetric{MetricsType: "pods_metrics", MetricsName: "qps", MetricTargetType: "average_value"}},
+		{name: "pods with value target", metric: RuleMetric{MetricsType: "pods_metrics", MetricsName: "qps", MetricTargetType: "value"}, wantErr: true},
+		{name: "object", metric: RuleMetric{MetricsType: "object_metrics", MetricsName: "qps", MetricTargetType: "value", DescribedObjectKind: "Service", DescribedObjectName: "web"}},
+		{name: "object without object", metric: RuleMetric{MetricsType: "object_metrics", MetricsName: "qps", MetricTargetType: "value"}, wantErr: true},
+		{name: "external", metric: RuleMetric{MetricsType: "external_metrics", MetricsName: "queue_length", MetricTargetType: "average_value", MetricSelector: "queue=orders"}},
+		{name: "invalid selector", metric: RuleMetric{MetricsType: "external_metrics", MetricsName: "queue_length", MetricTargetType: "value", MetricSelector: "queue in orders"}, wantErr: true},
+		{name: "unknown type", metric: RuleMetric{MetricsType: "foo", MetricsName: "bar"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.metric.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScalingScheduleReqValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ScalingScheduleReq
+		wantErr bool
+	}{
+		{name: "weekday evening", req: ScalingScheduleReq{Schedule: "0 20 * * 1-5", Timezone: "Asia/Shanghai"}},
+		{name: "default timezone", req: ScalingScheduleReq{Schedule: "0 8 * * MON-FRI", Replicas: 2}},
+		{name: "invalid schedule", req: ScalingScheduleReq{Schedule: "0 25 * * *"}, wantErr: true},
+		{name: "seconds are not supported", req: ScalingScheduleReq{Schedule: "0 0 20 * * *"}, wantErr: true},
+		{name: "invalid timezone", req: ScalingScheduleReq{Schedule: "0 20 * * *", Timezone: "Mars/Base"}, wantErr: true},
+		{name: "negative replicas", req: ScalingScheduleReq{Schedule: "0 20 * * *", Replicas: -1}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRecommendationPolicyReqValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     RecommendationPolicyReq
+		wantErr bool
+	}{
+		{name: "disabled", req: RecommendationPolicyReq{}},
+		{name: "off-peak", req: RecommendationPolicyReq{AutoApply: true, ApplyWindowStart: "02:00", ApplyWindowEnd: "04:00", Timezone: "Asia/Shanghai"}},
+		{name: "span midnight", req: RecommendationPolicyReq{AutoApply: true, ApplyWindowStart: "23:00", ApplyWindowEnd: "01:00"}},
+		{name: "invalid window", req: RecommendationPolicyReq{AutoApply: true, ApplyWindowStart: "2am", ApplyWindowEnd: "04:00"}, wantErr: true},
+		{name: "empty window", req: RecommendationPolicyReq{AutoApply: true, ApplyWindowStart: "02:00", ApplyWindowEnd: "02:00"}, wantErr: true},
+		{name: "invalid timezone", req: RecommendationPolicyReq{AutoApply: true, ApplyWindowStart: "02:00", ApplyWindowEnd: "04:00", Timezone: "Mars/Base"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
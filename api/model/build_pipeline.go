@@ -0,0 +1,64 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+// PipelineRun the run of the build pipeline for a build of the component
+type PipelineRun struct {
+	// EventID the event of the build, which has the logs of the stages
+	EventID       string                         `json:"event_id"`
+	DeployVersion string                         `json:"deploy_version"`
+	Action        string                         `json:"action"`
+	Status        string                         `json:"status"`
+	GateType      string                         `json:"gate_type"`
+	Stages        []*dbmodel.PipelineStageResult `json:"stages"`
+	Operator      string                         `json:"operator"`
+	Message       string                         `json:"message"`
+	CreateTime    time.Time                      `json:"create_time"`
+	FinishTime    *time.Time                     `json:"finish_time"`
+}
+
+// NewPipelineRun -
+func NewPipelineRun(run *dbmodel.TenantServicePipelineRun) *PipelineRun {
+	return &PipelineRun{
+		EventID:       run.EventID,
+		DeployVersion: run.DeployVersion,
+		Action:        run.Action,
+		Status:        run.Status,
+		GateType:      run.GateType,
+		Stages:        run.StageResults(),
+		Operator:      run.Operator,
+		Message:       run.Message,
+		CreateTime:    run.CreatedAt,
+		FinishTime:    run.FinishTime,
+	}
+}
+
+// PromotePipelineRunReq approves or rejects the version waiting for the approval
+type PromotePipelineRunReq struct {
+	// Approve deploys the version if true, otherwise the version is rejected
+	Approve  bool   `json:"approve"`
+	Operator string `json:"operator"`
+	Message  string `json:"message"`
+}
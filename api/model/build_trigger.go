@@ -0,0 +1,142 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"fmt"
+	"path"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+// GitProviders the git servers whose push and tag webhooks are accepted
+var GitProviders = []string{"github", "gitlab", "gitea", "bitbucket"}
+
+// BuildTriggerReq -
+type BuildTriggerReq struct {
+	// Mode webhook or poll
+	Mode string `json:"mode" validate:"mode|required"`
+	// Provider github, gitlab, gitea or bitbucket, the webhooks of any provider are accepted if empty
+	Provider string `json:"provider"`
+	RepoURL  string `json:"repo_url" validate:"repo_url|required"`
+	Branch   string `json:"branch" validate:"branch|required"`
+	// TagPattern builds the pushed tags matching the pattern, such as v*
+	TagPattern string `json:"tag_pattern"`
+	// Secret the secret of the webhook, required by the webhook mode. The secret is kept if not set on update of the same repository.
+	Secret     string `json:"secret"`
+	ServerType string `json:"server_type"`
+	Lang       string `json:"lang"`
+	Runtime    string `json:"runtime"`
+	User       string `json:"user"`
+	// Password the password of the repository, it is kept if not set on update of the same repository and user
+	Password string `json:"password"`
+	// Action upgrade deploys the component once the build succeeds
+	Action string `json:"action"`
+	// PollInterval the seconds between two polls, at least 60, defaults to 300
+	PollInterval int `json:"poll_interval"`
+	// Debounce the seconds to wait for the following pushes before building, defaults to 30
+	Debounce int `json:"debounce"`
+}
+
+// Validate checks the mode, the provider, the tag pattern and the intervals
+func (b BuildTriggerReq) Validate() error {
+	switch b.Mode {
+	case dbmodel.BuildTriggerModeWebhook, dbmodel.BuildTriggerModePoll:
+	default:
+		return fmt.Errorf("invalid mode %s, must be webhook or poll", b.Mode)
+	}
+	if b.Provider != "" && !isGitProvider(b.Provider) {
+		return fmt.Errorf("invalid provider %s", b.Provider)
+	}
+	if b.TagPattern != "" {
+		if _, err := path.Match(b.TagPattern, ""); err != nil {
+			return fmt.Errorf("invalid tag pattern %s: %v", b.TagPattern, err)
+		}
+	}
+	if b.PollInterval != 0 && b.PollInterval < 60 {
+		return fmt.Errorf("poll interval must be at least 60 seconds")
+	}
+	if b.Debounce < 0 {
+		return fmt.Errorf("debounce can not be negative")
+	}
+	return nil
+}
+
+// DbModel return the build trigger, the secret and the password are taken from old if not set
+func (b BuildTriggerReq) DbModel(old *dbmodel.BuildTrigger) *dbmodel.BuildTrigger {
+	trigger := &dbmodel.BuildTrigger{
+		Mode:         b.Mode,
+		Provider:     b.Provider,
+		RepoURL:      b.RepoURL,
+		Branch:       b.Branch,
+		TagPattern:   b.TagPattern,
+		Secret:       b.Secret,
+		ServerType:   b.ServerType,
+		Lang:         b.Lang,
+		Runtime:      b.Runtime,
+		User:         b.User,
+		Password:     b.Password,
+		Action:       b.Action,
+		PollInterval: b.PollInterval,
+		Debounce:     b.Debounce,
+	}
+	if trigger.PollInterval == 0 {
+		trigger.PollInterval = 300
+	}
+	if trigger.Debounce == 0 {
+		trigger.Debounce = 30
+	}
+	// the stored credentials are only kept for the same repository, they are never sent to another host
+	if old != nil && trigger.RepoURL == old.RepoURL {
+		if trigger.Secret == "" {
+			trigger.Secret = old.Secret
+		}
+		if trigger.Password == "" && trigger.User == old.User {
+			trigger.Password = old.Password
+		}
+		if trigger.Branch == old.Branch {
+			trigger.LastCommit = old.LastCommit
+		}
+	}
+	return trigger
+}
+
+func isGitProvider(provider string) bool {
+	for _, p := range GitProviders {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildTrigger the build trigger of the component, the secret and the password are masked
+type BuildTrigger struct {
+	dbmodel.BuildTrigger
+	// WebhookPath the path of the webhook served by the websocket server of rbd-api
+	WebhookPath string `json:"webhook_path,omitempty"`
+}
+
+// GitWebhookResult the components the webhook builds
+type GitWebhookResult struct {
+	// Scheduled the components which will be built once the debounce expires
+	Scheduled []string `json:"scheduled"`
+	// Ignored the reason why the webhook builds nothing
+	Ignored string `json:"ignored,omitempty"`
+}
@@ -0,0 +1,47 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"testing"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
+func TestBuildTriggerReqDbModel(t *testing.T) {
+	old := &dbmodel.BuildTrigger{
+		RepoURL:    "https://git.example.com/team/app.git",
+		Branch:     "main",
+		User:       "ci",
+		Password:   "pass",
+		Secret:     "secret",
+		LastCommit: "abc",
+	}
+	req := BuildTriggerReq{Mode: dbmodel.BuildTriggerModeWebhook, RepoURL: old.RepoURL, Branch: "main", User: "ci"}
+	trigger := req.DbModel(old)
+	if trigger.Password != "pass" || trigger.Secret != "secret" || trigger.LastCommit != "abc" {
+		t.Errorf("the credentials of the same repository should be kept, got %+v", trigger)
+	}
+
+	req.RepoURL = "https://attacker.example.com/team/app.git"
+	trigger = req.DbModel(old)
+	if trigger.Password != "" || trigger.Secret != "" || trigger.LastCommit != "" {
+		t.Errorf("the credentials should not be kept for another repository, got %+v", trigger)
+	}
+}
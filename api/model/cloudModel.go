@@ -18,6 +18,12 @@
 
 package model
 
+import (
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+)
+
 //GetUserToken GetUserToken
 //swagger:parameters createToken
 type GetUserToken struct {
@@ -101,3 +107,44 @@ type APIManager struct {
 		Remark string `json:"remark" validate:"remark"`
 	}
 }
+
+// CreateAPITokenStruct create api token request
+type CreateAPITokenStruct struct {
+	// in: body
+	// required: true
+	Name string `json:"name" validate:"name|required|max:64"`
+	// read-only, deploy or admin
+	// in: body
+	// required: true
+	Scope string `json:"scope" validate:"scope|required|in:read-only,deploy,admin"`
+	// restrict the token to the tenant
+	TenantName string `json:"tenant_name"`
+	// restrict the token to the app of the tenant
+	AppID string `json:"app_id"`
+	// the token never expires if empty
+	ExpireTime *time.Time `json:"expire_time"`
+	CreatedBy  string     `json:"created_by" validate:"created_by|max:64"`
+}
+
+// APITokenInfo api token, the token itself is only returned when it is created
+type APITokenInfo struct {
+	*dbmodel.APIToken
+	Token string `json:"token,omitempty"`
+}
+
+// AuditLogList a page of the audit logs
+type AuditLogList struct {
+	AuditLogs []*dbmodel.AuditLog `json:"audit_logs"`
+	Total     int64               `json:"total"`
+	Page      int                 `json:"page"`
+	PageSize  int                 `json:"page_size"`
+}
+
+// AuditLogVerifyResult the result of verifying the hash chain of the audit logs
+type AuditLogVerifyResult struct {
+	Valid   bool `json:"valid"`
+	Checked int  `json:"checked"`
+	// BrokenID the first record whose hash or previous hash does not match
+	BrokenID uint   `json:"broken_id,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
@@ -120,14 +120,16 @@ func (t *TenantComponentRelation) DbModel(tenantID, componentID string) *dbmodel
 type ComponentConfigFile struct {
 	VolumeName  string `json:"volume_name"`
 	FileContent string `json:"file_content"`
+	dbmodel.ExternalSecretRef
 }
 
 // DbModel return database model
 func (c *ComponentConfigFile) DbModel(componentID string) *dbmodel.TenantServiceConfigFile {
 	return &dbmodel.TenantServiceConfigFile{
-		ServiceID:   componentID,
-		VolumeName:  c.VolumeName,
-		FileContent: c.FileContent,
+		ServiceID:         componentID,
+		VolumeName:        c.VolumeName,
+		FileContent:       c.FileContent,
+		ExternalSecretRef: c.ExternalSecretRef,
 	}
 }
 
@@ -223,19 +225,21 @@ type ComponentEnv struct {
 	AttrValue     string `validate:"attr_value" json:"attr_value"`
 	IsChange      bool   `validate:"is_change|bool" json:"is_change"`
 	Scope         string `validate:"scope|in:outer,inner,both,build" json:"scope"`
+	dbmodel.ExternalSecretRef
 }
 
 // DbModel return database model
 func (e *ComponentEnv) DbModel(tenantID, componentID string) *dbmodel.TenantServiceEnvVar {
 	return &dbmodel.TenantServiceEnvVar{
-		TenantID:      tenantID,
-		ServiceID:     componentID,
-		Name:          e.Name,
-		AttrName:      e.AttrName,
-		AttrValue:     e.AttrValue,
-		ContainerPort: e.ContainerPort,
-		IsChange:      true,
-		Scope:         e.Scope,
+		TenantID:          tenantID,
+		ServiceID:         componentID,
+		Name:              e.Name,
+		AttrName:          e.AttrName,
+		AttrValue:         e.AttrValue,
+		ContainerPort:     e.ContainerPort,
+		IsChange:          true,
+		Scope:             e.Scope,
+		ExternalSecretRef: e.ExternalSecretRef,
 	}
 }
 
@@ -1419,19 +1419,21 @@ type AddTenantServiceEnvVar struct {
 	AttrValue     string `validate:"env_value" json:"env_value"`
 	IsChange      bool   `validate:"is_change|bool" json:"is_change"`
 	Scope         string `validate:"scope|in:outer,inner,both,build" json:"scope"`
+	dbmodel.ExternalSecretRef
 }
 
 // DbModel return database model
 func (a *AddTenantServiceEnvVar) DbModel(tenantID, componentID string) *dbmodel.TenantServiceEnvVar {
 	return &dbmodel.TenantServiceEnvVar{
-		TenantID:      tenantID,
-		ServiceID:     componentID,
-		Name:          a.Name,
-		AttrName:      a.AttrName,
-		AttrValue:     a.AttrValue,
-		ContainerPort: a.ContainerPort,
-		IsChange:      true,
-		Scope:         a.Scope,
+		TenantID:          tenantID,
+		ServiceID:         componentID,
+		Name:              a.Name,
+		AttrName:          a.AttrName,
+		AttrValue:         a.AttrValue,
+		ContainerPort:     a.ContainerPort,
+		IsChange:          true,
+		Scope:             a.Scope,
+		ExternalSecretRef: a.ExternalSecretRef,
 	}
 }
 
@@ -1567,6 +1569,12 @@ type ServiceProbe struct {
 	//标志为成功的检测次数
 	SuccessThreshold int    `gorm:"column:success_threshold;size:2;default:1" json:"success_threshold" validate:"success_threshold"`
 	FailureAction    string `json:"failure_action" validate:"failure_action"`
+	//grpc健康检查请求的服务名
+	GRPCService string `json:"grpc_service" validate:"grpc_service"`
+	//grpc检测是否使用tls
+	GRPCTLS bool `json:"grpc_tls" validate:"grpc_tls"`
+	//证书在此天数内过期视为检测失败
+	TLSExpireDays int `json:"tls_expire_days" validate:"tls_expire_days|numeric_between:0,3650"`
 }
 
 // DbModel return database model
@@ -1587,6 +1595,9 @@ func (p *ServiceProbe) DbModel(componentID string) *dbmodel.TenantServiceProbe {
 		SuccessThreshold:   p.SuccessThreshold,
 		TimeoutSecond:      p.TimeoutSecond,
 		FailureAction:      p.FailureAction,
+		GRPCService:        p.GRPCService,
+		GRPCTLS:            p.GRPCTLS,
+		TLSExpireDays:      p.TLSExpireDays,
 	}
 }
 
@@ -1653,6 +1664,8 @@ type ExportAppStruct struct {
 		Version       string `json:"version"`   // TODO 考虑去掉
 		Format        string `json:"format"`    // only rainbond-app/docker-compose/slug
 		GroupMetadata string `json:"group_metadata"`
+		// Encrypt encrypts the package with the keys configured for the builder
+		Encrypt bool `json:"encrypt"`
 	}
 }
 
@@ -1903,6 +1916,7 @@ func BuildMQBodyFrom(app *ExportAppStruct) *MQBody {
 		Version:   app.Body.Version,
 		Format:    app.Body.Format,
 		SourceDir: app.SourceDir,
+		Encrypt:   app.Body.Encrypt,
 	}
 }
 
@@ -1913,6 +1927,7 @@ type MQBody struct {
 	Version   string `json:"version"`
 	Format    string `json:"format"` // only rainbond-app/docker-compose/slug
 	SourceDir string `json:"source_dir"`
+	Encrypt   bool   `json:"encrypt"`
 }
 
 // NewAppStatusFromExport -
@@ -1984,6 +1999,10 @@ type Application struct {
 	ServiceIDs      []string `json:"service_ids"`
 	AppStoreName    string   `json:"app_store_name"`
 	AppStoreURL     string   `json:"app_store_url"`
+	AppStoreType    string   `json:"app_store_type" validate:"omitempty,oneof=helm oci git"`
+	AppStoreBranch  string   `json:"app_store_branch"`
+	ChartPath       string   `json:"chart_path"`
+	ChartDigest     string   `json:"chart_digest"`
 	AppTemplateName string   `json:"app_template_name"`
 	Version         string   `json:"version"`
 	K8sApp          string   `json:"k8s_app" validate:"required"`
@@ -2018,17 +2037,20 @@ type ListServiceResponse struct {
 
 // UpdateAppRequest -
 type UpdateAppRequest struct {
-	AppName        string   `json:"app_name"`
-	GovernanceMode string   `json:"governance_mode"`
-	Overrides      []string `json:"overrides"`
-	Version        string   `json:"version"`
-	Revision       int      `json:"revision"`
-	K8sApp         string   `json:"k8s_app"`
+	AppName          string   `json:"app_name"`
+	GovernanceMode   string   `json:"governance_mode"`
+	Overrides        []string `json:"overrides"`
+	Version          string   `json:"version"`
+	Revision         int      `json:"revision"`
+	K8sApp           string   `json:"k8s_app"`
+	ChartDigest      string   `json:"chart_digest"`
+	MeshMTLSMode     string   `json:"mesh_mtls_mode"`
+	NetworkIsolation string   `json:"network_isolation"`
 }
 
 // NeedUpdateHelmApp check if necessary to update the helm app.
 func (u *UpdateAppRequest) NeedUpdateHelmApp() bool {
-	return len(u.Overrides) > 0 || u.Version != "" || u.Revision != 0
+	return len(u.Overrides) > 0 || u.Version != "" || u.Revision != 0 || u.ChartDigest != ""
 }
 
 // BindServiceRequest -
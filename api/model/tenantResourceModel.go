@@ -137,3 +137,58 @@ func (list TenantList) Paging(page, pageSize int) map[string]interface{} {
 		"total":    list.Len(),
 	}
 }
+
+// TenantQuota the quota or the usage of a tenant, the zero values of the quota mean unlimited
+type TenantQuota struct {
+	// CPU limits of the pods in millicores
+	CPU int `json:"cpu"`
+	// Memory limits of the pods in MB
+	Memory int `json:"memory"`
+	// EphemeralStorage limits of the pods in GB
+	EphemeralStorage int `json:"ephemeral_storage"`
+	// Storage requests of the persistent volume claims in GB
+	Storage        int                 `json:"storage"`
+	Pods           int                 `json:"pods"`
+	GPU            int                 `json:"gpu"`
+	StorageClasses []StorageClassQuota `json:"storage_classes"`
+}
+
+// StorageClassQuota the storage quota or usage in a storage class
+type StorageClassQuota struct {
+	StorageClass string `json:"storage_class" validate:"storage_class|required"`
+	// Storage requests of the persistent volume claims in GB
+	Storage int `json:"storage"`
+}
+
+// TenantQuotaStatus the quota of a tenant and its usage
+type TenantQuotaStatus struct {
+	Hard *TenantQuota `json:"hard"`
+	Used *TenantQuota `json:"used"`
+}
+
+// NetworkIsolation the network isolation mode of a tenant
+type NetworkIsolation struct {
+	Mode string `json:"mode" validate:"mode|required|in:DISABLE,DRYRUN,ENFORCE"`
+}
+
+// NetworkIsolationReport the connections the network isolation blocks or would block
+type NetworkIsolationReport struct {
+	TenantMode string `json:"tenant_mode"`
+	// AllowedNamespaces the namespaces of the gateway and the platform monitoring, which can always access the components
+	AllowedNamespaces []string                    `json:"allowed_namespaces"`
+	Components        []*ComponentIsolationReport `json:"components"`
+}
+
+// ComponentIsolationReport the ingress of a component under the network isolation
+type ComponentIsolationReport struct {
+	ServiceID    string `json:"service_id"`
+	ServiceAlias string `json:"service_alias"`
+	ServiceName  string `json:"service_name"`
+	// Mode the effective isolation mode of the component, the stricter one of the tenant and the app
+	Mode  string `json:"mode"`
+	Ports []int  `json:"ports"`
+	// Allowed the components depending on this one
+	Allowed []string `json:"allowed"`
+	// Blocked the components of the tenant which can not reach this one once the isolation is enforced
+	Blocked []string `json:"blocked"`
+}
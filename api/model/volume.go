@@ -18,6 +18,8 @@
 
 package model
 
+import dbmodel "github.com/goodrain/rainbond/db/model"
+
 //AddVolumeStruct AddVolumeStruct
 //swagger:parameters addVolumes
 type AddVolumeStruct struct {
@@ -46,6 +48,8 @@ type AddVolumeStruct struct {
 		// required: true
 		VolumeName  string `json:"volume_name" validate:"volume_name|required|max:50"`
 		FileContent string `json:"file_content"`
+		// ExternalSecretRef the reference to the external secret store, the content of the config file is read from it
+		dbmodel.ExternalSecretRef
 		// 存储驱动别名（StorageClass别名）
 		VolumeProviderName string `json:"volume_provider_name"`
 		IsReadOnly         bool   `json:"is_read_only"`
@@ -245,6 +249,7 @@ type UpdVolumeReq struct {
 	FileContent string `json:"file_content"`
 	VolumePath  string `json:"volume_path" validate:"volume_path|required"`
 	Mode        *int32 `json:"mode"`
+	dbmodel.ExternalSecretRef
 }
 
 // VolumeWithStatusResp volume status
@@ -0,0 +1,81 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"fmt"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/robfig/cron/v3"
+)
+
+// CreateVolumeSnapshotReq -
+type CreateVolumeSnapshotReq struct {
+	VolumeName string `json:"volume_name" validate:"volume_name|required"`
+}
+
+// RestoreVolumeSnapshotReq restores the snapshot into the volume it is taken from, or into
+// the volume of another component, such as a new component created for the restore.
+type RestoreVolumeSnapshotReq struct {
+	// TargetServiceID the component to restore into, defaults to the component of the snapshot
+	TargetServiceID string `json:"target_service_id"`
+	// TargetVolumeName the volume to restore into, defaults to the volume of the snapshot
+	TargetVolumeName string `json:"target_volume_name"`
+}
+
+// SnapshotPolicyReq -
+type SnapshotPolicyReq struct {
+	// VolumeName the volume to snapshot, empty means all volumes of the component
+	VolumeName string `json:"volume_name"`
+	// Schedule standard 5 fields cron expression, such as '0 2 * * *'
+	Schedule string `json:"schedule" validate:"schedule|required"`
+	// Timezone IANA time zone name, such as Asia/Shanghai, defaults to UTC
+	Timezone string `json:"timezone"`
+	// Retention the number of the snapshots kept for every volume
+	Retention int  `json:"retention"`
+	Enable    bool `json:"enable"`
+}
+
+// Validate checks the cron expression, the timezone and the retention
+func (s SnapshotPolicyReq) Validate() error {
+	if _, err := cron.ParseStandard(s.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule %s: %v", s.Schedule, err)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %s: %v", s.Timezone, err)
+	}
+	if s.Retention < 1 {
+		return fmt.Errorf("retention must be at least 1")
+	}
+	return nil
+}
+
+// DbModel return database model
+func (s SnapshotPolicyReq) DbModel(tenantID, componentID string) *dbmodel.TenantServiceSnapshotPolicy {
+	return &dbmodel.TenantServiceSnapshotPolicy{
+		TenantID:   tenantID,
+		ServiceID:  componentID,
+		VolumeName: s.VolumeName,
+		Schedule:   s.Schedule,
+		Timezone:   s.Timezone,
+		Retention:  s.Retention,
+		Enable:     s.Enable,
+	}
+}
@@ -0,0 +1,87 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package region
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+
+	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/api/util"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	utilhttp "github.com/goodrain/rainbond/util/http"
+)
+
+// APITokenInterface api token api
+type APITokenInterface interface {
+	List(tenantName string) ([]*dbmodel.APIToken, *util.APIHandleError)
+	Create(req *model.CreateAPITokenStruct) (*model.APITokenInfo, *util.APIHandleError)
+	Revoke(tokenID string) *util.APIHandleError
+}
+
+func (r *regionImpl) APITokens() APITokenInterface {
+	return &apiToken{prefix: "/v2/api-tokens", regionImpl: *r}
+}
+
+type apiToken struct {
+	regionImpl
+	prefix string
+}
+
+func (a *apiToken) List(tenantName string) ([]*dbmodel.APIToken, *util.APIHandleError) {
+	var tokens []*dbmodel.APIToken
+	var decode utilhttp.ResponseBody
+	decode.List = &tokens
+	code, err := a.DoRequest(a.prefix+"?tenant_name="+url.QueryEscape(tenantName), "GET", nil, &decode)
+	if err != nil {
+		return nil, handleErrAndCode(err, code)
+	}
+	if apiErr := handleAPIResult(code, decode); apiErr != nil {
+		return nil, apiErr
+	}
+	return tokens, nil
+}
+
+func (a *apiToken) Create(req *model.CreateAPITokenStruct) (*model.APITokenInfo, *util.APIHandleError) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, util.CreateAPIHandleError(400, err)
+	}
+	var token model.APITokenInfo
+	var decode utilhttp.ResponseBody
+	decode.Bean = &token
+	code, err := a.DoRequest(a.prefix, "POST", bytes.NewBuffer(body), &decode)
+	if err != nil {
+		return nil, handleErrAndCode(err, code)
+	}
+	if apiErr := handleAPIResult(code, decode); apiErr != nil {
+		return nil, apiErr
+	}
+	return &token, nil
+}
+
+func (a *apiToken) Revoke(tokenID string) *util.APIHandleError {
+	var decode utilhttp.ResponseBody
+	code, err := a.DoRequest(a.prefix+"/"+tokenID, "DELETE", nil, &decode)
+	if err != nil {
+		return handleErrAndCode(err, code)
+	}
+	return handleAPIResult(code, decode)
+}
@@ -43,6 +43,8 @@ type ServiceInterface interface {
 	Stop(eventID string) (string, *util.APIHandleError)
 	Start(eventID string) (string, *util.APIHandleError)
 	EventLog(eventID, level string) ([]*model.MessageData, *util.APIHandleError)
+	ResourceRecommendation() (*model.ResourceRecommendation, *util.APIHandleError)
+	ApplyResourceRecommendation() (string, *util.APIHandleError)
 }
 
 func (s *services) Pods() ([]*podInfo, *util.APIHandleError) {
@@ -132,3 +134,30 @@ func (s *services) GetDeployInfo() (*ServiceDeployInfo, *util.APIHandleError) {
 	code, err := s.DoRequest(s.prefix+"/deploy-info", "GET", nil, &decode)
 	return &deployInfo, handleErrAndCode(err, code)
 }
+
+//ResourceRecommendation get the resources recommended for the service
+func (s *services) ResourceRecommendation() (*model.ResourceRecommendation, *util.APIHandleError) {
+	var rec model.ResourceRecommendation
+	var decode utilhttp.ResponseBody
+	decode.Bean = &rec
+	code, err := s.DoRequest(s.prefix+"/resource-recommendation", "GET", nil, &decode)
+	if err != nil {
+		return nil, util.CreateAPIHandleError(code, err)
+	}
+	if code != 200 {
+		return nil, util.CreateAPIHandleErrorf(code, "get resource recommendation: %s", decode.Msg)
+	}
+	return &rec, nil
+}
+
+//ApplyResourceRecommendation apply the recommendation to the service, returns the event id
+func (s *services) ApplyResourceRecommendation() (string, *util.APIHandleError) {
+	var event dbmodel.ServiceEvent
+	var res utilhttp.ResponseBody
+	res.Bean = &event
+	code, err := s.DoRequest(s.prefix+"/resource-recommendation/apply", "POST", bytes.NewBuffer([]byte("{}")), &res)
+	if err != nil {
+		return "", handleErrAndCode(err, code)
+	}
+	return event.EventID, handleAPIResult(code, res)
+}
@@ -21,6 +21,7 @@ package region
 import (
 	"path"
 
+	api_model "github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/api/util"
 	dbmodel "github.com/goodrain/rainbond/db/model"
 	utilhttp "github.com/goodrain/rainbond/util/http"
@@ -40,6 +41,7 @@ type TenantInterface interface {
 	List() ([]*dbmodel.Tenants, *util.APIHandleError)
 	Delete() *util.APIHandleError
 	Services(serviceAlias string) ServiceInterface
+	ResourceRecommendations() ([]*api_model.ResourceRecommendation, *util.APIHandleError)
 	// DefineSources(ss *api_model.SourceSpec) DefineSourcesInterface
 	// DefineCloudAuth(gt *api_model.GetUserToken) DefineCloudAuthInterface
 }
@@ -91,3 +93,18 @@ func (t *tenant) Services(serviceAlias string) ServiceInterface {
 		tenant: *t,
 	}
 }
+
+//ResourceRecommendations list the resources recommended for the services of the tenant
+func (t *tenant) ResourceRecommendations() ([]*api_model.ResourceRecommendation, *util.APIHandleError) {
+	var recs []*api_model.ResourceRecommendation
+	var decode utilhttp.ResponseBody
+	decode.List = &recs
+	code, err := t.DoRequest(t.prefix+"/resource-recommendations", "GET", nil, &decode)
+	if err != nil {
+		return nil, util.CreateAPIHandleError(code, err)
+	}
+	if code != 200 {
+		return nil, util.CreateAPIHandleErrorf(code, "list resource recommendations: %s", decode.Msg)
+	}
+	return recs, nil
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/prometheus/common/version"
 
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/oidc"
 
 	"github.com/goodrain/rainbond/cmd/api/option"
 
@@ -107,10 +108,29 @@ func (m *Manager) SetMiddleware() {
 	}
 	//Gracefully absorb panics and prints the stack trace
 	r.Use(interceptors.Recoverer)
+	//record the mutating requests in the audit log
+	if c.EnableAuditLog {
+		r.Use(apimiddleware.Audit)
+	}
 	//request time out
 	r.Use(middleware.Timeout(time.Second * 5))
 	//simple authz
-	if os.Getenv("TOKEN") != "" {
+	if c.OIDCIssuerURL != "" {
+		verifier, err := oidc.NewVerifier(oidc.Config{
+			IssuerURL:     c.OIDCIssuerURL,
+			Audiences:     c.OIDCAudiences,
+			UsernameClaim: c.OIDCUsernameClaim,
+			TenantsClaim:  c.OIDCTenantsClaim,
+			RolesClaim:    c.OIDCRolesClaim,
+			RoleScopes:    c.OIDCRoleScopes,
+		})
+		if err != nil {
+			logrus.Fatalf("create oidc verifier: %v", err)
+		}
+		apimiddleware.SetOIDCVerifier(verifier)
+	}
+	if os.Getenv("TOKEN") != "" || c.EnableAPIToken || c.OIDCIssuerURL != "" {
+		apimiddleware.SetDocsBasicAuth(c.DocsBasicAuth)
 		r.Use(apimiddleware.FullToken)
 	}
 	//simple api version
@@ -175,6 +195,7 @@ func (m *Manager) Run() {
 		websocketRouter.Mount("/logs", websocket.LogRoutes())
 		websocketRouter.Mount("/app", websocket.AppRoutes())
 		websocketRouter.Mount("/package_build", websocket.PackageBuildRoutes())
+		websocketRouter.Mount("/webhooks", websocket.WebhookRoutes())
 		if m.conf.WebsocketSSL {
 			logrus.Infof("websocket listen on (HTTPs) %s", m.conf.WebsocketAddr)
 			logrus.Fatal(http.ListenAndServeTLS(m.conf.WebsocketAddr, m.conf.WebsocketCertFile, m.conf.WebsocketKeyFile, websocketRouter))
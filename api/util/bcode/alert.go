@@ -0,0 +1,21 @@
+package bcode
+
+// alert 11400~11499
+var (
+	// ErrAlertRuleNotFound -
+	ErrAlertRuleNotFound = newByMessage(404, 11400, "alert rule not found")
+	// ErrAlertRuleNameExist -
+	ErrAlertRuleNameExist = newByMessage(400, 11401, "alert rule name already exists")
+	// ErrInvalidAlertRule -
+	ErrInvalidAlertRule = newByMessage(400, 11402, "invalid alert rule")
+	// ErrAlertChannelNotFound -
+	ErrAlertChannelNotFound = newByMessage(404, 11403, "alert channel not found")
+	// ErrAlertChannelNameExist -
+	ErrAlertChannelNameExist = newByMessage(400, 11404, "alert channel name already exists")
+	// ErrInvalidAlertChannel -
+	ErrInvalidAlertChannel = newByMessage(400, 11405, "invalid alert channel")
+	// ErrAlertChannelInUse -
+	ErrAlertChannelInUse = newByMessage(400, 11406, "alert channel is referenced by alert rules")
+	// ErrAlertNotFound -
+	ErrAlertNotFound = newByMessage(404, 11407, "alert not found")
+)
@@ -24,6 +24,10 @@ var (
 	ErrInvaildK8sApp = newByMessage(400, 11010, "invalid k8s app name")
 	// ErrK8sAppExists -
 	ErrK8sAppExists = newByMessage(400, 11011, "k8s app name exists")
+	// ErrInvalidMeshMTLSMode -
+	ErrInvalidMeshMTLSMode = newByMessage(400, 11012, "invalid mesh mtls mode")
+	// ErrInvalidNetworkIsolation -
+	ErrInvalidNetworkIsolation = newByMessage(400, 11013, "invalid network isolation mode")
 )
 
 // app config group 11100~11199
@@ -14,4 +14,38 @@ var (
 	ErrHorizontalDueToNoChange = newByMessage(400, 10104, "The number of components has not changed, no need to scale")
 	ErrPodNotFound             = newByMessage(404, 10105, "pod not found")
 	ErrK8sComponentNameExists  = newByMessage(400, 10106, "k8s component name exists")
+	// ErrScalingScheduleNotFound -
+	ErrScalingScheduleNotFound = newByMessage(404, 10107, "scaling schedule not found")
+	// ErrResourceRecommendationNotFound -
+	ErrResourceRecommendationNotFound = newByMessage(404, 10108, "resource recommendation not found, the usage of the component is not enough")
+	// ErrVolumeSnapshotNotFound -
+	ErrVolumeSnapshotNotFound = newByMessage(404, 10109, "volume snapshot not found")
+	// ErrVolumeSnapshotNotReady -
+	ErrVolumeSnapshotNotReady = newByMessage(400, 10110, "the volume snapshot is not ready")
+	// ErrSnapshotPolicyNotFound -
+	ErrSnapshotPolicyNotFound = newByMessage(404, 10111, "snapshot policy not found")
+	// ErrSnapshotVolumeNotFound -
+	ErrSnapshotVolumeNotFound = newByMessage(404, 10112, "the volume of the component not found")
+	// ErrRestoreTargetNotClosed -
+	ErrRestoreTargetNotClosed = newByMessage(400, 10113, "the component must be closed before restoring its volume")
+	// ErrRestoreTargetNotFound -
+	ErrRestoreTargetNotFound = newByMessage(404, 10114, "the component to restore into not found in the team")
+	// ErrHorizontalDaemonSet -
+	ErrHorizontalDaemonSet = newByMessage(400, 10115, "the daemonset component runs one instance on each selected node, it can not be scaled horizontally")
+	// ErrBuildTriggerNotFound -
+	ErrBuildTriggerNotFound = newByMessage(404, 10116, "build trigger not found")
+	// ErrInvalidWebhookSignature -
+	ErrInvalidWebhookSignature = newByMessage(401, 10117, "the signature of the webhook does not match the secret")
+	// ErrBuildPipelineNotFound -
+	ErrBuildPipelineNotFound = newByMessage(404, 10118, "build pipeline not found")
+	// ErrPipelineRunNotFound -
+	ErrPipelineRunNotFound = newByMessage(404, 10119, "pipeline run not found")
+	// ErrPipelineRunNotWaiting -
+	ErrPipelineRunNotWaiting = newByMessage(400, 10120, "the version of the pipeline run is not waiting for the approval")
+	// ErrPipelineRunOutdated -
+	ErrPipelineRunOutdated = newByMessage(400, 10121, "a newer version of the component has been deployed")
+	// ErrVersionNotPromoted -
+	ErrVersionNotPromoted = newByMessage(400, 10122, "the version is waiting for the approval or rejected by the promotion gate")
+	// ErrGRPCProbeNotSupported -
+	ErrGRPCProbeNotSupported = newByMessage(400, 10123, "grpc probe requires kubernetes 1.24 or later")
 )
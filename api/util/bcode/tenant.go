@@ -3,4 +3,16 @@ package bcode
 // tenant 11300~11399
 var (
 	ErrNamespaceExists = newByMessage(400, 11300, "tenant namespace exists")
+	// ErrTenantLackOfCPU -
+	ErrTenantLackOfCPU = newByMessage(412, 11301, "tenant_lack_of_cpu")
+	// ErrTenantLackOfMemory -
+	ErrTenantLackOfMemory = newByMessage(412, 11302, "tenant_lack_of_memory")
+	// ErrTenantLackOfGPU -
+	ErrTenantLackOfGPU = newByMessage(412, 11303, "tenant_lack_of_gpu")
+	// ErrTenantLackOfPods -
+	ErrTenantLackOfPods = newByMessage(412, 11304, "tenant_lack_of_pods")
+	// ErrInvalidTenantQuota -
+	ErrInvalidTenantQuota = newByMessage(400, 11305, "invalid tenant quota")
+	// ErrTenantLackOfStorage -
+	ErrTenantLackOfStorage = newByMessage(412, 11306, "tenant_lack_of_storage")
 )
@@ -19,15 +19,30 @@
 package util
 
 import (
+	"context"
 	"time"
 
+	ctxutil "github.com/goodrain/rainbond/api/util/ctx"
 	"github.com/goodrain/rainbond/db"
 	dbmodel "github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/util/oidc"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 )
 
+// GetOperator returns the authenticated user of the request as the operator of the events,
+// the operator given by the request is only used if the user is unknown.
+func GetOperator(ctx context.Context, operator string) string {
+	if identity, ok := ctx.Value(ctxutil.ContextKey("user")).(*oidc.Identity); ok && identity.Username != "" {
+		return identity.Username
+	}
+	if apiToken, ok := ctx.Value(ctxutil.ContextKey("api_token")).(*dbmodel.APIToken); ok && operator == "" {
+		return "token:" + apiToken.Name
+	}
+	return operator
+}
+
 // CanDoEvent check can do event or not
 func CanDoEvent(optType string, synType int, target, targetID string, componentKind string) bool {
 	if synType == dbmodel.SYNEVENTTYPE || componentKind == "third_party" {
@@ -0,0 +1,103 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"bufio"
+	"io"
+)
+
+// The default chunk sizes, the chunks are 1MiB on average.
+const (
+	DefaultMinChunkSize = 256 << 10
+	DefaultAvgChunkBits = 20
+	DefaultMaxChunkSize = 8 << 20
+)
+
+// gearTable the random values of the bytes used by the rolling hash
+var gearTable [256]uint64
+
+func init() {
+	// splitmix64 with a fixed seed, the cut points must not change between versions
+	seed := uint64(0x5261696e626f6e64)
+	for i := range gearTable {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gearTable[i] = z ^ (z >> 31)
+	}
+}
+
+// ChunkerOptions the sizes of the chunks
+type ChunkerOptions struct {
+	MinSize int
+	// AvgBits the chunks are 2^AvgBits bytes on average
+	AvgBits int
+	MaxSize int
+}
+
+// DefaultChunkerOptions -
+func DefaultChunkerOptions() ChunkerOptions {
+	return ChunkerOptions{MinSize: DefaultMinChunkSize, AvgBits: DefaultAvgChunkBits, MaxSize: DefaultMaxChunkSize}
+}
+
+// Chunker splits a stream into content defined chunks with a gear rolling hash.
+// The cut points only depend on the content around them, so that inserting or removing
+// data in a file only changes the chunks near the change.
+type Chunker struct {
+	r    *bufio.Reader
+	opts ChunkerOptions
+	mask uint64
+	buf  []byte
+}
+
+// NewChunker creates a chunker
+func NewChunker(r io.Reader, opts ChunkerOptions) *Chunker {
+	return &Chunker{
+		r:    bufio.NewReaderSize(r, 1<<20),
+		opts: opts,
+		// use the high bits of the hash, which depend on more bytes of the window
+		mask: ((uint64(1) << uint(opts.AvgBits)) - 1) << uint(64-opts.AvgBits),
+		buf:  make([]byte, 0, opts.MaxSize),
+	}
+}
+
+// Next returns the next chunk, the chunk is only valid until the next call. io.EOF is returned at the end.
+func (c *Chunker) Next() ([]byte, error) {
+	c.buf = c.buf[:0]
+	var hash uint64
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(c.buf) > 0 {
+				return c.buf, nil
+			}
+			return nil, err
+		}
+		c.buf = append(c.buf, b)
+		if len(c.buf) < c.opts.MinSize {
+			continue
+		}
+		hash = (hash << 1) + gearTable[b]
+		if hash&c.mask == 0 || len(c.buf) >= c.opts.MaxSize {
+			return c.buf, nil
+		}
+	}
+}
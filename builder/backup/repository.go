@@ -0,0 +1,595 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/goodrain/rainbond/builder/cloudos"
+	"github.com/goodrain/rainbond/builder/envelope"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// uploadConcurrency the number of the chunks uploaded at the same time
+const uploadConcurrency = 4
+
+const (
+	// staleLockTimeout the locks older than it are left by the crashed processes and ignored
+	staleLockTimeout = 24 * time.Hour
+	// pruneLockWait the time a backup waits for the running prune to finish
+	pruneLockWait = 30 * time.Minute
+	pruneLockID   = "prune"
+)
+
+// ErrRepositoryLocked the repository is being pruned
+var ErrRepositoryLocked = fmt.Errorf("the repository is being pruned")
+
+// Repository stores the backups as content addressed chunks in the object storage.
+// The chunks are shared by all the snapshots of the repository, so only the chunks
+// changed since the previous backups are uploaded.
+//
+//	<prefix>/snapshots/<snapshot id>.json  the files of the snapshot and their chunks
+//	<prefix>/chunks/<2 hex>/<sha256>        the gzipped chunks
+//	<prefix>/locks/<backup id or prune>.json the running backups and prune
+type Repository struct {
+	store   cloudos.CloudOSer
+	prefix  string
+	chunker ChunkerOptions
+	keyring *envelope.Keyring
+	encrypt bool
+}
+
+// Snapshot the manifest of a backup
+type Snapshot struct {
+	ID    string    `json:"id"`
+	Time  time.Time `json:"time"`
+	Files []*File   `json:"files"`
+	// Size the total size of the files
+	Size int64 `json:"size"`
+}
+
+// File a file or a directory of the snapshot, the path is relative to the backup directory
+type File struct {
+	Path  string      `json:"path"`
+	Mode  os.FileMode `json:"mode"`
+	Size  int64       `json:"size"`
+	Owner *Owner      `json:"owner,omitempty"`
+	// Link the target of the symlink
+	Link   string   `json:"link,omitempty"`
+	Chunks []string `json:"chunks,omitempty"`
+}
+
+// Owner the owner of the file
+type Owner struct {
+	UID int `json:"uid"`
+	GID int `json:"gid"`
+}
+
+type lock struct {
+	Time time.Time `json:"time"`
+}
+
+// Stats the statistics of a backup
+type Stats struct {
+	Files     int
+	Chunks    int
+	NewChunks int
+	Size      int64
+	// UploadedSize the compressed size of the new chunks
+	UploadedSize int64
+}
+
+// NewRepository creates a repository under the prefix of the object storage
+func NewRepository(store cloudos.CloudOSer, prefix string) *Repository {
+	return &Repository{store: store, prefix: strings.Trim(prefix, "/"), chunker: DefaultChunkerOptions()}
+}
+
+// Prefix returns the prefix of the repository
+func (r *Repository) Prefix() string {
+	return r.prefix
+}
+
+// SetKeyring sets the keyring used to decrypt the encrypted objects of the repository.
+// If encrypt is true, the chunks and the snapshots written are encrypted with it too.
+func (r *Repository) SetKeyring(keyring *envelope.Keyring, encrypt bool) {
+	r.keyring = keyring
+	r.encrypt = encrypt
+}
+
+func (r *Repository) snapshotKey(id string) string {
+	return path.Join(r.prefix, "snapshots", id+".json")
+}
+
+func (r *Repository) chunkKey(id string) string {
+	return path.Join(r.prefix, "chunks", id[:2], id)
+}
+
+func (r *Repository) lockKey(id string) string {
+	return path.Join(r.prefix, "locks", id+".json")
+}
+
+func (r *Repository) lock(id string) error {
+	data, err := json.Marshal(&lock{Time: time.Now()})
+	if err != nil {
+		return err
+	}
+	if err := r.store.PutObjectData(r.lockKey(id), data); err != nil {
+		return fmt.Errorf("put lock %s: %v", id, err)
+	}
+	return nil
+}
+
+func (r *Repository) unlock(id string) {
+	if err := r.store.DeleteObject(r.lockKey(id)); err != nil {
+		logrus.Warningf("repository %s: delete lock %s: %v", r.prefix, id, err)
+	}
+}
+
+// activeLocks returns the ids of the locks held by the running backups or prune, the stale locks are ignored
+func (r *Repository) activeLocks() (map[string]struct{}, error) {
+	keys, err := r.store.ListObjects(path.Join(r.prefix, "locks") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("list locks: %v", err)
+	}
+	locks := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		id := strings.TrimSuffix(path.Base(key), ".json")
+		data, err := r.store.GetObjectData(key)
+		if err != nil {
+			// the lock is released after listed
+			continue
+		}
+		var l lock
+		if err := json.Unmarshal(data, &l); err != nil || time.Since(l.Time) > staleLockTimeout {
+			continue
+		}
+		locks[id] = struct{}{}
+	}
+	return locks, nil
+}
+
+// lockBackup takes the lock of the backup, and waits for the running prune, which may delete
+// the chunks the backup treats as known, to finish.
+func (r *Repository) lockBackup(id string) error {
+	if err := r.lock(id); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(pruneLockWait)
+	for {
+		locks, err := r.activeLocks()
+		if err != nil {
+			r.unlock(id)
+			return err
+		}
+		if _, ok := locks[pruneLockID]; !ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			r.unlock(id)
+			return ErrRepositoryLocked
+		}
+		logrus.Infof("repository %s: waiting for the prune to finish", r.prefix)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// listChunks returns the ids of the chunks stored in the repository
+func (r *Repository) listChunks() (map[string]struct{}, error) {
+	keys, err := r.store.ListObjects(path.Join(r.prefix, "chunks") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("list chunks: %v", err)
+	}
+	chunks := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		chunks[path.Base(key)] = struct{}{}
+	}
+	return chunks, nil
+}
+
+// Backup stores the files of the directory as the snapshot
+func (r *Repository) Backup(dir, id string) (*Stats, error) {
+	if err := r.lockBackup(id); err != nil {
+		return nil, err
+	}
+	defer r.unlock(id)
+	known, err := r.listChunks()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{ID: id, Time: time.Now()}
+	stats := &Stats{}
+	var mu sync.Mutex
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		file := &File{Path: filepath.ToSlash(rel), Mode: info.Mode()}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			file.Owner = &Owner{UID: int(st.Uid), GID: int(st.Gid)}
+		}
+		switch mode := info.Mode(); {
+		case mode.IsRegular():
+			stats.Files++
+			if err := r.backupFile(p, file, known, &mu, stats); err != nil {
+				return err
+			}
+		case mode.IsDir(), mode&os.ModeNamedPipe != 0:
+		case mode&os.ModeSymlink != 0:
+			if file.Link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		case mode&os.ModeSocket != 0:
+			// the sockets are created by the processes listening on them, there is nothing to restore
+			logrus.Warningf("repository %s: socket %s is not backed up", r.prefix, rel)
+			return nil
+		default:
+			return fmt.Errorf("back up %s: unsupported file mode %s", rel, mode)
+		}
+		snapshot.Files = append(snapshot.Files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Size = stats.Size
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = r.seal(data); err != nil {
+		return nil, err
+	}
+	if err := r.store.PutObjectData(r.snapshotKey(id), data); err != nil {
+		return nil, fmt.Errorf("put snapshot %s: %v", id, err)
+	}
+	return stats, nil
+}
+
+func (r *Repository) backupFile(p string, file *File, known map[string]struct{}, mu *sync.Mutex, stats *Stats) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var g errgroup.Group
+	sem := make(chan struct{}, uploadConcurrency)
+	// wait for the uploads started, even if the file is not read completely
+	err = r.chunkFile(f, p, file, known, mu, stats, &g, sem)
+	if werr := g.Wait(); err == nil {
+		err = werr
+	}
+	return err
+}
+
+func (r *Repository) chunkFile(f io.Reader, p string, file *File, known map[string]struct{}, mu *sync.Mutex, stats *Stats, g *errgroup.Group, sem chan struct{}) error {
+	chunker := NewChunker(f, r.chunker)
+	for {
+		chunk, err := chunker.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %v", p, err)
+		}
+		sum := sha256.Sum256(chunk)
+		id := hex.EncodeToString(sum[:])
+		file.Chunks = append(file.Chunks, id)
+		file.Size += int64(len(chunk))
+		mu.Lock()
+		stats.Chunks++
+		stats.Size += int64(len(chunk))
+		_, ok := known[id]
+		if !ok {
+			// mark the chunk as known, so that the same chunk is only uploaded once
+			known[id] = struct{}{}
+			stats.NewChunks++
+		}
+		mu.Unlock()
+		if ok {
+			continue
+		}
+		data, err := compress(chunk)
+		if err != nil {
+			return err
+		}
+		if data, err = r.seal(data); err != nil {
+			return err
+		}
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			if err := r.store.PutObjectData(r.chunkKey(id), data); err != nil {
+				return fmt.Errorf("put chunk %s: %v", id, err)
+			}
+			mu.Lock()
+			stats.UploadedSize += int64(len(data))
+			mu.Unlock()
+			return nil
+		})
+	}
+}
+
+// Snapshot returns the manifest of the snapshot
+func (r *Repository) Snapshot(id string) (*Snapshot, error) {
+	data, err := r.store.GetObjectData(r.snapshotKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("get snapshot %s: %v", id, err)
+	}
+	if data, err = r.open(data); err != nil {
+		return nil, fmt.Errorf("decrypt snapshot %s: %v", id, err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("decode snapshot %s: %v", id, err)
+	}
+	return &snapshot, nil
+}
+
+// Snapshots returns all the snapshots of the repository, ordered from the newest
+func (r *Repository) Snapshots() ([]*Snapshot, error) {
+	keys, err := r.store.ListObjects(path.Join(r.prefix, "snapshots") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %v", err)
+	}
+	var snapshots []*Snapshot
+	for _, key := range keys {
+		snapshot, err := r.Snapshot(strings.TrimSuffix(path.Base(key), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// Restore reassembles the files of the snapshot into the directory, the chunks are verified while restoring
+func (r *Repository) Restore(id, dir string) error {
+	snapshot, err := r.Snapshot(id)
+	if err != nil {
+		return err
+	}
+	for _, file := range snapshot.Files {
+		target := filepath.Join(dir, filepath.FromSlash(file.Path))
+		switch mode := file.Mode; {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, mode.Perm()|0700); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			if err := r.restoreFile(file, target); err != nil {
+				return err
+			}
+		case mode&os.ModeSymlink != 0:
+			os.Remove(target)
+			if err := os.Symlink(file.Link, target); err != nil {
+				return fmt.Errorf("restore %s: %v", file.Path, err)
+			}
+		case mode&os.ModeNamedPipe != 0:
+			os.Remove(target)
+			if err := syscall.Mkfifo(target, uint32(mode.Perm())); err != nil {
+				return fmt.Errorf("restore %s: %v", file.Path, err)
+			}
+		default:
+			return fmt.Errorf("restore %s: unsupported file mode %s", file.Path, mode)
+		}
+		if file.Owner != nil {
+			if err := os.Lchown(target, file.Owner.UID, file.Owner.GID); err != nil {
+				return fmt.Errorf("restore the owner of %s: %v", file.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Repository) restoreFile(file *File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, file.Mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for _, id := range file.Chunks {
+		chunk, err := r.readChunk(id)
+		if err != nil {
+			return fmt.Errorf("restore %s: %v", file.Path, err)
+		}
+		if _, err := f.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readChunk downloads the chunk and verifies its content matches its id
+func (r *Repository) readChunk(id string) ([]byte, error) {
+	data, err := r.store.GetObjectData(r.chunkKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("get chunk %s: %v", id, err)
+	}
+	if data, err = r.open(data); err != nil {
+		return nil, fmt.Errorf("decrypt chunk %s: %v", id, err)
+	}
+	chunk, err := decompress(data)
+	if err != nil {
+		return nil, fmt.Errorf("decompress chunk %s: %v", id, err)
+	}
+	sum := sha256.Sum256(chunk)
+	if hex.EncodeToString(sum[:]) != id {
+		return nil, fmt.Errorf("chunk %s is corrupted", id)
+	}
+	return chunk, nil
+}
+
+// Check verifies all the chunks of the snapshot exist. If readData is true, the chunks
+// are downloaded and their contents are verified as well.
+func (r *Repository) Check(id string, readData bool) error {
+	snapshot, err := r.Snapshot(id)
+	if err != nil {
+		return err
+	}
+	known, err := r.listChunks()
+	if err != nil {
+		return err
+	}
+	checked := make(map[string]struct{})
+	for _, file := range snapshot.Files {
+		for _, chunk := range file.Chunks {
+			if _, ok := checked[chunk]; ok {
+				continue
+			}
+			checked[chunk] = struct{}{}
+			if _, ok := known[chunk]; !ok {
+				return fmt.Errorf("chunk %s of %s is missing", chunk, file.Path)
+			}
+			if readData {
+				if _, err := r.readChunk(chunk); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Forget deletes the snapshots except the latest keepLast ones, and prunes the chunks no longer used.
+// The prune is skipped while other backups of the repository are running, their chunks are
+// pruned by a later Forget. It returns the ids of the deleted snapshots.
+func (r *Repository) Forget(keepLast int) ([]string, error) {
+	snapshots, err := r.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	if keepLast <= 0 || len(snapshots) <= keepLast {
+		return nil, nil
+	}
+	var forgotten []string
+	for _, snapshot := range snapshots[keepLast:] {
+		if err := r.store.DeleteObject(r.snapshotKey(snapshot.ID)); err != nil {
+			return forgotten, fmt.Errorf("delete snapshot %s: %v", snapshot.ID, err)
+		}
+		forgotten = append(forgotten, snapshot.ID)
+	}
+	return forgotten, r.prune(snapshots[:keepLast])
+}
+
+// prune deletes the chunks not used by the snapshots
+func (r *Repository) prune(snapshots []*Snapshot) error {
+	if err := r.lock(pruneLockID); err != nil {
+		return err
+	}
+	defer r.unlock(pruneLockID)
+	// the lock is taken before checking the backups, so that a backup started later waits for the prune
+	locks, err := r.activeLocks()
+	if err != nil {
+		return err
+	}
+	if len(locks) > 1 {
+		logrus.Infof("repository %s: %d backups are running, skip pruning", r.prefix, len(locks)-1)
+		return nil
+	}
+	used := make(map[string]struct{})
+	for _, snapshot := range snapshots {
+		for _, file := range snapshot.Files {
+			for _, chunk := range file.Chunks {
+				used[chunk] = struct{}{}
+			}
+		}
+	}
+	known, err := r.listChunks()
+	if err != nil {
+		return err
+	}
+	var deleted int
+	for chunk := range known {
+		if _, ok := used[chunk]; ok {
+			continue
+		}
+		if err := r.store.DeleteObject(r.chunkKey(chunk)); err != nil {
+			return fmt.Errorf("delete chunk %s: %v", chunk, err)
+		}
+		deleted++
+	}
+	logrus.Infof("repository %s: %d unused chunks pruned", r.prefix, deleted)
+	return nil
+}
+
+// seal encrypts the object if the repository is encrypted
+func (r *Repository) seal(data []byte) ([]byte, error) {
+	if !r.encrypt {
+		return data, nil
+	}
+	return r.keyring.Seal(data)
+}
+
+// open decrypts the object if it is encrypted
+func (r *Repository) open(data []byte) ([]byte, error) {
+	if !envelope.IsEncrypted(data) {
+		return data, nil
+	}
+	if r.keyring == nil {
+		return nil, envelope.ErrNoKey
+	}
+	return r.keyring.Open(data)
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,365 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package backup
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goodrain/rainbond/builder/cloudos"
+	"github.com/goodrain/rainbond/builder/envelope"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 a minimal S3 compatible server keeping the objects of one bucket in memory
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    int
+}
+
+type listBucketResult struct {
+	XMLName     xml.Name `xml:"ListBucketResult"`
+	IsTruncated bool     `xml:"IsTruncated"`
+	Contents    []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// path style: /<bucket>/<key>
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	key := ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		var result listBucketResult
+		var keys []string
+		for k := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			result.Contents = append(result.Contents, struct {
+				Key string `xml:"Key"`
+			}{Key: k})
+		}
+		data, _ := xml.Marshal(result)
+		w.Write(data)
+	case r.Method == http.MethodPut:
+		data, _ := ioutil.ReadAll(r.Body)
+		f.objects[key] = data
+		f.puts++
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchKey</Code></Error>`))
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestRepository(t *testing.T) (*Repository, *fakeS3) {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	store, err := cloudos.New(&cloudos.Config{
+		ProviderType: cloudos.S3ProviderS3,
+		Endpoint:     server.URL,
+		AccessKey:    "access",
+		SecretKey:    "secret",
+		BucketName:   "backups",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepository(store, "rainbond-backups/app")
+	// small chunks, so that the test data is split into many chunks
+	repo.chunker = ChunkerOptions{MinSize: 1 << 10, AvgBits: 12, MaxSize: 16 << 10}
+	return repo, fake
+}
+
+func writeFile(t *testing.T, p string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChunkerBoundaries(t *testing.T) {
+	data := make([]byte, 256<<10)
+	rand.New(rand.NewSource(1)).Read(data)
+	opts := ChunkerOptions{MinSize: 1 << 10, AvgBits: 12, MaxSize: 16 << 10}
+	split := func(data []byte) map[string]bool {
+		chunks := make(map[string]bool)
+		chunker := NewChunker(bytes.NewReader(data), opts)
+		for {
+			chunk, err := chunker.Next()
+			if err != nil {
+				break
+			}
+			assert.True(t, len(chunk) <= opts.MaxSize)
+			chunks[string(chunk)] = true
+		}
+		return chunks
+	}
+	origin := split(data)
+	// insert some bytes in the middle, the chunks away from the change are kept
+	changed := append(append(append([]byte{}, data[:100<<10]...), []byte("inserted")...), data[100<<10:]...)
+	var shared int
+	for chunk := range split(changed) {
+		if origin[chunk] {
+			shared++
+		}
+	}
+	assert.True(t, shared >= len(origin)-3, "%d of %d chunks are shared", shared, len(origin))
+}
+
+func TestRepositoryIncrementalBackup(t *testing.T) {
+	repo, fake := newTestRepository(t)
+	src, err := ioutil.TempDir("", "backup-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	data := make([]byte, 512<<10)
+	rand.New(rand.NewSource(2)).Read(data)
+	writeFile(t, filepath.Join(src, "region_apps_metadata.json"), []byte(`{"Services":[]}`))
+	writeFile(t, filepath.Join(src, "data_svc", "__all_data.zip"), data)
+	if err := os.MkdirAll(filepath.Join(src, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := repo.Backup(src, "backup-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, first.Files)
+	assert.Equal(t, first.Chunks, first.NewChunks)
+
+	// change a small part of the data, only the chunks around the change are uploaded
+	copy(data[300<<10:], []byte("changed"))
+	writeFile(t, filepath.Join(src, "data_svc", "__all_data.zip"), data)
+	puts := fake.puts
+	second, err := repo.Backup(src, "backup-2")
+	assert.Nil(t, err)
+	assert.True(t, second.NewChunks > 0 && second.NewChunks <= 3, "%d new chunks", second.NewChunks)
+	// the new chunks, the snapshot and the lock of the backup
+	assert.Equal(t, second.NewChunks+2, fake.puts-puts)
+	assert.Nil(t, repo.Check("backup-2", true))
+
+	dst, err := ioutil.TempDir("", "backup-dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	assert.Nil(t, repo.Restore("backup-2", dst))
+	restored, err := ioutil.ReadFile(filepath.Join(dst, "data_svc", "__all_data.zip"))
+	assert.Nil(t, err)
+	assert.Equal(t, data, restored)
+	assert.DirExists(t, filepath.Join(dst, "empty"))
+
+	// the corrupted chunk is detected
+	for key, value := range fake.objects {
+		if strings.Contains(key, "/chunks/") {
+			corrupted, _ := compress([]byte("corrupted"))
+			fake.objects[key] = corrupted
+			assert.NotNil(t, repo.Check("backup-1", true))
+			fake.objects[key] = value
+			break
+		}
+	}
+}
+
+func TestRepositoryForget(t *testing.T) {
+	repo, fake := newTestRepository(t)
+	src, err := ioutil.TempDir("", "backup-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	random := rand.New(rand.NewSource(3))
+	for _, id := range []string{"backup-1", "backup-2", "backup-3"} {
+		data := make([]byte, 64<<10)
+		random.Read(data)
+		writeFile(t, filepath.Join(src, "data"), data)
+		_, err := repo.Backup(src, id)
+		assert.Nil(t, err)
+	}
+	forgotten, err := repo.Forget(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"backup-1"}, forgotten)
+
+	snapshots, err := repo.Snapshots()
+	assert.Nil(t, err)
+	assert.Len(t, snapshots, 2)
+	assert.Equal(t, "backup-3", snapshots[0].ID)
+	// only the chunks of the kept snapshots remain
+	var chunks int
+	for key := range fake.objects {
+		if strings.Contains(key, "/chunks/") {
+			chunks++
+		}
+	}
+	var used int
+	for _, snapshot := range snapshots {
+		for _, file := range snapshot.Files {
+			used += len(file.Chunks)
+		}
+	}
+	assert.Equal(t, used, chunks)
+	assert.Nil(t, repo.Check("backup-2", true))
+}
+
+func TestRepositorySymlink(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	src, err := ioutil.TempDir("", "backup-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	writeFile(t, filepath.Join(src, "data", "current.log"), []byte("log"))
+	if err := os.Symlink("current.log", filepath.Join(src, "data", "latest.log")); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := repo.Backup(src, "backup-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, stats.Files)
+
+	dst, err := ioutil.TempDir("", "backup-dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	assert.Nil(t, repo.Restore("backup-1", dst))
+	link, err := os.Readlink(filepath.Join(dst, "data", "latest.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, "current.log", link)
+}
+
+func TestRepositoryForgetWhileBackingUp(t *testing.T) {
+	repo, fake := newTestRepository(t)
+	src, err := ioutil.TempDir("", "backup-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	random := rand.New(rand.NewSource(6))
+	for _, id := range []string{"backup-1", "backup-2"} {
+		data := make([]byte, 64<<10)
+		random.Read(data)
+		writeFile(t, filepath.Join(src, "data"), data)
+		_, err := repo.Backup(src, id)
+		assert.Nil(t, err)
+	}
+	countChunks := func() int {
+		var chunks int
+		for key := range fake.objects {
+			if strings.Contains(key, "/chunks/") {
+				chunks++
+			}
+		}
+		return chunks
+	}
+	chunks := countChunks()
+
+	// another backup is running, its chunks may be the ones of the forgotten snapshot
+	assert.Nil(t, repo.lock("backup-3"))
+	forgotten, err := repo.Forget(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"backup-1"}, forgotten)
+	assert.Equal(t, chunks, countChunks())
+
+	// the chunks are pruned by the next forget once the backup finished
+	repo.unlock("backup-3")
+	data := make([]byte, 64<<10)
+	random.Read(data)
+	writeFile(t, filepath.Join(src, "data"), data)
+	_, err = repo.Backup(src, "backup-3")
+	assert.Nil(t, err)
+	_, err = repo.Forget(1)
+	assert.Nil(t, err)
+	snapshot, err := repo.Snapshot("backup-3")
+	assert.Nil(t, err)
+	assert.Equal(t, len(snapshot.Files[0].Chunks), countChunks())
+	for key := range fake.objects {
+		assert.False(t, strings.Contains(key, "/locks/"), key)
+	}
+}
+
+func TestRepositoryEncryption(t *testing.T) {
+	repo, fake := newTestRepository(t)
+	key := make([]byte, 32)
+	rand.New(rand.NewSource(4)).Read(key)
+	master, err := envelope.NewMasterKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.SetKeyring(envelope.NewKeyring([]envelope.KeyWrapper{master}, []envelope.KeyWrapper{master}), true)
+	src, err := ioutil.TempDir("", "backup-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	data := make([]byte, 64<<10)
+	rand.New(rand.NewSource(5)).Read(data)
+	writeFile(t, filepath.Join(src, "data"), data)
+	_, err = repo.Backup(src, "backup-1")
+	assert.Nil(t, err)
+	for key, value := range fake.objects {
+		assert.True(t, envelope.IsEncrypted(value), key)
+	}
+
+	dst, err := ioutil.TempDir("", "backup-dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	assert.Nil(t, repo.Restore("backup-1", dst))
+	restored, err := ioutil.ReadFile(filepath.Join(dst, "data"))
+	assert.Nil(t, err)
+	assert.Equal(t, data, restored)
+
+	// the encrypted backup can not be read without the key
+	repo.SetKeyring(nil, false)
+	assert.NotNil(t, repo.Check("backup-1", true))
+}
@@ -1,7 +1,10 @@
 package cloudos
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
 
@@ -68,6 +71,56 @@ func (a *aliOSS) DeleteObject(objkey string) error {
 	return bucket.DeleteObject(objkey)
 }
 
+func (a *aliOSS) PutObjectData(objkey string, data []byte) error {
+	bucket, err := a.Bucket(a.BucketName)
+	if err != nil {
+		return fmt.Errorf("failed to gets the bucket instance: %v", err)
+	}
+	if err := bucket.PutObject(objkey, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to put object: %v", err)
+	}
+	return nil
+}
+
+func (a *aliOSS) GetObjectData(objkey string) ([]byte, error) {
+	bucket, err := a.Bucket(a.BucketName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to gets the bucket instance: %v", err)
+	}
+	body, err := bucket.GetObject(objkey)
+	if err != nil {
+		svcErr, ok := err.(oss.ServiceError)
+		if !ok {
+			return nil, err
+		}
+		return nil, svcErrToS3SDKError(svcErr)
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+func (a *aliOSS) ListObjects(prefix string) ([]string, error) {
+	bucket, err := a.Bucket(a.BucketName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to gets the bucket instance: %v", err)
+	}
+	var keys []string
+	marker := ""
+	for {
+		result, err := bucket.ListObjects(oss.Prefix(prefix), oss.Marker(marker))
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range result.Objects {
+			keys = append(keys, obj.Key)
+		}
+		if !result.IsTruncated {
+			return keys, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
 func svcErrToS3SDKError(svcErr oss.ServiceError) S3SDKError {
 	return S3SDKError{
 		Code:       svcErr.Code,
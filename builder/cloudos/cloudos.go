@@ -40,6 +40,12 @@ type CloudOSer interface {
 	PutObject(objkey, filepath string) error
 	GetObject(objectKey, filePath string) error
 	DeleteObject(objkey string) error
+	// PutObjectData puts the data as the object
+	PutObjectData(objkey string, data []byte) error
+	// GetObjectData returns the data of the object
+	GetObjectData(objkey string) ([]byte, error)
+	// ListObjects returns the keys of the objects with the prefix
+	ListObjects(prefix string) ([]string, error)
 }
 
 // New returns a new CloudOSer.
@@ -1,6 +1,7 @@
 package cloudos
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"strconv"
@@ -76,3 +77,38 @@ func (s *s3Driver) DeleteObject(objkey string) error {
 	})
 	return err
 }
+
+func (s *s3Driver) PutObjectData(objkey string, data []byte) error {
+	_, err := s.s3.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(objkey),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+func (s *s3Driver) GetObjectData(objkey string) ([]byte, error) {
+	resp, err := s.s3.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.BucketName),
+		Key:    aws.String(objkey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (s *s3Driver) ListObjects(prefix string) ([]string, error) {
+	var keys []string
+	err := s.s3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.BucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.StringValue(obj.Key))
+		}
+		return true
+	})
+	return keys, err
+}
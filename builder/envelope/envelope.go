@@ -0,0 +1,425 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package envelope encrypts the backups and the exported packages with envelope encryption.
+// Every file is encrypted with its own random AES-256-GCM data key, and the data key is
+// wrapped by the customer managed keys: a master key and (or) some PGP recipients.
+//
+// The encrypted file is laid out as
+//
+//	magic | header length (4 bytes, big endian) | header (json) | segments
+//
+// The content is split into segments which are sealed one by one, so that large files
+// can be streamed. The nonce of a segment is made of the nonce prefix, the segment counter
+// and a flag marking the last segment, and the whole header is authenticated with every
+// segment, so the segments can not be reordered, truncated or mixed up with other files.
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// Magic the beginning of the encrypted files
+	Magic = "RBENC1\n"
+	// Ext the extension of the encrypted files
+	Ext = ".enc"
+	// CipherAES256GCM the cipher of the content
+	CipherAES256GCM = "AES-256-GCM"
+
+	// DefaultSegmentSize the size of the plaintext of a segment
+	DefaultSegmentSize = 64 * 1024
+
+	headerVersion   = 1
+	dataKeySize     = 32
+	noncePrefixSize = 7
+	maxHeaderSize   = 1 << 20
+	maxSegmentSize  = 16 << 20
+)
+
+var (
+	// ErrNoKey is returned when no key is configured to encrypt, or none of the keys
+	// is able to unwrap the data key of the encrypted file.
+	ErrNoKey = errors.New("no key available for the encrypted data")
+	// ErrNotEncrypted is returned when the data does not begin with the magic
+	ErrNotEncrypted = errors.New("data is not encrypted")
+	// ErrCorrupted is returned when the encrypted data has been tampered with or truncated
+	ErrCorrupted = errors.New("encrypted data is corrupted")
+)
+
+// Header the header of the encrypted files
+type Header struct {
+	Version     int          `json:"version"`
+	Cipher      string       `json:"cipher"`
+	SegmentSize int          `json:"segment_size"`
+	NoncePrefix []byte       `json:"nonce_prefix"`
+	Keys        []WrappedKey `json:"keys"`
+}
+
+// WrappedKey the data key wrapped by a key of the keyring
+type WrappedKey struct {
+	Scheme     string `json:"scheme"`
+	KeyID      string `json:"key_id"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// Encrypt returns a writer which encrypts the data written into it to w.
+// The writer must be closed to write the last segment.
+func (k *Keyring) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	if !k.CanEncrypt() {
+		return nil, ErrNoKey
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	header := Header{
+		Version:     headerVersion,
+		Cipher:      CipherAES256GCM,
+		SegmentSize: DefaultSegmentSize,
+		NoncePrefix: make([]byte, noncePrefixSize),
+	}
+	if _, err := rand.Read(header.NoncePrefix); err != nil {
+		return nil, err
+	}
+	for _, wrapper := range k.wrappers {
+		wrapped, err := wrapper.Wrap(dataKey)
+		if err != nil {
+			return nil, fmt.Errorf("wrap data key with %s key %s: %v", wrapper.Scheme(), wrapper.KeyID(), err)
+		}
+		header.Keys = append(header.Keys, WrappedKey{Scheme: wrapper.Scheme(), KeyID: wrapper.KeyID(), WrappedKey: wrapped})
+	}
+	raw, err := encodeHeader(&header)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return nil, err
+	}
+	return &segmentWriter{
+		w:      w,
+		aead:   aead,
+		header: &header,
+		aad:    raw,
+		buf:    make([]byte, 0, header.SegmentSize),
+	}, nil
+}
+
+// Decrypt returns a reader which decrypts the data read from r.
+// The reader returns ErrCorrupted if the data has been tampered with.
+func (k *Keyring) Decrypt(r io.Reader) (io.Reader, error) {
+	header, raw, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if header.Cipher != CipherAES256GCM || len(header.NoncePrefix) != noncePrefixSize ||
+		header.SegmentSize <= 0 || header.SegmentSize > maxSegmentSize {
+		return nil, fmt.Errorf("unsupported encrypted data: cipher %s, segment size %d", header.Cipher, header.SegmentSize)
+	}
+	dataKey, err := k.unwrap(header.Keys)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &segmentReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		aad:    raw,
+		buf:    make([]byte, header.SegmentSize+aead.Overhead()),
+	}, nil
+}
+
+// Seal encrypts the data
+func (k *Keyring) Seal(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	w, err := k.Encrypt(&out)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Open decrypts the data sealed by Seal
+func (k *Keyring) Open(data []byte) ([]byte, error) {
+	r, err := k.Decrypt(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// EncryptFile encrypts the file src into dst
+func (k *Keyring) EncryptFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w, err := k.Encrypt(out)
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	if err := w.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return out.Sync()
+}
+
+// DecryptFile decrypts the file src into dst, dst is removed if the decryption fails
+func (k *Keyring) DecryptFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r, err := k.Decrypt(in)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, r); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return out.Sync()
+}
+
+// IsEncrypted returns whether the data begins with the magic
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(Magic))
+}
+
+// IsEncryptedFile returns whether the file is encrypted
+func IsEncryptedFile(name string) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return IsEncrypted(magic), nil
+}
+
+func encodeHeader(header *Header) ([]byte, error) {
+	body, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([]byte, len(Magic)+4+len(body))
+	copy(raw, Magic)
+	binary.BigEndian.PutUint32(raw[len(Magic):], uint32(len(body)))
+	copy(raw[len(Magic)+4:], body)
+	return raw, nil
+}
+
+func readHeader(r io.Reader) (*Header, []byte, error) {
+	prefix := make([]byte, len(Magic)+4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, nil, ErrNotEncrypted
+		}
+		return nil, nil, err
+	}
+	if !IsEncrypted(prefix) {
+		return nil, nil, ErrNotEncrypted
+	}
+	size := binary.BigEndian.Uint32(prefix[len(Magic):])
+	if size > maxHeaderSize {
+		return nil, nil, ErrCorrupted
+	}
+	raw := make([]byte, len(prefix)+int(size))
+	copy(raw, prefix)
+	if _, err := io.ReadFull(r, raw[len(prefix):]); err != nil {
+		return nil, nil, ErrCorrupted
+	}
+	var header Header
+	if err := json.Unmarshal(raw[len(prefix):], &header); err != nil {
+		return nil, nil, ErrCorrupted
+	}
+	if header.Version != headerVersion {
+		return nil, nil, fmt.Errorf("unsupported encrypted data version %d", header.Version)
+	}
+	return &header, raw, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce returns the nonce of the segment: nonce prefix | counter | last flag
+func segmentNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+type segmentWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  *Header
+	aad     []byte
+	buf     []byte
+	counter uint32
+	closed  bool
+}
+
+func (s *segmentWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("write to closed encrypted writer")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		// a full segment is only flushed when more data comes, so that the
+		// last segment is never empty unless the whole content is empty.
+		if len(s.buf) == s.header.SegmentSize {
+			if err := s.flush(false); err != nil {
+				return 0, err
+			}
+		}
+		free := s.header.SegmentSize - len(s.buf)
+		if free > len(p) {
+			free = len(p)
+		}
+		s.buf = append(s.buf, p[:free]...)
+		p = p[free:]
+	}
+	return n, nil
+}
+
+func (s *segmentWriter) flush(last bool) error {
+	if s.counter == ^uint32(0) {
+		return errors.New("too many segments")
+	}
+	sealed := s.aead.Seal(nil, segmentNonce(s.header.NoncePrefix, s.counter, last), s.buf, s.aad)
+	s.counter++
+	s.buf = s.buf[:0]
+	_, err := s.w.Write(sealed)
+	return err
+}
+
+// Close writes the last segment, it does not close the underlying writer
+func (s *segmentWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.flush(true)
+}
+
+type segmentReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  *Header
+	aad     []byte
+	buf     []byte
+	plain   []byte
+	counter uint32
+	done    bool
+}
+
+func (s *segmentReader) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+func (s *segmentReader) next() error {
+	n, err := io.ReadFull(s.r, s.buf)
+	last := false
+	switch err {
+	case nil:
+		if _, err := s.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return err
+	}
+	plain, err := s.aead.Open(s.buf[:0], segmentNonce(s.header.NoncePrefix, s.counter, last), s.buf[:n], s.aad)
+	if err != nil {
+		return ErrCorrupted
+	}
+	s.counter++
+	s.plain = plain
+	s.done = last
+	return nil
+}
@@ -0,0 +1,138 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envelope
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+func newMasterKeyring(t *testing.T) *Keyring {
+	key := make([]byte, 32)
+	rand.Read(key)
+	master, err := NewMasterKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewKeyring([]KeyWrapper{master}, []KeyWrapper{master})
+}
+
+func randomData(size int) []byte {
+	data := make([]byte, size)
+	rand.Read(data)
+	return data
+}
+
+func TestMasterKeyRoundTrip(t *testing.T) {
+	keyring := newMasterKeyring(t)
+	for _, size := range []int{0, 1, DefaultSegmentSize - 1, DefaultSegmentSize, 3*DefaultSegmentSize + 17} {
+		data := randomData(size)
+		sealed, err := keyring.Seal(data)
+		assert.Nil(t, err)
+		assert.True(t, IsEncrypted(sealed))
+		opened, err := keyring.Open(sealed)
+		assert.Nil(t, err)
+		assert.True(t, bytes.Equal(data, opened), "size %d", size)
+	}
+
+	_, err := newMasterKeyring(t).Open(mustSeal(t, keyring, []byte("data")))
+	assert.Equal(t, ErrNoKey, err)
+	_, err = keyring.Open([]byte("plain data"))
+	assert.Equal(t, ErrNotEncrypted, err)
+}
+
+func TestTamperDetection(t *testing.T) {
+	keyring := newMasterKeyring(t)
+	sealed := mustSeal(t, keyring, randomData(2*DefaultSegmentSize+100))
+
+	flipped := append([]byte{}, sealed...)
+	flipped[len(flipped)-DefaultSegmentSize] ^= 1
+	_, err := keyring.Open(flipped)
+	assert.Equal(t, ErrCorrupted, err)
+
+	// dropping the last segment turns the second segment into the last one
+	truncated := sealed[:len(sealed)-116]
+	_, err = keyring.Open(truncated)
+	assert.Equal(t, ErrCorrupted, err)
+}
+
+func TestPGPRoundTrip(t *testing.T) {
+	entity, err := openpgp.NewEntity("rainbond", "backup", "backup@example.com", &packet.Config{RSABits: 1024, DefaultHash: crypto.SHA256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptor := NewKeyring([]KeyWrapper{NewPGPKey(publicOnly(t, entity))}, nil)
+	decryptor := NewKeyring(nil, []KeyWrapper{NewPGPKey(entity)})
+
+	dir, err := ioutil.TempDir("", "envelope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data := randomData(DefaultSegmentSize + 1)
+	src, enc, dst := path.Join(dir, "app.zip"), path.Join(dir, "app.zip.enc"), path.Join(dir, "restored.zip")
+	assert.Nil(t, ioutil.WriteFile(src, data, 0644))
+	assert.Nil(t, encryptor.EncryptFile(src, enc))
+	encrypted, err := IsEncryptedFile(enc)
+	assert.Nil(t, err)
+	assert.True(t, encrypted)
+
+	// the recipient can not decrypt without the private key
+	assert.NotNil(t, encryptor.DecryptFile(enc, dst))
+	assert.Nil(t, decryptor.DecryptFile(enc, dst))
+	restored, err := ioutil.ReadFile(dst)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(data, restored))
+}
+
+func mustSeal(t *testing.T, keyring *Keyring, data []byte) []byte {
+	sealed, err := keyring.Seal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sealed
+}
+
+// publicOnly returns the entity without its private keys, as read from a recipient file
+func publicOnly(t *testing.T, entity *openpgp.Entity) *openpgp.Entity {
+	// sign the identities again, NewEntity sets the preferred hash after signing them
+	for _, id := range entity.Identities {
+		if err := id.SelfSignature.SignUserId(id.UserId.Id, entity.PrimaryKey, entity.PrivateKey, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := entity.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	entities, err := openpgp.ReadKeyRing(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entities[0]
+}
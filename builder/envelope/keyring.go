@@ -0,0 +1,281 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+const (
+	// SchemeMasterKey the data key is wrapped by the master key with AES-256-GCM
+	SchemeMasterKey = "master-key"
+	// SchemePGP the data key is encrypted to a PGP recipient
+	SchemePGP = "pgp"
+)
+
+// KeyWrapper wraps and unwraps the data keys
+type KeyWrapper interface {
+	Scheme() string
+	KeyID() string
+	Wrap(dataKey []byte) ([]byte, error)
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// Options the customer managed keys of the keyring
+type Options struct {
+	// MasterKeyFile the file of the 32 bytes master key, raw, hex or base64 encoded
+	MasterKeyFile string
+	// PGPRecipientFile the public keys the data keys are encrypted to
+	PGPRecipientFile string
+	// PGPPrivateKeyFile the private keys used to decrypt the data keys
+	PGPPrivateKeyFile string
+	// PGPPassphraseFile the passphrase of the private keys
+	PGPPassphraseFile string
+}
+
+// Keyring holds the keys used to wrap the data keys when encrypting,
+// and the keys used to unwrap them when decrypting.
+type Keyring struct {
+	wrappers   []KeyWrapper
+	unwrappers []KeyWrapper
+}
+
+// NewKeyring creates a keyring with the keys which wrap and unwrap the data keys
+func NewKeyring(wrappers, unwrappers []KeyWrapper) *Keyring {
+	return &Keyring{wrappers: wrappers, unwrappers: unwrappers}
+}
+
+// LoadKeyring loads the keys from the files of the options. The keyring
+// is empty if no file is configured, it can neither encrypt nor decrypt.
+func LoadKeyring(opts Options) (*Keyring, error) {
+	k := &Keyring{}
+	if opts.MasterKeyFile != "" {
+		data, err := os.ReadFile(opts.MasterKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read master key: %v", err)
+		}
+		master, err := NewMasterKey(decodeKey(data))
+		if err != nil {
+			return nil, err
+		}
+		k.wrappers = append(k.wrappers, master)
+		k.unwrappers = append(k.unwrappers, master)
+	}
+	if opts.PGPRecipientFile != "" {
+		entities, err := readKeyRing(opts.PGPRecipientFile)
+		if err != nil {
+			return nil, fmt.Errorf("read pgp recipients: %v", err)
+		}
+		for _, entity := range entities {
+			k.wrappers = append(k.wrappers, NewPGPKey(entity))
+		}
+	}
+	if opts.PGPPrivateKeyFile != "" {
+		entities, err := readKeyRing(opts.PGPPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read pgp private keys: %v", err)
+		}
+		if opts.PGPPassphraseFile != "" {
+			passphrase, err := os.ReadFile(opts.PGPPassphraseFile)
+			if err != nil {
+				return nil, fmt.Errorf("read pgp passphrase: %v", err)
+			}
+			if err := decryptEntities(entities, bytes.TrimRight(passphrase, "\r\n")); err != nil {
+				return nil, err
+			}
+		}
+		for _, entity := range entities {
+			k.unwrappers = append(k.unwrappers, NewPGPKey(entity))
+		}
+	}
+	return k, nil
+}
+
+// CanEncrypt returns whether any key is configured to wrap the data keys
+func (k *Keyring) CanEncrypt() bool {
+	return k != nil && len(k.wrappers) > 0
+}
+
+// unwrap tries the keys of the keyring on the wrapped data keys one by one
+func (k *Keyring) unwrap(keys []WrappedKey) ([]byte, error) {
+	if k == nil {
+		return nil, ErrNoKey
+	}
+	var lastErr error
+	for _, wrapped := range keys {
+		for _, unwrapper := range k.unwrappers {
+			if unwrapper.Scheme() != wrapped.Scheme || unwrapper.KeyID() != wrapped.KeyID {
+				continue
+			}
+			dataKey, err := unwrapper.Unwrap(wrapped.WrappedKey)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if len(dataKey) != dataKeySize {
+				lastErr = ErrCorrupted
+				continue
+			}
+			return dataKey, nil
+		}
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("%v: %v", ErrNoKey, lastErr)
+	}
+	return nil, ErrNoKey
+}
+
+type masterKey struct {
+	id  string
+	key []byte
+}
+
+// NewMasterKey creates the wrapper of the 32 bytes master key, the key id is derived from the key
+func NewMasterKey(key []byte) (KeyWrapper, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("the master key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+	sum := sha256.Sum256(key)
+	return &masterKey{id: hex.EncodeToString(sum[:8]), key: key}, nil
+}
+
+func (m *masterKey) Scheme() string { return SchemeMasterKey }
+
+func (m *masterKey) KeyID() string { return m.id }
+
+func (m *masterKey) Wrap(dataKey []byte) ([]byte, error) {
+	aead, err := newAEAD(m.key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(SchemeMasterKey)), nil
+}
+
+func (m *masterKey) Unwrap(wrapped []byte) ([]byte, error) {
+	aead, err := newAEAD(m.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrCorrupted
+	}
+	nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(SchemeMasterKey))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key with master key %s: %v", m.id, err)
+	}
+	return dataKey, nil
+}
+
+type pgpKey struct {
+	entity *openpgp.Entity
+}
+
+// NewPGPKey creates the wrapper of the PGP key. The data keys can only be
+// unwrapped if the entity has the decrypted private key.
+func NewPGPKey(entity *openpgp.Entity) KeyWrapper {
+	return &pgpKey{entity: entity}
+}
+
+func (p *pgpKey) Scheme() string { return SchemePGP }
+
+func (p *pgpKey) KeyID() string { return p.entity.PrimaryKey.KeyIdString() }
+
+func (p *pgpKey) Wrap(dataKey []byte) ([]byte, error) {
+	var out bytes.Buffer
+	w, err := openpgp.Encrypt(&out, openpgp.EntityList{p.entity}, nil, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (p *pgpKey) Unwrap(wrapped []byte) ([]byte, error) {
+	if p.entity.PrivateKey == nil {
+		return nil, fmt.Errorf("pgp key %s has no private key", p.KeyID())
+	}
+	md, err := openpgp.ReadMessage(bytes.NewReader(wrapped), openpgp.EntityList{p.entity}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key with pgp key %s: %v", p.KeyID(), err)
+	}
+	return io.ReadAll(md.UnverifiedBody)
+}
+
+// decodeKey decodes the master key in hex or base64, the raw content is returned otherwise
+func decodeKey(data []byte) []byte {
+	text := strings.TrimSpace(string(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == dataKeySize {
+		return key
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == dataKeySize {
+		return key
+	}
+	return data
+}
+
+// readKeyRing reads the armored or the binary PGP keys from the file
+func readKeyRing(name string) (openpgp.EntityList, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if block, err := armor.Decode(bytes.NewReader(data)); err == nil {
+		return openpgp.ReadKeyRing(block.Body)
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+func decryptEntities(entities openpgp.EntityList, passphrase []byte) error {
+	for _, entity := range entities {
+		if entity.PrivateKey != nil && entity.PrivateKey.Encrypted {
+			if err := entity.PrivateKey.Decrypt(passphrase); err != nil {
+				return fmt.Errorf("decrypt pgp key %s: %v", entity.PrimaryKey.KeyIdString(), err)
+			}
+		}
+		for _, subkey := range entity.Subkeys {
+			if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+				if err := subkey.PrivateKey.Decrypt(passphrase); err != nil {
+					return fmt.Errorf("decrypt pgp subkey %s: %v", subkey.PublicKey.KeyIdString(), err)
+				}
+			}
+		}
+	}
+	return nil
+}
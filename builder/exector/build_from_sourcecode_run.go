@@ -76,6 +76,8 @@ type SourceCodeBuildItem struct {
 	CodeSouceInfo sources.CodeSourceInfo
 	RepoInfo      *sources.RepostoryBuildInfo
 	commit        Commit
+	pipeline      *dbmodel.BuildPipeline
+	pipelineRun   *dbmodel.TenantServicePipelineRun
 	Configs       map[string]gjson.Result `json:"configs"`
 	Ctx           context.Context
 	FailCause     string
@@ -304,8 +306,22 @@ func (i *SourceCodeBuildItem) Run(timeout time.Duration) error {
 		i.Lang = string(lang)
 	}
 
+	pipeline, err := i.loadPipeline()
+	if err != nil {
+		logrus.Errorf("load build pipeline of service %s error: %s", i.ServiceID, err.Error())
+		i.Logger.Error("Invalid build pipeline: "+err.Error(), map[string]string{"step": "builder-exector", "status": "failure"})
+		i.FailCause = "invalid build pipeline"
+		return err
+	}
+	i.pipeline = pipeline
+
 	i.Logger.Info("pull or clone code successfully, start code build", map[string]string{"step": "codee-version"})
-	res, err := i.codeBuild()
+	var res *build.Response
+	if i.pipeline != nil {
+		res, err = i.runPipeline()
+	} else {
+		res, err = i.codeBuild()
+	}
 	if err != nil {
 		if err.Error() == context.DeadlineExceeded.Error() {
 			i.Logger.Error("Build app version from source code timeout, the maximum time is 60 minutes", map[string]string{"step": "builder-exector", "status": "failure"})
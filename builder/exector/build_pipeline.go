@@ -0,0 +1,539 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package exector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eapache/channels"
+	"github.com/goodrain/rainbond/builder/build"
+	jobc "github.com/goodrain/rainbond/builder/job"
+	"github.com/goodrain/rainbond/builder/parser/code"
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultStageTimeout the seconds a stage may take if the stage does not set the timeout
+	defaultStageTimeout = 600
+	// defaultSmokeTimeout the seconds to wait for the preview to pass the smoke check if the gate does not set the timeout
+	defaultSmokeTimeout = 120
+	// implicitBuildStage the name of the build stage appended to the pipelines without a build stage
+	implicitBuildStage = "build-image"
+)
+
+// loadPipeline returns the pipeline defined by the api, or by the rainbondfile of the source code.
+// The source code is built without a pipeline if neither defines one.
+func (i *SourceCodeBuildItem) loadPipeline() (*dbmodel.BuildPipeline, error) {
+	var pipeline *dbmodel.BuildPipeline
+	record, err := db.GetManager().TenantServiceBuildPipelineDao().GetByServiceID(i.ServiceID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("get build pipeline: %v", err)
+	}
+	if err == nil {
+		pipeline, err = record.BuildPipeline()
+		if err != nil {
+			return nil, fmt.Errorf("decode build pipeline: %v", err)
+		}
+	} else {
+		rbdfile, err := code.ReadRainbondFile(i.RepoInfo.GetCodeBuildAbsPath())
+		if err != nil {
+			if err != code.ErrRainbondFileNotFound {
+				logrus.Warningf("read rainbondfile of service %s: %v", i.ServiceID, err)
+			}
+			return nil, nil
+		}
+		pipeline = rbdfile.Pipeline
+	}
+	if pipeline == nil {
+		return nil, nil
+	}
+	if err := pipeline.Validate(); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+// pipelineStages returns the stages of the pipeline, with the build stage appended if absent
+func pipelineStages(pipeline *dbmodel.BuildPipeline) []*dbmodel.PipelineStage {
+	stages := make([]*dbmodel.PipelineStage, 0, len(pipeline.Stages)+1)
+	var hasBuild bool
+	for _, stage := range pipeline.Stages {
+		if stage.Type == dbmodel.PipelineStageTypeBuild {
+			hasBuild = true
+		}
+		stages = append(stages, stage)
+	}
+	if !hasBuild {
+		stages = append(stages, &dbmodel.PipelineStage{Name: implicitBuildStage, Type: dbmodel.PipelineStageTypeBuild})
+	}
+	return stages
+}
+
+// runPipeline runs the stages of the pipeline in order, the image is built by the build stage.
+// The stages after the build stage get the built image from the env BUILD_IMAGE.
+func (i *SourceCodeBuildItem) runPipeline() (*build.Response, error) {
+	stages := pipelineStages(i.pipeline)
+	results := make([]*dbmodel.PipelineStageResult, 0, len(stages))
+	for _, stage := range stages {
+		stageType := stage.Type
+		if stageType == "" {
+			stageType = dbmodel.PipelineStageTypeScript
+		}
+		results = append(results, &dbmodel.PipelineStageResult{Name: stage.Name, Type: stageType, Status: "pending"})
+	}
+	i.pipelineRun = &dbmodel.TenantServicePipelineRun{
+		EventID:       i.EventID,
+		TenantID:      i.TenantID,
+		ServiceID:     i.ServiceID,
+		DeployVersion: i.DeployVersion,
+		Action:        i.Action,
+		Status:        dbmodel.PipelineRunStatusRunning,
+	}
+	if i.pipeline.Gate != nil {
+		i.pipelineRun.GateType = i.pipeline.Gate.Type
+	}
+	i.supersedeWaitingRuns()
+	i.savePipelineRun(results)
+
+	var res *build.Response
+	for idx, stage := range stages {
+		result := results[idx]
+		start := time.Now()
+		result.StartTime, result.Status = &start, "running"
+		i.savePipelineRun(results)
+
+		var err error
+		if stage.Type == dbmodel.PipelineStageTypeBuild {
+			i.Logger.Info(fmt.Sprintf("Pipeline stage %s start, start code build", stage.Name), map[string]string{"step": "pipeline-" + stage.Name, "status": "starting"})
+			res, err = i.codeBuild()
+		} else {
+			var image string
+			if res != nil {
+				image = res.MediumPath
+			}
+			err = i.runStage(stage, image)
+		}
+		finish := time.Now()
+		result.FinishTime = &finish
+		if err != nil {
+			result.Status, result.Message = "failure", err.Error()
+			for _, skipped := range results[idx+1:] {
+				skipped.Status = "skipped"
+			}
+			i.pipelineRun.Status = dbmodel.PipelineRunStatusFailed
+			i.pipelineRun.Message = fmt.Sprintf("stage %s failed", stage.Name)
+			i.pipelineRun.FinishTime = &finish
+			i.savePipelineRun(results)
+			return nil, err
+		}
+		result.Status = "success"
+		i.savePipelineRun(results)
+	}
+	if i.pipeline.Gate == nil {
+		finish := time.Now()
+		i.pipelineRun.Status = dbmodel.PipelineRunStatusSucceeded
+		i.pipelineRun.FinishTime = &finish
+		i.savePipelineRun(nil)
+	}
+	return res, nil
+}
+
+// supersedeWaitingRuns rejects the versions of the component still waiting for the approval,
+// so that an older version can not be deployed after the new one.
+func (i *SourceCodeBuildItem) supersedeWaitingRuns() {
+	runs, err := db.GetManager().TenantServicePipelineRunDao().ListByServiceIDAndStatus(i.ServiceID, dbmodel.PipelineRunStatusWaitingApproval)
+	if err != nil {
+		logrus.Warningf("list the pipeline runs waiting for approval of service %s: %v", i.ServiceID, err)
+		return
+	}
+	now := time.Now()
+	for _, run := range runs {
+		// the run may be approved by the api at the same time
+		claimed, err := db.GetManager().TenantServicePipelineRunDao().UpdateStatusIfMatch(run.EventID, dbmodel.PipelineRunStatusWaitingApproval, dbmodel.PipelineRunStatusRejected)
+		if err != nil || !claimed {
+			continue
+		}
+		run.Status = dbmodel.PipelineRunStatusRejected
+		run.Message = fmt.Sprintf("superseded by version %s", i.DeployVersion)
+		run.FinishTime = &now
+		if err := db.GetManager().TenantServicePipelineRunDao().UpdateModel(run); err != nil {
+			logrus.Warningf("supersede pipeline run %s: %v", run.EventID, err)
+		}
+	}
+}
+
+// savePipelineRun saves the pipeline run, the stage results are kept if results is nil
+func (i *SourceCodeBuildItem) savePipelineRun(results []*dbmodel.PipelineStageResult) error {
+	if results != nil {
+		body, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		i.pipelineRun.Stages = string(body)
+	}
+	var err error
+	if i.pipelineRun.ID == 0 {
+		err = db.GetManager().TenantServicePipelineRunDao().AddModel(i.pipelineRun)
+	} else {
+		err = db.GetManager().TenantServicePipelineRunDao().UpdateModel(i.pipelineRun)
+	}
+	if err != nil {
+		logrus.Warningf("save pipeline run %s: %v", i.pipelineRun.EventID, err)
+	}
+	return err
+}
+
+// runStage runs the commands of the stage in a job pod, whose logs are streamed to the event of the build
+func (i *SourceCodeBuildItem) runStage(stage *dbmodel.PipelineStage, image string) error {
+	step := "pipeline-" + stage.Name
+	i.Logger.Info(fmt.Sprintf("Pipeline stage %s start", stage.Name), map[string]string{"step": step, "status": "starting"})
+	pod, err := i.createStagePod(stage, image)
+	if err != nil {
+		i.Logger.Error(fmt.Sprintf("Pipeline stage %s failed: %v", stage.Name, err), map[string]string{"step": step, "status": "failure"})
+		return fmt.Errorf("stage %s: %v", stage.Name, err)
+	}
+	timeout := time.Duration(stage.Timeout) * time.Second
+	if stage.Timeout == 0 {
+		timeout = defaultStageTimeout * time.Second
+	}
+	if timeout > dbmodel.MaxPipelineStageTimeout*time.Second {
+		timeout = dbmodel.MaxPipelineStageTimeout * time.Second
+	}
+	writer := i.Logger.GetWriter(step, "info")
+	reChan := channels.NewRingChannel(10)
+	// the stage is canceled with the build
+	ctx, cancel := context.WithTimeout(i.Ctx, timeout)
+	defer cancel()
+	if err := jobc.GetJobController().ExecJob(ctx, pod, writer, reChan); err != nil {
+		logrus.Errorf("create job of pipeline stage %s failed: %s", pod.Name, err.Error())
+		i.Logger.Error(fmt.Sprintf("Pipeline stage %s failed: create job failure", stage.Name), map[string]string{"step": step, "status": "failure"})
+		return fmt.Errorf("stage %s: %v", stage.Name, err)
+	}
+	defer jobc.GetJobController().DeleteJob(pod.Name)
+	if err := waitingStageComplete(ctx, reChan, timeout); err != nil {
+		i.Logger.Error(fmt.Sprintf("Pipeline stage %s failed: %v", stage.Name, err), map[string]string{"step": step, "status": "failure"})
+		return fmt.Errorf("stage %s: %v", stage.Name, err)
+	}
+	i.Logger.Info(fmt.Sprintf("Pipeline stage %s succeeded", stage.Name), map[string]string{"step": step, "status": "success"})
+	return nil
+}
+
+// createStagePod creates the job pod of the stage, the source code is mounted from the shared storage
+func (i *SourceCodeBuildItem) createStagePod(stage *dbmodel.PipelineStage, image string) (*corev1.Pod, error) {
+	codeHome := i.RepoInfo.GetCodeHome()
+	if !strings.HasPrefix(codeHome, "/grdata/") {
+		return nil, fmt.Errorf("the source code %s is not on the shared storage", codeHome)
+	}
+	hostAlias, err := i.getHostAlias()
+	if err != nil {
+		return nil, fmt.Errorf("get rbd-repo ip failure: %v", err)
+	}
+	envs := []corev1.EnvVar{
+		{Name: "SERVICE_ID", Value: i.ServiceID},
+		{Name: "TENANT_ID", Value: i.TenantID},
+		{Name: "DEPLOY_VERSION", Value: i.DeployVersion},
+		{Name: "CODE_BRANCH", Value: i.CodeSouceInfo.Branch},
+		{Name: "CODE_COMMIT_HASH", Value: i.commit.Hash},
+		{Name: "PIPELINE_STAGE", Value: stage.Name},
+	}
+	if image != "" {
+		envs = append(envs, corev1.EnvVar{Name: "BUILD_IMAGE", Value: image})
+	}
+	envs = append(envs, sortedEnvs(stage.Envs)...)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s-%s", i.ServiceID, i.DeployVersion, stage.Name),
+			Namespace: i.RbdNamespace,
+			Labels: map[string]string{
+				"service":        i.ServiceID,
+				"job":            "codebuild",
+				"pipeline_stage": stage.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: util.Bool(false),
+			Containers: []corev1.Container{{
+				Name:            "stage",
+				Image:           stage.Image,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Command:         []string{"/bin/sh", "-c", "set -ex\n" + strings.Join(stage.Commands, "\n")},
+				WorkingDir:      path.Join("/app", i.RepoInfo.GetCodeBuildPath()),
+				Env:             envs,
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "source",
+					MountPath: "/app",
+					SubPath:   strings.TrimPrefix(codeHome, "/grdata/"),
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "source",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: i.GRDataPVCName,
+					},
+				},
+			}},
+		},
+	}
+	if i.Arch != "" {
+		pod.Spec.NodeSelector = map[string]string{"kubernetes.io/arch": i.Arch}
+	}
+	for _, ha := range hostAlias {
+		pod.Spec.HostAliases = append(pod.Spec.HostAliases, corev1.HostAlias{IP: ha.IP, Hostnames: ha.Hostnames})
+	}
+	setImagePullSecrets(pod)
+	return pod, nil
+}
+
+func setImagePullSecrets(pod *corev1.Pod) {
+	if name := os.Getenv("IMAGE_PULL_SECRET"); name != "" {
+		pod.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: name}}
+	}
+}
+
+func sortedEnvs(envs map[string]string) []corev1.EnvVar {
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]corev1.EnvVar, 0, len(names))
+	for _, name := range names {
+		result = append(result, corev1.EnvVar{Name: name, Value: envs[name]})
+	}
+	return result
+}
+
+// waitingStageComplete waits until both the job and its logs complete
+func waitingStageComplete(ctx context.Context, reChan *channels.RingChannel, timeout time.Duration) error {
+	var logComplete, jobComplete bool
+	var err error
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("time out (more than %s)", timeout)
+			}
+			return fmt.Errorf("the build is canceled")
+		case jobStatus := <-reChan.Out():
+			switch jobStatus.(string) {
+			case "complete":
+				jobComplete = true
+			case "failed":
+				jobComplete, err = true, fmt.Errorf("the commands exit with failure")
+			case "cancel":
+				jobComplete, err = true, fmt.Errorf("the job is canceled")
+			case "logcomplete":
+				logComplete = true
+			}
+			if jobComplete && logComplete {
+				return err
+			}
+		}
+	}
+}
+
+// passPromotionGate checks the promotion gate of the pipeline, the new version is deployed only if it passes.
+// The manual gate holds the version until it is approved by the api.
+func (i *SourceCodeBuildItem) passPromotionGate(configs map[string]string) bool {
+	if i.pipeline == nil || i.pipeline.Gate == nil || i.pipelineRun == nil {
+		return true
+	}
+	run := i.pipelineRun
+	switch i.pipeline.Gate.Type {
+	case dbmodel.PromotionGateManual:
+		body, _ := json.Marshal(configs)
+		run.Configs = string(body)
+		run.Status = dbmodel.PipelineRunStatusWaitingApproval
+		if err := i.savePipelineRun(nil); err != nil {
+			i.Logger.Error("Build success, but the version can not wait for the approval, please deploy it manually", map[string]string{"step": "callback", "status": "failure"})
+			return false
+		}
+		i.Logger.Info("Build success, the version is waiting for the approval before being deployed", map[string]string{"step": "last", "status": "success"})
+		return false
+	case dbmodel.PromotionGateSmoke:
+		err := i.smokeCheck(i.pipeline.Gate)
+		finish := time.Now()
+		run.FinishTime = &finish
+		if err != nil {
+			run.Status = dbmodel.PipelineRunStatusRejected
+			run.Message = fmt.Sprintf("smoke check failed: %v", err)
+			i.savePipelineRun(nil)
+			i.Logger.Error(fmt.Sprintf("The smoke check of the new version failed, the version is not deployed: %v", err), map[string]string{"step": "callback", "status": "failure"})
+			return false
+		}
+		run.Status = dbmodel.PipelineRunStatusPromoted
+		run.Message = "smoke check passed"
+		i.savePipelineRun(nil)
+	}
+	return true
+}
+
+// smokeCheck runs a preview of the new version with the envs of the component,
+// and waits until the http check of the gate passes.
+func (i *SourceCodeBuildItem) smokeCheck(gate *dbmodel.PromotionGate) error {
+	step := "pipeline-gate"
+	version, err := db.GetManager().VersionInfoDao().GetVersionByDeployVersion(i.DeployVersion, i.ServiceID)
+	if err != nil {
+		return fmt.Errorf("get version: %v", err)
+	}
+	if version.DeliveredType != string(build.ImageMediumType) {
+		return fmt.Errorf("the smoke check only supports the versions delivered as images")
+	}
+	envs, err := i.previewEnvs(gate)
+	if err != nil {
+		return err
+	}
+	var args []string
+	if version.Cmd != "" {
+		configs := make(map[string]string, len(envs))
+		for _, env := range envs {
+			configs[env.Name] = env.Value
+		}
+		args = strings.Fields(util.ParseVariable(version.Cmd, configs))
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s-preview", i.ServiceID, i.DeployVersion),
+			Namespace: i.RbdNamespace,
+			Labels: map[string]string{
+				"service":          i.ServiceID,
+				"pipeline_preview": "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: util.Bool(false),
+			Containers: []corev1.Container{{
+				Name:            "preview",
+				Image:           version.DeliveredPath,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Args:            args,
+				Env:             envs,
+				Ports:           []corev1.ContainerPort{{ContainerPort: int32(gate.Port)}},
+			}},
+		},
+	}
+	if i.Arch != "" {
+		pod.Spec.NodeSelector = map[string]string{"kubernetes.io/arch": i.Arch}
+	}
+	setImagePullSecrets(pod)
+	pods := i.KubeClient.CoreV1().Pods(i.RbdNamespace)
+	if _, err := pods.Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("create preview: %v", err)
+	}
+	defer func() {
+		if err := pods.Delete(context.Background(), pod.Name, metav1.DeleteOptions{}); err != nil {
+			logrus.Warningf("delete preview %s: %v", pod.Name, err)
+		}
+	}()
+	i.Logger.Info("Start the preview of the new version for the smoke check", map[string]string{"step": step, "status": "starting"})
+
+	timeout := time.Duration(gate.Timeout) * time.Second
+	if gate.Timeout == 0 {
+		timeout = defaultSmokeTimeout * time.Second
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	deadline := time.Now().Add(timeout)
+	lastErr := fmt.Errorf("the preview is not running in %s", timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(2 * time.Second)
+		current, err := pods.Get(context.Background(), pod.Name, metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if current.Status.Phase == corev1.PodFailed || current.Status.Phase == corev1.PodSucceeded {
+			lastErr = fmt.Errorf("the preview exits")
+			break
+		}
+		if current.Status.Phase != corev1.PodRunning || current.Status.PodIP == "" {
+			continue
+		}
+		url := fmt.Sprintf("http://%s%s", net.JoinHostPort(current.Status.PodIP, strconv.Itoa(gate.Port)), gate.Path)
+		if lastErr = checkPreview(client, url, gate.ExpectStatus); lastErr == nil {
+			i.Logger.Info("The smoke check of the new version passed", map[string]string{"step": step, "status": "success"})
+			return nil
+		}
+	}
+	i.writePreviewLogs(pod.Name, step)
+	return lastErr
+}
+
+// previewEnvs returns the envs of the component, overridden by the envs of the gate.
+// The envs read from the external secret store are not available to the preview.
+func (i *SourceCodeBuildItem) previewEnvs(gate *dbmodel.PromotionGate) ([]corev1.EnvVar, error) {
+	serviceEnvs, err := db.GetManager().TenantServiceEnvVarDao().GetServiceEnvs(i.ServiceID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get envs of the component: %v", err)
+	}
+	merged := make(map[string]string, len(serviceEnvs)+len(gate.Envs))
+	for _, env := range serviceEnvs {
+		if env.IsExternalSecret() {
+			continue
+		}
+		merged[env.AttrName] = env.AttrValue
+	}
+	for name, value := range gate.Envs {
+		merged[name] = value
+	}
+	return sortedEnvs(merged), nil
+}
+
+// checkPreview requests the url of the preview, any status below 400 passes if expect is 0
+func checkPreview(client *http.Client, url string, expect int) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == expect || (expect == 0 && resp.StatusCode < 400) {
+		return nil
+	}
+	return fmt.Errorf("%s returns status %d", url, resp.StatusCode)
+}
+
+// writePreviewLogs writes the last logs of the preview to the event of the build
+func (i *SourceCodeBuildItem) writePreviewLogs(name, step string) {
+	tail := int64(50)
+	logs, err := i.KubeClient.CoreV1().Pods(i.RbdNamespace).GetLogs(name, &corev1.PodLogOptions{TailLines: &tail}).DoRaw(context.Background())
+	if err != nil {
+		logrus.Warningf("get logs of preview %s: %v", name, err)
+		return
+	}
+	i.Logger.GetWriter(step, "info").Write(logs)
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 
+	"github.com/goodrain/rainbond/builder/envelope"
 	"github.com/goodrain/rainbond/builder/job"
 	"github.com/goodrain/rainbond/cmd/builder/option"
 	"github.com/goodrain/rainbond/db"
@@ -117,6 +118,16 @@ func NewManager(conf option.Config, mqc mqclient.MQClient) (Manager, error) {
 		cancel()
 		return nil, err
 	}
+	keyring, err := envelope.LoadKeyring(envelope.Options{
+		MasterKeyFile:     conf.EncryptionMasterKeyFile,
+		PGPRecipientFile:  conf.EncryptionPGPRecipientFile,
+		PGPPrivateKeyFile: conf.EncryptionPGPPrivateKeyFile,
+		PGPPassphraseFile: conf.EncryptionPGPPassphraseFile,
+	})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("load encryption keys: %v", err)
+	}
 	logrus.Infof("The maximum number of concurrent build tasks supported by the current node is %d", maxConcurrentTask)
 
 	return &exectorManager{
@@ -132,6 +143,7 @@ func NewManager(conf option.Config, mqc mqclient.MQClient) (Manager, error) {
 		cancel:            cancel,
 		cfg:               conf,
 		imageClient:       imageClient,
+		keyring:           keyring,
 	}, nil
 }
 
@@ -150,6 +162,7 @@ type exectorManager struct {
 	runningTask       sync.Map
 	cfg               option.Config
 	imageClient       sources.ImageClient
+	keyring           *envelope.Keyring
 }
 
 // TaskWorker worker interface
@@ -398,6 +411,9 @@ func (e *exectorManager) buildFromSourceCode(task *pb.TaskMessage) {
 		for k, v := range i.Configs {
 			configs[k] = v.String()
 		}
+		if !i.passPromotionGate(configs) {
+			return
+		}
 		if err := e.UpdateDeployVersion(i.ServiceID, i.DeployVersion); err != nil {
 			logrus.Errorf("Update app service deploy version failure %s, service %s do not auto upgrade", err.Error(), i.ServiceID)
 			return
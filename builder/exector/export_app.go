@@ -33,6 +33,7 @@ import (
 	"github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
 	ramv1alpha1 "github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
 	"github.com/goodrain/rainbond/builder"
+	"github.com/goodrain/rainbond/builder/envelope"
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/event"
 	"github.com/pkg/errors"
@@ -47,8 +48,11 @@ type ExportApp struct {
 	EventID     string `json:"event_id"`
 	Format      string `json:"format"`
 	SourceDir   string `json:"source_dir"`
+	// Encrypt encrypts the package with the keys configured for the builder
+	Encrypt     bool `json:"encrypt"`
 	Logger      event.Logger
 	ImageClient sources.ImageClient
+	keyring     *envelope.Keyring
 }
 
 func init() {
@@ -62,15 +66,21 @@ func NewExportApp(in []byte, m *exectorManager) (TaskWorker, error) {
 	return &ExportApp{
 		Format:      gjson.GetBytes(in, "format").String(),
 		SourceDir:   gjson.GetBytes(in, "source_dir").String(),
+		Encrypt:     gjson.GetBytes(in, "encrypt").Bool(),
 		Logger:      logger,
 		EventID:     eventID,
 		ImageClient: m.imageClient,
+		keyring:     m.keyring,
 	}, nil
 }
 
 //Run Run
 func (i *ExportApp) Run(timeout time.Duration) error {
 	defer os.RemoveAll(i.SourceDir)
+	if i.Encrypt && !i.keyring.CanEncrypt() {
+		i.updateStatus("failed", "")
+		return fmt.Errorf("encrypt package: %v", envelope.ErrNoKey)
+	}
 	// disable Md5 checksum
 	// if ok := i.isLatest(); ok {
 	// 	i.updateStatus("success")
@@ -123,7 +133,15 @@ func (i *ExportApp) Run(timeout time.Duration) error {
 	if re != nil {
 		// move package file to download dir
 		downloadPath := path.Dir(i.SourceDir)
-		os.Rename(re.PackagePath, path.Join(downloadPath, re.PackageName))
+		if i.Encrypt {
+			if err := i.encryptPackage(re, downloadPath); err != nil {
+				logrus.Errorf("encrypt app package failure %s", err.Error())
+				i.updateStatus("failed", "")
+				return err
+			}
+		} else {
+			os.Rename(re.PackagePath, path.Join(downloadPath, re.PackageName))
+		}
 		packageDownloadPath := path.Join("/v2/app/download/", i.Format, re.PackageName)
 		// update export event status
 		if err := i.updateStatus("success", packageDownloadPath); err != nil {
@@ -136,6 +154,13 @@ func (i *ExportApp) Run(timeout time.Duration) error {
 	return nil
 }
 
+// encryptPackage writes the encrypted package into the download dir, the plaintext package is removed
+func (i *ExportApp) encryptPackage(re *export.Result, downloadPath string) error {
+	defer os.Remove(re.PackagePath)
+	re.PackageName += envelope.Ext
+	return i.keyring.EncryptFile(re.PackagePath, path.Join(downloadPath, re.PackageName))
+}
+
 func (i *ExportApp) handleDefaultRepo(ram *v1alpha1.RainbondApplicationConfig) {
 	for i := range ram.Components {
 		com := ram.Components[i]
@@ -34,7 +34,9 @@ import (
 	"github.com/goodrain/rainbond/builder/sources/registry"
 	"github.com/goodrain/rainbond/db"
 
+	"github.com/goodrain/rainbond/builder/backup"
 	"github.com/goodrain/rainbond/builder/cloudos"
+	"github.com/goodrain/rainbond/builder/envelope"
 	dbmodel "github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/event"
 	"github.com/pquerna/ffjson/ffjson"
@@ -64,8 +66,14 @@ type BackupAPPNew struct {
 	BackupSize  int64
 	Logger      event.Logger
 	ImageClient sources.ImageClient
-	//full-online,full-offline
-	Mode     string `json:"mode"`
+	//full-online,full-offline,incremental-online
+	Mode string `json:"mode"`
+	// Retention the number of the incremental backups kept for the app, 0 means keeping all of them
+	Retention int `json:"retention"`
+	// Repository the prefix of the repository keeping the incremental backup, defaults to the one of the app
+	Repository string `json:"repository"`
+	// Encrypt encrypts the backup with the keys configured for the builder
+	Encrypt  bool `json:"encrypt"`
 	S3Config struct {
 		Provider   string `json:"provider"`
 		Endpoint   string `json:"endpoint"`
@@ -73,6 +81,7 @@ type BackupAPPNew struct {
 		SecretKey  string `json:"secret_key"`
 		BucketName string `json:"bucket_name"`
 	} `json:"s3_config"`
+	keyring *envelope.Keyring
 }
 
 func init() {
@@ -88,6 +97,7 @@ func BackupAPPNewCreater(in []byte, m *exectorManager) (TaskWorker, error) {
 		EventID: eventID,
 
 		ImageClient: m.imageClient,
+		keyring:     m.keyring,
 	}
 	if err := ffjson.Unmarshal(in, &backupNew); err != nil {
 		return nil, err
@@ -126,6 +136,10 @@ type RegionServiceSnapshot struct {
 
 //Run Run
 func (b *BackupAPPNew) Run(timeout time.Duration) error {
+	if b.Encrypt && !b.keyring.CanEncrypt() {
+		b.Logger.Error("No encryption key is configured for the builder", map[string]string{"step": "backup_builder", "status": "failure"})
+		return fmt.Errorf("encrypt backup: %v", envelope.ErrNoKey)
+	}
 	//read region group app metadata
 	metadata, err := ioutil.ReadFile(fmt.Sprintf("%s/region_apps_metadata.json", b.SourceDir))
 	if err != nil {
@@ -166,15 +180,27 @@ func (b *BackupAPPNew) Run(timeout time.Duration) error {
 	if strings.HasSuffix(b.SourceDir, "/") {
 		b.SourceDir = b.SourceDir[:len(b.SourceDir)-2]
 	}
+	if b.Mode == "incremental-online" {
+		if err := b.backupIncremental(); err != nil {
+			return fmt.Errorf("error backing up incrementally: %v", err)
+		}
+		return b.updateBackupStatu("success")
+	}
 	if err := util.Zip(b.SourceDir, fmt.Sprintf("%s.zip", b.SourceDir)); err != nil {
 		b.Logger.Info(fmt.Sprintf("Compressed backup metadata failed"), map[string]string{"step": "backup_builder", "status": "starting"})
 		return err
 	}
-	b.BackupSize += util.GetFileSize(fmt.Sprintf("%s.zip", b.SourceDir))
 	if err := os.RemoveAll(b.SourceDir); err != nil {
 		logrus.Warningf("error removing temporary direcotry: %v", err)
 	}
 	b.SourceDir = fmt.Sprintf("%s.zip", b.SourceDir)
+	if b.Encrypt {
+		if err := b.encryptPkg(); err != nil {
+			b.Logger.Error("Encrypt backup package failed", map[string]string{"step": "backup_builder", "status": "failure"})
+			return fmt.Errorf("error encrypting backup package: %v", err)
+		}
+	}
+	b.BackupSize += util.GetFileSize(b.SourceDir)
 
 	if err := b.uploadPkg(); err != nil {
 		return fmt.Errorf("error upload backup package: %v", err)
@@ -197,10 +223,35 @@ func (b *BackupAPPNew) uploadPkg() error {
 		}
 	}()
 
-	s3Provider, err := cloudos.Str2S3Provider(b.S3Config.Provider)
+	cloudoser, err := b.newCloudOSer()
 	if err != nil {
 		return err
 	}
+	_, filename := filepath.Split(b.SourceDir)
+	if err := cloudoser.PutObject(filename, b.SourceDir); err != nil {
+		return fmt.Errorf("object key: %s; filepath: %s; error putting object: %v", filename, b.SourceDir, err)
+	}
+	return nil
+}
+
+// encryptPkg replaces the backup package with the encrypted one
+func (b *BackupAPPNew) encryptPkg() error {
+	encrypted := b.SourceDir + envelope.Ext
+	if err := b.keyring.EncryptFile(b.SourceDir, encrypted); err != nil {
+		return err
+	}
+	if err := os.Remove(b.SourceDir); err != nil {
+		logrus.Warningf("error removing temporary file: %v", err)
+	}
+	b.SourceDir = encrypted
+	return nil
+}
+
+func (b *BackupAPPNew) newCloudOSer() (cloudos.CloudOSer, error) {
+	s3Provider, err := cloudos.Str2S3Provider(b.S3Config.Provider)
+	if err != nil {
+		return nil, err
+	}
 	cfg := &cloudos.Config{
 		ProviderType: s3Provider,
 		Endpoint:     b.S3Config.Endpoint,
@@ -210,11 +261,63 @@ func (b *BackupAPPNew) uploadPkg() error {
 	}
 	cloudoser, err := cloudos.New(cfg)
 	if err != nil {
-		return fmt.Errorf("error creating cloudoser: %v", err)
+		return nil, fmt.Errorf("error creating cloudoser: %v", err)
 	}
-	_, filename := filepath.Split(b.SourceDir)
-	if err := cloudoser.PutObject(filename, b.SourceDir); err != nil {
-		return fmt.Errorf("object key: %s; filepath: %s; error putting object: %v", filename, b.SourceDir, err)
+	return cloudoser, nil
+}
+
+// backupRepositoryPrefix the prefix of the repository keeping the incremental backups of the app
+func backupRepositoryPrefix(groupID string) string {
+	return path.Join("rainbond-backups", groupID)
+}
+
+// backupIncremental stores the backup directory in the chunked repository of the app, only the chunks
+// not uploaded by the previous backups are uploaded. The backups beyond the retention are removed.
+func (b *BackupAPPNew) backupIncremental() error {
+	cloudoser, err := b.newCloudOSer()
+	if err != nil {
+		return err
+	}
+	prefix := b.Repository
+	if prefix == "" {
+		prefix = backupRepositoryPrefix(b.GroupID)
+	}
+	if b.Encrypt {
+		// the encrypted backups never share the chunks with the plaintext ones
+		prefix = path.Join(prefix, "encrypted")
+	}
+	repo := backup.NewRepository(cloudoser, prefix)
+	repo.SetKeyring(b.keyring, b.Encrypt)
+	b.Logger.Info("Start uploading the changed data of the backup", map[string]string{"step": "backup_builder", "status": "starting"})
+	stats, err := repo.Backup(b.SourceDir, b.BackupID)
+	if err != nil {
+		return err
+	}
+	if err := repo.Check(b.BackupID, false); err != nil {
+		return fmt.Errorf("verify backup: %v", err)
+	}
+	b.Logger.Info(fmt.Sprintf("Uploaded %d of %d chunks, %d of %d bytes", stats.NewChunks, stats.Chunks, stats.UploadedSize, stats.Size),
+		map[string]string{"step": "backup_builder", "status": "success"})
+	b.BackupSize = stats.Size
+	if err := os.RemoveAll(b.SourceDir); err != nil {
+		logrus.Warningf("error removing temporary direcotry: %v", err)
+	}
+	b.SourceDir = repo.Prefix()
+
+	forgotten, err := repo.Forget(b.Retention)
+	if err != nil {
+		// the backup is done, the expired backups are removed next time
+		logrus.Warningf("group %s: error removing expired backups: %v", b.GroupID, err)
+	}
+	for _, backupID := range forgotten {
+		appBackup, err := db.GetManager().AppBackupDao().GetAppBackup(backupID)
+		if err != nil {
+			continue
+		}
+		appBackup.Status = "expired"
+		if err := db.GetManager().AppBackupDao().UpdateModel(appBackup); err != nil {
+			logrus.Warningf("backup %s: error updating status: %v", backupID, err)
+		}
 	}
 	return nil
 }
@@ -31,7 +31,9 @@ import (
 
 	"github.com/coreos/etcd/clientv3"
 	"github.com/goodrain/rainbond/builder"
+	"github.com/goodrain/rainbond/builder/backup"
 	"github.com/goodrain/rainbond/builder/cloudos"
+	"github.com/goodrain/rainbond/builder/envelope"
 	"github.com/goodrain/rainbond/builder/parser"
 	"github.com/goodrain/rainbond/builder/sources"
 	"github.com/goodrain/rainbond/db"
@@ -62,6 +64,7 @@ type BackupAPPRestore struct {
 	serviceChange map[string]*Info
 	volumeIDMap   map[uint]uint
 	etcdcli       *clientv3.Client
+	keyring       *envelope.Keyring
 
 	S3Config struct {
 		Provider   string `json:"provider"`
@@ -95,6 +98,7 @@ func BackupAPPRestoreCreater(in []byte, m *exectorManager) (TaskWorker, error) {
 		etcdcli:       m.EtcdCli,
 		serviceChange: make(map[string]*Info, 0),
 		volumeIDMap:   make(map[uint]uint),
+		keyring:       m.keyring,
 	}
 	if err := ffjson.Unmarshal(in, &backupRestore); err != nil {
 		return nil, err
@@ -126,8 +130,14 @@ func (b *BackupAPPRestore) Run(timeout time.Duration) error {
 		if err := b.downloadFromS3(backup.SourceDir); err != nil {
 			return fmt.Errorf("error downloading file from s3: %v", err)
 		}
+	case "incremental-online":
+		if err := b.restoreFromRepository(backup); err != nil {
+			return fmt.Errorf("error restoring backup from repository: %v", err)
+		}
 	default:
-		b.downloadFromLocal(backup)
+		if err := b.downloadFromLocal(backup); err != nil {
+			return err
+		}
 	}
 
 	//read metadata file
@@ -711,8 +721,12 @@ func (b *BackupAPPRestore) restoreMetadata(appSnapshot *AppSnapshot) error {
 }
 
 func (b *BackupAPPRestore) downloadFromLocal(backup *dbmodel.AppBackup) error {
-	sourceDir := backup.SourceDir
-	err := util.Unzip(sourceDir, b.cacheDir, false)
+	sourceDir, err := b.decryptPkg(backup.SourceDir)
+	if err != nil {
+		b.Logger.Error(util.Translation("decrypt backup file error"), map[string]string{"step": "backup_builder", "status": "failure"})
+		return err
+	}
+	err = util.Unzip(sourceDir, b.cacheDir, false)
 	if err != nil {
 		b.Logger.Error(util.Translation("unzip metadata file error"), map[string]string{"step": "backup_builder", "status": "failure"})
 		logrus.Errorf("unzip file error when restore backup app , %s", err.Error())
@@ -727,10 +741,28 @@ func (b *BackupAPPRestore) downloadFromLocal(backup *dbmodel.AppBackup) error {
 	return nil
 }
 
-func (b *BackupAPPRestore) downloadFromS3(sourceDir string) error {
+// decryptPkg decrypts the encrypted backup package into the cache directory.
+// The path of the plaintext package is returned.
+func (b *BackupAPPRestore) decryptPkg(pkg string) (string, error) {
+	encrypted, err := envelope.IsEncryptedFile(pkg)
+	if err != nil || !encrypted {
+		return pkg, err
+	}
+	name := strings.TrimSuffix(filepath.Base(pkg), envelope.Ext)
+	if name == filepath.Base(pkg) {
+		name += ".zip"
+	}
+	plain := path.Join(b.cacheDir, name)
+	if err := b.keyring.DecryptFile(pkg, plain); err != nil {
+		return "", fmt.Errorf("error decrypting backup file %s: %v", pkg, err)
+	}
+	return plain, nil
+}
+
+func (b *BackupAPPRestore) newCloudOSer() (cloudos.CloudOSer, error) {
 	s3Provider, err := cloudos.Str2S3Provider(b.S3Config.Provider)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	cfg := &cloudos.Config{
 		ProviderType: s3Provider,
@@ -741,7 +773,30 @@ func (b *BackupAPPRestore) downloadFromS3(sourceDir string) error {
 	}
 	cloudoser, err := cloudos.New(cfg)
 	if err != nil {
-		return fmt.Errorf("error creating cloudoser: %v", err)
+		return nil, fmt.Errorf("error creating cloudoser: %v", err)
+	}
+	return cloudoser, nil
+}
+
+// restoreFromRepository reassembles the backup from the chunks of the repository, the chunks are verified while restoring
+func (b *BackupAPPRestore) restoreFromRepository(appBackup *dbmodel.AppBackup) error {
+	cloudoser, err := b.newCloudOSer()
+	if err != nil {
+		return err
+	}
+	repo := backup.NewRepository(cloudoser, appBackup.SourceDir)
+	repo.SetKeyring(b.keyring, false)
+	if err := repo.Restore(appBackup.BackupID, b.cacheDir); err != nil {
+		return err
+	}
+	logrus.Debugf("successfully restoring backup %s from repository %s", appBackup.BackupID, appBackup.SourceDir)
+	return nil
+}
+
+func (b *BackupAPPRestore) downloadFromS3(sourceDir string) error {
+	cloudoser, err := b.newCloudOSer()
+	if err != nil {
+		return err
 	}
 
 	_, objectKey := filepath.Split(sourceDir)
@@ -752,6 +807,9 @@ func (b *BackupAPPRestore) downloadFromS3(sourceDir string) error {
 	}
 	logrus.Debugf("successfully downloading backup file: %s", disDir)
 
+	if disDir, err = b.decryptPkg(disDir); err != nil {
+		return err
+	}
 	err = util.Unzip(disDir, b.cacheDir, false)
 	if err != nil {
 		// b.Logger.Error(util.Translation("unzip metadata file error"), map[string]string{"step": "backup_builder", "status": "failure"})
@@ -36,6 +36,7 @@ import (
 	"github.com/goodrain/rainbond-oam/pkg/ram/v1alpha1"
 	"github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/builder"
+	"github.com/goodrain/rainbond/builder/envelope"
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/event"
 	"github.com/sirupsen/logrus"
@@ -56,6 +57,7 @@ type ImportApp struct {
 	oldAPPPath    map[string]string
 	oldPluginPath map[string]string
 	ImageClient   sources.ImageClient
+	keyring       *envelope.Keyring
 }
 
 //NewImportApp create
@@ -72,6 +74,7 @@ func NewImportApp(in []byte, m *exectorManager) (TaskWorker, error) {
 	logrus.Infof("load app image to hub %s", importApp.ServiceImage.HubURL)
 	importApp.Logger = event.GetManager().GetLogger(importApp.EventID)
 	importApp.ImageClient = m.imageClient
+	importApp.keyring = m.keyring
 
 	importApp.oldAPPPath = make(map[string]string)
 	importApp.oldPluginPath = make(map[string]string)
@@ -131,7 +134,14 @@ func (i *ImportApp) importApp() error {
 			if err := i.updateStatusForApp(app, "importing"); err != nil {
 				logrus.Errorf("Failed to update status to importing for app %s: %v", app, err)
 			}
-			ram, err := li.Import(appFile, v1alpha1.ImageInfo{
+			pkgFile, err := i.decryptApp(appFile, path.Join(oldSourceDir, app+"-decrypted"))
+			if err != nil {
+				logrus.Errorf("Failed to decrypt app %s: %v", appFile, err)
+				i.updateStatusForApp(app, "failed")
+				return
+			}
+			defer os.RemoveAll(path.Join(oldSourceDir, app+"-decrypted"))
+			ram, err := li.Import(pkgFile, v1alpha1.ImageInfo{
 				HubURL:      i.ServiceImage.HubURL,
 				HubUser:     i.ServiceImage.HubUser,
 				HubPassword: i.ServiceImage.HubPassword,
@@ -162,6 +172,23 @@ func (i *ImportApp) importApp() error {
 	return nil
 }
 
+// decryptApp decrypts the encrypted app package into the dir, the name of the
+// package without the encrypted extension is kept, so that its format can be recognized.
+func (i *ImportApp) decryptApp(appFile, dir string) (string, error) {
+	encrypted, err := envelope.IsEncryptedFile(appFile)
+	if err != nil || !encrypted {
+		return appFile, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	pkgFile := path.Join(dir, strings.TrimSuffix(filepath.Base(appFile), envelope.Ext))
+	if err := i.keyring.DecryptFile(appFile, pkgFile); err != nil {
+		return "", err
+	}
+	return pkgFile, nil
+}
+
 func (i *ImportApp) updateStatus(status string) error {
 	logrus.Debug("Update app status in database to: ", status)
 	// 从数据库中获取该应用的状态信息
@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"path"
 
+	dbmodel "github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/util"
 	"github.com/sirupsen/logrus"
 	yaml "gopkg.in/yaml.v2"
@@ -36,6 +37,8 @@ type RainbondFileConfig struct {
 	Envs      map[string]interface{} `yaml:"envs"`
 	Cmd       string                 `yaml:"cmd"`
 	Services  []*Service             `yaml:"services"`
+	// Pipeline the stages and the promotion gate of the source code build
+	Pipeline *dbmodel.BuildPipeline `yaml:"pipeline"`
 }
 
 // Service contains
@@ -29,3 +29,23 @@ func TestReadRainbondFile(t *testing.T) {
 	}
 	t.Log(rbdfile)
 }
+
+func TestReadRainbondFilePipeline(t *testing.T) {
+	rbdfile, err := ReadRainbondFile("./")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline := rbdfile.Pipeline
+	if pipeline == nil || len(pipeline.Stages) != 2 || pipeline.Gate == nil {
+		t.Fatalf("unexpected pipeline %+v", pipeline)
+	}
+	if stage := pipeline.Stages[0]; stage.Name != "unit-test" || stage.Commands[0] != "mvn test" || stage.Timeout != 900 {
+		t.Errorf("unexpected stage %+v", stage)
+	}
+	if pipeline.Gate.Port != 7777 || pipeline.Gate.Path != "/health" || pipeline.Gate.ExpectStatus != 200 {
+		t.Errorf("unexpected gate %+v", pipeline.Gate)
+	}
+	if err := pipeline.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -46,11 +46,13 @@ import (
 	netssh "golang.org/x/crypto/ssh"
 	sshkey "golang.org/x/crypto/ssh"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 	"gopkg.in/src-d/go-git.v4/plumbing/transport/client"
 	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
 	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
 )
 
 //CodeSourceInfo 代码源信息
@@ -444,6 +446,73 @@ func GetLastCommit(re *git.Repository) (*object.Commit, error) {
 	return re.CommitObject(ref.Hash())
 }
 
+//GetRemoteLastCommit get the hash of the last commit of the branch of the remote repository,
+//the references are listed like git ls-remote without fetching any object
+func GetRemoteLastCommit(csi CodeSourceInfo, timeout time.Duration) (plumbing.Hash, error) {
+	ep, err := transport.NewEndpoint(csi.RepositoryURL)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	opts := &git.ListOptions{}
+	if ep.Protocol == "ssh" {
+		sshAuth, err := ssh.NewPublicKeysFromFile("git", GetPrivateFile(csi.TenantID), "")
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		sshAuth.HostKeyCallbackHelper.HostKeyCallback = netssh.InsecureIgnoreHostKey()
+		opts.Auth = sshAuth
+	} else if csi.User != "" && csi.Password != "" {
+		opts.Auth = &githttp.BasicAuth{
+			Username: csi.User,
+			Password: csi.Password,
+		}
+	}
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{csi.RepositoryURL}})
+	type result struct {
+		refs []*plumbing.Reference
+		err  error
+	}
+	// List does not take a context, the result is dropped once the timeout expires
+	ch := make(chan result, 1)
+	go func() {
+		refs, err := remote.List(opts)
+		ch <- result{refs: refs, err: err}
+	}()
+	var refs []*plumbing.Reference
+	select {
+	case res := <-ch:
+		if res.err != nil {
+			return plumbing.ZeroHash, res.err
+		}
+		refs = res.refs
+	case <-time.After(timeout):
+		return plumbing.ZeroHash, fmt.Errorf("list the references of %s timeout", csi.RepositoryURL)
+	}
+	name := plumbing.HEAD
+	if csi.Branch != "" {
+		name = getBranch(csi.Branch)
+	}
+	for _, ref := range refs {
+		if ref.Name() != name {
+			continue
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			return lookupReference(refs, ref.Target())
+		}
+		return ref.Hash(), nil
+	}
+	return plumbing.ZeroHash, fmt.Errorf("reference %s not found in %s", name, csi.RepositoryURL)
+}
+
+func lookupReference(refs []*plumbing.Reference, name plumbing.ReferenceName) (plumbing.Hash, error) {
+	for _, ref := range refs {
+		if ref.Name() == name && ref.Type() == plumbing.HashReference {
+			return ref.Hash(), nil
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("reference %s not found", name)
+}
+
 //GetPrivateFile 获取私钥文件地址
 func GetPrivateFile(tenantID string) string {
 	home, _ := Home()
@@ -68,6 +68,16 @@ type Config struct {
 	GrctlImage             string
 	RbdHub                 string
 	RbdWorker              string
+	EnableAPIToken         bool
+	DocsBasicAuth          string
+	OIDCIssuerURL          string
+	OIDCAudiences          []string
+	OIDCUsernameClaim      string
+	OIDCTenantsClaim       string
+	OIDCRolesClaim         string
+	OIDCRoleScopes         map[string]string
+	EnableAuditLog         bool
+	AuditSyslogAddr        string
 }
 
 // APIServer  apiserver server
@@ -126,6 +136,16 @@ func (a *APIServer) AddFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVar(&a.NodeAPI, "node-api", []string{"rbd-node:6100"}, "the rbd-node server api")
 	fs.StringVar(&a.MQAPI, "mq-api", "rbd-mq:6300", "the rbd-mq server api")
 	fs.StringVar(&a.RbdWorker, "worker-api", "rbd-worker:6535", "the rbd-worker server api")
+	fs.BoolVar(&a.EnableAPIToken, "enable-api-token", false, "whether to authenticate the requests with the issued api tokens even if TOKEN is not set")
+	fs.StringVar(&a.DocsBasicAuth, "docs-basic-auth", "", "the user:password pair of the api docs, any valid api token is accepted as password if not set")
+	fs.StringVar(&a.OIDCIssuerURL, "oidc-issuer-url", "", "the oidc issuer, the id tokens issued by it are accepted if set")
+	fs.StringSliceVar(&a.OIDCAudiences, "oidc-audience", nil, "the accepted audiences of the oidc id tokens")
+	fs.StringVar(&a.OIDCUsernameClaim, "oidc-username-claim", "preferred_username", "the claim used as the operator of the events")
+	fs.StringVar(&a.OIDCTenantsClaim, "oidc-tenants-claim", "tenants", "the claim lists the tenant names the user can access, * means all tenants")
+	fs.StringVar(&a.OIDCRolesClaim, "oidc-roles-claim", "roles", "the claim lists the roles of the user, such as realm_access.roles")
+	fs.StringToStringVar(&a.OIDCRoleScopes, "oidc-role-scopes", nil, "maps the roles to the read-only, deploy or admin scope, such as rainbond-admin=admin,developer=deploy")
+	fs.BoolVar(&a.EnableAuditLog, "enable-audit-log", true, "whether to record every mutating request in the audit log")
+	fs.StringVar(&a.AuditSyslogAddr, "audit-syslog-addr", "", "forward the audit logs to the syslog server, such as udp://127.0.0.1:514")
 	fs.StringSliceVar(&a.EventLogServers, "event-servers", []string{"rbd-eventlog:6366"}, "event log server address")
 	fs.StringSliceVar(&a.EventLogEndpoints, "event-log", []string{"local=>rbd-eventlog:6363"}, "event log websocket address")
 
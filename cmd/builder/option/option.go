@@ -63,6 +63,11 @@ type Config struct {
 	KeepCount            int
 	CleanInterval        int
 	BRVersion            string
+	// the customer managed keys used to encrypt the backups and the exported packages
+	EncryptionMasterKeyFile     string
+	EncryptionPGPRecipientFile  string
+	EncryptionPGPPrivateKeyFile string
+	EncryptionPGPPassphraseFile string
 }
 
 // Builder  builder server
@@ -111,6 +116,10 @@ func (a *Builder) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&a.KeepCount, "keep-count", 5, "default number of reserved copies for images")
 	fs.IntVar(&a.CleanInterval, "clean-interval", 60, "clean image interval,default 60 minute")
 	fs.StringVar(&a.BRVersion, "br-version", "v5.16.0-release", "builder and runner version")
+	fs.StringVar(&a.EncryptionMasterKeyFile, "encryption-master-key-file", "", "the file of the 32 bytes master key which wraps the data keys of the encrypted backups and exports, raw, hex or base64 encoded")
+	fs.StringVar(&a.EncryptionPGPRecipientFile, "encryption-pgp-recipient-file", "", "the PGP public keys the data keys of the encrypted backups and exports are encrypted to")
+	fs.StringVar(&a.EncryptionPGPPrivateKeyFile, "encryption-pgp-private-key-file", "", "the PGP private keys used to decrypt the encrypted backups and packages")
+	fs.StringVar(&a.EncryptionPGPPassphraseFile, "encryption-pgp-passphrase-file", "", "the passphrase of the PGP private keys")
 
 	fs.StringSliceVar(&a.EventLogServers, "event-servers", []string{"rbd-eventlog:6366"}, "event log server address. simple lb")
 	fs.StringVar(&a.MQAPI, "mq-api", "rbd-mq:6300", "acp_mq api")
@@ -77,6 +77,8 @@ type Config struct {
 	ShareMemory       uint64
 	SyncRateLimit     float32
 	EnableSSLStapling bool
+	// ActivatorTimeout the time limit the activator holds the requests of a backend scaled to zero
+	ActivatorTimeout time.Duration
 }
 
 // ListenPorts describe the ports required to run the gateway controller
@@ -86,6 +88,8 @@ type ListenPorts struct {
 	Status int
 	Stream int
 	Health int
+	// Activator holds the requests of the backends scaled to zero
+	Activator int
 }
 
 // AddFlags adds flags
@@ -95,6 +99,8 @@ func (g *GWServer) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&g.ListenPorts.Status, "status-port", 18080, `Port to use for the lua HTTP endpoint configuration.`)
 	fs.IntVar(&g.ListenPorts.Stream, "stream-port", 18081, `Port to use for the lua TCP/UDP endpoint configuration.`)
 	fs.IntVar(&g.ListenPorts.Health, "healthz-port", 10254, `Port to use for the healthz endpoint.`)
+	fs.IntVar(&g.ListenPorts.Activator, "activator-port", 18082, `Port to use for the activator which holds the requests of the components scaled to zero.`)
+	fs.DurationVar(&g.ActivatorTimeout, "activator-timeout", 55*time.Second, "Time limit to hold the requests of the components scaled to zero, it should be less than the proxy read timeout.")
 	fs.IntVar(&g.ListenPorts.HTTP, "service-http-port", 80, `Port to use for the http service rule`)
 	fs.IntVar(&g.ListenPorts.HTTPS, "service-https-port", 443, `Port to use for the https service rule`)
 	fs.IntVar(&g.WorkerProcesses, "worker-processes", 0, "Default get current compute cpu core number.This number should be, at maximum, the number of CPU cores on your system.")
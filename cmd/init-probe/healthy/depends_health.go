@@ -27,10 +27,15 @@ import (
 	"strings"
 	"time"
 
-	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
-	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
-	endpointapi "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
-	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointapi "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	clusterservice "github.com/envoyproxy/go-control-plane/envoy/service/cluster/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	endpointservice "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
+	rsrc "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 	"github.com/goodrain/rainbond/util"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -42,14 +47,14 @@ import (
 //------- cds: discover all dependent services
 //------- sds: every service has at least one Ready instance
 type DependServiceHealthController struct {
-	listeners                       []v2.Listener
-	clusters                        []v2.Cluster
-	sdsHost                         []v2.ClusterLoadAssignment
+	listeners                       []*listenerv3.Listener
+	clusters                        []*clusterv3.Cluster
+	sdsHost                         []*endpointapi.ClusterLoadAssignment
 	interval                        time.Duration
-	envoyDiscoverVersion            string //only support v2
+	envoyDiscoverVersion            string //only support v3
 	checkFunc                       []func() bool
-	endpointClient                  v2.EndpointDiscoveryServiceClient
-	clusterClient                   v2.ClusterDiscoveryServiceClient
+	endpointClient                  endpointservice.EndpointDiscoveryServiceClient
+	clusterClient                   clusterservice.ClusterDiscoveryServiceClient
 	dependServiceCount              int
 	clusterID                       string
 	dependServiceNames              []string
@@ -86,8 +91,8 @@ func NewDependServiceHealthController() (*DependServiceHealthController, error)
 	if err != nil {
 		return nil, err
 	}
-	dsc.endpointClient = v2.NewEndpointDiscoveryServiceClient(cli)
-	dsc.clusterClient = v2.NewClusterDiscoveryServiceClient(cli)
+	dsc.endpointClient = endpointservice.NewEndpointDiscoveryServiceClient(cli)
+	dsc.clusterClient = clusterservice.NewClusterDiscoveryServiceClient(cli)
 	dsc.dependServiceNames = strings.Split(os.Getenv("STARTUP_SEQUENCE_DEPENDENCIES"), ",")
 	return &dsc, nil
 }
@@ -137,7 +142,8 @@ func (d *DependServiceHealthController) checkListener() bool {
 func (d *DependServiceHealthController) checkClusters() bool {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	res, err := d.clusterClient.FetchClusters(ctx, &v2.DiscoveryRequest{
+	res, err := d.clusterClient.FetchClusters(ctx, &discovery.DiscoveryRequest{
+		TypeUrl: rsrc.ClusterType,
 		Node: &core.Node{
 			Cluster: d.clusterID,
 			Id:      d.clusterID,
@@ -147,10 +153,10 @@ func (d *DependServiceHealthController) checkClusters() bool {
 		logrus.Errorf("discover depend services cluster failure %s", err.Error())
 		return false
 	}
-	clusters := envoyv2.ParseClustersResource(res.Resources)
+	clusters := envoyv3.ParseClustersResource(res.Resources)
 	d.ignoreCheckEndpointsClusterName = nil
 	for _, cluster := range clusters {
-		if cluster.GetType() == v2.Cluster_LOGICAL_DNS {
+		if cluster.GetType() == clusterv3.Cluster_LOGICAL_DNS {
 			d.ignoreCheckEndpointsClusterName = append(d.ignoreCheckEndpointsClusterName, cluster.Name)
 		}
 	}
@@ -190,7 +196,8 @@ func (d *DependServiceHealthController) checkEDS() bool {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	res, err := d.endpointClient.FetchEndpoints(ctx, &v2.DiscoveryRequest{
+	res, err := d.endpointClient.FetchEndpoints(ctx, &discovery.DiscoveryRequest{
+		TypeUrl: rsrc.EndpointType,
 		Node: &core.Node{
 			Cluster: d.clusterID,
 			Id:      d.clusterID,
@@ -200,7 +207,7 @@ func (d *DependServiceHealthController) checkEDS() bool {
 		logrus.Errorf("discover depend services endpoint failure %s", err.Error())
 		return false
 	}
-	clusterLoadAssignments := envoyv2.ParseLocalityLbEndpointsResource(res.Resources)
+	clusterLoadAssignments := envoyv3.ParseLocalityLbEndpointsResource(res.Resources)
 	readyClusters := make(map[string]bool, len(clusterLoadAssignments))
 	for _, cla := range clusterLoadAssignments {
 		// clusterName := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, destServiceAlias, service.Spec.Ports[0].Port)
@@ -25,13 +25,16 @@ import (
 
 	yaml "gopkg.in/yaml.v2"
 
-	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 
-	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	clusterservice "github.com/envoyproxy/go-control-plane/envoy/service/cluster/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	endpointservice "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
+	listenerservice "github.com/envoyproxy/go-control-plane/envoy/service/listener/v3"
 
 	"google.golang.org/grpc"
-
-	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 )
 
 var testClusterID = "8cd9214e6b3d4476942b600f41bfefea_tcpmeshd3d6a722b632b854b6c232e4895e0cc6_gr5e0cc6"
@@ -46,10 +49,10 @@ func TestClientListener(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	listenerDiscover := v2.NewListenerDiscoveryServiceClient(cli)
+	listenerDiscover := listenerservice.NewListenerDiscoveryServiceClient(cli)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	res, err := listenerDiscover.FetchListeners(ctx, &v2.DiscoveryRequest{
+	res, err := listenerDiscover.FetchListeners(ctx, &discovery.DiscoveryRequest{
 		Node: &core.Node{
 			Cluster: testClusterID,
 			Id:      testClusterID,
@@ -62,7 +65,7 @@ func TestClientListener(t *testing.T) {
 		t.Fatal("no listeners")
 	}
 	t.Logf("version %s", res.GetVersionInfo())
-	listeners := envoyv2.ParseListenerResource(res.Resources)
+	listeners := envoyv3.ParseListenerResource(res.Resources)
 	printYaml(t, listeners)
 }
 
@@ -71,10 +74,10 @@ func TestClientCluster(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	clusterDiscover := v2.NewClusterDiscoveryServiceClient(cli)
+	clusterDiscover := clusterservice.NewClusterDiscoveryServiceClient(cli)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	res, err := clusterDiscover.FetchClusters(ctx, &v2.DiscoveryRequest{
+	res, err := clusterDiscover.FetchClusters(ctx, &discovery.DiscoveryRequest{
 		Node: &core.Node{
 			Cluster: testClusterID,
 			Id:      testClusterID,
@@ -87,9 +90,9 @@ func TestClientCluster(t *testing.T) {
 		t.Fatal("no clusters")
 	}
 	t.Logf("version %s", res.GetVersionInfo())
-	clusters := envoyv2.ParseClustersResource(res.Resources)
+	clusters := envoyv3.ParseClustersResource(res.Resources)
 	for _, cluster := range clusters {
-		if cluster.GetType() == v2.Cluster_LOGICAL_DNS {
+		if cluster.GetType() == clusterv3.Cluster_LOGICAL_DNS {
 			fmt.Println(cluster.Name)
 		}
 		printYaml(t, cluster)
@@ -106,10 +109,10 @@ func TestClientEndpoint(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	endpointDiscover := v2.NewEndpointDiscoveryServiceClient(cli)
+	endpointDiscover := endpointservice.NewEndpointDiscoveryServiceClient(cli)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	res, err := endpointDiscover.FetchEndpoints(ctx, &v2.DiscoveryRequest{
+	res, err := endpointDiscover.FetchEndpoints(ctx, &discovery.DiscoveryRequest{
 		Node: &core.Node{
 			Cluster: testClusterID,
 			Id:      testClusterID,
@@ -122,7 +125,7 @@ func TestClientEndpoint(t *testing.T) {
 		t.Fatal("no endpoints")
 	}
 	t.Logf("version %s", res.GetVersionInfo())
-	endpoints := envoyv2.ParseLocalityLbEndpointsResource(res.Resources)
+	endpoints := envoyv3.ParseLocalityLbEndpointsResource(res.Resources)
 	for _, e := range endpoints {
 		fmt.Println(e.GetClusterName())
 	}
@@ -31,7 +31,7 @@ import (
 
 	api_model "github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/cmd"
-	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 	"github.com/goodrain/rainbond/util"
 	"github.com/sirupsen/logrus"
 )
@@ -159,7 +159,7 @@ func discoverConfig() *api_model.ResourceSpec {
 func getHosts(configs *api_model.ResourceSpec) map[string]string {
 	hosts := make(map[string]string)
 	for _, service := range configs.BaseServices {
-		options := envoyv2.GetOptionValues(service.Options)
+		options := envoyv3.GetOptionValues(service.Options)
 		for _, domain := range options.Domains {
 			if domain != "" && domain != "*" {
 				if strings.Contains(domain, ":") {
@@ -19,9 +19,12 @@
 package main
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -32,11 +35,17 @@ import (
 
 	"github.com/goodrain/rainbond/cmd/monitor/option"
 	"github.com/goodrain/rainbond/monitor"
+	"github.com/goodrain/rainbond/monitor/adapter"
 	"github.com/goodrain/rainbond/monitor/api"
 	"github.com/goodrain/rainbond/monitor/api/controller"
 	"github.com/goodrain/rainbond/monitor/prometheus"
+	k8sutil "github.com/goodrain/rainbond/util/k8s"
+	promapi "github.com/prometheus/client_golang/api"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
 func main() {
@@ -72,6 +81,10 @@ func main() {
 	m.Start()
 	defer m.Stop()
 
+	if c.MetricsAdapterListen != "" {
+		startMetricsAdapter(c)
+	}
+
 	r := api.Server(controllerManager)
 	logrus.Info("monitor api listen port 3329")
 	go http.ListenAndServe(":3329", r)
@@ -117,3 +130,46 @@ func monitorKSM(c *option.Config, p *prometheus.Manager) {
 		)
 	}
 }
+
+// startMetricsAdapter serves the custom and external metrics apis backed by the local prometheus
+func startMetricsAdapter(c *option.Config) {
+	restConfig, err := k8sutil.NewRestConfig(c.KubeConfig)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	_, port, err := net.SplitHostPort(c.Web.ListenAddress)
+	if err != nil {
+		logrus.Fatalf("parse prometheus listen address: %v", err)
+	}
+	client, err := promapi.NewClient(promapi.Config{Address: "http://127.0.0.1:" + port})
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	a := adapter.NewAdapter(apiv1.NewAPI(client), clientset)
+
+	if c.MetricsAdapterService != "" {
+		info := strings.SplitN(c.MetricsAdapterService, "/", 2)
+		_, adapterPort, err := net.SplitHostPort(c.MetricsAdapterListen)
+		if len(info) != 2 || err != nil {
+			logrus.Fatalf("invalid metrics adapter service %s or listen address %s", c.MetricsAdapterService, c.MetricsAdapterListen)
+		}
+		servicePort, _ := strconv.Atoi(adapterPort)
+		dynamicClient, err := dynamic.NewForConfig(restConfig)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		if err := adapter.RegisterAPIServices(context.Background(), dynamicClient, info[0], info[1], int32(servicePort)); err != nil {
+			logrus.Errorf("register metrics adapter api services: %v", err)
+		}
+	}
+
+	go func() {
+		if err := a.ListenAndServeTLS(c.MetricsAdapterListen); err != nil {
+			logrus.Errorf("metrics adapter stopped: %v", err)
+		}
+	}()
+}
@@ -43,6 +43,7 @@ type Config struct {
 	StartArgs            []string
 	ConfigFile           string
 	AlertingRulesFile    string
+	CustomRulesFile      string
 	AlertManagerURL      []string
 	LocalStoragePath     string
 	Web                  Web
@@ -58,6 +59,10 @@ type Config struct {
 	MysqldExporter       string
 	KSMExporter          string
 	KubeConfig           string
+	// MetricsAdapterListen the address of the custom metrics adapter, empty disables the adapter
+	MetricsAdapterListen string
+	// MetricsAdapterService namespace/name of the service the adapter is registered as api service with
+	MetricsAdapterService string
 }
 
 // Web Options for the web Handler.
@@ -108,6 +113,7 @@ func NewConfig() *Config {
 		KubeConfig:           "",
 		ConfigFile:           "/etc/prometheus/prometheus.yml",
 		AlertingRulesFile:    "/etc/prometheus/rules.yml",
+		CustomRulesFile:      "/etc/prometheus/rules/rainbond-custom.yml",
 		AlertManagerURL:      []string{},
 		LocalStoragePath:     "/prometheusdata",
 		WebTimeout:           "5m",
@@ -146,6 +152,8 @@ func (c *Config) AddFlag(cmd *pflag.FlagSet) {
 	cmd.StringVar(&c.MysqldExporter, "mysqld-exporter", c.MysqldExporter, "mysqld exporter address. eg: 127.0.0.1:9104")
 	cmd.StringVar(&c.KSMExporter, "kube-state-metrics", c.KSMExporter, "kube-state-metrics, current server's kube-state-metrics address")
 	cmd.StringVar(&c.KubeConfig, "kube-config", "", "kubernetes api server config file")
+	cmd.StringVar(&c.MetricsAdapterListen, "metrics-adapter-listen", "", "The https address the custom and external metrics adapter listens on, eg: :3330, empty disables the adapter.")
+	cmd.StringVar(&c.MetricsAdapterService, "metrics-adapter-service", "", "The namespace/name of the service in front of the metrics adapter, if set the adapter registers itself as custom.metrics.k8s.io and external.metrics.k8s.io api services.")
 }
 
 // AddPrometheusFlag prometheus flag
@@ -154,6 +162,8 @@ func (c *Config) AddPrometheusFlag(cmd *pflag.FlagSet) {
 
 	cmd.StringVar(&c.AlertingRulesFile, "rules-config.file", c.AlertingRulesFile, "Prometheus alerting rules config file path.")
 
+	cmd.StringVar(&c.CustomRulesFile, "custom-rules-config.file", c.CustomRulesFile, "The file user defined alerting rules are rendered into, it must match /etc/prometheus/rules/*.yml.")
+
 	cmd.StringVar(&c.Web.ListenAddress, "web.listen-address", c.Web.ListenAddress, "Address to listen on for UI, API, and telemetry.")
 
 	cmd.StringVar(&c.WebTimeout, "web.read-timeout", c.WebTimeout, "Maximum duration before timing out read of the request, and closing idle connections.")
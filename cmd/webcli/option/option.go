@@ -38,6 +38,11 @@ type Config struct {
 	SessionKey           string
 	PrometheusMetricPath string
 	K8SConfPath          string
+	OIDCIssuerURL        string
+	OIDCAudiences        []string
+	OIDCTenantsClaim     string
+	OIDCRolesClaim       string
+	OIDCExecRoles        []string
 }
 
 // WebCliServer container webcli server
@@ -64,6 +69,11 @@ func (a *WebCliServer) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.K8SConfPath, "kube-conf", "", "absolute path to the kubeconfig file")
 	fs.IntVar(&a.Port, "port", 7171, "server listen port")
 	fs.StringVar(&a.PrometheusMetricPath, "metric", "/metrics", "prometheus metrics path")
+	fs.StringVar(&a.OIDCIssuerURL, "oidc-issuer-url", "", "the oidc issuer, the connections must carry an id token issued by it if set")
+	fs.StringSliceVar(&a.OIDCAudiences, "oidc-audience", nil, "the accepted audiences of the oidc id tokens")
+	fs.StringVar(&a.OIDCTenantsClaim, "oidc-tenants-claim", "tenants", "the claim lists the tenants the user can access, * means all tenants")
+	fs.StringVar(&a.OIDCRolesClaim, "oidc-roles-claim", "roles", "the claim lists the roles of the user, such as realm_access.roles")
+	fs.StringSliceVar(&a.OIDCExecRoles, "oidc-exec-roles", nil, "the roles allowed to exec into the containers, all users of the tenant are allowed if empty")
 }
 
 // SetLog 设置log
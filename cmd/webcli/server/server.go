@@ -29,6 +29,7 @@ import (
 	"github.com/goodrain/rainbond/webcli/app"
 
 	etcdutil "github.com/goodrain/rainbond/util/etcd"
+	"github.com/goodrain/rainbond/util/oidc"
 	"github.com/sirupsen/logrus"
 )
 
@@ -40,6 +41,19 @@ func Run(s *option.WebCliServer) error {
 	option.Port = strconv.Itoa(s.Port)
 	option.SessionKey = s.SessionKey
 	option.K8SConfPath = s.K8SConfPath
+	if s.OIDCIssuerURL != "" {
+		verifier, err := oidc.NewVerifier(oidc.Config{
+			IssuerURL:    s.OIDCIssuerURL,
+			Audiences:    s.OIDCAudiences,
+			TenantsClaim: s.OIDCTenantsClaim,
+			RolesClaim:   s.OIDCRolesClaim,
+		})
+		if err != nil {
+			return err
+		}
+		option.Verifier = verifier
+		option.ExecRoles = s.OIDCExecRoles
+	}
 	ap, err := app.New(&option)
 	if err != nil {
 		return err
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
@@ -46,6 +47,8 @@ type Config struct {
 	KubeAPIBurst            int
 	MaxTasks                int
 	MQAPI                   string
+	PrometheusAPI           string
+	RecommendationWindow    time.Duration
 	NodeName                string
 	Listen                  string
 	HostIP                  string
@@ -55,6 +58,11 @@ type Config struct {
 	LeaderElectionNamespace string
 	LeaderElectionIdentity  string
 	RBDNamespace            string
+	NetworkPolicyAllowCIDRs []string
+	SecretStoreAddr         string
+	SecretStoreToken        string
+	SecretStoreMount        string
+	SecretRefreshInterval   time.Duration
 	GrdataPVCName           string
 	Helm                    Helm
 }
@@ -102,12 +110,19 @@ func (a *Worker) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&a.LeaderElectionNamespace, "leader-election-namespace", "rainbond", "Namespace where this attacher runs.")
 	fs.StringVar(&a.LeaderElectionIdentity, "leader-election-identity", "", "Unique idenity of this attcher. Typically name of the pod where the attacher runs.")
 	fs.StringVar(&a.RBDNamespace, "rbd-system-namespace", "rbd-system", "rbd components kubernetes namespace")
+	fs.StringSliceVar(&a.NetworkPolicyAllowCIDRs, "network-policy-allow-cidrs", nil, "the cidrs allowed to access the isolated components, such as the nodes of the gateway running in the host network, defaults to the internal addresses of all the nodes")
+	fs.StringVar(&a.SecretStoreAddr, "secret-store-addr", "", "the address of the vault compatible external secret store the env vars and config files can reference, such as http://vault:8200")
+	fs.StringVar(&a.SecretStoreToken, "secret-store-token", os.Getenv("SECRET_STORE_TOKEN"), "the token of the external secret store, defaults to the env SECRET_STORE_TOKEN")
+	fs.StringVar(&a.SecretStoreMount, "secret-store-mount", "secret", "the mount path of the kv version 2 secrets engine of the external secret store")
+	fs.DurationVar(&a.SecretRefreshInterval, "secret-refresh-interval", time.Minute, "the interval the latest versions of the external secrets are refreshed at, 0 disables the refresh")
 	fs.StringVar(&a.GrdataPVCName, "grdata-pvc-name", "rbd-cpt-grdata", "The name of grdata persistent volume claim")
 	fs.StringVar(&a.Helm.DataDir, "/grdata/helm", "/grdata/helm", "The data directory of Helm.")
 	fs.StringVar(&a.SharedStorageClass, "shared-storageclass", "", "custom shared storage class.use the specified storageclass to create shared storage, if this parameter is not specified, it will use rainbondsssc by default")
 
 	fs.StringSliceVar(&a.EtcdEndPoints, "etcd-endpoints", []string{"http://rbd-etcd:2379"}, "etcd v3 cluster endpoints.")
 	fs.StringVar(&a.MQAPI, "mq-api", "rbd-mq:6300", "acp_mq api")
+	fs.StringVar(&a.PrometheusAPI, "prometheus-api", "http://rbd-monitor:9999", "prometheus api, used to find the idle components to scale to zero and recommend resources")
+	fs.DurationVar(&a.RecommendationWindow, "recommendation-window", 7*24*time.Hour, "the range of the historical usage the resource recommendations are computed from")
 	fs.StringSliceVar(&a.EventLogServers, "event-servers", []string{"rbd-eventlog:6366"}, "event log server address. simple lb")
 
 	a.Helm.RepoFile = path.Join(a.Helm.DataDir, "repo/repositories.yaml")
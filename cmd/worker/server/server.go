@@ -35,6 +35,7 @@ import (
 	k8sutil "github.com/goodrain/rainbond/util/k8s"
 	"github.com/goodrain/rainbond/worker/appm/componentdefinition"
 	"github.com/goodrain/rainbond/worker/appm/controller"
+	"github.com/goodrain/rainbond/worker/appm/secretstore"
 	"github.com/goodrain/rainbond/worker/appm/store"
 	"github.com/goodrain/rainbond/worker/discover"
 	"github.com/goodrain/rainbond/worker/gc"
@@ -96,6 +97,9 @@ func Run(s *option.Worker) error {
 	rainbondClient := versioned.NewForConfigOrDie(restConfig)
 	//step 3: create componentdefinition builder factory
 	componentdefinition.NewComponentDefinitionBuilder(s.Config.RBDNamespace)
+	if s.Config.SecretStoreAddr != "" {
+		secretstore.SetDefault(secretstore.NewVault(s.Config.SecretStoreAddr, s.Config.SecretStoreToken, s.Config.SecretStoreMount))
+	}
 
 	//step 4: create component resource store
 	updateCh := channels.NewRingChannel(1024)
@@ -47,6 +47,20 @@ type EnterpriseDao interface {
 	GetEnterpriseTenants(enterpriseID string) ([]*model.Tenants, error)
 }
 
+// TenantQuotaDao -
+type TenantQuotaDao interface {
+	Dao
+	GetByTenantID(tenantID string) (*model.TenantQuota, error)
+	DeleteByTenantID(tenantID string) error
+}
+
+// TenantStorageClassQuotaDao -
+type TenantStorageClassQuotaDao interface {
+	Dao
+	ListByTenantID(tenantID string) ([]*model.TenantStorageClassQuota, error)
+	DeleteByTenantID(tenantID string) error
+}
+
 // TenantDao tenant dao
 type TenantDao interface {
 	Dao
@@ -76,6 +90,7 @@ type ApplicationDao interface {
 	DeleteApp(appID string) error
 	GetByServiceID(sid string) (*model.Application, error)
 	ListByAppIDs(appIDs []string) ([]*model.Application, error)
+	ListByNetworkIsolation(mode string) ([]*model.Application, error)
 	IsK8sAppDuplicate(tenantID, AppID, k8sApp string) bool
 	GetAppByName(tenantID, k8sAppName string) (*model.Application, error)
 	DeleteAppByK8sApp(tenantID, k8sAppName string) error
@@ -277,6 +292,7 @@ type TenantServiceRelationDao interface {
 	GetTenantServiceRelations(serviceID string) ([]*model.TenantServiceRelation, error)
 	ListByServiceIDs(serviceIDs []string) ([]*model.TenantServiceRelation, error)
 	GetTenantServiceRelationsByDependServiceID(dependServiceID string) ([]*model.TenantServiceRelation, error)
+	ListByDependServiceIDs(dependServiceIDs []string) ([]*model.TenantServiceRelation, error)
 	HaveRelations(serviceID string) bool
 	DELRelationsByServiceID(serviceID string) error
 	DeleteRelationByDepID(serviceID, depID string) error
@@ -473,6 +489,31 @@ type RegionUserInfoDao interface {
 	GetTokenByTokenID(token string) (*model.RegionUserInfo, error)
 }
 
+// APITokenDao -
+type APITokenDao interface {
+	Dao
+	GetByTokenID(tokenID string) (*model.APIToken, error)
+	GetByTokenHash(hash string) (*model.APIToken, error)
+	ListByTenantName(tenantName string) ([]*model.APIToken, error)
+}
+
+// AuditLogQuery filters the audit logs, the zero values are ignored
+type AuditLogQuery struct {
+	Start      time.Time
+	End        time.Time
+	Actor      string
+	TenantName string
+}
+
+// AuditLogDao -
+type AuditLogDao interface {
+	Dao
+	// GetLastAuditLog locks the last record until the transaction ends
+	GetLastAuditLog() (*model.AuditLog, error)
+	ListAuditLogs(query *AuditLogQuery, page, pageSize int) ([]*model.AuditLog, int64, error)
+	ListAuditLogsAfterID(query *AuditLogQuery, id uint, limit int) ([]*model.AuditLog, error)
+}
+
 // RegionAPIClassDao RegionAPIClassDao
 type RegionAPIClassDao interface {
 	Dao
@@ -500,10 +541,21 @@ type AppBackupDao interface {
 	GetDeleteAppBackups() ([]*model.AppBackup, error)
 }
 
+// AppMigrationDao -
+type AppMigrationDao interface {
+	Dao
+	GetByMigrationID(migrationID string) (*model.AppMigration, error)
+	ListByGroupID(groupID string) ([]*model.AppMigration, error)
+}
+
 // ServiceSourceDao service source dao
 type ServiceSourceDao interface {
 	Dao
 	GetServiceSource(serviceID string) ([]*model.ServiceSourceConfig, error)
+	GetServiceSourceByType(serviceID, sourceType string) (*model.ServiceSourceConfig, error)
+	ListBySourceType(sourceType string) ([]*model.ServiceSourceConfig, error)
+	DeleteByServiceIDAndType(serviceID, sourceType string) error
+	UpdateSourceBodyIfUnchanged(id uint, oldBody, newBody string) (bool, error)
 }
 
 // CertificateDao -
@@ -622,6 +674,79 @@ type TenantServiceScalingRecordsDao interface {
 	CountByServiceID(serviceID string) (int, error)
 }
 
+// TenantServiceScalingScheduleDao -
+type TenantServiceScalingScheduleDao interface {
+	Dao
+	GetByScheduleID(scheduleID string) (*model.TenantServiceScalingSchedule, error)
+	ListByServiceID(serviceID string) ([]*model.TenantServiceScalingSchedule, error)
+	ListEnable() ([]*model.TenantServiceScalingSchedule, error)
+	DeleteByScheduleID(scheduleID string) error
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantServiceScaleToZeroDao -
+type TenantServiceScaleToZeroDao interface {
+	Dao
+	GetByServiceID(serviceID string) (*model.TenantServiceScaleToZero, error)
+	ListEnable() ([]*model.TenantServiceScaleToZero, error)
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantServiceResourceRecommendationDao -
+type TenantServiceResourceRecommendationDao interface {
+	Dao
+	GetByServiceID(serviceID string) (*model.TenantServiceResourceRecommendation, error)
+	ListByTenantID(tenantID string) ([]*model.TenantServiceResourceRecommendation, error)
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantServiceRecommendationPolicyDao -
+type TenantServiceRecommendationPolicyDao interface {
+	Dao
+	GetByServiceID(serviceID string) (*model.TenantServiceRecommendationPolicy, error)
+	ListAutoApply() ([]*model.TenantServiceRecommendationPolicy, error)
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantServiceVolumeSnapshotDao -
+type TenantServiceVolumeSnapshotDao interface {
+	Dao
+	GetBySnapshotID(snapshotID string) (*model.TenantServiceVolumeSnapshot, error)
+	ListByServiceID(serviceID, volumeName string) ([]*model.TenantServiceVolumeSnapshot, error)
+	ListByPolicyID(policyID, volumeName string) ([]*model.TenantServiceVolumeSnapshot, error)
+	ListByStatus(status ...string) ([]*model.TenantServiceVolumeSnapshot, error)
+	DeleteBySnapshotID(snapshotID string) error
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantServiceSnapshotPolicyDao -
+type TenantServiceSnapshotPolicyDao interface {
+	Dao
+	GetByPolicyID(policyID string) (*model.TenantServiceSnapshotPolicy, error)
+	ListByServiceID(serviceID string) ([]*model.TenantServiceSnapshotPolicy, error)
+	ListEnable() ([]*model.TenantServiceSnapshotPolicy, error)
+	DeleteByPolicyID(policyID string) error
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantServiceBuildPipelineDao -
+type TenantServiceBuildPipelineDao interface {
+	Dao
+	GetByServiceID(serviceID string) (*model.TenantServiceBuildPipeline, error)
+	DeleteByServiceID(serviceID string) error
+}
+
+// TenantServicePipelineRunDao -
+type TenantServicePipelineRunDao interface {
+	Dao
+	GetByEventID(eventID string) (*model.TenantServicePipelineRun, error)
+	ListByServiceID(serviceID string, limit int) ([]*model.TenantServicePipelineRun, error)
+	ListByServiceIDAndStatus(serviceID, status string) ([]*model.TenantServicePipelineRun, error)
+	GetLatestByDeployVersion(serviceID, deployVersion string) (*model.TenantServicePipelineRun, error)
+	UpdateStatusIfMatch(eventID, oldStatus, newStatus string) (bool, error)
+	DeleteByServiceID(serviceID string) error
+}
+
 // TenantServiceMonitorDao -
 type TenantServiceMonitorDao interface {
 	Dao
@@ -651,3 +776,29 @@ type K8sResourceDao interface {
 	DeleteK8sResource(appID, name string, kind string) error
 	GetK8sResourceByName(appID, name, kind string) (model.K8sResource, error)
 }
+
+// TenantAlertRuleDao -
+type TenantAlertRuleDao interface {
+	Dao
+	GetByRuleID(ruleID string) (*model.TenantAlertRule, error)
+	ListByTenantID(tenantID string) ([]*model.TenantAlertRule, error)
+	ListByServiceID(serviceID string) ([]*model.TenantAlertRule, error)
+	DeleteByRuleID(ruleID string) error
+}
+
+// TenantAlertChannelDao -
+type TenantAlertChannelDao interface {
+	Dao
+	GetByChannelID(channelID string) (*model.TenantAlertChannel, error)
+	ListByTenantID(tenantID string) ([]*model.TenantAlertChannel, error)
+	ListByChannelIDs(channelIDs []string) ([]*model.TenantAlertChannel, error)
+	DeleteByChannelID(channelID string) error
+}
+
+// TenantAlertSilenceDao -
+type TenantAlertSilenceDao interface {
+	Dao
+	ListByTenantID(tenantID string) ([]*model.TenantAlertSilence, error)
+	ListActiveByRuleID(ruleID string, now time.Time) ([]*model.TenantAlertSilence, error)
+	DeleteBySilenceID(silenceID string) error
+}
@@ -4453,6 +4453,57 @@ func (mr *MockServiceSourceDaoMockRecorder) GetServiceSource(serviceID interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceSource", reflect.TypeOf((*MockServiceSourceDao)(nil).GetServiceSource), serviceID)
 }
 
+// GetServiceSourceByType mocks base method
+func (m *MockServiceSourceDao) GetServiceSourceByType(serviceID, sourceType string) (*model.ServiceSourceConfig, error) {
+	ret := m.ctrl.Call(m, "GetServiceSourceByType", serviceID, sourceType)
+	ret0, _ := ret[0].(*model.ServiceSourceConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceSourceByType indicates an expected call of GetServiceSourceByType
+func (mr *MockServiceSourceDaoMockRecorder) GetServiceSourceByType(serviceID, sourceType interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceSourceByType", reflect.TypeOf((*MockServiceSourceDao)(nil).GetServiceSourceByType), serviceID, sourceType)
+}
+
+// ListBySourceType mocks base method
+func (m *MockServiceSourceDao) ListBySourceType(sourceType string) ([]*model.ServiceSourceConfig, error) {
+	ret := m.ctrl.Call(m, "ListBySourceType", sourceType)
+	ret0, _ := ret[0].([]*model.ServiceSourceConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySourceType indicates an expected call of ListBySourceType
+func (mr *MockServiceSourceDaoMockRecorder) ListBySourceType(sourceType interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySourceType", reflect.TypeOf((*MockServiceSourceDao)(nil).ListBySourceType), sourceType)
+}
+
+// DeleteByServiceIDAndType mocks base method
+func (m *MockServiceSourceDao) DeleteByServiceIDAndType(serviceID, sourceType string) error {
+	ret := m.ctrl.Call(m, "DeleteByServiceIDAndType", serviceID, sourceType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByServiceIDAndType indicates an expected call of DeleteByServiceIDAndType
+func (mr *MockServiceSourceDaoMockRecorder) DeleteByServiceIDAndType(serviceID, sourceType interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByServiceIDAndType", reflect.TypeOf((*MockServiceSourceDao)(nil).DeleteByServiceIDAndType), serviceID, sourceType)
+}
+
+// UpdateSourceBodyIfUnchanged mocks base method
+func (m *MockServiceSourceDao) UpdateSourceBodyIfUnchanged(id uint, oldBody, newBody string) (bool, error) {
+	ret := m.ctrl.Call(m, "UpdateSourceBodyIfUnchanged", id, oldBody, newBody)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSourceBodyIfUnchanged indicates an expected call of UpdateSourceBodyIfUnchanged
+func (mr *MockServiceSourceDaoMockRecorder) UpdateSourceBodyIfUnchanged(id, oldBody, newBody interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSourceBodyIfUnchanged", reflect.TypeOf((*MockServiceSourceDao)(nil).UpdateSourceBodyIfUnchanged), id, oldBody, newBody)
+}
+
 // MockCertificateDao is a mock of CertificateDao interface
 type MockCertificateDao struct {
 	ctrl     *gomock.Controller
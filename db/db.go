@@ -52,6 +52,10 @@ type Manager interface {
 	EnterpriseDao() dao.EnterpriseDao
 	TenantDao() dao.TenantDao
 	TenantDaoTransactions(db *gorm.DB) dao.TenantDao
+	TenantQuotaDao() dao.TenantQuotaDao
+	TenantQuotaDaoTransactions(db *gorm.DB) dao.TenantQuotaDao
+	TenantStorageClassQuotaDao() dao.TenantStorageClassQuotaDao
+	TenantStorageClassQuotaDaoTransactions(db *gorm.DB) dao.TenantStorageClassQuotaDao
 	TenantServiceDao() dao.TenantServiceDao
 	TenantServiceDeleteDao() dao.TenantServiceDeleteDao
 	TenantServiceDaoTransactions(db *gorm.DB) dao.TenantServiceDao
@@ -104,10 +108,15 @@ type Manager interface {
 
 	RegionAPIClassDao() dao.RegionAPIClassDao
 	RegionAPIClassDaoTransactions(db *gorm.DB) dao.RegionAPIClassDao
+	APITokenDao() dao.APITokenDao
+	AuditLogDao() dao.AuditLogDao
+	AuditLogDaoTransactions(db *gorm.DB) dao.AuditLogDao
 
 	NotificationEventDao() dao.NotificationEventDao
 	AppBackupDao() dao.AppBackupDao
 	AppBackupDaoTransactions(db *gorm.DB) dao.AppBackupDao
+	AppMigrationDao() dao.AppMigrationDao
+	AppMigrationDaoTransactions(db *gorm.DB) dao.AppMigrationDao
 	ServiceSourceDao() dao.ServiceSourceDao
 
 	// gateway
@@ -136,12 +145,35 @@ type Manager interface {
 	TenantServceAutoscalerRuleMetricsDaoTransactions(db *gorm.DB) dao.TenantServceAutoscalerRuleMetricsDao
 	TenantServiceScalingRecordsDao() dao.TenantServiceScalingRecordsDao
 	TenantServiceScalingRecordsDaoTransactions(db *gorm.DB) dao.TenantServiceScalingRecordsDao
+	TenantServiceScalingScheduleDao() dao.TenantServiceScalingScheduleDao
+	TenantServiceScalingScheduleDaoTransactions(db *gorm.DB) dao.TenantServiceScalingScheduleDao
+	TenantServiceScaleToZeroDao() dao.TenantServiceScaleToZeroDao
+	TenantServiceScaleToZeroDaoTransactions(db *gorm.DB) dao.TenantServiceScaleToZeroDao
+	TenantServiceResourceRecommendationDao() dao.TenantServiceResourceRecommendationDao
+	TenantServiceResourceRecommendationDaoTransactions(db *gorm.DB) dao.TenantServiceResourceRecommendationDao
+	TenantServiceRecommendationPolicyDao() dao.TenantServiceRecommendationPolicyDao
+	TenantServiceRecommendationPolicyDaoTransactions(db *gorm.DB) dao.TenantServiceRecommendationPolicyDao
+
+	TenantServiceVolumeSnapshotDao() dao.TenantServiceVolumeSnapshotDao
+	TenantServiceVolumeSnapshotDaoTransactions(db *gorm.DB) dao.TenantServiceVolumeSnapshotDao
+	TenantServiceSnapshotPolicyDao() dao.TenantServiceSnapshotPolicyDao
+	TenantServiceSnapshotPolicyDaoTransactions(db *gorm.DB) dao.TenantServiceSnapshotPolicyDao
+
+	TenantServiceBuildPipelineDao() dao.TenantServiceBuildPipelineDao
+	TenantServiceBuildPipelineDaoTransactions(db *gorm.DB) dao.TenantServiceBuildPipelineDao
+	TenantServicePipelineRunDao() dao.TenantServicePipelineRunDao
+	TenantServicePipelineRunDaoTransactions(db *gorm.DB) dao.TenantServicePipelineRunDao
 
 	TenantServiceMonitorDao() dao.TenantServiceMonitorDao
 	TenantServiceMonitorDaoTransactions(db *gorm.DB) dao.TenantServiceMonitorDao
 
 	ComponentK8sAttributeDao() dao.ComponentK8sAttributeDao
 	ComponentK8sAttributeDaoTransactions(db *gorm.DB) dao.ComponentK8sAttributeDao
+
+	// alerting
+	TenantAlertRuleDao() dao.TenantAlertRuleDao
+	TenantAlertChannelDao() dao.TenantAlertChannelDao
+	TenantAlertSilenceDao() dao.TenantAlertSilenceDao
 }
 
 var defaultManager Manager
@@ -0,0 +1,132 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "time"
+
+// AlertRuleScope the object an alert rule is bound to
+type AlertRuleScope string
+
+var (
+	// AlertRuleScopeComponent rule is evaluated for a single component
+	AlertRuleScopeComponent AlertRuleScope = "component"
+	// AlertRuleScopeApp rule is evaluated for every component of an application
+	AlertRuleScopeApp AlertRuleScope = "app"
+)
+
+// AlertMetricType the builtin metric a rule is evaluated against
+type AlertMetricType string
+
+var (
+	// AlertMetricCPU cpu usage of the component, in millicores
+	AlertMetricCPU AlertMetricType = "cpu"
+	// AlertMetricMemory memory working set of the component, in MB
+	AlertMetricMemory AlertMetricType = "memory"
+	// AlertMetricRestart container restarts of the component in the rule window
+	AlertMetricRestart AlertMetricType = "restart"
+	// AlertMetricHTTP5xx rate of 5xx responses served by the gateway, in requests per second
+	AlertMetricHTTP5xx AlertMetricType = "http_5xx"
+	// AlertMetricCustom a user defined promql expression, e.g. from a ServiceMonitor metric
+	AlertMetricCustom AlertMetricType = "custom"
+)
+
+// AlertChannelType the kind of a notification channel
+type AlertChannelType string
+
+var (
+	// AlertChannelWebhook generic json webhook
+	AlertChannelWebhook AlertChannelType = "webhook"
+	// AlertChannelEmail email via smtp
+	AlertChannelEmail AlertChannelType = "email"
+	// AlertChannelDingTalk dingtalk robot webhook
+	AlertChannelDingTalk AlertChannelType = "dingtalk"
+	// AlertChannelSlack slack incoming webhook
+	AlertChannelSlack AlertChannelType = "slack"
+)
+
+// NotificationEventKindAlert kind of the notification events recorded for user defined alerts
+const NotificationEventKindAlert = "alert"
+
+// TenantAlertRule user defined alert rule of a component or an application
+type TenantAlertRule struct {
+	Model
+	RuleID    string `gorm:"column:rule_id;size:32;unique_index" json:"rule_id"`
+	TenantID  string `gorm:"column:tenant_id;size:32;index" json:"tenant_id"`
+	Name      string `gorm:"column:name;size:64" json:"name"`
+	Scope     string `gorm:"column:scope;size:16" json:"scope"`
+	AppID     string `gorm:"column:app_id;size:32" json:"app_id"`
+	ServiceID string `gorm:"column:service_id;size:32" json:"service_id"`
+	// MetricType is one of cpu, memory, restart, http_5xx and custom
+	MetricType string `gorm:"column:metric_type;size:16" json:"metric_type"`
+	// Expr is only used by the custom metric type
+	Expr      string  `gorm:"column:expr;size:2047" json:"expr"`
+	Operator  string  `gorm:"column:operator;size:4" json:"operator"`
+	Threshold float64 `gorm:"column:threshold" json:"threshold"`
+	// Duration how long the condition must hold before the alert fires, e.g. 5m
+	Duration string `gorm:"column:duration;size:16" json:"duration"`
+	Severity string `gorm:"column:severity;size:16" json:"severity"`
+	Summary  string `gorm:"column:summary;size:255" json:"summary"`
+	// ChannelIDs comma separated ids of the channels the alert is routed to
+	ChannelIDs string `gorm:"column:channel_ids;size:1023" json:"channel_ids"`
+	Enable     bool   `gorm:"column:enable" json:"enable"`
+}
+
+// TableName returns table name of TenantAlertRule
+func (t *TenantAlertRule) TableName() string {
+	return "tenant_alert_rules"
+}
+
+// TenantAlertChannel notification channel of a tenant
+type TenantAlertChannel struct {
+	Model
+	ChannelID string `gorm:"column:channel_id;size:32;unique_index" json:"channel_id"`
+	TenantID  string `gorm:"column:tenant_id;size:32;index" json:"tenant_id"`
+	Name      string `gorm:"column:name;size:64" json:"name"`
+	Type      string `gorm:"column:type;size:16" json:"type"`
+	// Config json encoded settings of the channel, such as url, secret or smtp server
+	Config string `gorm:"column:config;type:text" json:"-"`
+	Enable bool   `gorm:"column:enable" json:"enable"`
+}
+
+// TableName returns table name of TenantAlertChannel
+func (t *TenantAlertChannel) TableName() string {
+	return "tenant_alert_channels"
+}
+
+// TenantAlertSilence mutes the notifications of a rule during a time window
+type TenantAlertSilence struct {
+	Model
+	SilenceID string    `gorm:"column:silence_id;size:32;unique_index" json:"silence_id"`
+	TenantID  string    `gorm:"column:tenant_id;size:32;index" json:"tenant_id"`
+	RuleID    string    `gorm:"column:rule_id;size:32;index" json:"rule_id"`
+	StartsAt  time.Time `gorm:"column:starts_at" json:"starts_at"`
+	EndsAt    time.Time `gorm:"column:ends_at" json:"ends_at"`
+	Comment   string    `gorm:"column:comment;size:255" json:"comment"`
+	CreatedBy string    `gorm:"column:created_by;size:64" json:"created_by"`
+}
+
+// TableName returns table name of TenantAlertSilence
+func (t *TenantAlertSilence) TableName() string {
+	return "tenant_alert_silences"
+}
+
+// Active reports whether the silence covers the given time
+func (t *TenantAlertSilence) Active(now time.Time) bool {
+	return !now.Before(t.StartsAt) && now.Before(t.EndsAt)
+}
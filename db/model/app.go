@@ -22,7 +22,7 @@ type AppBackup struct {
 	EventID  string `gorm:"column:event_id;size:32;" json:"event_id"`
 	BackupID string `gorm:"column:backup_id;size:32;" json:"backup_id"`
 	GroupID  string `gorm:"column:group_id;size:32;" json:"group_id"`
-	//Status in starting,failed,success,restore
+	//Status in starting,failed,success,restore,expired
 	Status     string `gorm:"column:status;size:32" json:"status"`
 	Version    string `gorm:"column:version;size:32" json:"version"`
 	SourceDir  string `gorm:"column:source_dir;size:255" json:"source_dir"`
@@ -36,3 +36,57 @@ type AppBackup struct {
 func (t *AppBackup) TableName() string {
 	return "region_app_backup"
 }
+
+// The status of the app migrations
+const (
+	// AppMigrationStatusPresyncing a presync round is running
+	AppMigrationStatusPresyncing = "presyncing"
+	// AppMigrationStatusPresynced the data has been presynced, the app is still serving in the source region
+	AppMigrationStatusPresynced = "presynced"
+	// AppMigrationStatusStopping the components are stopping for the cutover
+	AppMigrationStatusStopping = "stopping"
+	// AppMigrationStatusFinalSyncing the final delta of the stopped components is syncing
+	AppMigrationStatusFinalSyncing = "final_syncing"
+	// AppMigrationStatusReady the final data is synced, the app can be restored in the target region
+	AppMigrationStatusReady = "ready"
+	// AppMigrationStatusCompleted the app is serving in the target region, the gateway rules of the source region are removed
+	AppMigrationStatusCompleted = "completed"
+	// AppMigrationStatusRolledBack the app is serving in the source region again
+	AppMigrationStatusRolledBack = "rolled_back"
+	// AppMigrationStatusFailed the migration failed, it can be rolled back
+	AppMigrationStatusFailed = "failed"
+)
+
+// AppMigration migrates the app to another region. The data is presynced by the incremental
+// backups of the app, so that only the last delta is synced after the components are stopped.
+type AppMigration struct {
+	Model
+	MigrationID string `gorm:"column:migration_id;unique;size:32" json:"migration_id"`
+	EventID     string `gorm:"column:event_id;size:32" json:"event_id"`
+	TenantID    string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	GroupID     string `gorm:"column:group_id;size:32" json:"group_id"`
+	// ServiceIDs the components to migrate, separated by commas
+	ServiceIDs   string `gorm:"column:service_ids;type:text" json:"service_ids"`
+	TargetRegion string `gorm:"column:target_region;size:64" json:"target_region"`
+	// Encrypt encrypts the synced data with the keys configured for the builder
+	Encrypt bool   `gorm:"column:encrypt" json:"encrypt"`
+	Status  string `gorm:"column:status;size:32" json:"status"`
+	// Rounds the number of the sync rounds, including the final one
+	Rounds int `gorm:"column:rounds" json:"rounds"`
+	// BackupID the incremental backup of the last sync round
+	BackupID string `gorm:"column:backup_id;size:32" json:"backup_id"`
+	// SyncedSize the size of the data synced by the last round
+	SyncedSize int64 `gorm:"column:synced_size;type:bigint" json:"synced_size"`
+	// RunningServiceIDs the components running before the cutover, they are started again when rolling back
+	RunningServiceIDs string `gorm:"column:running_service_ids;type:text" json:"running_service_ids"`
+	// Metadata the console level metadata of the app
+	Metadata string `gorm:"column:metadata;type:longtext" json:"-"`
+	// GatewayRules the gateway rules removed from the source region when completing, they are recreated when rolling back
+	GatewayRules string `gorm:"column:gateway_rules;type:longtext" json:"gateway_rules,omitempty"`
+	Message      string `gorm:"column:message;type:text" json:"message"`
+}
+
+// TableName -
+func (t *AppMigration) TableName() string {
+	return "region_app_migration"
+}
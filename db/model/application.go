@@ -25,6 +25,10 @@ const (
 	GovernanceModeKubernetesNativeService = "KUBERNETES_NATIVE_SERVICE"
 	// GovernanceModeIstioServiceMesh means the governance mode is ISTIO_SERVICE_MESH
 	GovernanceModeIstioServiceMesh = "ISTIO_SERVICE_MESH"
+	// GovernanceModeLinkerdServiceMesh means the governance mode is LINKERD_SERVICE_MESH
+	GovernanceModeLinkerdServiceMesh = "LINKERD_SERVICE_MESH"
+	// GovernanceModeConsulServiceMesh means the governance mode is CONSUL_SERVICE_MESH
+	GovernanceModeConsulServiceMesh = "CONSUL_SERVICE_MESH"
 )
 
 const (
@@ -32,8 +36,41 @@ const (
 	GovernanceModeBuildInServiceMeshDesc = "内置ServiceMesh模式需要用户显示的配置组件间的依赖关系，平台会在下游组件中自动注入sidecar容器组成ServiceMesh微服务架构，业务间通信地址统一为localhost模式"
 	// GovernanceModeKubernetesNativeServiceDesc -
 	GovernanceModeKubernetesNativeServiceDesc = "该模式组件间使用Kubernetes service名称域名进行通信，用户需要配置每个组件端口注册的service名称，治理能力有限"
+	// GovernanceModeLinkerdServiceMeshDesc -
+	GovernanceModeLinkerdServiceMeshDesc = "Linkerd ServiceMesh模式会在组件中注入linkerd-proxy，组件间通过mTLS通信，平台根据组件依赖关系生成访问授权策略"
+	// GovernanceModeConsulServiceMeshDesc -
+	GovernanceModeConsulServiceMeshDesc = "Consul Connect模式会在组件中注入Envoy sidecar并注册到Consul，平台根据组件依赖关系生成服务意图(ServiceIntentions)"
 )
 
+const (
+	// MeshMTLSModeDisable means the mtls between the components of the built-in service mesh is disabled
+	MeshMTLSModeDisable = "DISABLE"
+	// MeshMTLSModePermissive means the components accept both mtls and plaintext
+	MeshMTLSModePermissive = "PERMISSIVE"
+	// MeshMTLSModeStrict means the components only accept mtls, except for the ports open to the gateway
+	MeshMTLSModeStrict = "STRICT"
+)
+
+// IsMeshMTLSModeValid checks if the mtls mode is valid
+func IsMeshMTLSModeValid(mode string) bool {
+	return mode == MeshMTLSModeDisable || mode == MeshMTLSModePermissive || mode == MeshMTLSModeStrict
+}
+
+const (
+	// NetworkIsolationDisable means every pod in the namespace can reach the components
+	NetworkIsolationDisable = "DISABLE"
+	// NetworkIsolationDryRun means the network policies are only reported, not applied
+	NetworkIsolationDryRun = "DRYRUN"
+	// NetworkIsolationEnforce means the components only accept the ingress from the dependent components,
+	// the gateway and the platform monitoring
+	NetworkIsolationEnforce = "ENFORCE"
+)
+
+// IsNetworkIsolationValid checks if the network isolation mode is valid
+func IsNetworkIsolationValid(mode string) bool {
+	return mode == NetworkIsolationDisable || mode == NetworkIsolationDryRun || mode == NetworkIsolationEnforce
+}
+
 // app type
 const (
 	AppTypeRainbond = "rainbond"
@@ -43,17 +80,23 @@ const (
 // Application -
 type Application struct {
 	Model
-	EID             string `gorm:"column:eid" json:"eid"`
-	TenantID        string `gorm:"column:tenant_id" json:"tenant_id"`
-	AppName         string `gorm:"column:app_name" json:"app_name"`
-	AppID           string `gorm:"column:app_id" json:"app_id"`
-	AppType         string `gorm:"column:app_type;default:'rainbond'" json:"app_type"`
-	AppStoreName    string `gorm:"column:app_store_name" json:"app_store_name"`
-	AppStoreURL     string `gorm:"column:app_store_url" json:"app_store_url"`
-	AppTemplateName string `gorm:"column:app_template_name" json:"app_template_name"`
-	Version         string `gorm:"column:version" json:"version"`
-	GovernanceMode  string `gorm:"column:governance_mode;default:'KUBERNETES_NATIVE_SERVICE'" json:"governance_mode"`
-	K8sApp          string `gorm:"column:k8s_app" json:"k8s_app"`
+	EID              string `gorm:"column:eid" json:"eid"`
+	TenantID         string `gorm:"column:tenant_id" json:"tenant_id"`
+	AppName          string `gorm:"column:app_name" json:"app_name"`
+	AppID            string `gorm:"column:app_id" json:"app_id"`
+	AppType          string `gorm:"column:app_type;default:'rainbond'" json:"app_type"`
+	AppStoreName     string `gorm:"column:app_store_name" json:"app_store_name"`
+	AppStoreURL      string `gorm:"column:app_store_url" json:"app_store_url"`
+	AppStoreType     string `gorm:"column:app_store_type" json:"app_store_type"`
+	AppStoreBranch   string `gorm:"column:app_store_branch" json:"app_store_branch"`
+	ChartPath        string `gorm:"column:chart_path" json:"chart_path"`
+	ChartDigest      string `gorm:"column:chart_digest" json:"chart_digest"`
+	AppTemplateName  string `gorm:"column:app_template_name" json:"app_template_name"`
+	Version          string `gorm:"column:version" json:"version"`
+	GovernanceMode   string `gorm:"column:governance_mode;default:'KUBERNETES_NATIVE_SERVICE'" json:"governance_mode"`
+	K8sApp           string `gorm:"column:k8s_app" json:"k8s_app"`
+	MeshMTLSMode     string `gorm:"column:mesh_mtls_mode;default:'DISABLE'" json:"mesh_mtls_mode"`
+	NetworkIsolation string `gorm:"column:network_isolation;default:'DISABLE'" json:"network_isolation"`
 }
 
 // TableName return tableName "application"
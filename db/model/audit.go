@@ -0,0 +1,128 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// AuditActorOIDC the actor authenticated by an oidc id token
+	AuditActorOIDC = "oidc"
+	// AuditActorAPIToken the actor authenticated by an issued api token
+	AuditActorAPIToken = "api_token"
+	// AuditActorToken the actor authenticated by the legacy token, the actor is the operator given by the console
+	AuditActorToken = "token"
+	// AuditActorAnonymous the actor is not authenticated
+	AuditActorAnonymous = "anonymous"
+)
+
+// AuditOutcomeSuccess the request succeeded
+var AuditOutcomeSuccess = "success"
+
+// AuditOutcomeDenied the request is rejected by the authentication or the permission check
+var AuditOutcomeDenied = "denied"
+
+// AuditOutcomeFailure the request failed
+var AuditOutcomeFailure = "failure"
+
+// AuditLog a mutating api call, every record is chained to the previous one by its hash
+type AuditLog struct {
+	ID uint `gorm:"column:ID;primary_key" json:"id"`
+	// Time is truncated to seconds, which is the precision of the column
+	Time time.Time `gorm:"column:time;index" json:"time"`
+	// Actor the user, the api token or the operator given by the console
+	Actor string `gorm:"column:actor;size:128;index" json:"actor"`
+	// ActorType oidc, api_token, token or anonymous
+	ActorType string `gorm:"column:actor_type;size:16" json:"actor_type"`
+	// ClaimedOperator the operator given in the request body, it is only trusted as the actor with the legacy token
+	ClaimedOperator string `gorm:"column:claimed_operator;size:128" json:"claimed_operator"`
+	SourceIP        string `gorm:"column:source_ip;size:64" json:"source_ip"`
+	Method          string `gorm:"column:method;size:8" json:"method"`
+	Route           string `gorm:"column:route;size:255" json:"route"`
+	Path            string `gorm:"column:path;size:512" json:"path"`
+	TenantName      string `gorm:"column:tenant_name;size:64;index" json:"tenant_name"`
+	// Target the parameters of the route except the tenant, such as service_alias=gr123456
+	Target string `gorm:"column:target;size:255" json:"target"`
+	// RequestDigest sha256 of the request body
+	RequestDigest string `gorm:"column:request_digest;size:64" json:"request_digest"`
+	StatusCode    int    `gorm:"column:status_code" json:"status_code"`
+	Outcome       string `gorm:"column:outcome;size:16" json:"outcome"`
+	PrevHash      string `gorm:"column:prev_hash;size:64" json:"prev_hash"`
+	Hash          string `gorm:"column:hash;size:64" json:"hash"`
+}
+
+// TableName returns table name of AuditLog
+func (a *AuditLog) TableName() string {
+	return "region_audit_logs"
+}
+
+// Truncate cuts the fields to the size of their columns, it must be called
+// before ComputeHash so the stored record still matches its hash.
+func (a *AuditLog) Truncate() {
+	a.Actor = truncateString(a.Actor, 128)
+	a.ClaimedOperator = truncateString(a.ClaimedOperator, 128)
+	a.ActorType = truncateString(a.ActorType, 16)
+	a.SourceIP = truncateString(a.SourceIP, 64)
+	a.Method = truncateString(a.Method, 8)
+	a.Route = truncateString(a.Route, 255)
+	a.Path = truncateString(a.Path, 512)
+	a.TenantName = truncateString(a.TenantName, 64)
+	a.Target = truncateString(a.Target, 255)
+	a.RequestDigest = truncateString(a.RequestDigest, 64)
+	a.Outcome = truncateString(a.Outcome, 16)
+}
+
+// truncateString cuts s to size characters, which is how the columns are sized
+func truncateString(s string, size int) string {
+	if utf8.RuneCountInString(s) <= size {
+		return s
+	}
+	return string([]rune(s)[:size])
+}
+
+// ComputeHash returns the sha256 of the fields of the record, which covers the hash of the previous record.
+// The fields are encoded as a json array, so no field can run into the next one.
+func (a *AuditLog) ComputeHash() string {
+	fields := []string{
+		a.PrevHash,
+		strconv.FormatInt(a.Time.Unix(), 10),
+		a.Actor,
+		a.ActorType,
+		a.ClaimedOperator,
+		a.SourceIP,
+		a.Method,
+		a.Route,
+		a.Path,
+		a.TenantName,
+		a.Target,
+		a.RequestDigest,
+		strconv.Itoa(a.StatusCode),
+		a.Outcome,
+	}
+	// encoding a slice of strings never fails
+	data, _ := json.Marshal(fields)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,85 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestAuditLogComputeHash(t *testing.T) {
+	auditLog := &AuditLog{
+		Time:       time.Unix(1700000000, 0),
+		Actor:      "admin",
+		ActorType:  AuditActorOIDC,
+		Method:     "POST",
+		Route:      "/v2/tenants/{tenant_name}/services/{service_alias}/start",
+		TenantName: "team",
+		Target:     "service_alias=gr123456",
+		StatusCode: 200,
+		Outcome:    AuditOutcomeSuccess,
+	}
+	hash := auditLog.ComputeHash()
+	if len(hash) != 64 || hash != auditLog.ComputeHash() {
+		t.Fatalf("unexpected hash %s", hash)
+	}
+	auditLog.Time = auditLog.Time.Add(500 * time.Millisecond)
+	if auditLog.ComputeHash() != hash {
+		t.Error("the hash should ignore the sub-second part of the time")
+	}
+	auditLog.PrevHash = "0"
+	if auditLog.ComputeHash() == hash {
+		t.Error("the hash should cover the previous hash")
+	}
+	auditLog.PrevHash = ""
+	auditLog.Outcome = AuditOutcomeFailure
+	if auditLog.ComputeHash() == hash {
+		t.Error("the hash should cover the outcome")
+	}
+
+	// a field can not run into the next one
+	a := &AuditLog{Path: "/v2/tenants\nteam", TenantName: "x", Target: ""}
+	b := &AuditLog{Path: "/v2/tenants", TenantName: "team", Target: "x\n"}
+	if a.ComputeHash() == b.ComputeHash() {
+		t.Error("the hashes of the different fields should differ")
+	}
+}
+
+func TestAuditLogTruncate(t *testing.T) {
+	auditLog := &AuditLog{
+		Actor: strings.Repeat("a", 200),
+		Path:  "/" + strings.Repeat("p", 600),
+	}
+	auditLog.Truncate()
+	if len(auditLog.Actor) != 128 || len(auditLog.Path) != 512 {
+		t.Fatalf("unexpected lengths %d %d", len(auditLog.Actor), len(auditLog.Path))
+	}
+	auditLog.Target = strings.Repeat("目", 300)
+	auditLog.Truncate()
+	if !utf8.ValidString(auditLog.Target) || utf8.RuneCountInString(auditLog.Target) != 255 {
+		t.Fatalf("the target should be cut to 255 characters, got %d", utf8.RuneCountInString(auditLog.Target))
+	}
+	hash := auditLog.ComputeHash()
+	auditLog.ClaimedOperator = "admin"
+	if auditLog.ComputeHash() == hash {
+		t.Error("the hash should cover the claimed operator")
+	}
+}
@@ -0,0 +1,229 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// The types of the pipeline stages
+const (
+	// PipelineStageTypeScript runs the commands in the image of the stage, with the source code as the working dir
+	PipelineStageTypeScript = "script"
+	// PipelineStageTypeBuild builds the image of the component, it is appended after the other stages if absent
+	PipelineStageTypeBuild = "build"
+)
+
+// The types of the promotion gates
+const (
+	// PromotionGateManual deploys the version once it is approved
+	PromotionGateManual = "manual"
+	// PromotionGateSmoke deploys the version once a preview of the version passes the http check
+	PromotionGateSmoke = "smoke"
+)
+
+// The status of the pipeline runs
+const (
+	PipelineRunStatusRunning         = "running"
+	PipelineRunStatusFailed          = "failed"
+	PipelineRunStatusSucceeded       = "succeeded"
+	PipelineRunStatusWaitingApproval = "waiting_approval"
+	PipelineRunStatusPromoted        = "promoted"
+	PipelineRunStatusRejected        = "rejected"
+)
+
+// MaxPipelineStageTimeout the max seconds a stage may take, it is kept under the 60 minutes limit of the build
+const MaxPipelineStageTimeout = 3000
+
+// IsPipelineRunDeployable whether the version built by the run can be deployed,
+// the versions held or rejected by the promotion gate can not.
+func IsPipelineRunDeployable(status string) bool {
+	return status != PipelineRunStatusWaitingApproval && status != PipelineRunStatusRejected
+}
+
+var pipelineStageNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// BuildPipeline the ordered stages run when building the component from the source code,
+// and the gate the new version must pass before being deployed.
+// It is defined by the pipeline section of the rainbondfile, or by the api which takes precedence.
+type BuildPipeline struct {
+	Stages []*PipelineStage `json:"stages" yaml:"stages"`
+	Gate   *PromotionGate   `json:"gate,omitempty" yaml:"gate"`
+}
+
+// PipelineStage a stage of the build pipeline
+type PipelineStage struct {
+	Name string `json:"name" yaml:"name"`
+	// Type script or build, defaults to script
+	Type     string            `json:"type,omitempty" yaml:"type"`
+	Image    string            `json:"image,omitempty" yaml:"image"`
+	Commands []string          `json:"commands,omitempty" yaml:"commands"`
+	Envs     map[string]string `json:"envs,omitempty" yaml:"envs"`
+	// Timeout the seconds the stage may take, defaults to 600, at most MaxPipelineStageTimeout
+	Timeout int `json:"timeout,omitempty" yaml:"timeout"`
+}
+
+// PromotionGate the condition the new version must meet before being deployed
+type PromotionGate struct {
+	// Type manual or smoke
+	Type string `json:"type" yaml:"type"`
+	// Port the port of the preview checked by the smoke gate
+	Port int `json:"port,omitempty" yaml:"port"`
+	// Path the http path checked by the smoke gate, defaults to /
+	Path string `json:"path,omitempty" yaml:"path"`
+	// ExpectStatus the http status expected by the smoke gate, any status below 400 passes if not set
+	ExpectStatus int `json:"expect_status,omitempty" yaml:"expect_status"`
+	// Timeout the seconds to wait for the preview to pass the check, defaults to 120
+	Timeout int `json:"timeout,omitempty" yaml:"timeout"`
+	// Envs the envs of the preview besides those of the component
+	Envs map[string]string `json:"envs,omitempty" yaml:"envs"`
+}
+
+// Validate checks the stages and the gate of the pipeline
+func (p *BuildPipeline) Validate() error {
+	if len(p.Stages) == 0 && p.Gate == nil {
+		return fmt.Errorf("the pipeline defines neither stages nor gate")
+	}
+	names := make(map[string]bool)
+	var builds int
+	for _, stage := range p.Stages {
+		if len(stage.Name) > 40 || !pipelineStageNameRegexp.MatchString(stage.Name) {
+			return fmt.Errorf("invalid stage name %q, it must consist of at most 40 lower case alphanumeric characters or '-'", stage.Name)
+		}
+		if names[stage.Name] {
+			return fmt.Errorf("stage name %s is duplicated", stage.Name)
+		}
+		names[stage.Name] = true
+		switch stage.Type {
+		case "", PipelineStageTypeScript:
+			if stage.Image == "" || len(stage.Commands) == 0 {
+				return fmt.Errorf("stage %s requires the image and the commands", stage.Name)
+			}
+		case PipelineStageTypeBuild:
+			if stage.Image != "" || len(stage.Commands) > 0 {
+				return fmt.Errorf("the build stage %s does not accept the image or the commands", stage.Name)
+			}
+			builds++
+		default:
+			return fmt.Errorf("unsupported type %s of stage %s", stage.Type, stage.Name)
+		}
+		if stage.Timeout < 0 || stage.Timeout > MaxPipelineStageTimeout {
+			return fmt.Errorf("the timeout of stage %s must be between 0 and %d", stage.Name, MaxPipelineStageTimeout)
+		}
+	}
+	if builds > 1 {
+		return fmt.Errorf("the pipeline can only have one build stage")
+	}
+	if p.Gate == nil {
+		return nil
+	}
+	switch p.Gate.Type {
+	case PromotionGateManual:
+	case PromotionGateSmoke:
+		if p.Gate.Port < 1 || p.Gate.Port > 65535 {
+			return fmt.Errorf("the smoke gate requires a valid port")
+		}
+		if p.Gate.Path != "" && !strings.HasPrefix(p.Gate.Path, "/") {
+			return fmt.Errorf("the path of the smoke gate must start with /")
+		}
+		if p.Gate.ExpectStatus != 0 && (p.Gate.ExpectStatus < 100 || p.Gate.ExpectStatus > 599) {
+			return fmt.Errorf("invalid expected status %d of the smoke gate", p.Gate.ExpectStatus)
+		}
+		if p.Gate.Timeout < 0 {
+			return fmt.Errorf("the timeout of the smoke gate can not be negative")
+		}
+	default:
+		return fmt.Errorf("unsupported gate type %s", p.Gate.Type)
+	}
+	return nil
+}
+
+// TenantServiceBuildPipeline the build pipeline of the component defined by the api
+type TenantServiceBuildPipeline struct {
+	Model
+	ServiceID string `gorm:"column:service_id;unique;size:32" json:"service_id"`
+	TenantID  string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	// Pipeline the json of the BuildPipeline
+	Pipeline string `gorm:"column:pipeline;type:text" json:"pipeline"`
+}
+
+// TableName -
+func (t *TenantServiceBuildPipeline) TableName() string {
+	return "tenant_services_build_pipelines"
+}
+
+// BuildPipeline decodes the pipeline
+func (t *TenantServiceBuildPipeline) BuildPipeline() (*BuildPipeline, error) {
+	var pipeline BuildPipeline
+	if err := json.Unmarshal([]byte(t.Pipeline), &pipeline); err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// PipelineStageResult the result of a stage of the pipeline run
+type PipelineStageResult struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	FinishTime *time.Time `json:"finish_time,omitempty"`
+	Message    string     `json:"message,omitempty"`
+}
+
+// TenantServicePipelineRun the run of the build pipeline for a build of the component,
+// the logs of the stages are found in the event of the build.
+type TenantServicePipelineRun struct {
+	Model
+	// EventID the event of the build
+	EventID       string `gorm:"column:event_id;unique;size:32" json:"event_id"`
+	TenantID      string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	ServiceID     string `gorm:"column:service_id;size:32" json:"service_id"`
+	DeployVersion string `gorm:"column:deploy_version;size:40" json:"deploy_version"`
+	// Action upgrade rolling upgrades the component once the version is promoted
+	Action string `gorm:"column:action;size:20" json:"action"`
+	Status string `gorm:"column:status;size:32" json:"status"`
+	// Stages the json of the stage results
+	Stages   string `gorm:"column:stages;type:text" json:"stages"`
+	GateType string `gorm:"column:gate_type;size:20" json:"gate_type"`
+	// Configs the json of the configs of the rolling upgrade run once the version is approved
+	Configs string `gorm:"column:configs;type:text" json:"-"`
+	// Operator the user approving or rejecting the version
+	Operator   string     `gorm:"column:operator;size:64" json:"operator"`
+	Message    string     `gorm:"column:message;type:text" json:"message"`
+	FinishTime *time.Time `gorm:"column:finish_time" json:"finish_time"`
+}
+
+// TableName -
+func (t *TenantServicePipelineRun) TableName() string {
+	return "tenant_services_pipeline_runs"
+}
+
+// StageResults decodes the stage results
+func (t *TenantServicePipelineRun) StageResults() []*PipelineStageResult {
+	var stages []*PipelineStageResult
+	if t.Stages != "" {
+		_ = json.Unmarshal([]byte(t.Stages), &stages)
+	}
+	return stages
+}
@@ -0,0 +1,66 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "testing"
+
+func TestBuildPipelineValidate(t *testing.T) {
+	script := func(name string) *PipelineStage {
+		return &PipelineStage{Name: name, Image: "golang:1.20", Commands: []string{"go test ./..."}}
+	}
+	tests := []struct {
+		name     string
+		pipeline BuildPipeline
+		wantErr  bool
+	}{
+		{"empty", BuildPipeline{}, true},
+		{"stages", BuildPipeline{Stages: []*PipelineStage{script("lint"), script("unit-test"), {Name: "image", Type: PipelineStageTypeBuild}}}, false},
+		{"gate only", BuildPipeline{Gate: &PromotionGate{Type: PromotionGateManual}}, false},
+		{"invalid name", BuildPipeline{Stages: []*PipelineStage{script("Unit_Test")}}, true},
+		{"duplicate name", BuildPipeline{Stages: []*PipelineStage{script("test"), script("test")}}, true},
+		{"script without commands", BuildPipeline{Stages: []*PipelineStage{{Name: "test", Image: "golang:1.20"}}}, true},
+		{"build with image", BuildPipeline{Stages: []*PipelineStage{{Name: "image", Type: PipelineStageTypeBuild, Image: "golang:1.20"}}}, true},
+		{"two builds", BuildPipeline{Stages: []*PipelineStage{{Name: "a", Type: PipelineStageTypeBuild}, {Name: "b", Type: PipelineStageTypeBuild}}}, true},
+		{"unknown type", BuildPipeline{Stages: []*PipelineStage{{Name: "a", Type: "deploy"}}}, true},
+		{"stage timeout too long", BuildPipeline{Stages: []*PipelineStage{{Name: "test", Image: "golang:1.20", Commands: []string{"go test ./..."}, Timeout: MaxPipelineStageTimeout + 1}}}, true},
+		{"smoke", BuildPipeline{Gate: &PromotionGate{Type: PromotionGateSmoke, Port: 8080, Path: "/health", ExpectStatus: 200}}, false},
+		{"smoke without port", BuildPipeline{Gate: &PromotionGate{Type: PromotionGateSmoke}}, true},
+		{"smoke with relative path", BuildPipeline{Gate: &PromotionGate{Type: PromotionGateSmoke, Port: 8080, Path: "health"}}, true},
+		{"smoke with invalid status", BuildPipeline{Gate: &PromotionGate{Type: PromotionGateSmoke, Port: 8080, ExpectStatus: 1000}}, true},
+		{"unknown gate", BuildPipeline{Gate: &PromotionGate{Type: "vote"}}, true},
+	}
+	for _, tc := range tests {
+		if err := tc.pipeline.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestIsPipelineRunDeployable(t *testing.T) {
+	for status, want := range map[string]bool{
+		PipelineRunStatusSucceeded:       true,
+		PipelineRunStatusPromoted:        true,
+		PipelineRunStatusWaitingApproval: false,
+		PipelineRunStatusRejected:        false,
+	} {
+		if got := IsPipelineRunDeployable(status); got != want {
+			t.Errorf("IsPipelineRunDeployable(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
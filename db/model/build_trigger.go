@@ -0,0 +1,82 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "encoding/json"
+
+// ServiceSourceTypeBuildTrigger the type of the service source which stores the build trigger of the component
+const ServiceSourceTypeBuildTrigger = "build_trigger"
+
+const (
+	// BuildTriggerModeWebhook builds the component once the git server sends a push or tag webhook
+	BuildTriggerModeWebhook = "webhook"
+	// BuildTriggerModePoll builds the component once the last commit of the branch changes,
+	// for the repositories which can not send webhooks
+	BuildTriggerModePoll = "poll"
+)
+
+// BuildTrigger builds the component from the source code automatically,
+// it is stored as the body of the service source of type build_trigger.
+type BuildTrigger struct {
+	Mode string `json:"mode"`
+	// Provider github, gitlab, gitea or bitbucket, the webhooks of any provider are accepted if empty
+	Provider string `json:"provider,omitempty"`
+	RepoURL  string `json:"repo_url"`
+	Branch   string `json:"branch"`
+	// TagPattern builds the pushed tags matching the pattern, such as v*, the tags are ignored if empty
+	TagPattern string `json:"tag_pattern,omitempty"`
+	// Secret the secret of the webhook, used to verify the signature of the payload
+	Secret     string `json:"secret,omitempty"`
+	ServerType string `json:"server_type,omitempty"`
+	Lang       string `json:"lang,omitempty"`
+	Runtime    string `json:"runtime,omitempty"`
+	User       string `json:"user,omitempty"`
+	Password   string `json:"password,omitempty"`
+	// Action upgrade deploys the component once the build succeeds
+	Action string `json:"action,omitempty"`
+	// PollInterval the seconds between two polls of the last commit
+	PollInterval int `json:"poll_interval,omitempty"`
+	// Debounce the seconds to wait for the following pushes before building, only the last one is built
+	Debounce int `json:"debounce,omitempty"`
+	// LastCommit the last commit built by the trigger
+	LastCommit string `json:"last_commit,omitempty"`
+	LastPollAt int64  `json:"last_poll_at,omitempty"`
+}
+
+// ParseBuildTrigger parses the build trigger from the body of the service source
+func ParseBuildTrigger(source *ServiceSourceConfig) (*BuildTrigger, error) {
+	var trigger BuildTrigger
+	if err := json.Unmarshal([]byte(source.SourceBody), &trigger); err != nil {
+		return nil, err
+	}
+	return &trigger, nil
+}
+
+// ServiceSource encodes the build trigger as the service source of the component
+func (b *BuildTrigger) ServiceSource(serviceID string) (*ServiceSourceConfig, error) {
+	body, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceSourceConfig{
+		ServiceID:  serviceID,
+		SourceType: ServiceSourceTypeBuildTrigger,
+		SourceBody: string(body),
+	}, nil
+}
@@ -18,6 +18,8 @@
 
 package model
 
+import "time"
+
 //TableName 表名
 func (t *RegionUserInfo) TableName() string {
 	return "user_region_info"
@@ -34,3 +36,38 @@ type RegionUserInfo struct {
 	CA             string `gorm:"column:ca;size:4096" json:"ca"`
 	Key            string `gorm:"column:key;size:4096" json:"key"`
 }
+
+// APITokenScopeReadOnly only allows the requests which do not change anything
+var APITokenScopeReadOnly = "read-only"
+
+// APITokenScopeDeploy allows operating the tenants, apps and components, but not the cluster
+var APITokenScopeDeploy = "deploy"
+
+// APITokenScopeAdmin allows every request
+var APITokenScopeAdmin = "admin"
+
+// APIToken an issued rbd-api token, only the sha256 hash of the token is stored
+type APIToken struct {
+	Model
+	TokenID   string `gorm:"column:token_id;size:32;unique_index" json:"token_id"`
+	Name      string `gorm:"column:name;size:64" json:"name"`
+	TokenHash string `gorm:"column:token_hash;size:64;unique_index" json:"-"`
+	Scope     string `gorm:"column:scope;size:16" json:"scope"`
+	// TenantName restricts the token to the tenant if not empty
+	TenantName string `gorm:"column:tenant_name;size:64;index" json:"tenant_name"`
+	// AppID restricts the token to the app of the tenant if not empty
+	AppID      string     `gorm:"column:app_id;size:32" json:"app_id"`
+	ExpireTime *time.Time `gorm:"column:expire_time" json:"expire_time"`
+	Revoked    bool       `gorm:"column:revoked" json:"revoked"`
+	CreatedBy  string     `gorm:"column:created_by;size:64" json:"created_by"`
+}
+
+// TableName returns table name of APIToken
+func (t *APIToken) TableName() string {
+	return "region_api_tokens"
+}
+
+// Expired reports whether the token is expired at the given time
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpireTime != nil && !now.Before(*t.ExpireTime)
+}
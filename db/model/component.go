@@ -63,6 +63,18 @@ const (
 	K8sAttributeNameLiveNessProbe = "livenessProbe"
 	// K8sAttributeNameHostAliases -
 	K8sAttributeNameHostAliases = "hostAliases"
+	// K8sAttributeNameInitContainers the init containers of the component, run after those of the plugins
+	K8sAttributeNameInitContainers = "initContainers"
+	// K8sAttributeNameSidecarContainers the containers run beside the main container
+	K8sAttributeNameSidecarContainers = "sidecarContainers"
+	// K8sAttributeNameUpdateStrategy the update strategy of the daemonset
+	K8sAttributeNameUpdateStrategy = "updateStrategy"
+	// K8sAttributeNamePodDisruptionBudget the minAvailable or maxUnavailable of the pods during voluntary disruptions
+	K8sAttributeNamePodDisruptionBudget = "podDisruptionBudget"
+	// K8sAttributeNameTopologySpreadConstraints -
+	K8sAttributeNameTopologySpreadConstraints = "topologySpreadConstraints"
+	// K8sAttributeNamePriorityClassName -
+	K8sAttributeNamePriorityClassName = "priorityClassName"
 )
 
 // ComponentK8sAttributes -
@@ -0,0 +1,40 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2022-2022 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+// MaskedSecretValue the value stored and returned in place of the values of the external secrets
+const MaskedSecretValue = "******"
+
+// ExternalSecretRef references a key of a secret in the external secret store, such as HashiCorp Vault.
+// The value is resolved by the worker when the component starts or upgrades, it is never stored in the database.
+type ExternalSecretRef struct {
+	// SecretPath the path of the secret relative to the root of the tenant in the store, rainbond/<tenant_id>/
+	SecretPath string `gorm:"column:secret_path;size:255" json:"secret_path,omitempty"`
+	SecretKey  string `gorm:"column:secret_key;size:255" json:"secret_key,omitempty"`
+	// SecretVersion the version of the secret, 0 means the latest one, which is refreshed once the secret rotates
+	SecretVersion int `gorm:"column:secret_version;default:0" json:"secret_version,omitempty"`
+	// RestartOnRotation rolling restarts the component once the value is refreshed,
+	// the components always restart for the config files, which are not updated in the running pods
+	RestartOnRotation bool `gorm:"column:restart_on_rotation;default:false" json:"restart_on_rotation,omitempty"`
+}
+
+// IsExternalSecret whether the value is read from the external secret store
+func (e ExternalSecretRef) IsExternalSecret() bool {
+	return e.SecretPath != ""
+}
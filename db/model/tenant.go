@@ -62,12 +62,13 @@ func (t TenantStatus) String() string {
 // Tenants 租户信息
 type Tenants struct {
 	Model
-	Name        string `gorm:"column:name;size:40;unique_index"`
-	UUID        string `gorm:"column:uuid;size:33;unique_index"`
-	EID         string `gorm:"column:eid"`
-	LimitMemory int    `gorm:"column:limit_memory"`
-	Status      string `gorm:"column:status;default:'normal'"`
-	Namespace   string `gorm:"column:namespace;size:32;unique_index"`
+	Name             string `gorm:"column:name;size:40;unique_index"`
+	UUID             string `gorm:"column:uuid;size:33;unique_index"`
+	EID              string `gorm:"column:eid"`
+	LimitMemory      int    `gorm:"column:limit_memory"`
+	Status           string `gorm:"column:status;default:'normal'"`
+	Namespace        string `gorm:"column:namespace;size:32;unique_index"`
+	NetworkIsolation string `gorm:"column:network_isolation;default:'DISABLE'"`
 }
 
 // TableName 返回租户表名称
@@ -75,6 +76,41 @@ func (t *Tenants) TableName() string {
 	return "tenants"
 }
 
+// TenantQuota the quota of a tenant, the zero values mean unlimited
+type TenantQuota struct {
+	Model
+	TenantID string `gorm:"column:tenant_id;size:32;unique_index" json:"tenant_id"`
+	// CPU limits of the pods in millicores
+	CPU int `gorm:"column:cpu" json:"cpu"`
+	// Memory limits of the pods in MB
+	Memory int `gorm:"column:memory" json:"memory"`
+	// EphemeralStorage limits of the pods in GB
+	EphemeralStorage int `gorm:"column:ephemeral_storage" json:"ephemeral_storage"`
+	// Storage requests of the persistent volume claims in GB
+	Storage int `gorm:"column:storage" json:"storage"`
+	Pods    int `gorm:"column:pods" json:"pods"`
+	GPU     int `gorm:"column:gpu" json:"gpu"`
+}
+
+// TableName returns table name of TenantQuota
+func (t *TenantQuota) TableName() string {
+	return "tenant_quotas"
+}
+
+// TenantStorageClassQuota the storage quota of a tenant in a storage class
+type TenantStorageClassQuota struct {
+	Model
+	TenantID     string `gorm:"column:tenant_id;size:32;unique_index:tenant_storage_class" json:"tenant_id"`
+	StorageClass string `gorm:"column:storage_class;size:253;unique_index:tenant_storage_class" json:"storage_class"`
+	// Storage requests of the persistent volume claims in GB
+	Storage int `gorm:"column:storage" json:"storage"`
+}
+
+// TableName returns table name of TenantStorageClassQuota
+func (t *TenantStorageClassQuota) TableName() string {
+	return "tenant_storage_class_quotas"
+}
+
 // ServiceKind kind of service
 type ServiceKind string
 
@@ -134,6 +170,11 @@ func (s ServiceType) IsCronJob() bool {
 	return false
 }
 
+// IsDaemonSet is daemonset
+func (s ServiceType) IsDaemonSet() bool {
+	return s == ServiceTypeDaemonSet
+}
+
 // IsSingleton is singleton or not
 func (s ServiceType) IsSingleton() bool {
 	if s == "" {
@@ -177,6 +218,11 @@ func (t *TenantServices) IsCronJob() bool {
 	return false
 }
 
+// IsDaemonSet is daemonset, which runs a pod on every selected node
+func (t *TenantServices) IsDaemonSet() bool {
+	return ServiceType(t.ExtendMethod).IsDaemonSet()
+}
+
 // IsSingleton is singleton or multiple service
 func (t *TenantServices) IsSingleton() bool {
 	if t.ExtendMethod == "" {
@@ -209,6 +255,9 @@ var ServiceTypeJob ServiceType = "job"
 // ServiceTypeCronJob cronjob
 var ServiceTypeCronJob ServiceType = "cronjob"
 
+// ServiceTypeDaemonSet daemonset
+var ServiceTypeDaemonSet ServiceType = "daemonset"
+
 // TenantServices app service base info
 type TenantServices struct {
 	Model
@@ -467,6 +516,7 @@ type TenantServiceEnvVar struct {
 	AttrValue     string `gorm:"column:attr_value;type:text" validate:"env_value|required" json:"attr_value"`
 	IsChange      bool   `gorm:"column:is_change" validate:"is_change|bool" json:"is_change"`
 	Scope         string `gorm:"column:scope;default:'outer'" validate:"scope|in:outer,inner,both" json:"scope"`
+	ExternalSecretRef
 }
 
 // TableName 表名
@@ -580,6 +630,7 @@ type TenantServiceConfigFile struct {
 	ServiceID   string `gorm:"column:service_id;size:32" json:"service_id"`
 	VolumeName  string `gorm:"column:volume_name;size:128" json:"volume_name"`
 	FileContent string `gorm:"column:file_content;size:65535" json:"filename"`
+	ExternalSecretRef
 }
 
 // TableName returns table name of TenantServiceConfigFile.
@@ -644,6 +695,12 @@ type TenantServiceProbe struct {
 	//标志为成功的检测次数
 	SuccessThreshold int    `gorm:"column:success_threshold;size:2;default:1" json:"success_threshold" validate:"success_threshold"`
 	FailureAction    string `gorm:"column:failure_action;" json:"failure_action" validate:"failure_action"`
+	//grpc健康检查请求的服务名
+	GRPCService string `gorm:"column:grpc_service" json:"grpc_service" validate:"grpc_service"`
+	//grpc检测是否使用tls
+	GRPCTLS bool `gorm:"column:grpc_tls" json:"grpc_tls" validate:"grpc_tls"`
+	//证书在此天数内过期视为检测失败
+	TLSExpireDays int `gorm:"column:tls_expire_days" json:"tls_expire_days" validate:"tls_expire_days"`
 }
 
 // FailureActionType  type of failure action.
@@ -683,6 +740,25 @@ func (t *TenantServiceAutoscalerRules) TableName() string {
 	return "tenant_services_autoscaler_rules"
 }
 
+// The metric types of autoscaler rule metrics
+const (
+	// ResourceMetricsType cpu or memory of the pods
+	ResourceMetricsType = "resource_metrics"
+	// PodsMetricsType custom metric describing each pod, such as requests per second of the pod
+	PodsMetricsType = "pods_metrics"
+	// ObjectMetricsType custom metric describing a single kubernetes object, such as the qps of a service
+	ObjectMetricsType = "object_metrics"
+	// ExternalMetricsType metric not associated with any kubernetes object, such as the length of a queue
+	ExternalMetricsType = "external_metrics"
+)
+
+// The target types of autoscaler rule metrics
+const (
+	UtilizationMetricTarget  = "utilization"
+	AverageValueMetricTarget = "average_value"
+	ValueMetricTarget        = "value"
+)
+
 // TenantServiceAutoscalerRuleMetrics -
 type TenantServiceAutoscalerRuleMetrics struct {
 	Model
@@ -691,6 +767,11 @@ type TenantServiceAutoscalerRuleMetrics struct {
 	MetricsName       string `gorm:"column:metric_name;not null"`
 	MetricTargetType  string `gorm:"column:metric_target_type;not null"`
 	MetricTargetValue int    `gorm:"column:metric_target_value;not null"`
+	// MetricSelector label selector of pods, object and external metrics, such as queue=orders
+	MetricSelector string `gorm:"column:metric_selector;size:1024"`
+	// DescribedObjectKind and DescribedObjectName the object described by an object metric
+	DescribedObjectKind string `gorm:"column:described_object_kind;size:64"`
+	DescribedObjectName string `gorm:"column:described_object_name;size:255"`
 }
 
 // TableName -
@@ -717,6 +798,87 @@ func (t *TenantServiceScalingRecords) TableName() string {
 	return "tenant_services_scaling_records"
 }
 
+// The record types of scaling records
+const (
+	ManualScalingRecord      = "manual"
+	ScheduleScalingRecord    = "schedule"
+	ScaleToZeroScalingRecord = "scale_to_zero"
+)
+
+// TenantServiceScalingSchedule scales the component to the given replicas on a cron schedule
+type TenantServiceScalingSchedule struct {
+	Model
+	ScheduleID string `gorm:"column:schedule_id;unique;size:32" json:"schedule_id"`
+	ServiceID  string `gorm:"column:service_id;size:32" json:"service_id"`
+	Name       string `gorm:"column:name;size:64" json:"name"`
+	// Schedule standard 5 fields cron expression, such as '0 20 * * 1-5'
+	Schedule string `gorm:"column:schedule;size:64" json:"schedule"`
+	Timezone string `gorm:"column:timezone;size:64" json:"timezone"`
+	Replicas int    `gorm:"column:replicas" json:"replicas"`
+	Enable   bool   `gorm:"column:enable" json:"enable"`
+	// LastScheduleTime the last time the schedule was executed
+	LastScheduleTime *time.Time `gorm:"column:last_schedule_time" json:"last_schedule_time"`
+}
+
+// TableName -
+func (t *TenantServiceScalingSchedule) TableName() string {
+	return "tenant_services_scaling_schedules"
+}
+
+// TenantServiceScaleToZero scales the idle component to zero and wakes it up when requests arrive from the gateway
+type TenantServiceScaleToZero struct {
+	Model
+	ServiceID string `gorm:"column:service_id;unique;size:32" json:"service_id"`
+	Enable    bool   `gorm:"column:enable" json:"enable"`
+	// IdleSeconds the component is scaled to zero after receiving no requests for IdleSeconds
+	IdleSeconds int `gorm:"column:idle_seconds" json:"idle_seconds"`
+	// WakeReplicas the replicas restored on wake-up, recorded when scaling to zero
+	WakeReplicas int `gorm:"column:wake_replicas" json:"wake_replicas"`
+}
+
+// TableName -
+func (t *TenantServiceScaleToZero) TableName() string {
+	return "tenant_services_scale_to_zero"
+}
+
+// TenantServiceResourceRecommendation the resources recommended for the component from its historical usage
+type TenantServiceResourceRecommendation struct {
+	Model
+	ServiceID string `gorm:"column:service_id;unique;size:32" json:"service_id"`
+	TenantID  string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	// CPURequest and CPULimit in millicores
+	CPURequest int `gorm:"column:cpu_request" json:"cpu_request"`
+	CPULimit   int `gorm:"column:cpu_limit" json:"cpu_limit"`
+	// MemoryRequest and MemoryLimit in MB
+	MemoryRequest int `gorm:"column:memory_request" json:"memory_request"`
+	MemoryLimit   int `gorm:"column:memory_limit" json:"memory_limit"`
+	// Window the range of the historical usage the recommendation is computed from, such as 168h
+	Window        string     `gorm:"column:window;size:32" json:"window"`
+	RecommendTime *time.Time `gorm:"column:recommend_time" json:"recommend_time"`
+}
+
+// TableName -
+func (t *TenantServiceResourceRecommendation) TableName() string {
+	return "tenant_services_resource_recommendations"
+}
+
+// TenantServiceRecommendationPolicy decides whether the recommendation is applied automatically
+type TenantServiceRecommendationPolicy struct {
+	Model
+	ServiceID string `gorm:"column:service_id;unique;size:32" json:"service_id"`
+	AutoApply bool   `gorm:"column:auto_apply" json:"auto_apply"`
+	// ApplyWindowStart and ApplyWindowEnd the off-peak window in HH:MM, the window may span midnight
+	ApplyWindowStart string     `gorm:"column:apply_window_start;size:5" json:"apply_window_start"`
+	ApplyWindowEnd   string     `gorm:"column:apply_window_end;size:5" json:"apply_window_end"`
+	Timezone         string     `gorm:"column:timezone;size:64" json:"timezone"`
+	LastApplyTime    *time.Time `gorm:"column:last_apply_time" json:"last_apply_time"`
+}
+
+// TableName -
+func (t *TenantServiceRecommendationPolicy) TableName() string {
+	return "tenant_services_recommendation_policies"
+}
+
 // ServiceID -
 type ServiceID struct {
 	ServiceID string `gorm:"column:service_id" json:"-"`
@@ -0,0 +1,79 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package model
+
+import "time"
+
+// The status of the volume snapshots
+const (
+	VolumeSnapshotStatusCreating  = "creating"
+	VolumeSnapshotStatusReady     = "ready"
+	VolumeSnapshotStatusFailed    = "failed"
+	VolumeSnapshotStatusRestoring = "restoring"
+	VolumeSnapshotStatusDeleting  = "deleting"
+)
+
+// TenantServiceVolumeSnapshot a point-in-time snapshot of a component volume,
+// it consists of a csi VolumeSnapshot for every claim of the volume.
+type TenantServiceVolumeSnapshot struct {
+	Model
+	SnapshotID string `gorm:"column:snapshot_id;unique;size:32" json:"snapshot_id"`
+	TenantID   string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	ServiceID  string `gorm:"column:service_id;size:32" json:"service_id"`
+	VolumeName string `gorm:"column:volume_name;size:40" json:"volume_name"`
+	// PolicyID the snapshot policy which takes the snapshot, empty for the manual snapshots
+	PolicyID      string `gorm:"column:policy_id;size:32" json:"policy_id"`
+	SnapshotClass string `gorm:"column:snapshot_class" json:"snapshot_class"`
+	Status        string `gorm:"column:status;size:32" json:"status"`
+	// ClaimCount the number of the claims of the volume when taking the snapshot
+	ClaimCount int `gorm:"column:claim_count" json:"claim_count"`
+	// RestoreSize the minimum size in bytes of the volume restored from the snapshot
+	RestoreSize int64      `gorm:"column:restore_size" json:"restore_size"`
+	ReadyTime   *time.Time `gorm:"column:ready_time" json:"ready_time"`
+	Message     string     `gorm:"column:message;type:text" json:"message"`
+}
+
+// TableName -
+func (t *TenantServiceVolumeSnapshot) TableName() string {
+	return "tenant_services_volume_snapshots"
+}
+
+// TenantServiceSnapshotPolicy takes snapshots of the component volumes on a cron schedule,
+// and only keeps the latest snapshots of every volume.
+type TenantServiceSnapshotPolicy struct {
+	Model
+	PolicyID  string `gorm:"column:policy_id;unique;size:32" json:"policy_id"`
+	TenantID  string `gorm:"column:tenant_id;size:32" json:"tenant_id"`
+	ServiceID string `gorm:"column:service_id;size:32" json:"service_id"`
+	// VolumeName the volume to snapshot, empty means all volumes of the component
+	VolumeName string `gorm:"column:volume_name;size:40" json:"volume_name"`
+	// Schedule standard 5 fields cron expression, such as '0 2 * * *'
+	Schedule string `gorm:"column:schedule;size:64" json:"schedule"`
+	Timezone string `gorm:"column:timezone;size:64" json:"timezone"`
+	// Retention the number of the snapshots kept for every volume
+	Retention int  `gorm:"column:retention" json:"retention"`
+	Enable    bool `gorm:"column:enable" json:"enable"`
+	// LastScheduleTime the last time the policy was executed
+	LastScheduleTime *time.Time `gorm:"column:last_schedule_time" json:"last_schedule_time"`
+}
+
+// TableName -
+func (t *TenantServiceSnapshotPolicy) TableName() string {
+	return "tenant_services_snapshot_policies"
+}
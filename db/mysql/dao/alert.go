@@ -0,0 +1,176 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/goodrain/rainbond/api/util/bcode"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+// TenantAlertRuleDaoImpl -
+type TenantAlertRuleDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel create alert rule
+func (t *TenantAlertRuleDaoImpl) AddModel(mo model.Interface) error {
+	rule := mo.(*model.TenantAlertRule)
+	var old model.TenantAlertRule
+	if ok := t.DB.Where("tenant_id = ? and name = ?", rule.TenantID, rule.Name).Find(&old).RecordNotFound(); !ok {
+		return bcode.ErrAlertRuleNameExist
+	}
+	return t.DB.Create(rule).Error
+}
+
+// UpdateModel update alert rule
+func (t *TenantAlertRuleDaoImpl) UpdateModel(mo model.Interface) error {
+	rule := mo.(*model.TenantAlertRule)
+	return t.DB.Save(rule).Error
+}
+
+// GetByRuleID get alert rule by rule id
+func (t *TenantAlertRuleDaoImpl) GetByRuleID(ruleID string) (*model.TenantAlertRule, error) {
+	var rule model.TenantAlertRule
+	if err := t.DB.Where("rule_id = ?", ruleID).Find(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListByTenantID list alert rules of the tenant
+func (t *TenantAlertRuleDaoImpl) ListByTenantID(tenantID string) ([]*model.TenantAlertRule, error) {
+	var rules []*model.TenantAlertRule
+	if err := t.DB.Where("tenant_id = ?", tenantID).Order("ID desc").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ListByServiceID list alert rules bound to the component
+func (t *TenantAlertRuleDaoImpl) ListByServiceID(serviceID string) ([]*model.TenantAlertRule, error) {
+	var rules []*model.TenantAlertRule
+	if err := t.DB.Where("service_id = ?", serviceID).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// DeleteByRuleID delete alert rule by rule id
+func (t *TenantAlertRuleDaoImpl) DeleteByRuleID(ruleID string) error {
+	return t.DB.Where("rule_id = ?", ruleID).Delete(&model.TenantAlertRule{}).Error
+}
+
+// TenantAlertChannelDaoImpl -
+type TenantAlertChannelDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel create alert channel
+func (t *TenantAlertChannelDaoImpl) AddModel(mo model.Interface) error {
+	channel := mo.(*model.TenantAlertChannel)
+	var old model.TenantAlertChannel
+	if ok := t.DB.Where("tenant_id = ? and name = ?", channel.TenantID, channel.Name).Find(&old).RecordNotFound(); !ok {
+		return bcode.ErrAlertChannelNameExist
+	}
+	return t.DB.Create(channel).Error
+}
+
+// UpdateModel update alert channel
+func (t *TenantAlertChannelDaoImpl) UpdateModel(mo model.Interface) error {
+	channel := mo.(*model.TenantAlertChannel)
+	return t.DB.Save(channel).Error
+}
+
+// GetByChannelID get alert channel by channel id
+func (t *TenantAlertChannelDaoImpl) GetByChannelID(channelID string) (*model.TenantAlertChannel, error) {
+	var channel model.TenantAlertChannel
+	if err := t.DB.Where("channel_id = ?", channelID).Find(&channel).Error; err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// ListByTenantID list alert channels of the tenant
+func (t *TenantAlertChannelDaoImpl) ListByTenantID(tenantID string) ([]*model.TenantAlertChannel, error) {
+	var channels []*model.TenantAlertChannel
+	if err := t.DB.Where("tenant_id = ?", tenantID).Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// ListByChannelIDs list alert channels by channel ids
+func (t *TenantAlertChannelDaoImpl) ListByChannelIDs(channelIDs []string) ([]*model.TenantAlertChannel, error) {
+	var channels []*model.TenantAlertChannel
+	if len(channelIDs) == 0 {
+		return channels, nil
+	}
+	if err := t.DB.Where("channel_id in (?)", channelIDs).Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// DeleteByChannelID delete alert channel by channel id
+func (t *TenantAlertChannelDaoImpl) DeleteByChannelID(channelID string) error {
+	return t.DB.Where("channel_id = ?", channelID).Delete(&model.TenantAlertChannel{}).Error
+}
+
+// TenantAlertSilenceDaoImpl -
+type TenantAlertSilenceDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel create alert silence
+func (t *TenantAlertSilenceDaoImpl) AddModel(mo model.Interface) error {
+	silence := mo.(*model.TenantAlertSilence)
+	return t.DB.Create(silence).Error
+}
+
+// UpdateModel update alert silence
+func (t *TenantAlertSilenceDaoImpl) UpdateModel(mo model.Interface) error {
+	silence := mo.(*model.TenantAlertSilence)
+	return t.DB.Save(silence).Error
+}
+
+// ListByTenantID list alert silences of the tenant
+func (t *TenantAlertSilenceDaoImpl) ListByTenantID(tenantID string) ([]*model.TenantAlertSilence, error) {
+	var silences []*model.TenantAlertSilence
+	if err := t.DB.Where("tenant_id = ?", tenantID).Order("ends_at desc").Find(&silences).Error; err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// ListActiveByRuleID list the silences of the rule which cover the given time
+func (t *TenantAlertSilenceDaoImpl) ListActiveByRuleID(ruleID string, now time.Time) ([]*model.TenantAlertSilence, error) {
+	var silences []*model.TenantAlertSilence
+	if err := t.DB.Where("rule_id = ? and starts_at <= ? and ends_at > ?", ruleID, now, now).Find(&silences).Error; err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// DeleteBySilenceID delete alert silence by silence id
+func (t *TenantAlertSilenceDaoImpl) DeleteBySilenceID(silenceID string) error {
+	return t.DB.Where("silence_id = ?", silenceID).Delete(&model.TenantAlertSilence{}).Error
+}
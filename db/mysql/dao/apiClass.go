@@ -76,3 +76,51 @@ func (t *RegionAPIClassDaoImpl) DeletePrefixInClass(apiClass, prefix string) err
 	}
 	return nil
 }
+
+// APITokenDaoImpl -
+type APITokenDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel create api token
+func (t *APITokenDaoImpl) AddModel(mo model.Interface) error {
+	token := mo.(*model.APIToken)
+	return t.DB.Create(token).Error
+}
+
+// UpdateModel update api token
+func (t *APITokenDaoImpl) UpdateModel(mo model.Interface) error {
+	token := mo.(*model.APIToken)
+	return t.DB.Save(token).Error
+}
+
+// GetByTokenID get api token by token id
+func (t *APITokenDaoImpl) GetByTokenID(tokenID string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := t.DB.Where("token_id = ?", tokenID).Find(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByTokenHash get api token by the sha256 hash of the token
+func (t *APITokenDaoImpl) GetByTokenHash(hash string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := t.DB.Where("token_hash = ?", hash).Find(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListByTenantName list api tokens, all tokens are returned if tenantName is empty
+func (t *APITokenDaoImpl) ListByTenantName(tenantName string) ([]*model.APIToken, error) {
+	var tokens []*model.APIToken
+	db := t.DB
+	if tenantName != "" {
+		db = db.Where("tenant_name = ?", tenantName)
+	}
+	if err := db.Order("create_time desc").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -145,3 +145,45 @@ func (a *AppBackupDaoImpl) GetDeleteAppBackups() ([]*model.AppBackup, error) {
 	}
 	return apps, nil
 }
+
+// AppMigrationDaoImpl -
+type AppMigrationDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (a *AppMigrationDaoImpl) AddModel(mo model.Interface) error {
+	migration := mo.(*model.AppMigration)
+	var old model.AppMigration
+	if ok := a.DB.Where("migration_id = ?", migration.MigrationID).Find(&old).RecordNotFound(); ok {
+		return a.DB.Create(migration).Error
+	}
+	return fmt.Errorf("migration exist with id %s", migration.MigrationID)
+}
+
+// UpdateModel -
+func (a *AppMigrationDaoImpl) UpdateModel(mo model.Interface) error {
+	migration := mo.(*model.AppMigration)
+	if migration.ID == 0 {
+		return errors.New("Primary id can not be 0 when update")
+	}
+	return a.DB.Save(migration).Error
+}
+
+// GetByMigrationID -
+func (a *AppMigrationDaoImpl) GetByMigrationID(migrationID string) (*model.AppMigration, error) {
+	var migration model.AppMigration
+	if err := a.DB.Where("migration_id = ?", migrationID).Find(&migration).Error; err != nil {
+		return nil, err
+	}
+	return &migration, nil
+}
+
+// ListByGroupID lists the migrations of the app from newest to oldest
+func (a *AppMigrationDaoImpl) ListByGroupID(groupID string) ([]*model.AppMigration, error) {
+	var migrations []*model.AppMigration
+	if err := a.DB.Where("group_id = ?", groupID).Order("create_time desc").Find(&migrations).Error; err != nil {
+		return nil, err
+	}
+	return migrations, nil
+}
@@ -108,6 +108,15 @@ func (a *ApplicationDaoImpl) ListByAppIDs(appIDs []string) ([]*model.Application
 	return datas, nil
 }
 
+// ListByNetworkIsolation lists the apps in the network isolation mode
+func (a *ApplicationDaoImpl) ListByNetworkIsolation(mode string) ([]*model.Application, error) {
+	var datas []*model.Application
+	if err := a.DB.Where("network_isolation=?", mode).Find(&datas).Error; err != nil {
+		return nil, errors.Wrap(err, "list app by network isolation")
+	}
+	return datas, nil
+}
+
 // IsK8sAppDuplicate Verify whether the k8s app under the same team are duplicate
 func (a *ApplicationDaoImpl) IsK8sAppDuplicate(tenantID, AppID, k8sApp string) bool {
 	var count int64
@@ -0,0 +1,96 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dao
+
+import (
+	"fmt"
+
+	"github.com/goodrain/rainbond/db/dao"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+// AuditLogDaoImpl -
+type AuditLogDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel create audit log
+func (a *AuditLogDaoImpl) AddModel(mo model.Interface) error {
+	auditLog := mo.(*model.AuditLog)
+	return a.DB.Create(auditLog).Error
+}
+
+// UpdateModel the audit logs can not be updated
+func (a *AuditLogDaoImpl) UpdateModel(mo model.Interface) error {
+	return fmt.Errorf("audit log can not be updated")
+}
+
+// GetLastAuditLog get the last audit log with a row lock, so that the records are chained one by one
+func (a *AuditLogDaoImpl) GetLastAuditLog() (*model.AuditLog, error) {
+	var auditLog model.AuditLog
+	if err := a.DB.Set("gorm:query_option", "FOR UPDATE").Order("ID desc").Limit(1).Find(&auditLog).Error; err != nil {
+		return nil, err
+	}
+	return &auditLog, nil
+}
+
+// ListAuditLogs list audit logs by page, the latest first
+func (a *AuditLogDaoImpl) ListAuditLogs(query *dao.AuditLogQuery, page, pageSize int) ([]*model.AuditLog, int64, error) {
+	var auditLogs []*model.AuditLog
+	db := a.where(query)
+	var total int64
+	if err := db.Model(&model.AuditLog{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	if err := db.Order("ID desc").Limit(pageSize).Offset(offset).Find(&auditLogs).Error; err != nil {
+		return nil, 0, err
+	}
+	return auditLogs, total, nil
+}
+
+// ListAuditLogsAfterID list at most limit audit logs whose id is greater than the given id, the earliest first
+func (a *AuditLogDaoImpl) ListAuditLogsAfterID(query *dao.AuditLogQuery, id uint, limit int) ([]*model.AuditLog, error) {
+	var auditLogs []*model.AuditLog
+	if err := a.where(query).Where("ID > ?", id).Order("ID asc").Limit(limit).Find(&auditLogs).Error; err != nil {
+		return nil, err
+	}
+	return auditLogs, nil
+}
+
+func (a *AuditLogDaoImpl) where(query *dao.AuditLogQuery) *gorm.DB {
+	db := a.DB
+	if query == nil {
+		return db
+	}
+	if !query.Start.IsZero() {
+		db = db.Where("time >= ?", query.Start)
+	}
+	if !query.End.IsZero() {
+		db = db.Where("time < ?", query.End)
+	}
+	if query.Actor != "" {
+		db = db.Where("actor = ?", query.Actor)
+	}
+	if query.TenantName != "" {
+		db = db.Where("tenant_name = ?", query.TenantName)
+	}
+	return db
+}
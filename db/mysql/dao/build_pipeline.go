@@ -0,0 +1,131 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dao
+
+import (
+	dberr "github.com/goodrain/rainbond/db/errors"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+// TenantServiceBuildPipelineDaoImpl -
+type TenantServiceBuildPipelineDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceBuildPipelineDaoImpl) AddModel(mo model.Interface) error {
+	pipeline := mo.(*model.TenantServiceBuildPipeline)
+	var old model.TenantServiceBuildPipeline
+	if ok := t.DB.Where("service_id=?", pipeline.ServiceID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(pipeline).Error
+	}
+	return dberr.ErrRecordAlreadyExist
+}
+
+// UpdateModel -
+func (t *TenantServiceBuildPipelineDaoImpl) UpdateModel(mo model.Interface) error {
+	pipeline := mo.(*model.TenantServiceBuildPipeline)
+	return t.DB.Save(pipeline).Error
+}
+
+// GetByServiceID -
+func (t *TenantServiceBuildPipelineDaoImpl) GetByServiceID(serviceID string) (*model.TenantServiceBuildPipeline, error) {
+	var pipeline model.TenantServiceBuildPipeline
+	if err := t.DB.Where("service_id=?", serviceID).Find(&pipeline).Error; err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceBuildPipelineDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceBuildPipeline{}).Error
+}
+
+// TenantServicePipelineRunDaoImpl -
+type TenantServicePipelineRunDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServicePipelineRunDaoImpl) AddModel(mo model.Interface) error {
+	run := mo.(*model.TenantServicePipelineRun)
+	var old model.TenantServicePipelineRun
+	if ok := t.DB.Where("event_id=?", run.EventID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(run).Error
+	}
+	return dberr.ErrRecordAlreadyExist
+}
+
+// UpdateModel -
+func (t *TenantServicePipelineRunDaoImpl) UpdateModel(mo model.Interface) error {
+	run := mo.(*model.TenantServicePipelineRun)
+	return t.DB.Save(run).Error
+}
+
+// GetByEventID -
+func (t *TenantServicePipelineRunDaoImpl) GetByEventID(eventID string) (*model.TenantServicePipelineRun, error) {
+	var run model.TenantServicePipelineRun
+	if err := t.DB.Where("event_id=?", eventID).Find(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListByServiceID lists the latest runs of the component from newest to oldest
+func (t *TenantServicePipelineRunDaoImpl) ListByServiceID(serviceID string, limit int) ([]*model.TenantServicePipelineRun, error) {
+	var runs []*model.TenantServicePipelineRun
+	if err := t.DB.Where("service_id=?", serviceID).Order("create_time desc").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// ListByServiceIDAndStatus -
+func (t *TenantServicePipelineRunDaoImpl) ListByServiceIDAndStatus(serviceID, status string) ([]*model.TenantServicePipelineRun, error) {
+	var runs []*model.TenantServicePipelineRun
+	if err := t.DB.Where("service_id=? and status=?", serviceID, status).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// GetLatestByDeployVersion returns the latest run which built the deploy version of the component
+func (t *TenantServicePipelineRunDaoImpl) GetLatestByDeployVersion(serviceID, deployVersion string) (*model.TenantServicePipelineRun, error) {
+	var run model.TenantServicePipelineRun
+	if err := t.DB.Where("service_id=? and deploy_version=?", serviceID, deployVersion).Order("create_time desc").Limit(1).Find(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// UpdateStatusIfMatch updates the status of the run only if it is still oldStatus, so that only one caller claims the run
+func (t *TenantServicePipelineRunDaoImpl) UpdateStatusIfMatch(eventID, oldStatus, newStatus string) (bool, error) {
+	res := t.DB.Model(&model.TenantServicePipelineRun{}).Where("event_id=? and status=?", eventID, oldStatus).Update("status", newStatus)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// DeleteByServiceID -
+func (t *TenantServicePipelineRunDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServicePipelineRun{}).Error
+}
@@ -233,3 +233,39 @@ func (t *ServiceSourceImpl) GetServiceSource(serviceID string) ([]*model.Service
 	}
 	return serviceSources, nil
 }
+
+//GetServiceSourceByType get the service source of the type, nil if not found
+func (t *ServiceSourceImpl) GetServiceSourceByType(serviceID, sourceType string) (*model.ServiceSourceConfig, error) {
+	var serviceSource model.ServiceSourceConfig
+	if err := t.DB.Where("service_id=? and source_type=?", serviceID, sourceType).Find(&serviceSource).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &serviceSource, nil
+}
+
+//ListBySourceType list the service sources of the type
+func (t *ServiceSourceImpl) ListBySourceType(sourceType string) ([]*model.ServiceSourceConfig, error) {
+	var serviceSources []*model.ServiceSourceConfig
+	if err := t.DB.Where("source_type=?", sourceType).Find(&serviceSources).Error; err != nil {
+		return nil, err
+	}
+	return serviceSources, nil
+}
+
+//DeleteByServiceIDAndType delete the service source of the type
+func (t *ServiceSourceImpl) DeleteByServiceIDAndType(serviceID, sourceType string) error {
+	return t.DB.Where("service_id=? and source_type=?", serviceID, sourceType).Delete(&model.ServiceSourceConfig{}).Error
+}
+
+//UpdateSourceBodyIfUnchanged updates the body of the service source only if it is still the old one,
+//it returns false if the body is changed by others
+func (t *ServiceSourceImpl) UpdateSourceBodyIfUnchanged(id uint, oldBody, newBody string) (bool, error) {
+	res := t.DB.Model(&model.ServiceSourceConfig{}).Where("ID=? and source_body=?", id, oldBody).Update("source_body", newBody)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
@@ -0,0 +1,86 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dao
+
+import (
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+// TenantQuotaDaoImpl -
+type TenantQuotaDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel create tenant quota
+func (t *TenantQuotaDaoImpl) AddModel(mo model.Interface) error {
+	quota := mo.(*model.TenantQuota)
+	return t.DB.Create(quota).Error
+}
+
+// UpdateModel update tenant quota
+func (t *TenantQuotaDaoImpl) UpdateModel(mo model.Interface) error {
+	quota := mo.(*model.TenantQuota)
+	return t.DB.Save(quota).Error
+}
+
+// GetByTenantID get the quota of the tenant
+func (t *TenantQuotaDaoImpl) GetByTenantID(tenantID string) (*model.TenantQuota, error) {
+	var quota model.TenantQuota
+	if err := t.DB.Where("tenant_id = ?", tenantID).Find(&quota).Error; err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// DeleteByTenantID delete the quota of the tenant
+func (t *TenantQuotaDaoImpl) DeleteByTenantID(tenantID string) error {
+	return t.DB.Where("tenant_id = ?", tenantID).Delete(&model.TenantQuota{}).Error
+}
+
+// TenantStorageClassQuotaDaoImpl -
+type TenantStorageClassQuotaDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel create storage class quota
+func (t *TenantStorageClassQuotaDaoImpl) AddModel(mo model.Interface) error {
+	quota := mo.(*model.TenantStorageClassQuota)
+	return t.DB.Create(quota).Error
+}
+
+// UpdateModel update storage class quota
+func (t *TenantStorageClassQuotaDaoImpl) UpdateModel(mo model.Interface) error {
+	quota := mo.(*model.TenantStorageClassQuota)
+	return t.DB.Save(quota).Error
+}
+
+// ListByTenantID list the storage class quotas of the tenant
+func (t *TenantStorageClassQuotaDaoImpl) ListByTenantID(tenantID string) ([]*model.TenantStorageClassQuota, error) {
+	var quotas []*model.TenantStorageClassQuota
+	if err := t.DB.Where("tenant_id = ?", tenantID).Order("storage_class").Find(&quotas).Error; err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+// DeleteByTenantID delete the storage class quotas of the tenant
+func (t *TenantStorageClassQuotaDaoImpl) DeleteByTenantID(tenantID string) error {
+	return t.DB.Where("tenant_id = ?", tenantID).Delete(&model.TenantStorageClassQuota{}).Error
+}
@@ -995,6 +995,15 @@ func (t *TenantServiceRelationDaoImpl) ListByServiceIDs(serviceIDs []string) ([]
 	return relations, nil
 }
 
+// ListByDependServiceIDs lists the relations of the components depending on the given components
+func (t *TenantServiceRelationDaoImpl) ListByDependServiceIDs(dependServiceIDs []string) ([]*model.TenantServiceRelation, error) {
+	var relations []*model.TenantServiceRelation
+	if err := t.DB.Where("dep_service_id in (?)", dependServiceIDs).Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	return relations, nil
+}
+
 // HaveRelations 是否有依赖
 func (t *TenantServiceRelationDaoImpl) HaveRelations(serviceID string) bool {
 	var oldRelation []*model.TenantServiceRelation
@@ -1050,13 +1059,17 @@ func (t *TenantServiceEnvVarDaoImpl) AddModel(mo model.Interface) error {
 	return nil
 }
 
-// UpdateModel update env support attr_value\is_change\scope
+// UpdateModel update env support attr_value\is_change\scope and the external secret reference
 func (t *TenantServiceEnvVarDaoImpl) UpdateModel(mo model.Interface) error {
 	env := mo.(*model.TenantServiceEnvVar)
 	return t.DB.Table(env.TableName()).Where("service_id=? and attr_name = ?", env.ServiceID, env.AttrName).Update(map[string]interface{}{
-		"attr_value": env.AttrValue,
-		"is_change":  env.IsChange,
-		"scope":      env.Scope,
+		"attr_value":          env.AttrValue,
+		"is_change":           env.IsChange,
+		"scope":               env.Scope,
+		"secret_path":         env.SecretPath,
+		"secret_key":          env.SecretKey,
+		"secret_version":      env.SecretVersion,
+		"restart_on_rotation": env.RestartOnRotation,
 	}).Error
 }
 
@@ -1491,7 +1504,13 @@ func (t *TenantServiceConfigFileDaoImpl) UpdateModel(mo model.Interface) error {
 	}
 	return t.DB.Table(configFile.TableName()).
 		Where("service_id=? and volume_name=?", configFile.ServiceID, configFile.VolumeName).
-		Update(configFile).Error
+		Update(map[string]interface{}{
+			"file_content":        configFile.FileContent,
+			"secret_path":         configFile.SecretPath,
+			"secret_key":          configFile.SecretKey,
+			"secret_version":      configFile.SecretVersion,
+			"restart_on_rotation": configFile.RestartOnRotation,
+		}).Error
 }
 
 // GetConfigFileByServiceID -
@@ -2076,6 +2095,9 @@ func (t *TenantServceAutoscalerRuleMetricsDaoImpl) UpdateOrCreate(metric *model.
 	} else {
 		old.MetricTargetType = metric.MetricTargetType
 		old.MetricTargetValue = metric.MetricTargetValue
+		old.MetricSelector = metric.MetricSelector
+		old.DescribedObjectKind = metric.DescribedObjectKind
+		old.DescribedObjectName = metric.DescribedObjectName
 		if err := t.DB.Save(&old).Error; err != nil {
 			return err
 		}
@@ -2197,6 +2219,196 @@ func (t *TenantServiceScalingRecordsDaoImpl) CountByServiceID(serviceID string)
 	return count, nil
 }
 
+// TenantServiceScalingScheduleDaoImpl -
+type TenantServiceScalingScheduleDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceScalingScheduleDaoImpl) AddModel(mo model.Interface) error {
+	schedule := mo.(*model.TenantServiceScalingSchedule)
+	var old model.TenantServiceScalingSchedule
+	if ok := t.DB.Where("schedule_id=?", schedule.ScheduleID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(schedule).Error
+	}
+	return dberr.ErrRecordAlreadyExist
+}
+
+// UpdateModel -
+func (t *TenantServiceScalingScheduleDaoImpl) UpdateModel(mo model.Interface) error {
+	schedule := mo.(*model.TenantServiceScalingSchedule)
+	return t.DB.Save(schedule).Error
+}
+
+// GetByScheduleID -
+func (t *TenantServiceScalingScheduleDaoImpl) GetByScheduleID(scheduleID string) (*model.TenantServiceScalingSchedule, error) {
+	var schedule model.TenantServiceScalingSchedule
+	if err := t.DB.Where("schedule_id=?", scheduleID).Find(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ListByServiceID -
+func (t *TenantServiceScalingScheduleDaoImpl) ListByServiceID(serviceID string) ([]*model.TenantServiceScalingSchedule, error) {
+	var schedules []*model.TenantServiceScalingSchedule
+	if err := t.DB.Where("service_id=?", serviceID).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ListEnable -
+func (t *TenantServiceScalingScheduleDaoImpl) ListEnable() ([]*model.TenantServiceScalingSchedule, error) {
+	var schedules []*model.TenantServiceScalingSchedule
+	if err := t.DB.Where("enable=?", true).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// DeleteByScheduleID -
+func (t *TenantServiceScalingScheduleDaoImpl) DeleteByScheduleID(scheduleID string) error {
+	return t.DB.Where("schedule_id=?", scheduleID).Delete(&model.TenantServiceScalingSchedule{}).Error
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceScalingScheduleDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceScalingSchedule{}).Error
+}
+
+// TenantServiceScaleToZeroDaoImpl -
+type TenantServiceScaleToZeroDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceScaleToZeroDaoImpl) AddModel(mo model.Interface) error {
+	stz := mo.(*model.TenantServiceScaleToZero)
+	var old model.TenantServiceScaleToZero
+	if ok := t.DB.Where("service_id=?", stz.ServiceID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(stz).Error
+	}
+	return dberr.ErrRecordAlreadyExist
+}
+
+// UpdateModel -
+func (t *TenantServiceScaleToZeroDaoImpl) UpdateModel(mo model.Interface) error {
+	stz := mo.(*model.TenantServiceScaleToZero)
+	return t.DB.Save(stz).Error
+}
+
+// GetByServiceID -
+func (t *TenantServiceScaleToZeroDaoImpl) GetByServiceID(serviceID string) (*model.TenantServiceScaleToZero, error) {
+	var stz model.TenantServiceScaleToZero
+	if err := t.DB.Where("service_id=?", serviceID).Find(&stz).Error; err != nil {
+		return nil, err
+	}
+	return &stz, nil
+}
+
+// ListEnable -
+func (t *TenantServiceScaleToZeroDaoImpl) ListEnable() ([]*model.TenantServiceScaleToZero, error) {
+	var stzs []*model.TenantServiceScaleToZero
+	if err := t.DB.Where("enable=?", true).Find(&stzs).Error; err != nil {
+		return nil, err
+	}
+	return stzs, nil
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceScaleToZeroDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceScaleToZero{}).Error
+}
+
+// TenantServiceResourceRecommendationDaoImpl -
+type TenantServiceResourceRecommendationDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceResourceRecommendationDaoImpl) AddModel(mo model.Interface) error {
+	rec := mo.(*model.TenantServiceResourceRecommendation)
+	var old model.TenantServiceResourceRecommendation
+	if ok := t.DB.Where("service_id=?", rec.ServiceID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(rec).Error
+	}
+	return dberr.ErrRecordAlreadyExist
+}
+
+// UpdateModel -
+func (t *TenantServiceResourceRecommendationDaoImpl) UpdateModel(mo model.Interface) error {
+	rec := mo.(*model.TenantServiceResourceRecommendation)
+	return t.DB.Save(rec).Error
+}
+
+// GetByServiceID -
+func (t *TenantServiceResourceRecommendationDaoImpl) GetByServiceID(serviceID string) (*model.TenantServiceResourceRecommendation, error) {
+	var rec model.TenantServiceResourceRecommendation
+	if err := t.DB.Where("service_id=?", serviceID).Find(&rec).Error; err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// ListByTenantID -
+func (t *TenantServiceResourceRecommendationDaoImpl) ListByTenantID(tenantID string) ([]*model.TenantServiceResourceRecommendation, error) {
+	var recs []*model.TenantServiceResourceRecommendation
+	if err := t.DB.Where("tenant_id=?", tenantID).Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceResourceRecommendationDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceResourceRecommendation{}).Error
+}
+
+// TenantServiceRecommendationPolicyDaoImpl -
+type TenantServiceRecommendationPolicyDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceRecommendationPolicyDaoImpl) AddModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceRecommendationPolicy)
+	var old model.TenantServiceRecommendationPolicy
+	if ok := t.DB.Where("service_id=?", policy.ServiceID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(policy).Error
+	}
+	return dberr.ErrRecordAlreadyExist
+}
+
+// UpdateModel -
+func (t *TenantServiceRecommendationPolicyDaoImpl) UpdateModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceRecommendationPolicy)
+	return t.DB.Save(policy).Error
+}
+
+// GetByServiceID -
+func (t *TenantServiceRecommendationPolicyDaoImpl) GetByServiceID(serviceID string) (*model.TenantServiceRecommendationPolicy, error) {
+	var policy model.TenantServiceRecommendationPolicy
+	if err := t.DB.Where("service_id=?", serviceID).Find(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListAutoApply -
+func (t *TenantServiceRecommendationPolicyDaoImpl) ListAutoApply() ([]*model.TenantServiceRecommendationPolicy, error) {
+	var policies []*model.TenantServiceRecommendationPolicy
+	if err := t.DB.Where("auto_apply=?", true).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceRecommendationPolicyDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceRecommendationPolicy{}).Error
+}
+
 // ComponentK8sAttributeDaoImpl The K8s attribute value of the component
 type ComponentK8sAttributeDaoImpl struct {
 	DB *gorm.DB
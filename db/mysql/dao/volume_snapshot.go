@@ -0,0 +1,158 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dao
+
+import (
+	dberr "github.com/goodrain/rainbond/db/errors"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/jinzhu/gorm"
+)
+
+// TenantServiceVolumeSnapshotDaoImpl -
+type TenantServiceVolumeSnapshotDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceVolumeSnapshotDaoImpl) AddModel(mo model.Interface) error {
+	snapshot := mo.(*model.TenantServiceVolumeSnapshot)
+	var old model.TenantServiceVolumeSnapshot
+	if ok := t.DB.Where("snapshot_id=?", snapshot.SnapshotID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(snapshot).Error
+	}
+	return dberr.ErrRecordAlreadyExist
+}
+
+// UpdateModel -
+func (t *TenantServiceVolumeSnapshotDaoImpl) UpdateModel(mo model.Interface) error {
+	snapshot := mo.(*model.TenantServiceVolumeSnapshot)
+	return t.DB.Save(snapshot).Error
+}
+
+// GetBySnapshotID -
+func (t *TenantServiceVolumeSnapshotDaoImpl) GetBySnapshotID(snapshotID string) (*model.TenantServiceVolumeSnapshot, error) {
+	var snapshot model.TenantServiceVolumeSnapshot
+	if err := t.DB.Where("snapshot_id=?", snapshotID).Find(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ListByServiceID lists the snapshots of the component from newest to oldest, volumeName is optional
+func (t *TenantServiceVolumeSnapshotDaoImpl) ListByServiceID(serviceID, volumeName string) ([]*model.TenantServiceVolumeSnapshot, error) {
+	var snapshots []*model.TenantServiceVolumeSnapshot
+	db := t.DB.Where("service_id=?", serviceID)
+	if volumeName != "" {
+		db = db.Where("volume_name=?", volumeName)
+	}
+	if err := db.Order("create_time desc").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// ListByPolicyID lists the snapshots taken by the policy from newest to oldest, volumeName is optional
+func (t *TenantServiceVolumeSnapshotDaoImpl) ListByPolicyID(policyID, volumeName string) ([]*model.TenantServiceVolumeSnapshot, error) {
+	var snapshots []*model.TenantServiceVolumeSnapshot
+	db := t.DB.Where("policy_id=?", policyID)
+	if volumeName != "" {
+		db = db.Where("volume_name=?", volumeName)
+	}
+	if err := db.Order("create_time desc").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// ListByStatus -
+func (t *TenantServiceVolumeSnapshotDaoImpl) ListByStatus(status ...string) ([]*model.TenantServiceVolumeSnapshot, error) {
+	var snapshots []*model.TenantServiceVolumeSnapshot
+	if err := t.DB.Where("status in (?)", status).Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// DeleteBySnapshotID -
+func (t *TenantServiceVolumeSnapshotDaoImpl) DeleteBySnapshotID(snapshotID string) error {
+	return t.DB.Where("snapshot_id=?", snapshotID).Delete(&model.TenantServiceVolumeSnapshot{}).Error
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceVolumeSnapshotDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceVolumeSnapshot{}).Error
+}
+
+// TenantServiceSnapshotPolicyDaoImpl -
+type TenantServiceSnapshotPolicyDaoImpl struct {
+	DB *gorm.DB
+}
+
+// AddModel -
+func (t *TenantServiceSnapshotPolicyDaoImpl) AddModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceSnapshotPolicy)
+	var old model.TenantServiceSnapshotPolicy
+	if ok := t.DB.Where("policy_id=?", policy.PolicyID).Find(&old).RecordNotFound(); ok {
+		return t.DB.Create(policy).Error
+	}
+	return dberr.ErrRecordAlreadyExist
+}
+
+// UpdateModel -
+func (t *TenantServiceSnapshotPolicyDaoImpl) UpdateModel(mo model.Interface) error {
+	policy := mo.(*model.TenantServiceSnapshotPolicy)
+	return t.DB.Save(policy).Error
+}
+
+// GetByPolicyID -
+func (t *TenantServiceSnapshotPolicyDaoImpl) GetByPolicyID(policyID string) (*model.TenantServiceSnapshotPolicy, error) {
+	var policy model.TenantServiceSnapshotPolicy
+	if err := t.DB.Where("policy_id=?", policyID).Find(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ListByServiceID -
+func (t *TenantServiceSnapshotPolicyDaoImpl) ListByServiceID(serviceID string) ([]*model.TenantServiceSnapshotPolicy, error) {
+	var policies []*model.TenantServiceSnapshotPolicy
+	if err := t.DB.Where("service_id=?", serviceID).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// ListEnable -
+func (t *TenantServiceSnapshotPolicyDaoImpl) ListEnable() ([]*model.TenantServiceSnapshotPolicy, error) {
+	var policies []*model.TenantServiceSnapshotPolicy
+	if err := t.DB.Where("enable=?", true).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// DeleteByPolicyID -
+func (t *TenantServiceSnapshotPolicyDaoImpl) DeleteByPolicyID(policyID string) error {
+	return t.DB.Where("policy_id=?", policyID).Delete(&model.TenantServiceSnapshotPolicy{}).Error
+}
+
+// DeleteByServiceID -
+func (t *TenantServiceSnapshotPolicyDaoImpl) DeleteByServiceID(serviceID string) error {
+	return t.DB.Where("service_id=?", serviceID).Delete(&model.TenantServiceSnapshotPolicy{}).Error
+}
@@ -60,6 +60,34 @@ func (m *Manager) TenantDaoTransactions(db *gorm.DB) dao.TenantDao {
 	}
 }
 
+// TenantQuotaDao -
+func (m *Manager) TenantQuotaDao() dao.TenantQuotaDao {
+	return &mysqldao.TenantQuotaDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantQuotaDaoTransactions -
+func (m *Manager) TenantQuotaDaoTransactions(db *gorm.DB) dao.TenantQuotaDao {
+	return &mysqldao.TenantQuotaDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantStorageClassQuotaDao -
+func (m *Manager) TenantStorageClassQuotaDao() dao.TenantStorageClassQuotaDao {
+	return &mysqldao.TenantStorageClassQuotaDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantStorageClassQuotaDaoTransactions -
+func (m *Manager) TenantStorageClassQuotaDaoTransactions(db *gorm.DB) dao.TenantStorageClassQuotaDao {
+	return &mysqldao.TenantStorageClassQuotaDaoImpl{
+		DB: db,
+	}
+}
+
 //TenantServiceDao TenantServiceDao
 func (m *Manager) TenantServiceDao() dao.TenantServiceDao {
 	return &mysqldao.TenantServicesDaoImpl{
@@ -389,6 +417,27 @@ func (m *Manager) RegionAPIClassDaoTransactions(db *gorm.DB) dao.RegionAPIClassD
 	}
 }
 
+// APITokenDao -
+func (m *Manager) APITokenDao() dao.APITokenDao {
+	return &mysqldao.APITokenDaoImpl{
+		DB: m.db,
+	}
+}
+
+// AuditLogDao -
+func (m *Manager) AuditLogDao() dao.AuditLogDao {
+	return &mysqldao.AuditLogDaoImpl{
+		DB: m.db,
+	}
+}
+
+// AuditLogDaoTransactions -
+func (m *Manager) AuditLogDaoTransactions(db *gorm.DB) dao.AuditLogDao {
+	return &mysqldao.AuditLogDaoImpl{
+		DB: db,
+	}
+}
+
 //NotificationEventDao NotificationEventDao
 func (m *Manager) NotificationEventDao() dao.NotificationEventDao {
 	return &mysqldao.NotificationEventDaoImpl{
@@ -473,6 +522,20 @@ func (m *Manager) AppBackupDaoTransactions(db *gorm.DB) dao.AppBackupDao {
 	}
 }
 
+// AppMigrationDao -
+func (m *Manager) AppMigrationDao() dao.AppMigrationDao {
+	return &mysqldao.AppMigrationDaoImpl{
+		DB: m.db,
+	}
+}
+
+// AppMigrationDaoTransactions -
+func (m *Manager) AppMigrationDaoTransactions(db *gorm.DB) dao.AppMigrationDao {
+	return &mysqldao.AppMigrationDaoImpl{
+		DB: db,
+	}
+}
+
 //ServiceSourceDao service source db impl
 func (m *Manager) ServiceSourceDao() dao.ServiceSourceDao {
 	return &mysqldao.ServiceSourceImpl{
@@ -634,6 +697,118 @@ func (m *Manager) TenantServiceScalingRecordsDaoTransactions(db *gorm.DB) dao.Te
 	}
 }
 
+// TenantServiceScalingScheduleDao -
+func (m *Manager) TenantServiceScalingScheduleDao() dao.TenantServiceScalingScheduleDao {
+	return &mysqldao.TenantServiceScalingScheduleDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceScalingScheduleDaoTransactions -
+func (m *Manager) TenantServiceScalingScheduleDaoTransactions(db *gorm.DB) dao.TenantServiceScalingScheduleDao {
+	return &mysqldao.TenantServiceScalingScheduleDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceScaleToZeroDao -
+func (m *Manager) TenantServiceScaleToZeroDao() dao.TenantServiceScaleToZeroDao {
+	return &mysqldao.TenantServiceScaleToZeroDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceScaleToZeroDaoTransactions -
+func (m *Manager) TenantServiceScaleToZeroDaoTransactions(db *gorm.DB) dao.TenantServiceScaleToZeroDao {
+	return &mysqldao.TenantServiceScaleToZeroDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceResourceRecommendationDao -
+func (m *Manager) TenantServiceResourceRecommendationDao() dao.TenantServiceResourceRecommendationDao {
+	return &mysqldao.TenantServiceResourceRecommendationDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceResourceRecommendationDaoTransactions -
+func (m *Manager) TenantServiceResourceRecommendationDaoTransactions(db *gorm.DB) dao.TenantServiceResourceRecommendationDao {
+	return &mysqldao.TenantServiceResourceRecommendationDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceRecommendationPolicyDao -
+func (m *Manager) TenantServiceRecommendationPolicyDao() dao.TenantServiceRecommendationPolicyDao {
+	return &mysqldao.TenantServiceRecommendationPolicyDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceRecommendationPolicyDaoTransactions -
+func (m *Manager) TenantServiceRecommendationPolicyDaoTransactions(db *gorm.DB) dao.TenantServiceRecommendationPolicyDao {
+	return &mysqldao.TenantServiceRecommendationPolicyDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceVolumeSnapshotDao -
+func (m *Manager) TenantServiceVolumeSnapshotDao() dao.TenantServiceVolumeSnapshotDao {
+	return &mysqldao.TenantServiceVolumeSnapshotDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceVolumeSnapshotDaoTransactions -
+func (m *Manager) TenantServiceVolumeSnapshotDaoTransactions(db *gorm.DB) dao.TenantServiceVolumeSnapshotDao {
+	return &mysqldao.TenantServiceVolumeSnapshotDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceSnapshotPolicyDao -
+func (m *Manager) TenantServiceSnapshotPolicyDao() dao.TenantServiceSnapshotPolicyDao {
+	return &mysqldao.TenantServiceSnapshotPolicyDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceSnapshotPolicyDaoTransactions -
+func (m *Manager) TenantServiceSnapshotPolicyDaoTransactions(db *gorm.DB) dao.TenantServiceSnapshotPolicyDao {
+	return &mysqldao.TenantServiceSnapshotPolicyDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServiceBuildPipelineDao -
+func (m *Manager) TenantServiceBuildPipelineDao() dao.TenantServiceBuildPipelineDao {
+	return &mysqldao.TenantServiceBuildPipelineDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServiceBuildPipelineDaoTransactions -
+func (m *Manager) TenantServiceBuildPipelineDaoTransactions(db *gorm.DB) dao.TenantServiceBuildPipelineDao {
+	return &mysqldao.TenantServiceBuildPipelineDaoImpl{
+		DB: db,
+	}
+}
+
+// TenantServicePipelineRunDao -
+func (m *Manager) TenantServicePipelineRunDao() dao.TenantServicePipelineRunDao {
+	return &mysqldao.TenantServicePipelineRunDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantServicePipelineRunDaoTransactions -
+func (m *Manager) TenantServicePipelineRunDaoTransactions(db *gorm.DB) dao.TenantServicePipelineRunDao {
+	return &mysqldao.TenantServicePipelineRunDaoImpl{
+		DB: db,
+	}
+}
+
 //TenantServiceMonitorDao monitor dao
 func (m *Manager) TenantServiceMonitorDao() dao.TenantServiceMonitorDao {
 	return &mysqldao.TenantServiceMonitorDaoImpl{
@@ -675,3 +850,24 @@ func (m *Manager) K8sResourceDaoTransactions(db *gorm.DB) dao.K8sResourceDao {
 		DB: db,
 	}
 }
+
+// TenantAlertRuleDao -
+func (m *Manager) TenantAlertRuleDao() dao.TenantAlertRuleDao {
+	return &mysqldao.TenantAlertRuleDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantAlertChannelDao -
+func (m *Manager) TenantAlertChannelDao() dao.TenantAlertChannelDao {
+	return &mysqldao.TenantAlertChannelDaoImpl{
+		DB: m.db,
+	}
+}
+
+// TenantAlertSilenceDao -
+func (m *Manager) TenantAlertSilenceDao() dao.TenantAlertSilenceDao {
+	return &mysqldao.TenantAlertSilenceDaoImpl{
+		DB: m.db,
+	}
+}
@@ -124,6 +124,8 @@ func (m *Manager) Print(v ...interface{}) {
 //RegisterTableModel register table model
 func (m *Manager) RegisterTableModel() {
 	m.models = append(m.models, &model.Tenants{})
+	m.models = append(m.models, &model.TenantQuota{})
+	m.models = append(m.models, &model.TenantStorageClassQuota{})
 	m.models = append(m.models, &model.TenantServices{})
 	m.models = append(m.models, &model.TenantServicesPort{})
 	m.models = append(m.models, &model.TenantServiceRelation{})
@@ -146,11 +148,14 @@ func (m *Manager) RegisterTableModel() {
 	m.models = append(m.models, &model.RegionUserInfo{})
 	m.models = append(m.models, &model.TenantServicesStreamPluginPort{})
 	m.models = append(m.models, &model.RegionAPIClass{})
+	m.models = append(m.models, &model.APIToken{})
+	m.models = append(m.models, &model.AuditLog{})
 	m.models = append(m.models, &model.RegionProcotols{})
 	m.models = append(m.models, &model.LocalScheduler{})
 	m.models = append(m.models, &model.NotificationEvent{})
 	m.models = append(m.models, &model.AppStatus{})
 	m.models = append(m.models, &model.AppBackup{})
+	m.models = append(m.models, &model.AppMigration{})
 	m.models = append(m.models, &model.ServiceSourceConfig{})
 	m.models = append(m.models, &model.Application{})
 	m.models = append(m.models, &model.ApplicationConfigGroup{})
@@ -173,9 +178,20 @@ func (m *Manager) RegisterTableModel() {
 	m.models = append(m.models, &model.TenantServiceAutoscalerRules{})
 	m.models = append(m.models, &model.TenantServiceAutoscalerRuleMetrics{})
 	m.models = append(m.models, &model.TenantServiceScalingRecords{})
+	m.models = append(m.models, &model.TenantServiceScalingSchedule{})
+	m.models = append(m.models, &model.TenantServiceScaleToZero{})
+	m.models = append(m.models, &model.TenantServiceResourceRecommendation{})
+	m.models = append(m.models, &model.TenantServiceRecommendationPolicy{})
+	m.models = append(m.models, &model.TenantServiceVolumeSnapshot{})
+	m.models = append(m.models, &model.TenantServiceSnapshotPolicy{})
+	m.models = append(m.models, &model.TenantServiceBuildPipeline{})
+	m.models = append(m.models, &model.TenantServicePipelineRun{})
 	m.models = append(m.models, &model.TenantServiceMonitor{})
 	m.models = append(m.models, &model.ComponentK8sAttributes{})
 	m.models = append(m.models, &model.K8sResource{})
+	m.models = append(m.models, &model.TenantAlertRule{})
+	m.models = append(m.models, &model.TenantAlertChannel{})
+	m.models = append(m.models, &model.TenantAlertSilence{})
 }
 
 //CheckTable check and create tables
@@ -0,0 +1,201 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package activator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodrain/rainbond/gateway/annotations/scaletozero"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Header carries the namespace/name/port of the kubernetes service of the backend scaled to zero,
+// the gateway sets it on the locations of the scale-to-zero ingresses. The port is the service
+// port of the ingress backend, either its number or its name.
+const Header = "X-Rainbond-Wake"
+
+// EndpointsGetter gets endpoints from the local cache
+type EndpointsGetter interface {
+	GetEndpoints(namespace, name string) (*corev1.Endpoints, error)
+}
+
+// Activator holds the requests of the backends scaled to zero, wakes them up and
+// forwards the requests once the backends are ready.
+type Activator struct {
+	client    kubernetes.Interface
+	endpoints EndpointsGetter
+	timeout   time.Duration
+	interval  time.Duration
+	// throttle the wake-up of the same backend
+	wakeInterval time.Duration
+	lock         sync.Mutex
+	woken        map[string]time.Time
+}
+
+// New creates an activator
+func New(client kubernetes.Interface, endpoints EndpointsGetter, timeout time.Duration) *Activator {
+	return &Activator{
+		client:       client,
+		endpoints:    endpoints,
+		timeout:      timeout,
+		interval:     500 * time.Millisecond,
+		wakeInterval: 10 * time.Second,
+		woken:        make(map[string]time.Time),
+	}
+}
+
+// ListenAndServe listens on the port of the activator
+func (a *Activator) ListenAndServe(port int) error {
+	server := &http.Server{
+		Addr:    net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		Handler: a,
+	}
+	logrus.Infof("activator listen on %s", server.Addr)
+	return server.ListenAndServe()
+}
+
+// ServeHTTP holds the request until the backend is ready
+func (a *Activator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	namespace, name, port, err := parseHeader(r.Header.Get(Header))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	// the header is internal to the gateway, never pass it to the backend
+	r.Header.Del(Header)
+
+	ctx, cancel := context.WithTimeout(r.Context(), a.timeout)
+	defer cancel()
+	portName := a.portName(ctx, namespace, name, port)
+	target := a.readyAddress(namespace, name, portName)
+	if target == "" {
+		a.wakeUp(ctx, namespace, name)
+		target = a.waitReady(ctx, namespace, name, portName)
+	}
+	if target == "" {
+		http.Error(w, "the component is waking up, please try again later", http.StatusServiceUnavailable)
+		return
+	}
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = "http"
+			req.URL.Host = target
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+func parseHeader(value string) (string, string, string, error) {
+	s := strings.Split(value, "/")
+	if len(s) < 2 || len(s) > 3 || s[0] == "" || s[1] == "" {
+		return "", "", "", fmt.Errorf("invalid %s header %q", Header, value)
+	}
+	var port string
+	if len(s) == 3 {
+		port = s[2]
+	}
+	return s[0], s[1], port, nil
+}
+
+// portName returns the name of the service port, which is the name of the endpoints port as well.
+// The empty name matches the only port of the service.
+func (a *Activator) portName(ctx context.Context, namespace, name, port string) string {
+	number, err := strconv.Atoi(port)
+	if err != nil {
+		return port
+	}
+	svc, err := a.client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		logrus.Warningf("get service %s/%s: %v", namespace, name, err)
+		return ""
+	}
+	for _, p := range svc.Spec.Ports {
+		if int(p.Port) == number {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+func (a *Activator) readyAddress(namespace, name, portName string) string {
+	ep, err := a.endpoints.GetEndpoints(namespace, name)
+	if err != nil || ep == nil {
+		return ""
+	}
+	for _, subset := range ep.Subsets {
+		if len(subset.Addresses) == 0 {
+			continue
+		}
+		for _, port := range subset.Ports {
+			if port.Name == portName || (portName == "" && len(subset.Ports) == 1) {
+				return net.JoinHostPort(subset.Addresses[0].IP, strconv.Itoa(int(port.Port)))
+			}
+		}
+	}
+	return ""
+}
+
+func (a *Activator) waitReady(ctx context.Context, namespace, name, portName string) string {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ""
+		case <-ticker.C:
+			if target := a.readyAddress(namespace, name, portName); target != "" {
+				return target
+			}
+			a.wakeUp(ctx, namespace, name)
+		}
+	}
+}
+
+// wakeUp patches the wake-up annotation on the kubernetes service, the worker scales the component up.
+func (a *Activator) wakeUp(ctx context.Context, namespace, name string) {
+	key := namespace + "/" + name
+	now := time.Now()
+	a.lock.Lock()
+	if last, ok := a.woken[key]; ok && now.Sub(last) < a.wakeInterval {
+		a.lock.Unlock()
+		return
+	}
+	a.woken[key] = now
+	a.lock.Unlock()
+
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, scaletozero.WakeUpAnnotation, now.Format(time.RFC3339))
+	_, err := a.client.CoreV1().Services(namespace).Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		logrus.Warningf("wake up %s: %v", key, err)
+		return
+	}
+	logrus.Infof("requests arrived, wake up %s", key)
+}
@@ -0,0 +1,124 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package activator
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/gateway/annotations/scaletozero"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeEndpoints struct {
+	lock      sync.Mutex
+	endpoints *corev1.Endpoints
+}
+
+func (f *fakeEndpoints) GetEndpoints(namespace, name string) (*corev1.Endpoints, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.endpoints, nil
+}
+
+func (f *fakeEndpoints) set(ep *corev1.Endpoints) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.endpoints = ep
+}
+
+func TestActivator(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(Header) != "" {
+			t.Errorf("the activator header should be removed")
+		}
+		w.Write([]byte("hello " + r.URL.Path))
+	}))
+	defer backend.Close()
+	host, port, _ := net.SplitHostPort(backend.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	ready := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{{IP: host}},
+				// the port of the route is not the first one
+				Ports: []corev1.EndpointPort{{Name: "grpc", Port: 1}, {Name: "http", Port: int32(p)}},
+			},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "gr3a4b5c", Namespace: "ns"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "grpc", Port: 9000}, {Name: "http", Port: 80}},
+		},
+	})
+	endpoints := &fakeEndpoints{}
+	a := New(clientset, endpoints, 3*time.Second)
+	a.interval = 10 * time.Millisecond
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		endpoints.set(ready)
+	}()
+
+	req := httptest.NewRequest("GET", "/index", nil)
+	req.Header.Set(Header, "ns/gr3a4b5c/80")
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, req)
+	body, _ := ioutil.ReadAll(rec.Result().Body)
+	if rec.Code != http.StatusOK || string(body) != "hello /index" {
+		t.Fatalf("unexpected response %d %s", rec.Code, body)
+	}
+	svc, err := clientset.CoreV1().Services("ns").Get(req.Context(), "gr3a4b5c", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Annotations[scaletozero.WakeUpAnnotation] == "" {
+		t.Errorf("the service should be annotated to wake up")
+	}
+}
+
+func TestActivatorTimeout(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	a := New(clientset, &fakeEndpoints{}, 50*time.Millisecond)
+	a.interval = 10 * time.Millisecond
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(Header, "ns/gr3a4b5c")
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("want %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	rec = httptest.NewRecorder()
+	a.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("want %d, got %d", http.StatusBadGateway, rec.Code)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/goodrain/rainbond/gateway/annotations/proxy"
 	"github.com/goodrain/rainbond/gateway/annotations/resolver"
 	"github.com/goodrain/rainbond/gateway/annotations/rewrite"
+	"github.com/goodrain/rainbond/gateway/annotations/scaletozero"
 	"github.com/goodrain/rainbond/gateway/annotations/upstreamhashby"
 	weight "github.com/goodrain/rainbond/gateway/annotations/wight"
 	"github.com/goodrain/rainbond/util/ingress-nginx/ingress/errors"
@@ -49,6 +50,7 @@ type Ingress struct {
 	UpstreamHashBy    string
 	LoadBalancingType string
 	Proxy             proxy.Config
+	ScaleToZero       bool
 }
 
 // Extractor defines the annotation parsers to be used in the extraction of annotations
@@ -68,6 +70,7 @@ func NewAnnotationExtractor(cfg resolver.Resolver) Extractor {
 			"UpstreamHashBy":    upstreamhashby.NewParser(cfg),
 			"LoadBalancingType": lbtype.NewParser(cfg),
 			"Proxy":             proxy.NewParser(cfg),
+			"ScaleToZero":       scaletozero.NewParser(cfg),
 		},
 	}
 }
@@ -0,0 +1,53 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scaletozero
+
+import (
+	"github.com/goodrain/rainbond/gateway/annotations/parser"
+	"github.com/goodrain/rainbond/gateway/annotations/resolver"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotation marks the ingress whose backend may be scaled to zero,
+// requests to it are held by the gateway until the backend wakes up.
+const Annotation = "scale-to-zero"
+
+type scaleToZero struct {
+	r resolver.Resolver
+}
+
+// NewParser creates a new scale-to-zero annotation parser
+func NewParser(r resolver.Resolver) parser.IngressAnnotation {
+	return scaleToZero{r}
+}
+
+// Parse parses the scale-to-zero annotation of the ingress
+func (a scaleToZero) Parse(meta *metav1.ObjectMeta) (interface{}, error) {
+	return parser.GetBoolAnnotation(Annotation, meta)
+}
+
+// IsEnabled returns whether the backend of the ingress may be scaled to zero
+func IsEnabled(meta *metav1.ObjectMeta) bool {
+	enabled, _ := parser.GetBoolAnnotation(Annotation, meta)
+	return enabled
+}
+
+// WakeUpAnnotation is patched on the kubernetes service of the backend scaled to zero
+// when requests arrive, the value is the time of the request.
+const WakeUpAnnotation = "rainbond.io/wake-up-at"
@@ -29,6 +29,7 @@ import (
 
 	"github.com/eapache/channels"
 	"github.com/goodrain/rainbond/cmd/gateway/option"
+	"github.com/goodrain/rainbond/gateway/activator"
 	"github.com/goodrain/rainbond/gateway/controller/openresty"
 	"github.com/goodrain/rainbond/gateway/metric"
 	"github.com/goodrain/rainbond/gateway/store"
@@ -75,6 +76,7 @@ type GWController struct {
 	ctx     context.Context
 
 	metricCollector metric.Collector
+	activator       *activator.Activator
 }
 
 // Start starts Gateway
@@ -94,6 +96,13 @@ func (gwc *GWController) Start(errCh chan error) error {
 
 	go gwc.handleEvent()
 
+	// hold the requests of the components scaled to zero
+	go func() {
+		if err := gwc.activator.ListenAndServe(gwc.ocfg.ListenPorts.Activator); err != nil {
+			logrus.Errorf("activator listen and serve: %v", err)
+		}
+	}()
+
 	return nil
 }
 
@@ -196,6 +205,7 @@ func NewGWController(ctx context.Context, clientset kubernetes.Interface, cfg *o
 		gwc.updateCh,
 		cfg, node)
 	gwc.syncQueue = task.NewTaskQueue(gwc.syncGateway)
+	gwc.activator = activator.New(clientset, gwc.store, cfg.ActivatorTimeout)
 
 	return gwc, nil
 }
@@ -35,10 +35,13 @@ import (
 
 	"github.com/eapache/channels"
 	"github.com/goodrain/rainbond/cmd/gateway/option"
+	"github.com/goodrain/rainbond/gateway/activator"
 	"github.com/goodrain/rainbond/gateway/annotations"
 	"github.com/goodrain/rainbond/gateway/annotations/l4"
 	"github.com/goodrain/rainbond/gateway/annotations/parser"
+	"github.com/goodrain/rainbond/gateway/annotations/proxy"
 	"github.com/goodrain/rainbond/gateway/annotations/rewrite"
+	"github.com/goodrain/rainbond/gateway/annotations/scaletozero"
 	"github.com/goodrain/rainbond/gateway/cluster"
 	"github.com/goodrain/rainbond/gateway/controller/config"
 	"github.com/goodrain/rainbond/gateway/defaults"
@@ -98,6 +101,9 @@ type Storer interface {
 
 	// GetDefaultBackend returns the default backend configuration
 	GetDefaultBackend() defaults.Backend
+
+	// GetEndpoints returns the endpoints from the local cache
+	GetEndpoints(namespace, name string) (*corev1.Endpoints, error)
 }
 
 type backend struct {
@@ -105,6 +111,8 @@ type backend struct {
 	weight            int
 	hashBy            string
 	loadBalancingType string
+	// scaleToZero the requests are forwarded to the activator if the backend has no ready endpoints
+	scaleToZero bool
 }
 
 // Event holds the context of an event.
@@ -468,6 +476,31 @@ func (s *k8sStore) ListPool() ([]*v1.Pool, []*v1.Pool) {
 			}
 		}
 	}
+	// the requests of the backends scaled to zero are held by the activator
+	for _, backends := range l7PoolBackendMap {
+		for _, backend := range backends {
+			if !backend.scaleToZero {
+				continue
+			}
+			pool := l7Pools[backend.name]
+			if pool == nil {
+				pool = &v1.Pool{
+					Nodes: []*v1.Node{},
+				}
+				pool.Name = backend.name
+				pool.Namespace = "default"
+				pool.LoadBalancingType = v1.GetLoadBalancingType(backend.loadBalancingType)
+				l7Pools[backend.name] = pool
+			}
+			if len(pool.Nodes) == 0 {
+				pool.Nodes = append(pool.Nodes, &v1.Node{
+					Host:   "127.0.0.1",
+					Port:   int32(s.conf.ListenPorts.Activator),
+					Weight: 1,
+				})
+			}
+		}
+	}
 	// change map to slice TODO: use map directly
 	for _, pool := range l7Pools {
 		httpPools = append(httpPools, pool)
@@ -675,6 +708,9 @@ func (s *k8sStore) ListVirtualService() (l7vs []*v1.VirtualService, l4vs []*v1.V
 							vs.Locations = append(vs.Locations, location)
 							// the first ingress proxy takes effect
 							location.Proxy = anns.Proxy
+							if anns.ScaleToZero {
+								location.Proxy = s.wakeUpProxy(anns.Proxy, ing.Namespace, path.Backend.ServiceName, path.Backend.ServicePort.String())
+							}
 						}
 						// If their ServiceName is the same, then the new one will overwrite the old one.
 						nameCondition := &v1.Condition{}
@@ -698,6 +734,7 @@ func (s *k8sStore) ListVirtualService() (l7vs []*v1.VirtualService, l4vs []*v1.V
 							name:              backendName,
 							weight:            anns.Weight.Weight,
 							loadBalancingType: anns.LoadBalancingType,
+							scaleToZero:       anns.ScaleToZero,
 						}
 						if anns.UpstreamHashBy != "" {
 							backend.hashBy = anns.UpstreamHashBy
@@ -788,6 +825,9 @@ func (s *k8sStore) ListVirtualService() (l7vs []*v1.VirtualService, l4vs []*v1.V
 							vs.Locations = append(vs.Locations, location)
 							// the first ingress proxy takes effect
 							location.Proxy = anns.Proxy
+							if anns.ScaleToZero {
+								location.Proxy = s.wakeUpProxy(anns.Proxy, ing.Namespace, path.Backend.Service.Name, servicePort(path.Backend.Service.Port))
+							}
 						}
 						// If their ServiceName is the same, then the new one will overwrite the old one.
 						nameCondition := &v1.Condition{}
@@ -811,6 +851,7 @@ func (s *k8sStore) ListVirtualService() (l7vs []*v1.VirtualService, l4vs []*v1.V
 							name:              backendName,
 							weight:            anns.Weight.Weight,
 							loadBalancingType: anns.LoadBalancingType,
+							scaleToZero:       anns.ScaleToZero,
 						}
 						if anns.UpstreamHashBy != "" {
 							backend.hashBy = anns.UpstreamHashBy
@@ -952,11 +993,11 @@ func (s *k8sStore) ingressIsValid(ingress interface{}) bool {
 		logrus.Errorf("Cant not convert %v to %v", reflect.TypeOf(item), reflect.TypeOf(endpoint))
 		return false
 	}
-	if len(endpoint.Subsets) == 0 {
-		logrus.Debugf("Endpoints(%s) is empty, ignore it", endpointKey)
-		return false
-	}
 	if !hasReadyAddresses(endpoint) {
+		// the requests of the backend scaled to zero are held by the activator
+		if meta := ingressMeta(ingress); meta != nil && scaletozero.IsEnabled(meta) {
+			return true
+		}
 		logrus.Debugf("Endpoints(%s) is empty, ignore it", endpointKey)
 		return false
 	}
@@ -964,6 +1005,41 @@ func (s *k8sStore) ingressIsValid(ingress interface{}) bool {
 	return true
 }
 
+func ingressMeta(ingress interface{}) *metav1.ObjectMeta {
+	switch ing := ingress.(type) {
+	case *networkingv1.Ingress:
+		return &ing.ObjectMeta
+	case *betav1.Ingress:
+		return &ing.ObjectMeta
+	}
+	return nil
+}
+
+// wakeUpProxy returns the proxy config with the activator header, which tells the activator
+// the kubernetes service to wake up. Once the service has ready endpoints, the requests go to
+// the backend directly, the header is cleared so that it is not passed to the backend.
+func (s *k8sStore) wakeUpProxy(cfg proxy.Config, namespace, serviceName, port string) proxy.Config {
+	headers := make(map[string]string, len(cfg.SetHeaders)+1)
+	for k, v := range cfg.SetHeaders {
+		headers[k] = v
+	}
+	// nginx does not pass the header with the empty value
+	headers[activator.Header] = `""`
+	item, exists, err := s.listers.Endpoint.GetByKey(namespace + "/" + serviceName)
+	if err != nil || !exists || !hasReadyAddresses(item.(*corev1.Endpoints)) {
+		headers[activator.Header] = namespace + "/" + serviceName + "/" + port
+	}
+	cfg.SetHeaders = headers
+	return cfg
+}
+
+func servicePort(port networkingv1.ServiceBackendPort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return strconv.Itoa(int(port.Number))
+}
+
 func getEndpointKey(ingress interface{}) string {
 	var endpointKey string
 	ntwIngress, ok := ingress.(*networkingv1.Ingress)
@@ -1012,6 +1088,22 @@ func hasReadyAddresses(endpoints *corev1.Endpoints) bool {
 	return false
 }
 
+// GetEndpoints returns the endpoints from the local cache
+func (s *k8sStore) GetEndpoints(namespace, name string) (*corev1.Endpoints, error) {
+	item, exists, err := s.listers.Endpoint.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	endpoints, ok := item.(*corev1.Endpoints)
+	if !ok {
+		return nil, fmt.Errorf("can not convert %v to %v", reflect.TypeOf(item), reflect.TypeOf(endpoints))
+	}
+	return endpoints, nil
+}
+
 // GetIngress returns the Ingress matching key.
 func (s *k8sStore) GetIngress(key string) (interface{}, error) {
 	return s.listers.Ingress.ByKey(key)
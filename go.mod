@@ -15,6 +15,7 @@ require (
 	github.com/containerd/containerd v1.6.3
 	github.com/containerd/typeurl v1.0.2
 	github.com/crossplane/crossplane-runtime v0.14.1-0.20210722005935-0b469fcc77cd
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/docker/cli v20.10.16+incompatible
 	github.com/docker/distribution v2.8.1+incompatible
 	github.com/docker/docker v20.10.16+incompatible
@@ -113,9 +114,12 @@ require (
 	github.com/go-playground/assert/v2 v2.0.1
 	github.com/google/go-containerregistry v0.5.1
 	github.com/helm/helm v2.17.0+incompatible
+	github.com/kubernetes-csi/external-snapshotter/client/v4 v4.2.0
+	github.com/robfig/cron/v3 v3.0.1
 	golang.org/x/sync v0.2.0
 	k8s.io/apimachinery v0.28.3
 	k8s.io/klog/v2 v2.100.1
+	k8s.io/metrics v0.26.4
 	kubevirt.io/api v1.1.0
 	kubevirt.io/client-go v1.1.0
 	sigs.k8s.io/gateway-api v0.6.1
@@ -200,7 +204,6 @@ require (
 	github.com/jtolds/gls v4.20.0+incompatible // indirect
 	github.com/k8snetworkplumbingwg/network-attachment-definition-client v0.0.0-20191119172530-79f836b90111 // indirect
 	github.com/kr/fs v0.1.0 // indirect
-	github.com/kubernetes-csi/external-snapshotter/client/v4 v4.2.0 // indirect
 	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
 	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
 	github.com/leodido/go-urn v1.2.1 // indirect
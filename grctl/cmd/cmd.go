@@ -34,6 +34,7 @@ func GetCmds() []cli.Command {
 	cmds := []cli.Command{}
 	cmds = append(cmds, NewCmdInstall())
 	cmds = append(cmds, NewCmdService())
+	cmds = append(cmds, NewCmdRecommendation())
 	cmds = append(cmds, NewCmdTenant())
 	cmds = append(cmds, NewCmdNode())
 	cmds = append(cmds, NewCmdCluster())
@@ -47,6 +48,7 @@ func GetCmds() []cli.Command {
 	cmds = append(cmds, NewCmdReplace())
 	cmds = append(cmds, NewCmdMigrateConsole())
 	cmds = append(cmds, NewCmdGPUShare())
+	cmds = append(cmds, NewCmdToken())
 	return cmds
 }
 
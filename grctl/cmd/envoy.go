@@ -23,10 +23,12 @@ import (
 	"fmt"
 	"strings"
 
-	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
-	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
-	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
-	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	endpointservice "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
+	rsrc "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 	"github.com/gosuri/uitable"
 	"github.com/urfave/cli"
 	"google.golang.org/grpc"
@@ -69,10 +71,11 @@ func listEnvoyEndpoint(c *cli.Context) error {
 	if err != nil {
 		showError(err.Error())
 	}
-	endpointDiscover := v2.NewEndpointDiscoveryServiceClient(cli)
+	endpointDiscover := endpointservice.NewEndpointDiscoveryServiceClient(cli)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	res, err := endpointDiscover.FetchEndpoints(ctx, &v2.DiscoveryRequest{
+	res, err := endpointDiscover.FetchEndpoints(ctx, &discovery.DiscoveryRequest{
+		TypeUrl: rsrc.EndpointType,
 		Node: &core.Node{
 			Cluster: c.GlobalString("node"),
 			Id:      c.GlobalString("node"),
@@ -84,7 +87,7 @@ func listEnvoyEndpoint(c *cli.Context) error {
 	if len(res.Resources) == 0 {
 		showError("not find endpoints")
 	}
-	endpoints := envoyv2.ParseLocalityLbEndpointsResource(res.Resources)
+	endpoints := envoyv3.ParseLocalityLbEndpointsResource(res.Resources)
 	table := uitable.New()
 	table.Wrap = true // wrap columns
 	for _, end := range endpoints {
@@ -0,0 +1,148 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/grctl/clients"
+	"github.com/goodrain/rainbond/util/termtables"
+	"github.com/urfave/cli"
+)
+
+// NewCmdRecommendation resource recommendation command
+func NewCmdRecommendation() cli.Command {
+	tenantFlag := cli.StringFlag{
+		Name:     "tenantAlias,t",
+		Value:    "",
+		Usage:    "Specify the tenant alias",
+		FilePath: GetTenantNamePath(),
+	}
+	c := cli.Command{
+		Name:  "recommendation",
+		Usage: "resource recommendations computed from the historical usage，grctl recommendation -h",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Name:  "list",
+				Flags: []cli.Flag{tenantFlag},
+				Usage: "list the resource recommendations of the tenant services. For example <grctl recommendation list -t goodrain>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return listRecommendations(c)
+				},
+			},
+			cli.Command{
+				Name:  "get",
+				Flags: []cli.Flag{tenantFlag},
+				Usage: "get the resource recommendation of a service. For example <grctl recommendation get goodrain/gra564a1>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return getRecommendation(c)
+				},
+			},
+			cli.Command{
+				Name:  "apply",
+				Flags: []cli.Flag{tenantFlag},
+				Usage: "apply the resource recommendation to a service. For example <grctl recommendation apply goodrain/gra564a1>",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return applyRecommendation(c)
+				},
+			},
+		},
+	}
+	return c
+}
+
+func listRecommendations(c *cli.Context) error {
+	tenantName := c.String("tenantAlias")
+	if c.Args().First() != "" {
+		tenantName = c.Args().First()
+	}
+	if tenantName == "" {
+		showError("tenant alias can not be empty")
+	}
+	recs, err := clients.RegionClient.Tenants(tenantName).ResourceRecommendations()
+	handleErr(err)
+	table := termtables.CreateTable()
+	table.AddHeaders("ServiceAlias", "CPU(m)", "Recommended CPU(m)", "Memory(MB)", "Recommended Memory(MB)", "AutoApply", "RecommendTime")
+	for _, rec := range recs {
+		table.AddRow(recommendationRow(rec)...)
+	}
+	fmt.Println(table.Render())
+	return nil
+}
+
+func getRecommendation(c *cli.Context) error {
+	tenantName, serviceAlias := parseServiceAlias(c)
+	rec, err := clients.RegionClient.Tenants(tenantName).Services(serviceAlias).ResourceRecommendation()
+	handleErr(err)
+	table := termtables.CreateTable()
+	table.AddHeaders("ServiceAlias", "CPU(m)", "Recommended CPU(m)", "Memory(MB)", "Recommended Memory(MB)", "AutoApply", "RecommendTime")
+	table.AddRow(recommendationRow(rec)...)
+	fmt.Println(table.Render())
+	return nil
+}
+
+func applyRecommendation(c *cli.Context) error {
+	tenantName, serviceAlias := parseServiceAlias(c)
+	eventID, err := clients.RegionClient.Tenants(tenantName).Services(serviceAlias).ApplyResourceRecommendation()
+	handleErr(err)
+	fmt.Println("EventID:", eventID)
+	return nil
+}
+
+func parseServiceAlias(c *cli.Context) (string, string) {
+	serviceAlias := c.Args().First()
+	tenantName := c.String("tenantAlias")
+	info := strings.Split(serviceAlias, "/")
+	if len(info) >= 2 {
+		tenantName = info[0]
+		serviceAlias = info[1]
+	}
+	if tenantName == "" {
+		showError("tenant alias can not be empty")
+	}
+	if serviceAlias == "" {
+		showError("service alias can not be empty")
+	}
+	return tenantName, serviceAlias
+}
+
+func recommendationRow(rec *model.ResourceRecommendation) []interface{} {
+	var recommendTime string
+	if rec.RecommendTime != nil {
+		recommendTime = rec.RecommendTime.Format("2006-01-02 15:04:05")
+	}
+	autoApply := "false"
+	if rec.Policy != nil && rec.Policy.AutoApply {
+		autoApply = fmt.Sprintf("%s-%s %s", rec.Policy.ApplyWindowStart, rec.Policy.ApplyWindowEnd, rec.Policy.Timezone)
+	}
+	return []interface{}{
+		rec.ServiceAlias,
+		rec.ContainerCPU,
+		fmt.Sprintf("%d/%d", rec.CPURequest, rec.CPULimit),
+		rec.ContainerMemory,
+		fmt.Sprintf("%d/%d", rec.MemoryRequest, rec.MemoryLimit),
+		autoApply,
+		recommendTime,
+	}
+}
@@ -0,0 +1,141 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goodrain/rainbond/api/model"
+	"github.com/goodrain/rainbond/grctl/clients"
+	"github.com/goodrain/rainbond/util/termtables"
+	"github.com/urfave/cli"
+)
+
+// NewCmdToken api token cmd
+func NewCmdToken() cli.Command {
+	c := cli.Command{
+		Name:  "token",
+		Usage: "manage the api tokens of rbd-api. grctl token -h",
+		Subcommands: []cli.Command{
+			{
+				Name:  "create",
+				Usage: "issue an api token, grctl token create NAME --scope deploy --tenant TENANT_NAME",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "scope",
+						Usage: "read-only, deploy or admin",
+						Value: "read-only",
+					},
+					cli.StringFlag{
+						Name:  "tenant, t",
+						Usage: "restrict the token to the tenant",
+					},
+					cli.StringFlag{
+						Name:  "app",
+						Usage: "restrict the token to the app id of the tenant",
+					},
+					cli.DurationFlag{
+						Name:  "expire",
+						Usage: "how long the token is valid, such as 720h, the token never expires if not set",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return createAPIToken(c)
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "list the api tokens",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "tenant, t",
+						Usage: "only list the tokens of the tenant",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					Common(c)
+					return listAPITokens(c)
+				},
+			},
+			{
+				Name:  "revoke",
+				Usage: "revoke an api token, grctl token revoke TOKEN_ID",
+				Action: func(c *cli.Context) error {
+					Common(c)
+					tokenID := c.Args().First()
+					if tokenID == "" {
+						showError("token id can not be empty")
+					}
+					handleErr(clients.RegionClient.APITokens().Revoke(tokenID))
+					showSuccessMsg("revoke api token " + tokenID)
+					return nil
+				},
+			},
+		},
+	}
+	return c
+}
+
+func createAPIToken(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		showError("token name can not be empty")
+	}
+	req := &model.CreateAPITokenStruct{
+		Name:       name,
+		Scope:      c.String("scope"),
+		TenantName: c.String("tenant"),
+		AppID:      c.String("app"),
+		CreatedBy:  "grctl",
+	}
+	if expire := c.Duration("expire"); expire > 0 {
+		expireTime := time.Now().Add(expire)
+		req.ExpireTime = &expireTime
+	}
+	token, err := clients.RegionClient.APITokens().Create(req)
+	handleErr(err)
+	fmt.Printf("Token ID: %s\n", token.TokenID)
+	fmt.Printf("Token: %s\n", token.Token)
+	fmt.Println("The token can not be shown again, please keep it safe.")
+	return nil
+}
+
+func listAPITokens(c *cli.Context) error {
+	tokens, err := clients.RegionClient.APITokens().List(c.String("tenant"))
+	handleErr(err)
+	table := termtables.CreateTable()
+	table.AddHeaders("Token ID", "Name", "Scope", "Tenant", "App", "Expire Time", "Status")
+	now := time.Now()
+	for _, token := range tokens {
+		expireTime, status := "never", "active"
+		if token.ExpireTime != nil {
+			expireTime = token.ExpireTime.Format(time.RFC3339)
+		}
+		if token.Revoked {
+			status = "revoked"
+		} else if token.Expired(now) {
+			status = "expired"
+		}
+		table.AddRow(token.TokenID, token.Name, token.Scope, token.TenantName, token.AppID, expireTime, status)
+	}
+	fmt.Println(table.Render())
+	return nil
+}
@@ -0,0 +1,301 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	custommetrics "k8s.io/metrics/pkg/apis/custom_metrics/v1beta1"
+	externalmetrics "k8s.io/metrics/pkg/apis/external_metrics/v1beta1"
+)
+
+const (
+	customMetricsGroupVersion   = "custom.metrics.k8s.io/v1beta1"
+	externalMetricsGroupVersion = "external.metrics.k8s.io/v1beta1"
+)
+
+// querier the part of the prometheus api used by the adapter
+type querier interface {
+	Query(ctx context.Context, query string, ts time.Time, opts ...apiv1.Option) (model.Value, apiv1.Warnings, error)
+}
+
+// Adapter serves the custom.metrics.k8s.io and external.metrics.k8s.io apis
+// backed by the platform prometheus, so that hpas can scale components by
+// pods, object and external metrics.
+type Adapter struct {
+	prometheus querier
+	clientset  kubernetes.Interface
+	now        func() time.Time
+}
+
+// NewAdapter new metrics adapter
+func NewAdapter(prometheus apiv1.API, clientset kubernetes.Interface) *Adapter {
+	return &Adapter{
+		prometheus: prometheus,
+		clientset:  clientset,
+		now:        time.Now,
+	}
+}
+
+// Handler returns the http handler of the adapter
+func (a *Adapter) Handler() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	r.Get("/apis/custom.metrics.k8s.io/v1beta1", a.discovery(customMetricsGroupVersion))
+	r.Get("/apis/custom.metrics.k8s.io/v1beta1/namespaces/{namespace}/{resource}/{name}/{metric}", a.customMetrics)
+	r.Get("/apis/external.metrics.k8s.io/v1beta1", a.discovery(externalMetricsGroupVersion))
+	r.Get("/apis/external.metrics.k8s.io/v1beta1/namespaces/{namespace}/{metric}", a.externalMetrics)
+	return r
+}
+
+// discovery the metrics are not enumerated, any metric in prometheus can be requested
+func (a *Adapter) discovery(groupVersion string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, &metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+			GroupVersion: groupVersion,
+			APIResources: []metav1.APIResource{},
+		})
+	}
+}
+
+func (a *Adapter) customMetrics(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	resource := chi.URLParam(r, "resource")
+	name := chi.URLParam(r, "name")
+	metric := chi.URLParam(r, "metric")
+	metricSelector, err := labels.Parse(r.URL.Query().Get("metricLabelSelector"))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("invalid metric label selector: %v", err))
+		return
+	}
+	var items []custommetrics.MetricValue
+	if name == custommetrics.AllObjects {
+		if resource != "pods" {
+			writeStatus(w, http.StatusBadRequest, fmt.Sprintf("listing metrics of %s is not supported", resource))
+			return
+		}
+		items, err = a.podsMetrics(r.Context(), namespace, r.URL.Query().Get("labelSelector"), metric, metricSelector)
+	} else {
+		items, err = a.objectMetrics(r.Context(), namespace, resource, name, metric, metricSelector)
+	}
+	if err != nil {
+		logrus.Warningf("get custom metric %s of %s/%s/%s: %v", metric, namespace, resource, name, err)
+		writeStatus(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		writeStatus(w, http.StatusNotFound, fmt.Sprintf("metric %s of %s/%s/%s not found", metric, namespace, resource, name))
+		return
+	}
+	writeJSON(w, http.StatusOK, &custommetrics.MetricValueList{
+		TypeMeta: metav1.TypeMeta{Kind: "MetricValueList", APIVersion: customMetricsGroupVersion},
+		Items:    items,
+	})
+}
+
+func (a *Adapter) podsMetrics(ctx context.Context, namespace, podSelector, metric string, metricSelector labels.Selector) ([]custommetrics.MetricValue, error) {
+	podList, err := a.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: podSelector})
+	if err != nil {
+		return nil, err
+	}
+	var pods []string
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodRunning {
+			pods = append(pods, pod.Name)
+		}
+	}
+	if len(pods) == 0 {
+		return nil, nil
+	}
+	query, rate, err := podsQuery(namespace, pods, metric, metricSelector)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := a.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var items []custommetrics.MetricValue
+	for _, sample := range samples {
+		pod := string(sample.Metric["pod"])
+		if pod == "" {
+			continue
+		}
+		items = append(items, a.metricValue(namespace, "pods", pod, metric, rate, metricSelector, sample))
+	}
+	return items, nil
+}
+
+func (a *Adapter) objectMetrics(ctx context.Context, namespace, resource, name, metric string, metricSelector labels.Selector) ([]custommetrics.MetricValue, error) {
+	query, rate, err := objectQuery(namespace, resource, name, metric, metricSelector)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := a.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, nil
+	}
+	return []custommetrics.MetricValue{a.metricValue(namespace, resource, name, metric, rate, metricSelector, samples[0])}, nil
+}
+
+func (a *Adapter) metricValue(namespace, resource, name, metric string, rate bool, metricSelector labels.Selector, sample *model.Sample) custommetrics.MetricValue {
+	kind, apiVersion := resourceReference(resource)
+	value := custommetrics.MetricValue{
+		DescribedObject: corev1.ObjectReference{
+			Kind:       kind,
+			APIVersion: apiVersion,
+			Namespace:  namespace,
+			Name:       name,
+		},
+		MetricName: metric,
+		Timestamp:  metav1.NewTime(sample.Timestamp.Time()),
+		Value:      *quantity(float64(sample.Value)),
+	}
+	if rate {
+		value.WindowSeconds = windowSeconds()
+	}
+	if !metricSelector.Empty() {
+		if selector, err := metav1.ParseToLabelSelector(metricSelector.String()); err == nil {
+			value.Selector = selector
+		}
+	}
+	return value
+}
+
+func (a *Adapter) externalMetrics(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	metric := chi.URLParam(r, "metric")
+	metricSelector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, fmt.Sprintf("invalid label selector: %v", err))
+		return
+	}
+	query, rate, err := externalQuery(namespace, metric, metricSelector)
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	samples, err := a.query(r.Context(), query)
+	if err != nil {
+		logrus.Warningf("get external metric %s of %s: %v", metric, namespace, err)
+		writeStatus(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(samples) == 0 {
+		writeStatus(w, http.StatusNotFound, fmt.Sprintf("external metric %s of %s not found", metric, namespace))
+		return
+	}
+	value := externalmetrics.ExternalMetricValue{
+		MetricName:   metric,
+		MetricLabels: map[string]string{},
+		Timestamp:    metav1.NewTime(samples[0].Timestamp.Time()),
+		Value:        *quantity(float64(samples[0].Value)),
+	}
+	if rate {
+		value.WindowSeconds = windowSeconds()
+	}
+	if requirements, selectable := metricSelector.Requirements(); selectable {
+		for _, r := range requirements {
+			if values := r.Values().List(); len(values) == 1 {
+				value.MetricLabels[r.Key()] = values[0]
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, &externalmetrics.ExternalMetricValueList{
+		TypeMeta: metav1.TypeMeta{Kind: "ExternalMetricValueList", APIVersion: externalMetricsGroupVersion},
+		Items:    []externalmetrics.ExternalMetricValue{value},
+	})
+}
+
+func (a *Adapter) query(ctx context.Context, query string) (model.Vector, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	value, warnings, err := a.prometheus.Query(ctx, query, a.now())
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %v", query, err)
+	}
+	if len(warnings) > 0 {
+		logrus.Debugf("query %s warnings: %v", query, warnings)
+	}
+	vector, ok := value.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("query %s: unexpected result type %s", query, value.Type())
+	}
+	var samples model.Vector
+	for _, sample := range vector {
+		if math.IsNaN(float64(sample.Value)) || math.IsInf(float64(sample.Value), 0) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+func quantity(value float64) *resource.Quantity {
+	return resource.NewMilliQuantity(int64(math.Round(value*1000)), resource.DecimalSI)
+}
+
+func windowSeconds() *int64 {
+	window := int64(rateWindow)
+	return &window
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.Warningf("write metrics response: %v", err)
+	}
+}
+
+func writeStatus(w http.ResponseWriter, code int, message string) {
+	reason := metav1.StatusReasonInternalError
+	switch code {
+	case http.StatusBadRequest:
+		reason = metav1.StatusReasonBadRequest
+	case http.StatusNotFound:
+		reason = metav1.StatusReasonNotFound
+	}
+	writeJSON(w, code, &metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Message:  message,
+		Reason:   reason,
+		Code:     int32(code),
+	})
+}
@@ -0,0 +1,165 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package adapter
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	custommetrics "k8s.io/metrics/pkg/apis/custom_metrics/v1beta1"
+	externalmetrics "k8s.io/metrics/pkg/apis/external_metrics/v1beta1"
+)
+
+type fakeQuerier struct {
+	queries []string
+	result  model.Vector
+}
+
+func (f *fakeQuerier) Query(ctx context.Context, query string, ts time.Time, opts ...apiv1.Option) (model.Value, apiv1.Warnings, error) {
+	f.queries = append(f.queries, query)
+	return f.result, nil, nil
+}
+
+func TestQueries(t *testing.T) {
+	selector, _ := labels.Parse("queue in (orders,pay),app.kubernetes.io/name=mq,!deprecated")
+	tests := []struct {
+		name  string
+		query func() (string, bool, error)
+		want  string
+		rate  bool
+	}{
+		{
+			name: "pods",
+			query: func() (string, bool, error) {
+				return podsQuery("ns", []string{"web-1", "web-0"}, "http_requests_per_second", labels.Everything())
+			},
+			want: `sum by (pod) (rate(http_requests{namespace="ns",pod=~"web-0|web-1"}[120s]))`,
+			rate: true,
+		},
+		{
+			name: "object",
+			query: func() (string, bool, error) {
+				return objectQuery("ns", "services", "gr3a4b5c", "gateway_requests_per_second", labels.Everything())
+			},
+			want: `sum(rate(gateway_requests{namespace="ns",service="gr3a4b5c"}[120s]))`,
+			rate: true,
+		},
+		{
+			name: "external",
+			query: func() (string, bool, error) {
+				return externalQuery("ns", "rabbitmq_queue_messages", selector)
+			},
+			want: `sum(rabbitmq_queue_messages{namespace="ns",app_kubernetes_io_name="mq",deprecated="",queue=~"orders|pay"})`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, rate, err := tc.query()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want || rate != tc.rate {
+				t.Errorf("want %s(rate %v), got %s(rate %v)", tc.want, tc.rate, got, rate)
+			}
+		})
+	}
+	if _, _, err := externalQuery("ns", `up{job="x"} or vector(1)`, labels.Everything()); err == nil {
+		t.Errorf("want error for invalid metric name")
+	}
+}
+
+func TestPodsMetrics(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "ns", Labels: map[string]string{"name": "web"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "ns", Labels: map[string]string{"name": "web"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	)
+	querier := &fakeQuerier{result: model.Vector{
+		{Metric: model.Metric{"pod": "web-0"}, Value: 12.5, Timestamp: model.TimeFromUnix(1600000000)},
+	}}
+	a := &Adapter{prometheus: querier, clientset: clientset, now: time.Now}
+
+	req := httptest.NewRequest(http.MethodGet, "/apis/custom.metrics.k8s.io/v1beta1/namespaces/ns/pods/*/http_requests_per_second?labelSelector=name%3Dweb", nil)
+	w := httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var list custommetrics.MetricValueList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].DescribedObject.Name != "web-0" || list.Items[0].Value.MilliValue() != 12500 {
+		t.Errorf("unexpected items %+v", list.Items)
+	}
+	if len(querier.queries) != 1 || querier.queries[0] != `sum by (pod) (rate(http_requests{namespace="ns",pod=~"web-0"}[120s]))` {
+		t.Errorf("unexpected queries %v", querier.queries)
+	}
+}
+
+func TestExternalMetrics(t *testing.T) {
+	a := &Adapter{prometheus: &fakeQuerier{}, clientset: fake.NewSimpleClientset(), now: time.Now}
+	req := httptest.NewRequest(http.MethodGet, "/apis/external.metrics.k8s.io/v1beta1/namespaces/ns/queue_length", nil)
+	w := httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 without samples, got %d", w.Code)
+	}
+
+	a.prometheus = &fakeQuerier{result: model.Vector{{Value: 42}}}
+	req = httptest.NewRequest(http.MethodGet, "/apis/external.metrics.k8s.io/v1beta1/namespaces/ns/queue_length?labelSelector=queue%3Dorders", nil)
+	w = httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, req)
+	var list externalmetrics.ExternalMetricValueList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Value.Value() != 42 || list.Items[0].MetricLabels["queue"] != "orders" {
+		t.Errorf("unexpected items %+v", list.Items)
+	}
+}
+
+func TestVerifyAllowedName(t *testing.T) {
+	chains := [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "front-proxy-client"}}}}
+	if err := verifyAllowedName(chains, nil); err != nil {
+		t.Errorf("want any name allowed without allowed names, got %v", err)
+	}
+	if err := verifyAllowedName(chains, []string{"front-proxy-client"}); err != nil {
+		t.Errorf("want allowed name accepted, got %v", err)
+	}
+	if err := verifyAllowedName(chains, []string{"aggregator"}); err == nil {
+		t.Error("want unknown name rejected")
+	}
+}
@@ -0,0 +1,138 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package adapter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// rateSuffix metrics whose name ends with the suffix are served as the
+// per second rate of the counter without the suffix, such as
+// gateway_requests_per_second is rate(gateway_requests[2m]).
+const rateSuffix = "_per_second"
+
+// rateWindow the window of rate metrics
+const rateWindow = 120
+
+var (
+	metricNameRegexp   = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	invalidLabelRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// series returns the promql selecting the metric with the matchers, and
+// whether the metric is a rate metric.
+func series(metric string, matchers []string) (string, bool, error) {
+	if !metricNameRegexp.MatchString(metric) {
+		return "", false, fmt.Errorf("invalid metric name %s", metric)
+	}
+	selector := "{" + strings.Join(matchers, ",") + "}"
+	if strings.HasSuffix(metric, rateSuffix) && metric != rateSuffix {
+		base := strings.TrimSuffix(metric, rateSuffix)
+		return fmt.Sprintf("rate(%s%s[%ds])", base, selector, rateWindow), true, nil
+	}
+	return metric + selector, false, nil
+}
+
+// labelName converts a kubernetes label key to a prometheus label name
+func labelName(key string) string {
+	return invalidLabelRegexp.ReplaceAllString(key, "_")
+}
+
+func matcher(name, op, value string) string {
+	return fmt.Sprintf("%s%s%q", labelName(name), op, value)
+}
+
+// selectorMatchers converts a kubernetes label selector to promql label matchers
+func selectorMatchers(selector labels.Selector) []string {
+	if selector == nil {
+		return nil
+	}
+	requirements, _ := selector.Requirements()
+	var matchers []string
+	for _, r := range requirements {
+		values := r.Values().List()
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals:
+			matchers = append(matchers, matcher(r.Key(), "=", values[0]))
+		case selection.NotEquals:
+			matchers = append(matchers, matcher(r.Key(), "!=", values[0]))
+		case selection.In:
+			matchers = append(matchers, matcher(r.Key(), "=~", regexpAlternation(values)))
+		case selection.NotIn:
+			matchers = append(matchers, matcher(r.Key(), "!~", regexpAlternation(values)))
+		case selection.Exists:
+			matchers = append(matchers, matcher(r.Key(), "!=", ""))
+		case selection.DoesNotExist:
+			matchers = append(matchers, matcher(r.Key(), "=", ""))
+		}
+	}
+	return matchers
+}
+
+func regexpAlternation(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, regexp.QuoteMeta(value))
+	}
+	sort.Strings(quoted)
+	return strings.Join(quoted, "|")
+}
+
+// podsQuery the value of the metric of each pod
+func podsQuery(namespace string, pods []string, metric string, metricSelector labels.Selector) (string, bool, error) {
+	matchers := append([]string{
+		matcher("namespace", "=", namespace),
+		matcher("pod", "=~", regexpAlternation(pods)),
+	}, selectorMatchers(metricSelector)...)
+	s, rate, err := series(metric, matchers)
+	if err != nil {
+		return "", false, err
+	}
+	return fmt.Sprintf("sum by (pod) (%s)", s), rate, nil
+}
+
+// objectQuery the value of the metric of a single object, the object is
+// matched by the label named after its resource, such as service="gr3a4b5c".
+func objectQuery(namespace, resource, name, metric string, metricSelector labels.Selector) (string, bool, error) {
+	matchers := append([]string{
+		matcher("namespace", "=", namespace),
+		matcher(resourceLabel(resource), "=", name),
+	}, selectorMatchers(metricSelector)...)
+	s, rate, err := series(metric, matchers)
+	if err != nil {
+		return "", false, err
+	}
+	return fmt.Sprintf("sum(%s)", s), rate, nil
+}
+
+// externalQuery the value of the external metric, external metrics are
+// restricted to the namespace of the hpa so that tenants can not read each other's metrics.
+func externalQuery(namespace, metric string, metricSelector labels.Selector) (string, bool, error) {
+	matchers := append([]string{matcher("namespace", "=", namespace)}, selectorMatchers(metricSelector)...)
+	s, rate, err := series(metric, matchers)
+	if err != nil {
+		return "", false, err
+	}
+	return fmt.Sprintf("sum(%s)", s), rate, nil
+}
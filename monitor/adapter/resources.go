@@ -0,0 +1,51 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package adapter
+
+import "strings"
+
+type resourceKind struct {
+	label      string
+	kind       string
+	apiVersion string
+}
+
+var resourceKinds = map[string]resourceKind{
+	"pods":         {label: "pod", kind: "Pod", apiVersion: "v1"},
+	"services":     {label: "service", kind: "Service", apiVersion: "v1"},
+	"deployments":  {label: "deployment", kind: "Deployment", apiVersion: "apps/v1"},
+	"statefulsets": {label: "statefulset", kind: "StatefulSet", apiVersion: "apps/v1"},
+	"daemonsets":   {label: "daemonset", kind: "DaemonSet", apiVersion: "apps/v1"},
+	"ingresses":    {label: "ingress", kind: "Ingress", apiVersion: "networking.k8s.io/v1"},
+}
+
+// resourceLabel the prometheus label holding the name of the objects of the resource
+func resourceLabel(resource string) string {
+	if rk, ok := resourceKinds[resource]; ok {
+		return rk.label
+	}
+	return labelName(strings.TrimSuffix(resource, "s"))
+}
+
+// resourceReference the kind and api version of the objects of the resource
+func resourceReference(resource string) (string, string) {
+	if rk, ok := resourceKinds[resource]; ok {
+		return rk.kind, rk.apiVersion
+	}
+	return "", ""
+}
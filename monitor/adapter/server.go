@@ -0,0 +1,185 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package adapter
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var apiServiceResource = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// ListenAndServeTLS serves the adapter over https with a self signed
+// certificate, the kube-aggregator only talks to api services over https.
+// Clients must present a certificate signed by the requestheader client ca
+// of the cluster, so only the kube-aggregator can query the adapter.
+func (a *Adapter) ListenAndServeTLS(addr string) error {
+	cert, err := selfSignedCertificate()
+	if err != nil {
+		return fmt.Errorf("generate certificate: %v", err)
+	}
+	clientCAs, allowedNames, err := a.requestHeaderClientCA(context.Background())
+	if err != nil {
+		return fmt.Errorf("load requestheader client ca: %v", err)
+	}
+	server := &http.Server{
+		Addr:    addr,
+		Handler: a.Handler(),
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    clientCAs,
+			VerifyPeerCertificate: func(_ [][]byte, chains [][]*x509.Certificate) error {
+				return verifyAllowedName(chains, allowedNames)
+			},
+		},
+	}
+	logrus.Infof("metrics adapter listen %s", addr)
+	return server.ListenAndServeTLS("", "")
+}
+
+// requestHeaderClientCA reads the ca and the allowed common names the
+// kube-aggregator uses to authenticate itself to extension api servers.
+func (a *Adapter) requestHeaderClientCA(ctx context.Context) (*x509.CertPool, []string, error) {
+	cm, err := a.clientset.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, "extension-apiserver-authentication", metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	ca := cm.Data["requestheader-client-ca-file"]
+	if ca == "" {
+		return nil, nil, fmt.Errorf("requestheader-client-ca-file not found in %s/%s", cm.Namespace, cm.Name)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(ca)) {
+		return nil, nil, fmt.Errorf("invalid requestheader client ca")
+	}
+	var allowedNames []string
+	if names := cm.Data["requestheader-allowed-names"]; names != "" {
+		if err := json.Unmarshal([]byte(names), &allowedNames); err != nil {
+			return nil, nil, fmt.Errorf("invalid requestheader allowed names: %v", err)
+		}
+	}
+	return pool, allowedNames, nil
+}
+
+// verifyAllowedName checks the client common name, an empty list allows any
+// client certificate signed by the requestheader client ca.
+func verifyAllowedName(chains [][]*x509.Certificate, allowedNames []string) error {
+	if len(allowedNames) == 0 {
+		return nil
+	}
+	for _, chain := range chains {
+		if len(chain) == 0 {
+			continue
+		}
+		for _, name := range allowedNames {
+			if chain[0].Subject.CommonName == name {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("client certificate common name is not allowed")
+}
+
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "rbd-monitor-metrics-adapter"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// RegisterAPIServices registers the adapter as the custom.metrics.k8s.io and
+// external.metrics.k8s.io api services, the requests are proxied to the service.
+func RegisterAPIServices(ctx context.Context, client dynamic.Interface, namespace, service string, port int32) error {
+	for _, group := range []string{"custom.metrics.k8s.io", "external.metrics.k8s.io"} {
+		apiService := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiregistration.k8s.io/v1",
+			"kind":       "APIService",
+			"metadata": map[string]interface{}{
+				"name":   "v1beta1." + group,
+				"labels": map[string]interface{}{"creator": "Rainbond"},
+			},
+			"spec": map[string]interface{}{
+				"group":                 group,
+				"version":               "v1beta1",
+				"groupPriorityMinimum":  int64(100),
+				"versionPriority":       int64(100),
+				"insecureSkipTLSVerify": true,
+				"service": map[string]interface{}{
+					"namespace": namespace,
+					"name":      service,
+					"port":      int64(port),
+				},
+			},
+		}}
+		old, err := client.Resource(apiServiceResource).Get(ctx, apiService.GetName(), metav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return err
+			}
+			if _, err := client.Resource(apiServiceResource).Create(ctx, apiService, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("create api service %s: %v", apiService.GetName(), err)
+			}
+			continue
+		}
+		if old.GetLabels()["creator"] != "Rainbond" {
+			logrus.Warningf("api service %s is managed by others, skip registering the metrics adapter", old.GetName())
+			continue
+		}
+		apiService.SetResourceVersion(old.GetResourceVersion())
+		if _, err := client.Resource(apiServiceResource).Update(ctx, apiService, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("update api service %s: %v", apiService.GetName(), err)
+		}
+	}
+	return nil
+}
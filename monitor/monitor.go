@@ -48,6 +48,7 @@ type Monitor struct {
 	discoverv1     discoverv1.Discover
 	discoverv2     discoverv2.Discover
 	serviceMonitor *prometheus.ServiceMonitorController
+	prometheusRule *prometheus.RuleController
 	stopCh         chan struct{}
 }
 
@@ -92,6 +93,7 @@ func (d *Monitor) Start() {
 
 	// service monitor
 	d.serviceMonitor.Run(d.stopCh)
+	d.prometheusRule.Run(d.stopCh)
 }
 
 func (d *Monitor) discoverNodes(node *callback.Node, app *callback.App, done <-chan struct{}) {
@@ -279,5 +281,9 @@ func NewMonitor(opt *option.Config, p *prometheus.Manager) *Monitor {
 	if err != nil {
 		logrus.Fatal(err)
 	}
+	d.prometheusRule, err = prometheus.NewRuleController(ctx, restConfig, p)
+	if err != nil {
+		logrus.Fatal(err)
+	}
 	return d
 }
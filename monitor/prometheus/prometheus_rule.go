@@ -0,0 +1,174 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	mv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	externalversions "github.com/prometheus-operator/prometheus-operator/pkg/client/informers/externalversions"
+	"github.com/prometheus-operator/prometheus-operator/pkg/client/versioned"
+	"github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// RuleController renders the PrometheusRules created by rainbond into the custom rules file
+type RuleController struct {
+	ctx        context.Context
+	Prometheus *Manager
+	lastRules  []byte
+	ruleInf    cache.SharedIndexInformer
+	queue      workqueue.RateLimitingInterface
+}
+
+// NewRuleController new prometheus rule controller
+func NewRuleController(ctx context.Context, config *rest.Config, pm *Manager) (*RuleController, error) {
+	c, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	rc := &RuleController{
+		ctx:        ctx,
+		Prometheus: pm,
+		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "prometheus-rule"),
+	}
+	factory := externalversions.NewSharedInformerFactoryWithOptions(c, 5*time.Minute,
+		externalversions.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = "creator=Rainbond"
+		}))
+	informer := factory.Monitoring().V1().PrometheusRules().Informer()
+	informer.AddEventHandlerWithResyncPeriod(rc, time.Second*30)
+	rc.ruleInf = informer
+	return rc, nil
+}
+
+// Run run controller
+func (r *RuleController) Run(stopCh <-chan struct{}) {
+	go r.worker()
+	go func() {
+		defer r.queue.ShutDown()
+		r.ruleInf.Run(stopCh)
+	}()
+	cache.WaitForCacheSync(stopCh, r.ruleInf.HasSynced)
+	logrus.Info("prometheus rule controller start success")
+}
+
+// OnAdd rule add
+func (r *RuleController) OnAdd(obj interface{}) {
+	r.queue.Add("sync")
+}
+
+// OnUpdate rule update
+func (r *RuleController) OnUpdate(oldObj, newObj interface{}) {
+	r.queue.Add("sync")
+}
+
+// OnDelete rule delete
+func (r *RuleController) OnDelete(obj interface{}) {
+	r.queue.Add("sync")
+}
+
+func (r *RuleController) worker() {
+	for r.processNextWorkItem() {
+	}
+}
+
+func (r *RuleController) processNextWorkItem() bool {
+	key, quit := r.queue.Get()
+	if quit {
+		return false
+	}
+	defer r.queue.Done(key)
+	if err := r.sync(); err != nil {
+		logrus.Errorf("sync prometheus rules failure %s", err.Error())
+		r.queue.AddRateLimited(key)
+		return true
+	}
+	r.queue.Forget(key)
+	return true
+}
+
+func (r *RuleController) sync() error {
+	var rules []*mv1.PrometheusRule
+	for _, obj := range r.ruleInf.GetStore().List() {
+		if rule, ok := obj.(*mv1.PrometheusRule); ok && rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	data, err := yaml.Marshal(generateRulesConfig(rules))
+	if err != nil {
+		return err
+	}
+	if string(data) == string(r.lastRules) {
+		logrus.Debug("prometheus rules not changed")
+		return nil
+	}
+	file := r.Prometheus.Opt.CustomRulesFile
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+	r.lastRules = data
+	logrus.Infof("write %d prometheus rules to %s", len(rules), file)
+	return r.Prometheus.ReloadConfig()
+}
+
+// generateRulesConfig converts PrometheusRules into prometheus rule groups,
+// group names are prefixed with the namespace to keep them unique.
+func generateRulesConfig(rules []*mv1.PrometheusRule) *AlertingRulesConfig {
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Namespace != rules[j].Namespace {
+			return rules[i].Namespace < rules[j].Namespace
+		}
+		return rules[i].Name < rules[j].Name
+	})
+	config := &AlertingRulesConfig{Groups: []*AlertingNameConfig{}}
+	for _, rule := range rules {
+		for _, group := range rule.Spec.Groups {
+			g := &AlertingNameConfig{Name: rule.Namespace + "/" + group.Name}
+			for _, r := range group.Rules {
+				if r.Alert == "" {
+					continue
+				}
+				g.Rules = append(g.Rules, &RulesConfig{
+					Alert:       r.Alert,
+					Expr:        r.Expr.String(),
+					For:         r.For,
+					Labels:      r.Labels,
+					Annotations: r.Annotations,
+				})
+			}
+			if len(g.Rules) > 0 {
+				config.Groups = append(config.Groups, g)
+			}
+		}
+	}
+	return config
+}
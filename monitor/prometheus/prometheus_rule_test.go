@@ -0,0 +1,57 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package prometheus
+
+import (
+	"testing"
+
+	mv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGenerateRulesConfig(t *testing.T) {
+	newRule := func(namespace, name, alert string) *mv1.PrometheusRule {
+		return &mv1.PrometheusRule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: mv1.PrometheusRuleSpec{
+				Groups: []mv1.RuleGroup{{
+					Name: name,
+					Rules: []mv1.Rule{
+						{Record: "ignored", Expr: intstr.FromString("up")},
+						{Alert: alert, Expr: intstr.FromString("up == 0"), For: "1m", Labels: map[string]string{"rule_id": name}},
+					},
+				}},
+			},
+		}
+	}
+	config := generateRulesConfig([]*mv1.PrometheusRule{
+		newRule("ns2", "rbd-alert-b", "b"),
+		newRule("ns1", "rbd-alert-a", "a"),
+	})
+	if len(config.Groups) != 2 {
+		t.Fatalf("want 2 groups, got %d", len(config.Groups))
+	}
+	if config.Groups[0].Name != "ns1/rbd-alert-a" || config.Groups[1].Name != "ns2/rbd-alert-b" {
+		t.Errorf("unexpected group order %s, %s", config.Groups[0].Name, config.Groups[1].Name)
+	}
+	rules := config.Groups[0].Rules
+	if len(rules) != 1 || rules[0].Alert != "a" || rules[0].Expr != "up == 0" || rules[0].For != "1m" {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+}
@@ -58,6 +58,44 @@ const (
 	KeyBaseEjectionTimeMS string = "BaseEjectionTimeMS"
 	//KeyMaxEjectionPercent MaxEjectionPercent key
 	KeyMaxEjectionPercent string = "MaxEjectionPercent"
+	// KeyMaxRequestsPerConnection Optional maximum requests for a single upstream connection. This parameter
+	// is respected by both the HTTP/1.1 and HTTP/2 connection pool
+	// implementations. If not specified, there is no limit. Setting this
+	// parameter to 1 will effectively disable keep alive.
+	KeyMaxRequestsPerConnection string = "MaxRequestsPerConnection"
+	// KeyHealthyPanicThreshold default 50,More than 50% of hosts are ejected and go into panic mode
+	// Panic mode will send traffic back to the failed host
+	KeyHealthyPanicThreshold string = "HealthyPanicThreshold"
+	//KeyConnectionTimeout connection timeout setting
+	KeyConnectionTimeout string = "ConnectionTimeout"
+	//KeyTCPIdleTimeout tcp idle timeout
+	KeyTCPIdleTimeout string = "TCPIdleTimeout"
+	//KeyGrpcHealthServiceName The name of the grpc service used for health checking.
+	KeyGrpcHealthServiceName string = "GrpcHealthServiceName"
+	//KeyHealthCheckTimeout cluster health check timeout
+	KeyHealthCheckTimeout string = "HealthCheckTimeout"
+	//KeyHealthCheckInterval cluster health check interval
+	KeyHealthCheckInterval string = "HealthCheckInterval"
+	//KeyRequestTimeoutMS The timeout of the upstream request, include all retries. If not specified, the default is 15s.
+	KeyRequestTimeoutMS string = "RequestTimeoutMS"
+	//KeyRetryOn The conditions under which a request is retried, such as 5xx,connect-failure,retriable-4xx
+	KeyRetryOn string = "RetryOn"
+	//KeyNumRetries The number of retries of a request
+	KeyNumRetries string = "NumRetries"
+	//KeyPerTryTimeoutMS The timeout of each retry
+	KeyPerTryTimeoutMS string = "PerTryTimeoutMS"
+	//KeyRetryBaseIntervalMS The base interval of the exponential retry back off, the default is 25ms
+	KeyRetryBaseIntervalMS string = "RetryBaseIntervalMS"
+	//KeyRetryMaxIntervalMS The max interval of the exponential retry back off, the default is 10 times the base interval
+	KeyRetryMaxIntervalMS string = "RetryMaxIntervalMS"
+	//KeyFaultDelayPercent The percentage of requests to be delayed
+	KeyFaultDelayPercent string = "FaultDelayPercent"
+	//KeyFaultDelayMS The fixed delay of the delayed requests
+	KeyFaultDelayMS string = "FaultDelayMS"
+	//KeyFaultAbortPercent The percentage of requests to be aborted
+	KeyFaultAbortPercent string = "FaultAbortPercent"
+	//KeyFaultAbortHTTPStatus The http status of the aborted requests, the default is 503
+	KeyFaultAbortHTTPStatus string = "FaultAbortHTTPStatus"
 )
 
 //GetOptionValues get value from options
@@ -0,0 +1,677 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2017 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package v3
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/sirupsen/logrus"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	udpproxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/udp/udp_proxy/v2alpha"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	ratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/config/ratelimit/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	commonfault "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/common/fault/v3"
+	httpfault "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/fault/v3"
+	httpratelimit "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ratelimit/v3"
+	router "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/router/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tcpproxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "github.com/goodrain/rainbond/node/core/envoy/v1"
+)
+
+// the canonical extension names, envoy no longer resolves the deprecated ones
+const (
+	// TCPProxyFilterName -
+	TCPProxyFilterName = "envoy.filters.network.tcp_proxy"
+	// HTTPConnectionManagerFilterName -
+	HTTPConnectionManagerFilterName = "envoy.filters.network.http_connection_manager"
+	// RouterFilterName -
+	RouterFilterName = "envoy.filters.http.router"
+	// HTTPRateLimitFilterName -
+	HTTPRateLimitFilterName = "envoy.filters.http.ratelimit"
+	// HTTPFaultFilterName -
+	HTTPFaultFilterName = "envoy.filters.http.fault"
+	// UDPProxyFilterName -
+	UDPProxyFilterName = "envoy.filters.udp_listener.udp_proxy"
+	// TLSTransportSocketName -
+	TLSTransportSocketName = "envoy.transport_sockets.tls"
+	// udpProxyTypeURL the v3 udp proxy config has the same wire format as v2alpha
+	udpProxyTypeURL = "type.googleapis.com/envoy.extensions.filters.udp.udp_proxy.v3.UdpProxyConfig"
+)
+
+//DefaultLocalhostListenerAddress -
+var DefaultLocalhostListenerAddress = "127.0.0.1"
+
+// DefaultLocalhostListenerPort -
+var DefaultLocalhostListenerPort uint32 = 80
+
+//CreateTCPListener listener builder
+func CreateTCPListener(name, clusterName, address, statPrefix string, port uint32, idleTimeout int64) *listenerv3.Listener {
+	if address == "" {
+		address = DefaultLocalhostListenerAddress
+	}
+	tcpProxy := &tcpproxy.TcpProxy{
+		StatPrefix: statPrefix,
+		//todo:TcpProxy_WeightedClusters
+		ClusterSpecifier: &tcpproxy.TcpProxy_Cluster{
+			Cluster: clusterName,
+		},
+		IdleTimeout: ConverTimeDuration(idleTimeout),
+	}
+	if err := tcpProxy.Validate(); err != nil {
+		logrus.Errorf("validate listener tcp proxy config failure %s", err.Error())
+		return nil
+	}
+	listener := &listenerv3.Listener{
+		Name:    name,
+		Address: CreateSocketAddress("tcp", address, port),
+		FilterChains: []*listenerv3.FilterChain{
+			{
+				Filters: []*listenerv3.Filter{
+					{
+						Name:       TCPProxyFilterName,
+						ConfigType: &listenerv3.Filter_TypedConfig{TypedConfig: Message2Any(tcpProxy)},
+					},
+				},
+			},
+		},
+	}
+	if err := listener.Validate(); err != nil {
+		logrus.Errorf("validate listener config failure %s", err.Error())
+		return nil
+	}
+	return listener
+}
+
+//CreateUDPListener create udp listenner
+func CreateUDPListener(name, clusterName, address, statPrefix string, port uint32) *listenerv3.Listener {
+	if address == "" {
+		address = DefaultLocalhostListenerAddress
+	}
+	config := &udpproxy.UdpProxyConfig{
+		StatPrefix: statPrefix,
+		RouteSpecifier: &udpproxy.UdpProxyConfig_Cluster{
+			Cluster: clusterName,
+		},
+	}
+	if err := config.Validate(); err != nil {
+		logrus.Errorf("validate listener udp config failure %s", err.Error())
+		return nil
+	}
+	anyConfig, err := ptypes.MarshalAny(config)
+	if err != nil {
+		logrus.Errorf("marshal any failure %s", err.Error())
+		return nil
+	}
+	anyConfig.TypeUrl = udpProxyTypeURL
+	listener := &listenerv3.Listener{
+		Name:    name,
+		Address: CreateSocketAddress("udp", address, port),
+		ListenerFilters: []*listenerv3.ListenerFilter{
+			{
+				Name: UDPProxyFilterName,
+				ConfigType: &listenerv3.ListenerFilter_TypedConfig{
+					TypedConfig: anyConfig,
+				},
+			},
+		},
+		// Listening on UDP without SO_REUSEPORT socket option may result to unstable packet proxying. Consider configuring the reuse_port listener option.
+		ReusePort: true,
+	}
+	if err := listener.Validate(); err != nil {
+		logrus.Errorf("validate listener config failure %s", err.Error())
+		return nil
+	}
+	return listener
+}
+
+//RateLimitOptions rate limit options
+type RateLimitOptions struct {
+	Enable                bool
+	Domain                string
+	RateServerClusterName string
+	Stage                 uint32
+}
+
+//DefaultRateLimitServerClusterName default rate limit server cluster name
+var DefaultRateLimitServerClusterName = "rate_limit_service_cluster"
+
+//CreateHTTPRateLimit create http rate limit
+func CreateHTTPRateLimit(option RateLimitOptions) *httpratelimit.RateLimit {
+	httpRateLimit := &httpratelimit.RateLimit{
+		Domain: option.Domain,
+		Stage:  option.Stage,
+		RateLimitService: &ratelimitv3.RateLimitServiceConfig{
+			GrpcService: &corev3.GrpcService{
+				TargetSpecifier: &corev3.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &corev3.GrpcService_EnvoyGrpc{
+						ClusterName: option.RateServerClusterName,
+					},
+				},
+			},
+		},
+	}
+	if err := httpRateLimit.Validate(); err != nil {
+		logrus.Errorf("create http rate limit failure %s", err.Error())
+		return nil
+	}
+	logrus.Debugf("service http rate limit for domain %s", httpRateLimit.Domain)
+	return httpRateLimit
+}
+
+//CreateHTTPConnectionManager create http connection manager
+func CreateHTTPConnectionManager(name, statPrefix string, rateOpt *RateLimitOptions, routes ...*routev3.VirtualHost) *hcm.HttpConnectionManager {
+	var httpFilters []*hcm.HttpFilter
+	// the fault filter does nothing unless a route overrides its config
+	if hasRouteFilterConfig(HTTPFaultFilterName, routes...) {
+		httpFilters = append(httpFilters, &hcm.HttpFilter{
+			Name:       HTTPFaultFilterName,
+			ConfigType: &hcm.HttpFilter_TypedConfig{TypedConfig: Message2Any(&httpfault.HTTPFault{})},
+		})
+	}
+	if rateOpt != nil && rateOpt.Enable {
+		if rateLimit := CreateHTTPRateLimit(*rateOpt); rateLimit != nil {
+			httpFilters = append(httpFilters, &hcm.HttpFilter{
+				Name:       HTTPRateLimitFilterName,
+				ConfigType: &hcm.HttpFilter_TypedConfig{TypedConfig: Message2Any(rateLimit)},
+			})
+		}
+	}
+	httpFilters = append(httpFilters, &hcm.HttpFilter{
+		Name:       RouterFilterName,
+		ConfigType: &hcm.HttpFilter_TypedConfig{TypedConfig: Message2Any(&router.Router{})},
+	})
+	manager := &hcm.HttpConnectionManager{
+		StatPrefix: statPrefix,
+		RouteSpecifier: &hcm.HttpConnectionManager_RouteConfig{
+			RouteConfig: &routev3.RouteConfiguration{
+				Name:         name,
+				VirtualHosts: routes,
+			},
+		},
+		HttpFilters: httpFilters,
+	}
+	if err := manager.Validate(); err != nil {
+		logrus.Errorf("validate http connertion manager config failure %s", err.Error())
+		return nil
+	}
+	return manager
+}
+
+func hasRouteFilterConfig(filterName string, virtualHosts ...*routev3.VirtualHost) bool {
+	for _, vh := range virtualHosts {
+		for _, route := range vh.GetRoutes() {
+			if _, ok := route.GetTypedPerFilterConfig()[filterName]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//CreateHTTPListener create http manager listener
+func CreateHTTPListener(name, address, statPrefix string, port uint32, rateOpt *RateLimitOptions, routes ...*routev3.VirtualHost) *listenerv3.Listener {
+	manager := CreateHTTPConnectionManager(name, statPrefix, rateOpt, routes...)
+	if manager == nil {
+		logrus.Warningf("create http connection manager failure %s", name)
+		return nil
+	}
+	listener := &listenerv3.Listener{
+		Name:    name,
+		Address: CreateSocketAddress("tcp", address, port),
+		FilterChains: []*listenerv3.FilterChain{
+			{
+				Filters: []*listenerv3.Filter{
+					{
+						Name:       HTTPConnectionManagerFilterName,
+						ConfigType: &listenerv3.Filter_TypedConfig{TypedConfig: Message2Any(manager)},
+					},
+				},
+			},
+		},
+	}
+	if err := listener.Validate(); err != nil {
+		logrus.Errorf("validate listener config failure %s", err.Error())
+		return nil
+	}
+	return listener
+}
+
+//CreateSocketAddress create socket address
+func CreateSocketAddress(protocol, address string, port uint32) *corev3.Address {
+	if strings.HasPrefix(address, "https://") {
+		address = strings.Split(address, "https://")[1]
+	}
+	if strings.HasPrefix(address, "http://") {
+		address = strings.Split(address, "http://")[1]
+	}
+	return &corev3.Address{
+		Address: &corev3.Address_SocketAddress{
+			SocketAddress: &corev3.SocketAddress{
+				Protocol: func(protocol string) corev3.SocketAddress_Protocol {
+					if protocol == "udp" {
+						return corev3.SocketAddress_UDP
+					}
+					return corev3.SocketAddress_TCP
+				}(protocol),
+				Address: address,
+				PortSpecifier: &corev3.SocketAddress_PortValue{
+					PortValue: port,
+				},
+			},
+		},
+	}
+}
+
+//CreateCircuitBreaker create down cluster circuitbreaker
+func CreateCircuitBreaker(options RainbondPluginOptions) *clusterv3.CircuitBreakers {
+	circuitBreakers := &clusterv3.CircuitBreakers{
+		Thresholds: []*clusterv3.CircuitBreakers_Thresholds{
+			{
+				Priority:           corev3.RoutingPriority_DEFAULT,
+				MaxConnections:     ConversionUInt32(uint32(options.MaxConnections)),
+				MaxRequests:        ConversionUInt32(uint32(options.MaxRequests)),
+				MaxRetries:         ConversionUInt32(uint32(options.MaxActiveRetries)),
+				MaxPendingRequests: ConversionUInt32(uint32(options.MaxPendingRequests)),
+				// expose the remaining capacity of the thresholds as gauges
+				TrackRemaining: true,
+			},
+		},
+	}
+	if err := circuitBreakers.Validate(); err != nil {
+		logrus.Errorf("validate envoy config circuitBreakers failure %s", err.Error())
+		return nil
+	}
+	return circuitBreakers
+}
+
+//CreatOutlierDetection create up cluster OutlierDetection
+func CreatOutlierDetection(options RainbondPluginOptions) *clusterv3.OutlierDetection {
+	outlierDetection := &clusterv3.OutlierDetection{
+		Interval:           ConverMillisecondDuration(options.IntervalMS),
+		BaseEjectionTime:   ConverMillisecondDuration(options.BaseEjectionTimeMS),
+		MaxEjectionPercent: ConversionUInt32(uint32(options.MaxEjectionPercent)),
+		Consecutive_5Xx:    ConversionUInt32(uint32(options.ConsecutiveErrors)),
+	}
+	if err := outlierDetection.Validate(); err != nil {
+		logrus.Errorf("validate envoy config outlierDetection failure %s", err.Error())
+		return nil
+	}
+	return outlierDetection
+}
+
+//CreateRouteVirtualHost create route virtual host
+func CreateRouteVirtualHost(name string, domains []string, rateLimits []*routev3.RateLimit, routes ...*routev3.Route) *routev3.VirtualHost {
+	pvh := &routev3.VirtualHost{
+		Name:       name,
+		Domains:    domains,
+		Routes:     routes,
+		RateLimits: rateLimits,
+	}
+	if err := pvh.Validate(); err != nil {
+		logrus.Errorf("route virtualhost config validate failure %s domains %s", err.Error(), domains)
+		return nil
+	}
+	return pvh
+}
+
+//CreateRouteWithHostRewrite create route with hostRewrite
+func CreateRouteWithHostRewrite(host, clusterName, prefix string, headers []*routev3.HeaderMatcher, weight uint32) *routev3.Route {
+	var rout *routev3.Route
+	if host != "" {
+		if strings.HasPrefix(host, "https://") {
+			host = strings.Split(host, "https://")[1]
+		}
+		if strings.HasPrefix(host, "http://") {
+			host = strings.Split(host, "http://")[1]
+		}
+		rout = &routev3.Route{
+			Match: &routev3.RouteMatch{
+				PathSpecifier: &routev3.RouteMatch_Prefix{
+					Prefix: prefix,
+				},
+				Headers: headers,
+			},
+			Action: &routev3.Route_Route{
+				Route: &routev3.RouteAction{
+					ClusterSpecifier: &routev3.RouteAction_Cluster{
+						Cluster: clusterName,
+					},
+					Priority: corev3.RoutingPriority_DEFAULT,
+					HostRewriteSpecifier: &routev3.RouteAction_HostRewriteLiteral{
+						HostRewriteLiteral: host,
+					},
+				},
+			},
+		}
+		if err := rout.Validate(); err != nil {
+			logrus.Errorf("route http route config validate failure %s", err.Error())
+			return nil
+		}
+
+	}
+	return rout
+}
+
+//CreateRoute create http route
+func CreateRoute(clusterName, prefix string, headers []*routev3.HeaderMatcher, weight uint32) *routev3.Route {
+	rout := &routev3.Route{
+		Match: &routev3.RouteMatch{
+			PathSpecifier: &routev3.RouteMatch_Prefix{
+				Prefix: prefix,
+			},
+			Headers: headers,
+		},
+		Action: &routev3.Route_Route{
+			Route: &routev3.RouteAction{
+				ClusterSpecifier: &routev3.RouteAction_WeightedClusters{
+					WeightedClusters: &routev3.WeightedCluster{
+						Clusters: []*routev3.WeightedCluster_ClusterWeight{
+							{
+								Name:   clusterName,
+								Weight: ConversionUInt32(weight),
+							},
+						},
+					},
+				},
+				Priority: corev3.RoutingPriority_DEFAULT,
+			},
+		},
+	}
+
+	if err := rout.Validate(); err != nil {
+		logrus.Errorf("route http route config validate failure %s", err.Error())
+		return nil
+	}
+	return rout
+}
+
+//CreateRetryPolicy create route retry policy, return nil if retry is not enabled
+func CreateRetryPolicy(options RainbondPluginOptions) *routev3.RetryPolicy {
+	if options.RetryOn == "" && options.NumRetries == 0 {
+		return nil
+	}
+	retryPolicy := &routev3.RetryPolicy{
+		RetryOn: options.RetryOn,
+	}
+	if retryPolicy.RetryOn == "" {
+		retryPolicy.RetryOn = "5xx,connect-failure"
+	}
+	if options.NumRetries > 0 {
+		retryPolicy.NumRetries = ConversionUInt32(options.NumRetries)
+	}
+	if options.PerTryTimeoutMS > 0 {
+		retryPolicy.PerTryTimeout = ConverMillisecondDuration(options.PerTryTimeoutMS)
+	}
+	if options.RetryBaseIntervalMS > 0 {
+		retryPolicy.RetryBackOff = &routev3.RetryPolicy_RetryBackOff{
+			BaseInterval: ConverMillisecondDuration(options.RetryBaseIntervalMS),
+		}
+		if options.RetryMaxIntervalMS >= options.RetryBaseIntervalMS {
+			retryPolicy.RetryBackOff.MaxInterval = ConverMillisecondDuration(options.RetryMaxIntervalMS)
+		}
+	}
+	if err := retryPolicy.Validate(); err != nil {
+		logrus.Errorf("validate route retry policy failure %s", err.Error())
+		return nil
+	}
+	return retryPolicy
+}
+
+//CreateHTTPFault create http fault injection config, return nil if no fault is injected
+func CreateHTTPFault(options RainbondPluginOptions) *httpfault.HTTPFault {
+	var fault httpfault.HTTPFault
+	if options.FaultDelayPercent > 0 && options.FaultDelayMS > 0 {
+		fault.Delay = &commonfault.FaultDelay{
+			FaultDelaySecifier: &commonfault.FaultDelay_FixedDelay{
+				FixedDelay: ConverMillisecondDuration(options.FaultDelayMS),
+			},
+			Percentage: &typev3.FractionalPercent{
+				Numerator:   options.FaultDelayPercent,
+				Denominator: typev3.FractionalPercent_HUNDRED,
+			},
+		}
+	}
+	if options.FaultAbortPercent > 0 {
+		fault.Abort = &httpfault.FaultAbort{
+			ErrorType: &httpfault.FaultAbort_HttpStatus{
+				HttpStatus: options.FaultAbortHTTPStatus,
+			},
+			Percentage: &typev3.FractionalPercent{
+				Numerator:   options.FaultAbortPercent,
+				Denominator: typev3.FractionalPercent_HUNDRED,
+			},
+		}
+	}
+	if fault.Delay == nil && fault.Abort == nil {
+		return nil
+	}
+	if err := fault.Validate(); err != nil {
+		logrus.Errorf("validate http fault config failure %s", err.Error())
+		return nil
+	}
+	return &fault
+}
+
+//ApplyRoutePolicy set the timeout, retry policy and fault injection of the plugin options to the route
+func ApplyRoutePolicy(route *routev3.Route, options RainbondPluginOptions) *routev3.Route {
+	action := route.GetRoute()
+	if action == nil {
+		return route
+	}
+	if options.RequestTimeoutMS > 0 {
+		action.Timeout = ConverMillisecondDuration(options.RequestTimeoutMS)
+	}
+	action.RetryPolicy = CreateRetryPolicy(options)
+	if fault := CreateHTTPFault(options); fault != nil {
+		if route.TypedPerFilterConfig == nil {
+			route.TypedPerFilterConfig = make(map[string]*any.Any)
+		}
+		route.TypedPerFilterConfig[HTTPFaultFilterName] = Message2Any(fault)
+	}
+	return route
+}
+
+//CreateHeaderMatcher create http route config header matcher
+func CreateHeaderMatcher(header v1.Header) *routev3.HeaderMatcher {
+	if header.Name == "" {
+		return nil
+	}
+	headerMatcher := &routev3.HeaderMatcher{
+		Name: header.Name,
+		HeaderMatchSpecifier: &routev3.HeaderMatcher_PrefixMatch{
+			PrefixMatch: header.Value,
+		},
+	}
+	if err := headerMatcher.Validate(); err != nil {
+		logrus.Errorf("route http header(%s) matcher config validate failure %s", header.Name, err.Error())
+		return nil
+	}
+	return headerMatcher
+}
+
+//CreateEDSClusterConfig create eds cluster config, the endpoints are discovered with ADS
+func CreateEDSClusterConfig(serviceName string) *clusterv3.Cluster_EdsClusterConfig {
+	edsClusterConfig := &clusterv3.Cluster_EdsClusterConfig{
+		EdsConfig: &corev3.ConfigSource{
+			ConfigSourceSpecifier: &corev3.ConfigSource_Ads{
+				Ads: &corev3.AggregatedConfigSource{},
+			},
+			ResourceApiVersion: corev3.ApiVersion_V3,
+		},
+		ServiceName: serviceName,
+	}
+	if err := edsClusterConfig.Validate(); err != nil {
+		logrus.Errorf("validate eds cluster config failure %s", err.Error())
+		return nil
+	}
+	return edsClusterConfig
+}
+
+//ClusterOptions cluster options
+type ClusterOptions struct {
+	Name                     string
+	ServiceName              string
+	ConnectionTimeout        *duration.Duration
+	ClusterType              clusterv3.Cluster_DiscoveryType
+	MaxRequestsPerConnection *uint32
+	OutlierDetection         *clusterv3.OutlierDetection
+	CircuitBreakers          *clusterv3.CircuitBreakers
+	Hosts                    []*corev3.Address
+	HealthyPanicThreshold    int64
+	TransportSocket          *corev3.TransportSocket
+	LoadAssignment           *endpointv3.ClusterLoadAssignment
+	Protocol                 string
+	// grpc service name of health check
+	GrpcHealthServiceName string
+	//health check
+	HealthTimeout  int64
+	HealthInterval int64
+}
+
+//CreateCluster create cluster config
+func CreateCluster(options ClusterOptions) *clusterv3.Cluster {
+	var edsClusterConfig *clusterv3.Cluster_EdsClusterConfig
+	if options.ClusterType == clusterv3.Cluster_EDS {
+		edsClusterConfig = CreateEDSClusterConfig(options.ServiceName)
+		if edsClusterConfig == nil {
+			logrus.Errorf("create eds cluster config failure")
+			return nil
+		}
+	}
+	cluster := &clusterv3.Cluster{
+		Name:                 options.Name,
+		ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: options.ClusterType},
+		ConnectTimeout:       options.ConnectionTimeout,
+		LbPolicy:             clusterv3.Cluster_ROUND_ROBIN,
+		EdsClusterConfig:     edsClusterConfig,
+		OutlierDetection:     options.OutlierDetection,
+		CircuitBreakers:      options.CircuitBreakers,
+		CommonLbConfig: &clusterv3.Cluster_CommonLbConfig{
+			HealthyPanicThreshold: &typev3.Percent{Value: float64(options.HealthyPanicThreshold) / 100},
+		},
+	}
+	if options.Protocol == "http2" || options.Protocol == "grpc" {
+		cluster.Http2ProtocolOptions = &corev3.Http2ProtocolOptions{}
+		// set grpc health check
+		if options.Protocol == "grpc" && options.GrpcHealthServiceName != "" {
+			cluster.HealthChecks = append(cluster.HealthChecks, &corev3.HealthCheck{
+				Timeout:  ConverTimeDuration(options.HealthTimeout),
+				Interval: ConverTimeDuration(options.HealthInterval),
+				//The number of unhealthy health checks required before a host is marked unhealthy.
+				//Note that for http health checking if a host responds with 503 this threshold is ignored and the host is considered unhealthy immediately.
+				UnhealthyThreshold: ConversionUInt32(2),
+				//The number of healthy health checks required before a host is marked healthy.
+				//Note that during startup, only a single successful health check is required to mark a host healthy.
+				HealthyThreshold: ConversionUInt32(1),
+				HealthChecker: &corev3.HealthCheck_GrpcHealthCheck_{
+					GrpcHealthCheck: &corev3.HealthCheck_GrpcHealthCheck{
+						ServiceName: options.GrpcHealthServiceName,
+					},
+				}})
+		}
+	}
+	if options.TransportSocket != nil {
+		cluster.TransportSocket = options.TransportSocket
+	}
+	if options.LoadAssignment != nil {
+		cluster.LoadAssignment = options.LoadAssignment
+	} else if len(options.Hosts) > 0 {
+		// v3 cluster has no hosts field, static hosts are set in the load assignment
+		var lbe []*endpointv3.LbEndpoint
+		for _, host := range options.Hosts {
+			lbe = append(lbe, &endpointv3.LbEndpoint{
+				HostIdentifier: &endpointv3.LbEndpoint_Endpoint{
+					Endpoint: &endpointv3.Endpoint{Address: host},
+				},
+			})
+		}
+		cluster.LoadAssignment = &endpointv3.ClusterLoadAssignment{
+			ClusterName: options.Name,
+			Endpoints:   []*endpointv3.LocalityLbEndpoints{{LbEndpoints: lbe}},
+		}
+	}
+	if options.MaxRequestsPerConnection != nil {
+		cluster.MaxRequestsPerConnection = ConversionUInt32(*options.MaxRequestsPerConnection)
+	}
+	if err := cluster.Validate(); err != nil {
+		logrus.Errorf("validate cluster config failure %s", err.Error())
+		return nil
+	}
+	return cluster
+}
+
+//GetServiceAliasByService get service alias from k8s service
+func GetServiceAliasByService(service *corev1.Service) string {
+	//v5.1 and later
+	if serviceAlias, ok := service.Labels["service_alias"]; ok {
+		return serviceAlias
+	}
+	//version before v5.1
+	if serviceAlias, ok := service.Spec.Selector["name"]; ok {
+		return serviceAlias
+	}
+	return ""
+}
+
+//CreateDNSLoadAssignment create dns loadAssignment
+func CreateDNSLoadAssignment(serviceAlias, namespace, domain string, service *corev1.Service, p corev1.ServicePort) *endpointv3.ClusterLoadAssignment {
+	destServiceAlias := GetServiceAliasByService(service)
+	if destServiceAlias == "" {
+		logrus.Errorf("service alias is empty in k8s service %s", service.Name)
+		return nil
+	}
+
+	clusterName := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, destServiceAlias, p.Port)
+	var lendpoints []*endpointv3.LocalityLbEndpoints
+	port := p.Port
+	portProtocol := fmt.Sprintf("port_protocol_%v", port)
+	protocol := service.Labels[portProtocol]
+	var lbe []*endpointv3.LbEndpoint
+	envoyAddress := CreateSocketAddress(protocol, domain, uint32(port))
+	lbe = append(lbe, &endpointv3.LbEndpoint{
+		HostIdentifier: &endpointv3.LbEndpoint_Endpoint{
+			Endpoint: &endpointv3.Endpoint{
+				Address:           envoyAddress,
+				HealthCheckConfig: &endpointv3.Endpoint_HealthCheckConfig{PortValue: uint32(port)},
+			},
+		},
+	})
+	lendpoints = append(lendpoints, &endpointv3.LocalityLbEndpoints{LbEndpoints: lbe})
+	cla := &endpointv3.ClusterLoadAssignment{
+		ClusterName: clusterName,
+		Endpoints:   lendpoints,
+	}
+	if err := cla.Validate(); err != nil {
+		logrus.Errorf("endpoints discover validate failure %s", err.Error())
+	}
+
+	return cla
+}
@@ -0,0 +1,170 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package v3
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/sirupsen/logrus"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+)
+
+const (
+	// MeshMTLSModeAnnotation the annotation of the plugin config, it is PERMISSIVE or STRICT if mtls is enabled
+	MeshMTLSModeAnnotation = "mesh_mtls_mode"
+	// MeshMTLSPermissivePortsAnnotation the ports which accept plaintext in the STRICT mode, such as the ports open to the gateway
+	MeshMTLSPermissivePortsAnnotation = "mesh_mtls_permissive_ports"
+	// MeshMTLSServiceLabel the label of the k8s service whose endpoints accept mtls
+	MeshMTLSServiceLabel = "mesh_mtls"
+	// MeshMTLSModePermissive inbound listeners accept both mtls and plaintext
+	MeshMTLSModePermissive = "PERMISSIVE"
+	// MeshMTLSModeStrict inbound listeners only accept mtls
+	MeshMTLSModeStrict = "STRICT"
+
+	// DefaultTrustDomain the trust domain of the spiffe ids
+	DefaultTrustDomain = "cluster.local"
+	// SDSCertificateName the name of the secret of the sidecar certificate
+	SDSCertificateName = "default"
+	// SDSRootCAName the name of the secret of the mesh root ca
+	SDSRootCAName = "ROOTCA"
+	// TLSInspectorFilterName -
+	TLSInspectorFilterName = "envoy.filters.listener.tls_inspector"
+)
+
+// SpiffeID returns the identity of a component
+func SpiffeID(trustDomain, namespace, serviceAlias string) string {
+	return fmt.Sprintf("spiffe://%s/ns/%s/sa/%s", trustDomain, namespace, serviceAlias)
+}
+
+// MTLSEnabled returns whether the mode enables mtls
+func MTLSEnabled(mode string) bool {
+	return mode == MeshMTLSModePermissive || mode == MeshMTLSModeStrict
+}
+
+// ParsePorts parses a comma separated port list
+func ParsePorts(value string) map[int]bool {
+	ports := make(map[int]bool)
+	for _, p := range strings.Split(value, ",") {
+		if port, err := strconv.Atoi(strings.TrimSpace(p)); err == nil {
+			ports[port] = true
+		}
+	}
+	return ports
+}
+
+// CreateTLSCertificateSecret create the sds secret of the sidecar certificate
+func CreateTLSCertificateSecret(certPEM, keyPEM []byte) *tlsv3.Secret {
+	return &tlsv3.Secret{
+		Name: SDSCertificateName,
+		Type: &tlsv3.Secret_TlsCertificate{
+			TlsCertificate: &tlsv3.TlsCertificate{
+				CertificateChain: &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: certPEM}},
+				PrivateKey:       &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: keyPEM}},
+			},
+		},
+	}
+}
+
+// CreateRootCASecret create the sds secret of the root ca
+func CreateRootCASecret(caPEM []byte) *tlsv3.Secret {
+	return &tlsv3.Secret{
+		Name: SDSRootCAName,
+		Type: &tlsv3.Secret_ValidationContext{
+			ValidationContext: &tlsv3.CertificateValidationContext{
+				TrustedCa: &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: caPEM}},
+			},
+		},
+	}
+}
+
+func sdsSecretConfig(name string) *tlsv3.SdsSecretConfig {
+	return &tlsv3.SdsSecretConfig{
+		Name: name,
+		SdsConfig: &corev3.ConfigSource{
+			ConfigSourceSpecifier: &corev3.ConfigSource_Ads{Ads: &corev3.AggregatedConfigSource{}},
+			ResourceApiVersion:    corev3.ApiVersion_V3,
+		},
+	}
+}
+
+// createCommonTLSContext the certificate and the root ca are both fetched by sds,
+// the peer certificate must be issued for one of the spiffe ids if given.
+func createCommonTLSContext(peerIDs ...string) *tlsv3.CommonTlsContext {
+	var matchers []*matcherv3.StringMatcher
+	for _, id := range peerIDs {
+		matchers = append(matchers, &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Exact{Exact: id}})
+	}
+	return &tlsv3.CommonTlsContext{
+		TlsCertificateSdsSecretConfigs: []*tlsv3.SdsSecretConfig{sdsSecretConfig(SDSCertificateName)},
+		ValidationContextType: &tlsv3.CommonTlsContext_CombinedValidationContext{
+			CombinedValidationContext: &tlsv3.CommonTlsContext_CombinedCertificateValidationContext{
+				DefaultValidationContext:         &tlsv3.CertificateValidationContext{MatchSubjectAltNames: matchers},
+				ValidationContextSdsSecretConfig: sdsSecretConfig(SDSRootCAName),
+			},
+		},
+	}
+}
+
+func createTransportSocket(tlsContext proto.Message) *corev3.TransportSocket {
+	return &corev3.TransportSocket{
+		Name:       TLSTransportSocketName,
+		ConfigType: &corev3.TransportSocket_TypedConfig{TypedConfig: Message2Any(tlsContext)},
+	}
+}
+
+// EnableUpstreamMTLS originates mtls to the cluster, the server must present the certificate of the peer id
+func EnableUpstreamMTLS(cluster *clusterv3.Cluster, peerID string) {
+	cluster.TransportSocket = createTransportSocket(&tlsv3.UpstreamTlsContext{
+		CommonTlsContext: createCommonTLSContext(peerID),
+	})
+}
+
+// EnableDownstreamMTLS requires the clients of the listener to present a certificate issued by the mesh ca,
+// in permissive mode, a plaintext filter chain is kept and the tls inspector chooses the chain by the connection.
+func EnableDownstreamMTLS(listener *listenerv3.Listener, permissive bool) *listenerv3.Listener {
+	if len(listener.FilterChains) == 0 || listener.GetAddress().GetSocketAddress().GetProtocol() == corev3.SocketAddress_UDP {
+		return listener
+	}
+	plaintext := listener.FilterChains[0]
+	mtls := proto.Clone(plaintext).(*listenerv3.FilterChain)
+	mtls.TransportSocket = createTransportSocket(&tlsv3.DownstreamTlsContext{
+		CommonTlsContext:         createCommonTLSContext(),
+		RequireClientCertificate: &wrappers.BoolValue{Value: true},
+	})
+	if !permissive {
+		listener.FilterChains = []*listenerv3.FilterChain{mtls}
+	} else {
+		mtls.FilterChainMatch = &listenerv3.FilterChainMatch{TransportProtocol: "tls"}
+		listener.FilterChains = []*listenerv3.FilterChain{mtls, plaintext}
+		listener.ListenerFilters = append(listener.ListenerFilters, &listenerv3.ListenerFilter{Name: TLSInspectorFilterName})
+	}
+	if err := listener.Validate(); err != nil {
+		logrus.Errorf("validate mtls listener config failure %s", err.Error())
+	}
+	return listener
+}
@@ -16,7 +16,7 @@
 // You should have received a copy of the GNU General Public License
 // along with this program. If not, see <http://www.gnu.org/licenses/>.
 
-package v2
+package v3
 
 import (
 	"crypto/sha256"
@@ -24,34 +24,24 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/envoyproxy/go-control-plane/pkg/conversion"
-	"github.com/gogo/protobuf/proto"
-	"github.com/gogo/protobuf/types"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/sirupsen/logrus"
 
-	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
-	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
-	rsrc "github.com/envoyproxy/go-control-plane/pkg/resource/v2"
-	_struct "github.com/golang/protobuf/ptypes/struct"
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	rsrc "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 
 	v1 "github.com/goodrain/rainbond/node/core/envoy/v1"
 )
 
-// MessageToStruct converts from proto message to proto Struct
-func MessageToStruct(msg proto.Message) *_struct.Struct {
-	s, err := conversion.MessageToStruct(msg)
-	if err != nil {
-		logrus.Error(err.Error())
-		return &_struct.Struct{}
-	}
-	return s
-}
-
 // Message2Any converts from proto message to proto any
 func Message2Any(msg proto.Message) *any.Any {
 	a, err := ptypes.MarshalAny(msg)
@@ -69,13 +59,6 @@ func ConversionUInt32(value uint32) *wrappers.UInt32Value {
 	}
 }
 
-//ConversionTypeUInt32 conversion uint32 to proto uint32
-func ConversionTypeUInt32(value uint32) *types.UInt32Value {
-	return &types.UInt32Value{
-		Value: value,
-	}
-}
-
 //ConverTimeDuration second
 func ConverTimeDuration(second int64) *duration.Duration {
 	return &duration.Duration{
@@ -83,58 +66,10 @@ func ConverTimeDuration(second int64) *duration.Duration {
 	}
 }
 
-const (
-	//KeyPrefix request path prefix
-	KeyPrefix string = "Prefix"
-	//KeyHeaders request http headers
-	KeyHeaders string = "Headers"
-	//KeyDomains request domains
-	KeyDomains string = "Domains"
-	//KeyMaxConnections The maximum number of connections that Envoy will make to the upstream cluster. If not specified, the default is 1024.
-	KeyMaxConnections string = "MaxConnections"
-	//KeyMaxPendingRequests The maximum number of pending requests that Envoy will allow to the upstream cluster. If not specified, the default is 1024
-	KeyMaxPendingRequests string = "MaxPendingRequests"
-	//KeyMaxRequests The maximum number of parallel requests that Envoy will make to the upstream cluster. If not specified, the default is 1024.
-	KeyMaxRequests string = "MaxRequests"
-	//KeyMaxActiveRetries  The maximum number of parallel retries that Envoy will allow to the upstream cluster. If not specified, the default is 3.
-	KeyMaxActiveRetries string = "MaxActiveRetries"
-	//KeyUpStream upStream
-	KeyUpStream string = "upStream"
-	//KeyDownStream downStream
-	KeyDownStream string = "downStream"
-	//KeyWeight WEIGHT
-	KeyWeight string = "Weight"
-	//KeyWeightModel MODEL_WEIGHT
-	KeyWeightModel string = "weight_model"
-	//KeyPrefixModel MODEL_PREFIX
-	KeyPrefixModel string = "prefix_model"
-	//KeyIntervalMS IntervalMS key
-	KeyIntervalMS string = "IntervalMS"
-	//KeyConsecutiveErrors ConsecutiveErrors key
-	KeyConsecutiveErrors string = "ConsecutiveErrors"
-	//KeyBaseEjectionTimeMS BaseEjectionTimeMS key
-	KeyBaseEjectionTimeMS string = "BaseEjectionTimeMS"
-	//KeyMaxEjectionPercent MaxEjectionPercent key
-	KeyMaxEjectionPercent string = "MaxEjectionPercent"
-	// KeyMaxRequestsPerConnection Optional maximum requests for a single upstream connection. This parameter
-	// is respected by both the HTTP/1.1 and HTTP/2 connection pool
-	// implementations. If not specified, there is no limit. Setting this
-	// parameter to 1 will effectively disable keep alive.
-	KeyMaxRequestsPerConnection string = "MaxRequestsPerConnection"
-	// KeyHealthyPanicThreshold default 50,More than 50% of hosts are ejected and go into panic mode
-	// Panic mode will send traffic back to the failed host
-	KeyHealthyPanicThreshold string = "HealthyPanicThreshold"
-	//KeyConnectionTimeout connection timeout setting
-	KeyConnectionTimeout string = "ConnectionTimeout"
-	//KeyTCPIdleTimeout tcp idle timeout
-	KeyTCPIdleTimeout string = "TCPIdleTimeout"
-	//KeyGrpcHealthServiceName The name of the grpc service used for health checking.
-	KeyGrpcHealthServiceName string = "GrpcHealthServiceName"
-	// cluster health check timeout
-	KeyHealthCheckTimeout string = "HealthCheckTimeout"
-	// cluster health check interval
-	KeyHealthCheckInterval string = "HealthCheckInterval"
-)
+//ConverMillisecondDuration millisecond
+func ConverMillisecondDuration(ms int64) *duration.Duration {
+	return ptypes.DurationProto(time.Duration(ms) * time.Millisecond)
+}
 
 //RainbondPluginOptions rainbond plugin config struct
 type RainbondPluginOptions struct {
@@ -146,7 +81,7 @@ type RainbondPluginOptions struct {
 	Headers                  v1.Headers
 	Domains                  []string
 	Weight                   uint32
-	Interval                 int64
+	IntervalMS               int64
 	ConsecutiveErrors        int
 	BaseEjectionTimeMS       int64
 	MaxEjectionPercent       int
@@ -157,6 +92,16 @@ type RainbondPluginOptions struct {
 	GrpcHealthServiceName    string
 	HealthCheckTimeout       int64
 	HealthCheckInterval      int64
+	RequestTimeoutMS         int64
+	RetryOn                  string
+	NumRetries               uint32
+	PerTryTimeoutMS          int64
+	RetryBaseIntervalMS      int64
+	RetryMaxIntervalMS       int64
+	FaultDelayPercent        uint32
+	FaultDelayMS             int64
+	FaultAbortPercent        uint32
+	FaultAbortHTTPStatus     uint32
 }
 
 //RainbondInboundPluginOptions rainbond inbound plugin options
@@ -188,7 +133,7 @@ func GetOptionValues(sr map[string]interface{}) RainbondPluginOptions {
 		MaxActiveRetries:      3,
 		Domains:               []string{"*"},
 		Weight:                100,
-		Interval:              10,
+		IntervalMS:            10000,
 		ConsecutiveErrors:     5,
 		BaseEjectionTimeMS:    30000,
 		MaxEjectionPercent:    10,
@@ -197,31 +142,32 @@ func GetOptionValues(sr map[string]interface{}) RainbondPluginOptions {
 		TCPIdleTimeout:        60 * 60 * 2,
 		HealthCheckTimeout:    5,
 		HealthCheckInterval:   4,
+		FaultAbortHTTPStatus:  503,
 	}
 	if sr == nil {
 		return rpo
 	}
 	for kind, v := range sr {
 		switch kind {
-		case KeyPrefix:
+		case v1.KeyPrefix:
 			rpo.Prefix = strings.TrimSpace(v.(string))
-		case KeyMaxConnections:
+		case v1.KeyMaxConnections:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				rpo.MaxConnections = i
 			}
-		case KeyMaxRequests:
+		case v1.KeyMaxRequests:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				rpo.MaxRequests = i
 			}
-		case KeyMaxPendingRequests:
+		case v1.KeyMaxPendingRequests:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				rpo.MaxPendingRequests = i
 			}
-		case KeyMaxActiveRetries:
+		case v1.KeyMaxActiveRetries:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				rpo.MaxActiveRetries = i
 			}
-		case KeyHeaders:
+		case v1.KeyHeaders:
 			parents := strings.Split(v.(string), ";")
 			var hm v1.Header
 			for _, h := range parents {
@@ -236,29 +182,29 @@ func GetOptionValues(sr map[string]interface{}) RainbondPluginOptions {
 				}
 			}
 			rpo.Headers = append(rpo.Headers, hm)
-		case KeyDomains:
+		case v1.KeyDomains:
 			if strings.Contains(v.(string), ",") {
 				rpo.Domains = strings.Split(v.(string), ",")
 			} else if v.(string) != "" {
 				rpo.Domains = []string{v.(string)}
 			}
-		case KeyWeight:
+		case v1.KeyWeight:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				rpo.Weight = uint32(i)
 			}
-		case KeyIntervalMS:
-			if i, err := strconv.Atoi(v.(string)); err == nil && i < 0 {
-				rpo.Interval = int64(i)
+		case v1.KeyIntervalMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.IntervalMS = int64(i)
 			}
-		case KeyConsecutiveErrors:
+		case v1.KeyConsecutiveErrors:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				rpo.ConsecutiveErrors = i
 			}
-		case KeyBaseEjectionTimeMS:
+		case v1.KeyBaseEjectionTimeMS:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				rpo.BaseEjectionTimeMS = int64(i)
 			}
-		case KeyMaxEjectionPercent:
+		case v1.KeyMaxEjectionPercent:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				if i > 100 {
 					rpo.MaxEjectionPercent = 100
@@ -266,12 +212,12 @@ func GetOptionValues(sr map[string]interface{}) RainbondPluginOptions {
 					rpo.MaxEjectionPercent = i
 				}
 			}
-		case KeyMaxRequestsPerConnection:
+		case v1.KeyMaxRequestsPerConnection:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				value := uint32(i)
 				rpo.MaxRequestsPerConnection = &value
 			}
-		case KeyHealthyPanicThreshold:
+		case v1.KeyHealthyPanicThreshold:
 			if i, err := strconv.Atoi(v.(string)); err == nil && i != 0 {
 				if i > 100 {
 					rpo.HealthyPanicThreshold = 100
@@ -279,29 +225,75 @@ func GetOptionValues(sr map[string]interface{}) RainbondPluginOptions {
 					rpo.HealthyPanicThreshold = int64(i)
 				}
 			}
-		case KeyConnectionTimeout:
+		case v1.KeyConnectionTimeout:
 			if i, err := strconv.Atoi(v.(string)); err == nil {
 				rpo.ConnectionTimeout = int64(i)
 			}
-		case KeyTCPIdleTimeout:
+		case v1.KeyTCPIdleTimeout:
 			if i, err := strconv.Atoi(v.(string)); err == nil {
 				rpo.TCPIdleTimeout = int64(i)
 			}
-		case KeyHealthCheckInterval:
+		case v1.KeyHealthCheckInterval:
 			if i, err := strconv.Atoi(v.(string)); err == nil {
 				rpo.HealthCheckInterval = int64(i)
 			}
-		case KeyHealthCheckTimeout:
+		case v1.KeyHealthCheckTimeout:
 			if i, err := strconv.Atoi(v.(string)); err == nil {
 				rpo.HealthCheckTimeout = int64(i)
 			}
-		case KeyGrpcHealthServiceName:
+		case v1.KeyGrpcHealthServiceName:
 			rpo.GrpcHealthServiceName = strings.TrimSpace(v.(string))
+		case v1.KeyRequestTimeoutMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.RequestTimeoutMS = int64(i)
+			}
+		case v1.KeyRetryOn:
+			rpo.RetryOn = strings.Replace(v.(string), " ", "", -1)
+		case v1.KeyNumRetries:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.NumRetries = uint32(i)
+			}
+		case v1.KeyPerTryTimeoutMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.PerTryTimeoutMS = int64(i)
+			}
+		case v1.KeyRetryBaseIntervalMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.RetryBaseIntervalMS = int64(i)
+			}
+		case v1.KeyRetryMaxIntervalMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.RetryMaxIntervalMS = int64(i)
+			}
+		case v1.KeyFaultDelayPercent:
+			rpo.FaultDelayPercent = parsePercent(v.(string))
+		case v1.KeyFaultDelayMS:
+			if i, err := strconv.Atoi(v.(string)); err == nil && i > 0 {
+				rpo.FaultDelayMS = int64(i)
+			}
+		case v1.KeyFaultAbortPercent:
+			rpo.FaultAbortPercent = parsePercent(v.(string))
+		case v1.KeyFaultAbortHTTPStatus:
+			// envoy only accepts status in [200, 600)
+			if i, err := strconv.Atoi(v.(string)); err == nil && i >= 200 && i < 600 {
+				rpo.FaultAbortHTTPStatus = uint32(i)
+			}
 		}
 	}
 	return rpo
 }
 
+func parsePercent(value string) uint32 {
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0
+	}
+	if i > 100 {
+		return 100
+	}
+	return uint32(i)
+}
+
 //GetRainbondInboundPluginOptions get rainbond inbound plugin options
 func GetRainbondInboundPluginOptions(sr map[string]interface{}) (r RainbondInboundPluginOptions) {
 	for k, v := range sr {
@@ -318,71 +310,71 @@ func GetRainbondInboundPluginOptions(sr map[string]interface{}) (r RainbondInbou
 }
 
 //ParseLocalityLbEndpointsResource parse envoy xds server response ParseLocalityLbEndpointsResource
-func ParseLocalityLbEndpointsResource(resources []*any.Any) []v2.ClusterLoadAssignment {
-	var endpoints []v2.ClusterLoadAssignment
+func ParseLocalityLbEndpointsResource(resources []*any.Any) []*endpointv3.ClusterLoadAssignment {
+	var endpoints []*endpointv3.ClusterLoadAssignment
 	for _, resource := range resources {
 		switch resource.GetTypeUrl() {
 		case rsrc.EndpointType:
-			var endpoint v2.ClusterLoadAssignment
+			var endpoint endpointv3.ClusterLoadAssignment
 			if err := proto.Unmarshal(resource.GetValue(), &endpoint); err != nil {
 				logrus.Errorf("unmarshal envoy endpoint resource failure %s", err.Error())
 			}
-			endpoints = append(endpoints, endpoint)
+			endpoints = append(endpoints, &endpoint)
 		}
 	}
 	return endpoints
 }
 
 //ParseClustersResource parse envoy xds server response ParseClustersResource
-func ParseClustersResource(resources []*any.Any) []v2.Cluster {
-	var clusters []v2.Cluster
+func ParseClustersResource(resources []*any.Any) []*clusterv3.Cluster {
+	var clusters []*clusterv3.Cluster
 	for _, resource := range resources {
 		switch resource.GetTypeUrl() {
 		case rsrc.ClusterType:
-			var cluster v2.Cluster
+			var cluster clusterv3.Cluster
 			if err := proto.Unmarshal(resource.GetValue(), &cluster); err != nil {
 				logrus.Errorf("unmarshal envoy cluster resource failure %s", err.Error())
 			}
-			clusters = append(clusters, cluster)
+			clusters = append(clusters, &cluster)
 		}
 	}
 	return clusters
 }
 
 //ParseListenerResource parse envoy xds server response ListenersResource
-func ParseListenerResource(resources []*any.Any) []v2.Listener {
-	var listeners []v2.Listener
+func ParseListenerResource(resources []*any.Any) []*listenerv3.Listener {
+	var listeners []*listenerv3.Listener
 	for _, resource := range resources {
 		switch resource.GetTypeUrl() {
 		case rsrc.ListenerType:
-			var listener v2.Listener
+			var listener listenerv3.Listener
 			if err := proto.Unmarshal(resource.GetValue(), &listener); err != nil {
 				logrus.Errorf("unmarshal envoy listener resource failure %s", err.Error())
 			}
-			listeners = append(listeners, listener)
+			listeners = append(listeners, &listener)
 		}
 	}
 	return listeners
 }
 
 //ParseRouteConfigurationsResource parse envoy xds server response RouteConfigurationsResource
-func ParseRouteConfigurationsResource(resources []*any.Any) []v2.RouteConfiguration {
-	var routes []v2.RouteConfiguration
+func ParseRouteConfigurationsResource(resources []*any.Any) []*routev3.RouteConfiguration {
+	var routes []*routev3.RouteConfiguration
 	for _, resource := range resources {
 		switch resource.GetTypeUrl() {
 		case rsrc.RouteType:
-			var route v2.RouteConfiguration
+			var route routev3.RouteConfiguration
 			if err := proto.Unmarshal(resource.GetValue(), &route); err != nil {
 				logrus.Errorf("unmarshal envoy route resource failure %s", err.Error())
 			}
-			routes = append(routes, route)
+			routes = append(routes, &route)
 		}
 	}
 	return routes
 }
 
 //CheckWeightSum check all cluster weight sum
-func CheckWeightSum(clusters []*route.WeightedCluster_ClusterWeight, weight uint32) uint32 {
+func CheckWeightSum(clusters []*routev3.WeightedCluster_ClusterWeight, weight uint32) uint32 {
 	var sum uint32
 	for _, cluster := range clusters {
 		sum += cluster.Weight.GetValue()
@@ -0,0 +1,136 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package v3
+
+import (
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"github.com/golang/protobuf/ptypes"
+
+	v1 "github.com/goodrain/rainbond/node/core/envoy/v1"
+)
+
+func TestPluginOptionsToClusterConfig(t *testing.T) {
+	options := GetOptionValues(map[string]interface{}{
+		v1.KeyMaxConnections:     "100",
+		v1.KeyMaxActiveRetries:   "5",
+		v1.KeyIntervalMS:         "2000",
+		v1.KeyBaseEjectionTimeMS: "60000",
+		v1.KeyMaxEjectionPercent: "120",
+		v1.KeyConsecutiveErrors:  "3",
+	})
+	circuitBreakers := CreateCircuitBreaker(options)
+	if circuitBreakers == nil || len(circuitBreakers.Thresholds) != 1 {
+		t.Fatal("expect one circuit breaker threshold")
+	}
+	threshold := circuitBreakers.Thresholds[0]
+	if threshold.MaxConnections.GetValue() != 100 || threshold.MaxRetries.GetValue() != 5 {
+		t.Fatalf("unexpected threshold %v", threshold)
+	}
+
+	outlierDetection := CreatOutlierDetection(options)
+	if outlierDetection == nil {
+		t.Fatal("expect outlier detection")
+	}
+	interval, _ := ptypes.Duration(outlierDetection.Interval)
+	baseEjectionTime, _ := ptypes.Duration(outlierDetection.BaseEjectionTime)
+	if interval != 2*time.Second || baseEjectionTime != time.Minute {
+		t.Fatalf("unexpected durations %s %s", interval, baseEjectionTime)
+	}
+	if outlierDetection.MaxEjectionPercent.GetValue() != 100 || outlierDetection.Consecutive_5Xx.GetValue() != 3 {
+		t.Fatalf("unexpected outlier detection %v", outlierDetection)
+	}
+}
+
+func TestApplyRoutePolicy(t *testing.T) {
+	options := GetOptionValues(map[string]interface{}{
+		v1.KeyRequestTimeoutMS:     "3000",
+		v1.KeyNumRetries:           "2",
+		v1.KeyRetryBaseIntervalMS:  "100",
+		v1.KeyRetryMaxIntervalMS:   "1000",
+		v1.KeyFaultAbortPercent:    "10",
+		v1.KeyFaultAbortHTTPStatus: "502",
+	})
+	route := ApplyRoutePolicy(CreateRoute("cluster", "/", nil, 100), options)
+	action := route.GetRoute()
+	if timeout, _ := ptypes.Duration(action.Timeout); timeout != 3*time.Second {
+		t.Fatalf("unexpected timeout %s", timeout)
+	}
+	if action.RetryPolicy == nil || action.RetryPolicy.RetryOn != "5xx,connect-failure" || action.RetryPolicy.NumRetries.GetValue() != 2 {
+		t.Fatalf("unexpected retry policy %v", action.RetryPolicy)
+	}
+	if action.RetryPolicy.RetryBackOff == nil || action.RetryPolicy.RetryBackOff.MaxInterval == nil {
+		t.Fatalf("unexpected retry back off %v", action.RetryPolicy.RetryBackOff)
+	}
+	if _, ok := route.TypedPerFilterConfig[HTTPFaultFilterName]; !ok {
+		t.Fatal("expect fault config of the route")
+	}
+
+	vh := CreateRouteVirtualHost("vh", []string{"*"}, nil, route)
+	manager := CreateHTTPConnectionManager("listener", "stat", nil, vh)
+	if len(manager.HttpFilters) != 2 || manager.HttpFilters[0].Name != HTTPFaultFilterName {
+		t.Fatalf("expect fault filter before router, got %v", manager.HttpFilters)
+	}
+
+	// no policy without options
+	route = ApplyRoutePolicy(CreateRoute("cluster", "/", nil, 100), GetOptionValues(nil))
+	if route.GetRoute().RetryPolicy != nil || route.GetRoute().Timeout != nil || route.TypedPerFilterConfig != nil {
+		t.Fatalf("unexpected route policy %v", route)
+	}
+}
+
+func TestEnableMTLS(t *testing.T) {
+	strict := EnableDownstreamMTLS(CreateTCPListener("tenant_app_80", "tenant_app_80", "0.0.0.0", "app_80", 65530, 0), false)
+	if len(strict.FilterChains) != 1 || strict.FilterChains[0].TransportSocket == nil {
+		t.Fatalf("strict listener should only accept mtls, got %v", strict.FilterChains)
+	}
+	var tlsContext tlsv3.DownstreamTlsContext
+	if err := ptypes.UnmarshalAny(strict.FilterChains[0].TransportSocket.GetTypedConfig(), &tlsContext); err != nil {
+		t.Fatal(err)
+	}
+	if !tlsContext.GetRequireClientCertificate().GetValue() {
+		t.Fatal("client certificate should be required")
+	}
+
+	permissive := EnableDownstreamMTLS(CreateTCPListener("tenant_app_80", "tenant_app_80", "0.0.0.0", "app_80", 65530, 0), true)
+	if len(permissive.FilterChains) != 2 || permissive.FilterChains[1].TransportSocket != nil {
+		t.Fatalf("permissive listener should keep the plaintext chain, got %v", permissive.FilterChains)
+	}
+	if permissive.FilterChains[0].FilterChainMatch.GetTransportProtocol() != "tls" || len(permissive.ListenerFilters) != 1 {
+		t.Fatal("permissive listener should inspect the transport protocol")
+	}
+
+	cluster := CreateCluster(ClusterOptions{Name: "tenant_app_web_80", ServiceName: "tenant_app_web_80", ClusterType: clusterv3.Cluster_EDS})
+	peerID := SpiffeID(DefaultTrustDomain, "tenant", "web")
+	EnableUpstreamMTLS(cluster, peerID)
+	if err := cluster.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var upstream tlsv3.UpstreamTlsContext
+	if err := ptypes.UnmarshalAny(cluster.TransportSocket.GetTypedConfig(), &upstream); err != nil {
+		t.Fatal(err)
+	}
+	matchers := upstream.CommonTlsContext.GetCombinedValidationContext().GetDefaultValidationContext().GetMatchSubjectAltNames()
+	if len(matchers) != 1 || matchers[0].GetExact() != peerID {
+		t.Fatalf("unexpected san matchers %v", matchers)
+	}
+}
@@ -0,0 +1,126 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envoy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	rsrc "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// parseNodeID returns the namespace and the service alias of the node id created by createNodeID
+func parseNodeID(nodeID string) (namespace, serviceAlias string, ok bool) {
+	first, last := strings.Index(nodeID, "_"), strings.LastIndex(nodeID, "_")
+	if first <= 0 || last == first || last == len(nodeID)-1 {
+		return "", "", false
+	}
+	return nodeID[:first], nodeID[last+1:], true
+}
+
+// authorizeStream is the stream interceptor making sure the secrets of a node are only served
+// to the pods of the component the node belongs to. The node id is sent by envoy, so it is
+// verified against the address the stream comes from.
+func (d *DiscoverServerManager) authorizeStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &authorizedStream{ServerStream: ss, verify: d.verifyNode})
+}
+
+// verifyNode checks the peer of the stream is a pod of the component of the node
+func (d *DiscoverServerManager) verifyNode(ctx context.Context, node *corev3.Node) error {
+	if node == nil {
+		return fmt.Errorf("the node of the stream is unknown")
+	}
+	namespace, serviceAlias, ok := parseNodeID(Hasher{}.ID(node))
+	if !ok {
+		return fmt.Errorf("invalid node id %s", node.Cluster)
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("the peer of the stream is unknown")
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return fmt.Errorf("parse peer address %s: %v", p.Addr, err)
+	}
+	pods, err := d.kubecli.CoreV1().Pods(namespace).List(ctx, meta_v1.ListOptions{LabelSelector: "service_alias=" + serviceAlias})
+	if err != nil {
+		return fmt.Errorf("list pods of %s/%s: %v", namespace, serviceAlias, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == host {
+			return nil
+		}
+		for _, ip := range pod.Status.PodIPs {
+			if ip.IP == host {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s is not a pod of %s/%s", host, namespace, serviceAlias)
+}
+
+// authorizedStream verifies the node on every request of the secrets. Envoy only sends the node
+// in the first request of the stream, a later request naming another node is refused.
+type authorizedStream struct {
+	grpc.ServerStream
+	verify func(ctx context.Context, node *corev3.Node) error
+	node   *corev3.Node
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	var node *corev3.Node
+	var typeURL string
+	switch req := m.(type) {
+	case *discovery.DiscoveryRequest:
+		node, typeURL = req.Node, req.TypeUrl
+	case *discovery.DeltaDiscoveryRequest:
+		node, typeURL = req.Node, req.TypeUrl
+	default:
+		return nil
+	}
+	if node != nil {
+		if s.node == nil {
+			s.node = node
+		} else if node.Id != s.node.Id || node.Cluster != s.node.Cluster {
+			logrus.Warningf("refuse the request of node %s on the stream of node %s", node.Cluster, s.node.Cluster)
+			return status.Error(codes.PermissionDenied, "the node of the stream can not change")
+		}
+	}
+	if typeURL != rsrc.SecretType {
+		return nil
+	}
+	if err := s.verify(s.Context(), s.node); err != nil {
+		logrus.Warningf("refuse to serve the secrets: %s", err.Error())
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envoy
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	rsrc "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestVerifyNode(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "gr123456-0", Namespace: "ns", Labels: map[string]string{"service_alias": "gr123456"}},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+	})
+	d := &DiscoverServerManager{kubecli: clientset}
+	withPeer := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}})
+	}
+	node := &corev3.Node{Cluster: createNodeID("ns", "plugin_1", "gr123456")}
+	if err := d.verifyNode(withPeer("10.0.0.1"), node); err != nil {
+		t.Errorf("the pod of the component should be allowed: %v", err)
+	}
+	if err := d.verifyNode(withPeer("10.0.0.2"), node); err == nil {
+		t.Errorf("other pods should be refused")
+	}
+	other := &corev3.Node{Cluster: createNodeID("ns", "plugin_1", "gr654321")}
+	if err := d.verifyNode(withPeer("10.0.0.1"), other); err == nil {
+		t.Errorf("the pod should not get the secrets of other components")
+	}
+	if err := d.verifyNode(withPeer("10.0.0.1"), &corev3.Node{Cluster: "invalid"}); err == nil {
+		t.Errorf("the invalid node id should be refused")
+	}
+}
+
+// requestStream replays the requests received by the server
+type requestStream struct {
+	grpc.ServerStream
+	requests []*discovery.DiscoveryRequest
+}
+
+func (r *requestStream) Context() context.Context {
+	return context.Background()
+}
+
+func (r *requestStream) RecvMsg(m interface{}) error {
+	*m.(*discovery.DiscoveryRequest) = *r.requests[0]
+	r.requests = r.requests[1:]
+	return nil
+}
+
+func TestAuthorizedStream(t *testing.T) {
+	node := &corev3.Node{Cluster: createNodeID("ns", "plugin_1", "gr123456")}
+	other := &corev3.Node{Cluster: createNodeID("ns", "plugin_1", "gr654321")}
+	var verified int
+	s := &authorizedStream{
+		ServerStream: &requestStream{requests: []*discovery.DiscoveryRequest{
+			{Node: node, TypeUrl: rsrc.SecretType},
+			{TypeUrl: rsrc.SecretType},
+			{Node: other, TypeUrl: rsrc.SecretType},
+		}},
+		verify: func(ctx context.Context, n *corev3.Node) error {
+			verified++
+			return nil
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.RecvMsg(&discovery.DiscoveryRequest{}); err != nil {
+			t.Fatalf("request %d should be allowed: %v", i, err)
+		}
+	}
+	if verified != 2 {
+		t.Errorf("expected every request of the secrets verified, got %d", verified)
+	}
+	if err := s.RecvMsg(&discovery.DiscoveryRequest{}); err == nil {
+		t.Errorf("the request of another node should be refused")
+	}
+}
@@ -0,0 +1,199 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envoy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	meshCASecretName = "rbd-mesh-ca"
+	meshCAValidity   = 10 * 365 * 24 * time.Hour
+	// MeshCertTTL the validity of the certificates issued for the sidecars
+	MeshCertTTL = 24 * time.Hour
+)
+
+// MeshCA issues short-lived certificates for the sidecars of the built-in mesh.
+// The root ca is kept in a secret, so that all the discover servers share it.
+type MeshCA struct {
+	trustDomain string
+	cert        *x509.Certificate
+	key         *ecdsa.PrivateKey
+	certPEM     []byte
+	ttl         time.Duration
+}
+
+// IssuedCert a certificate issued by the mesh ca
+type IssuedCert struct {
+	CertPEM  []byte
+	KeyPEM   []byte
+	NotAfter time.Time
+}
+
+// NeedRotate returns true if less than half of the validity is left
+func (c *IssuedCert) NeedRotate(ttl time.Duration) bool {
+	return c == nil || time.Until(c.NotAfter) < ttl/2
+}
+
+// NewMeshCA loads the root ca from the secret, or creates it if not exist
+func NewMeshCA(ctx context.Context, clientset kubernetes.Interface, namespace, trustDomain string) (*MeshCA, error) {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, meshCASecretName, metav1.GetOptions{})
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return nil, fmt.Errorf("get mesh ca secret: %v", err)
+	}
+	if secret == nil || k8sErrors.IsNotFound(err) {
+		certPEM, keyPEM, err := createRootCA(trustDomain)
+		if err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      meshCASecretName,
+				Namespace: namespace,
+				Labels:    map[string]string{"creator": "Rainbond"},
+			},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       certPEM,
+				corev1.TLSPrivateKeyKey: keyPEM,
+			},
+			Type: corev1.SecretTypeTLS,
+		}
+		created, err := clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+		if k8sErrors.IsAlreadyExists(err) {
+			// created by another discover server
+			created, err = clientset.CoreV1().Secrets(namespace).Get(ctx, meshCASecretName, metav1.GetOptions{})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("create mesh ca secret: %v", err)
+		}
+		secret = created
+	}
+	return LoadMeshCA(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey], trustDomain)
+}
+
+// LoadMeshCA creates the mesh ca with the pem encoded root certificate and key
+func LoadMeshCA(certPEM, keyPEM []byte, trustDomain string) (*MeshCA, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, fmt.Errorf("invalid mesh ca pem data")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse mesh ca certificate: %v", err)
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse mesh ca key: %v", err)
+	}
+	return &MeshCA{trustDomain: trustDomain, cert: cert, key: key, certPEM: certPEM, ttl: MeshCertTTL}, nil
+}
+
+// RootCertPEM returns the pem encoded root certificate
+func (m *MeshCA) RootCertPEM() []byte {
+	return m.certPEM
+}
+
+// Issue issues a certificate with the spiffe id of the component
+func (m *MeshCA) Issue(spiffeID string) (*IssuedCert, error) {
+	uri, err := url.Parse(spiffeID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{m.trustDomain}},
+		URIs:         []*url.URL{uri},
+		// tolerate clock skew between the nodes
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(m.ttl),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, m.cert, &key.PublicKey, m.key)
+	if err != nil {
+		return nil, fmt.Errorf("issue certificate for %s: %v", spiffeID, err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("issued mesh certificate for %s, expires at %s", spiffeID, template.NotAfter)
+	return &IssuedCert{
+		CertPEM:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:   pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		NotAfter: template.NotAfter,
+	}, nil
+}
+
+func createRootCA(trustDomain string) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{trustDomain}, CommonName: "Rainbond Mesh CA"},
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.Add(meshCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create mesh root ca: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
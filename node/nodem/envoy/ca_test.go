@@ -0,0 +1,79 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envoy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMeshCAIssue(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	ca, err := NewMeshCA(context.Background(), clientset, "rbd-system", envoyv3.DefaultTrustDomain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the ca is stored in the secret, so that all discover servers share it
+	same, err := NewMeshCA(context.Background(), clientset, "rbd-system", envoyv3.DefaultTrustDomain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(same.RootCertPEM()) != string(ca.RootCertPEM()) {
+		t.Fatal("ca is not reused from the secret")
+	}
+
+	id := envoyv3.SpiffeID(envoyv3.DefaultTrustDomain, "tenant", "gr123456")
+	issued, err := ca.Issue(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tls.X509KeyPair(issued.CertPEM, issued.KeyPEM); err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(issued.CertPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cert.URIs) != 1 || cert.URIs[0].String() != id {
+		t.Fatalf("unexpected uri san %v", cert.URIs)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.RootCertPEM())
+	for _, usage := range []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth} {
+		if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{usage}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if issued.NeedRotate(MeshCertTTL) {
+		t.Fatal("new certificate should not be rotated")
+	}
+	issued.NotAfter = time.Now().Add(MeshCertTTL / 4)
+	if !issued.NeedRotate(MeshCertTTL) {
+		t.Fatal("certificate close to expiry should be rotated")
+	}
+}
@@ -23,13 +23,13 @@ import (
 	"strconv"
 	"strings"
 
-	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
-	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
-	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
 	"github.com/golang/protobuf/ptypes"
 	api_model "github.com/goodrain/rainbond/api/model"
-	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 	"github.com/goodrain/rainbond/node/utils"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -43,7 +43,8 @@ func OneNodeCluster(serviceAlias, namespace string, configs *corev1.ConfigMap, s
 	}
 	var clusters []types.Resource
 	if resources.BaseServices != nil && len(resources.BaseServices) > 0 {
-		for _, cl := range upstreamClusters(serviceAlias, namespace, resources.BaseServices, services) {
+		mtls := envoyv3.MTLSEnabled(configs.Annotations[envoyv3.MeshMTLSModeAnnotation])
+		for _, cl := range upstreamClusters(serviceAlias, namespace, resources.BaseServices, services, mtls) {
 			if err := cl.Validate(); err != nil {
 				logrus.Errorf("cluster validate failure %s", err.Error())
 			} else {
@@ -68,7 +69,7 @@ func OneNodeCluster(serviceAlias, namespace string, configs *corev1.ConfigMap, s
 
 // upstreamClusters handle upstream app cluster
 // handle kubernetes inner service
-func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_model.BaseService, services []*corev1.Service) (cdsClusters []*v2.Cluster) {
+func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_model.BaseService, services []*corev1.Service, mtls bool) (cdsClusters []*clusterv3.Cluster) {
 	var clusterConfig = make(map[string]*api_model.BaseService, len(dependsServices))
 	for i, dService := range dependsServices {
 		depServiceIndex := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, dService.DependServiceAlias, dService.Port)
@@ -81,27 +82,27 @@ func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_mod
 			continue
 		}
 		for _, port := range service.Spec.Ports {
-			getOptions := func() (d envoyv2.RainbondPluginOptions) {
+			getOptions := func() (d envoyv3.RainbondPluginOptions) {
 				relPort, _ := strconv.Atoi(service.Labels["origin_port"])
 				if relPort == 0 {
 					relPort = int(port.TargetPort.IntVal)
 				}
 				depServiceIndex := fmt.Sprintf("%s_%s_%s_%d", namespace, serviceAlias, GetServiceAliasByService(service), relPort)
 				if _, ok := clusterConfig[depServiceIndex]; ok {
-					return envoyv2.GetOptionValues(clusterConfig[depServiceIndex].Options)
+					return envoyv3.GetOptionValues(clusterConfig[depServiceIndex].Options)
 				}
-				return envoyv2.GetOptionValues(nil)
+				return envoyv3.GetOptionValues(nil)
 			}
-			var clusterOption envoyv2.ClusterOptions
+			var clusterOption envoyv3.ClusterOptions
 			clusterOption.Name = fmt.Sprintf("%s_%s_%s_%v", namespace, serviceAlias, GetServiceAliasByService(service), port.Port)
 			options := getOptions()
-			clusterOption.OutlierDetection = envoyv2.CreatOutlierDetection(options)
-			clusterOption.CircuitBreakers = envoyv2.CreateCircuitBreaker(options)
+			clusterOption.OutlierDetection = envoyv3.CreatOutlierDetection(options)
+			clusterOption.CircuitBreakers = envoyv3.CreateCircuitBreaker(options)
 			clusterOption.ServiceName = fmt.Sprintf("%s_%s_%s_%v", namespace, serviceAlias, destServiceAlias, port.Port)
 			if domain, ok := service.Annotations["domain"]; ok && domain != "" {
 				logrus.Debugf("domain endpoint[%s], create logical_dns cluster: ", domain)
-				clusterOption.ClusterType = v2.Cluster_LOGICAL_DNS
-				clusterOption.LoadAssignment = envoyv2.CreateDNSLoadAssignment(serviceAlias, namespace, domain, service, port)
+				clusterOption.ClusterType = clusterv3.Cluster_LOGICAL_DNS
+				clusterOption.LoadAssignment = envoyv3.CreateDNSLoadAssignment(serviceAlias, namespace, domain, service, port)
 				if strings.HasPrefix(domain, "https://") {
 					splitDomain := strings.Split(domain, "https://")
 					if len(splitDomain) == 2 {
@@ -109,17 +110,21 @@ func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_mod
 					}
 				}
 			} else {
-				clusterOption.ClusterType = v2.Cluster_EDS
+				clusterOption.ClusterType = clusterv3.Cluster_EDS
 			}
 			clusterOption.HealthyPanicThreshold = options.HealthyPanicThreshold
-			clusterOption.ConnectionTimeout = envoyv2.ConverTimeDuration(options.ConnectionTimeout)
+			clusterOption.ConnectionTimeout = envoyv3.ConverTimeDuration(options.ConnectionTimeout)
 			// set port realy protocol
 			portProtocol := service.Labels[fmt.Sprintf("port_protocol_%v", port)]
 			clusterOption.Protocol = portProtocol
 			clusterOption.GrpcHealthServiceName = options.GrpcHealthServiceName
 			clusterOption.HealthTimeout = options.HealthCheckTimeout
 			clusterOption.HealthInterval = options.HealthCheckInterval
-			cluster := envoyv2.CreateCluster(clusterOption)
+			cluster := envoyv3.CreateCluster(clusterOption)
+			if cluster != nil && mtls && clusterOption.TransportSocket == nil && service.Labels[envoyv3.MeshMTLSServiceLabel] == "true" {
+				// the sidecar of the dependency accepts mtls, originate it with the identity of this component
+				envoyv3.EnableUpstreamMTLS(cluster, envoyv3.SpiffeID(envoyv3.DefaultTrustDomain, service.Namespace, destServiceAlias))
+			}
 			if cluster != nil {
 				logrus.Debugf("cluster is : %v", cluster)
 				cdsClusters = append(cdsClusters, cluster)
@@ -129,19 +134,19 @@ func upstreamClusters(serviceAlias, namespace string, dependsServices []*api_mod
 	return
 }
 
-func transportSocket(name, domain string) *core.TransportSocket {
+func transportSocket(name, domain string) *corev3.TransportSocket {
 	logrus.Debugf("https domain tlsContext: %s", domain)
-	// refer to: https://www.envoyproxy.io/docs/envoy/v1.17.2/api-v2/api/v2/auth/tls.proto#auth-upstreamtlscontext
-	tlsContext, err := ptypes.MarshalAny(&auth.UpstreamTlsContext{Sni: domain})
+	// refer to: https://www.envoyproxy.io/docs/envoy/v1.17.2/api-v3/extensions/transport_sockets/tls/v3/tls.proto#extensions-transport-sockets-tls-v3-upstreamtlscontext
+	tlsContext, err := ptypes.MarshalAny(&tlsv3.UpstreamTlsContext{Sni: domain})
 	if err != nil {
 		logrus.Errorf("error marshaling tls context to transport_socket config for cluster %s, err=%v",
 			name, err)
 		// no tls context for the cluster
 		return nil
 	}
-	return &core.TransportSocket{
+	return &corev3.TransportSocket{
 		Name: utils.EnvoyTLSSocketName,
-		ConfigType: &core.TransportSocket_TypedConfig{
+		ConfigType: &corev3.TransportSocket_TypedConfig{
 			TypedConfig: tlsContext,
 		},
 	}
@@ -149,21 +154,21 @@ func transportSocket(name, domain string) *core.TransportSocket {
 
 // downstreamClusters handle app self cluster
 // only local port
-func downstreamClusters(serviceAlias, namespace string, ports []*api_model.BasePort) (cdsClusters []*v2.Cluster) {
+func downstreamClusters(serviceAlias, namespace string, ports []*api_model.BasePort) (cdsClusters []*clusterv3.Cluster) {
 	for i := range ports {
 		port := ports[i]
-		address := envoyv2.CreateSocketAddress(port.Protocol, "127.0.0.1", uint32(port.Port))
+		address := envoyv3.CreateSocketAddress(port.Protocol, "127.0.0.1", uint32(port.Port))
 		clusterName := fmt.Sprintf("%s_%s_%v", namespace, serviceAlias, port.Port)
-		option := envoyv2.GetOptionValues(port.Options)
-		cluster := envoyv2.CreateCluster(envoyv2.ClusterOptions{
+		option := envoyv3.GetOptionValues(port.Options)
+		cluster := envoyv3.CreateCluster(envoyv3.ClusterOptions{
 			Name:                     clusterName,
-			ConnectionTimeout:        envoyv2.ConverTimeDuration(option.ConnectionTimeout),
+			ConnectionTimeout:        envoyv3.ConverTimeDuration(option.ConnectionTimeout),
 			ServiceName:              "",
-			ClusterType:              v2.Cluster_STATIC,
-			CircuitBreakers:          envoyv2.CreateCircuitBreaker(option),
-			OutlierDetection:         envoyv2.CreatOutlierDetection(option),
+			ClusterType:              clusterv3.Cluster_STATIC,
+			CircuitBreakers:          envoyv3.CreateCircuitBreaker(option),
+			OutlierDetection:         envoyv3.CreatOutlierDetection(option),
 			MaxRequestsPerConnection: option.MaxRequestsPerConnection,
-			Hosts:                    []*core.Address{address},
+			Hosts:                    []*corev3.Address{address},
 			HealthyPanicThreshold:    option.HealthyPanicThreshold,
 		})
 		if cluster != nil {
@@ -24,10 +24,9 @@ import (
 
 	"github.com/sirupsen/logrus"
 
-	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
-	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
-	envoyv2 "github.com/goodrain/rainbond/node/core/envoy/v2"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -77,7 +76,7 @@ func OneNodeClusterLoadAssignment(serviceAlias, namespace string, endpoints []*c
 					if len(subset.Addresses) > 0 {
 						var lbe []*endpoint.LbEndpoint
 						for _, address := range subset.Addresses {
-							envoyAddress := envoyv2.CreateSocketAddress(protocol, address.IP, uint32(toport))
+							envoyAddress := envoyv3.CreateSocketAddress(protocol, address.IP, uint32(toport))
 							lbe = append(lbe, &endpoint.LbEndpoint{
 								HostIdentifier: &endpoint.LbEndpoint_Endpoint{
 									Endpoint: &endpoint.Endpoint{
@@ -95,7 +94,7 @@ func OneNodeClusterLoadAssignment(serviceAlias, namespace string, endpoints []*c
 			}
 			if len(lendpoints) == 0 && notReadyAddress != nil && notReadyPort != nil {
 				var lbe []*endpoint.LbEndpoint
-				envoyAddress := envoyv2.CreateSocketAddress(string(notReadyPort.Protocol), notReadyAddress.IP, uint32(notreadyToPort))
+				envoyAddress := envoyv3.CreateSocketAddress(string(notReadyPort.Protocol), notReadyAddress.IP, uint32(notreadyToPort))
 				lbe = append(lbe, &endpoint.LbEndpoint{
 					HostIdentifier: &endpoint.LbEndpoint_Endpoint{
 						Endpoint: &endpoint.Endpoint{
@@ -123,7 +122,7 @@ func OneNodeClusterLoadAssignment(serviceAlias, namespace string, endpoints []*c
 				newlendpoints = append(newlendpoints, ep)
 			}
 
-			cla := &v2.ClusterLoadAssignment{
+			cla := &endpoint.ClusterLoadAssignment{
 				ClusterName: clusterName,
 				Endpoints:   newlendpoints,
 			}
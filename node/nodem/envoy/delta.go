@@ -0,0 +1,240 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envoy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/sirupsen/logrus"
+
+	rsrc "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+)
+
+// deltaTypeOrder the order in which resources are pushed, so that envoy never
+// receives a resource before the resources it depends on
+var deltaTypeOrder = []string{rsrc.SecretType, rsrc.ClusterType, rsrc.EndpointType, rsrc.ListenerType, rsrc.RouteType}
+
+// deltaServer serves the incremental variant of ADS from the snapshot cache,
+// the embedded server only implements the state of the world protocol.
+type deltaServer struct {
+	server.Server
+	cache cache.SnapshotCache
+	hash  cache.NodeHash
+}
+
+func newDeltaServer(srv server.Server, configCache cache.SnapshotCache, hash cache.NodeHash) server.Server {
+	return &deltaServer{Server: srv, cache: configCache, hash: hash}
+}
+
+// deltaSubscription the resources of one type subscribed by a stream
+type deltaSubscription struct {
+	wildcard bool
+	names    map[string]bool
+	// versions of the resources known by envoy
+	versions map[string]string
+	// responded whether the first response has been sent
+	responded bool
+}
+
+func (s *deltaSubscription) subscribed(name string) bool {
+	return s.wildcard || s.names[name]
+}
+
+// deltaStream the state of one delta ADS stream
+type deltaStream struct {
+	stream        discovery.AggregatedDiscoveryService_DeltaAggregatedResourcesServer
+	node          *corev3.Node
+	subscriptions map[string]*deltaSubscription
+	nonce         int64
+}
+
+// DeltaAggregatedResources implements the incremental ADS. A watch on the clusters of the
+// node is used to be notified of new snapshots, the differences between the snapshot and
+// the resources known by envoy are pushed for every subscribed type.
+func (d *deltaServer) DeltaAggregatedResources(stream discovery.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
+	reqCh := make(chan *discovery.DeltaDiscoveryRequest)
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			select {
+			case reqCh <- req:
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+
+	ds := &deltaStream{stream: stream, subscriptions: make(map[string]*deltaSubscription)}
+	var (
+		watch   chan cache.Response
+		cancel  func()
+		version string
+	)
+	defer func() {
+		if cancel != nil {
+			cancel()
+		}
+	}()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case err := <-errCh:
+			return err
+		case resp := <-watch:
+			version = resp.Version
+			if cancel != nil {
+				cancel()
+			}
+			watch, cancel = d.cache.CreateWatch(cache.Request{Node: ds.node, TypeUrl: rsrc.ClusterType, VersionInfo: version})
+			for _, typeURL := range deltaTypeOrder {
+				if _, ok := ds.subscriptions[typeURL]; !ok {
+					continue
+				}
+				if err := d.respond(ds, typeURL); err != nil {
+					return err
+				}
+			}
+		case req := <-reqCh:
+			if ds.node == nil {
+				if req.Node == nil {
+					return fmt.Errorf("missing node in the first delta discovery request")
+				}
+				ds.node = req.Node
+			}
+			if req.ErrorDetail != nil {
+				logrus.Warningf("envoy node %s rejected %s config: %s", d.hash.ID(ds.node), req.TypeUrl, req.ErrorDetail.GetMessage())
+				continue
+			}
+			sub, changed := ds.subscribe(req)
+			if watch == nil {
+				watch, cancel = d.cache.CreateWatch(cache.Request{Node: ds.node, TypeUrl: rsrc.ClusterType, VersionInfo: version})
+			}
+			if !changed && sub.responded {
+				// ack of a previous response
+				continue
+			}
+			if err := d.respond(ds, req.TypeUrl); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// subscribe updates the subscription with the request, returns whether the subscribed resources are changed
+func (ds *deltaStream) subscribe(req *discovery.DeltaDiscoveryRequest) (*deltaSubscription, bool) {
+	sub, ok := ds.subscriptions[req.TypeUrl]
+	if !ok {
+		sub = &deltaSubscription{names: make(map[string]bool), versions: make(map[string]string)}
+		// clusters and listeners are subscribed with wildcard by envoy
+		if len(req.ResourceNamesSubscribe) == 0 && (req.TypeUrl == rsrc.ClusterType || req.TypeUrl == rsrc.ListenerType) {
+			sub.wildcard = true
+		}
+		for name, version := range req.InitialResourceVersions {
+			sub.versions[name] = version
+		}
+		ds.subscriptions[req.TypeUrl] = sub
+	}
+	changed := false
+	for _, name := range req.ResourceNamesSubscribe {
+		if name == "*" {
+			sub.wildcard = true
+		} else {
+			sub.names[name] = true
+		}
+		changed = true
+	}
+	for _, name := range req.ResourceNamesUnsubscribe {
+		if name == "*" {
+			sub.wildcard = false
+		}
+		delete(sub.names, name)
+		delete(sub.versions, name)
+		changed = true
+	}
+	return sub, changed
+}
+
+// respond sends the changed and removed resources of the type, nothing is sent if
+// envoy is up to date, except for the first response of the type.
+func (d *deltaServer) respond(ds *deltaStream, typeURL string) error {
+	sub := ds.subscriptions[typeURL]
+	snapshotVersion := ""
+	current := make(map[string]*discovery.Resource)
+	if snapshot, err := d.cache.GetSnapshot(d.hash.ID(ds.node)); err == nil {
+		snapshotVersion = snapshot.GetVersion(typeURL)
+		for name, resource := range snapshot.GetResources(typeURL) {
+			if !sub.subscribed(name) {
+				continue
+			}
+			value, err := cache.MarshalResource(resource)
+			if err != nil {
+				logrus.Errorf("marshal envoy resource %s failure %s", name, err.Error())
+				continue
+			}
+			current[name] = &discovery.Resource{
+				Name:     name,
+				Version:  fmt.Sprintf("%x", sha256.Sum256(value)),
+				Resource: &any.Any{TypeUrl: typeURL, Value: value},
+			}
+		}
+	}
+	resp := &discovery.DeltaDiscoveryResponse{
+		SystemVersionInfo: snapshotVersion,
+		TypeUrl:           typeURL,
+	}
+	for name, resource := range current {
+		if sub.versions[name] != resource.Version {
+			resp.Resources = append(resp.Resources, resource)
+		}
+	}
+	for name := range sub.versions {
+		if _, ok := current[name]; !ok {
+			resp.RemovedResources = append(resp.RemovedResources, name)
+		}
+	}
+	if len(resp.Resources) == 0 && len(resp.RemovedResources) == 0 && sub.responded {
+		return nil
+	}
+	ds.nonce++
+	resp.Nonce = strconv.FormatInt(ds.nonce, 10)
+	if err := ds.stream.Send(resp); err != nil {
+		return err
+	}
+	for _, resource := range resp.Resources {
+		sub.versions[resource.Name] = resource.Version
+	}
+	for _, name := range resp.RemovedResources {
+		delete(sub.versions, name)
+	}
+	sub.responded = true
+	return nil
+}
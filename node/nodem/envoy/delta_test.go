@@ -0,0 +1,116 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package envoy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	rsrc "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	"google.golang.org/grpc"
+)
+
+type mockDeltaStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv chan *discovery.DeltaDiscoveryRequest
+	sent chan *discovery.DeltaDiscoveryResponse
+}
+
+func (m *mockDeltaStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockDeltaStream) Send(resp *discovery.DeltaDiscoveryResponse) error {
+	m.sent <- resp
+	return nil
+}
+
+func (m *mockDeltaStream) Recv() (*discovery.DeltaDiscoveryRequest, error) {
+	select {
+	case req := <-m.recv:
+		return req, nil
+	case <-m.ctx.Done():
+		return nil, m.ctx.Err()
+	}
+}
+
+func (m *mockDeltaStream) next(t *testing.T) *discovery.DeltaDiscoveryResponse {
+	select {
+	case resp := <-m.sent:
+		return resp
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for delta response")
+	}
+	return nil
+}
+
+func clusterSnapshot(version string, names ...string) cache.Snapshot {
+	var clusters []types.Resource
+	for _, name := range names {
+		clusters = append(clusters, &clusterv3.Cluster{Name: name})
+	}
+	return cache.NewSnapshot(version, nil, clusters, nil, nil, nil)
+}
+
+func TestDeltaAggregatedResources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	configCache := cache.NewSnapshotCache(false, Hasher{}, nil)
+	node := &corev3.Node{Id: "sidecar", Cluster: "tenant_plugin_app"}
+	if err := configCache.SetSnapshot(node.Cluster, clusterSnapshot("version_1", "a", "b")); err != nil {
+		t.Fatal(err)
+	}
+	srv := newDeltaServer(server.NewServer(ctx, configCache, nil), configCache, Hasher{})
+	stream := &mockDeltaStream{
+		ctx:  ctx,
+		recv: make(chan *discovery.DeltaDiscoveryRequest, 1),
+		sent: make(chan *discovery.DeltaDiscoveryResponse, 10),
+	}
+	go srv.DeltaAggregatedResources(stream)
+
+	stream.recv <- &discovery.DeltaDiscoveryRequest{Node: node, TypeUrl: rsrc.ClusterType}
+	resp := stream.next(t)
+	if len(resp.Resources) != 2 || len(resp.RemovedResources) != 0 {
+		t.Fatalf("expect 2 clusters, got %v", resp)
+	}
+	stream.recv <- &discovery.DeltaDiscoveryRequest{TypeUrl: rsrc.ClusterType, ResponseNonce: resp.Nonce}
+
+	// only the changed clusters are pushed
+	if err := configCache.SetSnapshot(node.Cluster, clusterSnapshot("version_2", "a", "c")); err != nil {
+		t.Fatal(err)
+	}
+	resp = stream.next(t)
+	if len(resp.Resources) != 1 || resp.Resources[0].Name != "c" {
+		t.Fatalf("expect cluster c to be added, got %v", resp.Resources)
+	}
+	if len(resp.RemovedResources) != 1 || resp.RemovedResources[0] != "b" {
+		t.Fatalf("expect cluster b to be removed, got %v", resp.RemovedResources)
+	}
+	if resp.SystemVersionInfo != "version_2" {
+		t.Fatalf("expect version_2, got %s", resp.SystemVersionInfo)
+	}
+}
@@ -30,13 +30,18 @@ import (
 
 	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
 
-	v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
-	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
-	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
-	"github.com/envoyproxy/go-control-plane/pkg/cache/v2"
-	"github.com/envoyproxy/go-control-plane/pkg/server/v2"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	clusterservice "github.com/envoyproxy/go-control-plane/envoy/service/cluster/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	endpointservice "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
+	listenerservice "github.com/envoyproxy/go-control-plane/envoy/service/listener/v3"
+	routeservice "github.com/envoyproxy/go-control-plane/envoy/service/route/v3"
+	secretservice "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/server/v3"
 	api_model "github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/cmd/node/option"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 	"github.com/goodrain/rainbond/node/nodem/envoy/conver"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
@@ -64,6 +69,7 @@ type DiscoverServerManager struct {
 	endpoints       cacheHandler
 	configmaps      cacheHandler
 	queue           Queue
+	ca              *MeshCA
 }
 
 // Hasher returns node ID as an ID
@@ -71,7 +77,7 @@ type Hasher struct {
 }
 
 // ID function
-func (h Hasher) ID(node *envoy_api_v2_core.Node) string {
+func (h Hasher) ID(node *corev3.Node) string {
 	if node == nil {
 		return "unknown"
 	}
@@ -88,6 +94,8 @@ type NodeConfig struct {
 	configModel                    *api_model.ResourceSpec
 	dependServices                 sync.Map
 	listeners, clusters, endpoints []types.Resource
+	secrets                        []types.Resource
+	cert                           *IssuedCert
 }
 
 // GetID get envoy node config id
@@ -237,6 +245,7 @@ func (d *DiscoverServerManager) UpdateNodeConfig(nc *NodeConfig) error {
 	} else {
 		nc.endpoints = clusterLoadAssignment
 	}
+	nc.secrets = d.meshSecrets(nc)
 	//Fill the configuration information and inject envoy
 	nc.VersionUpdate()
 	return d.setSnapshot(nc)
@@ -248,6 +257,7 @@ func (d *DiscoverServerManager) setSnapshot(nc *NodeConfig) error {
 		return nil
 	}
 	snapshot := cache.NewSnapshot(nc.GetVersion(), nc.endpoints, nc.clusters, nil, nc.listeners, nil)
+	snapshot.Resources[types.Secret] = cache.NewResources(nc.GetVersion(), nc.secrets)
 	err := d.cacheManager.SetSnapshot(nc.nodeID, snapshot)
 	if err != nil {
 		return err
@@ -256,12 +266,34 @@ func (d *DiscoverServerManager) setSnapshot(nc *NodeConfig) error {
 	return nil
 }
 
+// meshSecrets returns the certificate and root ca served by sds, the certificate is
+// reissued when half of its validity has passed. They are only served to the pods
+// of the component, see authorizeStream.
+func (d *DiscoverServerManager) meshSecrets(nc *NodeConfig) []types.Resource {
+	if d.ca == nil || !envoyv3.MTLSEnabled(nc.config.Annotations[envoyv3.MeshMTLSModeAnnotation]) {
+		nc.cert = nil
+		return nil
+	}
+	if nc.cert.NeedRotate(MeshCertTTL) {
+		cert, err := d.ca.Issue(envoyv3.SpiffeID(envoyv3.DefaultTrustDomain, nc.namespace, nc.serviceAlias))
+		if err != nil {
+			logrus.Errorf("issue mesh certificate for envoy node %s failure %s", nc.GetID(), err.Error())
+			return nc.secrets
+		}
+		nc.cert = cert
+	}
+	return []types.Resource{
+		envoyv3.CreateTLSCertificateSecret(nc.cert.CertPEM, nc.cert.KeyPEM),
+		envoyv3.CreateRootCASecret(d.ca.RootCertPEM()),
+	}
+}
+
 // CreateDiscoverServerManager create discover server manager
 func CreateDiscoverServerManager(clientset kubernetes.Interface, conf option.Conf) (*DiscoverServerManager, error) {
 	configcache := cache.NewSnapshotCache(false, Hasher{}, logrus.WithField("module", "config-cache"))
 	ctx, cancel := context.WithCancel(context.Background())
 	dsm := &DiscoverServerManager{
-		server:       server.NewServer(ctx, configcache, nil),
+		server:       newDeltaServer(server.NewServer(ctx, configcache, nil), configcache, Hasher{}),
 		cacheManager: configcache,
 		kubecli:      clientset,
 		conf:         conf,
@@ -275,6 +307,14 @@ func CreateDiscoverServerManager(clientset kubernetes.Interface, conf option.Con
 		cancel: cancel,
 		queue:  NewQueue(1 * time.Second),
 	}
+	caCtx, caCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer caCancel()
+	meshCA, err := NewMeshCA(caCtx, clientset, conf.RbdNamespace, envoyv3.DefaultTrustDomain)
+	if err != nil {
+		// the components still work without mtls
+		logrus.Errorf("create mesh ca failure, mtls of the built-in mesh is disabled: %s", err.Error())
+	}
+	dsm.ca = meshCA
 	sharedInformers := informers.NewFilteredSharedInformerFactory(dsm.kubecli, time.Second*10, corev1.NamespaceAll, func(options *meta_v1.ListOptions) {
 		options.LabelSelector = "creator=Rainbond"
 	})
@@ -296,6 +336,7 @@ const grpcMaxConcurrentStreams = 1000000
 func (d *DiscoverServerManager) Start(errch chan error) error {
 	go func() {
 		go d.queue.Run(d.ctx.Done())
+		go d.rotateCertificates()
 		go d.services.informer.Run(d.ctx.Done())
 		go d.endpoints.informer.Run(d.ctx.Done())
 		//waiting service and endpoint resource loading is complete
@@ -310,14 +351,15 @@ func (d *DiscoverServerManager) Start(errch chan error) error {
 		// availability problems.
 		var grpcOptions []grpc.ServerOption
 		grpcOptions = append(grpcOptions, grpc.MaxConcurrentStreams(grpcMaxConcurrentStreams))
+		grpcOptions = append(grpcOptions, grpc.StreamInterceptor(d.authorizeStream))
 		d.grpcServer = grpc.NewServer(grpcOptions...)
 		// register services
 		discovery.RegisterAggregatedDiscoveryServiceServer(d.grpcServer, d.server)
-		v2.RegisterEndpointDiscoveryServiceServer(d.grpcServer, d.server)
-		v2.RegisterClusterDiscoveryServiceServer(d.grpcServer, d.server)
-		v2.RegisterRouteDiscoveryServiceServer(d.grpcServer, d.server)
-		v2.RegisterListenerDiscoveryServiceServer(d.grpcServer, d.server)
-		discovery.RegisterSecretDiscoveryServiceServer(d.grpcServer, d.server)
+		endpointservice.RegisterEndpointDiscoveryServiceServer(d.grpcServer, d.server)
+		clusterservice.RegisterClusterDiscoveryServiceServer(d.grpcServer, d.server)
+		routeservice.RegisterRouteDiscoveryServiceServer(d.grpcServer, d.server)
+		listenerservice.RegisterListenerDiscoveryServiceServer(d.grpcServer, d.server)
+		secretservice.RegisterSecretDiscoveryServiceServer(d.grpcServer, d.server)
 		logrus.Infof("envoy grpc management server listening %s", d.conf.GrpcAPIAddr)
 		lis, err := net.Listen("tcp", d.conf.GrpcAPIAddr)
 		if err != nil {
@@ -393,6 +435,7 @@ func (d *DiscoverServerManager) AddNodeConfig(nc *NodeConfig) {
 	for i, existNC := range d.cacheNodeConfig {
 		if existNC.nodeID == nc.nodeID {
 			nc.version = existNC.version
+			nc.cert = existNC.cert
 			d.cacheNodeConfig[i] = nc
 			exist = true
 			break
@@ -450,6 +493,32 @@ func (d *DiscoverServerManager) configHandle(obj interface{}, event Event) error
 	return nil
 }
 
+// rotateCertificates checks the certificates periodically, the rotation is handled in
+// the queue, so that the node configs are only changed by one goroutine.
+func (d *DiscoverServerManager) rotateCertificates() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.queue.Push(Task{handler: d.rotateHandle, event: EventUpdate})
+		}
+	}
+}
+
+func (d *DiscoverServerManager) rotateHandle(obj interface{}, event Event) error {
+	for i, nodeConfig := range d.cacheNodeConfig {
+		if nodeConfig.cert != nil && nodeConfig.cert.NeedRotate(MeshCertTTL) {
+			if err := d.UpdateNodeConfig(d.cacheNodeConfig[i]); err != nil {
+				logrus.Errorf("rotate envoy node certificate failure %s", err.Error())
+			}
+		}
+	}
+	return nil
+}
+
 func (d *DiscoverServerManager) resourceSimpleHandle(obj interface{}, event Event) error {
 	switch event {
 	case EventAdd, EventUpdate, EventDelete:
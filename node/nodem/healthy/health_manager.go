@@ -299,6 +299,24 @@ func (p *probeManager) GetCurrentServiceHealthy(serviceName string) (*service.He
 				}
 				return result, nil
 			}
+			if v.ServiceHealth.Model == "grpc" {
+				statusMap := probe.GetGrpcHealth(v.ServiceHealth.Address, v.ServiceHealth.Service, v.ServiceHealth.TLS)
+				result := &service.HealthStatus{
+					Name:   v.Name,
+					Status: statusMap["status"],
+					Info:   statusMap["info"],
+				}
+				return result, nil
+			}
+			if v.ServiceHealth.Model == "tls" {
+				statusMap := probe.GetTLSHealth(v.ServiceHealth.Address, v.ServiceHealth.ServerName, v.ServiceHealth.ExpireThresholdDays)
+				result := &service.HealthStatus{
+					Name:   v.Name,
+					Status: statusMap["status"],
+					Info:   statusMap["info"],
+				}
+				return result, nil
+			}
 		}
 	}
 	return nil, errors.New("the service does not exist")
@@ -0,0 +1,66 @@
+package probe
+
+import (
+	"context"
+	"time"
+
+	"github.com/goodrain/rainbond/node/nodem/client"
+	"github.com/goodrain/rainbond/node/nodem/service"
+	utilprobe "github.com/goodrain/rainbond/util/prober/probes"
+)
+
+// probeTimeout is the timeout of the grpc and tls probes
+const probeTimeout = 5 * time.Second
+
+// GrpcProbe checks the service through the grpc health checking protocol
+type GrpcProbe struct {
+	Name         string
+	Address      string
+	Service      string
+	TLS          bool
+	ResultsChan  chan *service.HealthStatus
+	Ctx          context.Context
+	Cancel       context.CancelFunc
+	TimeInterval int
+	HostNode     *client.HostNode
+	MaxErrorsNum int
+}
+
+// Check -
+func (h *GrpcProbe) Check() {
+	go h.GrpcCheck()
+}
+
+// Stop -
+func (h *GrpcProbe) Stop() {
+	h.Cancel()
+}
+
+// GrpcCheck -
+func (h *GrpcProbe) GrpcCheck() {
+	timer := time.NewTimer(time.Second * time.Duration(h.TimeInterval))
+	defer timer.Stop()
+	for {
+		HealthMap := GetGrpcHealth(h.Address, h.Service, h.TLS)
+		result := &service.HealthStatus{
+			Name:   h.Name,
+			Status: HealthMap["status"],
+			Info:   HealthMap["info"],
+		}
+		h.ResultsChan <- result
+		timer.Reset(time.Second * time.Duration(h.TimeInterval))
+		select {
+		case <-h.Ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// GetGrpcHealth get grpc health
+func GetGrpcHealth(address, grpcService string, tls bool) map[string]string {
+	if err := utilprobe.GRPCHealthCheck(address, grpcService, tls, probeTimeout); err != nil {
+		return map[string]string{"status": service.Stat_unhealthy, "info": err.Error()}
+	}
+	return map[string]string{"status": service.Stat_healthy, "info": "service health"}
+}
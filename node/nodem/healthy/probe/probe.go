@@ -71,6 +71,32 @@ func CreateProbe(ctx context.Context, hostNode *client.HostNode, statusChan chan
 			HostNode:     hostNode,
 			MaxErrorsNum: v.ServiceHealth.MaxErrorsNum,
 		}, nil
+	case "grpc":
+		return &GrpcProbe{
+			Name:         v.ServiceHealth.Name,
+			Address:      v.ServiceHealth.Address,
+			Service:      v.ServiceHealth.Service,
+			TLS:          v.ServiceHealth.TLS,
+			Ctx:          ctx,
+			Cancel:       cancel,
+			ResultsChan:  statusChan,
+			TimeInterval: v.ServiceHealth.TimeInterval,
+			HostNode:     hostNode,
+			MaxErrorsNum: v.ServiceHealth.MaxErrorsNum,
+		}, nil
+	case "tls":
+		return &TLSProbe{
+			Name:                v.ServiceHealth.Name,
+			Address:             v.ServiceHealth.Address,
+			ServerName:          v.ServiceHealth.ServerName,
+			ExpireThresholdDays: v.ServiceHealth.ExpireThresholdDays,
+			Ctx:                 ctx,
+			Cancel:              cancel,
+			ResultsChan:         statusChan,
+			TimeInterval:        v.ServiceHealth.TimeInterval,
+			HostNode:            hostNode,
+			MaxErrorsNum:        v.ServiceHealth.MaxErrorsNum,
+		}, nil
 	default:
 		cancel()
 		return nil, fmt.Errorf("service %s probe mode %s not support ", v.Name, model)
@@ -0,0 +1,67 @@
+package probe
+
+import (
+	"context"
+	"time"
+
+	"github.com/goodrain/rainbond/node/nodem/client"
+	"github.com/goodrain/rainbond/node/nodem/service"
+	utilprobe "github.com/goodrain/rainbond/util/prober/probes"
+)
+
+// TLSProbe checks the certificate of the service does not expire soon
+type TLSProbe struct {
+	Name                string
+	Address             string
+	ServerName          string
+	ExpireThresholdDays int
+	ResultsChan         chan *service.HealthStatus
+	Ctx                 context.Context
+	Cancel              context.CancelFunc
+	TimeInterval        int
+	HostNode            *client.HostNode
+	MaxErrorsNum        int
+}
+
+// Check -
+func (h *TLSProbe) Check() {
+	go h.TLSCheck()
+}
+
+// Stop -
+func (h *TLSProbe) Stop() {
+	h.Cancel()
+}
+
+// TLSCheck -
+func (h *TLSProbe) TLSCheck() {
+	timer := time.NewTimer(time.Second * time.Duration(h.TimeInterval))
+	defer timer.Stop()
+	for {
+		HealthMap := GetTLSHealth(h.Address, h.ServerName, h.ExpireThresholdDays)
+		result := &service.HealthStatus{
+			Name:   h.Name,
+			Status: HealthMap["status"],
+			Info:   HealthMap["info"],
+		}
+		h.ResultsChan <- result
+		timer.Reset(time.Second * time.Duration(h.TimeInterval))
+		select {
+		case <-h.Ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// GetTLSHealth get tls health, the service is unhealthy if the certificate expires within the days, death if it has expired.
+func GetTLSHealth(address, serverName string, expireThresholdDays int) map[string]string {
+	notAfter, err := utilprobe.TLSExpiryCheck(address, serverName, utilprobe.ExpireThreshold(expireThresholdDays), probeTimeout)
+	if err != nil {
+		if notAfter.IsZero() || notAfter.Before(time.Now()) {
+			return map[string]string{"status": service.Stat_death, "info": err.Error()}
+		}
+		return map[string]string{"status": service.Stat_unhealthy, "info": err.Error()}
+	}
+	return map[string]string{"status": service.Stat_healthy, "info": "certificate expires at " + notAfter.Format(time.RFC3339)}
+}
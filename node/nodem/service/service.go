@@ -88,6 +88,14 @@ type Health struct {
 	Address      string `yaml:"address"`
 	TimeInterval int    `yaml:"time_interval"`
 	MaxErrorsNum int    `yaml:"max_errors_num"`
+	// Service is the service name in the health check request of the grpc model
+	Service string `yaml:"service"`
+	// TLS makes the grpc model connect with tls
+	TLS bool `yaml:"tls"`
+	// ServerName is sent in the tls handshake of the tls model
+	ServerName string `yaml:"server_name"`
+	// ExpireThresholdDays the tls model fails if the certificate expires within the days
+	ExpireThresholdDays int `yaml:"expire_threshold_days"`
 }
 
 //HealthStatus health status
@@ -19,6 +19,8 @@
 package v1alpha1
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -149,6 +151,19 @@ func (in *HelmAppSpec) FullName() string {
 	return in.EID + "-" + in.AppStore.Name
 }
 
+// HelmAppStoreType is the type of the helm app store.
+type HelmAppStoreType string
+
+// HelmAppStoreType
+const (
+	// HelmAppStoreTypeHelm is a helm native repo with index.yaml.
+	HelmAppStoreTypeHelm HelmAppStoreType = "helm"
+	// HelmAppStoreTypeOCI is an OCI registry, such as oci://registry.example.com/charts.
+	HelmAppStoreTypeOCI HelmAppStoreType = "oci"
+	// HelmAppStoreTypeGit is a git repo containing the chart.
+	HelmAppStoreTypeGit HelmAppStoreType = "git"
+)
+
 // HelmAppStore represents a helm repo.
 type HelmAppStore struct {
 	// The verision of the helm app store.
@@ -157,12 +172,26 @@ type HelmAppStore struct {
 	// The name of app store.
 	Name string `json:"name"`
 
-	// The url of helm repo, sholud be a helm native repo url or a git url.
+	// The type of app store, inferred from the url if empty.
+	// +kubebuilder:validation:Enum=helm;oci;git
+	// +optional
+	Type HelmAppStoreType `json:"type,omitempty"`
+
+	// The url of helm repo, sholud be a helm native repo url, an oci:// registry url or a git url.
 	URL string `json:"url"`
 
 	// The branch of a git repo.
 	Branch string `json:"branch,omitempty"`
 
+	// The path of the chart in a git repo, the root of the repo by default.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// The digest the chart is pinned to, the manifest digest for an oci chart
+	// or the commit for a git chart.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
 	// The chart repository username where to locate the requested chart
 	Username string `json:"username,omitempty"`
 
@@ -170,6 +199,20 @@ type HelmAppStore struct {
 	Password string `json:"password,omitempty"`
 }
 
+// GetType returns the type of the app store.
+func (in *HelmAppStore) GetType() HelmAppStoreType {
+	if in.Type != "" {
+		return in.Type
+	}
+	if strings.HasPrefix(in.URL, "oci://") {
+		return HelmAppStoreTypeOCI
+	}
+	if strings.HasSuffix(in.URL, ".git") || in.Branch != "" {
+		return HelmAppStoreTypeGit
+	}
+	return HelmAppStoreTypeHelm
+}
+
 // HelmAppStatus defines the observed state of HelmApp
 type HelmAppStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -187,6 +230,9 @@ type HelmAppStatus struct {
 	// The version infect.
 	CurrentVersion string `json:"currentVersion,omitempty"`
 
+	// The digest of the chart in effect.
+	CurrentDigest string `json:"currentDigest,omitempty"`
+
 	// Overrides in effect.
 	Overrides []string `json:"overrides,omitempty"`
 }
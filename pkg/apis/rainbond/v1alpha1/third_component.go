@@ -89,7 +89,7 @@ func (in ThirdComponentSpec) NeedProbe() bool {
 	if in.Probe == nil {
 		return false
 	}
-	return in.IsStaticEndpoints()
+	return in.IsStaticEndpoints() || in.IsDynamicEndpoints()
 }
 
 // IsStaticEndpoints -
@@ -97,14 +97,21 @@ func (in ThirdComponentSpec) IsStaticEndpoints() bool {
 	return len(in.EndpointSource.StaticEndpoints) > 0
 }
 
+// IsDynamicEndpoints returns true if the endpoints are resolved from the dns or a service registry.
+func (in ThirdComponentSpec) IsDynamicEndpoints() bool {
+	source := in.EndpointSource
+	return source.DNS != nil || source.Consul != nil || source.Nacos != nil
+}
+
 // ThirdComponentEndpointSource -
 type ThirdComponentEndpointSource struct {
 	StaticEndpoints   []*ThirdComponentEndpoint `json:"endpoints,omitempty"`
 	KubernetesService *KubernetesServiceSource  `json:"kubernetesService,omitempty"`
+	DNS               *DNSSource                `json:"dns,omitempty"`
+	Consul            *ConsulSource             `json:"consul,omitempty"`
+	Nacos             *NacosSource              `json:"nacos,omitempty"`
 	//other source
-	// NacosSource
 	// EurekaSource
-	// ConsulSource
 	// CustomAPISource
 }
 
@@ -152,6 +159,75 @@ type KubernetesServiceSource struct {
 	Name      string `json:"name"`
 }
 
+// DNSRecordType -
+type DNSRecordType string
+
+// DNSRecordType
+const (
+	DNSRecordA    DNSRecordType = "A"
+	DNSRecordAAAA DNSRecordType = "AAAA"
+	DNSRecordSRV  DNSRecordType = "SRV"
+)
+
+// DNSSource resolves the endpoints from the dns records, and refreshes them as the TTL expires.
+type DNSSource struct {
+	// The domain name to resolve, such as _http._tcp.example.com for the SRV records.
+	Name string `json:"name"`
+	// The type of the records. Defaults to A.
+	// +kubebuilder:validation:Enum=A;AAAA;SRV
+	// +optional
+	Type DNSRecordType `json:"type,omitempty"`
+	// The port of the A and AAAA records. If not specified, the ports of the component are used.
+	// +optional
+	Port int `json:"port,omitempty"`
+	// The address of the nameserver, host:port. If not specified, the nameservers in /etc/resolv.conf are used.
+	// +optional
+	Nameserver string `json:"nameserver,omitempty"`
+}
+
+// GetType -
+func (in *DNSSource) GetType() DNSRecordType {
+	if in.Type == "" {
+		return DNSRecordA
+	}
+	return in.Type
+}
+
+// ConsulSource watches the healthy instances of the service in the consul catalog.
+type ConsulSource struct {
+	// The address of the consul agent, such as http://127.0.0.1:8500
+	Address string `json:"address"`
+	// The name of the service
+	Service string `json:"service"`
+	// Only the instances with the tag are discovered
+	// +optional
+	Tag string `json:"tag,omitempty"`
+	// If not specified, the datacenter of the agent is used.
+	// +optional
+	Datacenter string `json:"datacenter,omitempty"`
+	// The name of the secret which holds the acl token in the key token.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+}
+
+// NacosSource discovers the instances of the service registered in nacos.
+type NacosSource struct {
+	// The address of the nacos server including the context path, such as http://127.0.0.1:8848/nacos
+	Address string `json:"address"`
+	// The name of the service
+	Service string `json:"service"`
+	// +optional
+	Group string `json:"group,omitempty"`
+	// The id of the nacos namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Clusters []string `json:"clusters,omitempty"`
+	// The name of the secret which holds the username and password in the keys username and password.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+}
+
 // Probe describes a health check to be performed against a container to determine whether it is
 // alive or ready to receive traffic.
 type Probe struct {
@@ -203,6 +279,12 @@ type Handler struct {
 	// TODO: implement a realistic TCP lifecycle hook
 	// +optional
 	TCPSocket *TCPSocketAction `json:"tcpSocket,omitempty"`
+	// GRPC specifies an action involving the grpc health checking protocol.
+	// +optional
+	GRPC *GRPCAction `json:"grpc,omitempty"`
+	// TLSExpiry checks the certificate served by the endpoint does not expire soon.
+	// +optional
+	TLSExpiry *TLSExpiryAction `json:"tlsExpiry,omitempty"`
 }
 
 // Equals -
@@ -217,6 +299,12 @@ func (in *Handler) Equals(target *Handler) bool {
 	if !in.HTTPGet.Equals(target.HTTPGet) {
 		return false
 	}
+	if !in.GRPC.Equals(target.GRPC) {
+		return false
+	}
+	if !in.TLSExpiry.Equals(target.TLSExpiry) {
+		return false
+	}
 	return in.TCPSocket.Equals(target.TCPSocket)
 }
 
@@ -237,6 +325,49 @@ func (in *TCPSocketAction) Equals(target *TCPSocketAction) bool {
 	return true
 }
 
+// GRPCAction checks the endpoint through the grpc.health.v1 health checking protocol
+type GRPCAction struct {
+	// Service is the name of the service to place in the health check request.
+	// If not specified, the health of the server as a whole is checked.
+	// +optional
+	Service string `json:"service,omitempty"`
+	// TLS connects the endpoint with tls, the certificate is not verified.
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+}
+
+// Equals -
+func (in *GRPCAction) Equals(target *GRPCAction) bool {
+	if in == nil && target == nil {
+		return true
+	}
+	if in == nil || target == nil {
+		return false
+	}
+	return *in == *target
+}
+
+// TLSExpiryAction fails if the certificate served by the endpoint expires within the threshold
+type TLSExpiryAction struct {
+	// The days before the certificate expires that the probe fails. Defaults to 7.
+	// +optional
+	ThresholdDays int32 `json:"thresholdDays,omitempty"`
+	// ServerName is sent in the tls handshake. Defaults to the host of the endpoint.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// Equals -
+func (in *TLSExpiryAction) Equals(target *TLSExpiryAction) bool {
+	if in == nil && target == nil {
+		return true
+	}
+	if in == nil || target == nil {
+		return false
+	}
+	return *in == *target
+}
+
 //HTTPGetAction enable http check
 type HTTPGetAction struct {
 	// Path to access on the HTTP server.
@@ -333,6 +464,10 @@ func (e EndpointAddress) GetIP() string {
 }
 
 func (e EndpointAddress) getIP() string {
+	if host, _, err := net.SplitHostPort(string(e)); err == nil && strings.Contains(host, ":") {
+		// ipv6
+		return host
+	}
 	info := strings.Split(string(e), ":")
 	if len(info) == 2 {
 		return info[0]
@@ -343,12 +478,12 @@ func (e EndpointAddress) getIP() string {
 // GetPort -
 func (e EndpointAddress) GetPort() int {
 	if !validation.IsDomainNotIP(e.getIP()) {
-		info := strings.Split(string(e), ":")
-		if len(info) == 2 {
-			port, _ := strconv.Atoi(info[1])
-			return port
+		_, port, err := net.SplitHostPort(string(e))
+		if err != nil {
+			return 0
 		}
-		return 0
+		p, _ := strconv.Atoi(port)
+		return p
 	}
 
 	u, err := url.Parse(e.EnsureScheme())
@@ -387,7 +522,7 @@ func NewEndpointAddress(host string, port int) *EndpointAddress {
 		if port < 0 || port > 65533 {
 			return nil
 		}
-		ea := EndpointAddress(fmt.Sprintf("%s:%d", host, port))
+		ea := EndpointAddress(net.JoinHostPort(host, strconv.Itoa(port)))
 		return &ea
 	}
 
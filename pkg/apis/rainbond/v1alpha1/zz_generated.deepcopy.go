@@ -162,6 +162,51 @@ func (in *ComponentPort) DeepCopy() *ComponentPort {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsulSource) DeepCopyInto(out *ConsulSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulSource.
+func (in *ConsulSource) DeepCopy() *ConsulSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSSource) DeepCopyInto(out *DNSSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSSource.
+func (in *DNSSource) DeepCopy() *DNSSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GRPCAction) DeepCopyInto(out *GRPCAction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GRPCAction.
+func (in *GRPCAction) DeepCopy() *GRPCAction {
+	if in == nil {
+		return nil
+	}
+	out := new(GRPCAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPGetAction) DeepCopyInto(out *HTTPGetAction) {
 	*out = *in
@@ -210,6 +255,16 @@ func (in *Handler) DeepCopyInto(out *Handler) {
 		*out = new(TCPSocketAction)
 		**out = **in
 	}
+	if in.GRPC != nil {
+		in, out := &in.GRPC, &out.GRPC
+		*out = new(GRPCAction)
+		**out = **in
+	}
+	if in.TLSExpiry != nil {
+		in, out := &in.TLSExpiry, &out.TLSExpiry
+		*out = new(TLSExpiryAction)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Handler.
@@ -379,6 +434,26 @@ func (in *KubernetesServiceSource) DeepCopy() *KubernetesServiceSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NacosSource) DeepCopyInto(out *NacosSource) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NacosSource.
+func (in *NacosSource) DeepCopy() *NacosSource {
+	if in == nil {
+		return nil
+	}
+	out := new(NacosSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Probe) DeepCopyInto(out *Probe) {
 	*out = *in
@@ -618,6 +693,21 @@ func (in *TCPSocketAction) DeepCopy() *TCPSocketAction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSExpiryAction) DeepCopyInto(out *TLSExpiryAction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSExpiryAction.
+func (in *TLSExpiryAction) DeepCopy() *TLSExpiryAction {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSExpiryAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThirdComponent) DeepCopyInto(out *ThirdComponent) {
 	*out = *in
@@ -679,6 +769,21 @@ func (in *ThirdComponentEndpointSource) DeepCopyInto(out *ThirdComponentEndpoint
 		*out = new(KubernetesServiceSource)
 		**out = **in
 	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(DNSSource)
+		**out = **in
+	}
+	if in.Consul != nil {
+		in, out := &in.Consul, &out.Consul
+		*out = new(ConsulSource)
+		**out = **in
+	}
+	if in.Nacos != nil {
+		in, out := &in.Nacos, &out.Nacos
+		*out = new(NacosSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThirdComponentEndpointSource.
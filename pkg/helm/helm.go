@@ -51,6 +51,9 @@ type Helm struct {
 
 	repoFile  string
 	repoCache string
+
+	// source locates the chart outside the index.yaml repos if not nil.
+	source *ChartSource
 }
 
 // NewHelm creates a new helm.
@@ -89,6 +92,11 @@ func NewHelm(namespace, repoFile, repoCache string) (*Helm, error) {
 	}, nil
 }
 
+// SetChartSource sets where the chart is located besides the index.yaml repos.
+func (h *Helm) SetChartSource(source *ChartSource) {
+	h.source = source
+}
+
 //UpdateRepo -
 func (h *Helm) UpdateRepo(names string) error {
 	return h.repoUpdate(names, ioutil.Discard)
@@ -107,9 +115,20 @@ func (h *Helm) Install(chartPath, name, chart, version string, overrides []strin
 }
 
 func (h *Helm) locateChart(chart, version string) (string, error) {
+	cp, _, err := h.locateChartWithDigest(chart, version)
+	return cp, err
+}
+
+// locateChartWithDigest returns the path and the digest of the chart.
+func (h *Helm) locateChartWithDigest(chart, version string) (string, string, error) {
 	repoAndName := strings.Split(chart, "/")
 	if len(repoAndName) != 2 {
-		return "", errors.New("invalid chart. expect repo/name, but got " + chart)
+		return "", "", errors.New("invalid chart. expect repo/name, but got " + chart)
+	}
+
+	if h.source != nil {
+		chartCache := path.Join(h.settings.RepositoryCache, string(h.source.Type), chart, version)
+		return h.source.Locate(repoAndName[1], version, chartCache)
 	}
 
 	chartCache := path.Join(h.settings.RepositoryCache, chart, version)
@@ -120,17 +139,17 @@ func (h *Helm) locateChart(chart, version string) (string, error) {
 		// check if the chart file is up to date.
 		hash, err := provenance.Digest(f)
 		if err != nil {
-			return "", errors.Wrap(err, "digist chart file")
+			return "", "", errors.Wrap(err, "digist chart file")
 		}
 
 		// get digiest from repo index.
 		digest, err := h.getDigest(chart, version)
 		if err != nil {
-			return "", err
+			return "", "", err
 		}
 
 		if hash == digest {
-			return cp, nil
+			return cp, digest, nil
 		}
 	}
 
@@ -139,10 +158,14 @@ func (h *Helm) locateChart(chart, version string) (string, error) {
 	settings := h.settings
 	cp, err := cpo.LocateChart(chart, chartCache, settings)
 	if err != nil {
-		return "", err
+		return "", "", err
+	}
+	digest, err := h.getDigest(chart, version)
+	if err != nil {
+		logrus.Warningf("get digest of chart %s: %v", chart, err)
 	}
 
-	return cp, err
+	return cp, digest, nil
 }
 
 func (h *Helm) getDigest(chart, version string) (string, error) {
@@ -180,7 +203,7 @@ func (h *Helm) install(name, chart, version, chartPath string, overrides []strin
 	//client.IsUpgrade = true
 	client.ClientOnly = true
 	var cp string
-	if chartPath != ""{
+	if chartPath != "" {
 		cp = chartPath
 	} else {
 		res, err := h.locateChart(chart, version)
@@ -260,14 +283,17 @@ func (h *Helm) parseOverrides(overrides []string) (map[string]interface{}, error
 }
 
 // Upgrade -
-func (h *Helm) Upgrade(name string, chart, version string, overrides []string) error {
+func (h *Helm) Upgrade(chartPath, name string, chart, version string, overrides []string) error {
 	client := action.NewUpgrade(h.cfg)
 	client.Namespace = h.namespace
 	client.Version = version
 
-	chartPath, err := h.locateChart(chart, version)
-	if err != nil {
-		return err
+	if chartPath == "" {
+		cp, err := h.locateChart(chart, version)
+		if err != nil {
+			return err
+		}
+		chartPath = cp
 	}
 
 	// User specified a value via --set
@@ -356,9 +382,9 @@ func (h *Helm) History(name string) (ReleaseHistory, error) {
 	return releaseHistory, nil
 }
 
-// Load loads the chart from the repository.
-func (h *Helm) Load(chart, version string) (string, error) {
-	return h.locateChart(chart, version)
+// Load loads the chart from the repository or the chart source, returns the path and the digest of the chart.
+func (h *Helm) Load(chart, version string) (string, string, error) {
+	return h.locateChartWithDigest(chart, version)
 }
 
 // ChartPathOptions -
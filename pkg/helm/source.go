@@ -0,0 +1,188 @@
+package helm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// ChartSourceType is the type of the chart source.
+type ChartSourceType string
+
+// ChartSourceType
+const (
+	ChartSourceOCI ChartSourceType = "oci"
+	ChartSourceGit ChartSourceType = "git"
+)
+
+// digestFile records the digest of the located chart.
+const digestFile = "digest"
+
+// ChartSource locates the chart in an OCI registry or a git repo rather than an index.yaml repo.
+type ChartSource struct {
+	Type ChartSourceType
+	// URL is oci://registry/namespace for an OCI registry, or the url of a git repo.
+	URL string
+	// Branch is the branch of the git repo.
+	Branch string
+	// Path is the path of the chart in the git repo.
+	Path string
+	// Digest pins the chart, the manifest digest for an OCI chart or the commit for a git chart.
+	Digest   string
+	Username string
+	Password string
+}
+
+// Locate locates the chart into dest, returns the path and the digest of the chart.
+func (s *ChartSource) Locate(name, version, dest string) (string, string, error) {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", "", err
+	}
+	switch s.Type {
+	case ChartSourceOCI:
+		return s.locateOCI(name, version, dest)
+	case ChartSourceGit:
+		return s.locateGit(dest)
+	}
+	return "", "", fmt.Errorf("unsupported chart source %s", s.Type)
+}
+
+// ociReference returns the reference of the chart in the OCI registry, the digest takes precedence over the version.
+func (s *ChartSource) ociReference(name, version string) string {
+	ref := strings.TrimSuffix(strings.TrimPrefix(s.URL, registry.OCIScheme+"://"), "/") + "/" + name
+	if s.Digest != "" {
+		return ref + "@" + s.Digest
+	}
+	return ref + ":" + version
+}
+
+func (s *ChartSource) locateOCI(name, version, dest string) (string, string, error) {
+	cp := path.Join(dest, name+"-"+version+".tgz")
+	// the pinned chart never changes, while the tag may be moved.
+	if s.Digest != "" && cachedDigest(dest) == s.Digest {
+		if _, err := os.Stat(cp); err == nil {
+			return cp, s.Digest, nil
+		}
+	}
+
+	ref := s.ociReference(name, version)
+	credentialsFile := path.Join(dest, "config.json")
+	if err := writeCredentials(credentialsFile, strings.SplitN(ref, "/", 2)[0], s.Username, s.Password); err != nil {
+		return "", "", err
+	}
+	defer os.Remove(credentialsFile)
+	client, err := registry.NewClient(registry.ClientOptCredentialsFile(credentialsFile))
+	if err != nil {
+		return "", "", errors.Wrap(err, "create registry client")
+	}
+	res, err := client.Pull(ref)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "pull chart %s", ref)
+	}
+	if s.Digest != "" && res.Manifest.Digest != s.Digest {
+		return "", "", fmt.Errorf("chart %s digest mismatch, expect %s, but got %s", ref, s.Digest, res.Manifest.Digest)
+	}
+	if err := ioutil.WriteFile(cp, res.Chart.Data, 0644); err != nil {
+		return "", "", err
+	}
+	if err := ioutil.WriteFile(path.Join(dest, digestFile), []byte(res.Manifest.Digest), 0644); err != nil {
+		logrus.Warningf("record digest of chart %s: %v", ref, err)
+	}
+	return cp, res.Manifest.Digest, nil
+}
+
+// writeCredentials writes the docker config file the registry client authorizes with.
+func writeCredentials(file, host, username, password string) error {
+	auths := map[string]interface{}{}
+	if username != "" || password != "" {
+		auths[host] = map[string]string{
+			"auth": base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{"auths": auths})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+func (s *ChartSource) locateGit(dest string) (string, string, error) {
+	repoDir := path.Join(dest, "repo")
+	chartDir := filepath.Join(repoDir, filepath.Clean("/"+s.Path))
+	if s.Digest != "" && cachedDigest(dest) == s.Digest {
+		if _, err := os.Stat(chartDir); err == nil {
+			return chartDir, s.Digest, nil
+		}
+	}
+
+	if err := os.RemoveAll(repoDir); err != nil {
+		return "", "", err
+	}
+	opts := &git.CloneOptions{
+		URL:          s.URL,
+		SingleBranch: true,
+	}
+	if s.Branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(s.Branch)
+	}
+	if s.Digest == "" {
+		opts.Depth = 1
+	}
+	if s.Username != "" || s.Password != "" {
+		opts.Auth = &githttp.BasicAuth{
+			Username: s.Username,
+			Password: s.Password,
+		}
+	}
+	repo, err := git.PlainClone(repoDir, false, opts)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "clone %s", s.URL)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", "", errors.Wrap(err, "get head")
+	}
+	commit := head.Hash().String()
+	if s.Digest != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(s.Digest))
+		if err != nil {
+			return "", "", errors.Wrapf(err, "resolve commit %s", s.Digest)
+		}
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return "", "", err
+		}
+		if err := worktree.Checkout(&git.CheckoutOptions{Hash: *hash}); err != nil {
+			return "", "", errors.Wrapf(err, "checkout %s", s.Digest)
+		}
+		commit = hash.String()
+	}
+
+	if _, err := os.Stat(path.Join(chartDir, "Chart.yaml")); err != nil {
+		return "", "", errors.Wrapf(err, "chart not found in %s", s.Path)
+	}
+	if err := ioutil.WriteFile(path.Join(dest, digestFile), []byte(commit), 0644); err != nil {
+		logrus.Warningf("record commit of chart %s: %v", s.URL, err)
+	}
+	return chartDir, commit, nil
+}
+
+func cachedDigest(dest string) string {
+	data, err := ioutil.ReadFile(path.Join(dest, digestFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
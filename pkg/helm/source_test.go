@@ -0,0 +1,87 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestOCIReference(t *testing.T) {
+	source := &ChartSource{Type: ChartSourceOCI, URL: "oci://registry.example.com/charts/"}
+	assert.Equal(t, "registry.example.com/charts/nginx:1.0.0", source.ociReference("nginx", "1.0.0"))
+
+	source.Digest = "sha256:4a5f"
+	assert.Equal(t, "registry.example.com/charts/nginx@sha256:4a5f", source.ociReference("nginx", "1.0.0"))
+}
+
+func TestLocateGitChart(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir, err := ioutil.TempDir("", "chart-repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := commitChart(t, dir, "1.0.0")
+	second := commitChart(t, dir, "1.1.0")
+
+	source := &ChartSource{Type: ChartSourceGit, URL: dir, Path: "charts/demo"}
+	cp, digest, err := source.Locate("demo", "1.1.0", path.Join(dir, "cache", "latest"))
+	assert.Nil(t, err)
+	assert.Equal(t, second.String(), digest)
+	assertChartVersion(t, cp, "1.1.0")
+
+	source.Digest = first.String()
+	cp, digest, err = source.Locate("demo", "1.0.0", path.Join(dir, "cache", "pinned"))
+	assert.Nil(t, err)
+	assert.Equal(t, first.String(), digest)
+	assertChartVersion(t, cp, "1.0.0")
+}
+
+func commitChart(t *testing.T, dir, version string) plumbing.Hash {
+	repo, err := git.PlainOpen(dir)
+	if err == git.ErrRepositoryNotExists {
+		repo, err = git.PlainInit(dir, false)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	chartDir := path.Join(dir, "charts", "demo")
+	if err := os.MkdirAll(chartDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chart := "apiVersion: v2\nname: demo\nversion: " + version + "\n"
+	if err := ioutil.WriteFile(path.Join(chartDir, "Chart.yaml"), []byte(chart), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("charts/demo/Chart.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit("chart "+version, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func assertChartVersion(t *testing.T, chartDir, version string) {
+	data, err := ioutil.ReadFile(path.Join(chartDir, "Chart.yaml"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "version: "+version)
+}
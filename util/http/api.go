@@ -209,6 +209,21 @@ func ReturnResNotEnough(r *http.Request, w http.ResponseWriter, eventID, msg str
 	render.DefaultResponder(w, r, ResponseBody{Msg: msg})
 }
 
+// ReturnResNotEnoughError responds the quota errors with their bcode, and the others as ReturnResNotEnough does
+func ReturnResNotEnoughError(r *http.Request, w http.ResponseWriter, eventID string, err error) {
+	var coder bcode.Coder
+	if !errors.As(err, &coder) {
+		ReturnResNotEnough(r, w, eventID, err.Error())
+		return
+	}
+	if err := db.GetManager().ServiceEventDao().UpdateReason(eventID, coder.Error()); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logrus.Warningf("update event reason: %v", err)
+		}
+	}
+	ReturnBcodeError(r, w, err)
+}
+
 //ReturnBcodeError bcode error
 func ReturnBcodeError(r *http.Request, w http.ResponseWriter, err error) {
 	berr := bcode.Err2Coder(err)
@@ -0,0 +1,171 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the hmac-sha256 signature of a webhook body
+const SignatureHeader = "X-Rainbond-Signature"
+
+type webhookNotifier struct {
+	cfg    *Config
+	client *http.Client
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, msg *Message) error {
+	body := marshal(msg)
+	headers := make(map[string]string, len(w.cfg.Headers)+1)
+	for k, v := range w.cfg.Headers {
+		headers[k] = v
+	}
+	if w.cfg.Secret != "" {
+		headers[SignatureHeader] = "sha256=" + Sign(w.cfg.Secret, body)
+	}
+	return postJSON(ctx, w.client, w.cfg.URL, headers, body)
+}
+
+// Sign returns the hex encoded hmac-sha256 of the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type dingTalkNotifier struct {
+	cfg    *Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func (d *dingTalkNotifier) Notify(ctx context.Context, msg *Message) error {
+	address := d.cfg.URL
+	if d.cfg.Secret != "" {
+		now := time.Now
+		if d.now != nil {
+			now = d.now
+		}
+		timestamp := strconv.FormatInt(now().UnixNano()/int64(time.Millisecond), 10)
+		mac := hmac.New(sha256.New, []byte(d.cfg.Secret))
+		mac.Write([]byte(timestamp + "\n" + d.cfg.Secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		u, err := url.Parse(address)
+		if err != nil {
+			return err
+		}
+		query := u.Query()
+		query.Set("timestamp", timestamp)
+		query.Set("sign", sign)
+		u.RawQuery = query.Encode()
+		address = u.String()
+	}
+	body := marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": msg.Title(),
+			"text":  "#### " + msg.Title() + "\n\n" + strings.Replace(msg.Text(), "\n", "\n\n", -1),
+		},
+	})
+	return postJSON(ctx, d.client, address, nil, body)
+}
+
+type slackNotifier struct {
+	cfg    *Config
+	client *http.Client
+}
+
+func (s *slackNotifier) Notify(ctx context.Context, msg *Message) error {
+	body := marshal(map[string]string{"text": msg.Text()})
+	return postJSON(ctx, s.client, s.cfg.URL, nil, body)
+}
+
+type emailNotifier struct {
+	cfg *Config
+}
+
+func (e *emailNotifier) Notify(ctx context.Context, msg *Message) error {
+	addr := net.JoinHostPort(e.cfg.SMTPHost, strconv.Itoa(e.cfg.SMTPPort))
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.SMTPHost)
+	}
+	content := e.build(msg)
+	if !e.cfg.ImplicitTLS {
+		return smtp.SendMail(addr, auth, e.cfg.From, e.cfg.To, content)
+	}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: e.cfg.SMTPHost})
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, e.cfg.SMTPHost)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range e.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(content); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (e *emailNotifier) build(msg *Message) []byte {
+	var header strings.Builder
+	fmt.Fprintf(&header, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&header, "To: %s\r\n", strings.Join(e.cfg.To, ","))
+	fmt.Fprintf(&header, "Subject: %s\r\n", msg.Title())
+	header.WriteString("MIME-Version: 1.0\r\n")
+	header.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	header.WriteString(strings.Replace(msg.Text(), "\n", "\r\n", -1))
+	return []byte(header.String())
+}
@@ -0,0 +1,167 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message the content of an alert notification
+type Message struct {
+	RuleID      string            `json:"rule_id"`
+	RuleName    string            `json:"rule_name"`
+	Status      string            `json:"status"`
+	Severity    string            `json:"severity"`
+	Summary     string            `json:"summary"`
+	Description string            `json:"description"`
+	Labels      map[string]string `json:"labels"`
+	StartsAt    time.Time         `json:"starts_at"`
+	EndsAt      time.Time         `json:"ends_at,omitempty"`
+}
+
+// Title one line title of the message
+func (m *Message) Title() string {
+	return fmt.Sprintf("[%s][%s] %s", strings.ToUpper(m.Status), m.Severity, m.RuleName)
+}
+
+// Text plain text body of the message
+func (m *Message) Text() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", m.Title())
+	if m.Summary != "" {
+		fmt.Fprintf(&buf, "Summary: %s\n", m.Summary)
+	}
+	if m.Description != "" {
+		fmt.Fprintf(&buf, "Description: %s\n", m.Description)
+	}
+	fmt.Fprintf(&buf, "Starts at: %s\n", m.StartsAt.Format(time.RFC3339))
+	if !m.EndsAt.IsZero() && m.Status == "resolved" {
+		fmt.Fprintf(&buf, "Ends at: %s\n", m.EndsAt.Format(time.RFC3339))
+	}
+	var keys []string
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s\n", k, m.Labels[k])
+	}
+	return buf.String()
+}
+
+// Notifier sends a message to a notification channel
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// Config the settings of a notification channel
+type Config struct {
+	// URL webhook, dingtalk or slack address
+	URL string `json:"url,omitempty"`
+	// Secret signs webhook requests, or the dingtalk robot requests
+	Secret  string            `json:"secret,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	SMTPHost string   `json:"smtp_host,omitempty"`
+	SMTPPort int      `json:"smtp_port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+	// ImplicitTLS dial the smtp server with tls, usually on port 465
+	ImplicitTLS bool `json:"implicit_tls,omitempty"`
+}
+
+// Validate checks the config is usable by the given channel type
+func (c *Config) Validate(channelType string) error {
+	switch channelType {
+	case "webhook", "dingtalk", "slack":
+		if c.URL == "" {
+			return fmt.Errorf("url is required by %s channel", channelType)
+		}
+		if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			return fmt.Errorf("url %s is not a http address", c.URL)
+		}
+	case "email":
+		if c.SMTPHost == "" || c.SMTPPort == 0 {
+			return fmt.Errorf("smtp host and port are required by email channel")
+		}
+		if c.From == "" || len(c.To) == 0 {
+			return fmt.Errorf("from and to are required by email channel")
+		}
+	default:
+		return fmt.Errorf("channel type %s is not supported", channelType)
+	}
+	return nil
+}
+
+// New creates the notifier of the channel type
+func New(channelType string, cfg *Config) (Notifier, error) {
+	if err := cfg.Validate(channelType); err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch channelType {
+	case "webhook":
+		return &webhookNotifier{cfg: cfg, client: client}, nil
+	case "dingtalk":
+		return &dingTalkNotifier{cfg: cfg, client: client}, nil
+	case "slack":
+		return &slackNotifier{cfg: cfg, client: client}, nil
+	case "email":
+		return &emailNotifier{cfg: cfg}, nil
+	}
+	return nil, fmt.Errorf("channel type %s is not supported", channelType)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		content, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("notify %s: status %d, %s", url, res.StatusCode, string(content))
+	}
+	return nil
+}
+
+func marshal(v interface{}) []byte {
+	body, _ := json.Marshal(v)
+	return body
+}
@@ -0,0 +1,136 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMessage() *Message {
+	return &Message{
+		RuleID:   "rule1",
+		RuleName: "high-cpu",
+		Status:   "firing",
+		Severity: "warning",
+		Summary:  "cpu usage is too high",
+		Labels:   map[string]string{"service_id": "abc"},
+		StartsAt: time.Unix(1600000000, 0),
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	var body []byte
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+	}))
+	defer srv.Close()
+
+	n, err := New("webhook", &Config{URL: srv.URL, Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	var got Message
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.RuleID != "rule1" {
+		t.Errorf("want rule id rule1, got %s", got.RuleID)
+	}
+	if signature != "sha256="+Sign("s3cret", body) {
+		t.Errorf("unexpected signature %s", signature)
+	}
+}
+
+func TestDingTalkNotify(t *testing.T) {
+	var query map[string][]string
+	var payload map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer srv.Close()
+
+	n := &dingTalkNotifier{
+		cfg:    &Config{URL: srv.URL + "?access_token=token", Secret: "s3cret"},
+		client: http.DefaultClient,
+		now:    func() time.Time { return time.Unix(1600000000, 0) },
+	}
+	if err := n.Notify(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	if query["timestamp"][0] != "1600000000000" || query["sign"][0] == "" || query["access_token"][0] != "token" {
+		t.Errorf("unexpected query %v", query)
+	}
+	if payload["msgtype"] != "markdown" {
+		t.Errorf("unexpected payload %v", payload)
+	}
+}
+
+func TestSlackNotifyError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("invalid_token"))
+	}))
+	defer srv.Close()
+
+	n, err := New("slack", &Config{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Notify(context.Background(), testMessage())
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("want invalid_token error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		channelType string
+		cfg         Config
+		wantErr     bool
+	}{
+		{name: "webhook", channelType: "webhook", cfg: Config{URL: "http://example.com"}},
+		{name: "webhook without url", channelType: "webhook", wantErr: true},
+		{name: "not http", channelType: "slack", cfg: Config{URL: "ftp://example.com"}, wantErr: true},
+		{name: "email", channelType: "email", cfg: Config{SMTPHost: "smtp.example.com", SMTPPort: 25, From: "a@example.com", To: []string{"b@example.com"}}},
+		{name: "email without to", channelType: "email", cfg: Config{SMTPHost: "smtp.example.com", SMTPPort: 25, From: "a@example.com"}, wantErr: true},
+		{name: "unknown", channelType: "sms", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate(tc.channelType)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
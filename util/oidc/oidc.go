@@ -0,0 +1,379 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUnknownKey the token is signed by a key which is not in the jwks of the issuer
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// minRefreshInterval limits how often the jwks is refreshed because of unknown key ids
+const minRefreshInterval = 10 * time.Second
+
+// Config the settings of the oidc provider
+type Config struct {
+	IssuerURL string
+	// Audiences the token must be issued for one of them
+	Audiences []string
+	// UsernameClaim defaults to preferred_username, the subject is used if the claim is empty
+	UsernameClaim string
+	// TenantsClaim lists the tenants the user can access, * means all tenants
+	TenantsClaim string
+	// RolesClaim the roles of the user, nested claims are separated by dots, such as realm_access.roles
+	RolesClaim string
+	// RoleScopes maps the roles to the scopes
+	RoleScopes   map[string]string
+	JWKSCacheTTL time.Duration
+	HTTPClient   *http.Client
+}
+
+// Identity the user identified by a token
+type Identity struct {
+	Subject  string   `json:"subject"`
+	Username string   `json:"username"`
+	Tenants  []string `json:"tenants"`
+	Roles    []string `json:"roles"`
+	// Scopes the scopes mapped from the roles
+	Scopes []string `json:"scopes"`
+}
+
+// CanAccessTenant reports whether the user can access the tenant identified by any of the names
+func (i *Identity) CanAccessTenant(names ...string) bool {
+	for _, tenant := range i.Tenants {
+		if tenant == "*" {
+			return true
+		}
+		for _, name := range names {
+			if name != "" && tenant == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the user has any of the roles
+func (i *Identity) HasRole(roles ...string) bool {
+	for _, role := range i.Roles {
+		for _, r := range roles {
+			if role == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Verifier verifies the id tokens issued by an oidc provider
+type Verifier struct {
+	config Config
+	client *http.Client
+
+	lock      sync.Mutex
+	jwksURI   string
+	keys      map[string]interface{}
+	fetchTime time.Time
+}
+
+// NewVerifier creates a verifier, the provider is discovered when the first token is verified
+func NewVerifier(config Config) (*Verifier, error) {
+	if config.IssuerURL == "" {
+		return nil, fmt.Errorf("issuer url can not be empty")
+	}
+	if len(config.Audiences) == 0 {
+		return nil, fmt.Errorf("at least one audience is required")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "preferred_username"
+	}
+	if config.TenantsClaim == "" {
+		config.TenantsClaim = "tenants"
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = "roles"
+	}
+	if config.JWKSCacheTTL == 0 {
+		config.JWKSCacheTTL = time.Hour
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Verifier{config: config, client: client}, nil
+}
+
+// LooksLikeJWT reports whether the token is in the compact jws format
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify verifies the signature, issuer, audience and expiry of the token and maps its claims
+func (v *Verifier) Verify(raw string) (*Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA, *jwt.SigningMethodRSAPSS:
+		default:
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		kid, _ := token.Header["kid"].(string)
+		return v.key(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(v.config.IssuerURL, "/") {
+		return nil, fmt.Errorf("unexpected issuer %s", iss)
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("token without expiry is not accepted")
+	}
+	if !v.verifyAudience(claims["aud"]) {
+		return nil, fmt.Errorf("token is not issued for %s", strings.Join(v.config.Audiences, ","))
+	}
+	identity := &Identity{
+		Subject: stringClaim(claims, "sub"),
+		Tenants: listClaim(claims, v.config.TenantsClaim),
+		Roles:   listClaim(claims, v.config.RolesClaim),
+	}
+	identity.Username = stringClaim(claims, v.config.UsernameClaim)
+	if identity.Username == "" {
+		identity.Username = identity.Subject
+	}
+	for _, role := range identity.Roles {
+		if scope, ok := v.config.RoleScopes[role]; ok {
+			identity.Scopes = append(identity.Scopes, scope)
+		}
+	}
+	return identity, nil
+}
+
+func (v *Verifier) verifyAudience(aud interface{}) bool {
+	var auds []string
+	switch a := aud.(type) {
+	case string:
+		auds = []string{a}
+	case []interface{}:
+		for _, item := range a {
+			if s, ok := item.(string); ok {
+				auds = append(auds, s)
+			}
+		}
+	}
+	for _, a := range auds {
+		for _, expected := range v.config.Audiences {
+			if a == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key returns the public key of the kid, the jwks is refreshed when it is expired
+// or the kid is unknown, which happens after the provider rotates its keys.
+func (v *Verifier) key(kid string) (interface{}, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	age := time.Since(v.fetchTime)
+	if v.keys == nil || age > v.config.JWKSCacheTTL {
+		if err := v.refresh(); err != nil {
+			return nil, err
+		}
+	}
+	if key := v.lookup(kid); key != nil {
+		return key, nil
+	}
+	if age > minRefreshInterval {
+		if err := v.refresh(); err != nil {
+			return nil, err
+		}
+		if key := v.lookup(kid); key != nil {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+func (v *Verifier) lookup(kid string) interface{} {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key
+		}
+	}
+	return v.keys[kid]
+}
+
+func (v *Verifier) refresh() error {
+	if v.jwksURI == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(strings.TrimSuffix(v.config.IssuerURL, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return fmt.Errorf("discover oidc provider: %v", err)
+		}
+		if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(v.config.IssuerURL, "/") {
+			return fmt.Errorf("issuer %s of the provider does not match %s", discovery.Issuer, v.config.IssuerURL)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("the provider does not publish jwks_uri")
+		}
+		v.jwksURI = discovery.JWKSURI
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(v.jwksURI, &jwks); err != nil {
+		return fmt.Errorf("fetch jwks: %v", err)
+	}
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			logrus.Warningf("skip jwk %s: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	v.keys = keys
+	v.fetchTime = time.Now()
+	return nil
+}
+
+func (v *Verifier) getJSON(url string, out interface{}) error {
+	res, err := v.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: status %d", url, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// claim looks up the claim by its path, such as realm_access.roles
+func claim(claims jwt.MapClaims, path string) interface{} {
+	var value interface{} = map[string]interface{}(claims)
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func stringClaim(claims jwt.MapClaims, path string) string {
+	s, _ := claim(claims, path).(string)
+	return s
+}
+
+// listClaim accepts both a json array and a space or comma separated string
+func listClaim(claims jwt.MapClaims, path string) []string {
+	var list []string
+	switch value := claim(claims, path).(type) {
+	case []interface{}:
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				list = append(list, s)
+			}
+		}
+	case string:
+		list = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	return list
+}
@@ -0,0 +1,172 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+type testProvider struct {
+	*httptest.Server
+	lock sync.Mutex
+	keys map[string]*rsa.PrivateKey
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	p := &testProvider{keys: make(map[string]*rsa.PrivateKey)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": p.URL, "jwks_uri": p.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		var keys []map[string]string
+		for kid, key := range p.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	})
+	p.Server = httptest.NewServer(mux)
+	p.addKey(t, "key1")
+	return p
+}
+
+func (p *testProvider) addKey(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.lock.Lock()
+	p.keys[kid] = key
+	p.lock.Unlock()
+}
+
+func (p *testProvider) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	p.lock.Lock()
+	key := p.keys[kid]
+	p.lock.Unlock()
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func (p *testProvider) claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                p.URL,
+		"sub":                "user-1",
+		"aud":                []interface{}{"rbd-api", "console"},
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"preferred_username": "alice",
+		"tenants":            []interface{}{"team-a", "team-b"},
+		"realm_access":       map[string]interface{}{"roles": []interface{}{"developer", "offline_access"}},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	provider := newTestProvider(t)
+	defer provider.Close()
+	verifier, err := NewVerifier(Config{
+		IssuerURL:  provider.URL,
+		Audiences:  []string{"rbd-api"},
+		RolesClaim: "realm_access.roles",
+		RoleScopes: map[string]string{"developer": "deploy"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := verifier.Verify(provider.sign(t, "key1", provider.claims()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Username != "alice" || identity.Subject != "user-1" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	if !identity.CanAccessTenant("team-b") || identity.CanAccessTenant("team-c") {
+		t.Errorf("unexpected tenants %v", identity.Tenants)
+	}
+	if len(identity.Scopes) != 1 || identity.Scopes[0] != "deploy" {
+		t.Errorf("unexpected scopes %v", identity.Scopes)
+	}
+
+	wrongAudience := provider.claims()
+	wrongAudience["aud"] = "other"
+	if _, err := verifier.Verify(provider.sign(t, "key1", wrongAudience)); err == nil {
+		t.Error("token of other audience should be rejected")
+	}
+	expired := provider.claims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	if _, err := verifier.Verify(provider.sign(t, "key1", expired)); err == nil {
+		t.Error("expired token should be rejected")
+	}
+	wrongIssuer := provider.claims()
+	wrongIssuer["iss"] = "https://issuer.example.com"
+	if _, err := verifier.Verify(provider.sign(t, "key1", wrongIssuer)); err == nil {
+		t.Error("token of other issuer should be rejected")
+	}
+}
+
+func TestVerifyRotatedKey(t *testing.T) {
+	provider := newTestProvider(t)
+	defer provider.Close()
+	verifier, err := NewVerifier(Config{IssuerURL: provider.URL, Audiences: []string{"rbd-api"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(provider.sign(t, "key1", provider.claims())); err != nil {
+		t.Fatal(err)
+	}
+
+	provider.addKey(t, "key2")
+	raw := provider.sign(t, "key2", provider.claims())
+	// the cached jwks is too young to be refreshed
+	if _, err := verifier.Verify(raw); err == nil {
+		t.Fatal("unknown key should be rejected before the jwks is refreshed")
+	}
+	verifier.fetchTime = time.Now().Add(-2 * minRefreshInterval)
+	identity, err := verifier.Verify(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Username != "alice" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+}
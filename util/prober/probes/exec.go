@@ -0,0 +1,112 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	v1 "github.com/goodrain/rainbond/util/prober/types/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// ExecProbe probes by running the command, the service is healthy if the command exits with 0
+type ExecProbe struct {
+	Name          string
+	Command       string
+	ResultsChan   chan *v1.HealthStatus
+	Ctx           context.Context
+	Cancel        context.CancelFunc
+	TimeoutSecond int
+	TimeInterval  int
+	MaxErrorsNum  int
+}
+
+// Check starts exec probe.
+func (h *ExecProbe) Check() {
+	go h.ExecCheck()
+}
+
+// Stop stops exec probe.
+func (h *ExecProbe) Stop() {
+	h.Cancel()
+}
+
+// ExecCheck exec check
+func (h *ExecProbe) ExecCheck() {
+	logrus.Debugf("Exec check; Name: %s; Command: %s Interval %d", h.Name, h.Command, h.TimeInterval)
+	timer := time.NewTimer(time.Second * time.Duration(h.TimeInterval))
+	defer timer.Stop()
+	for {
+		HealthMap := h.GetExecHealth()
+		result := &v1.HealthStatus{
+			Name:   h.Name,
+			Status: HealthMap["status"],
+			Info:   HealthMap["info"],
+		}
+		h.ResultsChan <- result
+		timer.Reset(time.Second * time.Duration(h.TimeInterval))
+		select {
+		case <-h.Ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// GetExecHealth get exec health
+func (h *ExecProbe) GetExecHealth() map[string]string {
+	if err := ExecHealthCheck(h.Ctx, h.Command, time.Duration(h.TimeoutSecond)*time.Second); err != nil {
+		logrus.Debugf("exec probe command %s: %v", h.Command, err)
+		return map[string]string{"status": v1.StatDeath, "info": err.Error()}
+	}
+	return map[string]string{"status": v1.StatHealthy, "info": "service health"}
+}
+
+// ExecHealthCheck runs the command with sh, returns the error with the stderr if the command fails.
+func ExecHealthCheck(ctx context.Context, command string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// stderr is written to a file rather than a pipe, otherwise the processes the command
+	// starts in background keep the pipe open, and the probe waits for them after the timeout.
+	stderr, err := ioutil.TempFile("", "exec-probe")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(stderr.Name())
+	defer stderr.Close()
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("command timed out after %s", timeout)
+		}
+		output, _ := ioutil.ReadFile(stderr.Name())
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	v1 "github.com/goodrain/rainbond/util/prober/types/v1"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCProbe probes through the grpc health checking protocol
+type GRPCProbe struct {
+	Name string
+	// Address is the address of the grpc server, host:port
+	Address string
+	// Service is the service name in the health check request, empty means the server as a whole
+	Service       string
+	TLS           bool
+	ResultsChan   chan *v1.HealthStatus
+	Ctx           context.Context
+	Cancel        context.CancelFunc
+	TimeoutSecond int
+	TimeInterval  int
+	MaxErrorsNum  int
+}
+
+// Check starts grpc probe.
+func (h *GRPCProbe) Check() {
+	go h.GRPCCheck()
+}
+
+// Stop stops grpc probe.
+func (h *GRPCProbe) Stop() {
+	h.Cancel()
+}
+
+// GRPCCheck grpc check
+func (h *GRPCProbe) GRPCCheck() {
+	logrus.Debugf("GRPC check; Name: %s; Address: %s Interval %d", h.Name, h.Address, h.TimeInterval)
+	timer := time.NewTimer(time.Second * time.Duration(h.TimeInterval))
+	defer timer.Stop()
+	for {
+		HealthMap := h.GetGRPCHealth()
+		result := &v1.HealthStatus{
+			Name:   h.Name,
+			Status: HealthMap["status"],
+			Info:   HealthMap["info"],
+		}
+		h.ResultsChan <- result
+		timer.Reset(time.Second * time.Duration(h.TimeInterval))
+		select {
+		case <-h.Ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// GetGRPCHealth get grpc health
+func (h *GRPCProbe) GetGRPCHealth() map[string]string {
+	if err := GRPCHealthCheck(h.Address, h.Service, h.TLS, time.Duration(h.TimeoutSecond)*time.Second); err != nil {
+		logrus.Debugf("grpc probe check address %s: %v", h.Address, err)
+		return map[string]string{"status": v1.StatUnhealthy, "info": err.Error()}
+	}
+	return map[string]string{"status": v1.StatHealthy, "info": "service health"}
+}
+
+// GRPCHealthCheck checks the service through the grpc.health.v1 health checking protocol.
+// Like the http probe of kubernetes, the certificate of the server is not verified.
+func GRPCHealthCheck(address, service string, useTLS bool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	if useTLS {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	conn, err := grpc.DialContext(ctx, address, opts...)
+	if err != nil {
+		return fmt.Errorf("connect grpc server %s: %v", address, err)
+	}
+	defer conn.Close()
+	res, err := grpchealth.NewHealthClient(conn).Check(ctx, &grpchealth.HealthCheckRequest{Service: service})
+	if err != nil {
+		return fmt.Errorf("check health of grpc service %q: %v", service, err)
+	}
+	if res.GetStatus() != grpchealth.HealthCheckResponse_SERVING {
+		return fmt.Errorf("grpc service %q is %s", service, res.GetStatus())
+	}
+	return nil
+}
@@ -67,6 +67,46 @@ func CreateProbe(ctx context.Context, statusChan chan *v1.HealthStatus, v *v1.Se
 		}
 		return t
 	}
+	if v.ServiceHealth.Model == "grpc" {
+		return &GRPCProbe{
+			Name:          v.ServiceHealth.Name,
+			Address:       v.ServiceHealth.Address,
+			Service:       v.ServiceHealth.Service,
+			TLS:           v.ServiceHealth.TLS,
+			Ctx:           ctx,
+			Cancel:        cancel,
+			ResultsChan:   statusChan,
+			TimeInterval:  interval,
+			MaxErrorsNum:  v.ServiceHealth.MaxErrorsNum,
+			TimeoutSecond: timeoutSecond,
+		}
+	}
+	if v.ServiceHealth.Model == "tls" {
+		return &TLSProbe{
+			Name:                v.ServiceHealth.Name,
+			Address:             v.ServiceHealth.Address,
+			ServerName:          v.ServiceHealth.ServerName,
+			ExpireThresholdDays: v.ServiceHealth.ExpireThresholdDays,
+			Ctx:                 ctx,
+			Cancel:              cancel,
+			ResultsChan:         statusChan,
+			TimeInterval:        interval,
+			MaxErrorsNum:        v.ServiceHealth.MaxErrorsNum,
+			TimeoutSecond:       timeoutSecond,
+		}
+	}
+	if v.ServiceHealth.Model == "cmd" {
+		return &ExecProbe{
+			Name:          v.ServiceHealth.Name,
+			Command:       v.ServiceHealth.Address,
+			Ctx:           ctx,
+			Cancel:        cancel,
+			ResultsChan:   statusChan,
+			TimeInterval:  interval,
+			MaxErrorsNum:  v.ServiceHealth.MaxErrorsNum,
+			TimeoutSecond: timeoutSecond,
+		}
+	}
 	cancel()
 	return nil
 }
@@ -0,0 +1,98 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	grpchealth "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCHealthCheck(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("user", grpchealth.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("order", grpchealth.HealthCheckResponse_NOT_SERVING)
+	grpchealth.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	address := lis.Addr().String()
+	if err := GRPCHealthCheck(address, "", false, time.Second); err != nil {
+		t.Errorf("the server should be healthy: %v", err)
+	}
+	if err := GRPCHealthCheck(address, "user", false, time.Second); err != nil {
+		t.Errorf("the service user should be healthy: %v", err)
+	}
+	if err := GRPCHealthCheck(address, "order", false, time.Second); err == nil {
+		t.Error("the service order should be unhealthy")
+	}
+	if err := GRPCHealthCheck(address, "unknown", false, time.Second); err == nil {
+		t.Error("the unknown service should be unhealthy")
+	}
+}
+
+func TestTLSExpiryCheck(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "https://")
+	cert := server.Certificate()
+
+	notAfter, err := TLSExpiryCheck(address, "example.com", ExpireThreshold(7), time.Second)
+	if err != nil {
+		t.Fatalf("the certificate should not expire soon: %v", err)
+	}
+	if !notAfter.Equal(cert.NotAfter) {
+		t.Errorf("want not after %v, got %v", cert.NotAfter, notAfter)
+	}
+
+	threshold := time.Until(cert.NotAfter) + 24*time.Hour
+	notAfter, err = TLSExpiryCheck(address, "", threshold, time.Second)
+	if err == nil {
+		t.Fatal("the certificate expires within the threshold")
+	}
+	if !notAfter.Equal(cert.NotAfter) {
+		t.Errorf("want not after %v, got %v", cert.NotAfter, notAfter)
+	}
+}
+
+func TestExecHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	if err := ExecHealthCheck(ctx, "test -d /", time.Second); err != nil {
+		t.Errorf("the command should succeed: %v", err)
+	}
+	if err := ExecHealthCheck(ctx, "echo broken >&2; exit 1", time.Second); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("want the stderr in the error, got %v", err)
+	}
+	if err := ExecHealthCheck(ctx, "sleep 3", 100*time.Millisecond); err == nil {
+		t.Error("the command should time out")
+	}
+}
@@ -0,0 +1,136 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	v1 "github.com/goodrain/rainbond/util/prober/types/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultExpireThresholdDays is the default days before the certificate expires that the tls probe fails.
+const DefaultExpireThresholdDays = 7
+
+// TLSProbe checks the expiry of the certificate served on the address
+type TLSProbe struct {
+	Name    string
+	Address string
+	// ServerName is sent in the tls handshake, defaults to the host of the address
+	ServerName          string
+	ExpireThresholdDays int
+	ResultsChan         chan *v1.HealthStatus
+	Ctx                 context.Context
+	Cancel              context.CancelFunc
+	TimeoutSecond       int
+	TimeInterval        int
+	MaxErrorsNum        int
+}
+
+// Check starts tls probe.
+func (h *TLSProbe) Check() {
+	go h.TLSCheck()
+}
+
+// Stop stops tls probe.
+func (h *TLSProbe) Stop() {
+	h.Cancel()
+}
+
+// TLSCheck tls check
+func (h *TLSProbe) TLSCheck() {
+	logrus.Debugf("TLS check; Name: %s; Address: %s Interval %d", h.Name, h.Address, h.TimeInterval)
+	timer := time.NewTimer(time.Second * time.Duration(h.TimeInterval))
+	defer timer.Stop()
+	for {
+		HealthMap := h.GetTLSHealth()
+		result := &v1.HealthStatus{
+			Name:   h.Name,
+			Status: HealthMap["status"],
+			Info:   HealthMap["info"],
+		}
+		h.ResultsChan <- result
+		timer.Reset(time.Second * time.Duration(h.TimeInterval))
+		select {
+		case <-h.Ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// GetTLSHealth get tls health
+func (h *TLSProbe) GetTLSHealth() map[string]string {
+	notAfter, err := TLSExpiryCheck(h.Address, h.ServerName, ExpireThreshold(h.ExpireThresholdDays), time.Duration(h.TimeoutSecond)*time.Second)
+	if err != nil {
+		logrus.Debugf("tls probe check address %s: %v", h.Address, err)
+		if notAfter.IsZero() || notAfter.Before(time.Now()) {
+			return map[string]string{"status": v1.StatDeath, "info": err.Error()}
+		}
+		return map[string]string{"status": v1.StatUnhealthy, "info": err.Error()}
+	}
+	return map[string]string{"status": v1.StatHealthy, "info": fmt.Sprintf("certificate expires at %s", notAfter.Format(time.RFC3339))}
+}
+
+// ExpireThreshold returns the threshold of the days, DefaultExpireThresholdDays if days is not positive.
+func ExpireThreshold(days int) time.Duration {
+	if days <= 0 {
+		days = DefaultExpireThresholdDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// TLSExpiryCheck returns the time the certificate chain served on the address expires, and
+// an error if it expires within the threshold. The time is zero if the certificate can not be got.
+func TLSExpiryCheck(address, serverName string, threshold, timeout time.Duration) (time.Time, error) {
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return time.Time{}, err
+		}
+		serverName = host
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	// the certificate is only inspected, the expired or self signed certificate should not fail the handshake.
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("tls handshake with %s: %v", address, err)
+	}
+	defer conn.Close()
+	var notAfter time.Time
+	for _, cert := range conn.ConnectionState().PeerCertificates {
+		if notAfter.IsZero() || cert.NotAfter.Before(notAfter) {
+			notAfter = cert.NotAfter
+		}
+	}
+	if notAfter.IsZero() {
+		return notAfter, fmt.Errorf("no certificate served on %s", address)
+	}
+	if remain := time.Until(notAfter); remain < threshold {
+		if remain < 0 {
+			return notAfter, fmt.Errorf("certificate of %s expired at %s", serverName, notAfter.Format(time.RFC3339))
+		}
+		return notAfter, fmt.Errorf("certificate of %s expires at %s, within %s", serverName, notAfter.Format(time.RFC3339), threshold)
+	}
+	return notAfter, nil
+}
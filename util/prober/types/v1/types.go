@@ -67,6 +67,14 @@ type Health struct {
 	TimeInterval     int    `json:"time_interval"`
 	MaxErrorsNum     int    `json:"max_errors_num"`
 	MaxTimeoutSecond int    `json:"max_timeout"`
+	// Service is the service name in the grpc health check request
+	Service string `json:"service,omitempty"`
+	// TLS makes the grpc probe connect with tls
+	TLS bool `json:"tls,omitempty"`
+	// ServerName is sent in the tls handshake of the tls probe
+	ServerName string `json:"server_name,omitempty"`
+	// ExpireThresholdDays the tls probe fails if the certificate expires within the days
+	ExpireThresholdDays int `json:"expire_threshold_days,omitempty"`
 }
 
 // Equal check if the left health(l) is equal to the right health(r)
@@ -95,6 +103,12 @@ func (l *Health) Equal(r *Health) bool {
 	if l.MaxErrorsNum != r.MaxErrorsNum {
 		return false
 	}
+	if l.Service != r.Service || l.TLS != r.TLS {
+		return false
+	}
+	if l.ServerName != r.ServerName || l.ExpireThresholdDays != r.ExpireThresholdDays {
+		return false
+	}
 	return true
 }
 
@@ -38,12 +38,15 @@ import (
 	"github.com/barnettZQG/gotty/webtty"
 	httputil "github.com/goodrain/rainbond/util/http"
 	k8sutil "github.com/goodrain/rainbond/util/k8s"
+	"github.com/goodrain/rainbond/util/oidc"
 	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/yudai/umutex"
 	api "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -85,6 +88,9 @@ type Options struct {
 	RawPreferences  map[string]interface{} `hcl:"preferences"`
 	SessionKey      string                 `hcl:"session_key"`
 	K8SConfPath     string
+	// Verifier verifies the id token of the user if set
+	Verifier  *oidc.Verifier
+	ExecRoles []string
 }
 
 //Version -
@@ -111,6 +117,8 @@ type InitMessage struct {
 	ContainerName string `json:"containerName"`
 	Md5           string `json:"Md5"`
 	Namespace     string `json:"namespace"`
+	// Token the oidc id token of the user
+	Token string `json:"token"`
 }
 
 func checkSameOrigin(r *http.Request) bool {
@@ -211,9 +219,6 @@ func (app *App) handleWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	message := string(stream)
-	logrus.Print("message=", message)
-
 	var init InitMessage
 
 	err = json.Unmarshal(stream, &init)
@@ -233,6 +238,16 @@ func (app *App) handleWS(w http.ResponseWriter, r *http.Request) {
 		conn.Close()
 		return
 	}
+	if app.options.Verifier != nil {
+		identity, err := app.authenticate(&init)
+		if err != nil {
+			logrus.Warningf("user is not allowed to exec into %s/%s: %v", init.Namespace, init.PodName, err)
+			conn.WriteMessage(websocket.TextMessage, []byte("Auth is not allowed!"))
+			conn.Close()
+			return
+		}
+		logrus.Infof("user %s exec into %s/%s", identity.Username, init.Namespace, init.PodName)
+	}
 	// base kubernetes api create exec slave
 	if init.Namespace == "" {
 		init.Namespace = init.TenantID
@@ -282,6 +297,56 @@ func (app *App) handleWS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// authenticate verifies the id token carried by the init message, the user
+// must be able to access the tenant and have one of the exec roles if any.
+func (app *App) authenticate(init *InitMessage) (*oidc.Identity, error) {
+	if init.Token == "" {
+		return nil, errors.New("id token is required")
+	}
+	identity, err := app.options.Verifier.Verify(init.Token)
+	if err != nil {
+		return nil, err
+	}
+	pod, err := app.getTenantPod(init)
+	if err != nil {
+		return nil, err
+	}
+	// match the tenant claims on the tenant name, the same way rbd-api does
+	tenantName := pod.Labels["tenant_name"]
+	if tenantName == "" || !identity.CanAccessTenant(tenantName) {
+		return nil, fmt.Errorf("user %s can not access tenant %s", identity.Username, init.TenantID)
+	}
+	if len(app.options.ExecRoles) > 0 && !identity.HasRole(app.options.ExecRoles...) {
+		return nil, fmt.Errorf("user %s does not have any of the roles %v", identity.Username, app.options.ExecRoles)
+	}
+	init.Namespace = pod.Namespace
+	return identity, nil
+}
+
+// getTenantPod looks up the pod of the tenant component on the server side,
+// so the namespace the exec runs in is never taken from the client.
+func (app *App) getTenantPod(init *InitMessage) (*api.Pod, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		"tenant_id":  init.TenantID,
+		"service_id": init.ServiceID,
+	})
+	pods, err := app.coreClient.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", init.PodName).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) != 1 {
+		return nil, fmt.Errorf("pod %s does not belong to tenant %s", init.PodName, init.TenantID)
+	}
+	pod := &pods.Items[0]
+	if init.Namespace != "" && init.Namespace != pod.Namespace {
+		return nil, fmt.Errorf("namespace %s does not match tenant %s", init.Namespace, init.TenantID)
+	}
+	return pod, nil
+}
+
 //Exit -
 func (app *App) Exit() (firstCall bool) {
 	return true
@@ -264,9 +264,14 @@ func (c *Builder) createProbe(componentID string) (*v1alpha1.Probe, error) {
 		SuccessThreshold: int32(probe.SuccessThreshold),
 		FailureThreshold: int32(probe.FailureThreshold),
 	}
-	if probe.Scheme == "tcp" {
+	switch probe.Scheme {
+	case "tcp":
 		p.TCPSocket = c.createTCPGetAction(probe)
-	} else {
+	case "grpc":
+		p.GRPC = &v1alpha1.GRPCAction{Service: probe.GRPCService, TLS: probe.GRPCTLS}
+	case "tls":
+		p.TLSExpiry = &v1alpha1.TLSExpiryAction{ThresholdDays: int32(probe.TLSExpireDays)}
+	default:
 		p.HTTPGet = c.createHTTPGetAction(probe)
 	}
 
@@ -80,6 +80,14 @@ parameter: {
 		}
 		tcpSocket?:{
 		}
+		grpc?: {
+			service?: string
+			tls?: bool
+		}
+		tlsExpiry?: {
+			thresholdDays?: >=0 & <=3650
+			serverName?: string
+		}
 		timeoutSeconds?: >0 & <=65533
 		periodSeconds?: >0 & <=65533
 		successThreshold?: >0 & <=65533
@@ -97,7 +105,7 @@ var thirdComponentDefine = v1alpha1.ComponentDefinition{
 		Name: thirdComponentDefineName,
 		Annotations: map[string]string{
 			"definition.oam.dev/description": "Rainbond built-in component type that defines third-party service components.",
-			"version":                        "0.2",
+			"version":                        "0.3",
 		},
 	},
 	Spec: v1alpha1.ComponentDefinitionSpec{
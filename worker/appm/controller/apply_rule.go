@@ -45,6 +45,9 @@ func (a *applyRuleController) Begin() {
 			if err := f.ApplyOne(a.ctx, a.manager.apply, a.manager.client, &service); err != nil {
 				logrus.Errorf("apply rules for service %s failure: %s", service.ServiceAlias, err.Error())
 			}
+			if err := f.PruneMeshPolicies(a.ctx, a.manager.runtimeClient, &service); err != nil {
+				logrus.Warningf("prune mesh policies of service %s failure: %s", service.ServiceAlias, err.Error())
+			}
 		}(service)
 	}
 	wait.Wait()
@@ -28,6 +28,7 @@ import (
 
 	"github.com/goodrain/rainbond/event"
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/worker/appm/f"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -112,6 +113,9 @@ func (s *startController) startOne(app v1.AppService) error {
 			}
 		}
 	}
+	if err := f.PruneMeshPolicies(s.ctx, s.manager.runtimeClient, &app); err != nil {
+		logrus.Warningf("prune mesh policies of %s failure %s", app.ServiceAlias, err.Error())
+	}
 	// for core component
 	//step 1: create configmap
 	if configs := app.GetConfigMaps(); configs != nil {
@@ -143,6 +147,12 @@ func (s *startController) startOne(app v1.AppService) error {
 			return fmt.Errorf("create deployment failure:%s;", err.Error())
 		}
 	}
+	if daemonset := app.GetDaemonSet(); daemonset != nil {
+		_, err = s.manager.client.AppsV1().DaemonSets(app.GetNamespace()).Create(s.ctx, daemonset, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("create daemonset failure:%s;", err.Error())
+		}
+	}
 	if vm := app.GetVirtualMachine(); vm != nil {
 		_, err = s.manager.kubevirtCli.VirtualMachine(app.GetNamespace()).Create(s.ctx, vm)
 		if err != nil {
@@ -226,6 +236,12 @@ func (s *startController) startOne(app v1.AppService) error {
 			}
 		}
 	}
+	if pdb := app.GetPodDisruptionBudget(); pdb != nil {
+		if err := f.EnsurePodDisruptionBudget(s.manager.client, pdb.Namespace, pdb.Name, pdb); err != nil {
+			// the budget only protects the pods during the node drains, do not fail the start
+			logrus.Warningf("start service %s: %v", app.ServiceAlias, err)
+		}
+	}
 	//step 7: create CR resource
 	if crd, _ := s.manager.store.GetCrd(store.ServiceMonitor); crd != nil {
 		if sms := app.GetServiceMonitors(true); len(sms) > 0 {
@@ -137,6 +137,9 @@ func printLogger(a *v1.AppService, logger event.Logger) {
 	if a.GetDeployment() != nil {
 		ready = a.GetDeployment().Status.ReadyReplicas
 	}
+	if a.GetDaemonSet() != nil {
+		ready = a.GetDaemonSet().Status.NumberReady
+	}
 	logger.Info(fmt.Sprintf("current instance(count:%d ready:%d notready:%d)", len(a.GetPods(false)), ready, int32(len(a.GetPods(false)))-ready), map[string]string{"step": "appruntime", "status": "running"})
 	pods := a.GetPods(false)
 	for _, pod := range pods {
@@ -28,6 +28,7 @@ import (
 
 	"github.com/goodrain/rainbond/event"
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/worker/appm/f"
 	"github.com/goodrain/rainbond/worker/appm/store"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
 	"github.com/sirupsen/logrus"
@@ -175,6 +176,13 @@ func (s *stopController) stopOne(app v1.AppService) error {
 		}
 		s.manager.store.OnDeletes(deployment)
 	}
+	if daemonset := app.GetDaemonSet(); daemonset != nil && daemonset.Name != "" {
+		err := s.manager.client.AppsV1().DaemonSets(app.GetNamespace()).Delete(s.ctx, daemonset.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete daemonset failure:%s", err.Error())
+		}
+		s.manager.store.OnDeletes(daemonset)
+	}
 	if job := app.GetJob(); job != nil {
 		err := s.manager.client.BatchV1().Jobs(app.GetNamespace()).Delete(s.ctx, job.Name, metav1.DeleteOptions{})
 		if err != nil && !errors.IsNotFound(err) {
@@ -229,6 +237,12 @@ func (s *stopController) stopOne(app v1.AppService) error {
 			}
 		}
 	}
+	if app.GetDeployment() != nil || app.GetStatefulSet() != nil {
+		if err := f.EnsurePodDisruptionBudget(s.manager.client, app.GetNamespace(), app.GetK8sWorkloadName(), nil); err != nil {
+			// a budget left behind has no pods to protect, do not fail the stop
+			logrus.Warningf("stop service %s: %v", app.ServiceAlias, err)
+		}
+	}
 	//step 8: delete CR resource
 	if crd, _ := s.manager.store.GetCrd(store.ServiceMonitor); crd != nil {
 		if sms := app.GetServiceMonitors(true); len(sms) > 0 {
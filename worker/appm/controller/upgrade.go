@@ -165,6 +165,9 @@ func (s *upgradeController) upgradeOne(app v1.AppService) error {
 			}
 		}
 	}
+	if err := f.PruneMeshPolicies(s.ctx, s.manager.runtimeClient, &app); err != nil {
+		logrus.Warningf("prune mesh policies of %s failure %s", app.ServiceAlias, err.Error())
+	}
 	s.upgradeConfigMap(app)
 	if deployment := app.GetDeployment(); deployment != nil {
 		_, err = s.manager.client.AppsV1().Deployments(deployment.Namespace).Patch(s.ctx, deployment.Name, types.MergePatchType, app.UpgradePatch["deployment"], metav1.PatchOptions{})
@@ -173,6 +176,13 @@ func (s *upgradeController) upgradeOne(app v1.AppService) error {
 			return fmt.Errorf("upgrade deployment %s failure %s", app.ServiceAlias, err.Error())
 		}
 	}
+	if daemonset := app.GetDaemonSet(); daemonset != nil {
+		_, err = s.manager.client.AppsV1().DaemonSets(daemonset.Namespace).Patch(s.ctx, daemonset.Name, types.MergePatchType, app.UpgradePatch["daemonset"], metav1.PatchOptions{})
+		if err != nil {
+			app.Logger.Error(fmt.Sprintf("upgrade daemonset %s failure %s", app.ServiceAlias, err.Error()), event.GetLoggerOption("failure"))
+			return fmt.Errorf("upgrade daemonset %s failure %s", app.ServiceAlias, err.Error())
+		}
+	}
 
 	// create claims
 	for _, claim := range app.GetClaimsManually() {
@@ -192,6 +202,12 @@ func (s *upgradeController) upgradeOne(app v1.AppService) error {
 		}
 	}
 
+	if app.GetDeployment() != nil || app.GetStatefulSet() != nil {
+		if err := f.EnsurePodDisruptionBudget(s.manager.client, app.GetNamespace(), app.GetK8sWorkloadName(), app.GetPodDisruptionBudget()); err != nil {
+			logrus.Warningf("upgrade service %s: %v", app.ServiceAlias, err)
+		}
+	}
+
 	oldApp := s.manager.store.GetAppService(app.ServiceID)
 	s.upgradeService(app)
 	handleErr := func(msg string, err error) error {
@@ -44,6 +44,10 @@ var str2ResourceName = map[string]corev1.ResourceName{
 
 // TenantServiceAutoscaler -
 func TenantServiceAutoscaler(as *v1.AppService, dbmanager db.Manager) error {
+	if as.GetDaemonSet() != nil {
+		// a daemonset runs one pod on every selected node, it can not be scaled
+		return nil
+	}
 	if k8sutil.GetKubeVersion().AtLeast(utilversion.MustParseSemantic("v1.23.0")) {
 		hpas, err := newHPAs(as, dbmanager)
 		if err != nil {
@@ -127,6 +131,66 @@ func createResourceMetricsBeta2(metric *model.TenantServiceAutoscalerRuleMetrics
 	return ms
 }
 
+func createPodsMetricsBeta2(metric *model.TenantServiceAutoscalerRuleMetrics) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.PodsMetricSourceType,
+		Pods: &autoscalingv2beta2.PodsMetricSource{
+			Metric: autoscalingv2beta2.MetricIdentifier{
+				Name:     metric.MetricsName,
+				Selector: metricSelector(metric),
+			},
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:         autoscalingv2beta2.AverageValueMetricType,
+				AverageValue: metricQuantity(metric),
+			},
+		},
+	}
+}
+
+func createObjectMetricsBeta2(metric *model.TenantServiceAutoscalerRuleMetrics) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ObjectMetricSourceType,
+		Object: &autoscalingv2beta2.ObjectMetricSource{
+			DescribedObject: autoscalingv2beta2.CrossVersionObjectReference{
+				Kind:       metric.DescribedObjectKind,
+				Name:       metric.DescribedObjectName,
+				APIVersion: describedObjectAPIVersion(metric.DescribedObjectKind),
+			},
+			Metric: autoscalingv2beta2.MetricIdentifier{
+				Name:     metric.MetricsName,
+				Selector: metricSelector(metric),
+			},
+			Target: metricTargetBeta2(metric),
+		},
+	}
+}
+
+func createExternalMetricsBeta2(metric *model.TenantServiceAutoscalerRuleMetrics) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ExternalMetricSourceType,
+		External: &autoscalingv2beta2.ExternalMetricSource{
+			Metric: autoscalingv2beta2.MetricIdentifier{
+				Name:     metric.MetricsName,
+				Selector: metricSelector(metric),
+			},
+			Target: metricTargetBeta2(metric),
+		},
+	}
+}
+
+func metricTargetBeta2(metric *model.TenantServiceAutoscalerRuleMetrics) autoscalingv2beta2.MetricTarget {
+	if metric.MetricTargetType == model.AverageValueMetricTarget {
+		return autoscalingv2beta2.MetricTarget{
+			Type:         autoscalingv2beta2.AverageValueMetricType,
+			AverageValue: metricQuantity(metric),
+		}
+	}
+	return autoscalingv2beta2.MetricTarget{
+		Type:  autoscalingv2beta2.ValueMetricType,
+		Value: metricQuantity(metric),
+	}
+}
+
 func newHPABeta2(namespace, kind, name string, labels map[string]string, rule *model.TenantServiceAutoscalerRules, metrics []*model.TenantServiceAutoscalerRuleMetrics) *autoscalingv2beta2.HorizontalPodAutoscaler {
 	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
@@ -147,17 +211,25 @@ func newHPABeta2(namespace, kind, name string, labels map[string]string, rule *m
 	}
 
 	for _, metric := range metrics {
-		if metric.MetricsType != "resource_metrics" {
-			logrus.Warningf("rule id:  %s; unsupported metric type: %s", rule.RuleID, metric.MetricsType)
-			continue
-		}
 		if metric.MetricTargetValue <= 0 {
-			// TODO: If the target value of cpu and memory is 0, it will not take effect.
-			// TODO: The target value of the custom indicator can be 0.
+			// the target value of hpa metrics must be positive.
 			continue
 		}
 
-		ms := createResourceMetricsBeta2(metric)
+		var ms autoscalingv2beta2.MetricSpec
+		switch metric.MetricsType {
+		case model.ResourceMetricsType:
+			ms = createResourceMetricsBeta2(metric)
+		case model.PodsMetricsType:
+			ms = createPodsMetricsBeta2(metric)
+		case model.ObjectMetricsType:
+			ms = createObjectMetricsBeta2(metric)
+		case model.ExternalMetricsType:
+			ms = createExternalMetricsBeta2(metric)
+		default:
+			logrus.Warningf("rule id:  %s; unsupported metric type: %s", rule.RuleID, metric.MetricsType)
+			continue
+		}
 		spec.Metrics = append(spec.Metrics, ms)
 	}
 	if len(spec.Metrics) == 0 {
@@ -229,6 +301,66 @@ func createResourceMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) aut
 	return ms
 }
 
+func createPodsMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{
+				Name:     metric.MetricsName,
+				Selector: metricSelector(metric),
+			},
+			Target: autoscalingv2.MetricTarget{
+				Type:         autoscalingv2.AverageValueMetricType,
+				AverageValue: metricQuantity(metric),
+			},
+		},
+	}
+}
+
+func createObjectMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ObjectMetricSourceType,
+		Object: &autoscalingv2.ObjectMetricSource{
+			DescribedObject: autoscalingv2.CrossVersionObjectReference{
+				Kind:       metric.DescribedObjectKind,
+				Name:       metric.DescribedObjectName,
+				APIVersion: describedObjectAPIVersion(metric.DescribedObjectKind),
+			},
+			Metric: autoscalingv2.MetricIdentifier{
+				Name:     metric.MetricsName,
+				Selector: metricSelector(metric),
+			},
+			Target: metricTarget(metric),
+		},
+	}
+}
+
+func createExternalMetrics(metric *model.TenantServiceAutoscalerRuleMetrics) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{
+				Name:     metric.MetricsName,
+				Selector: metricSelector(metric),
+			},
+			Target: metricTarget(metric),
+		},
+	}
+}
+
+func metricTarget(metric *model.TenantServiceAutoscalerRuleMetrics) autoscalingv2.MetricTarget {
+	if metric.MetricTargetType == model.AverageValueMetricTarget {
+		return autoscalingv2.MetricTarget{
+			Type:         autoscalingv2.AverageValueMetricType,
+			AverageValue: metricQuantity(metric),
+		}
+	}
+	return autoscalingv2.MetricTarget{
+		Type:  autoscalingv2.ValueMetricType,
+		Value: metricQuantity(metric),
+	}
+}
+
 func newHPA(namespace, kind, name string, labels map[string]string, rule *model.TenantServiceAutoscalerRules, metrics []*model.TenantServiceAutoscalerRuleMetrics) *autoscalingv2.HorizontalPodAutoscaler {
 	hpa := &autoscalingv2.HorizontalPodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
@@ -249,17 +381,25 @@ func newHPA(namespace, kind, name string, labels map[string]string, rule *model.
 	}
 
 	for _, metric := range metrics {
-		if metric.MetricsType != "resource_metrics" {
-			logrus.Warningf("rule id:  %s; unsupported metric type: %s", rule.RuleID, metric.MetricsType)
-			continue
-		}
 		if metric.MetricTargetValue <= 0 {
-			// TODO: If the target value of cpu and memory is 0, it will not take effect.
-			// TODO: The target value of the custom indicator can be 0.
+			// the target value of hpa metrics must be positive.
 			continue
 		}
 
-		ms := createResourceMetrics(metric)
+		var ms autoscalingv2.MetricSpec
+		switch metric.MetricsType {
+		case model.ResourceMetricsType:
+			ms = createResourceMetrics(metric)
+		case model.PodsMetricsType:
+			ms = createPodsMetrics(metric)
+		case model.ObjectMetricsType:
+			ms = createObjectMetrics(metric)
+		case model.ExternalMetricsType:
+			ms = createExternalMetrics(metric)
+		default:
+			logrus.Warningf("rule id:  %s; unsupported metric type: %s", rule.RuleID, metric.MetricsType)
+			continue
+		}
 		spec.Metrics = append(spec.Metrics, ms)
 	}
 	if len(spec.Metrics) == 0 {
@@ -269,3 +409,31 @@ func newHPA(namespace, kind, name string, labels map[string]string, rule *model.
 
 	return hpa
 }
+
+func metricSelector(metric *model.TenantServiceAutoscalerRuleMetrics) *metav1.LabelSelector {
+	if metric.MetricSelector == "" {
+		return nil
+	}
+	selector, err := metav1.ParseToLabelSelector(metric.MetricSelector)
+	if err != nil {
+		logrus.Warningf("rule id: %s; parse metric selector %s: %v", metric.RuleID, metric.MetricSelector, err)
+		return nil
+	}
+	return selector
+}
+
+func metricQuantity(metric *model.TenantServiceAutoscalerRuleMetrics) *resource.Quantity {
+	return resource.NewQuantity(int64(metric.MetricTargetValue), resource.DecimalSI)
+}
+
+// describedObjectAPIVersion returns the api version of the object described by object metrics
+func describedObjectAPIVersion(kind string) string {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		return "apps/v1"
+	case "Ingress":
+		return "networking.k8s.io/v1"
+	default:
+		return "v1"
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/goodrain/rainbond/db/model"
 	k8sutil "github.com/goodrain/rainbond/util/k8s"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -58,3 +59,60 @@ func TestNewHPA(t *testing.T) {
 		t.Fatalf("create hpa: %v", err)
 	}
 }
+
+func TestNewHPACustomMetrics(t *testing.T) {
+	rule := &model.TenantServiceAutoscalerRules{
+		RuleID:      "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+		MinReplicas: 1,
+		MaxReplicas: 10,
+	}
+	metrics := []*model.TenantServiceAutoscalerRuleMetrics{
+		{
+			MetricsType:       model.PodsMetricsType,
+			MetricsName:       "http_requests_per_second",
+			MetricTargetType:  model.AverageValueMetricTarget,
+			MetricTargetValue: 100,
+		},
+		{
+			MetricsType:         model.ObjectMetricsType,
+			MetricsName:         "gateway_requests_per_second",
+			MetricTargetType:    model.ValueMetricTarget,
+			MetricTargetValue:   2000,
+			DescribedObjectKind: "Service",
+			DescribedObjectName: "gr3a4b5c",
+		},
+		{
+			MetricsType:       model.ExternalMetricsType,
+			MetricsName:       "rabbitmq_queue_messages",
+			MetricSelector:    "queue=orders",
+			MetricTargetType:  model.AverageValueMetricTarget,
+			MetricTargetValue: 30,
+		},
+		{
+			MetricsType:       "unknown",
+			MetricsName:       "foo",
+			MetricTargetValue: 1,
+		},
+	}
+	hpa := newHPA("ns", "Deployment", "gr3a4b5c", nil, rule, metrics)
+	if hpa == nil || len(hpa.Spec.Metrics) != 3 {
+		t.Fatalf("want 3 metrics, got %#v", hpa)
+	}
+	pods := hpa.Spec.Metrics[0].Pods
+	if pods == nil || pods.Target.AverageValue.Value() != 100 {
+		t.Errorf("unexpected pods metric %#v", hpa.Spec.Metrics[0])
+	}
+	object := hpa.Spec.Metrics[1].Object
+	if object == nil || object.DescribedObject.APIVersion != "v1" || object.Target.Type != autoscalingv2.ValueMetricType || object.Target.Value.Value() != 2000 {
+		t.Errorf("unexpected object metric %#v", hpa.Spec.Metrics[1])
+	}
+	external := hpa.Spec.Metrics[2].External
+	if external == nil || external.Metric.Selector.MatchLabels["queue"] != "orders" || external.Target.Type != autoscalingv2.AverageValueMetricType {
+		t.Errorf("unexpected external metric %#v", hpa.Spec.Metrics[2])
+	}
+
+	beta2 := newHPABeta2("ns", "Deployment", "gr3a4b5c", nil, rule, metrics)
+	if beta2 == nil || len(beta2.Spec.Metrics) != 3 {
+		t.Fatalf("want 3 beta2 metrics, got %#v", beta2)
+	}
+}
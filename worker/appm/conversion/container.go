@@ -0,0 +1,87 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/model"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// TenantServiceContainers adds the init and sidecar containers defined on the component,
+// which do not require authoring a plugin.
+func TenantServiceContainers(as *v1.AppService, dbmanager db.Manager) error {
+	if as.GetVirtualMachine() != nil {
+		return nil
+	}
+	podtemplate := as.GetPodTemplate()
+	if podtemplate == nil {
+		return fmt.Errorf("pod templete is nil before define containers")
+	}
+	initContainers, err := getAttributeContainers(as, dbmanager, model.K8sAttributeNameInitContainers)
+	if err != nil {
+		return fmt.Errorf("get init containers: %v", err)
+	}
+	sidecars, err := getAttributeContainers(as, dbmanager, model.K8sAttributeNameSidecarContainers)
+	if err != nil {
+		return fmt.Errorf("get sidecar containers: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, c := range append(podtemplate.Spec.InitContainers, podtemplate.Spec.Containers...) {
+		names[c.Name] = true
+	}
+	for _, c := range append(initContainers, sidecars...) {
+		if names[c.Name] {
+			return fmt.Errorf("container name %s of component %s is duplicated", c.Name, as.ServiceAlias)
+		}
+		names[c.Name] = true
+	}
+	podtemplate.Spec.InitContainers = append(podtemplate.Spec.InitContainers, initContainers...)
+	podtemplate.Spec.Containers = append(podtemplate.Spec.Containers, sidecars...)
+	return nil
+}
+
+func getAttributeContainers(as *v1.AppService, dbmanager db.Manager, name string) ([]corev1.Container, error) {
+	attr, err := dbmanager.ComponentK8sAttributeDao().GetByComponentIDAndName(as.ServiceID, name)
+	if err != nil {
+		return nil, err
+	}
+	if attr == nil || attr.AttributeValue == "" {
+		return nil, nil
+	}
+	containersJSON, err := yaml.YAMLToJSON([]byte(attr.AttributeValue))
+	if err != nil {
+		return nil, err
+	}
+	var containers []corev1.Container
+	if err := json.Unmarshal(containersJSON, &containers); err != nil {
+		return nil, err
+	}
+	for i := range containers {
+		if containers[i].ImagePullPolicy == "" {
+			containers[i].ImagePullPolicy = corev1.PullIfNotPresent
+		}
+	}
+	return containers, nil
+}
@@ -36,12 +36,20 @@ func init() {
 	RegistConversion("TenantServiceConfigGroup", TenantServiceConfigGroup)
 	//step1 conv service pod base info
 	RegistConversion("TenantServiceVersion", TenantServiceVersion)
+	//conv the env vars and config files resolved from the external secret store
+	RegistConversion("TenantServiceExternalSecret", TenantServiceExternalSecret)
 	//step2 conv service plugin
 	RegistConversion("TenantServicePlugin", TenantServicePlugin)
+	//conv the init and sidecar containers defined on the component
+	RegistConversion("TenantServiceContainers", TenantServiceContainers)
 	//step3 -
 	RegistConversion("TenantServiceAutoscaler", TenantServiceAutoscaler)
+	//conv the pod disruption budget
+	RegistConversion("TenantServicePodDisruptionBudget", TenantServicePodDisruptionBudget)
 	//step4 conv service monitor
 	RegistConversion("TenantServiceMonitor", TenantServiceMonitor)
+	//step5 conv service mesh identity and authorization policies
+	RegistConversion("TenantServiceMesh", TenantServiceMesh)
 }
 
 //Conversion conversion function
@@ -86,6 +94,7 @@ func InitAppService(sharedStorageClass string, dryRun bool, dbmanager db.Manager
 	}
 	if app != nil {
 		appService.AppServiceBase.GovernanceMode = app.GovernanceMode
+		appService.AppServiceBase.MeshMTLSMode = app.MeshMTLSMode
 		appService.AppServiceBase.K8sApp = app.K8sApp
 	}
 	if dryRun {
@@ -145,6 +154,7 @@ func InitCacheAppService(dbm db.Manager, serviceID, creatorID string) (*v1.AppSe
 	}
 	if app != nil {
 		appService.AppServiceBase.GovernanceMode = app.GovernanceMode
+		appService.AppServiceBase.MeshMTLSMode = app.MeshMTLSMode
 		appService.AppServiceBase.K8sApp = app.K8sApp
 	}
 
@@ -0,0 +1,91 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/model"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// TenantServicePodDisruptionBudget creates the pod disruption budget protecting the pods of the component
+// during the node drains. A stateless_multiple component allows one unavailable pod by default, which never
+// blocks the drains of a single replica, an attribute without minAvailable and maxUnavailable disables the budget.
+func TenantServicePodDisruptionBudget(as *v1.AppService, dbmanager db.Manager) error {
+	var selector *metav1.LabelSelector
+	if deployment := as.GetDeployment(); deployment != nil {
+		selector = deployment.Spec.Selector
+	} else if statefulset := as.GetStatefulSet(); statefulset != nil {
+		selector = statefulset.Spec.Selector
+	}
+	if selector == nil {
+		return nil
+	}
+	spec, err := createPodDisruptionBudgetSpec(as, dbmanager)
+	if err != nil {
+		return fmt.Errorf("create pod disruption budget spec: %v", err)
+	}
+	if spec == nil {
+		as.SetPodDisruptionBudget(nil)
+		return nil
+	}
+	spec.Selector = selector.DeepCopy()
+	as.SetPodDisruptionBudget(&policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      as.GetK8sWorkloadName(),
+			Namespace: as.GetNamespace(),
+			Labels:    as.GetCommonLabels(),
+		},
+		Spec: *spec,
+	})
+	return nil
+}
+
+func createPodDisruptionBudgetSpec(as *v1.AppService, dbmanager db.Manager) (*policyv1.PodDisruptionBudgetSpec, error) {
+	attr, err := dbmanager.ComponentK8sAttributeDao().GetByComponentIDAndName(as.ServiceID, model.K8sAttributeNamePodDisruptionBudget)
+	if err != nil {
+		return nil, err
+	}
+	if attr == nil {
+		if model.ServiceType(as.ExtendMethod) != model.ServiceTypeStatelessMultiple {
+			return nil, nil
+		}
+		maxUnavailable := intstr.FromInt(1)
+		return &policyv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable}, nil
+	}
+	specJSON, err := yaml.YAMLToJSON([]byte(attr.AttributeValue))
+	if err != nil {
+		return nil, err
+	}
+	var spec policyv1.PodDisruptionBudgetSpec
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		return nil, err
+	}
+	if spec.MinAvailable == nil && spec.MaxUnavailable == nil {
+		return nil, nil
+	}
+	return &spec, nil
+}
@@ -0,0 +1,134 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package conversion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/worker/appm/secretstore"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TenantServiceExternalSecret resolves the env vars and config files referencing the external secret store
+// into a secret of the component. The pod template is annotated with the checksum of the values,
+// so that the pods are restarted once the values change.
+func TenantServiceExternalSecret(as *v1.AppService, dbmanager db.Manager) error {
+	podtemplate := as.GetPodTemplate()
+	if podtemplate == nil {
+		return nil
+	}
+	refs, err := listExternalSecretRefs(as, dbmanager)
+	if err != nil {
+		return fmt.Errorf("list external secret refs: %v", err)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	store, err := secretstore.Default()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	data, err := secretstore.Resolve(ctx, store, as.TenantID, refs)
+	if err != nil {
+		return fmt.Errorf("resolve external secrets: %v", err)
+	}
+	refsJSON, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	annotations := map[string]string{secretstore.RefsAnnotation: string(refsJSON)}
+	if kind := externalSecretWorkloadKind(as); kind != "" {
+		annotations[secretstore.WorkloadAnnotation] = kind + "/" + as.GetK8sWorkloadName()
+	}
+	as.SetSecret(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secretstore.SecretName(as.GetK8sWorkloadName()),
+			Namespace:   as.GetNamespace(),
+			Labels:      as.GetCommonLabels(map[string]string{secretstore.ExternalSecretLabel: "true"}),
+			Annotations: annotations,
+		},
+		Data: data,
+		Type: corev1.SecretTypeOpaque,
+	})
+	if podtemplate.Annotations == nil {
+		podtemplate.Annotations = make(map[string]string)
+	}
+	podtemplate.Annotations[secretstore.ChecksumAnnotation] = secretstore.Checksum(data)
+	return nil
+}
+
+// listExternalSecretRefs lists the env vars and config files of the component referencing the external secret store.
+// The env vars of the dependencies are not shared with the component.
+func listExternalSecretRefs(as *v1.AppService, dbmanager db.Manager) ([]*secretstore.Ref, error) {
+	envs, err := dbmanager.TenantServiceEnvVarDao().GetServiceEnvs(as.ServiceID, []string{"inner", "both", "outer"})
+	if err != nil {
+		return nil, err
+	}
+	var refs []*secretstore.Ref
+	for _, env := range envs {
+		if !env.IsExternalSecret() {
+			continue
+		}
+		refs = append(refs, &secretstore.Ref{
+			DataKey: strings.TrimSpace(env.AttrName),
+			Path:    env.SecretPath,
+			Key:     env.SecretKey,
+			Version: env.SecretVersion,
+			Restart: env.RestartOnRotation,
+		})
+	}
+	configFiles, err := dbmanager.TenantServiceConfigFileDao().GetConfigFileByServiceID(as.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, cf := range configFiles {
+		if !cf.IsExternalSecret() {
+			continue
+		}
+		refs = append(refs, &secretstore.Ref{
+			DataKey: secretstore.FileKey(cf.VolumeName),
+			Path:    cf.SecretPath,
+			Key:     cf.SecretKey,
+			Version: cf.SecretVersion,
+			// the file is mounted with a sub path, which never receives the updates of the secret
+			Restart: true,
+		})
+	}
+	return refs, nil
+}
+
+func externalSecretWorkloadKind(as *v1.AppService) string {
+	switch {
+	case as.GetDeployment() != nil:
+		return "Deployment"
+	case as.GetStatefulSet() != nil:
+		return "StatefulSet"
+	case as.GetDaemonSet() != nil:
+		return "DaemonSet"
+	}
+	return ""
+}
@@ -27,8 +27,11 @@ import (
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/db/model"
 	"github.com/goodrain/rainbond/gateway/annotations/parser"
+	"github.com/goodrain/rainbond/gateway/annotations/scaletozero"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 	"github.com/goodrain/rainbond/util/k8s"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -173,7 +176,12 @@ func (a *AppServiceBuild) Build() (*v1.K8sResources, error) {
 		}
 	}
 	if len(innerService) > 0 {
-		services = append(services, a.createInnerService(innerService))
+		service := a.createInnerService(innerService)
+		// the inbound sidecar of the component accepts mtls, so its clients originate it
+		if crt && a.appService.GovernanceMode == model.GovernanceModeBuildInServiceMesh && envoyv3.MTLSEnabled(a.appService.MeshMTLSMode) {
+			service.Labels[envoyv3.MeshMTLSServiceLabel] = "true"
+		}
+		services = append(services, service)
 	}
 	// build stateful service
 	if a.replicationType == model.TypeStatefulSet {
@@ -566,6 +574,15 @@ func (a *AppServiceBuild) parseAnnotations(rule *model.HTTPRule) (map[string]str
 		}
 	}
 
+	// scale-to-zero, the gateway holds the requests and wakes up the component
+	stz, err := a.dbmanager.TenantServiceScaleToZeroDao().GetByServiceID(a.serviceID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	if stz != nil && stz.Enable {
+		annos[parser.GetAnnotationWithPrefix(scaletozero.Annotation)] = "true"
+	}
+
 	configs, err := db.GetManager().GwRuleConfigDao().ListByRuleID(rule.UUID)
 	if err != nil {
 		return nil, err
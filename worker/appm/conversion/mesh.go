@@ -0,0 +1,144 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package conversion
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goodrain/rainbond/api/handler/app_governance_mode/adaptor"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/util/commonutil"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TenantServiceMesh sets up the mesh sidecar injection and the workload identity of the component,
+// and translates its dependencies into the authorization policies of the mesh.
+func TenantServiceMesh(as *v1.AppService, dbmanager db.Manager) error {
+	mode, err := adaptor.NewAppGoveranceModeHandler(as.GovernanceMode, nil)
+	if err != nil {
+		return nil
+	}
+	podTemplate := as.GetPodTemplate()
+	if podTemplate == nil {
+		return nil
+	}
+	component, err := createMeshComponent(as, dbmanager)
+	if err != nil {
+		return fmt.Errorf("create mesh component: %v", err)
+	}
+	if annotations := mode.GetInjectAnnotations(component); len(annotations) > 0 {
+		if podTemplate.Annotations == nil {
+			podTemplate.Annotations = make(map[string]string)
+		}
+		for k, v := range annotations {
+			podTemplate.Annotations[k] = v
+		}
+	}
+	policyHandler, ok := mode.(adaptor.AuthorizationPolicyHandler)
+	if !ok {
+		return nil
+	}
+	manifests := as.GetManifests()
+	// the mesh identity of a component is its service account,
+	// so every component without a custom service account gets its own.
+	if podTemplate.Spec.ServiceAccountName == "" {
+		podTemplate.Spec.ServiceAccountName = component.ServiceAccount
+		sa := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ServiceAccount",
+		}}
+		sa.SetName(component.ServiceAccount)
+		manifests = append(manifests, sa)
+	}
+	manifests = append(manifests, policyHandler.GetAuthorizationPolicies(component)...)
+	as.SetMeshPolicyKinds(policyHandler.GetAuthorizationPolicyKinds())
+	for _, manifest := range manifests {
+		manifest.SetNamespace(as.GetNamespace())
+		manifest.SetLabels(as.GetCommonLabels(manifest.GetLabels()))
+	}
+	as.SetManifests(manifests)
+	return nil
+}
+
+func createMeshComponent(as *v1.AppService, dbmanager db.Manager) (*adaptor.MeshComponent, error) {
+	sa, err := createServiceAccountName(as, dbmanager)
+	if err != nil {
+		return nil, err
+	}
+	if sa == "" {
+		sa = as.GetK8sWorkloadName()
+	}
+	component := &adaptor.MeshComponent{
+		Name:           as.GetK8sWorkloadName(),
+		Namespace:      as.GetNamespace(),
+		ServiceAccount: sa,
+		Selector:       map[string]string{"service_id": as.ServiceID},
+	}
+	ports, err := dbmanager.TenantServicesPortDao().GetPortsByServiceID(as.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range ports {
+		component.Ports = append(component.Ports, adaptor.MeshPort{
+			Port:           port.ContainerPort,
+			IsOuterService: commonutil.BoolValue(port.IsOuterService),
+		})
+	}
+	sort.Slice(component.Ports, func(i, j int) bool {
+		return component.Ports[i].Port < component.Ports[j].Port
+	})
+
+	relations, err := dbmanager.TenantServiceRelationDao().GetTenantServiceRelationsByDependServiceID(as.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	if len(relations) == 0 {
+		return component, nil
+	}
+	var clientIDs []string
+	for _, r := range relations {
+		clientIDs = append(clientIDs, r.ServiceID)
+	}
+	workloads, err := dbmanager.TenantServiceDao().GetWorkloadNameByIDs(clientIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, workload := range workloads {
+		client := &adaptor.MeshComponent{
+			Name:           fmt.Sprintf("%s-%s", workload.K8sApp, workload.K8sComponentName),
+			Namespace:      as.GetNamespace(),
+			ServiceAccount: fmt.Sprintf("%s-%s", workload.K8sApp, workload.K8sComponentName),
+		}
+		attr, err := dbmanager.ComponentK8sAttributeDao().GetByComponentIDAndName(workload.ComponentID, model.K8sAttributeNameServiceAccountName)
+		if err != nil {
+			return nil, err
+		}
+		if attr != nil && attr.AttributeValue != "" {
+			client.ServiceAccount = attr.AttributeValue
+		}
+		component.Clients = append(component.Clients, client)
+	}
+	sort.Slice(component.Clients, func(i, j int) bool {
+		return component.Clients[i].Name < component.Clients[j].Name
+	})
+	return component, nil
+}
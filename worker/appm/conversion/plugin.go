@@ -36,6 +36,7 @@ import (
 	api_model "github.com/goodrain/rainbond/api/model"
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/db/model"
+	envoyv3 "github.com/goodrain/rainbond/node/core/envoy/v3"
 	"github.com/goodrain/rainbond/util"
 	typesv1 "github.com/goodrain/rainbond/worker/appm/types/v1"
 )
@@ -214,7 +215,7 @@ func conversionServicePlugin(as *typesv1.AppService, dbmanager db.Manager) ([]v1
 	if as.NeedProxy && !netPlugin {
 		pluginID, pluginConfig, err := applyDefaultMeshPluginConfig(as, dbmanager)
 		if err != nil {
-			logrus.Errorf("apply default mesh plugin config failure %s", err.Error())
+			return nil, nil, nil, fmt.Errorf("apply default mesh plugin config failure %s", err.Error())
 		}
 		defaultSidecarContainer := createTCPDefaultPluginContainer(as, pluginID, mainContainer.Env, pluginConfig)
 		precontainers = append(precontainers, defaultSidecarContainer)
@@ -372,6 +373,32 @@ func ApplyPluginConfig(as *typesv1.AppService, servicePluginRelation *model.Tena
 	}
 }
 
+// meshMTLSAnnotations tells the discover server how the sidecar uses mtls.
+// in the STRICT mode, the ports open to the gateway still accept plaintext.
+func meshMTLSAnnotations(as *typesv1.AppService, dbmanager db.Manager) (map[string]string, error) {
+	if as.GovernanceMode != model.GovernanceModeBuildInServiceMesh || !envoyv3.MTLSEnabled(as.MeshMTLSMode) {
+		return nil, nil
+	}
+	annotations := map[string]string{envoyv3.MeshMTLSModeAnnotation: as.MeshMTLSMode}
+	if as.MeshMTLSMode == model.MeshMTLSModeStrict {
+		// without the ports open to the gateway, the gateway would be refused by the sidecar
+		ports, err := dbmanager.TenantServicesPortDao().GetPortsByServiceID(as.ServiceID)
+		if err != nil {
+			return nil, fmt.Errorf("get ports of %s: %v", as.ServiceID, err)
+		}
+		var outerPorts []string
+		for _, port := range ports {
+			if port.IsOuterService != nil && *port.IsOuterService {
+				outerPorts = append(outerPorts, strconv.Itoa(port.ContainerPort))
+			}
+		}
+		if len(outerPorts) > 0 {
+			annotations[envoyv3.MeshMTLSPermissivePortsAnnotation] = strings.Join(outerPorts, ",")
+		}
+	}
+	return annotations, nil
+}
+
 // applyDefaultMeshPluginConfig applyDefaultMeshPluginConfig
 func applyDefaultMeshPluginConfig(as *typesv1.AppService, dbmanager db.Manager) (string, *api_model.ResourceSpec, error) {
 	var baseServices []*api_model.BaseService
@@ -409,6 +436,10 @@ func applyDefaultMeshPluginConfig(as *typesv1.AppService, dbmanager db.Manager)
 	if err != nil {
 		return "", nil, err
 	}
+	annotations, err := meshMTLSAnnotations(as, dbmanager)
+	if err != nil {
+		return "", nil, err
+	}
 	pluginID := "def-mesh" + as.ServiceID
 	cm := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -417,6 +448,7 @@ func applyDefaultMeshPluginConfig(as *typesv1.AppService, dbmanager db.Manager)
 				"plugin_id":     pluginID,
 				"service_alias": as.ServiceAlias,
 			}),
+			Annotations: annotations,
 		},
 		Data: map[string]string{
 			"plugin-config": string(resJSON),
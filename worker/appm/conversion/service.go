@@ -109,6 +109,7 @@ func TenantServiceBase(as *v1.AppService, dbmanager db.Manager) error {
 	as.AppID = tenantService.AppID
 	as.ServiceAlias = tenantService.ServiceAlias
 	as.UpgradeMethod = v1.TypeUpgradeMethod(tenantService.UpgradeMethod)
+	as.ExtendMethod = tenantService.ExtendMethod
 	if tenantService.K8sComponentName == "" {
 		tenantService.K8sComponentName = tenantService.ServiceAlias
 	}
@@ -147,6 +148,10 @@ func TenantServiceBase(as *v1.AppService, dbmanager db.Manager) error {
 		initBaseCronJob(as, tenantService)
 		return nil
 	}
+	if tenantService.IsDaemonSet() {
+		initBaseDaemonSet(as, tenantService)
+		return nil
+	}
 	if tenantService.IsVM() {
 		initBaseVirtualMachine(as, tenantService)
 		return nil
@@ -260,6 +265,31 @@ func initBaseDeployment(as *v1.AppService, service *dbmodel.TenantServices) {
 	as.SetDeployment(deployment)
 }
 
+func initBaseDaemonSet(as *v1.AppService, service *dbmodel.TenantServices) {
+	as.ServiceType = v1.TypeDaemonSet
+	daemonset := as.GetDaemonSet()
+	if daemonset == nil {
+		daemonset = &appsv1.DaemonSet{}
+	}
+	daemonset.Namespace = as.GetNamespace()
+	if daemonset.Spec.Selector == nil {
+		daemonset.Spec.Selector = &metav1.LabelSelector{}
+	}
+	initSelector(daemonset.Spec.Selector, service)
+	daemonset.Name = as.GetK8sWorkloadName()
+	daemonset.GenerateName = strings.Replace(service.ServiceAlias, "_", "-", -1)
+	injectLabels := getInjectLabels(as)
+	daemonset.Labels = as.GetCommonLabels(daemonset.Labels, map[string]string{
+		"name":    service.ServiceAlias,
+		"version": service.DeployVersion,
+	}, injectLabels)
+	daemonset.Spec.UpdateStrategy.Type = appsv1.RollingUpdateDaemonSetStrategyType
+	if as.UpgradeMethod == v1.OnDelete {
+		daemonset.Spec.UpdateStrategy.Type = appsv1.OnDeleteDaemonSetStrategyType
+	}
+	as.SetDaemonSet(daemonset)
+}
+
 func initBaseJob(as *v1.AppService, service *dbmodel.TenantServices) {
 	as.ServiceType = v1.TypeJob
 	job := as.GetJob()
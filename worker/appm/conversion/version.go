@@ -37,13 +37,17 @@ import (
 	"github.com/goodrain/rainbond/node/nodem/client"
 	"github.com/goodrain/rainbond/util"
 	"github.com/goodrain/rainbond/util/envutil"
+	k8sutil "github.com/goodrain/rainbond/util/k8s"
+	"github.com/goodrain/rainbond/worker/appm/secretstore"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
 	"github.com/goodrain/rainbond/worker/appm/volume"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 	"sigs.k8s.io/yaml"
 )
 
@@ -104,6 +108,14 @@ func TenantServiceVersion(as *v1.AppService, dbmanager db.Manager) error {
 	if err != nil {
 		return fmt.Errorf("craete service account name failure: %v", err)
 	}
+	topologySpreadConstraints, err := createTopologySpreadConstraints(as, dbmanager)
+	if err != nil {
+		return fmt.Errorf("create topology spread constraints failure: %v", err)
+	}
+	priorityClassName, err := createPriorityClassName(as, dbmanager)
+	if err != nil {
+		return fmt.Errorf("create priority class name failure: %v", err)
+	}
 	var terminationGracePeriodSeconds int64 = 10
 	vmt := kubevirtv1.VirtualMachineInstanceTemplateSpec{}
 	podtmpSpec := corev1.PodTemplateSpec{}
@@ -251,10 +263,12 @@ func TenantServiceVersion(as *v1.AppService, dbmanager db.Manager) error {
 					}
 					return ""
 				}(),
-				ServiceAccountName:    san,
-				ShareProcessNamespace: util.Bool(createShareProcessNamespace(as, dbmanager)),
-				DNSPolicy:             corev1.DNSPolicy(dnsPolicy),
-				HostIPC:               createHostIPC(as, dbmanager),
+				ServiceAccountName:        san,
+				ShareProcessNamespace:     util.Bool(createShareProcessNamespace(as, dbmanager)),
+				DNSPolicy:                 corev1.DNSPolicy(dnsPolicy),
+				HostIPC:                   createHostIPC(as, dbmanager),
+				TopologySpreadConstraints: topologySpreadConstraints,
+				PriorityClassName:         priorityClassName,
 			},
 		}
 	}
@@ -269,6 +283,16 @@ func TenantServiceVersion(as *v1.AppService, dbmanager db.Manager) error {
 	if as.GetDeployment() != nil {
 		podtmpSpec.Spec.TerminationGracePeriodSeconds = &terminationGracePeriodSeconds
 	}
+	if daemonset := as.GetDaemonSet(); daemonset != nil {
+		podtmpSpec.Spec.TerminationGracePeriodSeconds = &terminationGracePeriodSeconds
+		updateStrategy, err := createUpdateStrategy(as, dbmanager)
+		if err != nil {
+			return fmt.Errorf("create update strategy failure: %v", err)
+		}
+		if updateStrategy != nil {
+			daemonset.Spec.UpdateStrategy = *updateStrategy
+		}
+	}
 	if as.GetJob() != nil {
 		podtmpSpec.Spec.RestartPolicy = "Never"
 	}
@@ -498,6 +522,19 @@ func createEnv(as *v1.AppService, dbmanager db.Manager, envVarSecrets []*corev1.
 		envsAll = append(envsAll, es...)
 	}
 	for _, e := range envsAll {
+		if e.IsExternalSecret() {
+			if e.ServiceID != as.ServiceID {
+				// the external secrets of the dependencies are not shared
+				continue
+			}
+			envs = append(envs, corev1.EnvVar{Name: strings.TrimSpace(e.AttrName), ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretstore.SecretName(as.GetK8sWorkloadName())},
+					Key:                  strings.TrimSpace(e.AttrName),
+				},
+			}})
+			continue
+		}
 		envs = append(envs, corev1.EnvVar{Name: strings.TrimSpace(e.AttrName), Value: e.AttrValue})
 	}
 
@@ -964,6 +1001,15 @@ func createProbe(as *v1.AppService, dbmanager db.Manager, mode string) *corev1.P
 		} else if probe.Scheme == "cmd" {
 			p.Exec = &corev1.ExecAction{Command: strings.Split(probe.Cmd, " ")}
 			return p
+		} else if probe.Scheme == "grpc" {
+			// grpc probes are only served by kubelet since kubernetes 1.24
+			if !k8sutil.GetKubeVersion().AtLeast(utilversion.MustParseSemantic("v1.24.0")) {
+				logrus.Warningf("grpc probe of service %s is not supported by this cluster, fall back to tcp", as.ServiceID)
+				p.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt(probe.Port)}
+				return p
+			}
+			p.GRPC = &corev1.GRPCAction{Port: int32(probe.Port), Service: &probe.GRPCService}
+			return p
 		}
 		return nil
 	}
@@ -1459,6 +1505,69 @@ func createLifecycle(as *v1.AppService, dbmanager db.Manager) (*corev1.Lifecycle
 	return &lifecycle, nil
 }
 
+func createUpdateStrategy(as *v1.AppService, dbmanager db.Manager) (*appsv1.DaemonSetUpdateStrategy, error) {
+	attr, err := dbmanager.ComponentK8sAttributeDao().GetByComponentIDAndName(as.ServiceID, model.K8sAttributeNameUpdateStrategy)
+	if err != nil {
+		return nil, err
+	}
+	if attr == nil {
+		return nil, nil
+	}
+	updateStrategyJSON, err := yaml.YAMLToJSON([]byte(attr.AttributeValue))
+	if err != nil {
+		return nil, err
+	}
+	var updateStrategy appsv1.DaemonSetUpdateStrategy
+	if err := json.Unmarshal(updateStrategyJSON, &updateStrategy); err != nil {
+		return nil, err
+	}
+	return &updateStrategy, nil
+}
+
+// createTopologySpreadConstraints spreads the pods of a stateless_multiple component across the nodes
+// and zones as far as possible by default, an empty list of the attribute disables the default.
+func createTopologySpreadConstraints(as *v1.AppService, dbmanager db.Manager) ([]corev1.TopologySpreadConstraint, error) {
+	attr, err := dbmanager.ComponentK8sAttributeDao().GetByComponentIDAndName(as.ServiceID, model.K8sAttributeNameTopologySpreadConstraints)
+	if err != nil {
+		return nil, err
+	}
+	if attr == nil {
+		if dbmodel.ServiceType(as.ExtendMethod) != dbmodel.ServiceTypeStatelessMultiple || as.GetDeployment() == nil {
+			return nil, nil
+		}
+		selector := &metav1.LabelSelector{MatchLabels: map[string]string{"service_id": as.ServiceID}}
+		return []corev1.TopologySpreadConstraint{
+			{MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: selector},
+			{MaxSkew: 1, TopologyKey: corev1.LabelTopologyZone, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: selector},
+		}, nil
+	}
+	constraintsJSON, err := yaml.YAMLToJSON([]byte(attr.AttributeValue))
+	if err != nil {
+		return nil, err
+	}
+	var constraints []corev1.TopologySpreadConstraint
+	if err := json.Unmarshal(constraintsJSON, &constraints); err != nil {
+		return nil, err
+	}
+	for i := range constraints {
+		if constraints[i].LabelSelector == nil {
+			constraints[i].LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"service_id": as.ServiceID}}
+		}
+	}
+	return constraints, nil
+}
+
+func createPriorityClassName(as *v1.AppService, dbmanager db.Manager) (string, error) {
+	attr, err := dbmanager.ComponentK8sAttributeDao().GetByComponentIDAndName(as.ServiceID, model.K8sAttributeNamePriorityClassName)
+	if err != nil {
+		return "", err
+	}
+	if attr == nil {
+		return "", nil
+	}
+	return strings.TrimSpace(attr.AttributeValue), nil
+}
+
 func createSecurityContext(as *v1.AppService, dbmanager db.Manager) (*corev1.SecurityContext, error) {
 	var securityContext corev1.SecurityContext
 	sc, err := dbmanager.ComponentK8sAttributeDao().GetByComponentIDAndName(as.ServiceID, model.K8sAttributeNameSecurityContext)
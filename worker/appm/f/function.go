@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/goodrain/rainbond/api/handler/app_governance_mode/adaptor"
 	"github.com/goodrain/rainbond/gateway/annotations/parser"
 	"github.com/goodrain/rainbond/util/apply"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
@@ -34,11 +35,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	betav1 "k8s.io/api/networking/v1beta1"
+	policyv1 "k8s.io/api/policy/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -373,6 +378,71 @@ func EnsureHPABetav2(new *autoscalingv2beta2.HorizontalPodAutoscaler, clientSet
 	}
 }
 
+// EnsurePodDisruptionBudget creates or updates the pod disruption budget of the component,
+// or deletes the one named name if the component no longer has a budget.
+func EnsurePodDisruptionBudget(clientSet kubernetes.Interface, namespace, name string, new *policyv1.PodDisruptionBudget) error {
+	if new == nil {
+		err := clientSet.PolicyV1().PodDisruptionBudgets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return fmt.Errorf("delete pod disruption budget %s/%s: %v", namespace, name, err)
+		}
+		return nil
+	}
+	old, err := clientSet.PolicyV1().PodDisruptionBudgets(new.Namespace).Get(context.Background(), new.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			return fmt.Errorf("get pod disruption budget %s/%s: %v", new.Namespace, new.Name, err)
+		}
+		if _, err := clientSet.PolicyV1().PodDisruptionBudgets(new.Namespace).Create(context.Background(), new, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create pod disruption budget %s/%s: %v", new.Namespace, new.Name, err)
+		}
+		return nil
+	}
+	if apiequality.Semantic.DeepEqual(old.Spec, new.Spec) && apiequality.Semantic.DeepEqual(old.Labels, new.Labels) {
+		return nil
+	}
+	new.ResourceVersion = old.ResourceVersion
+	if _, err := clientSet.PolicyV1().PodDisruptionBudgets(new.Namespace).Update(context.Background(), new, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update pod disruption budget %s/%s: %v", new.Namespace, new.Name, err)
+	}
+	return nil
+}
+
+// PruneMeshPolicies deletes the mesh objects of the component which are no longer in its manifests,
+// such as the policies of a removed port or of the last dependency removed.
+func PruneMeshPolicies(ctx context.Context, c client.Client, app *v1.AppService) error {
+	wanted := make(map[string]bool)
+	for _, manifest := range app.GetManifests() {
+		wanted[manifest.GetKind()+"/"+manifest.GetName()] = true
+	}
+	for _, gvk := range app.GetMeshPolicyKinds() {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := c.List(ctx, list, client.InNamespace(app.GetNamespace()), client.MatchingLabels{
+			"service_id":            app.ServiceID,
+			adaptor.MeshPolicyLabel: "true",
+		})
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				// the mesh is not installed
+				continue
+			}
+			return fmt.Errorf("list %s of %s: %v", gvk.Kind, app.ServiceAlias, err)
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			if wanted[item.GetKind()+"/"+item.GetName()] {
+				continue
+			}
+			if err := c.Delete(ctx, item); err != nil && !k8sErrors.IsNotFound(err) {
+				return fmt.Errorf("delete %s %s/%s: %v", item.GetKind(), item.GetNamespace(), item.GetName(), err)
+			}
+			logrus.Infof("deleted %s %s/%s no longer wanted by the mesh", item.GetKind(), item.GetNamespace(), item.GetName())
+		}
+	}
+	return nil
+}
+
 // UpgradeIngress is used to update *networkingv1.Ingress.
 func UpgradeIngress(clientset kubernetes.Interface,
 	as *v1.AppService,
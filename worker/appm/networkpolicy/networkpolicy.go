@@ -0,0 +1,352 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package networkpolicy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/goodrain/rainbond/db"
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// IsolationLabel the label of the network policies generated for the isolated components
+	IsolationLabel = "network_isolation"
+	// NamespaceNameLabel the label set by kubernetes 1.21+ on every namespace, it is set by the controller before 1.21
+	NamespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+// Options the sources allowed to access the isolated components regardless of the dependencies
+type Options struct {
+	// SystemNamespace the namespace of the gateway and the platform monitoring
+	SystemNamespace string
+	// AllowCIDRs the cidrs allowed, such as the nodes of a gateway running in the host network,
+	// the controller allows the addresses of the nodes if it is empty
+	AllowCIDRs []string
+}
+
+// Peer a component allowed to access an isolated component
+type Peer struct {
+	ServiceID    string
+	ServiceAlias string
+	Namespace    string
+}
+
+// Rule the ingress of a component derived from the components depending on it
+type Rule struct {
+	Service   *dbmodel.TenantServices
+	Namespace string
+	Mode      string
+	Ports     []*dbmodel.TenantServicesPort
+	Peers     []*Peer
+}
+
+// EffectiveMode returns the stricter one of the isolation modes of the tenant and the app
+func EffectiveMode(tenantMode, appMode string) string {
+	weight := func(mode string) int {
+		switch mode {
+		case dbmodel.NetworkIsolationEnforce:
+			return 2
+		case dbmodel.NetworkIsolationDryRun:
+			return 1
+		}
+		return 0
+	}
+	if weight(appMode) > weight(tenantMode) {
+		return appMode
+	}
+	if weight(tenantMode) == 0 {
+		return dbmodel.NetworkIsolationDisable
+	}
+	return tenantMode
+}
+
+// ListRules computes the ingress rules of the components of the tenant.
+// If appID is not empty, only the components of the app are returned.
+// The components whose isolation mode is disabled are skipped unless withDisabled is true.
+func ListRules(dbm db.Manager, tenant *dbmodel.Tenants, appID string, withDisabled bool) ([]*Rule, error) {
+	services, err := dbm.TenantServiceDao().GetServicesByTenantID(tenant.UUID)
+	if err != nil {
+		return nil, err
+	}
+	var appIDs []string
+	for _, service := range services {
+		if service.AppID != "" {
+			appIDs = append(appIDs, service.AppID)
+		}
+	}
+	appModes := make(map[string]string)
+	if len(appIDs) > 0 {
+		apps, err := dbm.ApplicationDao().ListByAppIDs(appIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, app := range apps {
+			appModes[app.AppID] = app.NetworkIsolation
+		}
+	}
+	tenants := map[string]*dbmodel.Tenants{tenant.UUID: tenant}
+	return computeRules(dbm, tenants, services, func(service *dbmodel.TenantServices) (string, bool) {
+		if appID != "" && service.AppID != appID {
+			return "", false
+		}
+		mode := EffectiveMode(tenant.NetworkIsolation, appModes[service.AppID])
+		return mode, mode != dbmodel.NetworkIsolationDisable || withDisabled
+	})
+}
+
+// ListEnforcedRules computes the ingress rules of the components whose isolation is enforced in all the tenants.
+// The number of the queries does not grow with the number of the tenants.
+func ListEnforcedRules(dbm db.Manager) ([]*Rule, error) {
+	allTenants, err := dbm.TenantDao().GetALLTenants("")
+	if err != nil {
+		return nil, err
+	}
+	apps, err := dbm.ApplicationDao().ListByNetworkIsolation(dbmodel.NetworkIsolationEnforce)
+	if err != nil {
+		return nil, err
+	}
+	tenants := make(map[string]*dbmodel.Tenants)
+	var tenantIDs []string
+	for _, tenant := range allTenants {
+		tenants[tenant.UUID] = tenant
+		if tenant.NetworkIsolation == dbmodel.NetworkIsolationEnforce {
+			tenantIDs = append(tenantIDs, tenant.UUID)
+		}
+	}
+	enforcedApps := make(map[string]bool)
+	for _, app := range apps {
+		enforcedApps[app.AppID] = true
+		if tenant, ok := tenants[app.TenantID]; ok && tenant.NetworkIsolation != dbmodel.NetworkIsolationEnforce {
+			tenantIDs = append(tenantIDs, app.TenantID)
+		}
+	}
+	if len(tenantIDs) == 0 {
+		return nil, nil
+	}
+	services, err := dbm.TenantServiceDao().GetServicesByTenantIDs(tenantIDs)
+	if err != nil {
+		return nil, err
+	}
+	return computeRules(dbm, tenants, services, func(service *dbmodel.TenantServices) (string, bool) {
+		tenant, ok := tenants[service.TenantID]
+		if !ok {
+			return "", false
+		}
+		enforced := tenant.NetworkIsolation == dbmodel.NetworkIsolationEnforce || enforcedApps[service.AppID]
+		return dbmodel.NetworkIsolationEnforce, enforced
+	})
+}
+
+// computeRules computes the rules of the services selected by filter, which returns the isolation mode of the service.
+// tenants holds the known tenants by uuid, the others are queried for the namespaces of the peers.
+func computeRules(dbm db.Manager, tenants map[string]*dbmodel.Tenants, services []*dbmodel.TenantServices,
+	filter func(service *dbmodel.TenantServices) (string, bool)) ([]*Rule, error) {
+	var serviceIDs []string
+	serviceMap := make(map[string]*dbmodel.TenantServices)
+	rules := make(map[string]*Rule)
+	for _, service := range services {
+		serviceMap[service.ServiceID] = service
+		if service.Kind == dbmodel.ServiceKindThirdParty.String() {
+			continue
+		}
+		mode, ok := filter(service)
+		if !ok {
+			continue
+		}
+		tenant, ok := tenants[service.TenantID]
+		if !ok {
+			continue
+		}
+		serviceIDs = append(serviceIDs, service.ServiceID)
+		rules[service.ServiceID] = &Rule{Service: service, Namespace: tenant.Namespace, Mode: mode}
+	}
+	if len(serviceIDs) == 0 {
+		return nil, nil
+	}
+
+	ports, err := dbm.TenantServicesPortDao().ListInnerPortsByServiceIDs(serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range ports {
+		if rule, ok := rules[port.ServiceID]; ok {
+			rule.Ports = append(rule.Ports, port)
+		}
+	}
+
+	relations, err := dbm.TenantServiceRelationDao().ListByDependServiceIDs(serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+	peers, err := listPeers(dbm, tenants, serviceMap, relations)
+	if err != nil {
+		return nil, err
+	}
+	for _, relation := range relations {
+		rule, ok := rules[relation.DependServiceID]
+		if !ok {
+			continue
+		}
+		if peer, ok := peers[relation.ServiceID]; ok {
+			rule.Peers = append(rule.Peers, peer)
+		}
+	}
+
+	var result []*Rule
+	for _, serviceID := range serviceIDs {
+		rule := rules[serviceID]
+		sort.Slice(rule.Peers, func(i, j int) bool {
+			return rule.Peers[i].ServiceAlias < rule.Peers[j].ServiceAlias
+		})
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// listPeers returns the dependent components of the relations, including those of the other tenants
+func listPeers(dbm db.Manager, tenants map[string]*dbmodel.Tenants, serviceMap map[string]*dbmodel.TenantServices,
+	relations []*dbmodel.TenantServiceRelation) (map[string]*Peer, error) {
+	peers := make(map[string]*Peer)
+	var missing []string
+	namespaces := make(map[string]string)
+	for tenantID, tenant := range tenants {
+		namespaces[tenantID] = tenant.Namespace
+	}
+	for _, relation := range relations {
+		if _, ok := peers[relation.ServiceID]; ok {
+			continue
+		}
+		service, ok := serviceMap[relation.ServiceID]
+		if !ok {
+			missing = append(missing, relation.ServiceID)
+			continue
+		}
+		peers[service.ServiceID] = &Peer{ServiceID: service.ServiceID, ServiceAlias: service.ServiceAlias, Namespace: namespaces[service.TenantID]}
+	}
+	if len(missing) == 0 {
+		return peers, nil
+	}
+	services, err := dbm.TenantServiceDao().GetServiceByIDs(missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services {
+		namespace, ok := namespaces[service.TenantID]
+		if !ok {
+			other, err := dbm.TenantDao().GetTenantByUUID(service.TenantID)
+			if err != nil {
+				return nil, err
+			}
+			namespace = other.Namespace
+			namespaces[service.TenantID] = namespace
+		}
+		peers[service.ServiceID] = &Peer{ServiceID: service.ServiceID, ServiceAlias: service.ServiceAlias, Namespace: namespace}
+	}
+	return peers, nil
+}
+
+// Name returns the name of the network policy of the component
+func Name(service *dbmodel.TenantServices) string {
+	return service.ServiceAlias + "-isolation"
+}
+
+// Build creates the network policy of the rule. The pods of the component accept the ingress from:
+// the other pods of the component, the dependent components on the inner ports,
+// the system namespace and the allowed cidrs on any port.
+func Build(rule *Rule, opts Options) *networkingv1.NetworkPolicy {
+	service := rule.Service
+	selfPeer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"service_id": service.ServiceID}},
+	}
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{From: []networkingv1.NetworkPolicyPeer{selfPeer}},
+	}
+
+	// without an inner port, the dependent components have nothing to access, an empty
+	// port list would allow them on every port
+	if ports := policyPorts(rule.Ports); len(rule.Peers) > 0 && len(ports) > 0 {
+		var from []networkingv1.NetworkPolicyPeer
+		for _, peer := range rule.Peers {
+			p := networkingv1.NetworkPolicyPeer{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"service_id": peer.ServiceID}},
+			}
+			if peer.Namespace != rule.Namespace {
+				p.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{NamespaceNameLabel: peer.Namespace}}
+			}
+			from = append(from, p)
+		}
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{From: from, Ports: ports})
+	}
+
+	var system []networkingv1.NetworkPolicyPeer
+	if opts.SystemNamespace != "" {
+		system = append(system, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{NamespaceNameLabel: opts.SystemNamespace}},
+		})
+	}
+	for _, cidr := range opts.AllowCIDRs {
+		system = append(system, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: cidr}})
+	}
+	if len(system) > 0 {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{From: system})
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Name(service),
+			Namespace: rule.Namespace,
+			Labels: map[string]string{
+				"creator":      "Rainbond",
+				"tenant_id":    service.TenantID,
+				"service_id":   service.ServiceID,
+				IsolationLabel: "true",
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"service_id": service.ServiceID}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     ingress,
+		},
+	}
+}
+
+func policyPorts(ports []*dbmodel.TenantServicesPort) []networkingv1.NetworkPolicyPort {
+	var result []networkingv1.NetworkPolicyPort
+	seen := make(map[string]bool)
+	for _, port := range ports {
+		protocol := corev1.ProtocolTCP
+		if strings.ToLower(port.Protocol) == "udp" {
+			protocol = corev1.ProtocolUDP
+		}
+		p := intstr.FromInt(port.ContainerPort)
+		key := string(protocol) + p.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &p})
+	}
+	return result
+}
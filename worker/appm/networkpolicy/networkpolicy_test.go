@@ -0,0 +1,113 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package networkpolicy
+
+import (
+	"testing"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEffectiveMode(t *testing.T) {
+	tests := []struct {
+		tenant, app, want string
+	}{
+		{"", "", dbmodel.NetworkIsolationDisable},
+		{dbmodel.NetworkIsolationDisable, dbmodel.NetworkIsolationDryRun, dbmodel.NetworkIsolationDryRun},
+		{dbmodel.NetworkIsolationEnforce, dbmodel.NetworkIsolationDisable, dbmodel.NetworkIsolationEnforce},
+		{dbmodel.NetworkIsolationDryRun, dbmodel.NetworkIsolationEnforce, dbmodel.NetworkIsolationEnforce},
+	}
+	for _, tc := range tests {
+		if got := EffectiveMode(tc.tenant, tc.app); got != tc.want {
+			t.Errorf("EffectiveMode(%q, %q) = %q, want %q", tc.tenant, tc.app, got, tc.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	rule := &Rule{
+		Service:   &dbmodel.TenantServices{ServiceID: "s1", ServiceAlias: "gr000001", TenantID: "t1"},
+		Namespace: "ns1",
+		Mode:      dbmodel.NetworkIsolationEnforce,
+		Ports: []*dbmodel.TenantServicesPort{
+			{ContainerPort: 5000, Protocol: "http"},
+			{ContainerPort: 53, Protocol: "udp"},
+		},
+		Peers: []*Peer{
+			{ServiceID: "s2", ServiceAlias: "gr000002", Namespace: "ns1"},
+			{ServiceID: "s3", ServiceAlias: "gr000003", Namespace: "ns2"},
+		},
+	}
+	policy := Build(rule, Options{SystemNamespace: "rbd-system", AllowCIDRs: []string{"192.168.0.0/24"}})
+	if policy.Name != "gr000001-isolation" || policy.Namespace != "ns1" {
+		t.Fatalf("unexpected policy %s/%s", policy.Namespace, policy.Name)
+	}
+	if policy.Labels[IsolationLabel] != "true" {
+		t.Errorf("missing the isolation label")
+	}
+	if policy.Spec.PodSelector.MatchLabels["service_id"] != "s1" {
+		t.Errorf("unexpected pod selector %v", policy.Spec.PodSelector)
+	}
+	if len(policy.Spec.Ingress) != 3 {
+		t.Fatalf("expected 3 ingress rules, got %d", len(policy.Spec.Ingress))
+	}
+
+	dependents := policy.Spec.Ingress[1]
+	if len(dependents.From) != 2 {
+		t.Fatalf("expected 2 dependents, got %d", len(dependents.From))
+	}
+	if dependents.From[0].NamespaceSelector != nil {
+		t.Errorf("the dependent of the same namespace should not select the namespace")
+	}
+	if ns := dependents.From[1].NamespaceSelector; ns == nil || ns.MatchLabels[NamespaceNameLabel] != "ns2" {
+		t.Errorf("unexpected namespace selector of the dependent of the other namespace: %v", ns)
+	}
+	if len(dependents.Ports) != 2 || *dependents.Ports[1].Protocol != corev1.ProtocolUDP || dependents.Ports[1].Port.IntValue() != 53 {
+		t.Errorf("unexpected ports %v", dependents.Ports)
+	}
+
+	system := policy.Spec.Ingress[2]
+	if len(system.From) != 2 || system.From[1].IPBlock == nil || system.Ports != nil {
+		t.Errorf("unexpected system ingress %v", system)
+	}
+}
+
+func TestBuildWithoutDependents(t *testing.T) {
+	rule := &Rule{
+		Service:   &dbmodel.TenantServices{ServiceID: "s1", ServiceAlias: "gr000001"},
+		Namespace: "ns1",
+	}
+	policy := Build(rule, Options{})
+	if len(policy.Spec.Ingress) != 1 {
+		t.Fatalf("expected only the ingress from the component itself, got %d rules", len(policy.Spec.Ingress))
+	}
+}
+
+func TestBuildWithoutInnerPorts(t *testing.T) {
+	rule := &Rule{
+		Service:   &dbmodel.TenantServices{ServiceID: "s1", ServiceAlias: "gr000001"},
+		Namespace: "ns1",
+		Peers:     []*Peer{{ServiceID: "s2", ServiceAlias: "gr000002", Namespace: "ns1"}},
+	}
+	policy := Build(rule, Options{})
+	if len(policy.Spec.Ingress) != 1 {
+		t.Fatalf("the dependents should not be allowed without an inner port, got %d rules", len(policy.Spec.Ingress))
+	}
+}
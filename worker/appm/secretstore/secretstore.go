@@ -0,0 +1,152 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package secretstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// ExternalSecretLabel the label of the secrets resolved from the external secret store
+	ExternalSecretLabel = "external_secret"
+	// RefsAnnotation the references resolved into the secret
+	RefsAnnotation = "rainbond.io/external-secret-refs"
+	// WorkloadAnnotation the kind and name of the workload using the secret, such as Deployment/gr123456
+	WorkloadAnnotation = "rainbond.io/external-secret-workload"
+	// ChecksumAnnotation the checksum of the resolved values set on the pod template,
+	// changing it rolling restarts the pods.
+	ChecksumAnnotation = "rainbond.io/external-secret-checksum"
+	// TenantRoot the root of the paths of the tenants in the store, the secrets of a tenant are kept under <TenantRoot>/<tenant_id>/
+	TenantRoot = "rainbond"
+)
+
+var (
+	// ErrNotConfigured the external secret store is not configured
+	ErrNotConfigured = errors.New("the external secret store is not configured")
+	// ErrSecretNotFound the secret or its version does not exist
+	ErrSecretNotFound = errors.New("secret not found")
+	// ErrNoTenant the tenant of the refs is unknown
+	ErrNoTenant = errors.New("the tenant of the external secrets is unknown")
+)
+
+// Store reads the secrets from the external secret store
+type Store interface {
+	// Read reads the given version of the secret at path, the latest one if version is 0
+	Read(ctx context.Context, path string, version int) (*Secret, error)
+}
+
+// Secret the key values of a version of a secret
+type Secret struct {
+	Data    map[string]string
+	Version int
+}
+
+// Ref a key of the external secret store resolved into a data key of the secret of the component
+type Ref struct {
+	DataKey string `json:"data_key"`
+	Path    string `json:"path"`
+	Key     string `json:"key"`
+	// Version the pinned version, 0 means the latest one
+	Version int `json:"version,omitempty"`
+	// Resolved the version read from the store
+	Resolved int  `json:"resolved,omitempty"`
+	Restart  bool `json:"restart,omitempty"`
+}
+
+var defaultStore Store
+
+// SetDefault sets the store the components read their external secrets from
+func SetDefault(store Store) {
+	defaultStore = store
+}
+
+// Default returns the store the components read their external secrets from
+func Default() (Store, error) {
+	if defaultStore == nil {
+		return nil, ErrNotConfigured
+	}
+	return defaultStore, nil
+}
+
+// SecretName returns the name of the secret holding the external values of the workload
+func SecretName(workloadName string) string {
+	return workloadName + "-external-secrets"
+}
+
+// FileKey returns the data key of a config file, the volume name is not always a valid key
+func FileKey(volumeName string) string {
+	sum := sha256.Sum256([]byte(volumeName))
+	return "file-" + hex.EncodeToString(sum[:8])
+}
+
+// TenantPath returns the path in the store of the secret path referenced by the tenant.
+// The store is shared by all the tenants, a tenant only reads the secrets under its own root.
+func TenantPath(tenantID, path string) string {
+	return TenantRoot + "/" + tenantID + "/" + strings.TrimLeft(path, "/")
+}
+
+// Resolve reads the values of the refs of the tenant and records the versions read. Each version of a secret is read once.
+func Resolve(ctx context.Context, store Store, tenantID string, refs []*Ref) (map[string][]byte, error) {
+	if tenantID == "" {
+		return nil, ErrNoTenant
+	}
+	secrets := make(map[string]*Secret)
+	data := make(map[string][]byte, len(refs))
+	for _, ref := range refs {
+		cacheKey := fmt.Sprintf("%s@%d", ref.Path, ref.Version)
+		secret, ok := secrets[cacheKey]
+		if !ok {
+			var err error
+			secret, err = store.Read(ctx, TenantPath(tenantID, ref.Path), ref.Version)
+			if err != nil {
+				return nil, fmt.Errorf("read secret %s: %v", ref.Path, err)
+			}
+			secrets[cacheKey] = secret
+		}
+		value, ok := secret.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("key %s not found in secret %s", ref.Key, ref.Path)
+		}
+		data[ref.DataKey] = []byte(value)
+		ref.Resolved = secret.Version
+	}
+	return data, nil
+}
+
+// Checksum returns the checksum of the resolved values
+func Checksum(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write(data[key])
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
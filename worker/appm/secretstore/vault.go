@@ -0,0 +1,103 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type vault struct {
+	addr   string
+	token  string
+	mount  string
+	client *http.Client
+}
+
+// NewVault creates a store reading the secrets from the KV version 2 secrets engine mounted at mount
+// of HashiCorp Vault, or of any server compatible with its http api.
+func NewVault(addr, token, mount string) Store {
+	return &vault{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		mount:  strings.Trim(mount, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data     map[string]interface{} `json:"data"`
+		Metadata struct {
+			Version int `json:"version"`
+		} `json:"metadata"`
+	} `json:"data"`
+}
+
+// Read reads the given version of the secret at path, the latest one if version is 0
+func (v *vault) Read(ctx context.Context, path string, version int) (*Secret, error) {
+	u := fmt.Sprintf("%s/v1/%s/data/%s", v.addr, v.mount, strings.TrimLeft(path, "/"))
+	if version > 0 {
+		u += "?" + url.Values{"version": []string{strconv.Itoa(version)}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	res, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrSecretNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("unexpected status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var kv vaultKVResponse
+	if err := json.NewDecoder(res.Body).Decode(&kv); err != nil {
+		return nil, fmt.Errorf("decode secret: %v", err)
+	}
+	if kv.Data.Data == nil {
+		// the version is deleted or destroyed
+		return nil, ErrSecretNotFound
+	}
+	secret := &Secret{Data: make(map[string]string, len(kv.Data.Data)), Version: kv.Data.Metadata.Version}
+	for key, value := range kv.Data.Data {
+		if str, ok := value.(string); ok {
+			secret.Data[key] = str
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encode the value of key %s: %v", key, err)
+		}
+		secret.Data[key] = string(b)
+	}
+	return secret, nil
+}
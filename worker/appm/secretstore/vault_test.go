@@ -0,0 +1,129 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newVaultServer starts a stand-in of the KV version 2 api of vault serving the versions of the secrets
+func newVaultServer(t *testing.T, token string, secrets map[string][]map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+		versions, ok := secrets[path]
+		if !ok {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+		version := len(versions)
+		if v := r.URL.Query().Get("version"); v != "" {
+			version, _ = strconv.Atoi(v)
+		}
+		if version < 1 || version > len(versions) {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     versions[version-1],
+				"metadata": map[string]interface{}{"version": version},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultRead(t *testing.T) {
+	server := newVaultServer(t, "root", map[string][]map[string]interface{}{
+		"db": {
+			{"password": "v1"},
+			{"password": "v2", "port": 3306},
+		},
+	})
+	store := NewVault(server.URL, "root", "secret")
+
+	secret, err := store.Read(context.Background(), "db", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Version != 2 || secret.Data["password"] != "v2" || secret.Data["port"] != "3306" {
+		t.Errorf("unexpected latest secret %+v", secret)
+	}
+	secret, err = store.Read(context.Background(), "/db", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Version != 1 || secret.Data["password"] != "v1" {
+		t.Errorf("unexpected secret version 1 %+v", secret)
+	}
+	if _, err := store.Read(context.Background(), "missing", 0); err != ErrSecretNotFound {
+		t.Errorf("expected secret not found, got %v", err)
+	}
+	if _, err := NewVault(server.URL, "wrong", "secret").Read(context.Background(), "db", 0); err == nil {
+		t.Errorf("expected permission denied")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	server := newVaultServer(t, "root", map[string][]map[string]interface{}{
+		"rainbond/t1/db": {
+			{"password": "v1", "user": "admin"},
+			{"password": "v2", "user": "admin"},
+		},
+	})
+	store := NewVault(server.URL, "root", "secret")
+	refs := []*Ref{
+		{DataKey: "DB_PASSWORD", Path: "db", Key: "password"},
+		{DataKey: "DB_USER", Path: "db", Key: "user"},
+		{DataKey: FileKey("conf"), Path: "db", Key: "password", Version: 1},
+	}
+	data, err := Resolve(context.Background(), store, "t1", refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data["DB_PASSWORD"]) != "v2" || string(data["DB_USER"]) != "admin" || string(data[FileKey("conf")]) != "v1" {
+		t.Errorf("unexpected data %v", data)
+	}
+	if refs[0].Resolved != 2 || refs[2].Resolved != 1 {
+		t.Errorf("unexpected resolved versions %d, %d", refs[0].Resolved, refs[2].Resolved)
+	}
+	if Checksum(data) == Checksum(map[string][]byte{"DB_PASSWORD": []byte("v1")}) {
+		t.Errorf("expected different checksums")
+	}
+
+	if _, err := Resolve(context.Background(), store, "t1", []*Ref{{DataKey: "X", Path: "db", Key: "missing"}}); err == nil {
+		t.Errorf("expected the missing key error")
+	}
+	if _, err := Resolve(context.Background(), store, "t2", []*Ref{{DataKey: "X", Path: "db", Key: "password"}}); err == nil {
+		t.Errorf("expected the secret of another tenant not found")
+	}
+	if _, err := Resolve(context.Background(), store, "", refs); err != ErrNoTenant {
+		t.Errorf("expected the unknown tenant error, got %v", err)
+	}
+}
@@ -30,6 +30,7 @@ type Informer struct {
 	Secret                  cache.SharedIndexInformer
 	StatefulSet             cache.SharedIndexInformer
 	Deployment              cache.SharedIndexInformer
+	DaemonSet               cache.SharedIndexInformer
 	Pod                     cache.SharedIndexInformer
 	ConfigMap               cache.SharedIndexInformer
 	ReplicaSet              cache.SharedIndexInformer
@@ -63,6 +64,7 @@ func (i *Informer) Start(stop chan struct{}) {
 	go i.Secret.Run(stop)
 	go i.StatefulSet.Run(stop)
 	go i.Deployment.Run(stop)
+	go i.DaemonSet.Run(stop)
 	go i.Pod.Run(stop)
 	go i.ConfigMap.Run(stop)
 	go i.ReplicaSet.Run(stop)
@@ -83,7 +85,7 @@ func (i *Informer) Start(stop chan struct{}) {
 //Ready if all kube informers is syncd, store is ready
 func (i *Informer) Ready() bool {
 	if i.Namespace.HasSynced() && i.Ingress.HasSynced() && i.Service.HasSynced() && i.Secret.HasSynced() &&
-		i.StatefulSet.HasSynced() && i.Deployment.HasSynced() && i.Pod.HasSynced() && i.CronJob.HasSynced() &&
+		i.StatefulSet.HasSynced() && i.Deployment.HasSynced() && i.DaemonSet.HasSynced() && i.Pod.HasSynced() && i.CronJob.HasSynced() &&
 		i.ConfigMap.HasSynced() && i.Nodes.HasSynced() && i.Events.HasSynced() &&
 		i.HorizontalPodAutoscaler.HasSynced() && i.StorageClass.HasSynced() && i.Claims.HasSynced() && i.CRD.HasSynced() {
 		return true
@@ -212,6 +212,9 @@ func NewStore(
 	store.informers.Deployment = infFactory.Apps().V1().Deployments().Informer()
 	store.listers.Deployment = infFactory.Apps().V1().Deployments().Lister()
 
+	store.informers.DaemonSet = infFactory.Apps().V1().DaemonSets().Informer()
+	store.listers.DaemonSet = infFactory.Apps().V1().DaemonSets().Lister()
+
 	store.informers.StatefulSet = infFactory.Apps().V1().StatefulSets().Informer()
 	store.listers.StatefulSet = infFactory.Apps().V1().StatefulSets().Lister()
 
@@ -333,6 +336,7 @@ func NewStore(
 
 	store.informers.Namespace.AddEventHandler(store.nsEventHandler())
 	store.informers.Deployment.AddEventHandlerWithResyncPeriod(store, time.Second*10)
+	store.informers.DaemonSet.AddEventHandlerWithResyncPeriod(store, time.Second*10)
 	store.informers.StatefulSet.AddEventHandlerWithResyncPeriod(store, time.Second*10)
 	store.informers.Job.AddEventHandlerWithResyncPeriod(store, time.Second*10)
 	store.informers.CronJob.AddEventHandlerWithResyncPeriod(store, time.Second*10)
@@ -465,6 +469,21 @@ func (a *appRuntimeStore) OnAdd(obj interface{}) {
 			}
 		}
 	}
+	if daemonset, ok := obj.(*appsv1.DaemonSet); ok {
+		serviceID := daemonset.Labels["service_id"]
+		version := daemonset.Labels["version"]
+		createrID := daemonset.Labels["creater_id"]
+		if serviceID != "" && version != "" && createrID != "" {
+			appservice, err := a.getAppService(serviceID, version, createrID, true)
+			if err == conversion.ErrServiceNotFound {
+				a.conf.KubeClient.AppsV1().DaemonSets(daemonset.Namespace).Delete(context.Background(), daemonset.Name, metav1.DeleteOptions{})
+			}
+			if appservice != nil {
+				appservice.SetDaemonSet(daemonset)
+				return
+			}
+		}
+	}
 	if job, ok := obj.(*batchv1.Job); ok {
 		serviceID := job.Labels["service_id"]
 		version := job.Labels["version"]
@@ -888,6 +907,21 @@ func (a *appRuntimeStore) OnDeletes(objs ...interface{}) {
 				}
 			}
 		}
+		if daemonset, ok := obj.(*appsv1.DaemonSet); ok {
+			serviceID := daemonset.Labels["service_id"]
+			version := daemonset.Labels["version"]
+			createrID := daemonset.Labels["creater_id"]
+			if serviceID != "" && version != "" && createrID != "" {
+				appservice, _ := a.getAppService(serviceID, version, createrID, false)
+				if appservice != nil {
+					appservice.DeleteDaemonSet(daemonset)
+					if appservice.IsClosed() {
+						a.DeleteAppService(appservice)
+					}
+					return
+				}
+			}
+		}
 		if statefulset, ok := obj.(*appsv1.StatefulSet); ok {
 			serviceID := statefulset.Labels["service_id"]
 			version := statefulset.Labels["version"]
@@ -1139,6 +1173,15 @@ func (a *appRuntimeStore) UpdateGetAppService(serviceID string) *v1.AppService {
 				appService.SetDeployment(deploy)
 			}
 		}
+		if daemonset := appService.GetDaemonSet(); daemonset != nil {
+			ds, err := a.listers.DaemonSet.DaemonSets(daemonset.Namespace).Get(daemonset.Name)
+			if err != nil && k8sErrors.IsNotFound(err) {
+				appService.DeleteDaemonSet(daemonset)
+			}
+			if ds != nil {
+				appService.SetDaemonSet(ds)
+			}
+		}
 		if job := appService.GetJob(); job != nil {
 			j, err := a.listers.Job.Jobs(job.Namespace).Get(job.Name)
 			if err != nil && k8sErrors.IsNotFound(err) {
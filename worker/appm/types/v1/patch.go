@@ -68,6 +68,21 @@ func (a *AppService) SetUpgradePatch(new *AppService) error {
 		}
 		new.UpgradePatch["deployment"] = deploymentPatch
 	}
+	if a.daemonset != nil && new.daemonset != nil {
+		daemonsetPatch, err := getDaemonSetModifiedConfiguration(a.daemonset, new.daemonset)
+		if err != nil {
+			return err
+		}
+		if len(daemonsetPatch) == 0 {
+			return fmt.Errorf("no upgrade")
+		}
+		daemonsetPatch, err = K8sResourceFormat(daemonsetPatch)
+		if err != nil {
+			logrus.Error("service upgrade format daemonset patch error:", err)
+			return err
+		}
+		new.UpgradePatch["daemonset"] = daemonsetPatch
+	}
 	//update cache app service base info by new app service
 	a.AppServiceBase = new.AppServiceBase
 	return nil
@@ -199,18 +214,20 @@ func getStatefulsetAllowFields(s *v1.StatefulSet) *v1.StatefulSet {
 			Replicas: s.Spec.Replicas,
 			Template: corev1.PodTemplateSpec{
 				Spec: corev1.PodSpec{
-					Volumes:          s.Spec.Template.Spec.Volumes,
-					InitContainers:   s.Spec.Template.Spec.InitContainers,
-					Containers:       s.Spec.Template.Spec.Containers,
-					ImagePullSecrets: s.Spec.Template.Spec.ImagePullSecrets,
-					NodeSelector:     s.Spec.Template.Spec.NodeSelector,
-					Tolerations:      s.Spec.Template.Spec.Tolerations,
-					Affinity:         s.Spec.Template.Spec.Affinity,
-					HostAliases:      s.Spec.Template.Spec.HostAliases,
-					Hostname:         s.Spec.Template.Spec.Hostname,
-					NodeName:         s.Spec.Template.Spec.NodeName,
-					HostNetwork:      s.Spec.Template.Spec.HostNetwork,
-					SchedulerName:    s.Spec.Template.Spec.SchedulerName,
+					Volumes:                   s.Spec.Template.Spec.Volumes,
+					InitContainers:            s.Spec.Template.Spec.InitContainers,
+					Containers:                s.Spec.Template.Spec.Containers,
+					ImagePullSecrets:          s.Spec.Template.Spec.ImagePullSecrets,
+					NodeSelector:              s.Spec.Template.Spec.NodeSelector,
+					Tolerations:               s.Spec.Template.Spec.Tolerations,
+					Affinity:                  s.Spec.Template.Spec.Affinity,
+					HostAliases:               s.Spec.Template.Spec.HostAliases,
+					Hostname:                  s.Spec.Template.Spec.Hostname,
+					NodeName:                  s.Spec.Template.Spec.NodeName,
+					HostNetwork:               s.Spec.Template.Spec.HostNetwork,
+					SchedulerName:             s.Spec.Template.Spec.SchedulerName,
+					PriorityClassName:         s.Spec.Template.Spec.PriorityClassName,
+					TopologySpreadConstraints: s.Spec.Template.Spec.TopologySpreadConstraints,
 				},
 			},
 			UpdateStrategy: s.Spec.UpdateStrategy,
@@ -234,21 +251,58 @@ func getDeploymentAllowFields(d *v1.Deployment) *v1.Deployment {
 			Replicas: d.Spec.Replicas,
 			Template: corev1.PodTemplateSpec{
 				Spec: corev1.PodSpec{
-					Volumes:          d.Spec.Template.Spec.Volumes,
-					InitContainers:   d.Spec.Template.Spec.InitContainers,
-					Containers:       d.Spec.Template.Spec.Containers,
-					ImagePullSecrets: d.Spec.Template.Spec.ImagePullSecrets,
-					NodeSelector:     d.Spec.Template.Spec.NodeSelector,
-					Tolerations:      d.Spec.Template.Spec.Tolerations,
-					Affinity:         d.Spec.Template.Spec.Affinity,
-					HostAliases:      d.Spec.Template.Spec.HostAliases,
-					Hostname:         d.Spec.Template.Spec.Hostname,
-					NodeName:         d.Spec.Template.Spec.NodeName,
-					HostNetwork:      d.Spec.Template.Spec.HostNetwork,
-					SchedulerName:    d.Spec.Template.Spec.SchedulerName,
+					Volumes:                   d.Spec.Template.Spec.Volumes,
+					InitContainers:            d.Spec.Template.Spec.InitContainers,
+					Containers:                d.Spec.Template.Spec.Containers,
+					ImagePullSecrets:          d.Spec.Template.Spec.ImagePullSecrets,
+					NodeSelector:              d.Spec.Template.Spec.NodeSelector,
+					Tolerations:               d.Spec.Template.Spec.Tolerations,
+					Affinity:                  d.Spec.Template.Spec.Affinity,
+					HostAliases:               d.Spec.Template.Spec.HostAliases,
+					Hostname:                  d.Spec.Template.Spec.Hostname,
+					NodeName:                  d.Spec.Template.Spec.NodeName,
+					HostNetwork:               d.Spec.Template.Spec.HostNetwork,
+					SchedulerName:             d.Spec.Template.Spec.SchedulerName,
+					PriorityClassName:         d.Spec.Template.Spec.PriorityClassName,
+					TopologySpreadConstraints: d.Spec.Template.Spec.TopologySpreadConstraints,
+				},
+				ObjectMeta: d.Spec.Template.ObjectMeta,
+			},
+		},
+	}
+}
+
+//daemonset label can not be patch
+func getDaemonSetModifiedConfiguration(old, new *v1.DaemonSet) ([]byte, error) {
+	old.Status = new.Status
+	oldNeed := getDaemonSetAllowFields(old)
+	newNeed := getDaemonSetAllowFields(new)
+	return getchange(oldNeed, newNeed)
+}
+
+// updates to daemonset spec for fields other than 'template' and 'updateStrategy' are forbidden.
+func getDaemonSetAllowFields(d *v1.DaemonSet) *v1.DaemonSet {
+	return &v1.DaemonSet{
+		Spec: v1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes:                   d.Spec.Template.Spec.Volumes,
+					InitContainers:            d.Spec.Template.Spec.InitContainers,
+					Containers:                d.Spec.Template.Spec.Containers,
+					ImagePullSecrets:          d.Spec.Template.Spec.ImagePullSecrets,
+					NodeSelector:              d.Spec.Template.Spec.NodeSelector,
+					Tolerations:               d.Spec.Template.Spec.Tolerations,
+					Affinity:                  d.Spec.Template.Spec.Affinity,
+					HostAliases:               d.Spec.Template.Spec.HostAliases,
+					Hostname:                  d.Spec.Template.Spec.Hostname,
+					HostNetwork:               d.Spec.Template.Spec.HostNetwork,
+					SchedulerName:             d.Spec.Template.Spec.SchedulerName,
+					PriorityClassName:         d.Spec.Template.Spec.PriorityClassName,
+					TopologySpreadConstraints: d.Spec.Template.Spec.TopologySpreadConstraints,
 				},
 				ObjectMeta: d.Spec.Template.ObjectMeta,
 			},
+			UpdateStrategy: d.Spec.UpdateStrategy,
 		},
 	}
 }
@@ -58,7 +58,7 @@ func (a *AppService) IsClosed() bool {
 			return true
 		}
 	} else {
-		if a.IsEmpty() && a.statefulset == nil && a.deployment == nil {
+		if a.IsEmpty() && a.statefulset == nil && a.deployment == nil && a.daemonset == nil {
 			return true
 		}
 		if a.IsEmpty() && a.statefulset != nil && a.statefulset.ResourceVersion == "" {
@@ -70,6 +70,9 @@ func (a *AppService) IsClosed() bool {
 		if a.IsEmpty() && a.virtualmachine != nil && a.virtualmachine.ResourceVersion == "" {
 			return true
 		}
+		if a.IsEmpty() && a.daemonset != nil && a.daemonset.ResourceVersion == "" {
+			return true
+		}
 	}
 	return false
 }
@@ -229,6 +232,9 @@ func (a *AppService) GetServiceStatus() string {
 		}
 		return RUNNING
 	}
+	if a.daemonset != nil {
+		return a.daemonSetStatus()
+	}
 	if a.statefulset == nil && a.deployment == nil && len(a.pods) > 0 {
 		return STOPPING
 	}
@@ -287,6 +293,32 @@ func (a *AppService) GetServiceStatus() string {
 	return UNKNOW
 }
 
+// daemonSetStatus the status of a daemonset depends on the number of the nodes it is scheduled to
+// rather than the replicas of the component
+func (a *AppService) daemonSetStatus() string {
+	status := a.daemonset.Status
+	if status.ObservedGeneration < a.daemonset.Generation {
+		return STARTING
+	}
+	if status.DesiredNumberScheduled == 0 {
+		// no node matches the node selection of the daemonset
+		return ABNORMAL
+	}
+	if status.NumberReady >= status.DesiredNumberScheduled {
+		if status.UpdatedNumberScheduled < status.DesiredNumberScheduled || !a.UpgradeComlete() {
+			return UPGRADE
+		}
+		return RUNNING
+	}
+	if isHaveTerminatedContainer(a.pods) {
+		if status.NumberReady > 0 {
+			return SOMEABNORMAL
+		}
+		return ABNORMAL
+	}
+	return STARTING
+}
+
 func isHaveTerminatedContainer(pods []*corev1.Pod) bool {
 	for _, pod := range pods {
 		for _, con := range pod.Status.ContainerStatuses {
@@ -330,6 +362,12 @@ func (a *AppService) Ready() bool {
 			return true
 		}
 	}
+	if a.daemonset != nil {
+		status := a.daemonset.Status
+		if status.DesiredNumberScheduled > 0 && status.NumberReady >= status.DesiredNumberScheduled {
+			return true
+		}
+	}
 	return false
 }
 
@@ -349,6 +387,12 @@ func (a *AppService) IsWaitting() bool {
 			return false
 		}
 	}
+	if a.daemonset != nil {
+		initcontainer = a.daemonset.Spec.Template.Spec.InitContainers
+		if len(initcontainer) == 0 {
+			return false
+		}
+	}
 	var haveProbeInitContainer bool
 	for _, init := range initcontainer {
 		if init.Image == GetProbeMeshImageName() || init.Image == GetOnlineProbeMeshImageName() {
@@ -380,6 +424,9 @@ func (a *AppService) GetReadyReplicas() int32 {
 	if a.deployment != nil {
 		return a.deployment.Status.ReadyReplicas
 	}
+	if a.daemonset != nil {
+		return a.daemonset.Status.NumberReady
+	}
 	return 0
 }
 
@@ -391,6 +438,9 @@ func (a *AppService) GetRunningVersion() string {
 	if a.deployment != nil {
 		return a.deployment.Labels["version"]
 	}
+	if a.daemonset != nil {
+		return a.daemonset.Labels["version"]
+	}
 	return ""
 }
 
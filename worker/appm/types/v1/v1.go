@@ -42,9 +42,11 @@ import (
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // EventType type of event
@@ -86,6 +88,9 @@ var TypeVirtualMachine AppServiceType = "virtualmachine"
 // TypeCronJob deployment
 var TypeCronJob AppServiceType = "cronjob"
 
+// TypeDaemonSet daemonset
+var TypeDaemonSet AppServiceType = "daemonset"
+
 // TypeReplicationController rc
 var TypeReplicationController AppServiceType = "replicationcontroller"
 
@@ -107,6 +112,7 @@ type AppServiceBase struct {
 	ServiceAlias     string
 	ServiceType      AppServiceType
 	ServiceKind      model.ServiceKind
+	ExtendMethod     string
 	discoveryCfg     *dbmodel.ThirdPartySvcDiscoveryCfg
 	DeployVersion    string
 	ContainerCPU     int
@@ -121,6 +127,7 @@ type AppServiceBase struct {
 	Dependces          []string
 	ExtensionSet       map[string]string
 	GovernanceMode     string
+	MeshMTLSMode       string
 	K8sApp             string
 	K8sComponentName   string
 	DryRun             bool
@@ -182,6 +189,7 @@ type AppService struct {
 	tenant           *corev1.Namespace
 	statefulset      *v1.StatefulSet
 	deployment       *v1.Deployment
+	daemonset        *v1.DaemonSet
 	virtualmachine   *kubevirtv1.VirtualMachine
 	job              *batchv1.Job
 	cronjob          *batchv1.CronJob
@@ -205,6 +213,7 @@ type AppService struct {
 	pods             []*corev1.Pod
 	claims           []*corev1.PersistentVolumeClaim
 	serviceMonitor   []*monitorv1.ServiceMonitor
+	disruptionBudget *policyv1.PodDisruptionBudget
 	// claims that needs to be created manually
 	claimsmanual     []*corev1.PersistentVolumeClaim
 	podMemoryRequest int64
@@ -217,6 +226,8 @@ type AppService struct {
 	envVarSecrets    []*corev1.Secret
 	// custom componentdefinition output manifests
 	manifests []*unstructured.Unstructured
+	// the kinds of the mesh objects of the governance mode, the ones not in the manifests are deleted
+	meshPolicyKinds []schema.GroupVersionKind
 }
 
 // CacheKey app cache key
@@ -278,6 +289,36 @@ func (a *AppService) DeleteDeployment(d *v1.Deployment) {
 	a.deployment = nil
 }
 
+// GetDaemonSet get kubernetes daemonset model
+func (a AppService) GetDaemonSet() *v1.DaemonSet {
+	return a.daemonset
+}
+
+// SetDaemonSet set kubernetes daemonset model
+func (a *AppService) SetDaemonSet(d *v1.DaemonSet) {
+	a.daemonset = d
+	a.workload = d
+	if v, ok := d.Spec.Template.Labels["version"]; ok && v != "" {
+		a.DeployVersion = v
+	}
+	a.calculateComponentMemoryRequest()
+}
+
+// DeleteDaemonSet delete kubernetes daemonset model
+func (a *AppService) DeleteDaemonSet(d *v1.DaemonSet) {
+	a.daemonset = nil
+}
+
+// GetPodDisruptionBudget get the pod disruption budget of the component
+func (a AppService) GetPodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	return a.disruptionBudget
+}
+
+// SetPodDisruptionBudget set the pod disruption budget of the component
+func (a *AppService) SetPodDisruptionBudget(pdb *policyv1.PodDisruptionBudget) {
+	a.disruptionBudget = pdb
+}
+
 // DeleteJob delete kubernetes job model
 func (a *AppService) DeleteJob(d *batchv1.Job) {
 	a.job = nil
@@ -630,6 +671,12 @@ func (a *AppService) calculateComponentMemoryRequest() {
 			cpuRequest += c.Resources.Requests.Cpu().MilliValue()
 		}
 	}
+	if a.daemonset != nil {
+		for _, c := range a.daemonset.Spec.Template.Spec.Containers {
+			memoryRequest += c.Resources.Requests.Memory().Value() / 1024 / 1024
+			cpuRequest += c.Resources.Requests.Cpu().MilliValue()
+		}
+	}
 	a.podMemoryRequest = memoryRequest
 	a.podCPURequest = cpuRequest
 }
@@ -643,6 +690,9 @@ func (a *AppService) SetPodAndVMTemplate(d corev1.PodTemplateSpec, vmt kubevirtv
 	if a.deployment != nil {
 		a.deployment.Spec.Template = d
 	}
+	if a.daemonset != nil {
+		a.daemonset.Spec.Template = d
+	}
 	if a.job != nil {
 		a.job.Spec.Template = d
 	}
@@ -663,6 +713,9 @@ func (a *AppService) GetPodTemplate() *corev1.PodTemplateSpec {
 	if a.deployment != nil {
 		return &a.deployment.Spec.Template
 	}
+	if a.daemonset != nil {
+		return &a.daemonset.Spec.Template
+	}
 	if a.job != nil {
 		return &a.job.Spec.Template
 	}
@@ -1118,6 +1171,16 @@ func (a *AppService) SetManifests(manifests []*unstructured.Unstructured) {
 	a.manifests = manifests
 }
 
+// GetMeshPolicyKinds get the kinds of the mesh objects of the component
+func (a *AppService) GetMeshPolicyKinds() []schema.GroupVersionKind {
+	return a.meshPolicyKinds
+}
+
+// SetMeshPolicyKinds set the kinds of the mesh objects of the component
+func (a *AppService) SetMeshPolicyKinds(kinds []schema.GroupVersionKind) {
+	a.meshPolicyKinds = kinds
+}
+
 // SetWorkload set component workload
 func (a *AppService) SetWorkload(workload client.Object) {
 	a.workload = workload
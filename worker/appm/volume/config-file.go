@@ -23,6 +23,7 @@ import (
 	"path"
 
 	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/worker/appm/secretstore"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -57,6 +58,11 @@ func (v *ConfigFileVolume) CreateVolume(define *Define) error {
 		logrus.Errorf("error getting config file by volume name(%s): %v", v.svm.VolumeName, err)
 		return fmt.Errorf("error getting config file by volume name(%s): %v", v.svm.VolumeName, err)
 	}
+	if cf.IsExternalSecret() {
+		// the content is resolved into the secret of the component by the conversion of the external secrets
+		define.SetVolumeSecret(secretstore.SecretName(v.as.GetK8sWorkloadName()), secretstore.FileKey(v.svm.VolumeName), v.svm.VolumePath, v.svm.Mode)
+		return nil
+	}
 	cmap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      util.NewUUID(),
@@ -86,6 +92,9 @@ func (v *ConfigFileVolume) CreateDependVolume(define *Define) error {
 	if err != nil {
 		return fmt.Errorf("error getting TenantServiceConfigFile according to volumeName(%s): %v", v.smr.VolumeName, err)
 	}
+	if cf.IsExternalSecret() {
+		return fmt.Errorf("the config file %s of the dependency references the external secret store, it can not be shared", v.smr.VolumeName)
+	}
 
 	cmap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,517 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package volume
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	snapshotclient "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// SnapshotIDLabel the label of the csi volume snapshots taken for a component volume snapshot
+	SnapshotIDLabel = "snapshot_id"
+	// snapshotSourceClaimAnnotation the claim the csi volume snapshot is taken from
+	snapshotSourceClaimAnnotation    = "rainbond.io/source-claim"
+	isDefaultSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/is-default-class"
+	// restoreClaimTimeout the time restoring a claim takes at most
+	restoreClaimTimeout = 5 * time.Minute
+)
+
+// ErrSnapshotNotSupported the storage of the volume has no csi driver supporting snapshots
+var ErrSnapshotNotSupported = errors.New("the storage of the volume does not support snapshots")
+
+// SnapshotManager creates, restores and deletes the csi volume snapshots of the component volumes.
+// A component volume snapshot consists of a csi VolumeSnapshot for every claim of the volume,
+// such as the claims of every pod of the statefulset.
+type SnapshotManager struct {
+	kubeClient     kubernetes.Interface
+	snapshotClient snapshotclient.Interface
+}
+
+// NewSnapshotManager creates a snapshot manager
+func NewSnapshotManager(kubeClient kubernetes.Interface, snapshotClient snapshotclient.Interface) *SnapshotManager {
+	return &SnapshotManager{
+		kubeClient:     kubeClient,
+		snapshotClient: snapshotClient,
+	}
+}
+
+// ListVolumeClaims lists the claims of the component volume, sorted by name
+func (s *SnapshotManager) ListVolumeClaims(ctx context.Context, namespace, serviceID, volumeName string) ([]corev1.PersistentVolumeClaim, error) {
+	selector := labels.SelectorFromSet(map[string]string{"service_id": serviceID, "volume_name": volumeName})
+	claims, err := s.kubeClient.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(claims.Items, func(i, j int) bool {
+		return claims.Items[i].Name < claims.Items[j].Name
+	})
+	return claims.Items, nil
+}
+
+// CreateSnapshot takes a csi volume snapshot for every claim of the volume
+func (s *SnapshotManager) CreateSnapshot(ctx context.Context, namespace string, snapshot *dbmodel.TenantServiceVolumeSnapshot) error {
+	claims, err := s.ListVolumeClaims(ctx, namespace, snapshot.ServiceID, snapshot.VolumeName)
+	if err != nil {
+		return err
+	}
+	if len(claims) == 0 {
+		return fmt.Errorf("no claim found of volume %s, the component has not been started", snapshot.VolumeName)
+	}
+	class, err := s.snapshotClassOf(ctx, &claims[0])
+	if err != nil {
+		return err
+	}
+	for i, claim := range claims {
+		claimName := claim.Name
+		vs := &snapshotv1.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", snapshot.SnapshotID, i),
+				Namespace: namespace,
+				Labels: map[string]string{
+					"creator":       "Rainbond",
+					"service_id":    snapshot.ServiceID,
+					"volume_name":   snapshot.VolumeName,
+					SnapshotIDLabel: snapshot.SnapshotID,
+				},
+				Annotations: map[string]string{snapshotSourceClaimAnnotation: claimName},
+			},
+			Spec: snapshotv1.VolumeSnapshotSpec{
+				Source:                  snapshotv1.VolumeSnapshotSource{PersistentVolumeClaimName: &claimName},
+				VolumeSnapshotClassName: &class.Name,
+			},
+		}
+		_, err := s.snapshotClient.SnapshotV1().VolumeSnapshots(namespace).Create(ctx, vs, metav1.CreateOptions{})
+		if err != nil && !k8sErrors.IsAlreadyExists(err) {
+			return fmt.Errorf("create volume snapshot of claim %s: %v", claimName, err)
+		}
+	}
+	snapshot.SnapshotClass = class.Name
+	snapshot.ClaimCount = len(claims)
+	return nil
+}
+
+// snapshotClassOf finds the snapshot class of the csi driver provisioning the claim, the default class is preferred.
+func (s *SnapshotManager) snapshotClassOf(ctx context.Context, claim *corev1.PersistentVolumeClaim) (*snapshotv1.VolumeSnapshotClass, error) {
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return nil, ErrSnapshotNotSupported
+	}
+	sc, err := s.kubeClient.StorageV1().StorageClasses().Get(ctx, *claim.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get storage class %s: %v", *claim.Spec.StorageClassName, err)
+	}
+	classes, err := s.snapshotClient.SnapshotV1().VolumeSnapshotClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, ErrSnapshotNotSupported
+		}
+		return nil, err
+	}
+	var found *snapshotv1.VolumeSnapshotClass
+	for i := range classes.Items {
+		class := &classes.Items[i]
+		if class.Driver != sc.Provisioner {
+			continue
+		}
+		if class.Annotations[isDefaultSnapshotClassAnnotation] == "true" {
+			return class, nil
+		}
+		if found == nil {
+			found = class
+		}
+	}
+	if found == nil {
+		return nil, ErrSnapshotNotSupported
+	}
+	return found, nil
+}
+
+func (s *SnapshotManager) listVolumeSnapshots(ctx context.Context, namespace, snapshotID string) ([]snapshotv1.VolumeSnapshot, error) {
+	selector := labels.SelectorFromSet(map[string]string{SnapshotIDLabel: snapshotID})
+	vss, err := s.snapshotClient.SnapshotV1().VolumeSnapshots(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(vss.Items, func(i, j int) bool {
+		return vss.Items[i].Name < vss.Items[j].Name
+	})
+	return vss.Items, nil
+}
+
+// SyncSnapshot updates the status of the snapshot from its csi volume snapshots, returns whether the status changes.
+// The snapshot is ready when all the csi volume snapshots are ready to use.
+func (s *SnapshotManager) SyncSnapshot(ctx context.Context, namespace string, snapshot *dbmodel.TenantServiceVolumeSnapshot) (bool, error) {
+	vss, err := s.listVolumeSnapshots(ctx, namespace, snapshot.SnapshotID)
+	if err != nil {
+		return false, err
+	}
+	status, message, restoreSize := summarizeVolumeSnapshots(vss, snapshot.ClaimCount)
+	if status == snapshot.Status && message == snapshot.Message {
+		return false, nil
+	}
+	snapshot.Status = status
+	snapshot.Message = message
+	if status == dbmodel.VolumeSnapshotStatusReady {
+		now := time.Now()
+		snapshot.ReadyTime = &now
+		snapshot.RestoreSize = restoreSize
+	}
+	return true, nil
+}
+
+func summarizeVolumeSnapshots(vss []snapshotv1.VolumeSnapshot, claimCount int) (status, message string, restoreSize int64) {
+	if len(vss) < claimCount {
+		return dbmodel.VolumeSnapshotStatusFailed, fmt.Sprintf("%d of %d volume snapshots are lost", claimCount-len(vss), claimCount), 0
+	}
+	ready := 0
+	for _, vs := range vss {
+		if vs.Status == nil {
+			continue
+		}
+		if vs.Status.Error != nil && vs.Status.Error.Message != nil {
+			return dbmodel.VolumeSnapshotStatusFailed, fmt.Sprintf("volume snapshot %s: %s", vs.Name, *vs.Status.Error.Message), 0
+		}
+		if vs.Status.ReadyToUse != nil && *vs.Status.ReadyToUse {
+			ready++
+			if vs.Status.RestoreSize != nil && vs.Status.RestoreSize.Value() > restoreSize {
+				restoreSize = vs.Status.RestoreSize.Value()
+			}
+		}
+	}
+	if ready < len(vss) {
+		return dbmodel.VolumeSnapshotStatusCreating, "", 0
+	}
+	return dbmodel.VolumeSnapshotStatusReady, "", restoreSize
+}
+
+// RestoreClaims recreates the claims with the data of the snapshot, so the component owning the claims must be closed.
+// The volumes of the existing claims are retained until the restored claims are bound, and are bound to the
+// claims again if the restore fails. Every claim is restored within its own timeout.
+// It returns the volumes still retained, whose restored claims are bound only after the pods using them are scheduled.
+func (s *SnapshotManager) RestoreClaims(ctx context.Context, namespace string, snapshot *dbmodel.TenantServiceVolumeSnapshot, claims []corev1.PersistentVolumeClaim) ([]string, error) {
+	vss, err := s.listVolumeSnapshots(ctx, namespace, snapshot.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+	if len(vss) == 0 {
+		return nil, fmt.Errorf("the volume snapshots of %s not found", snapshot.SnapshotID)
+	}
+	if len(claims) == 0 {
+		return nil, fmt.Errorf("no claim to restore")
+	}
+	if err := s.checkRestorable(ctx, snapshot, &claims[0]); err != nil {
+		return nil, err
+	}
+	var retained []string
+	for i := range claims {
+		claimCtx, cancel := context.WithTimeout(ctx, restoreClaimTimeout)
+		volumeName, err := s.restoreClaim(claimCtx, namespace, &claims[i], snapshotForClaim(vss, claims[i].Name))
+		cancel()
+		if err != nil {
+			return retained, err
+		}
+		if volumeName != "" {
+			retained = append(retained, volumeName)
+		}
+	}
+	return retained, nil
+}
+
+// restoreClaim replaces the claim with the one restored from the volume snapshot. It returns the name of
+// the previous volume if it is still retained.
+func (s *SnapshotManager) restoreClaim(ctx context.Context, namespace string, claim *corev1.PersistentVolumeClaim, vs *snapshotv1.VolumeSnapshot) (string, error) {
+	claims := s.kubeClient.CoreV1().PersistentVolumeClaims(namespace)
+	old, err := claims.Get(ctx, claim.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			return "", fmt.Errorf("get claim %s: %v", claim.Name, err)
+		}
+		old = nil
+	}
+	var pv *corev1.PersistentVolume
+	if old != nil && old.Spec.VolumeName != "" {
+		pv, err = s.kubeClient.CoreV1().PersistentVolumes().Get(ctx, old.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("get volume %s: %v", old.Spec.VolumeName, err)
+		}
+		// keep the data until the restored claim is bound
+		if err := s.setReclaimPolicy(ctx, pv.Name, corev1.PersistentVolumeReclaimRetain); err != nil {
+			return "", err
+		}
+	}
+	if err := s.deleteClaim(ctx, namespace, claim.Name); err != nil {
+		return "", s.recoverClaim(namespace, old, pv, err)
+	}
+	restored := restoredClaim(claim, vs)
+	restored.Namespace = namespace
+	if _, err := claims.Create(ctx, restored, metav1.CreateOptions{}); err != nil {
+		return "", s.recoverClaim(namespace, old, pv, fmt.Errorf("restore claim %s: %v", claim.Name, err))
+	}
+	immediate, err := s.bindsImmediately(ctx, claim)
+	if err != nil {
+		return "", s.recoverClaim(namespace, old, pv, err)
+	}
+	if !immediate {
+		if pv != nil && pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+			return pv.Name, nil
+		}
+		return "", nil
+	}
+	err = wait.PollImmediateUntil(2*time.Second, func() (bool, error) {
+		c, err := claims.Get(ctx, claim.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return c.Status.Phase == corev1.ClaimBound, nil
+	}, ctx.Done())
+	if err != nil {
+		return "", s.recoverClaim(namespace, old, pv, fmt.Errorf("wait for claim %s to be bound: %v", claim.Name, err))
+	}
+	if pv != nil {
+		if err := s.setReclaimPolicy(ctx, pv.Name, pv.Spec.PersistentVolumeReclaimPolicy); err != nil {
+			logrus.Warningf("reset the reclaim policy of volume %s: %v", pv.Name, err)
+			return pv.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// recoverClaim binds the claim to its previous volume again after the restore fails, the cause is returned.
+func (s *SnapshotManager) recoverClaim(namespace string, old *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume, cause error) error {
+	if old == nil || pv == nil {
+		return cause
+	}
+	// the context of the claim may have expired
+	ctx, cancel := context.WithTimeout(context.Background(), restoreClaimTimeout)
+	defer cancel()
+	claims := s.kubeClient.CoreV1().PersistentVolumeClaims(namespace)
+	current, err := claims.Get(ctx, old.Name, metav1.GetOptions{})
+	if err == nil && current.UID == old.UID {
+		// the claim is not deleted yet
+		if err := s.setReclaimPolicy(ctx, pv.Name, pv.Spec.PersistentVolumeReclaimPolicy); err != nil {
+			logrus.Warningf("reset the reclaim policy of volume %s: %v", pv.Name, err)
+		}
+		return cause
+	}
+	if err := s.deleteClaim(ctx, namespace, old.Name); err != nil {
+		return fmt.Errorf("%v, and the volume %s is retained: %v", cause, pv.Name, err)
+	}
+	// make the released volume available to the recreated claim
+	patch := []byte(`{"spec":{"claimRef":null}}`)
+	if _, err := s.kubeClient.CoreV1().PersistentVolumes().Patch(ctx, pv.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("%v, and the volume %s is retained: %v", cause, pv.Name, err)
+	}
+	recovered := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        old.Name,
+			Namespace:   namespace,
+			Labels:      old.Labels,
+			Annotations: userAnnotations(old.Annotations),
+		},
+		Spec: *old.Spec.DeepCopy(),
+	}
+	recovered.Spec.VolumeName = pv.Name
+	if _, err := claims.Create(ctx, recovered, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("%v, and the volume %s is retained: %v", cause, pv.Name, err)
+	}
+	if err := s.setReclaimPolicy(ctx, pv.Name, pv.Spec.PersistentVolumeReclaimPolicy); err != nil {
+		logrus.Warningf("reset the reclaim policy of volume %s: %v", pv.Name, err)
+	}
+	return cause
+}
+
+func (s *SnapshotManager) setReclaimPolicy(ctx context.Context, name string, policy corev1.PersistentVolumeReclaimPolicy) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"persistentVolumeReclaimPolicy":%q}}`, policy))
+	if _, err := s.kubeClient.CoreV1().PersistentVolumes().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("set the reclaim policy of volume %s to %s: %v", name, policy, err)
+	}
+	return nil
+}
+
+// bindsImmediately returns whether the storage class of the claim binds the claims without waiting for the pods
+func (s *SnapshotManager) bindsImmediately(ctx context.Context, claim *corev1.PersistentVolumeClaim) (bool, error) {
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	sc, err := s.kubeClient.StorageV1().StorageClasses().Get(ctx, *claim.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("get storage class %s: %v", *claim.Spec.StorageClassName, err)
+	}
+	return sc.VolumeBindingMode == nil || *sc.VolumeBindingMode == storagev1.VolumeBindingImmediate, nil
+}
+
+// checkRestorable checks the claim is provisioned by the csi driver which takes the snapshot
+func (s *SnapshotManager) checkRestorable(ctx context.Context, snapshot *dbmodel.TenantServiceVolumeSnapshot, claim *corev1.PersistentVolumeClaim) error {
+	class, err := s.snapshotClassOf(ctx, claim)
+	if err != nil {
+		return err
+	}
+	if snapshot.SnapshotClass == "" || class.Name == snapshot.SnapshotClass {
+		return nil
+	}
+	origin, err := s.snapshotClient.SnapshotV1().VolumeSnapshotClasses().Get(ctx, snapshot.SnapshotClass, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get snapshot class %s: %v", snapshot.SnapshotClass, err)
+	}
+	if origin.Driver != class.Driver {
+		return fmt.Errorf("the snapshot is taken by %s, can not be restored to the storage of %s", origin.Driver, class.Driver)
+	}
+	return nil
+}
+
+func (s *SnapshotManager) deleteClaim(ctx context.Context, namespace, name string) error {
+	err := s.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("delete claim %s: %v", name, err)
+	}
+	// the claim is deleted after the pods using it are gone
+	return wait.PollImmediate(2*time.Second, 2*time.Minute, func() (bool, error) {
+		_, err := s.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// snapshotForClaim finds the volume snapshot taken from the claim, or from the claim of the pod with the same ordinal.
+func snapshotForClaim(vss []snapshotv1.VolumeSnapshot, claimName string) *snapshotv1.VolumeSnapshot {
+	for i := range vss {
+		if vss[i].Annotations[snapshotSourceClaimAnnotation] == claimName {
+			return &vss[i]
+		}
+	}
+	if idx := strings.LastIndex(claimName, "-"); idx > 0 {
+		ordinal := claimName[idx:]
+		for i := range vss {
+			if strings.HasSuffix(vss[i].Annotations[snapshotSourceClaimAnnotation], ordinal) {
+				return &vss[i]
+			}
+		}
+	}
+	return &vss[0]
+}
+
+func restoredClaim(claim *corev1.PersistentVolumeClaim, vs *snapshotv1.VolumeSnapshot) *corev1.PersistentVolumeClaim {
+	annotations := userAnnotations(claim.Annotations)
+	annotations[snapshotSourceClaimAnnotation] = vs.Annotations[snapshotSourceClaimAnnotation]
+	resources := *claim.Spec.Resources.DeepCopy()
+	if vs.Status != nil && vs.Status.RestoreSize != nil {
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		if request, ok := resources.Requests[corev1.ResourceStorage]; !ok || request.Cmp(*vs.Status.RestoreSize) < 0 {
+			resources.Requests[corev1.ResourceStorage] = vs.Status.RestoreSize.DeepCopy()
+		}
+	}
+	apiGroup := snapshotv1.GroupName
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        claim.Name,
+			Labels:      claim.Labels,
+			Annotations: annotations,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      claim.Spec.AccessModes,
+			StorageClassName: claim.Spec.StorageClassName,
+			VolumeMode:       claim.Spec.VolumeMode,
+			Resources:        resources,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     "VolumeSnapshot",
+				Name:     vs.Name,
+			},
+		},
+	}
+}
+
+// userAnnotations returns the annotations of the claim except the ones of the binding and provisioning set by kubernetes
+func userAnnotations(claimAnnotations map[string]string) map[string]string {
+	annotations := make(map[string]string)
+	for k, v := range claimAnnotations {
+		if strings.HasPrefix(k, "pv.kubernetes.io/") || strings.HasPrefix(k, "volume.beta.kubernetes.io/") || strings.HasPrefix(k, "volume.kubernetes.io/") {
+			continue
+		}
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// DeleteSnapshot deletes the csi volume snapshots of the snapshot
+func (s *SnapshotManager) DeleteSnapshot(ctx context.Context, namespace, snapshotID string) error {
+	selector := labels.SelectorFromSet(map[string]string{SnapshotIDLabel: snapshotID})
+	return s.snapshotClient.SnapshotV1().VolumeSnapshots(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: selector.String()})
+}
+
+// DeleteServiceSnapshots deletes all the csi volume snapshots of the component
+func (s *SnapshotManager) DeleteServiceSnapshots(ctx context.Context, namespace, serviceID string) error {
+	selector := labels.SelectorFromSet(map[string]string{"creator": "Rainbond", "service_id": serviceID})
+	return s.snapshotClient.SnapshotV1().VolumeSnapshots(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: selector.String()})
+}
+
+// ClaimsOfVolume returns the claims the component creates for the volume.
+// The claims of the statefulset are named by the claim template, the statefulset and the ordinal of the pod.
+func ClaimsOfVolume(as *v1.AppService, volumeName string) []corev1.PersistentVolumeClaim {
+	var claims []corev1.PersistentVolumeClaim
+	if sts := as.GetStatefulSet(); sts != nil {
+		replicas := 1
+		if sts.Spec.Replicas != nil && *sts.Spec.Replicas > 1 {
+			replicas = int(*sts.Spec.Replicas)
+		}
+		for _, tpl := range sts.Spec.VolumeClaimTemplates {
+			if tpl.Labels["volume_name"] != volumeName {
+				continue
+			}
+			for i := 0; i < replicas; i++ {
+				claim := tpl.DeepCopy()
+				claim.Name = fmt.Sprintf("%s-%s-%d", tpl.Name, sts.Name, i)
+				claim.Namespace = sts.Namespace
+				claims = append(claims, *claim)
+			}
+		}
+		return claims
+	}
+	for _, claim := range as.GetClaimsManually() {
+		if claim.Labels["volume_name"] == volumeName {
+			claims = append(claims, *claim.DeepCopy())
+		}
+	}
+	return claims
+}
@@ -0,0 +1,184 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package volume
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/db/model"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newVolumeSnapshot(name, claim string, ready bool, size string) snapshotv1.VolumeSnapshot {
+	vs := snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{snapshotSourceClaimAnnotation: claim},
+		},
+		Status: &snapshotv1.VolumeSnapshotStatus{ReadyToUse: &ready},
+	}
+	if size != "" {
+		quantity := resource.MustParse(size)
+		vs.Status.RestoreSize = &quantity
+	}
+	return vs
+}
+
+func TestSummarizeVolumeSnapshots(t *testing.T) {
+	vss := []snapshotv1.VolumeSnapshot{
+		newVolumeSnapshot("s-0", "data-sts-0", true, "1Gi"),
+		newVolumeSnapshot("s-1", "data-sts-1", false, ""),
+	}
+	status, _, _ := summarizeVolumeSnapshots(vss, 2)
+	assert.Equal(t, model.VolumeSnapshotStatusCreating, status)
+
+	vss[1] = newVolumeSnapshot("s-1", "data-sts-1", true, "2Gi")
+	status, _, size := summarizeVolumeSnapshots(vss, 2)
+	assert.Equal(t, model.VolumeSnapshotStatusReady, status)
+	assert.Equal(t, int64(2<<30), size)
+
+	status, _, _ = summarizeVolumeSnapshots(vss[:1], 2)
+	assert.Equal(t, model.VolumeSnapshotStatusFailed, status)
+
+	message := "driver error"
+	vss[1].Status.Error = &snapshotv1.VolumeSnapshotError{Message: &message}
+	status, msg, _ := summarizeVolumeSnapshots(vss, 2)
+	assert.Equal(t, model.VolumeSnapshotStatusFailed, status)
+	assert.Contains(t, msg, message)
+}
+
+func TestSnapshotForClaim(t *testing.T) {
+	vss := []snapshotv1.VolumeSnapshot{
+		newVolumeSnapshot("s-0", "data-sts-0", true, ""),
+		newVolumeSnapshot("s-1", "data-sts-1", true, ""),
+	}
+	assert.Equal(t, "s-1", snapshotForClaim(vss, "data-sts-1").Name)
+	// restore into another component, matched by the ordinal of the pod
+	assert.Equal(t, "s-1", snapshotForClaim(vss, "data-other-1").Name)
+	assert.Equal(t, "s-0", snapshotForClaim(vss, "data-other-5").Name)
+}
+
+func TestRestoredClaim(t *testing.T) {
+	class := "rbd"
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "data-sts-0",
+			Labels: map[string]string{"volume_name": "data"},
+			Annotations: map[string]string{
+				"pv.kubernetes.io/bind-completed":               "yes",
+				"volume.beta.kubernetes.io/storage-provisioner": "rbd.csi.ceph.com",
+				"app": "demo",
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &class,
+			VolumeName:       "pvc-123",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+	vs := newVolumeSnapshot("s-0", "data-sts-0", true, "2Gi")
+	restored := restoredClaim(claim, &vs)
+	assert.Equal(t, map[string]string{"app": "demo", snapshotSourceClaimAnnotation: "data-sts-0"}, restored.Annotations)
+	assert.Empty(t, restored.Spec.VolumeName)
+	assert.Equal(t, "VolumeSnapshot", restored.Spec.DataSource.Kind)
+	assert.Equal(t, "s-0", restored.Spec.DataSource.Name)
+	request := restored.Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.Equal(t, "2Gi", request.String())
+	// the claim is not changed
+	request = claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.Equal(t, "1Gi", request.String())
+}
+
+func TestClaimsOfVolume(t *testing.T) {
+	replicas := int32(2)
+	as := &v1.AppService{}
+	as.SetStatefulSet(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "sts", Namespace: "tenant"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: map[string]string{"volume_name": "data"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "logs", Labels: map[string]string{"volume_name": "logs"}}},
+			},
+		},
+	})
+	var names []string
+	for _, claim := range ClaimsOfVolume(as, "data") {
+		names = append(names, claim.Name)
+	}
+	assert.Equal(t, []string{"data-sts-0", "data-sts-1"}, names)
+}
+
+func TestRestoreClaimRetainsVolume(t *testing.T) {
+	for _, mode := range []storagev1.VolumeBindingMode{storagev1.VolumeBindingWaitForFirstConsumer, storagev1.VolumeBindingImmediate} {
+		class := "rbd"
+		claim := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data-sts-0", Namespace: "ns", UID: "old"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: &class,
+				VolumeName:       "pvc-123",
+			},
+		}
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-123"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+				ClaimRef:                      &corev1.ObjectReference{Namespace: "ns", Name: "data-sts-0", UID: "old"},
+			},
+		}
+		sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: class}, VolumeBindingMode: &mode}
+		client := fake.NewSimpleClientset(claim, pv, sc)
+		s := NewSnapshotManager(client, nil)
+		vs := newVolumeSnapshot("s-0", "data-sts-0", true, "")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		retained, err := s.restoreClaim(ctx, "ns", claim, &vs)
+		cancel()
+		current, _ := client.CoreV1().PersistentVolumeClaims("ns").Get(context.Background(), "data-sts-0", metav1.GetOptions{})
+		volume, _ := client.CoreV1().PersistentVolumes().Get(context.Background(), "pvc-123", metav1.GetOptions{})
+		if mode == storagev1.VolumeBindingWaitForFirstConsumer {
+			// the restored claim is bound later, the previous volume is retained
+			assert.Nil(t, err)
+			assert.Equal(t, "pvc-123", retained)
+			assert.Equal(t, "s-0", current.Spec.DataSource.Name)
+			assert.Equal(t, corev1.PersistentVolumeReclaimRetain, volume.Spec.PersistentVolumeReclaimPolicy)
+			continue
+		}
+		// the restored claim is never bound by the fake client, the previous volume is bound again
+		assert.NotNil(t, err)
+		assert.Nil(t, current.Spec.DataSource)
+		assert.Equal(t, "pvc-123", current.Spec.VolumeName)
+		assert.Nil(t, volume.Spec.ClaimRef)
+		assert.Equal(t, corev1.PersistentVolumeReclaimDelete, volume.Spec.PersistentVolumeReclaimPolicy)
+	}
+}
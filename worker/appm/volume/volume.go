@@ -269,6 +269,43 @@ func (v *Define) SetVolumeCMap(cmap *corev1.ConfigMap, k, p string, isReadOnly b
 	v.volumes = append(v.volumes, vo)
 }
 
+// SetVolumeSecret mounts the key k of the secret at the path p.
+// The key is mounted with a sub path so the other files of the directory stay
+// visible, kubelet never updates such a file, so the workload is restarted
+// once the secret rotates.
+func (v *Define) SetVolumeSecret(secretName, k, p string, mode *int32) {
+	// a pod may mount several keys of the same secret
+	name := "secret-" + k
+	v.volumeMounts = append(v.volumeMounts, corev1.VolumeMount{
+		MountPath: p,
+		Name:      name,
+		ReadOnly:  true,
+		SubPath:   path.Base(p),
+	})
+	var defaultMode int32 = 0644
+	if mode != nil {
+		// convert int to octal
+		octal, _ := strconv.ParseInt(strconv.Itoa(int(*mode)), 8, 64)
+		defaultMode = int32(octal)
+	}
+	v.volumes = append(v.volumes, corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  secretName,
+				DefaultMode: &defaultMode,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  k,
+						Path: path.Base(p),
+						Mode: &defaultMode,
+					},
+				},
+			},
+		},
+	})
+}
+
 func convertRulesToEnvs(as *v1.AppService, dbmanager db.Manager, ports []*dbmodel.TenantServicesPort) (re []corev1.EnvVar) {
 	defDomain := fmt.Sprintf(".%s.%s.", as.ServiceAlias, as.TenantName)
 	httpRules, _ := dbmanager.HTTPRuleDao().ListByServiceID(as.ServiceID)
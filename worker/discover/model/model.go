@@ -183,6 +183,13 @@ func NewTaskBody(taskType string, body []byte) TaskBody {
 			return nil
 		}
 		return b
+	case "create_volume_snapshot", "restore_volume_snapshot", "delete_volume_snapshot":
+		b := &VolumeSnapshotTaskBody{}
+		err := ffjson.Unmarshal(body, &b)
+		if err != nil {
+			return nil
+		}
+		return b
 	default:
 		return DefaultTaskBody{}
 	}
@@ -250,6 +257,10 @@ type HorizontalScalingTaskBody struct {
 	Replicas  int32  `json:"replicas"`
 	EventID   string `json:"event_id"`
 	Username  string `json:"username"`
+	// RuleID the schedule or rule triggers the scaling
+	RuleID string `json:"rule_id,omitempty"`
+	// RecordType the type of the scaling record, such as schedule and scale_to_zero, defaults to manual
+	RecordType string `json:"record_type,omitempty"`
 }
 
 //VerticalScalingTaskBody 垂直伸缩操作任务主体
@@ -396,6 +407,18 @@ type DeleteK8sResourceTaskBody struct {
 	ResourceYaml string `json:"resource_yaml"`
 }
 
+// VolumeSnapshotTaskBody the body of the tasks creating, restoring and deleting volume snapshots
+type VolumeSnapshotTaskBody struct {
+	TenantID   string `json:"tenant_id"`
+	ServiceID  string `json:"service_id"`
+	SnapshotID string `json:"snapshot_id"`
+	// TargetServiceID the component the snapshot is restored into, defaults to the component of the snapshot
+	TargetServiceID string `json:"target_service_id,omitempty"`
+	// TargetVolumeName the volume the snapshot is restored into, defaults to the volume of the snapshot
+	TargetVolumeName string `json:"target_volume_name,omitempty"`
+	EventID          string `json:"event_id"`
+}
+
 //BuildResource -
 type BuildResource struct {
 	Resource      *unstructured.Unstructured
@@ -44,8 +44,10 @@ import (
 	"github.com/goodrain/rainbond/worker/appm/conversion"
 	"github.com/goodrain/rainbond/worker/appm/store"
 	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	"github.com/goodrain/rainbond/worker/appm/volume"
 	"github.com/goodrain/rainbond/worker/discover/model"
 	"github.com/goodrain/rainbond/worker/gc"
+	snapshotclient "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -63,6 +65,7 @@ type Manager struct {
 	restConfig        *rest.Config
 	mapper            meta.RESTMapper
 	clientset         *kubernetes.Clientset
+	snapshotManager   *volume.SnapshotManager
 }
 
 //NewManager now handle
@@ -85,6 +88,7 @@ func NewManager(ctx context.Context,
 		restConfig:        restConfig,
 		mapper:            mapper,
 		clientset:         clientset,
+		snapshotManager:   volume.NewSnapshotManager(clientset, snapshotclient.NewForConfigOrDie(restConfig)),
 	}
 }
 
@@ -147,6 +151,9 @@ func (m *Manager) AnalystToExec(task *model.Task) error {
 	case "delete_k8s_resource":
 		logrus.Info("start a 'delete_k8s_resource' task worker")
 		return m.DeleteK8sResource(task)
+	case "create_volume_snapshot", "restore_volume_snapshot", "delete_volume_snapshot":
+		logrus.Infof("start a '%s' task worker", task.Type)
+		return m.ExecVolumeSnapshotTask(task)
 	default:
 		logrus.Warning("task can not execute because no type is identified")
 		return nil
@@ -268,6 +275,10 @@ func (m *Manager) horizontalScalingExec(task *model.Task) (err error) {
 		return
 	}
 	oldReplicas, newReplicas := appService.Replicas, service.Replicas
+	recordType := body.RecordType
+	if recordType == "" {
+		recordType = dbmodel.ManualScalingRecord
+	}
 
 	defer func() {
 		desc := "the replicas is scaling from %d to %d successfully"
@@ -280,8 +291,9 @@ func (m *Manager) horizontalScalingExec(task *model.Task) (err error) {
 		}
 		scalingRecord := &dbmodel.TenantServiceScalingRecords{
 			ServiceID:   body.ServiceID,
+			RuleID:      body.RuleID,
 			EventName:   util.NewUUID(),
-			RecordType:  "manual",
+			RecordType:  recordType,
 			Reason:      reason,
 			Count:       1,
 			Description: desc,
@@ -491,6 +503,7 @@ func (m *Manager) ExecServiceGCTask(task *model.Task) error {
 	m.garbageCollector.DelKubernetesObjects(serviceGCReq)
 	m.garbageCollector.DelComponentPkg(serviceGCReq)
 	m.garbageCollector.DelShellPod()
+	m.deleteServiceSnapshots(serviceGCReq.TenantID, serviceGCReq.ServiceID)
 	return nil
 }
 
@@ -532,6 +545,15 @@ func (m *Manager) deleteTenant(task *model.Task) (err error) {
 		return
 	}
 
+	if err = db.GetManager().TenantQuotaDao().DeleteByTenantID(body.TenantID); err != nil {
+		err = fmt.Errorf("delete tenant quota: %v", err)
+		return
+	}
+	if err = db.GetManager().TenantStorageClassQuotaDao().DeleteByTenantID(body.TenantID); err != nil {
+		err = fmt.Errorf("delete tenant storage class quotas: %v", err)
+		return
+	}
+
 	err = db.GetManager().TenantDao().DelByTenantID(body.TenantID)
 	if err != nil {
 		err = fmt.Errorf("delete tenant: %v", err)
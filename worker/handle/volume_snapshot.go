@@ -0,0 +1,181 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package handle
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	dbmodel "github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/event"
+	"github.com/goodrain/rainbond/worker/appm/conversion"
+	"github.com/goodrain/rainbond/worker/appm/volume"
+	"github.com/goodrain/rainbond/worker/discover/model"
+	"github.com/sirupsen/logrus"
+)
+
+// ExecVolumeSnapshotTask executes the tasks creating, restoring and deleting volume snapshots
+func (m *Manager) ExecVolumeSnapshotTask(task *model.Task) error {
+	body, ok := task.Body.(*model.VolumeSnapshotTaskBody)
+	if !ok {
+		logrus.Errorf("exec task '%s'; wrong type: %v", task.Type, reflect.TypeOf(task))
+		return fmt.Errorf("exec task '%s': wrong input", task.Type)
+	}
+	logger := event.GetManager().GetLogger(body.EventID)
+	defer event.GetManager().ReleaseLogger(logger)
+
+	snapshot, err := m.dbmanager.TenantServiceVolumeSnapshotDao().GetBySnapshotID(body.SnapshotID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("get volume snapshot %s failure", body.SnapshotID), event.GetCallbackLoggerOption())
+		return fmt.Errorf("get volume snapshot %s: %v", body.SnapshotID, err)
+	}
+	tenant, err := m.dbmanager.TenantDao().GetTenantByUUID(snapshot.TenantID)
+	if err != nil {
+		logger.Error("get tenant of the volume snapshot failure", event.GetCallbackLoggerOption())
+		return fmt.Errorf("get tenant %s: %v", snapshot.TenantID, err)
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Minute)
+	defer cancel()
+
+	switch task.Type {
+	case "create_volume_snapshot":
+		err = m.createVolumeSnapshot(ctx, tenant.Namespace, snapshot, logger)
+	case "restore_volume_snapshot":
+		// every claim is restored within its own timeout
+		err = m.restoreVolumeSnapshot(m.ctx, tenant.Namespace, snapshot, body, logger)
+	case "delete_volume_snapshot":
+		err = m.deleteVolumeSnapshot(ctx, tenant.Namespace, snapshot, logger)
+	}
+	if err != nil {
+		logrus.Errorf("exec task '%s' of volume snapshot %s: %v", task.Type, snapshot.SnapshotID, err)
+	}
+	return err
+}
+
+// createVolumeSnapshot takes the csi volume snapshots, the status of the snapshot is synchronized
+// by the snapshot controller until the storage finishes them.
+func (m *Manager) createVolumeSnapshot(ctx context.Context, namespace string, snapshot *dbmodel.TenantServiceVolumeSnapshot, logger event.Logger) error {
+	logger.Info(fmt.Sprintf("start taking snapshot of volume %s", snapshot.VolumeName), event.GetLoggerOption("starting"))
+	if err := m.snapshotManager.CreateSnapshot(ctx, namespace, snapshot); err != nil {
+		snapshot.Status = dbmodel.VolumeSnapshotStatusFailed
+		snapshot.Message = err.Error()
+		m.updateVolumeSnapshot(snapshot)
+		logger.Error(fmt.Sprintf("take snapshot of volume %s failure: %v", snapshot.VolumeName, err), event.GetCallbackLoggerOption())
+		return err
+	}
+	m.updateVolumeSnapshot(snapshot)
+	logger.Info(fmt.Sprintf("the snapshot of %d claims is taken by %s", snapshot.ClaimCount, snapshot.SnapshotClass), event.GetLastLoggerOption())
+	return nil
+}
+
+// restoreVolumeSnapshot restores the snapshot into the volume of the component, which is the volume the snapshot
+// is taken from or the volume of a new component. The component must be closed, because its claims are recreated.
+func (m *Manager) restoreVolumeSnapshot(ctx context.Context, namespace string, snapshot *dbmodel.TenantServiceVolumeSnapshot, body *model.VolumeSnapshotTaskBody, logger event.Logger) error {
+	targetServiceID, targetVolumeName := body.TargetServiceID, body.TargetVolumeName
+	if targetServiceID == "" {
+		targetServiceID = snapshot.ServiceID
+	}
+	if targetVolumeName == "" {
+		targetVolumeName = snapshot.VolumeName
+	}
+	if appService := m.store.GetAppService(targetServiceID); appService != nil && !appService.IsClosed() {
+		logger.Error("the component must be closed before restoring the volume", event.GetCallbackLoggerOption())
+		return fmt.Errorf("component %s is not closed", targetServiceID)
+	}
+	if snapshot.Status != dbmodel.VolumeSnapshotStatusReady {
+		logger.Error(fmt.Sprintf("the snapshot is %s, can not be restored", snapshot.Status), event.GetCallbackLoggerOption())
+		return fmt.Errorf("snapshot %s is not ready", snapshot.SnapshotID)
+	}
+	logger.Info(fmt.Sprintf("start restoring snapshot to volume %s", targetVolumeName), event.GetLoggerOption("starting"))
+
+	claims, err := m.snapshotManager.ListVolumeClaims(ctx, namespace, targetServiceID, targetVolumeName)
+	if err != nil {
+		logger.Error("list the claims of the volume failure", event.GetCallbackLoggerOption())
+		return err
+	}
+	if len(claims) == 0 {
+		// the component has never been started, restore the claims it will create
+		appService, err := conversion.InitAppService(m.cfg.SharedStorageClass, false, m.dbmanager, targetServiceID, nil)
+		if err != nil {
+			logger.Error("component init create failure", event.GetCallbackLoggerOption())
+			return err
+		}
+		if appService.GetNamespace() != namespace {
+			logger.Error("the snapshot can only be restored into the components of the same team", event.GetCallbackLoggerOption())
+			return fmt.Errorf("component %s is not in namespace %s", targetServiceID, namespace)
+		}
+		claims = volume.ClaimsOfVolume(appService, targetVolumeName)
+	}
+	if len(claims) == 0 {
+		logger.Error(fmt.Sprintf("volume %s of the component does not use a storage supporting snapshots", targetVolumeName), event.GetCallbackLoggerOption())
+		return volume.ErrSnapshotNotSupported
+	}
+
+	snapshot.Status = dbmodel.VolumeSnapshotStatusRestoring
+	m.updateVolumeSnapshot(snapshot)
+	retained, err := m.snapshotManager.RestoreClaims(ctx, namespace, snapshot, claims)
+	snapshot.Status = dbmodel.VolumeSnapshotStatusReady
+	m.updateVolumeSnapshot(snapshot)
+	if err != nil {
+		logger.Error(fmt.Sprintf("restore snapshot failure: %v", err), event.GetCallbackLoggerOption())
+		return err
+	}
+	if len(retained) > 0 {
+		logger.Info(fmt.Sprintf("the previous volumes %s are retained, delete them once the component runs with the restored data",
+			strings.Join(retained, ",")), event.GetLoggerOption("running"))
+	}
+	logger.Info(fmt.Sprintf("%d claims are restored from the snapshot, start the component to use them", len(claims)), event.GetLastLoggerOption())
+	return nil
+}
+
+func (m *Manager) deleteVolumeSnapshot(ctx context.Context, namespace string, snapshot *dbmodel.TenantServiceVolumeSnapshot, logger event.Logger) error {
+	if err := m.snapshotManager.DeleteSnapshot(ctx, namespace, snapshot.SnapshotID); err != nil {
+		logger.Error(fmt.Sprintf("delete volume snapshots failure: %v", err), event.GetCallbackLoggerOption())
+		return err
+	}
+	if err := m.dbmanager.TenantServiceVolumeSnapshotDao().DeleteBySnapshotID(snapshot.SnapshotID); err != nil {
+		logger.Error("delete volume snapshot record failure", event.GetCallbackLoggerOption())
+		return err
+	}
+	logger.Info("the volume snapshot is deleted", event.GetLastLoggerOption())
+	return nil
+}
+
+func (m *Manager) updateVolumeSnapshot(snapshot *dbmodel.TenantServiceVolumeSnapshot) {
+	if err := m.dbmanager.TenantServiceVolumeSnapshotDao().UpdateModel(snapshot); err != nil {
+		logrus.Warningf("update volume snapshot %s: %v", snapshot.SnapshotID, err)
+	}
+}
+
+// deleteServiceSnapshots deletes the csi volume snapshots of the deleted component
+func (m *Manager) deleteServiceSnapshots(tenantID, serviceID string) {
+	tenant, err := m.dbmanager.TenantDao().GetTenantByUUID(tenantID)
+	if err != nil {
+		logrus.Warningf("get tenant %s: %v", tenantID, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, time.Minute)
+	defer cancel()
+	if err := m.snapshotManager.DeleteServiceSnapshots(ctx, tenant.Namespace, serviceID); err != nil {
+		logrus.Warningf("delete volume snapshots of component %s: %v", serviceID, err)
+	}
+}
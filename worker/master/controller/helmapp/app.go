@@ -57,6 +57,7 @@ type App struct {
 	overrides    []string
 	revision     int
 	chartDir     string
+	appStore     *v1alpha1.HelmAppStore
 
 	helmCmd *helm.Helm
 	repo    *helm.Repo
@@ -73,6 +74,9 @@ func NewApp(ctx context.Context, kubeClient clientset.Interface, rainbondClient
 	if err != nil {
 		return nil, err
 	}
+	if source := chartSource(ctx, kubeClient, helmApp); source != nil {
+		helmCmd.SetChartSource(source)
+	}
 	repo := helm.NewRepo(repoFile, repoCache)
 	log := logrus.WithField("HelmAppController", "Reconcile").WithField("Namespace", helmApp.GetNamespace()).WithField("Name", helmApp.GetName())
 
@@ -91,6 +95,7 @@ func NewApp(ctx context.Context, kubeClient clientset.Interface, rainbondClient
 		version:         helmApp.Spec.Version,
 		revision:        helmApp.Spec.Revision,
 		overrides:       helmApp.Spec.Overrides,
+		appStore:        helmApp.Spec.AppStore,
 		helmCmd:         helmCmd,
 		repo:            repo,
 		chartDir:        path.Join(chartCache, helmApp.Namespace, helmApp.Name, helmApp.Spec.Version),
@@ -147,6 +152,9 @@ func (a *App) NeedUpdate() bool {
 	if a.helmApp.Spec.PreStatus != v1alpha1.HelmAppPreStatusConfigured {
 		return false
 	}
+	if a.appStore.Digest != "" && a.appStore.Digest != a.helmApp.Status.CurrentDigest {
+		return true
+	}
 	return !a.helmApp.OverridesEqual() || a.helmApp.Spec.Version != a.helmApp.Status.CurrentVersion
 }
 
@@ -251,13 +259,21 @@ func (a *App) Detect() error {
 	return a.UpdateStatus()
 }
 
+// AddRepo adds the helm native repo, the charts in oci registries or git repos need no repo.
+func (a *App) AddRepo() error {
+	if a.appStore.GetType() != v1alpha1.HelmAppStoreTypeHelm {
+		return nil
+	}
+	return a.repo.Add(a.repoName, a.repoURL, "", "")
+}
+
 // LoadChart loads the chart from repository.
 func (a *App) LoadChart() error {
-	if err := a.repo.Add(a.repoName, a.repoURL, "", ""); err != nil {
+	if err := a.AddRepo(); err != nil {
 		return err
 	}
 
-	_, err := a.helmCmd.Load(a.chart(), a.version)
+	_, _, err := a.helmCmd.Load(a.chart(), a.version)
 	return err
 }
 
@@ -267,7 +283,7 @@ func (a *App) chart() string {
 
 // PreInstall will check if we can intall the helm app.
 func (a *App) PreInstall() error {
-	if err := a.repo.Add(a.repoName, a.repoURL, "", ""); err != nil {
+	if err := a.AddRepo(); err != nil {
 		return err
 	}
 
@@ -281,7 +297,8 @@ func (a *App) Status() (*release.Release, error) {
 
 // InstallOrUpdate will install or update the helm app.
 func (a *App) InstallOrUpdate() error {
-	if err := a.installOrUpdate(); err != nil {
+	digest, err := a.installOrUpdate()
+	if err != nil {
 		a.helmApp.Status.SetCondition(*v1alpha1.NewHelmAppCondition(
 			v1alpha1.HelmAppInstalled, corev1.ConditionFalse, "InstallFailed", err.Error()))
 		return a.UpdateStatus()
@@ -289,31 +306,38 @@ func (a *App) InstallOrUpdate() error {
 
 	a.helmApp.Status.UpdateConditionStatus(v1alpha1.HelmAppInstalled, corev1.ConditionTrue)
 	a.helmApp.Status.CurrentVersion = a.helmApp.Spec.Version
+	a.helmApp.Status.CurrentDigest = digest
 	a.helmApp.Status.Overrides = a.helmApp.Spec.Overrides
 	return a.UpdateStatus()
 }
 
-func (a *App) installOrUpdate() error {
-	if err := a.repo.Add(a.repoName, a.repoURL, "", ""); err != nil {
-		return err
+// installOrUpdate installs or upgrades the release, returns the digest of the chart in effect.
+func (a *App) installOrUpdate() (string, error) {
+	if err := a.AddRepo(); err != nil {
+		return "", err
 	}
 
-	_, err := a.helmCmd.Status(a.name)
+	chartPath, digest, err := a.helmCmd.Load(a.Chart(), a.version)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = a.helmCmd.Status(a.name)
 	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
-		return err
+		return "", err
 	}
 
 	if errors.Is(err, driver.ErrReleaseNotFound) {
 		logrus.Debugf("name: %s; namespace: %s; chart: %s; install helm app", a.name, a.namespace, a.Chart())
-		if _, err := a.helmCmd.Install("", a.name, a.Chart(), a.version, a.overrides); err != nil {
-			return err
+		if _, err := a.helmCmd.Install(chartPath, a.name, a.Chart(), a.version, a.overrides); err != nil {
+			return "", err
 		}
 
-		return nil
+		return digest, nil
 	}
 
 	logrus.Debugf("name: %s; namespace: %s; chart: %s; upgrade helm app", a.name, a.namespace, a.Chart())
-	return a.helmCmd.Upgrade(a.name, a.chart(), a.version, a.overrides)
+	return digest, a.helmCmd.Upgrade(chartPath, a.name, a.chart(), a.version, a.overrides)
 }
 
 // Uninstall uninstalls the helm app.
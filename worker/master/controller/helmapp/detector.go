@@ -44,8 +44,7 @@ func NewDetector(helmApp *v1alpha1.HelmApp, app *App, repo *helm.Repo) *Detector
 func (d *Detector) Detect() error {
 	// add repo
 	if !d.helmApp.Status.IsConditionTrue(v1alpha1.HelmAppChartReady) {
-		appStore := d.helmApp.Spec.AppStore
-		if err := d.repo.Add(appStore.Name, appStore.URL, "", ""); err != nil {
+		if err := d.app.AddRepo(); err != nil {
 			d.helmApp.Status.SetCondition(*v1alpha1.NewHelmAppCondition(
 				v1alpha1.HelmAppChartReady, corev1.ConditionFalse, "RepoFailed", err.Error()))
 			return err
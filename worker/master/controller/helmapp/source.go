@@ -0,0 +1,82 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package helmapp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+	"github.com/goodrain/rainbond/pkg/helm"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// registryAuthSecretSelector selects the registry auth secrets of the tenant
+const registryAuthSecretSelector = "rainbond.io/registry-auth-secret=true"
+
+// chartSource returns the source of the chart, nil for a helm native repo.
+func chartSource(ctx context.Context, kubeClient clientset.Interface, helmApp *v1alpha1.HelmApp) *helm.ChartSource {
+	appStore := helmApp.Spec.AppStore
+	source := &helm.ChartSource{
+		URL:      appStore.URL,
+		Branch:   appStore.Branch,
+		Path:     appStore.Path,
+		Digest:   appStore.Digest,
+		Username: appStore.Username,
+		Password: appStore.Password,
+	}
+	switch appStore.GetType() {
+	case v1alpha1.HelmAppStoreTypeOCI:
+		source.Type = helm.ChartSourceOCI
+		if source.Username == "" && source.Password == "" {
+			source.Username, source.Password = registryAuth(ctx, kubeClient, helmApp.Namespace, registryHost(appStore.URL))
+		}
+	case v1alpha1.HelmAppStoreTypeGit:
+		source.Type = helm.ChartSourceGit
+	default:
+		return nil
+	}
+	return source
+}
+
+// registryHost returns the host of the oci registry url
+func registryHost(url string) string {
+	return strings.SplitN(strings.TrimPrefix(url, "oci://"), "/", 2)[0]
+}
+
+// registryAuth finds the username and password of the registry from the registry auth secrets of the namespace
+func registryAuth(ctx context.Context, kubeClient clientset.Interface, namespace, host string) (string, string) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+	secrets, err := kubeClient.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: registryAuthSecretSelector,
+	})
+	if err != nil {
+		logrus.Warningf("list registry auth secrets in %s: %v", namespace, err)
+		return "", ""
+	}
+	for _, secret := range secrets.Items {
+		if string(secret.Data["Domain"]) == host {
+			return string(secret.Data["Username"]), string(secret.Data["Password"])
+		}
+	}
+	return "", ""
+}
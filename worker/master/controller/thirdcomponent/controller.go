@@ -164,7 +164,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (res recon
 		}
 
 		// create endpoint for component service
-		if len(component.Spec.Ports) == 1 && len(component.Spec.EndpointSource.StaticEndpoints) > 1 {
+		if len(component.Spec.Ports) == 1 && (len(component.Spec.EndpointSource.StaticEndpoints) > 1 || component.Spec.IsDynamicEndpoints()) {
 			svc := services.Items[0]
 			ep := createEndpointsOnlyOnePort(component, svc, component.Status.Endpoints)
 			if ep != nil {
@@ -218,8 +218,8 @@ func (r *Reconciler) applyEndpointService(ctx context.Context, log *logrus.Entry
 }
 
 func createEndpointsOnlyOnePort(thirdComponent *v1alpha1.ThirdComponent, service corev1.Service, sourceEndpoints []*v1alpha1.ThirdComponentEndpointStatus) *corev1.Endpoints {
-	if len(thirdComponent.Spec.EndpointSource.StaticEndpoints) == 0 {
-		// support static endpoints only for now
+	if len(thirdComponent.Spec.EndpointSource.StaticEndpoints) == 0 && !thirdComponent.Spec.IsDynamicEndpoints() {
+		// support static and dynamic endpoints only for now
 		return nil
 	}
 	if len(thirdComponent.Spec.Ports) != 1 {
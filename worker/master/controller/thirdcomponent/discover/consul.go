@@ -0,0 +1,142 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+)
+
+// consulWait is the max time a blocking query waits for the changes.
+var consulWait = 5 * time.Minute
+
+// consulResolver watches the instances of the service with the blocking queries of the consul health api.
+type consulResolver struct {
+	source *v1alpha1.ConsulSource
+	token  string
+	client *http.Client
+	// index is the X-Consul-Index of the last query.
+	index uint64
+}
+
+func newConsulResolver(source *v1alpha1.ConsulSource, token string) *consulResolver {
+	return &consulResolver{
+		source: source,
+		token:  token,
+		// consul adds a jitter of up to wait/16 to the blocking query
+		client: &http.Client{Timeout: consulWait + consulWait/16 + 10*time.Second},
+	}
+}
+
+type consulServiceEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		ID      string
+		Address string
+		Port    int
+	}
+	Checks []struct {
+		Status string
+	}
+}
+
+func (c *consulResolver) resolve(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	return c.query(ctx, 0)
+}
+
+func (c *consulResolver) watch(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	// the query with the index 0 returns immediately
+	index := c.index
+	if index == 0 {
+		index = 1
+	}
+	return c.query(ctx, index)
+}
+
+// query lists the instances of the service, blocks until the index of the service changes if index is not zero.
+func (c *consulResolver) query(ctx context.Context, index uint64) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	params := url.Values{}
+	if c.source.Tag != "" {
+		params.Set("tag", c.source.Tag)
+	}
+	if c.source.Datacenter != "" {
+		params.Set("dc", c.source.Datacenter)
+	}
+	if index > 0 {
+		params.Set("index", strconv.FormatUint(index, 10))
+		params.Set("wait", fmt.Sprintf("%ds", int(consulWait.Seconds())))
+	}
+	u := fmt.Sprintf("%s/v1/health/service/%s?%s", strings.TrimSuffix(c.source.Address, "/"), url.PathEscape(c.source.Service), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return nil, fmt.Errorf("list consul service %s: %s %s", c.source.Service, res.Status, strings.TrimSpace(string(body)))
+	}
+	var entries []consulServiceEntry
+	if err := json.NewDecoder(res.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decode consul service %s: %v", c.source.Service, err)
+	}
+
+	newIndex, _ := strconv.ParseUint(res.Header.Get("X-Consul-Index"), 10, 64)
+	// the index must be reset if it goes backwards, see https://developer.hashicorp.com/consul/api-docs/features/blocking
+	if newIndex < c.index {
+		newIndex = 0
+	}
+	c.index = newIndex
+
+	var endpoints []*v1alpha1.ThirdComponentEndpointStatus
+	for _, entry := range entries {
+		host := entry.Service.Address
+		if host == "" {
+			host = entry.Node.Address
+		}
+		ready := true
+		for _, check := range entry.Checks {
+			if check.Status == "critical" {
+				ready = false
+			}
+		}
+		if ep := newEndpointStatus(entry.Service.ID, host, entry.Service.Port, ready); ep != nil {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return sortEndpoints(endpoints), nil
+}
@@ -0,0 +1,103 @@
+package discover
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+)
+
+// fakeConsul is a consul catalog supports the blocking queries.
+type fakeConsul struct {
+	lock    sync.Mutex
+	index   uint64
+	entries []map[string]interface{}
+	changed chan struct{}
+}
+
+func (f *fakeConsul) set(entries ...map[string]interface{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.index++
+	f.entries = entries
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/health/service/web" || r.Header.Get("X-Consul-Token") != "secret" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	f.lock.Lock()
+	if index >= f.index {
+		changed := f.changed
+		f.lock.Unlock()
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+		f.lock.Lock()
+	}
+	defer f.lock.Unlock()
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	json.NewEncoder(w).Encode(f.entries)
+}
+
+func consulEntry(id, ip string, port int, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"Node":    map[string]interface{}{"Address": ip},
+		"Service": map[string]interface{}{"ID": id, "Port": port},
+		"Checks":  []map[string]interface{}{{"Status": status}},
+	}
+}
+
+func TestConsulDiscover(t *testing.T) {
+	consul := &fakeConsul{changed: make(chan struct{})}
+	consul.set(consulEntry("web-1", "10.0.0.1", 8080, "passing"), consulEntry("web-2", "10.0.0.2", 8080, "critical"))
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	component := &v1alpha1.ThirdComponent{}
+	component.Spec.EndpointSource.Consul = &v1alpha1.ConsulSource{Address: server.URL, Service: "web"}
+	d := &dynamicEndpoint{
+		component: component,
+		source:    "consul web",
+		resolver:  newConsulResolver(component.Spec.EndpointSource.Consul, "secret"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	update := make(chan *v1alpha1.ThirdComponent)
+	go d.Discover(ctx, update)
+
+	assertEndpoints(t, update, []*v1alpha1.ThirdComponentEndpointStatus{
+		{Name: "web-1", Address: "10.0.0.1:8080", Status: v1alpha1.EndpointReady},
+		{Name: "web-2", Address: "10.0.0.2:8080", Status: v1alpha1.EndpointNotReady},
+	})
+
+	consul.set(consulEntry("web-2", "10.0.0.2", 8080, "passing"))
+	assertEndpoints(t, update, []*v1alpha1.ThirdComponentEndpointStatus{
+		{Name: "web-2", Address: "10.0.0.2:8080", Status: v1alpha1.EndpointReady},
+	})
+}
+
+func assertEndpoints(t *testing.T, update chan *v1alpha1.ThirdComponent, want []*v1alpha1.ThirdComponentEndpointStatus) {
+	select {
+	case component := <-update:
+		if !reflect.DeepEqual(component.Status.Endpoints, want) {
+			got, _ := json.Marshal(component.Status.Endpoints)
+			t.Fatalf("unexpected endpoints %s", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("endpoints are not updated")
+	}
+}
@@ -63,9 +63,66 @@ func NewDiscover(component *v1alpha1.ThirdComponent,
 			lister:    lister,
 		}, nil
 	}
+	source := component.Spec.EndpointSource
+	if source.DNS != nil {
+		resolver, err := newDNSResolver(source.DNS, component.Spec.Ports)
+		if err != nil {
+			return nil, err
+		}
+		return &dynamicEndpoint{
+			component: component,
+			source:    "dns " + source.DNS.Name,
+			resolver:  resolver,
+		}, nil
+	}
+	if source.Consul != nil {
+		secret, err := getSecret(component.Namespace, source.Consul.Secret, restConfig)
+		if err != nil {
+			return nil, err
+		}
+		return &dynamicEndpoint{
+			component: component,
+			source:    "consul " + source.Consul.Service,
+			resolver:  newConsulResolver(source.Consul, secret["token"]),
+		}, nil
+	}
+	if source.Nacos != nil {
+		secret, err := getSecret(component.Namespace, source.Nacos.Secret, restConfig)
+		if err != nil {
+			return nil, err
+		}
+		return &dynamicEndpoint{
+			component: component,
+			source:    "nacos " + source.Nacos.Service,
+			resolver:  newNacosResolver(source.Nacos, secret["username"], secret["password"]),
+		}, nil
+	}
 	return nil, fmt.Errorf("not support source type")
 }
 
+// getSecret returns the data of the secret, nil if the name is empty.
+func getSecret(namespace, name string, restConfig *rest.Config) (map[string]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		logrus.Errorf("create kube client error: %s", err.Error())
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("load secret %s failure %s", name, err.Error())
+	}
+	data := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	return data, nil
+}
+
 type kubernetesDiscover struct {
 	component *v1alpha1.ThirdComponent
 	client    *kubernetes.Clientset
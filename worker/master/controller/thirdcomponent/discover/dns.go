@@ -0,0 +1,312 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	minDNSRefresh     = 5 * time.Second
+	maxDNSRefresh     = 5 * time.Minute
+	defaultDNSRefresh = 30 * time.Second
+	dnsQueryTimeout   = 5 * time.Second
+)
+
+// dnsResolver resolves the endpoints from the A, AAAA or SRV records, and refreshes them as the TTL expires.
+type dnsResolver struct {
+	source      *v1alpha1.DNSSource
+	ports       []*v1alpha1.ComponentPort
+	nameservers []string
+	refresh     time.Duration
+}
+
+func newDNSResolver(source *v1alpha1.DNSSource, ports []*v1alpha1.ComponentPort) (*dnsResolver, error) {
+	nameservers := []string{source.Nameserver}
+	if source.Nameserver == "" {
+		var err error
+		nameservers, err = systemNameservers("/etc/resolv.conf")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &dnsResolver{
+		source:      source,
+		ports:       ports,
+		nameservers: nameservers,
+		refresh:     defaultDNSRefresh,
+	}, nil
+}
+
+// systemNameservers returns the nameservers in resolv.conf.
+func systemNameservers(resolvConf string) ([]string, error) {
+	f, err := os.Open(resolvConf)
+	if err != nil {
+		return nil, fmt.Errorf("read nameservers: %v", err)
+	}
+	defer f.Close()
+	var nameservers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			nameservers = append(nameservers, net.JoinHostPort(fields[1], "53"))
+		}
+	}
+	if len(nameservers) == 0 {
+		return nil, fmt.Errorf("no nameserver found in %s", resolvConf)
+	}
+	return nameservers, nil
+}
+
+func (d *dnsResolver) watch(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(d.refresh):
+	}
+	return d.resolve(ctx)
+}
+
+func (d *dnsResolver) resolve(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	name := d.source.Name
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	var endpoints []*v1alpha1.ThirdComponentEndpointStatus
+	var ttl uint32
+	var err error
+	switch d.source.GetType() {
+	case v1alpha1.DNSRecordSRV:
+		endpoints, ttl, err = d.resolveSRV(ctx, name)
+	case v1alpha1.DNSRecordAAAA:
+		endpoints, ttl, err = d.resolveIP(ctx, name, dnsmessage.TypeAAAA)
+	default:
+		endpoints, ttl, err = d.resolveIP(ctx, name, dnsmessage.TypeA)
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.refresh = refreshInterval(ttl)
+	return sortEndpoints(endpoints), nil
+}
+
+// refreshInterval returns the ttl limited in [minDNSRefresh, maxDNSRefresh], defaultDNSRefresh if there is no record.
+func refreshInterval(ttl uint32) time.Duration {
+	if ttl == 0 {
+		return defaultDNSRefresh
+	}
+	refresh := time.Duration(ttl) * time.Second
+	if refresh < minDNSRefresh {
+		return minDNSRefresh
+	}
+	if refresh > maxDNSRefresh {
+		return maxDNSRefresh
+	}
+	return refresh
+}
+
+func (d *dnsResolver) resolveIP(ctx context.Context, name string, qtype dnsmessage.Type) ([]*v1alpha1.ThirdComponentEndpointStatus, uint32, error) {
+	msg, err := d.query(ctx, name, qtype)
+	if err != nil {
+		return nil, 0, err
+	}
+	ips, ttl := ipsOf(msg.Answers, "")
+	var ports []int
+	if d.source.Port > 0 {
+		ports = append(ports, d.source.Port)
+	} else {
+		for _, port := range d.ports {
+			ports = append(ports, port.Port)
+		}
+	}
+	var endpoints []*v1alpha1.ThirdComponentEndpointStatus
+	for _, ip := range ips {
+		for _, port := range ports {
+			if ep := newEndpointStatus("", ip, port, true); ep != nil {
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+	return endpoints, ttl, nil
+}
+
+func (d *dnsResolver) resolveSRV(ctx context.Context, name string) ([]*v1alpha1.ThirdComponentEndpointStatus, uint32, error) {
+	msg, err := d.query(ctx, name, dnsmessage.TypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+	var endpoints []*v1alpha1.ThirdComponentEndpointStatus
+	var ttl uint32
+	for _, answer := range msg.Answers {
+		srv, ok := answer.Body.(*dnsmessage.SRVResource)
+		if !ok {
+			continue
+		}
+		ttl = minTTL(ttl, answer.Header.TTL)
+		target := srv.Target.String()
+		// the addresses of the target are usually in the additional section
+		ips, ipTTL := ipsOf(msg.Additionals, target)
+		if len(ips) == 0 {
+			targetMsg, err := d.query(ctx, target, dnsmessage.TypeA)
+			if err != nil {
+				return nil, 0, err
+			}
+			ips, ipTTL = ipsOf(targetMsg.Answers, "")
+		}
+		ttl = minTTL(ttl, ipTTL)
+		for _, ip := range ips {
+			if ep := newEndpointStatus(strings.TrimSuffix(target, "."), ip, int(srv.Port), true); ep != nil {
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+	return endpoints, ttl, nil
+}
+
+// ipsOf returns the addresses of the A and AAAA records, only the records of the name if it is not empty.
+func ipsOf(resources []dnsmessage.Resource, name string) ([]string, uint32) {
+	var ips []string
+	var ttl uint32
+	for _, resource := range resources {
+		if name != "" && !strings.EqualFold(resource.Header.Name.String(), name) {
+			continue
+		}
+		switch body := resource.Body.(type) {
+		case *dnsmessage.AResource:
+			ips = append(ips, net.IP(body.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			ips = append(ips, net.IP(body.AAAA[:]).String())
+		default:
+			continue
+		}
+		ttl = minTTL(ttl, resource.Header.TTL)
+	}
+	return ips, ttl
+}
+
+// minTTL returns the smaller non zero ttl.
+func minTTL(a, b uint32) uint32 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// query queries the nameservers in order until one of them answers.
+func (d *dnsResolver) query(ctx context.Context, name string, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, err
+	}
+	req := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: uint16(rand.Intn(1 << 16)), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  qname,
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packed, err := req.Pack()
+	if err != nil {
+		return nil, err
+	}
+	for _, nameserver := range d.nameservers {
+		var msg *dnsmessage.Message
+		msg, err = exchange(ctx, "udp", nameserver, packed, req.ID)
+		if err == nil && msg.Truncated {
+			msg, err = exchange(ctx, "tcp", nameserver, packed, req.ID)
+		}
+		if err != nil {
+			continue
+		}
+		switch msg.RCode {
+		case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+			// no such domain means there is no endpoint
+			return msg, nil
+		default:
+			err = fmt.Errorf("query %s %s from %s: %s", qtype, name, nameserver, msg.RCode)
+		}
+	}
+	return nil, err
+}
+
+func exchange(ctx context.Context, network, nameserver string, packed []byte, id uint16) (*dnsmessage.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, dnsQueryTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, nameserver)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var resp []byte
+	if network == "tcp" {
+		// the message over tcp is prefixed with the length
+		req := make([]byte, 2+len(packed))
+		binary.BigEndian.PutUint16(req, uint16(len(packed)))
+		copy(req[2:], packed)
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		resp = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, resp); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(packed); err != nil {
+			return nil, err
+		}
+		resp = make([]byte, 65535)
+		n, err := conn.Read(resp)
+		if err != nil {
+			return nil, err
+		}
+		resp = resp[:n]
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(resp); err != nil {
+		return nil, err
+	}
+	if msg.ID != id {
+		return nil, fmt.Errorf("dns message id mismatch, expect %d, but got %d", id, msg.ID)
+	}
+	return &msg, nil
+}
@@ -0,0 +1,144 @@
+package discover
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startDNSServer starts a dns server on the loopback, which answers the questions with the records.
+func startDNSServer(t *testing.T, records map[string][]dnsmessage.Resource, additionals []dnsmessage.Resource) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var req dnsmessage.Message
+			if err := req.Unpack(buf[:n]); err != nil {
+				continue
+			}
+			q := req.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: req.ID, Response: true},
+				Questions: req.Questions,
+			}
+			answers, ok := records[q.Type.String()+" "+q.Name.String()]
+			if !ok {
+				resp.RCode = dnsmessage.RCodeNameError
+			}
+			resp.Answers = answers
+			if q.Type == dnsmessage.TypeSRV {
+				resp.Additionals = additionals
+			}
+			packed, err := resp.Pack()
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			conn.WriteTo(packed, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func aRecord(name string, ip string, ttl uint32) dnsmessage.Resource {
+	var a [4]byte
+	copy(a[:], net.ParseIP(ip).To4())
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.AResource{A: a},
+	}
+}
+
+func srvRecord(name, target string, port uint16, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: dnsmessage.TypeSRV, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.SRVResource{Target: dnsmessage.MustNewName(target), Port: port},
+	}
+}
+
+func TestDNSResolver(t *testing.T) {
+	nameserver := startDNSServer(t, map[string][]dnsmessage.Resource{
+		"TypeA web.example.com.": {
+			aRecord("web.example.com.", "10.0.0.2", 60),
+			aRecord("web.example.com.", "10.0.0.1", 30),
+		},
+		"TypeSRV _http._tcp.example.com.": {
+			srvRecord("_http._tcp.example.com.", "a.example.com.", 8080, 120),
+			srvRecord("_http._tcp.example.com.", "b.example.com.", 8081, 120),
+		},
+		"TypeA b.example.com.": {
+			aRecord("b.example.com.", "10.0.1.2", 2),
+		},
+	}, []dnsmessage.Resource{
+		aRecord("a.example.com.", "10.0.1.1", 300),
+	})
+
+	tests := []struct {
+		name    string
+		source  *v1alpha1.DNSSource
+		want    []string
+		refresh time.Duration
+	}{
+		{
+			name:    "A records with the component ports",
+			source:  &v1alpha1.DNSSource{Name: "web.example.com", Nameserver: nameserver},
+			want:    []string{"10.0.0.1:443", "10.0.0.1:80", "10.0.0.2:443", "10.0.0.2:80"},
+			refresh: 30 * time.Second,
+		},
+		{
+			name:    "A records with the port",
+			source:  &v1alpha1.DNSSource{Name: "web.example.com", Port: 8080, Nameserver: nameserver},
+			want:    []string{"10.0.0.1:8080", "10.0.0.2:8080"},
+			refresh: 30 * time.Second,
+		},
+		{
+			name:    "SRV records",
+			source:  &v1alpha1.DNSSource{Name: "_http._tcp.example.com", Type: v1alpha1.DNSRecordSRV, Nameserver: nameserver},
+			want:    []string{"10.0.1.1:8080", "10.0.1.2:8081"},
+			refresh: minDNSRefresh,
+		},
+		{
+			name:    "no such domain",
+			source:  &v1alpha1.DNSSource{Name: "none.example.com", Nameserver: nameserver},
+			refresh: defaultDNSRefresh,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver, err := newDNSResolver(tc.source, []*v1alpha1.ComponentPort{{Port: 80}, {Port: 443}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			endpoints, err := resolver.resolve(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, ep := range endpoints {
+				got = append(got, string(ep.Address))
+				if ep.Status != v1alpha1.EndpointReady {
+					t.Errorf("endpoint %s should be ready", ep.Address)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+			if resolver.refresh != tc.refresh {
+				t.Errorf("want refresh %v, got %v", tc.refresh, resolver.refresh)
+			}
+		})
+	}
+}
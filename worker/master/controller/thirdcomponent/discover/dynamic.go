@@ -0,0 +1,197 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+	"github.com/goodrain/rainbond/worker/master/controller/thirdcomponent/prober"
+	"github.com/goodrain/rainbond/worker/master/controller/thirdcomponent/prober/results"
+	"github.com/sirupsen/logrus"
+)
+
+// retryInterval is how long to wait before resolving again after a failure.
+var retryInterval = 10 * time.Second
+
+// resolver resolves the endpoints from the dns or a service registry.
+type resolver interface {
+	// resolve returns the current endpoints without blocking.
+	resolve(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error)
+	// watch blocks until the endpoints may have changed, then returns them.
+	watch(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error)
+}
+
+// dynamicEndpoint discovers the endpoints which change over time, such as the endpoints from the dns, consul or nacos.
+type dynamicEndpoint struct {
+	component *v1alpha1.ThirdComponent
+	source    string
+	resolver  resolver
+
+	pmlock        sync.Mutex
+	proberManager prober.Manager
+}
+
+func (d *dynamicEndpoint) GetComponent() *v1alpha1.ThirdComponent {
+	return d.component
+}
+
+func (d *dynamicEndpoint) SetProberManager(proberManager prober.Manager) {
+	d.pmlock.Lock()
+	defer d.pmlock.Unlock()
+	d.proberManager = proberManager
+}
+
+func (d *dynamicEndpoint) getProberManager() prober.Manager {
+	d.pmlock.Lock()
+	defer d.pmlock.Unlock()
+	return d.proberManager
+}
+
+func (d *dynamicEndpoint) DiscoverOne(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	endpoints, err := d.resolver.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.withProbeResults(endpoints), nil
+}
+
+func (d *dynamicEndpoint) Discover(ctx context.Context, update chan *v1alpha1.ThirdComponent) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	changes := make(chan []*v1alpha1.ThirdComponentEndpointStatus)
+	go d.watch(ctx, changes)
+
+	var probeUpdates <-chan results.Update
+	if pm := d.getProberManager(); pm != nil {
+		probeUpdates = pm.Updates()
+	}
+	var endpoints []*v1alpha1.ThirdComponentEndpointStatus
+	resolved := false
+	last := d.component.Status.Endpoints
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case endpoints = <-changes:
+			resolved = true
+			d.addProbes(endpoints)
+		case <-probeUpdates:
+		}
+		if !resolved {
+			continue
+		}
+		current := d.withProbeResults(endpoints)
+		if reflect.DeepEqual(current, last) {
+			continue
+		}
+		last = current
+		newComponent := d.component.DeepCopy()
+		newComponent.Status.Endpoints = current
+		update <- newComponent
+	}
+}
+
+// watch sends the endpoints to changes every time they may have changed, until the context is done.
+func (d *dynamicEndpoint) watch(ctx context.Context, changes chan<- []*v1alpha1.ThirdComponentEndpointStatus) {
+	next := d.resolver.resolve
+	for {
+		endpoints, err := next(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logrus.Warningf("discover endpoints of %s from %s: %v", d.component.GetNamespaceName(), d.source, err)
+			next = d.resolver.resolve
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+		next = d.resolver.watch
+		select {
+		case <-ctx.Done():
+			return
+		case changes <- endpoints:
+		}
+	}
+}
+
+// addProbes makes the prober manager probe the endpoints.
+func (d *dynamicEndpoint) addProbes(endpoints []*v1alpha1.ThirdComponentEndpointStatus) {
+	pm := d.getProberManager()
+	if pm == nil {
+		return
+	}
+	component := d.component.DeepCopy()
+	component.Status.Endpoints = endpoints
+	pm.AddThirdComponent(component)
+}
+
+// withProbeResults returns a copy of the endpoints, whose status is updated with the probe results.
+// The endpoints the source reports unhealthy keep their status.
+func (d *dynamicEndpoint) withProbeResults(endpoints []*v1alpha1.ThirdComponentEndpointStatus) []*v1alpha1.ThirdComponentEndpointStatus {
+	pm := d.getProberManager()
+	var newEndpoints []*v1alpha1.ThirdComponentEndpointStatus
+	for _, ep := range endpoints {
+		ep := *ep
+		if pm != nil && d.component.Spec.Probe != nil && ep.Status == v1alpha1.EndpointReady {
+			result, found := pm.GetResult(d.component.GetEndpointID(&ep))
+			if !found {
+				ep.Status = v1alpha1.EndpointNotReady
+			} else if result != results.Success {
+				ep.Status = v1alpha1.EndpointUnhealthy
+			}
+		}
+		newEndpoints = append(newEndpoints, &ep)
+	}
+	return newEndpoints
+}
+
+// newEndpointStatus returns nil if the host or the port is invalid.
+func newEndpointStatus(name, host string, port int, ready bool) *v1alpha1.ThirdComponentEndpointStatus {
+	address := v1alpha1.NewEndpointAddress(host, port)
+	if address == nil {
+		return nil
+	}
+	status := v1alpha1.EndpointReady
+	if !ready {
+		status = v1alpha1.EndpointNotReady
+	}
+	return &v1alpha1.ThirdComponentEndpointStatus{
+		Name:    name,
+		Address: *address,
+		Status:  status,
+	}
+}
+
+// sortEndpoints sorts the endpoints by address, so that the same endpoints are always equal.
+func sortEndpoints(endpoints []*v1alpha1.ThirdComponentEndpointStatus) []*v1alpha1.ThirdComponentEndpointStatus {
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Address != endpoints[j].Address {
+			return endpoints[i].Address < endpoints[j].Address
+		}
+		return endpoints[i].Name < endpoints[j].Name
+	})
+	return endpoints
+}
@@ -0,0 +1,171 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+)
+
+const defaultNacosRefresh = 10 * time.Second
+
+// nacosResolver polls the instances of the service from the nacos open api, at the interval the server suggests.
+type nacosResolver struct {
+	source   *v1alpha1.NacosSource
+	username string
+	password string
+	client   *http.Client
+	refresh  time.Duration
+
+	accessToken string
+	tokenExpire time.Time
+}
+
+func newNacosResolver(source *v1alpha1.NacosSource, username, password string) *nacosResolver {
+	return &nacosResolver{
+		source:   source,
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		refresh:  defaultNacosRefresh,
+	}
+}
+
+type nacosInstanceList struct {
+	CacheMillis int64 `json:"cacheMillis"`
+	Hosts       []struct {
+		InstanceID string `json:"instanceId"`
+		IP         string `json:"ip"`
+		Port       int    `json:"port"`
+		Healthy    bool   `json:"healthy"`
+		Enabled    bool   `json:"enabled"`
+	} `json:"hosts"`
+}
+
+func (n *nacosResolver) watch(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(n.refresh):
+	}
+	return n.resolve(ctx)
+}
+
+func (n *nacosResolver) resolve(ctx context.Context) ([]*v1alpha1.ThirdComponentEndpointStatus, error) {
+	params := url.Values{}
+	params.Set("serviceName", n.source.Service)
+	params.Set("healthyOnly", "false")
+	if n.source.Group != "" {
+		params.Set("groupName", n.source.Group)
+	}
+	if n.source.Namespace != "" {
+		params.Set("namespaceId", n.source.Namespace)
+	}
+	if len(n.source.Clusters) > 0 {
+		params.Set("clusters", strings.Join(n.source.Clusters, ","))
+	}
+	token, err := n.getAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		params.Set("accessToken", token)
+	}
+
+	var list nacosInstanceList
+	if err := n.do(ctx, http.MethodGet, "/v1/ns/instance/list?"+params.Encode(), nil, &list); err != nil {
+		return nil, fmt.Errorf("list nacos service %s: %v", n.source.Service, err)
+	}
+	n.refresh = defaultNacosRefresh
+	if list.CacheMillis > 0 {
+		n.refresh = time.Duration(list.CacheMillis) * time.Millisecond
+	}
+
+	var endpoints []*v1alpha1.ThirdComponentEndpointStatus
+	for _, host := range list.Hosts {
+		// the disabled instance is offline
+		if !host.Enabled {
+			continue
+		}
+		if ep := newEndpointStatus(host.InstanceID, host.IP, host.Port, host.Healthy); ep != nil {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return sortEndpoints(endpoints), nil
+}
+
+// getAccessToken logins nacos if the auth is enabled, and caches the token until it expires.
+func (n *nacosResolver) getAccessToken(ctx context.Context) (string, error) {
+	if n.username == "" {
+		return "", nil
+	}
+	if n.accessToken != "" && time.Now().Before(n.tokenExpire) {
+		return n.accessToken, nil
+	}
+	form := url.Values{}
+	form.Set("username", n.username)
+	form.Set("password", n.password)
+	var res struct {
+		AccessToken string `json:"accessToken"`
+		TokenTTL    int64  `json:"tokenTtl"`
+	}
+	if err := n.do(ctx, http.MethodPost, "/v1/auth/login", form, &res); err != nil {
+		return "", fmt.Errorf("login nacos: %v", err)
+	}
+	n.accessToken = res.AccessToken
+	// renew the token before it expires
+	n.tokenExpire = time.Now().Add(time.Duration(res.TokenTTL) * time.Second * 9 / 10)
+	return n.accessToken, nil
+}
+
+func (n *nacosResolver) do(ctx context.Context, method, path string, form url.Values, result interface{}) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(n.source.Address, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s", res.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, result)
+}
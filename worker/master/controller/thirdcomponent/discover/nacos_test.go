@@ -0,0 +1,70 @@
+package discover
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+)
+
+func TestNacosResolver(t *testing.T) {
+	logins := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/nacos/v1/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("username") != "nacos" || r.FormValue("password") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		logins++
+		json.NewEncoder(w).Encode(map[string]interface{}{"accessToken": "token", "tokenTtl": 18000})
+	})
+	mux.HandleFunc("/nacos/v1/ns/instance/list", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("accessToken") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if query.Get("serviceName") != "web" || query.Get("groupName") != "prod" || query.Get("clusters") != "a,b" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"hosts": []interface{}{}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"cacheMillis": 3000,
+			"hosts": []map[string]interface{}{
+				{"instanceId": "web-2", "ip": "10.0.0.2", "port": 8080, "healthy": false, "enabled": true},
+				{"instanceId": "web-1", "ip": "10.0.0.1", "port": 8080, "healthy": true, "enabled": true},
+				{"instanceId": "web-3", "ip": "10.0.0.3", "port": 8080, "healthy": true, "enabled": false},
+			},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	source := &v1alpha1.NacosSource{Address: server.URL + "/nacos", Service: "web", Group: "prod", Clusters: []string{"a", "b"}}
+	resolver := newNacosResolver(source, "nacos", "secret")
+	for i := 0; i < 2; i++ {
+		endpoints, err := resolver.resolve(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []*v1alpha1.ThirdComponentEndpointStatus{
+			{Name: "web-1", Address: "10.0.0.1:8080", Status: v1alpha1.EndpointReady},
+			{Name: "web-2", Address: "10.0.0.2:8080", Status: v1alpha1.EndpointNotReady},
+		}
+		if !reflect.DeepEqual(endpoints, want) {
+			got, _ := json.Marshal(endpoints)
+			t.Fatalf("unexpected endpoints %s", got)
+		}
+	}
+	if logins != 1 {
+		t.Errorf("the access token should be cached, but login %d times", logins)
+	}
+	if resolver.refresh != 3*time.Second {
+		t.Errorf("want refresh 3s, got %v", resolver.refresh)
+	}
+}
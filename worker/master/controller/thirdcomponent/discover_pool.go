@@ -108,7 +108,7 @@ func (d *DiscoverPool) newWorker(dis dis.Discover) *Worker {
 	}
 
 	component := dis.GetComponent()
-	if component.Spec.IsStaticEndpoints() {
+	if needProberManager(component) {
 		proberManager := prober.NewManager(d.recorder)
 		dis.SetProberManager(proberManager)
 		worker.proberManager = proberManager
@@ -117,6 +117,11 @@ func (d *DiscoverPool) newWorker(dis dis.Discover) *Worker {
 	return worker
 }
 
+// needProberManager returns true if the endpoints of the component are static or need to be probed.
+func needProberManager(component *v1alpha1.ThirdComponent) bool {
+	return component.Spec.IsStaticEndpoints() || component.Spec.NeedProbe()
+}
+
 // AddDiscover -
 func (d *DiscoverPool) AddDiscover(dis dis.Discover) {
 	d.lock.Lock()
@@ -135,7 +140,7 @@ func (d *DiscoverPool) AddDiscover(dis dis.Discover) {
 		return
 	}
 	worker := d.newWorker(dis)
-	if component.Spec.IsStaticEndpoints() {
+	if needProberManager(component) {
 		worker.proberManager.AddThirdComponent(dis.GetComponent())
 	}
 	go worker.Start()
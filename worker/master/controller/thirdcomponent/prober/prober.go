@@ -2,11 +2,14 @@ package prober
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/goodrain/rainbond/pkg/apis/rainbond/v1alpha1"
+	utilprobe "github.com/goodrain/rainbond/util/prober/probes"
 	"github.com/goodrain/rainbond/worker/master/controller/thirdcomponent/prober/results"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
@@ -93,6 +96,30 @@ func (pb *prober) runProbe(p *v1alpha1.Probe, thirdComponent *v1alpha1.ThirdComp
 		return pb.tcp.Probe(endpointStatus.Address.GetIP(), endpointStatus.Address.GetPort(), timeout)
 	}
 
+	if p.GRPC != nil || p.TLSExpiry != nil {
+		// the timeout defaults to 1 second
+		if timeout <= 0 {
+			timeout = time.Second
+		}
+	}
+
+	if p.GRPC != nil {
+		address := net.JoinHostPort(endpointStatus.Address.GetIP(), strconv.Itoa(endpointStatus.Address.GetPort()))
+		if err := utilprobe.GRPCHealthCheck(address, p.GRPC.Service, p.GRPC.TLS, timeout); err != nil {
+			return probe.Failure, err.Error(), nil
+		}
+		return probe.Success, "", nil
+	}
+
+	if p.TLSExpiry != nil {
+		address := net.JoinHostPort(endpointStatus.Address.GetIP(), strconv.Itoa(endpointStatus.Address.GetPort()))
+		threshold := utilprobe.ExpireThreshold(int(p.TLSExpiry.ThresholdDays))
+		if _, err := utilprobe.TLSExpiryCheck(address, p.TLSExpiry.ServerName, threshold, timeout); err != nil {
+			return probe.Failure, err.Error(), nil
+		}
+		return probe.Success, "", nil
+	}
+
 	pb.logger.Warningf("Failed to find probe builder for endpoint address: %v", endpointID)
 	return probe.Unknown, "", fmt.Errorf("missing probe handler for %s/%s", thirdComponent.Namespace, thirdComponent.Name)
 }
@@ -37,7 +37,7 @@ func (w *Worker) Start() {
 // UpdateDiscover -
 func (w *Worker) UpdateDiscover(discover dis.Discover) {
 	component := discover.GetComponent()
-	if component.Spec.IsStaticEndpoints() {
+	if needProberManager(component) && w.proberManager != nil {
 		w.proberManager.AddThirdComponent(discover.GetComponent())
 		discover.SetProberManager(w.proberManager)
 	}
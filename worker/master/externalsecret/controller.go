@@ -0,0 +1,175 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package externalsecret
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goodrain/rainbond/worker/appm/secretstore"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Controller refreshes the secrets resolved from the latest versions of the external secrets once they rotate,
+// and rolling restarts the workloads whose changed values are marked to restart on rotation.
+// The other workloads read the new values the next time they start or upgrade.
+type Controller struct {
+	ctx        context.Context
+	kubeClient kubernetes.Interface
+	interval   time.Duration
+}
+
+// NewController creates an external secret controller
+func NewController(ctx context.Context, kubeClient kubernetes.Interface, interval time.Duration) *Controller {
+	return &Controller{
+		ctx:        ctx,
+		kubeClient: kubeClient,
+		interval:   interval,
+	}
+}
+
+// Start starts the controller until the context is done
+func (c *Controller) Start() {
+	if c.interval <= 0 {
+		logrus.Info("the refresh of the external secrets is disabled")
+		return
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	logrus.Info("external secret controller start success")
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.sync()
+		}
+	}
+}
+
+func (c *Controller) sync() {
+	store, err := secretstore.Default()
+	if err != nil {
+		return
+	}
+	secrets, err := c.kubeClient.CoreV1().Secrets(metav1.NamespaceAll).List(c.ctx, metav1.ListOptions{
+		LabelSelector: secretstore.ExternalSecretLabel + "=true",
+	})
+	if err != nil {
+		logrus.Errorf("list external secrets: %v", err)
+		return
+	}
+	for i := range secrets.Items {
+		if err := c.refresh(store, &secrets.Items[i]); err != nil {
+			logrus.Warningf("refresh external secret %s/%s: %v", secrets.Items[i].Namespace, secrets.Items[i].Name, err)
+		}
+	}
+}
+
+func (c *Controller) refresh(store secretstore.Store, secret *corev1.Secret) error {
+	var refs []*secretstore.Ref
+	if err := json.Unmarshal([]byte(secret.Annotations[secretstore.RefsAnnotation]), &refs); err != nil {
+		return fmt.Errorf("decode refs: %v", err)
+	}
+	var latest bool
+	for _, ref := range refs {
+		if ref.Version == 0 {
+			latest = true
+		}
+	}
+	if !latest {
+		// the pinned versions never change
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, 30*time.Second)
+	defer cancel()
+	// the tenant is taken from the labels set by the worker, the refs only hold the paths relative to its root
+	data, err := secretstore.Resolve(ctx, store, secret.Labels["tenant_id"], refs)
+	if err != nil {
+		return err
+	}
+	var restart, changed bool
+	for _, ref := range refs {
+		if bytes.Equal(secret.Data[ref.DataKey], data[ref.DataKey]) {
+			continue
+		}
+		changed = true
+		restart = restart || ref.Restart
+	}
+	if !changed {
+		return nil
+	}
+	refsJSON, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	secret.Data = data
+	secret.Annotations[secretstore.RefsAnnotation] = string(refsJSON)
+	if _, err := c.kubeClient.CoreV1().Secrets(secret.Namespace).Update(c.ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update secret: %v", err)
+	}
+	logrus.Infof("external secret %s/%s refreshed", secret.Namespace, secret.Name)
+	if !restart {
+		return nil
+	}
+	return c.restart(secret.Namespace, secret.Annotations[secretstore.WorkloadAnnotation], secretstore.Checksum(data))
+}
+
+// restart rolling restarts the workload by updating the checksum annotation of its pod template
+func (c *Controller) restart(namespace, workload, checksum string) error {
+	kindName := strings.SplitN(workload, "/", 2)
+	if len(kindName) != 2 {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{secretstore.ChecksumAnnotation: checksum},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	kind, name := kindName[0], kindName[1]
+	switch kind {
+	case "Deployment":
+		_, err = c.kubeClient.AppsV1().Deployments(namespace).Patch(c.ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = c.kubeClient.AppsV1().StatefulSets(namespace).Patch(c.ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case "DaemonSet":
+		_, err = c.kubeClient.AppsV1().DaemonSets(namespace).Patch(c.ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("restart %s: %v", workload, err)
+	}
+	logrus.Infof("%s/%s restarted for the rotation of its external secrets", namespace, workload)
+	return nil
+}
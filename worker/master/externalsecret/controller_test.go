@@ -0,0 +1,88 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+package externalsecret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goodrain/rainbond/worker/appm/secretstore"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeStore map[string]*secretstore.Secret
+
+func (f fakeStore) Read(ctx context.Context, path string, version int) (*secretstore.Secret, error) {
+	secret, ok := f[path]
+	if !ok {
+		return nil, secretstore.ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func newSecret(refs string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-web-external-secrets",
+			Namespace: "ns1",
+			Labels:    map[string]string{secretstore.ExternalSecretLabel: "true", "tenant_id": "t1"},
+			Annotations: map[string]string{
+				secretstore.RefsAnnotation:     refs,
+				secretstore.WorkloadAnnotation: "Deployment/app-web",
+			},
+		},
+		Data: data,
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	store := fakeStore{"rainbond/t1/db": {Data: map[string]string{"password": "new"}, Version: 2}}
+	tests := []struct {
+		name        string
+		refs        string
+		wantValue   string
+		wantRestart bool
+	}{
+		{"restart", `[{"data_key":"DB_PASSWORD","path":"db","key":"password","restart":true}]`, "new", true},
+		{"no restart", `[{"data_key":"DB_PASSWORD","path":"db","key":"password"}]`, "new", false},
+		{"pinned", `[{"data_key":"DB_PASSWORD","path":"db","key":"password","version":1,"restart":true}]`, "old", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			secret := newSecret(tc.refs, map[string][]byte{"DB_PASSWORD": []byte("old")})
+			deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app-web", Namespace: "ns1"}}
+			client := fake.NewSimpleClientset(secret, deployment)
+			c := NewController(context.Background(), client, 0)
+			if err := c.refresh(store, secret.DeepCopy()); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := client.CoreV1().Secrets("ns1").Get(context.Background(), secret.Name, metav1.GetOptions{})
+			if string(got.Data["DB_PASSWORD"]) != tc.wantValue {
+				t.Errorf("expected value %s, got %s", tc.wantValue, got.Data["DB_PASSWORD"])
+			}
+			d, _ := client.AppsV1().Deployments("ns1").Get(context.Background(), "app-web", metav1.GetOptions{})
+			_, restarted := d.Spec.Template.Annotations[secretstore.ChecksumAnnotation]
+			if restarted != tc.wantRestart {
+				t.Errorf("expected restart %v, got %v", tc.wantRestart, restarted)
+			}
+		})
+	}
+}
@@ -27,18 +27,27 @@ import (
 	"github.com/goodrain/rainbond/cmd/worker/option"
 	"github.com/goodrain/rainbond/db"
 	"github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/mq/client"
 	"github.com/goodrain/rainbond/pkg/common"
 	"github.com/goodrain/rainbond/pkg/generated/clientset/versioned"
 	"github.com/goodrain/rainbond/util/leader"
+	appnetworkpolicy "github.com/goodrain/rainbond/worker/appm/networkpolicy"
 	"github.com/goodrain/rainbond/worker/appm/store"
+	"github.com/goodrain/rainbond/worker/appm/volume"
 	mcontroller "github.com/goodrain/rainbond/worker/master/controller"
 	"github.com/goodrain/rainbond/worker/master/controller/helmapp"
 	"github.com/goodrain/rainbond/worker/master/controller/thirdcomponent"
+	"github.com/goodrain/rainbond/worker/master/externalsecret"
+	"github.com/goodrain/rainbond/worker/master/networkpolicy"
 	"github.com/goodrain/rainbond/worker/master/podevent"
+	"github.com/goodrain/rainbond/worker/master/recommendation"
+	"github.com/goodrain/rainbond/worker/master/scaling"
+	"github.com/goodrain/rainbond/worker/master/snapshot"
 	"github.com/goodrain/rainbond/worker/master/volumes/provider"
 	"github.com/goodrain/rainbond/worker/master/volumes/provider/lib/controller"
 	"github.com/goodrain/rainbond/worker/master/volumes/statistical"
 	"github.com/goodrain/rainbond/worker/master/volumes/sync"
+	snapshotclient "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/version"
@@ -214,6 +223,44 @@ func (m *Controller) Start() error {
 		}
 		m.mgr = mgr
 		m.controllers = append(m.controllers, thirdComponentController)
+
+		// scaling schedules and scale-to-zero
+		mqClient, err := client.NewMqClient(m.conf.MQAPI)
+		if err != nil {
+			logrus.Errorf("new mq client: %v", err)
+			return
+		}
+		defer mqClient.Close()
+		scalingController, err := scaling.NewController(ctx, m.store, m.kubeClient, mqClient, m.conf.PrometheusAPI)
+		if err != nil {
+			logrus.Errorf("create scaling controller: %v", err)
+			return
+		}
+		go scalingController.Start()
+
+		// resource recommendations
+		recommendationController, err := recommendation.NewController(ctx, m.store, mqClient, m.conf.PrometheusAPI, m.conf.RecommendationWindow)
+		if err != nil {
+			logrus.Errorf("create recommendation controller: %v", err)
+			return
+		}
+		go recommendationController.Start()
+
+		// volume snapshot policies
+		snapshotController := snapshot.NewController(ctx, volume.NewSnapshotManager(m.kubeClient, snapshotclient.NewForConfigOrDie(m.restConfig)), mqClient)
+		go snapshotController.Start()
+
+		// network isolation derived from the component dependencies
+		networkPolicyController := networkpolicy.NewController(ctx, m.kubeClient, appnetworkpolicy.Options{
+			SystemNamespace: m.conf.RBDNamespace,
+			AllowCIDRs:      m.conf.NetworkPolicyAllowCIDRs,
+		})
+		go networkPolicyController.Start()
+
+		// the rotation of the external secrets
+		externalSecretController := externalsecret.NewController(ctx, m.kubeClient, m.conf.SecretRefreshInterval)
+		go externalSecretController.Start()
+
 		stopchan := make(chan struct{})
 		go m.mgr.Start(ctx)
 
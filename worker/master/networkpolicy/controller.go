@@ -0,0 +1,215 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/worker/appm/networkpolicy"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Controller keeps the network policies of the isolated components consistent with
+// their dependencies and ports, and removes the policies once the isolation is disabled.
+type Controller struct {
+	ctx        context.Context
+	dbmanager  db.Manager
+	kubeClient kubernetes.Interface
+	opts       networkpolicy.Options
+	interval   time.Duration
+	// labelSupported caches whether kubernetes labels the namespaces with their names
+	labelSupported *bool
+}
+
+// NewController creates a network policy controller
+func NewController(ctx context.Context, kubeClient kubernetes.Interface, opts networkpolicy.Options) *Controller {
+	return &Controller{
+		ctx:        ctx,
+		dbmanager:  db.GetManager(),
+		kubeClient: kubeClient,
+		opts:       opts,
+		interval:   30 * time.Second,
+	}
+}
+
+// Start starts the controller until the context is done
+func (c *Controller) Start() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	logrus.Info("network policy controller start success")
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.sync()
+		}
+	}
+}
+
+func (c *Controller) sync() {
+	rules, err := networkpolicy.ListEnforcedRules(c.dbmanager)
+	if err != nil {
+		// keep the existing policies rather than opening the components
+		logrus.Errorf("list network isolation rules: %v", err)
+		return
+	}
+	opts := c.opts
+	if len(opts.AllowCIDRs) == 0 {
+		// the gateway runs in the host network, its traffic comes from the node addresses
+		opts.AllowCIDRs, err = c.nodeCIDRs()
+		if err != nil || len(opts.AllowCIDRs) == 0 {
+			logrus.Errorf("refuse to enforce the network isolation without the cidrs of the nodes: %v", err)
+			return
+		}
+	}
+	desired := make(map[string]*networkingv1.NetworkPolicy)
+	namespaces := make(map[string]bool)
+	for _, rule := range rules {
+		policy := networkpolicy.Build(rule, opts)
+		desired[policy.Namespace+"/"+policy.Name] = policy
+		for _, peer := range rule.Peers {
+			namespaces[peer.Namespace] = true
+		}
+	}
+	if len(desired) > 0 && opts.SystemNamespace != "" {
+		namespaces[opts.SystemNamespace] = true
+	}
+	if !c.namespaceLabelSupported() {
+		c.labelNamespaces(namespaces)
+	}
+
+	existing, err := c.kubeClient.NetworkingV1().NetworkPolicies(metav1.NamespaceAll).List(c.ctx, metav1.ListOptions{
+		LabelSelector: networkpolicy.IsolationLabel + "=true",
+	})
+	if err != nil {
+		logrus.Errorf("list network policies: %v", err)
+		return
+	}
+	current := make(map[string]*networkingv1.NetworkPolicy)
+	for i := range existing.Items {
+		policy := &existing.Items[i]
+		current[policy.Namespace+"/"+policy.Name] = policy
+	}
+
+	for key, policy := range desired {
+		if policy == nil {
+			continue
+		}
+		old, ok := current[key]
+		if !ok {
+			if _, err := c.kubeClient.NetworkingV1().NetworkPolicies(policy.Namespace).Create(c.ctx, policy, metav1.CreateOptions{}); err != nil {
+				logrus.Warningf("create network policy %s: %v", key, err)
+			}
+			continue
+		}
+		if reflect.DeepEqual(old.Spec, policy.Spec) && reflect.DeepEqual(old.Labels, policy.Labels) {
+			continue
+		}
+		policy.ResourceVersion = old.ResourceVersion
+		if _, err := c.kubeClient.NetworkingV1().NetworkPolicies(policy.Namespace).Update(c.ctx, policy, metav1.UpdateOptions{}); err != nil {
+			logrus.Warningf("update network policy %s: %v", key, err)
+		}
+	}
+	for key, policy := range current {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		if err := c.kubeClient.NetworkingV1().NetworkPolicies(policy.Namespace).Delete(c.ctx, policy.Name, metav1.DeleteOptions{}); err != nil {
+			logrus.Warningf("delete network policy %s: %v", key, err)
+		}
+	}
+}
+
+// nodeCIDRs returns the internal addresses of the nodes as single address cidrs
+func (c *Controller) nodeCIDRs() ([]string, error) {
+	nodes, err := c.kubeClient.CoreV1().Nodes().List(c.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var cidrs []string
+	for _, node := range nodes.Items {
+		for _, address := range node.Status.Addresses {
+			if address.Type != corev1.NodeInternalIP {
+				continue
+			}
+			ip := net.ParseIP(address.Address)
+			if ip == nil {
+				continue
+			}
+			if ip.To4() != nil {
+				cidrs = append(cidrs, ip.String()+"/32")
+			} else {
+				cidrs = append(cidrs, ip.String()+"/128")
+			}
+		}
+	}
+	sort.Strings(cidrs)
+	return cidrs, nil
+}
+
+// namespaceLabelSupported whether kubernetes sets the name label on every namespace, which is added in 1.21
+func (c *Controller) namespaceLabelSupported() bool {
+	if c.labelSupported != nil {
+		return *c.labelSupported
+	}
+	serverVersion, err := c.kubeClient.Discovery().ServerVersion()
+	if err != nil {
+		logrus.Warningf("get kubernetes version: %v", err)
+		return false
+	}
+	version, err := utilversion.ParseGeneric(serverVersion.GitVersion)
+	if err != nil {
+		logrus.Warningf("parse kubernetes version %s: %v", serverVersion.GitVersion, err)
+		return false
+	}
+	supported := version.AtLeast(utilversion.MustParseGeneric("v1.21.0"))
+	c.labelSupported = &supported
+	return supported
+}
+
+// labelNamespaces sets the name label on the namespaces the policies select, for the kubernetes before 1.21
+func (c *Controller) labelNamespaces(namespaces map[string]bool) {
+	for namespace := range namespaces {
+		ns, err := c.kubeClient.CoreV1().Namespaces().Get(c.ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			logrus.Warningf("get namespace %s: %v", namespace, err)
+			continue
+		}
+		if ns.Labels[networkpolicy.NamespaceNameLabel] == namespace {
+			continue
+		}
+		patch := []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, networkpolicy.NamespaceNameLabel, namespace))
+		if _, err := c.kubeClient.CoreV1().Namespaces().Patch(c.ctx, namespace, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			logrus.Warningf("label namespace %s: %v", namespace, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+
+package networkpolicy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/goodrain/rainbond/worker/appm/networkpolicy"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNodeCIDRs(t *testing.T) {
+	node := func(name string, addresses ...corev1.NodeAddress) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.NodeStatus{Addresses: addresses}}
+	}
+	client := fake.NewSimpleClientset(
+		node("n1", corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "192.168.0.2"}, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "1.2.3.4"}),
+		node("n2", corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "fd00::1"}),
+	)
+	c := &Controller{ctx: context.Background(), kubeClient: client}
+	cidrs, err := c.nodeCIDRs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"192.168.0.2/32", "fd00::1/128"}; !reflect.DeepEqual(cidrs, want) {
+		t.Errorf("want %v, got %v", want, cidrs)
+	}
+}
+
+func TestLabelNamespacesBefore121(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "rbd-system"}})
+	client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.19.16"}
+	c := &Controller{ctx: context.Background(), kubeClient: client}
+	if c.namespaceLabelSupported() {
+		t.Fatal("the namespace name label is not supported before 1.21")
+	}
+	c.labelNamespaces(map[string]bool{"rbd-system": true})
+	ns, err := client.CoreV1().Namespaces().Get(context.Background(), "rbd-system", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.Labels[networkpolicy.NamespaceNameLabel] != "rbd-system" {
+		t.Errorf("want the name label set, got %v", ns.Labels)
+	}
+
+	client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.24.3"}
+	c = &Controller{ctx: context.Background(), kubeClient: client}
+	if !c.namespaceLabelSupported() {
+		t.Error("the namespace name label is supported since 1.21")
+	}
+}
@@ -0,0 +1,311 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/goodrain/rainbond/api/client/prometheus"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/mq/client"
+	"github.com/goodrain/rainbond/worker/appm/store"
+	v1 "github.com/goodrain/rainbond/worker/appm/types/v1"
+	discovermodel "github.com/goodrain/rainbond/worker/discover/model"
+	"github.com/jinzhu/gorm"
+	promodel "github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// minCPU the minimum recommended cpu in millicores
+	minCPU = 10
+	// minMemory the minimum recommended memory in MB
+	minMemory = 32
+	// margin the headroom added to the percentiles
+	margin = 1.15
+	// memoryLimitMargin the headroom added to the max memory usage
+	memoryLimitMargin = 1.2
+	// significantChange the applied resources differ from the recommendation by more than the ratio
+	significantChange = 0.1
+)
+
+// Usage the historical resource usage of a container, cpu in millicores and memory in MB
+type Usage struct {
+	CPUP90    float64
+	CPUP99    float64
+	MemoryP95 float64
+	MemoryMax float64
+}
+
+// Controller computes the resource recommendations of the running components from the
+// historical usage, and applies them during the off-peak windows if the policy allows.
+type Controller struct {
+	ctx        context.Context
+	dbmanager  db.Manager
+	store      store.Storer
+	mqclient   client.MQClient
+	prometheus prometheus.Interface
+	window     time.Duration
+	// interval the interval of computing the recommendations
+	interval      time.Duration
+	lastRecommend time.Time
+}
+
+// NewController creates a recommendation controller
+func NewController(ctx context.Context, store store.Storer, mqclient client.MQClient, prometheusAPI string, window time.Duration) (*Controller, error) {
+	prom, err := prometheus.NewPrometheus(&prometheus.Options{Endpoint: prometheusAPI})
+	if err != nil {
+		return nil, err
+	}
+	return &Controller{
+		ctx:        ctx,
+		dbmanager:  db.GetManager(),
+		store:      store,
+		mqclient:   mqclient,
+		prometheus: prom,
+		window:     window,
+		interval:   time.Hour,
+	}, nil
+}
+
+// Start starts the controller until the context is done
+func (c *Controller) Start() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+	logrus.Info("recommendation controller start success")
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if now.Sub(c.lastRecommend) >= c.interval {
+				c.recommendAll(now)
+				c.lastRecommend = now
+			}
+			c.autoApply(now)
+		}
+	}
+}
+
+func (c *Controller) recommendAll(now time.Time) {
+	for _, app := range c.store.GetAllAppServices() {
+		if app.IsClosed() {
+			continue
+		}
+		if err := c.recommend(app, now); err != nil {
+			logrus.Warningf("recommend resources for component %s: %v", app.ServiceID, err)
+		}
+	}
+}
+
+func (c *Controller) recommend(app *v1.AppService, now time.Time) error {
+	selector := fmt.Sprintf(`namespace="%s",pod=~"%s-.*",container="%s"`, app.GetNamespace(), app.GetK8sWorkloadName(), app.K8sComponentName)
+	usage, err := c.queryUsage(selector, now)
+	if err != nil {
+		return err
+	}
+	if usage == nil {
+		// not enough data yet
+		return nil
+	}
+	rec := Recommend(*usage)
+	rec.ServiceID = app.ServiceID
+	rec.TenantID = app.TenantID
+	rec.Window = promodel.Duration(c.window).String()
+	rec.RecommendTime = &now
+
+	old, err := c.dbmanager.TenantServiceResourceRecommendationDao().GetByServiceID(app.ServiceID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		return c.dbmanager.TenantServiceResourceRecommendationDao().AddModel(rec)
+	}
+	rec.Model = old.Model
+	return c.dbmanager.TenantServiceResourceRecommendationDao().UpdateModel(rec)
+}
+
+// queryUsage queries the usage of the container over the window, nil if there is no data
+func (c *Controller) queryUsage(selector string, now time.Time) (*Usage, error) {
+	window := promodel.Duration(c.window).String()
+	var usage Usage
+	queries := []struct {
+		expr  string
+		value *float64
+	}{
+		{expr: fmt.Sprintf(`max(quantile_over_time(0.9, rate(container_cpu_usage_seconds_total{%s}[5m])[%s:5m])) * 1000`, selector, window), value: &usage.CPUP90},
+		{expr: fmt.Sprintf(`max(quantile_over_time(0.99, rate(container_cpu_usage_seconds_total{%s}[5m])[%s:5m])) * 1000`, selector, window), value: &usage.CPUP99},
+		{expr: fmt.Sprintf(`max(quantile_over_time(0.95, container_memory_working_set_bytes{%s}[%s])) / 1024 / 1024`, selector, window), value: &usage.MemoryP95},
+		{expr: fmt.Sprintf(`max(max_over_time(container_memory_working_set_bytes{%s}[%s])) / 1024 / 1024`, selector, window), value: &usage.MemoryMax},
+	}
+	for _, query := range queries {
+		metric := c.prometheus.GetMetric(query.expr, now)
+		if metric.Error != "" {
+			return nil, fmt.Errorf(metric.Error)
+		}
+		if len(metric.MetricValues) == 0 || metric.MetricValues[0].Sample == nil {
+			return nil, nil
+		}
+		*query.value = metric.MetricValues[0].Sample.Value()
+	}
+	return &usage, nil
+}
+
+// Recommend computes the recommended requests and limits from the usage
+func Recommend(usage Usage) *model.TenantServiceResourceRecommendation {
+	rec := &model.TenantServiceResourceRecommendation{
+		CPURequest:    roundUp(usage.CPUP90*margin, 10, minCPU),
+		CPULimit:      roundUp(usage.CPUP99*margin, 10, minCPU),
+		MemoryRequest: roundUp(usage.MemoryP95*margin, 16, minMemory),
+		MemoryLimit:   roundUp(usage.MemoryMax*memoryLimitMargin, 16, minMemory),
+	}
+	if rec.CPULimit < rec.CPURequest {
+		rec.CPULimit = rec.CPURequest
+	}
+	if rec.MemoryLimit < rec.MemoryRequest {
+		rec.MemoryLimit = rec.MemoryRequest
+	}
+	return rec
+}
+
+// roundUp rounds the value up to the multiple of step, no less than min
+func roundUp(value float64, step, min int) int {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return min
+	}
+	v := int(math.Ceil(value/float64(step))) * step
+	if v < min {
+		return min
+	}
+	return v
+}
+
+// autoApply applies the recommendations of the components whose policies allow it during the off-peak windows,
+// at most once a day.
+func (c *Controller) autoApply(now time.Time) {
+	policies, err := c.dbmanager.TenantServiceRecommendationPolicyDao().ListAutoApply()
+	if err != nil {
+		logrus.Errorf("list recommendation policies: %v", err)
+		return
+	}
+	for _, policy := range policies {
+		ok, err := inApplyWindow(policy, now)
+		if err != nil {
+			logrus.Warningf("recommendation policy of component %s: %v", policy.ServiceID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := c.apply(policy, now); err != nil {
+			logrus.Errorf("apply resource recommendation of component %s: %v", policy.ServiceID, err)
+		}
+	}
+}
+
+func (c *Controller) apply(policy *model.TenantServiceRecommendationPolicy, now time.Time) error {
+	app := c.store.GetAppService(policy.ServiceID)
+	if app == nil || app.IsClosed() {
+		return nil
+	}
+	rec, err := c.dbmanager.TenantServiceResourceRecommendationDao().GetByServiceID(policy.ServiceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+	service, err := c.dbmanager.TenantServiceDao().GetServiceByID(policy.ServiceID)
+	if err != nil {
+		return err
+	}
+	task := &discovermodel.VerticalScalingTaskBody{
+		TenantID:  service.TenantID,
+		ServiceID: service.ServiceID,
+	}
+	// the resources not limited are left as they are
+	if changed(service.ContainerCPU, rec.CPULimit) {
+		service.ContainerCPU = rec.CPULimit
+		task.ContainerCPU = &rec.CPULimit
+	}
+	if changed(service.ContainerMemory, rec.MemoryLimit) {
+		service.ContainerMemory = rec.MemoryLimit
+		task.ContainerMemory = &rec.MemoryLimit
+	}
+	policy.LastApplyTime = &now
+	if err := c.dbmanager.TenantServiceRecommendationPolicyDao().UpdateModel(policy); err != nil {
+		return err
+	}
+	if task.ContainerCPU == nil && task.ContainerMemory == nil {
+		return nil
+	}
+	logrus.Infof("apply resource recommendation of component %s, cpu: %d, memory: %d", service.ServiceID, service.ContainerCPU, service.ContainerMemory)
+	if err := c.dbmanager.TenantServiceDao().UpdateModel(service); err != nil {
+		return err
+	}
+	return c.mqclient.SendBuilderTopic(client.TaskStruct{
+		TaskType: "vertical_scaling",
+		TaskBody: task,
+		Topic:    client.WorkerTopic,
+	})
+}
+
+// changed reports whether the recommended value differs from the current one significantly
+func changed(current, recommended int) bool {
+	if current <= 0 || recommended <= 0 {
+		return false
+	}
+	return math.Abs(float64(recommended-current))/float64(current) > significantChange
+}
+
+// inApplyWindow reports whether now is in the off-peak window of the policy and
+// the recommendation has not been applied in the window yet.
+func inApplyWindow(policy *model.TenantServiceRecommendationPolicy, now time.Time) (bool, error) {
+	loc, err := time.LoadLocation(policy.Timezone)
+	if err != nil {
+		return false, err
+	}
+	start, err := time.Parse("15:04", policy.ApplyWindowStart)
+	if err != nil {
+		return false, err
+	}
+	end, err := time.Parse("15:04", policy.ApplyWindowEnd)
+	if err != nil {
+		return false, err
+	}
+	now = now.In(loc)
+	windowStart := time.Date(now.Year(), now.Month(), now.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+	windowEnd := time.Date(now.Year(), now.Month(), now.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if !windowEnd.After(windowStart) {
+		// the window spans midnight
+		if now.Before(windowEnd) {
+			windowStart = windowStart.AddDate(0, 0, -1)
+		} else {
+			windowEnd = windowEnd.AddDate(0, 0, 1)
+		}
+	}
+	if now.Before(windowStart) || !now.Before(windowEnd) {
+		return false, nil
+	}
+	return policy.LastApplyTime == nil || policy.LastApplyTime.Before(windowStart), nil
+}
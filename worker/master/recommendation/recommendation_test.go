@@ -0,0 +1,89 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package recommendation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/db/model"
+)
+
+func TestRecommend(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage Usage
+		want  model.TenantServiceResourceRecommendation
+	}{
+		{
+			name:  "normal",
+			usage: Usage{CPUP90: 200, CPUP99: 400, MemoryP95: 500, MemoryMax: 600},
+			want:  model.TenantServiceResourceRecommendation{CPURequest: 230, CPULimit: 460, MemoryRequest: 576, MemoryLimit: 720},
+		},
+		{
+			name:  "idle",
+			usage: Usage{CPUP90: 0.5, CPUP99: 1, MemoryP95: 3, MemoryMax: 4},
+			want:  model.TenantServiceResourceRecommendation{CPURequest: minCPU, CPULimit: minCPU, MemoryRequest: minMemory, MemoryLimit: minMemory},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Recommend(tc.usage)
+			if got.CPURequest != tc.want.CPURequest || got.CPULimit != tc.want.CPULimit ||
+				got.MemoryRequest != tc.want.MemoryRequest || got.MemoryLimit != tc.want.MemoryLimit {
+				t.Errorf("want %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestInApplyWindow(t *testing.T) {
+	applied := time.Date(2024, 3, 4, 2, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		start, end  string
+		lastApplied *time.Time
+		now         time.Time
+		want        bool
+	}{
+		{name: "in window", start: "02:00", end: "04:00", now: time.Date(2024, 3, 4, 3, 0, 0, 0, time.UTC), want: true},
+		{name: "out of window", start: "02:00", end: "04:00", now: time.Date(2024, 3, 4, 4, 0, 0, 0, time.UTC)},
+		{name: "applied in window", start: "02:00", end: "04:00", lastApplied: &applied, now: time.Date(2024, 3, 4, 3, 0, 0, 0, time.UTC)},
+		{name: "applied yesterday", start: "02:00", end: "04:00", lastApplied: &applied, now: time.Date(2024, 3, 5, 3, 0, 0, 0, time.UTC), want: true},
+		{name: "span midnight before", start: "23:00", end: "01:00", now: time.Date(2024, 3, 4, 23, 30, 0, 0, time.UTC), want: true},
+		{name: "span midnight after", start: "23:00", end: "01:00", now: time.Date(2024, 3, 4, 0, 30, 0, 0, time.UTC), want: true},
+		{name: "span midnight out", start: "23:00", end: "01:00", now: time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &model.TenantServiceRecommendationPolicy{
+				ApplyWindowStart: tc.start,
+				ApplyWindowEnd:   tc.end,
+				LastApplyTime:    tc.lastApplied,
+			}
+			got, err := inApplyWindow(policy, tc.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
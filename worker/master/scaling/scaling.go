@@ -0,0 +1,302 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/goodrain/rainbond/api/client/prometheus"
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/gateway/annotations/scaletozero"
+	"github.com/goodrain/rainbond/mq/client"
+	"github.com/goodrain/rainbond/worker/appm/store"
+	discovermodel "github.com/goodrain/rainbond/worker/discover/model"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Controller executes the scaling schedules and scales the idle components to zero
+type Controller struct {
+	ctx        context.Context
+	dbmanager  db.Manager
+	store      store.Storer
+	kubeClient kubernetes.Interface
+	mqclient   client.MQClient
+	prometheus prometheus.Interface
+	interval   time.Duration
+	// runningSince the time the controller found the component running,
+	// the component will not be scaled to zero until it has been running for the idle seconds.
+	runningSince map[string]time.Time
+	wakeCh       chan string
+}
+
+// NewController creates a scaling controller
+func NewController(ctx context.Context, store store.Storer, kubeClient kubernetes.Interface, mqclient client.MQClient, prometheusAPI string) (*Controller, error) {
+	prom, err := prometheus.NewPrometheus(&prometheus.Options{Endpoint: prometheusAPI})
+	if err != nil {
+		return nil, err
+	}
+	return &Controller{
+		ctx:          ctx,
+		dbmanager:    db.GetManager(),
+		store:        store,
+		kubeClient:   kubeClient,
+		mqclient:     mqclient,
+		prometheus:   prom,
+		interval:     time.Minute,
+		runningSince: make(map[string]time.Time),
+		wakeCh:       make(chan string, 100),
+	}, nil
+}
+
+// Start starts the controller until the context is done
+func (c *Controller) Start() {
+	factory := informers.NewSharedInformerFactoryWithOptions(c.kubeClient, 10*time.Minute,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = "creator=Rainbond"
+		}))
+	informer := factory.Core().V1().Services().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			osvc, ok1 := old.(*corev1.Service)
+			csvc, ok2 := cur.(*corev1.Service)
+			if !ok1 || !ok2 {
+				return
+			}
+			wakeUpAt := csvc.Annotations[scaletozero.WakeUpAnnotation]
+			if wakeUpAt == "" || wakeUpAt == osvc.Annotations[scaletozero.WakeUpAnnotation] {
+				return
+			}
+			serviceID := csvc.Labels["service_id"]
+			if serviceID == "" {
+				return
+			}
+			select {
+			case c.wakeCh <- serviceID:
+			default:
+				logrus.Warningf("too many components to wake up, ignore %s", serviceID)
+			}
+		},
+	})
+	go informer.Run(c.ctx.Done())
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	logrus.Info("scaling controller start success")
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case serviceID := <-c.wakeCh:
+			c.wakeUp(serviceID)
+		case now := <-ticker.C:
+			c.runSchedules(now)
+			c.scaleIdleToZero(now)
+		}
+	}
+}
+
+// runSchedules scales the components whose schedules are due. When several schedules of
+// a component are due, only the latest one takes effect.
+func (c *Controller) runSchedules(now time.Time) {
+	schedules, err := c.dbmanager.TenantServiceScalingScheduleDao().ListEnable()
+	if err != nil {
+		logrus.Errorf("list scaling schedules: %v", err)
+		return
+	}
+	latest := make(map[string]*model.TenantServiceScalingSchedule)
+	latestRun := make(map[string]time.Time)
+	for _, schedule := range schedules {
+		if schedule.LastScheduleTime == nil {
+			c.updateLastScheduleTime(schedule, now)
+			continue
+		}
+		run, err := latestScheduleTime(schedule, *schedule.LastScheduleTime, now)
+		if err != nil {
+			logrus.Warningf("scaling schedule %s: %v", schedule.ScheduleID, err)
+			continue
+		}
+		if run.IsZero() {
+			continue
+		}
+		c.updateLastScheduleTime(schedule, now)
+		if run.After(latestRun[schedule.ServiceID]) {
+			latest[schedule.ServiceID] = schedule
+			latestRun[schedule.ServiceID] = run
+		}
+	}
+	for serviceID, schedule := range latest {
+		if err := c.scale(serviceID, schedule.Replicas, schedule.ScheduleID, model.ScheduleScalingRecord); err != nil {
+			logrus.Errorf("execute scaling schedule %s of component %s: %v", schedule.ScheduleID, serviceID, err)
+		}
+	}
+}
+
+func (c *Controller) updateLastScheduleTime(schedule *model.TenantServiceScalingSchedule, now time.Time) {
+	schedule.LastScheduleTime = &now
+	if err := c.dbmanager.TenantServiceScalingScheduleDao().UpdateModel(schedule); err != nil {
+		logrus.Warningf("update last schedule time of %s: %v", schedule.ScheduleID, err)
+	}
+}
+
+// latestScheduleTime returns the latest time in (last, now] the schedule should run, zero if none.
+func latestScheduleTime(schedule *model.TenantServiceScalingSchedule, last, now time.Time) (time.Time, error) {
+	sched, err := cron.ParseStandard(schedule.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var latest time.Time
+	for t := sched.Next(last.In(loc)); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		latest = t
+	}
+	return latest, nil
+}
+
+// scaleIdleToZero scales the components that have received no requests for the idle seconds to zero
+func (c *Controller) scaleIdleToZero(now time.Time) {
+	stzs, err := c.dbmanager.TenantServiceScaleToZeroDao().ListEnable()
+	if err != nil {
+		logrus.Errorf("list scale-to-zero components: %v", err)
+		return
+	}
+	enabled := make(map[string]struct{}, len(stzs))
+	for _, stz := range stzs {
+		enabled[stz.ServiceID] = struct{}{}
+		service, err := c.dbmanager.TenantServiceDao().GetServiceByID(stz.ServiceID)
+		if err != nil {
+			logrus.Warningf("get component %s: %v", stz.ServiceID, err)
+			continue
+		}
+		appService := c.store.GetAppService(stz.ServiceID)
+		if service.Replicas == 0 || appService == nil || appService.IsClosed() {
+			delete(c.runningSince, stz.ServiceID)
+			continue
+		}
+		since, ok := c.runningSince[stz.ServiceID]
+		if !ok {
+			c.runningSince[stz.ServiceID] = now
+			continue
+		}
+		if now.Sub(since) < time.Duration(stz.IdleSeconds)*time.Second {
+			continue
+		}
+		idle, err := c.isIdle(stz.ServiceID, stz.IdleSeconds)
+		if err != nil {
+			logrus.Warningf("query requests of component %s: %v", stz.ServiceID, err)
+			continue
+		}
+		if !idle {
+			continue
+		}
+		logrus.Infof("component %s received no requests in %d seconds, scale it to zero", stz.ServiceID, stz.IdleSeconds)
+		stz.WakeReplicas = service.Replicas
+		if err := c.dbmanager.TenantServiceScaleToZeroDao().UpdateModel(stz); err != nil {
+			logrus.Errorf("record wake replicas of component %s: %v", stz.ServiceID, err)
+			continue
+		}
+		if err := c.scale(stz.ServiceID, 0, "", model.ScaleToZeroScalingRecord); err != nil {
+			logrus.Errorf("scale component %s to zero: %v", stz.ServiceID, err)
+			continue
+		}
+		delete(c.runningSince, stz.ServiceID)
+	}
+	for serviceID := range c.runningSince {
+		if _, ok := enabled[serviceID]; !ok {
+			delete(c.runningSince, serviceID)
+		}
+	}
+}
+
+func (c *Controller) isIdle(serviceID string, idleSeconds int) (bool, error) {
+	expr := fmt.Sprintf(`sum(increase(gateway_requests{service_id="%s"}[%ds]))`, serviceID, idleSeconds)
+	metric := c.prometheus.GetMetric(expr, time.Now())
+	if metric.Error != "" {
+		return false, fmt.Errorf(metric.Error)
+	}
+	for _, value := range metric.MetricValues {
+		if value.Sample != nil && value.Sample.Value() > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// wakeUp restores the replicas of the component scaled to zero
+func (c *Controller) wakeUp(serviceID string) {
+	stz, err := c.dbmanager.TenantServiceScaleToZeroDao().GetByServiceID(serviceID)
+	if err != nil || !stz.Enable || stz.WakeReplicas == 0 {
+		return
+	}
+	service, err := c.dbmanager.TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		logrus.Warningf("get component %s: %v", serviceID, err)
+		return
+	}
+	if service.Replicas > 0 {
+		return
+	}
+	logrus.Infof("requests arrived, wake up component %s", serviceID)
+	if err := c.scale(serviceID, stz.WakeReplicas, "", model.ScaleToZeroScalingRecord); err != nil {
+		logrus.Errorf("wake up component %s: %v", serviceID, err)
+	}
+}
+
+// scale updates the replicas of the component and sends the horizontal scaling task to the worker
+func (c *Controller) scale(serviceID string, replicas int, ruleID, recordType string) error {
+	service, err := c.dbmanager.TenantServiceDao().GetServiceByID(serviceID)
+	if err != nil {
+		return err
+	}
+	appService := c.store.GetAppService(serviceID)
+	if appService == nil || appService.IsClosed() {
+		logrus.Debugf("component %s is closed, no need to scale", serviceID)
+		return nil
+	}
+	if service.Replicas == replicas {
+		return nil
+	}
+	service.Replicas = replicas
+	if err := c.dbmanager.TenantServiceDao().UpdateModel(service); err != nil {
+		return err
+	}
+	return c.mqclient.SendBuilderTopic(client.TaskStruct{
+		TaskType: "horizontal_scaling",
+		TaskBody: discovermodel.HorizontalScalingTaskBody{
+			TenantID:   service.TenantID,
+			ServiceID:  serviceID,
+			Replicas:   int32(replicas),
+			Username:   "system",
+			RuleID:     ruleID,
+			RecordType: recordType,
+		},
+		Topic: client.WorkerTopic,
+	})
+}
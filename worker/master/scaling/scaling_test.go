@@ -0,0 +1,85 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package scaling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goodrain/rainbond/db/model"
+)
+
+func TestLatestScheduleTime(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	tests := []struct {
+		name     string
+		schedule string
+		timezone string
+		last     time.Time
+		now      time.Time
+		want     time.Time
+	}{
+		{
+			name:     "not due",
+			schedule: "0 20 * * 1-5",
+			last:     time.Date(2024, 3, 4, 19, 58, 0, 0, time.UTC),
+			now:      time.Date(2024, 3, 4, 19, 59, 0, 0, time.UTC),
+		},
+		{
+			name:     "due",
+			schedule: "0 20 * * 1-5",
+			last:     time.Date(2024, 3, 4, 19, 59, 0, 0, time.UTC),
+			now:      time.Date(2024, 3, 4, 20, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 4, 20, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekend",
+			schedule: "0 20 * * 1-5",
+			last:     time.Date(2024, 3, 9, 19, 59, 0, 0, time.UTC),
+			now:      time.Date(2024, 3, 9, 20, 1, 0, 0, time.UTC),
+		},
+		{
+			name:     "the latest missed run",
+			schedule: "0 * * * *",
+			last:     time.Date(2024, 3, 4, 8, 30, 0, 0, time.UTC),
+			now:      time.Date(2024, 3, 4, 11, 30, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 4, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone",
+			schedule: "0 8 * * *",
+			timezone: "Asia/Shanghai",
+			last:     time.Date(2024, 3, 3, 23, 59, 0, 0, time.UTC),
+			now:      time.Date(2024, 3, 4, 0, 1, 0, 0, time.UTC),
+			want:     time.Date(2024, 3, 4, 8, 0, 0, 0, shanghai),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule := &model.TenantServiceScalingSchedule{Schedule: tc.schedule, Timezone: tc.timezone}
+			got, err := latestScheduleTime(schedule, tc.last, tc.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"context"
+	"time"
+
+	"github.com/goodrain/rainbond/db"
+	"github.com/goodrain/rainbond/db/model"
+	"github.com/goodrain/rainbond/mq/client"
+	"github.com/goodrain/rainbond/util"
+	"github.com/goodrain/rainbond/worker/appm/volume"
+	discovermodel "github.com/goodrain/rainbond/worker/discover/model"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// Controller executes the snapshot policies, deletes the snapshots beyond the retention
+// and synchronizes the status of the snapshots being taken.
+type Controller struct {
+	ctx             context.Context
+	dbmanager       db.Manager
+	snapshotManager *volume.SnapshotManager
+	mqclient        client.MQClient
+	interval        time.Duration
+}
+
+// NewController creates a snapshot controller
+func NewController(ctx context.Context, snapshotManager *volume.SnapshotManager, mqclient client.MQClient) *Controller {
+	return &Controller{
+		ctx:             ctx,
+		dbmanager:       db.GetManager(),
+		snapshotManager: snapshotManager,
+		mqclient:        mqclient,
+		interval:        time.Minute,
+	}
+}
+
+// Start starts the controller until the context is done
+func (c *Controller) Start() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	logrus.Info("snapshot controller start success")
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.runPolicies(now)
+			c.syncSnapshots()
+		}
+	}
+}
+
+func (c *Controller) runPolicies(now time.Time) {
+	policies, err := c.dbmanager.TenantServiceSnapshotPolicyDao().ListEnable()
+	if err != nil {
+		logrus.Errorf("list snapshot policies: %v", err)
+		return
+	}
+	for _, policy := range policies {
+		if policy.LastScheduleTime == nil {
+			c.updateLastScheduleTime(policy, now)
+			continue
+		}
+		due, err := isDue(policy, *policy.LastScheduleTime, now)
+		if err != nil {
+			logrus.Warningf("snapshot policy %s: %v", policy.PolicyID, err)
+			continue
+		}
+		if !due {
+			continue
+		}
+		c.updateLastScheduleTime(policy, now)
+		volumeNames, err := c.policyVolumes(policy)
+		if err != nil {
+			logrus.Errorf("list volumes of snapshot policy %s: %v", policy.PolicyID, err)
+			continue
+		}
+		for _, volumeName := range volumeNames {
+			if err := c.takeSnapshot(policy, volumeName); err != nil {
+				logrus.Errorf("take snapshot of volume %s by policy %s: %v", volumeName, policy.PolicyID, err)
+				continue
+			}
+			c.enforceRetention(policy, volumeName)
+		}
+	}
+}
+
+func (c *Controller) updateLastScheduleTime(policy *model.TenantServiceSnapshotPolicy, now time.Time) {
+	policy.LastScheduleTime = &now
+	if err := c.dbmanager.TenantServiceSnapshotPolicyDao().UpdateModel(policy); err != nil {
+		logrus.Warningf("update last schedule time of %s: %v", policy.PolicyID, err)
+	}
+}
+
+// isDue returns whether the policy should run in (last, now]
+func isDue(policy *model.TenantServiceSnapshotPolicy, last, now time.Time) (bool, error) {
+	sched, err := cron.ParseStandard(policy.Schedule)
+	if err != nil {
+		return false, err
+	}
+	loc, err := time.LoadLocation(policy.Timezone)
+	if err != nil {
+		return false, err
+	}
+	next := sched.Next(last.In(loc))
+	return !next.IsZero() && !next.After(now), nil
+}
+
+// policyVolumes returns the volumes of the policy, the volumes not backed by claims are skipped
+func (c *Controller) policyVolumes(policy *model.TenantServiceSnapshotPolicy) ([]string, error) {
+	if policy.VolumeName != "" {
+		return []string{policy.VolumeName}, nil
+	}
+	volumes, err := c.dbmanager.TenantServiceVolumeDao().GetTenantServiceVolumesByServiceID(policy.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, vol := range volumes {
+		switch vol.VolumeType {
+		case model.ConfigFileVolumeType.String(), model.MemoryFSVolumeType.String():
+			continue
+		}
+		names = append(names, vol.VolumeName)
+	}
+	return names, nil
+}
+
+// takeSnapshot records the snapshot and sends the task taking it to the worker
+func (c *Controller) takeSnapshot(policy *model.TenantServiceSnapshotPolicy, volumeName string) error {
+	snapshot := &model.TenantServiceVolumeSnapshot{
+		SnapshotID: util.NewUUID(),
+		TenantID:   policy.TenantID,
+		ServiceID:  policy.ServiceID,
+		VolumeName: volumeName,
+		PolicyID:   policy.PolicyID,
+		Status:     model.VolumeSnapshotStatusCreating,
+	}
+	if err := c.dbmanager.TenantServiceVolumeSnapshotDao().AddModel(snapshot); err != nil {
+		return err
+	}
+	eventID, err := c.createSystemEvent(snapshot, "create-volume-snapshot")
+	if err != nil {
+		logrus.Warningf("create event of volume snapshot %s: %v", snapshot.SnapshotID, err)
+	}
+	return c.sendTask("create_volume_snapshot", snapshot, eventID)
+}
+
+// enforceRetention deletes the oldest snapshots of the volume beyond the retention of the policy
+func (c *Controller) enforceRetention(policy *model.TenantServiceSnapshotPolicy, volumeName string) {
+	if policy.Retention <= 0 {
+		return
+	}
+	snapshots, err := c.dbmanager.TenantServiceVolumeSnapshotDao().ListByPolicyID(policy.PolicyID, volumeName)
+	if err != nil {
+		logrus.Warningf("list snapshots of policy %s: %v", policy.PolicyID, err)
+		return
+	}
+	for _, snapshot := range expiredSnapshots(snapshots, policy.Retention) {
+		snapshot.Status = model.VolumeSnapshotStatusDeleting
+		if err := c.dbmanager.TenantServiceVolumeSnapshotDao().UpdateModel(snapshot); err != nil {
+			logrus.Warningf("update volume snapshot %s: %v", snapshot.SnapshotID, err)
+			continue
+		}
+		eventID, err := c.createSystemEvent(snapshot, "delete-volume-snapshot")
+		if err != nil {
+			logrus.Warningf("create event of volume snapshot %s: %v", snapshot.SnapshotID, err)
+		}
+		if err := c.sendTask("delete_volume_snapshot", snapshot, eventID); err != nil {
+			logrus.Errorf("send task deleting volume snapshot %s: %v", snapshot.SnapshotID, err)
+		}
+	}
+}
+
+// expiredSnapshots returns the snapshots beyond the retention, the snapshots are ordered from the newest.
+// The snapshots being restored or deleted are skipped.
+func expiredSnapshots(snapshots []*model.TenantServiceVolumeSnapshot, retention int) []*model.TenantServiceVolumeSnapshot {
+	var kept int
+	var expired []*model.TenantServiceVolumeSnapshot
+	for _, snapshot := range snapshots {
+		switch snapshot.Status {
+		case model.VolumeSnapshotStatusDeleting, model.VolumeSnapshotStatusRestoring:
+			continue
+		}
+		if kept < retention {
+			kept++
+			continue
+		}
+		expired = append(expired, snapshot)
+	}
+	return expired
+}
+
+// syncSnapshots synchronizes the status of the snapshots being taken from the csi VolumeSnapshots
+func (c *Controller) syncSnapshots() {
+	snapshots, err := c.dbmanager.TenantServiceVolumeSnapshotDao().ListByStatus(model.VolumeSnapshotStatusCreating)
+	if err != nil {
+		logrus.Errorf("list creating volume snapshots: %v", err)
+		return
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.ClaimCount == 0 {
+			// the worker has not taken the snapshot yet
+			continue
+		}
+		tenant, err := c.dbmanager.TenantDao().GetTenantByUUID(snapshot.TenantID)
+		if err != nil {
+			logrus.Warningf("get tenant %s: %v", snapshot.TenantID, err)
+			continue
+		}
+		changed, err := c.snapshotManager.SyncSnapshot(c.ctx, tenant.Namespace, snapshot)
+		if err != nil {
+			logrus.Warningf("sync volume snapshot %s: %v", snapshot.SnapshotID, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err := c.dbmanager.TenantServiceVolumeSnapshotDao().UpdateModel(snapshot); err != nil {
+			logrus.Warningf("update volume snapshot %s: %v", snapshot.SnapshotID, err)
+		}
+	}
+}
+
+func (c *Controller) createSystemEvent(snapshot *model.TenantServiceVolumeSnapshot, optType string) (string, error) {
+	eventID := util.NewUUID()
+	et := &model.ServiceEvent{
+		EventID:   eventID,
+		TenantID:  snapshot.TenantID,
+		ServiceID: snapshot.ServiceID,
+		Target:    model.TargetTypeService,
+		TargetID:  snapshot.ServiceID,
+		UserName:  model.UsernameSystem,
+		OptType:   optType,
+		StartTime: time.Now().Format(time.RFC3339),
+		SynType:   model.ASYNEVENTTYPE,
+	}
+	return eventID, c.dbmanager.ServiceEventDao().AddModel(et)
+}
+
+func (c *Controller) sendTask(taskType string, snapshot *model.TenantServiceVolumeSnapshot, eventID string) error {
+	return c.mqclient.SendBuilderTopic(client.TaskStruct{
+		TaskType: taskType,
+		TaskBody: discovermodel.VolumeSnapshotTaskBody{
+			TenantID:   snapshot.TenantID,
+			ServiceID:  snapshot.ServiceID,
+			SnapshotID: snapshot.SnapshotID,
+			EventID:    eventID,
+		},
+		Topic: client.WorkerTopic,
+	})
+}
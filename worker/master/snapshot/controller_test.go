@@ -0,0 +1,47 @@
+// RAINBOND, Application Management Platform
+// Copyright (C) 2014-2024 Goodrain Co., Ltd.
+
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version. For any non-GPL usage of Rainbond,
+// one or multiple Commercial Licenses authorized by Goodrain Co., Ltd.
+// must be obtained first.
+
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/goodrain/rainbond/db/model"
+)
+
+func TestExpiredSnapshots(t *testing.T) {
+	// ordered from the newest
+	snapshots := []*model.TenantServiceVolumeSnapshot{
+		{SnapshotID: "s5", Status: model.VolumeSnapshotStatusCreating},
+		{SnapshotID: "s4", Status: model.VolumeSnapshotStatusReady},
+		{SnapshotID: "s3", Status: model.VolumeSnapshotStatusRestoring},
+		{SnapshotID: "s2", Status: model.VolumeSnapshotStatusFailed},
+		{SnapshotID: "s1", Status: model.VolumeSnapshotStatusDeleting},
+		{SnapshotID: "s0", Status: model.VolumeSnapshotStatusReady},
+	}
+	var got []string
+	for _, snapshot := range expiredSnapshots(snapshots, 2) {
+		got = append(got, snapshot.SnapshotID)
+	}
+	if len(got) != 2 || got[0] != "s2" || got[1] != "s0" {
+		t.Errorf("expected [s2 s0], got %v", got)
+	}
+	if expired := expiredSnapshots(snapshots, 10); len(expired) != 0 {
+		t.Errorf("expected no expired snapshots, got %d", len(expired))
+	}
+}
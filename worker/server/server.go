@@ -437,6 +437,9 @@ func (r *RuntimeServer) GetDeployInfo(ctx context.Context, re *pb.ServiceRequest
 			deployinfo.Deployment = appService.GetDeployment().Name
 			deployinfo.StartTime = appService.GetDeployment().ObjectMeta.CreationTimestamp.Format(time.RFC3339)
 		}
+		if appService.GetDaemonSet() != nil {
+			deployinfo.StartTime = appService.GetDaemonSet().ObjectMeta.CreationTimestamp.Format(time.RFC3339)
+		}
 		if services := appService.GetServices(false); services != nil {
 			service := make(map[string]string, len(services))
 			for _, s := range services {
@@ -594,7 +597,7 @@ func (r *RuntimeServer) listThirdEndpoints(as *v1.AppService) []*v1alpha1.ThirdC
 			Address: v1alpha1.EndpointAddress(endpointNameAddr[endpoint.Name]),
 			Status:  endpoint.Status,
 		}
-		if component.Spec.EndpointSource.KubernetesService != nil {
+		if component.Spec.EndpointSource.KubernetesService != nil || component.Spec.IsDynamicEndpoints() {
 			endPointStatus.Address = endpoint.Address
 		}
 		endpointStatuses = append(endpointStatuses, endPointStatus)